@@ -0,0 +1,67 @@
+package godev
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// assetNamePattern is what the names of assets have to match so that
+// they can be used as the names of execution groups
+var assetNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+// AssetConfig is a non-Go step declared under the assets key of the
+// configuration file, such as compiling stylesheets or generating code,
+// which runs at the start of the pipeline when its own files change
+type AssetConfig struct {
+	// Watch are the patterns of the files that the asset is built from,
+	// they are watched regardless of their extension
+	Watch []string `yaml:"watch" toml:"watch"`
+	// Run is the execution group that builds the asset
+	Run string `yaml:"run" toml:"run"`
+	// Outputs are the patterns of the files that the asset builds, their
+	// changes are ignored so that they do not trigger the pipeline again
+	Outputs []string `yaml:"outputs" toml:"outputs"`
+}
+
+// Validate checks that the asset can be added to the pipeline
+func (config *AssetConfig) Validate(delimiter string) error {
+	if len(config.Run) == 0 {
+		return fmt.Errorf("has nothing to run")
+	} else if err := validateExecutionGroup(config.Run, delimiter); err != nil {
+		return err
+	}
+	if onlyOn, commands, _ := parseExecutionGroupFilters(config.Run); len(onlyOn) > 0 {
+		return fmt.Errorf("has a file pattern prefix in run (watch is used instead)")
+	} else if options, _, _ := parseExecutionOptions(commands); len(options.Name) > 0 {
+		return fmt.Errorf("has a name in run (it is named by its key)")
+	}
+	if len(config.Watch) == 0 {
+		return fmt.Errorf("has no files to watch")
+	} else if err := validatePatterns(config.Watch); err != nil {
+		return fmt.Errorf("has an invalid watch pattern: %s", err)
+	}
+	if err := validatePatterns(config.Outputs); err != nil {
+		return fmt.Errorf("has an invalid output pattern: %s", err)
+	}
+	return nil
+}
+
+// validateAssetName checks that :name can name an execution group
+func validateAssetName(name string) error {
+	if !assetNamePattern.MatchString(name) {
+		return fmt.Errorf("'%s' is not a valid asset name (expected letters, digits, '-' and '_' starting with a letter)", name)
+	}
+	return nil
+}
+
+// getSortedAssetNames returns the names of :assets in the order that
+// their execution groups run in
+func getSortedAssetNames(assets map[string]AssetConfig) []string {
+	var names []string
+	for name := range assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,47 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AssetTestSuite struct {
+	suite.Suite
+}
+
+func TestAsset(t *testing.T) {
+	suite.Run(t, new(AssetTestSuite))
+}
+
+func (s *AssetTestSuite) TestValidate() {
+	t := s.T()
+	asset := &AssetConfig{Watch: []string{"**/*.sql"}, Run: "sqlc generate", Outputs: []string{"internal/db/**"}}
+	assert.Nil(t, asset.Validate(""))
+	assert.Nil(t, (&AssetConfig{Watch: []string{"*.scss"}, Run: "dir=web:npx sass main.scss main.css"}).Validate(""))
+	assert.Contains(t, (&AssetConfig{Watch: []string{"*.scss"}}).Validate("").Error(), "has nothing to run")
+	assert.Contains(t, (&AssetConfig{Run: "sass"}).Validate("").Error(), "has no files to watch")
+	assert.Contains(t, (&AssetConfig{Watch: []string{"["}, Run: "sass"}).Validate("").Error(), "has an invalid watch pattern")
+	assert.Contains(t, (&AssetConfig{Watch: []string{"*.scss"}, Run: "sass", Outputs: []string{"["}}).Validate("").Error(), "has an invalid output pattern")
+	assert.Contains(t, (&AssetConfig{Watch: []string{"*.scss"}, Run: "name=css:sass"}).Validate("").Error(), "has a name in run")
+	assert.Contains(t, (&AssetConfig{Watch: []string{"*.scss"}, Run: "[*.scss] sass"}).Validate("").Error(), "has a file pattern prefix in run")
+}
+
+func (s *AssetTestSuite) Test_validateAssetName() {
+	t := s.T()
+	for _, name := range []string{"css", "tailwind-css", "sqlc_gen", "templ2"} {
+		assert.Nilf(t, validateAssetName(name), "expected '%s' to be valid", name)
+	}
+	for _, name := range []string{"", "2css", "css files", "css:min", "css,js", "name=css"} {
+		assert.NotNilf(t, validateAssetName(name), "expected '%s' to be invalid", name)
+	}
+}
+
+func (s *AssetTestSuite) Test_getSortedAssetNames() {
+	assert.Equal(s.T(), []string{"css", "sqlc", "templ"}, getSortedAssetNames(map[string]AssetConfig{
+		"templ": AssetConfig{},
+		"css":   AssetConfig{},
+		"sqlc":  AssetConfig{},
+	}))
+}
@@ -0,0 +1,180 @@
+package godev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BuildReporterConfig configures BuildReporter
+type BuildReporterConfig struct {
+	Binaries  []string
+	GoModPath string
+	LogLevel  LogLevel
+	RunState  *RunState
+}
+
+// InitBuildReporter creates a reporter of the changes to the built
+// binaries and the modules of the project between pipelines
+func InitBuildReporter(config *BuildReporterConfig) *BuildReporter {
+	return &BuildReporter{
+		config:   config,
+		logger:   InitLogger(&LoggerConfig{Name: "report", Format: "production", Level: config.LogLevel, RunState: config.RunState}),
+		binaries: map[string]os.FileInfo{},
+	}
+}
+
+// BuildReporter reports how the size of the built binaries and the
+// modules required in go.mod changed since the last successful pipeline
+// so that dependency bloat is noticed while it is being introduced
+type BuildReporter struct {
+	config        *BuildReporterConfig
+	logger        *Logger
+	binaries      map[string]os.FileInfo
+	modules       map[string]string
+	goModModified time.Time
+	mutex         sync.Mutex
+}
+
+// Update reports the binaries which were rebuilt and the changes to
+// go.mod since the last update
+func (reporter *BuildReporter) Update() {
+	reporter.mutex.Lock()
+	defer reporter.mutex.Unlock()
+	for _, binary := range reporter.config.Binaries {
+		fileInfo, err := os.Stat(binary)
+		if err != nil {
+			reporter.logger.Tracef("no binary at '%s' to report the size of", binary)
+			continue
+		}
+		previous, ok := reporter.binaries[binary]
+		reporter.binaries[binary] = fileInfo
+		if !ok {
+			reporter.logger.Infof("binary size of '%s': %s", binary, formatByteSize(fileInfo.Size()))
+		} else if fileInfo.ModTime().After(previous.ModTime()) {
+			reporter.logger.Infof("binary size of '%s': %s (%s)", binary, formatByteSize(fileInfo.Size()), formatByteSizeDelta(fileInfo.Size()-previous.Size()))
+		}
+	}
+	fileInfo, err := os.Stat(reporter.config.GoModPath)
+	if err != nil || !fileInfo.ModTime().After(reporter.goModModified) {
+		return
+	}
+	reporter.goModModified = fileInfo.ModTime()
+	contents, err := ioutil.ReadFile(reporter.config.GoModPath)
+	if err != nil {
+		reporter.logger.Warnf("unable to read '%s': %s", reporter.config.GoModPath, err)
+		return
+	}
+	modules := parseGoModRequirements(string(contents))
+	if reporter.modules != nil {
+		if changes := diffModules(reporter.modules, modules); len(changes) > 0 {
+			reporter.logger.Infof("go.mod changed: %s", strings.Join(changes, ", "))
+		}
+	}
+	reporter.modules = modules
+}
+
+// parseGoModRequirements returns the versions of the modules required
+// in the go.mod :contents by their paths
+func parseGoModRequirements(contents string) map[string]string {
+	modules := map[string]string{}
+	inRequireBlock := false
+	for _, line := range strings.Split(contents, "\n") {
+		if index := strings.Index(line, "//"); index >= 0 {
+			line = line[:index]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inRequireBlock && fields[0] == ")":
+			inRequireBlock = false
+		case inRequireBlock && len(fields) == 2:
+			modules[fields[0]] = fields[1]
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequireBlock = true
+		case fields[0] == "require" && len(fields) == 3:
+			modules[fields[1]] = fields[2]
+		}
+	}
+	return modules
+}
+
+// diffModules describes the modules which were added, removed, upgraded
+// or downgraded from :previous to :current sorted by their paths
+func diffModules(previous, current map[string]string) []string {
+	var paths []string
+	for modulePath := range previous {
+		paths = append(paths, modulePath)
+	}
+	for modulePath := range current {
+		if _, ok := previous[modulePath]; !ok {
+			paths = append(paths, modulePath)
+		}
+	}
+	sort.Strings(paths)
+	var changes []string
+	for _, modulePath := range paths {
+		previousVersion, wasRequired := previous[modulePath]
+		currentVersion, isRequired := current[modulePath]
+		switch {
+		case !wasRequired:
+			changes = append(changes, fmt.Sprintf("added %s %s", modulePath, currentVersion))
+		case !isRequired:
+			changes = append(changes, fmt.Sprintf("removed %s %s", modulePath, previousVersion))
+		case compareModuleVersions(currentVersion, previousVersion) > 0:
+			changes = append(changes, fmt.Sprintf("upgraded %s %s => %s", modulePath, previousVersion, currentVersion))
+		case compareModuleVersions(currentVersion, previousVersion) < 0:
+			changes = append(changes, fmt.Sprintf("downgraded %s %s => %s", modulePath, previousVersion, currentVersion))
+		}
+	}
+	return changes
+}
+
+// compareModuleVersions compares the semantic versions :a and :b and
+// returns a negative number when :a is lower, a positive one when it is
+// higher and 0 when they are the same - pre-releases and pseudo-versions
+// are lower than the release of the same version
+func compareModuleVersions(a, b string) int {
+	aRelease, aPreRelease := splitModuleVersion(a)
+	bRelease, bPreRelease := splitModuleVersion(b)
+	for index := 0; index < len(aRelease) && index < len(bRelease); index++ {
+		if aRelease[index] != bRelease[index] {
+			return aRelease[index] - bRelease[index]
+		}
+	}
+	if len(aRelease) != len(bRelease) {
+		return len(aRelease) - len(bRelease)
+	}
+	switch {
+	case aPreRelease == bPreRelease:
+		return 0
+	case len(aPreRelease) == 0:
+		return 1
+	case len(bPreRelease) == 0:
+		return -1
+	}
+	return strings.Compare(aPreRelease, bPreRelease)
+}
+
+// splitModuleVersion splits :version (eg. v1.2.3-rc.1+incompatible)
+// into its numbers and its pre-release
+func splitModuleVersion(version string) ([]int, string) {
+	version = strings.TrimPrefix(version, "v")
+	version = strings.SplitN(version, "+", 2)[0]
+	parts := strings.SplitN(version, "-", 2)
+	var numbers []int
+	for _, part := range strings.Split(parts[0], ".") {
+		number, _ := strconv.Atoi(part)
+		numbers = append(numbers, number)
+	}
+	if len(parts) == 2 {
+		return numbers, parts[1]
+	}
+	return numbers, ""
+}
@@ -0,0 +1,84 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BuildReporterTestSuite struct {
+	suite.Suite
+	directory string
+	reporter  *BuildReporter
+	logs      bytes.Buffer
+}
+
+func TestBuildReporter(t *testing.T) {
+	suite.Run(t, new(BuildReporterTestSuite))
+}
+
+func (s *BuildReporterTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-build-report")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.reporter = InitBuildReporter(&BuildReporterConfig{
+		Binaries:  []string{path.Join(directory, "app")},
+		GoModPath: path.Join(directory, "go.mod"),
+		LogLevel:  "trace",
+	})
+	s.logs.Reset()
+	s.reporter.logger.SetOutput(&s.logs)
+}
+
+func (s *BuildReporterTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *BuildReporterTestSuite) writeFile(name string, contents []byte, modTime time.Time) {
+	filePath := path.Join(s.directory, name)
+	assert.Nil(s.T(), ioutil.WriteFile(filePath, contents, 0644))
+	assert.Nil(s.T(), os.Chtimes(filePath, modTime, modTime))
+}
+
+func (s *BuildReporterTestSuite) TestUpdate() {
+	t := s.T()
+	s.reporter.Update()
+	assert.Contains(t, s.logs.String(), "no binary at")
+	now := time.Now()
+	s.writeFile("app", make([]byte, 2048), now.Add(-time.Minute))
+	s.writeFile("go.mod", []byte("module app\n\nrequire (\n\tgithub.com/a/a v1.0.0\n\tgithub.com/b/b v1.2.0 // indirect\n)\n"), now.Add(-time.Minute))
+	s.reporter.Update()
+	assert.Contains(t, s.logs.String(), "binary size of '"+path.Join(s.directory, "app")+"': 2.0 KB")
+	assert.NotContains(t, s.logs.String(), "go.mod changed", "expected the first go.mod to be the baseline")
+	s.logs.Reset()
+	s.reporter.Update()
+	assert.Empty(t, s.logs.String(), "expected nothing to be reported when nothing was rebuilt")
+	s.writeFile("app", make([]byte, 3072), now)
+	s.writeFile("go.mod", []byte("module app\n\nrequire github.com/b/b v1.10.0\nrequire github.com/c/c v0.1.0\n"), now)
+	s.reporter.Update()
+	assert.Contains(t, s.logs.String(), "3.0 KB (+1.0 KB)")
+	assert.Contains(t, s.logs.String(), "go.mod changed: removed github.com/a/a v1.0.0, upgraded github.com/b/b v1.2.0 => v1.10.0, added github.com/c/c v0.1.0")
+}
+
+func (s *BuildReporterTestSuite) Test_compareModuleVersions() {
+	t := s.T()
+	assert.True(t, compareModuleVersions("v1.10.0", "v1.9.0") > 0)
+	assert.True(t, compareModuleVersions("v1.0.0-rc.1", "v1.0.0") < 0)
+	assert.True(t, compareModuleVersions("v0.0.0-20200101000000-abcdef123456", "v0.0.0-20190101000000-abcdef123456") > 0)
+	assert.Equal(t, 0, compareModuleVersions("v2.0.0+incompatible", "v2.0.0"))
+}
+
+func (s *BuildReporterTestSuite) Test_diffModules() {
+	t := s.T()
+	changes := diffModules(
+		map[string]string{"a": "v1.0.0", "b": "v1.1.0"},
+		map[string]string{"a": "v1.0.0", "b": "v1.0.1"},
+	)
+	assert.Equal(t, []string{"downgraded b v1.1.0 => v1.0.1"}, changes)
+}
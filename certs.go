@@ -0,0 +1,224 @@
+package godev
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"time"
+)
+
+// ProjectCertsDirectoryName is the name of the directory in the project
+// directory which holds the development CA and certificates
+const ProjectCertsDirectoryName = "certs"
+
+// names of the files in the certificates directory
+const (
+	CertsCAFileName    = "ca.pem"
+	CertsCAKeyFileName = "ca-key.pem"
+	CertsCertFileName  = "cert.pem"
+	CertsKeyFileName   = "key.pem"
+)
+
+// CertsValidity - duration that generated certificates are valid for,
+// CertsCAValidity - duration that the generated CA is valid for
+const (
+	CertsValidity   = 825 * 24 * time.Hour
+	CertsCAValidity = 10 * 365 * 24 * time.Hour
+)
+
+// DefaultCertsHosts - default hosts that certificates are generated for
+var DefaultCertsHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// CertsConfig configures Certs
+type CertsConfig struct {
+	// Directory is where the CA and certificates are kept
+	Directory string
+	// Hosts are the DNS names and IP addresses certificates are valid for
+	Hosts []string
+	// Name identifies the project in the CA's subject
+	Name string
+}
+
+// InitCerts creates a handle on the development CA and certificate of
+// a project
+func InitCerts(config *CertsConfig) *Certs {
+	if len(config.Hosts) == 0 {
+		config.Hosts = DefaultCertsHosts
+	}
+	return &Certs{config: config}
+}
+
+// Certs manages a local CA and the certificate it signs for serving
+// TLS in development
+type Certs struct {
+	config *CertsConfig
+}
+
+// GetPath returns the path of the file named :fileName in the
+// certificates directory
+func (certs *Certs) GetPath(fileName string) string {
+	return path.Join(certs.config.Directory, fileName)
+}
+
+// Exists checks whether a certificate and its key were generated
+func (certs *Certs) Exists() bool {
+	return fileExists(certs.GetPath(CertsCertFileName)) && fileExists(certs.GetPath(CertsKeyFileName))
+}
+
+// GetEnvironment returns the variables pointing child processes at the
+// certificate, its key and the CA as KEY=value pairs
+func (certs *Certs) GetEnvironment() []string {
+	return []string{
+		"GODEV_TLS_CA=" + certs.GetPath(CertsCAFileName),
+		"GODEV_TLS_CERT=" + certs.GetPath(CertsCertFileName),
+		"GODEV_TLS_KEY=" + certs.GetPath(CertsKeyFileName),
+	}
+}
+
+// Generate creates the CA if it does not exist yet and (re-)issues the
+// certificate for the configured hosts, reusing the CA keeps it trusted
+func (certs *Certs) Generate() error {
+	if err := os.MkdirAll(certs.config.Directory, 0700); err != nil {
+		return err
+	}
+	ca, caKey, err := certs.loadCA()
+	if os.IsNotExist(err) {
+		ca, caKey, err = certs.generateCA()
+	}
+	if err != nil {
+		return err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template, err := newCertificateTemplate(certs.config.Hosts[0], CertsValidity)
+	if err != nil {
+		return err
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	for _, host := range certs.config.Hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("unable to issue the certificate: %s", err)
+	}
+	if err := writePEM(certs.GetPath(CertsCertFileName), "CERTIFICATE", certificate, 0644); err != nil {
+		return err
+	}
+	return writeECKey(certs.GetPath(CertsKeyFileName), key)
+}
+
+// generateCA creates a self-signed CA for signing certificates
+func (certs *Certs) generateCA() (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := newCertificateTemplate(fmt.Sprintf("godev development CA (%s)", certs.config.Name), CertsCAValidity)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.MaxPathLenZero = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	encoded, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create the CA: %s", err)
+	}
+	if err := writePEM(certs.GetPath(CertsCAFileName), "CERTIFICATE", encoded, 0644); err != nil {
+		return nil, nil, err
+	}
+	if err := writeECKey(certs.GetPath(CertsCAKeyFileName), key); err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(encoded)
+	return ca, key, err
+}
+
+// loadCA reads the CA and its key, errors satisfy os.IsNotExist when
+// the CA has not been generated yet
+func (certs *Certs) loadCA() (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	caBlock, err := readPEM(certs.GetPath(CertsCAFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, err := readPEM(certs.GetPath(CertsCAKeyFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(caBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse the CA at '%s': %s", certs.GetPath(CertsCAFileName), err)
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse the CA key at '%s': %s", certs.GetPath(CertsCAKeyFileName), err)
+	}
+	return ca, key, nil
+}
+
+// Trust adds the CA to the trust store of the operating system so that
+// browsers and clients accept the certificates it signs
+func (certs *Certs) Trust() error {
+	caPath := certs.GetPath(CertsCAFileName)
+	if !fileExists(caPath) {
+		return fmt.Errorf("no CA at '%s' - run 'godev certs generate' first", caPath)
+	}
+	return trustCertificate(caPath, "godev-"+certs.config.Name)
+}
+
+func newCertificateTemplate(commonName string, validity time.Duration) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{Organization: []string{"godev"}, CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, nil
+}
+
+func readPEM(filePath string) (*pem.Block, error) {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, errors.New("'" + filePath + "' does not contain PEM data")
+	}
+	return block, nil
+}
+
+func writePEM(filePath, blockType string, contents []byte, mode os.FileMode) error {
+	return ioutil.WriteFile(filePath, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: contents}), mode)
+}
+
+func writeECKey(filePath string, key *ecdsa.PrivateKey) error {
+	encoded, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	return writePEM(filePath, "EC PRIVATE KEY", encoded, 0600)
+}
@@ -0,0 +1,19 @@
+//go:build darwin
+// +build darwin
+
+package godev
+
+import (
+	"os"
+	"os/exec"
+)
+
+// trustCertificate adds the CA at :caPath to the system keychain as a
+// trusted root, macOS prompts for the password of an administrator
+func trustCertificate(caPath, name string) error {
+	cmd := exec.Command("security", "add-trusted-cert", "-d", "-r", "trustRoot", "-k", "/Library/Keychains/System.keychain", caPath)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
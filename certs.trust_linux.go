@@ -0,0 +1,46 @@
+//go:build linux
+// +build linux
+
+package godev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+)
+
+// linuxTrustStores are the anchor directories of the common linux
+// distributions and the commands which rebuild their trust stores
+var linuxTrustStores = []struct {
+	directory string
+	command   []string
+}{
+	{"/usr/local/share/ca-certificates", []string{"update-ca-certificates"}},
+	{"/etc/pki/ca-trust/source/anchors", []string{"update-ca-trust", "extract"}},
+	{"/etc/ca-certificates/trust-source/anchors", []string{"trust", "extract-compat"}},
+}
+
+// trustCertificate copies the CA at :caPath into the first trust store
+// found and rebuilds it, this usually requires root
+func trustCertificate(caPath, name string) error {
+	contents, err := ioutil.ReadFile(caPath)
+	if err != nil {
+		return err
+	}
+	for _, store := range linuxTrustStores {
+		if _, err := exec.LookPath(store.command[0]); err != nil || !directoryExists(store.directory) {
+			continue
+		}
+		anchorPath := path.Join(store.directory, name+".crt")
+		if err := ioutil.WriteFile(anchorPath, contents, 0644); err != nil {
+			return fmt.Errorf("unable to write '%s' (try again with sudo): %s", anchorPath, err)
+		}
+		cmd := exec.Command(store.command[0], store.command[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+	return fmt.Errorf("no supported trust store was found - add '%s' to your trust store manually", caPath)
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package godev
+
+import (
+	"fmt"
+)
+
+// trustCertificate is not implemented for this platform yet
+func trustCertificate(caPath, name string) error {
+	return fmt.Errorf("trusting certificates is not supported on this platform - add '%s' to your trust store manually", caPath)
+}
@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"os"
+	"os/exec"
+)
+
+// trustCertificate adds the CA at :caPath to the trusted root
+// certification authorities of the current user
+func trustCertificate(caPath, name string) error {
+	cmd := exec.Command("certutil", "-user", "-addstore", "-f", "Root", caPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,93 @@
+package godev
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CertsTestSuite struct {
+	suite.Suite
+	directory string
+	certs     *Certs
+}
+
+func TestCerts(t *testing.T) {
+	suite.Run(t, new(CertsTestSuite))
+}
+
+func (s *CertsTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-certs")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+	s.certs = InitCerts(&CertsConfig{Directory: path.Join(directory, "certs"), Name: "project"})
+}
+
+func (s *CertsTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CertsTestSuite) loadCertificate(fileName string) *x509.Certificate {
+	block, err := readPEM(s.certs.GetPath(fileName))
+	assert.Nil(s.T(), err)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	assert.Nil(s.T(), err)
+	return certificate
+}
+
+func (s *CertsTestSuite) TestInitCerts_defaultsHosts() {
+	assert.Equal(s.T(), DefaultCertsHosts, s.certs.config.Hosts)
+}
+
+func (s *CertsTestSuite) TestGenerate() {
+	t := s.T()
+	assert.False(t, s.certs.Exists())
+	assert.Nil(t, s.certs.Generate())
+	assert.True(t, s.certs.Exists())
+	ca := s.loadCertificate(CertsCAFileName)
+	assert.True(t, ca.IsCA)
+	assert.Equal(t, "godev development CA (project)", ca.Subject.CommonName)
+	certificate := s.loadCertificate(CertsCertFileName)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		_, err := certificate.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.Nilf(t, err, "expected the certificate to be valid for '%s'", host)
+	}
+	_, err := certificate.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots})
+	assert.NotNil(t, err)
+	fileInfo, err := os.Stat(s.certs.GetPath(CertsKeyFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+}
+
+func (s *CertsTestSuite) TestGenerate_reusesCA() {
+	t := s.T()
+	assert.Nil(t, s.certs.Generate())
+	ca := s.loadCertificate(CertsCAFileName)
+	s.certs.config.Hosts = []string{"api.test"}
+	assert.Nil(t, s.certs.Generate())
+	assert.Equal(t, ca.SerialNumber, s.loadCertificate(CertsCAFileName).SerialNumber)
+	assert.Equal(t, []string{"api.test"}, s.loadCertificate(CertsCertFileName).DNSNames)
+}
+
+func (s *CertsTestSuite) TestGetEnvironment() {
+	assert.Equal(s.T(), []string{
+		"GODEV_TLS_CA=" + path.Join(s.directory, "certs", "ca.pem"),
+		"GODEV_TLS_CERT=" + path.Join(s.directory, "certs", "cert.pem"),
+		"GODEV_TLS_KEY=" + path.Join(s.directory, "certs", "key.pem"),
+	}, s.certs.GetEnvironment())
+}
+
+func (s *CertsTestSuite) TestTrust_withoutCA() {
+	err := s.certs.Trust()
+	assert.NotNil(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "run 'godev certs generate' first")
+}
@@ -0,0 +1,146 @@
+package godev
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	shellquote "github.com/kballard/go-shellquote"
+)
+
+// check resolves the pipeline without starting the watcher, writes the
+// resolved plan and any problems found to :output and returns the
+// number of problems
+func (godev *GoDev) check(output io.Writer) int {
+	config := godev.config
+	var problems []string
+	fmt.Fprintf(output, "watch directory : %s\n", config.WatchDirectory)
+	if !directoryExists(config.WatchDirectory) {
+		problems = append(problems, fmt.Sprintf("watch directory '%s' does not exist", config.WatchDirectory))
+	}
+	fmt.Fprintf(output, "work directory  : %s\n", config.WorkDirectory)
+	if !directoryExists(config.WorkDirectory) {
+		problems = append(problems, fmt.Sprintf("work directory '%s' does not exist", config.WorkDirectory))
+	}
+	if len(config.ConfigFile) > 0 {
+		fmt.Fprintf(output, "config file     : %s\n", config.ConfigFile)
+	}
+	if len(config.Profile) > 0 {
+		fmt.Fprintf(output, "profile         : %s\n", config.Profile)
+	}
+	if err := config.resolveProfile(); err != nil {
+		problems = append(problems, err.Error())
+	} else if err := config.resolveAssets(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	fmt.Fprintf(output, "file extensions : %s\n", strings.Join(config.FileExtensions, ", "))
+	fmt.Fprintf(output, "ignored names   : %s\n", strings.Join(config.IgnoredNames, ", "))
+	fmt.Fprintln(output, "execution groups:")
+	for index, execGroup := range config.ExecGroups {
+		problems = append(problems, godev.checkExecutionGroup(output, index+1, execGroup)...)
+	}
+	if len(config.PublishTarget) > 0 {
+		fmt.Fprintf(output, "publish to      : %s\n", config.PublishTarget)
+		if strings.HasPrefix(config.PublishTarget, PublishDockerPrefix) {
+			if _, err := exec.LookPath("docker"); err != nil {
+				problems = append(problems, "publish: 'docker' was not found in $PATH")
+			}
+			if !fileExists(path.Join(config.WorkDirectory, "Dockerfile")) {
+				problems = append(problems, fmt.Sprintf("publish: no Dockerfile in '%s' to build an image from", config.WorkDirectory))
+			}
+		}
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(output, Color("red", "✗ "+problem))
+	}
+	if len(problems) == 0 {
+		fmt.Fprintln(output, Color("green", "✓ no problems found"))
+	}
+	return len(problems)
+}
+
+// checkExecutionGroup writes the commands of the 1-based :index-th
+// :execGroup to :output and returns the problems found with them
+func (godev *GoDev) checkExecutionGroup(output io.Writer, index int, execGroup string) []string {
+	config := godev.config
+	if err := validateExecutionGroup(execGroup, config.CommandsDelimiter); err != nil {
+		fmt.Fprintf(output, "  %v) %s\n", index, execGroup)
+		return []string{fmt.Sprintf("execution group %v: %s", index, err)}
+	}
+	var problems []string
+	onlyOn, execGroupCommands, _ := parseExecutionGroupFilters(execGroup)
+	groupOptions, execGroupCommands, _ := parseExecutionOptions(execGroupCommands)
+	groupDirectory := groupOptions.GetDirectory(config.WorkDirectory)
+	if len(onlyOn) > 0 {
+		fmt.Fprintf(output, "  %v) on changes to %s\n", index, strings.Join(onlyOn, ", "))
+	} else {
+		fmt.Fprintf(output, "  %v) on all changes\n", index)
+	}
+	for commandIndex, command := range splitCommands(execGroupCommands, config.CommandsDelimiter) {
+		commandOptions, command, _ := parseExecutionOptions(command)
+		var commandArguments []string
+		if index == len(config.ExecGroups) {
+			commandArguments = config.CommandArguments
+		}
+		sections, _ := splitCommand(command, config.Shell, commandArguments)
+		if len(sections) == 0 {
+			problems = append(problems, fmt.Sprintf("execution group %v: command %v is empty", index, commandIndex+1))
+			continue
+		}
+		arguments := sections[1:]
+		directory := commandOptions.GetDirectory(groupDirectory)
+		fmt.Fprintf(output, "     %v > %s\n", commandIndex+1, strings.TrimSpace(sections[0]+" "+shellquote.Join(arguments...)))
+		if directory != config.WorkDirectory {
+			fmt.Fprintf(output, "         in %s\n", directory)
+		}
+		if when := commandOptions.GetWhen(groupOptions); len(when) > 0 {
+			fmt.Fprintf(output, "         on changes to %s\n", strings.Join(when, ", "))
+		}
+		application := sections[0]
+		if image := commandOptions.GetImage(groupOptions); len(image) > 0 {
+			fmt.Fprintf(output, "         in a container of %s\n", image)
+			containerRuntime, err := findContainerRuntime(config.ContainerRuntime)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("execution group %v: %s", index, err))
+				continue
+			}
+			application = containerRuntime
+		}
+		if !directoryExists(directory) {
+			problems = append(problems, fmt.Sprintf("execution group %v: directory '%s' of command %v does not exist", index, directory, commandIndex+1))
+		} else if err := checkExecutable(application, directory, index > 1); err != nil {
+			problems = append(problems, fmt.Sprintf("execution group %v: %s", index, err))
+		}
+	}
+	return problems
+}
+
+// checkExecutable verifies that :application can be run from
+// :directory - paths which do not exist yet are allowed when
+// :canBeBuilt since an earlier execution group may create them
+func checkExecutable(application, directory string, canBeBuilt bool) error {
+	if !strings.Contains(application, "/") {
+		if _, err := exec.LookPath(application); err != nil {
+			return fmt.Errorf("'%s' was not found in PATH", application)
+		}
+		return nil
+	}
+	applicationPath := application
+	if !path.IsAbs(applicationPath) {
+		applicationPath = path.Join(directory, applicationPath)
+	}
+	fileInfo, err := os.Stat(applicationPath)
+	if os.IsNotExist(err) && canBeBuilt {
+		return nil
+	} else if os.IsNotExist(err) {
+		return fmt.Errorf("'%s' does not exist", applicationPath)
+	} else if err != nil {
+		return err
+	} else if fileInfo.IsDir() {
+		return fmt.Errorf("'%s' is a directory", applicationPath)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CheckTestSuite struct {
+	suite.Suite
+	directory string
+	output    bytes.Buffer
+}
+
+func TestCheck(t *testing.T) {
+	suite.Run(t, new(CheckTestSuite))
+}
+
+func (s *CheckTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-check")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+	s.output.Reset()
+}
+
+func (s *CheckTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CheckTestSuite) initGoDev(execGroups ...string) *GoDev {
+	return New(&Config{
+		CommandsDelimiter: ",",
+		ExecGroups:        execGroups,
+		LogLevel:          "panic",
+		WatchDirectory:    s.directory,
+		WorkDirectory:     s.directory,
+	})
+}
+
+func (s *CheckTestSuite) Test_check() {
+	t := s.T()
+	assert.Nil(t, os.Mkdir(path.Join(s.directory, "api"), 0755))
+	godev := s.initGoDev(
+		"[*.go] go build -o bin/app,go vet ./...",
+		"dir=api,env=PORT=8080:bin/app --verbose",
+	)
+	godev.config.ExecGroups[0] = "[*.go] go build -o bin/app,when=**/*.go|go.mod:go vet ./..."
+	assert.Equal(t, 0, godev.check(&s.output))
+	assert.Contains(t, s.output.String(), "on changes to **/*.go, go.mod")
+	assert.Contains(t, s.output.String(), "1) on changes to *.go")
+	assert.Contains(t, s.output.String(), "1 > go build -o bin/app")
+	assert.Contains(t, s.output.String(), "2) on all changes")
+	assert.Contains(t, s.output.String(), "in "+path.Join(s.directory, "api"))
+	assert.Contains(t, s.output.String(), "no problems found")
+}
+
+func (s *CheckTestSuite) Test_check_reportsProblems() {
+	t := s.T()
+	godev := s.initGoDev(
+		"./missing-script.sh",
+		"godev-command-that-does-not-exist",
+		"dir=missing:go run .",
+		"go build,env=PORT:go run .",
+	)
+	assert.Equal(t, 4, godev.check(&s.output))
+	assert.Contains(t, s.output.String(), "execution group 1: '"+path.Join(s.directory, "missing-script.sh")+"' does not exist")
+	assert.Contains(t, s.output.String(), "execution group 2: 'godev-command-that-does-not-exist' was not found in PATH")
+	assert.Contains(t, s.output.String(), "execution group 3: directory '"+path.Join(s.directory, "missing")+"' of command 1 does not exist")
+	assert.Contains(t, s.output.String(), "execution group 4: 'env=PORT' is not a valid environment variable")
+	assert.NotContains(t, s.output.String(), "no problems found")
+}
+
+func (s *CheckTestSuite) Test_check_missingDirectories() {
+	t := s.T()
+	godev := s.initGoDev("go build")
+	godev.config.WatchDirectory = path.Join(s.directory, "missing")
+	assert.Equal(t, 1, godev.check(&s.output))
+	assert.Contains(t, s.output.String(), "watch directory '"+godev.config.WatchDirectory+"' does not exist")
+}
+
+func (s *CheckTestSuite) Test_check_publishToDockerWithoutDockerfile() {
+	t := s.T()
+	godev := s.initGoDev("go build -o bin/app")
+	godev.config.PublishTarget = "docker://localhost:5000/app"
+	assert.NotEqual(t, 0, godev.check(&s.output))
+	assert.Contains(t, s.output.String(), "publish to      : docker://localhost:5000/app")
+	assert.Contains(t, s.output.String(), "publish: no Dockerfile in '"+s.directory+"'")
+}
+
+func (s *CheckTestSuite) Test_checkExecutable() {
+	t := s.T()
+	assert.Nil(t, checkExecutable("go", s.directory, false))
+	assert.NotNil(t, checkExecutable("./bin/app", s.directory, false))
+	assert.Nil(t, checkExecutable("./bin/app", s.directory, true))
+	assert.NotNil(t, checkExecutable("/", s.directory, false), "expected directories to be rejected")
+}
@@ -1,7 +1,8 @@
-package main
+package godev
 
 import (
 	"os"
+	"strings"
 
 	"github.com/urfave/cli"
 )
@@ -26,10 +27,27 @@ func initCLI() *CLI {
 	instance.Version = Version
 	instance.Action = getDefaultAction(app.config)
 	instance.Commands = []cli.Command{
+		getCertsCommand(app.config, app.rawLogger),
+		getCleanCommand(app.config, app.rawLogger),
+		getCoverageCommand(app.config, app.rawLogger),
+		getDaemonCommand(app.config),
+		getHistoryCommand(app.config, app.rawLogger),
 		getInitCommand(app.config),
+		getLintScaffoldCommand(app.config, app.rawLogger),
+		getLogsCommand(app.config, app.rawLogger),
+		getPromptCommand(app.config, app.rawLogger),
+		getReplayCommand(app.config, app.rawLogger),
+		getReportCommand(app.config, app.rawLogger),
+		getRunCommand(app.config),
+		getSecretCommand(app.config, app.rawLogger),
+		getShardCommand(app.config, app.rawLogger),
+		getStatusCommand(app.config, app.rawLogger),
 		getTestCommand(app.config),
+		getTouchCommand(app.config, app.rawLogger),
 		getVersionCommand(app.config, app.rawLogger),
 		getViewCommand(app.config, app.rawLogger),
+		getWarmCommand(app.config, app.rawLogger),
+		getWatchCommand(app.config),
 	}
 	instance.Flags = getDefaultFlags()
 	app.instance = instance
@@ -46,15 +64,95 @@ type CLI struct {
 }
 
 // Start triggers the CLI manager to parse the inputs and set
-// the configuration flags correctly
-func (app *CLI) Start(args []string, after func(*Config)) {
-	app.instance.After = func(c *cli.Context) error {
-		after(app.config)
-		return nil
-	}
+// the configuration flags correctly, it returns the error of :after or
+// the error of parsing the inputs which :after is not called for
+func (app *CLI) Start(args []string, after func(*Config) error) error {
 	if err := app.instance.Run(args); err != nil {
 		app.logger.Error(err)
-		app.logger.Warn("exiting with status code 1")
-		os.Exit(1)
+		return err
+	}
+	return after(app.config)
+}
+
+// ParseConfig returns the configuration which the command line runs
+// with for the arguments :args, eg. []string{"godev", "test"}, so that
+// programs which embed godev can change it before passing it to New -
+// :args are kept to re-execute godev with after an upgrade
+func ParseConfig(args []string) (*Config, error) {
+	var parsed *Config
+	err := initCLI().Start(args, func(config *Config) error {
+		config.Args = args
+		parsed = config
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// applyConfigFile merges the configuration file specified by --config,
+// or found in the watch directory, into :config
+func applyConfigFile(c *cli.Context, config *Config) error {
+	config.ConfigFile = c.String("config")
+	if len(config.ConfigFile) == 0 {
+		config.ConfigFile = FindConfigFile(config.WatchDirectory)
+		if len(config.ConfigFile) == 0 {
+			return nil
+		}
+	}
+	configFile, err := LoadConfigFile(config.ConfigFile)
+	if err != nil {
+		return err
+	}
+	return InitConfig(config, configFile, getFlagIsSet(c))
+}
+
+// ExecGroupsEnvVar is the environment variable for --exec which is not
+// handled by the flag itself since commas delimit commands in a group
+const ExecGroupsEnvVar = "GODEV_EXEC"
+
+// ExecGroupsEnvDelimiter separates execution groups in ExecGroupsEnvVar
+const ExecGroupsEnvDelimiter = ";"
+
+// getExecGroups returns the execution groups from --exec or from the
+// semicolon-delimited ExecGroupsEnvVar if no --exec was specified
+func getExecGroups(c *cli.Context) []string {
+	execGroups := c.StringSlice("exec")
+	if len(execGroups) == 0 && len(os.Getenv(ExecGroupsEnvVar)) > 0 {
+		for _, execGroup := range splitCommands(os.Getenv(ExecGroupsEnvVar), ExecGroupsEnvDelimiter) {
+			if len(strings.TrimSpace(execGroup)) > 0 {
+				execGroups = append(execGroups, strings.TrimSpace(execGroup))
+			}
+		}
+	}
+	return execGroups
+}
+
+// getFlagEnvVar returns the GODEV_ environment variable for the flag :name
+func getFlagEnvVar(name string) string {
+	return "GODEV_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// getFlagIsSet returns a function which checks if a flag was specified
+// by any of its names or through its environment variable
+func getFlagIsSet(c *cli.Context) func(string) bool {
+	flags := c.Command.Flags
+	if len(c.Command.Name) == 0 && c.App != nil {
+		flags = c.App.Flags
+	}
+	return func(name string) bool {
+		for _, flag := range flags {
+			names := strings.Split(flag.GetName(), ",")
+			if strings.TrimSpace(names[0]) != name {
+				continue
+			}
+			for _, alias := range names {
+				if c.IsSet(strings.TrimSpace(alias)) {
+					return true
+				}
+			}
+		}
+		return c.IsSet(name) || len(os.Getenv(getFlagEnvVar(name))) > 0
 	}
 }
@@ -0,0 +1,78 @@
+package godev
+
+import (
+	"path"
+
+	"github.com/urfave/cli"
+)
+
+func getCertsCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Description: "manage a local CA and the TLS certificate it signs for the project at --dir, godev passes their paths to child processes as GODEV_TLS_CA, GODEV_TLS_CERT and GODEV_TLS_KEY",
+		Name:        "certs",
+		Usage:       "manage development TLS certificates",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Action:      getCertsAction(config, logger, generateCerts),
+				Description: "create the CA if it does not exist yet and issue a certificate for the --host values",
+				Flags:       append(getCertsFlags(), getFlagHosts()),
+				Name:        "generate",
+				Usage:       "generate the CA and a certificate for the project",
+			},
+			cli.Command{
+				Action:      getCertsAction(config, logger, trustCerts),
+				Description: "add the CA to the trust store of the operating system, this usually requires elevated privileges",
+				Flags:       getCertsFlags(),
+				Name:        "trust",
+				Usage:       "trust the CA of the project",
+			},
+		},
+	}
+}
+
+func getCertsFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagProjectDirectory(),
+		getFlagWorkDirectory(),
+	}
+}
+
+func getCertsAction(config *Config, logger *Logger, operation func(*Certs, *Logger) error) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunCerts = true
+		config.ProjectDirectory = c.String("project-dir")
+		config.WorkDirectory = c.String("dir")
+		if !path.IsAbs(config.ProjectDirectory) {
+			config.ProjectDirectory = path.Join(config.WorkDirectory, config.ProjectDirectory)
+		}
+		config.interpretLogLevel()
+		if err := InitProjectDirectory(&ProjectDirectoryConfig{LogLevel: config.LogLevel, Path: config.ProjectDirectory}).Init(); err != nil {
+			return err
+		}
+		certs := InitCerts(&CertsConfig{
+			Directory: path.Join(config.ProjectDirectory, ProjectCertsDirectoryName),
+			Hosts:     c.StringSlice("host"),
+			Name:      path.Base(config.WorkDirectory),
+		})
+		return operation(certs, logger)
+	}
+}
+
+func generateCerts(certs *Certs, logger *Logger) error {
+	if err := certs.Generate(); err != nil {
+		return err
+	}
+	logger.Infof("generated a certificate for %v", certs.config.Hosts)
+	for _, fileName := range []string{CertsCAFileName, CertsCertFileName, CertsKeyFileName} {
+		logger.Infof("  %s", certs.GetPath(fileName))
+	}
+	return nil
+}
+
+func trustCerts(certs *Certs, logger *Logger) error {
+	if err := certs.Trust(); err != nil {
+		return err
+	}
+	logger.Infof("trusted '%s'", certs.GetPath(CertsCAFileName))
+	return nil
+}
@@ -0,0 +1,65 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLICertsHandlerTestSuite struct {
+	suite.Suite
+	directory string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestCLICertsHandler(t *testing.T) {
+	suite.Run(t, new(CLICertsHandlerTestSuite))
+}
+
+func (s *CLICertsHandlerTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-cli-certs")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getCertsAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLICertsHandlerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLICertsHandlerTestSuite) Test_getCertsCommand() {
+	t := s.T()
+	config := Config{}
+	command := getCertsCommand(&config, s.logger)
+	assert.Equal(t, "certs", command.Name)
+	assert.Len(t, command.Subcommands, 2)
+	ensureCLICommand(t, command.Subcommands[0], []string{"generate"}, append(getCertsFlags(), getFlagHosts()))
+	ensureCLICommand(t, command.Subcommands[1], []string{"trust"}, getCertsFlags())
+}
+
+func (s *CLICertsHandlerTestSuite) Test_getCertsFlags() {
+	ensureCLIFlags(s.T(), []string{"dir", "project-dir"}, getCertsFlags())
+}
+
+func (s *CLICertsHandlerTestSuite) Test_getCertsAction_generate() {
+	t := s.T()
+	config := Config{}
+	app := cli.NewApp()
+	app.Commands = []cli.Command{getCertsCommand(&config, s.logger)}
+	assert.Nil(t, app.Run([]string{"godev", "certs", "generate", "--dir", s.directory, "--host", "api.test"}))
+	assert.True(t, config.RunCerts)
+	assert.Equal(t, "panic", config.LogLevel.String())
+	certsDirectory := path.Join(s.directory, DefaultProjectDirectory, ProjectCertsDirectoryName)
+	assert.True(t, fileExists(path.Join(certsDirectory, CertsCertFileName)))
+	assert.Contains(t, s.logs.String(), "generated a certificate for [api.test]")
+	assert.Contains(t, s.logs.String(), path.Join(certsDirectory, CertsCAFileName))
+}
@@ -0,0 +1,50 @@
+package godev
+
+import (
+	"path"
+
+	"github.com/urfave/cli"
+)
+
+func getCleanCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getCleanAction(config, logger),
+		Description: "remove the project directory holding godev's caches, run history and lock files, the certificates of godev certs are kept",
+		Flags:       getCleanFlags(),
+		Name:        "clean",
+		Usage:       "remove godev's caches, run history and lock files",
+	}
+}
+
+func getCleanFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagProjectDirectory(),
+		getFlagWorkDirectory(),
+	}
+}
+
+func getCleanAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunClean = true
+		config.ProjectDirectory = c.String("project-dir")
+		config.WorkDirectory = c.String("dir")
+		if !path.IsAbs(config.ProjectDirectory) {
+			config.ProjectDirectory = path.Join(config.WorkDirectory, config.ProjectDirectory)
+		}
+		config.interpretLogLevel()
+		if !directoryExists(config.ProjectDirectory) {
+			logger.Infof("nothing to clean at '%s'", config.ProjectDirectory)
+			return nil
+		}
+		project := InitProjectDirectory(&ProjectDirectoryConfig{Path: config.ProjectDirectory})
+		if err := project.Clean(config.WorkDirectory); err != nil {
+			return err
+		}
+		if certsDirectory := project.GetPath(ProjectCertsDirectoryName); directoryExists(certsDirectory) {
+			logger.Infof("removed '%s' except for the certificates in '%s'", config.ProjectDirectory, certsDirectory)
+			return nil
+		}
+		logger.Infof("removed '%s'", config.ProjectDirectory)
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLICleanHandlerTestSuite struct {
+	suite.Suite
+	mockApp   *cli.App
+	directory string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestCLICleanHandler(t *testing.T) {
+	suite.Run(t, new(CLICleanHandlerTestSuite))
+}
+
+func (s *CLICleanHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getCleanFlags()
+	directory, err := ioutil.TempDir("", "godev-cli-clean")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getCleanAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLICleanHandlerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLICleanHandlerTestSuite) Test_getCleanCommand() {
+	config := Config{}
+	command := getCleanCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"clean"}, getCleanFlags())
+}
+
+func (s *CLICleanHandlerTestSuite) Test_getCleanFlags() {
+	ensureCLIFlags(s.T(), []string{"dir", "project-dir"}, getCleanFlags())
+}
+
+func (s *CLICleanHandlerTestSuite) Test_getCleanAction() {
+	t := s.T()
+	projectDirectory := path.Join(s.directory, ".godev")
+	assert.Nil(t, InitProjectDirectory(&ProjectDirectoryConfig{Path: projectDirectory}).Init())
+	config := Config{}
+	s.mockApp.Action = getCleanAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-clean", "--dir", s.directory}))
+	assert.True(t, config.RunClean)
+	assert.False(t, directoryExists(projectDirectory))
+	assert.Contains(t, s.logs.String(), "removed '"+projectDirectory+"'")
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-clean", "--dir", s.directory}))
+	assert.Contains(t, s.logs.String(), "nothing to clean")
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-clean", "--dir", s.directory, "--project-dir", s.directory}), "expected the work directory to not be removed")
+	assert.True(t, directoryExists(s.directory))
+}
@@ -0,0 +1,61 @@
+package godev
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+func getCoverageCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getCoverageAction(config, logger),
+		ArgsUsage:   "<profiles...>",
+		Description: "merge the coverage profiles <profiles...> produced by go test -coverprofile and print the total coverage",
+		Flags:       getCoverageFlags(),
+		Name:        "coverage",
+		Usage:       "merge coverage profiles",
+	}
+}
+
+func getCoverageFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagCoverProfile(),
+	}
+}
+
+// getCoverageAction skips profiles which do not exist so that a shard
+// which failed to compile does not prevent the others from being merged
+func getCoverageAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunCoverage = true
+		config.interpretLogLevel()
+		if len(c.Args()) == 0 {
+			return errors.New("specify the coverage profiles to merge")
+		}
+		merged := &CoverageProfile{}
+		mergedCount := 0
+		for _, profilePath := range c.Args() {
+			if !fileExists(profilePath) {
+				logger.Infof("skipping '%s' which does not exist", profilePath)
+				continue
+			}
+			profile, err := LoadCoverageProfile(profilePath)
+			if err != nil {
+				return err
+			}
+			if err := merged.Merge(profile); err != nil {
+				return err
+			}
+			mergedCount++
+		}
+		if mergedCount == 0 {
+			return fmt.Errorf("none of the coverage profiles %v exist", []string(c.Args()))
+		}
+		if err := merged.WriteFile(c.String("coverprofile")); err != nil {
+			return err
+		}
+		logger.Infof("coverage: %.1f%% of statements (merged %v profiles into %s)", merged.GetCoverage(), mergedCount, c.String("coverprofile"))
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLICoverageHandlerTestSuite struct {
+	suite.Suite
+	mockApp   *cli.App
+	directory string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestCLICoverageHandler(t *testing.T) {
+	suite.Run(t, new(CLICoverageHandlerTestSuite))
+}
+
+func (s *CLICoverageHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getCoverageFlags()
+	directory, err := ioutil.TempDir("", "godev-cli-coverage")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getCoverageAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLICoverageHandlerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLICoverageHandlerTestSuite) Test_getCoverageCommand() {
+	config := Config{}
+	command := getCoverageCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"coverage"}, getCoverageFlags())
+}
+
+func (s *CLICoverageHandlerTestSuite) Test_getCoverageFlags() {
+	ensureCLIFlags(s.T(), []string{"coverprofile"}, getCoverageFlags())
+}
+
+func (s *CLICoverageHandlerTestSuite) Test_getCoverageAction() {
+	t := s.T()
+	first := path.Join(s.directory, "c.1.out")
+	second := path.Join(s.directory, "c.2.out")
+	output := path.Join(s.directory, "c.out")
+	ioutil.WriteFile(first, []byte("mode: set\napp/a.go:1.1,2.2 1 1\n"), 0644)
+	ioutil.WriteFile(second, []byte("mode: set\napp/b.go:1.1,2.2 1 0\n"), 0644)
+	config := Config{}
+	s.mockApp.Action = getCoverageAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-coverage", "--coverprofile", output, first, second, path.Join(s.directory, "c.3.out")}))
+	assert.True(t, config.RunCoverage)
+	assert.Contains(t, s.logs.String(), "coverage: 50.0% of statements (merged 2 profiles")
+	merged, err := LoadCoverageProfile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, 50.0, merged.GetCoverage())
+}
+
+func (s *CLICoverageHandlerTestSuite) Test_getCoverageActionWithoutProfiles() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getCoverageAction(&config, s.logger)
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-coverage"}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-coverage", path.Join(s.directory, "missing.out")}))
+}
@@ -0,0 +1,56 @@
+package godev
+
+import (
+	"github.com/urfave/cli"
+)
+
+func getDaemonCommand(config *Config) cli.Command {
+	return cli.Command{
+		Aliases:     []string{"d"},
+		Description: "register godev to run in the background for the project at --dir when you log in",
+		Name:        "daemon",
+		Usage:       "manage godev as a background task",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Action:      getDaemonAction(config, (*Daemon).Install),
+				ArgsUsage:   "[godev flags...]",
+				Description: "register a background task which runs godev with the provided flags",
+				Flags:       getDaemonFlags(),
+				Name:        "install",
+				Usage:       "register a background task for the project",
+			},
+			cli.Command{
+				Action:      getDaemonAction(config, (*Daemon).Uninstall),
+				Description: "remove the background task registered by the install sub-command",
+				Flags:       getDaemonFlags(),
+				Name:        "uninstall",
+				Usage:       "remove the background task for the project",
+			},
+		},
+	}
+}
+
+func getDaemonFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagWatchDirectory(),
+		getFlagWorkDirectory(),
+	}
+}
+
+func getDaemonAction(config *Config, operation func(*Daemon) error) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunDaemon = true
+		config.WatchDirectory = c.String("watch")
+		config.WorkDirectory = c.String("dir")
+		config.interpretLogLevel()
+		daemon, err := InitDaemon(&DaemonConfig{
+			Arguments:      c.Args(),
+			WatchDirectory: config.WatchDirectory,
+			WorkDirectory:  config.WorkDirectory,
+		})
+		if err != nil {
+			return err
+		}
+		return operation(daemon)
+	}
+}
@@ -0,0 +1,59 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIDaemonHandlerTestSuite struct {
+	suite.Suite
+}
+
+func TestCLIDaemonHandler(t *testing.T) {
+	suite.Run(t, new(CLIDaemonHandlerTestSuite))
+}
+
+func (s *CLIDaemonHandlerTestSuite) Test_getDaemonCommand() {
+	t := s.T()
+	config := Config{}
+	command := getDaemonCommand(&config)
+	assert.Equal(t, "daemon", command.Name)
+	assert.Contains(t, command.Aliases, "d")
+	assert.Len(t, command.Subcommands, 2)
+	for index, name := range []string{"install", "uninstall"} {
+		ensureCLICommand(t, command.Subcommands[index], []string{name}, getDaemonFlags())
+	}
+}
+
+func (s *CLIDaemonHandlerTestSuite) Test_getDaemonFlags() {
+	ensureCLIFlags(s.T(),
+		[]string{
+			"dir",
+			"watch",
+		},
+		getDaemonFlags(),
+	)
+}
+
+func (s *CLIDaemonHandlerTestSuite) Test_getDaemonAction() {
+	t := s.T()
+	config := Config{}
+	var received *Daemon
+	command := getDaemonCommand(&config)
+	command.Subcommands[0].Action = getDaemonAction(&config, func(daemon *Daemon) error {
+		received = daemon
+		return nil
+	})
+	app := cli.NewApp()
+	app.Commands = []cli.Command{command}
+	err := app.Run([]string{"godev", "daemon", "install", "--", "--exec", "echo"})
+	assert.Nil(t, err)
+	assert.True(t, config.RunDaemon)
+	assert.Equal(t, "panic", config.LogLevel.String())
+	assert.NotNil(t, received)
+	assert.Equal(t, []string{"--exec", "echo"}, received.config.Arguments)
+	assert.Equal(t, getCurrentWorkingDirectory(), received.config.WorkDirectory)
+}
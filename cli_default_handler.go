@@ -1,6 +1,8 @@
-package main
+package godev
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 
 	shellquote "github.com/kballard/go-shellquote"
@@ -9,18 +11,97 @@ import (
 
 func getDefaultFlags() []cli.Flag {
 	return []cli.Flag{
+		getFlagAllMains(),
+		getFlagBuildCommand(),
 		getFlagBuildOutput(),
+		getFlagBuildReport(),
+		getFlagCheck(),
+		getFlagChildLogFormat(),
+		getFlagChildLogLevel(),
 		getFlagCommandArguments(),
 		getFlagCommandsDelimiter(),
+		getFlagConfigFile(),
+		getFlagContainerRuntime(),
+		getFlagControlAddress(),
+		getFlagDebug(),
+		getFlagDebugAddress(),
+		getFlagDocsAddress(),
+		getFlagEnvFile(),
 		getFlagEnvVars(),
+		getFlagExcludePatterns(),
+		getFlagFollowSymlinks(),
 		getFlagExecGroups(),
 		getFlagFileExtensions(),
+		getFlagForwardedPorts(),
+		getFlagGCFlags(),
+		getFlagGenerate(),
+		getFlagGenerateInputs(),
+		getFlagIgnoreBinaryFiles(),
 		getFlagIgnoredNames(),
+		getFlagIncludePatterns(),
+		getFlagIsolateNetwork(),
+		getFlagIsolateRuns(),
+		getFlagKillTimeout(),
+		getFlagLDFlags(),
+		getFlagLocale(),
+		getFlagLogLevel(),
+		getFlagLogOutput(),
+		getFlagMake(),
+		getFlagManual(),
+		getFlagMaxFileSize(),
+		getFlagMaxProcs(),
+		getFlagMaxWarnings(),
+		getFlagMinIntervals(),
+		getFlagMock(),
+		getFlagNoDetect(),
+		getFlagNoNewPrivileges(),
+		getFlagNoPrefix(),
+		getFlagNotify(),
+		getFlagOnce(),
+		getFlagOnlyGroups(),
+		getFlagPlugin(),
+		getFlagPolicy(),
+		getFlagPollFallback(),
+		getFlagPollInterval(),
+		getFlagPostHook(),
+		getFlagPreHook(),
+		getFlagProfile(),
+		getFlagProjectDirectory(),
+		getFlagProxy(),
+		getFlagPublish(),
+		getFlagPty(),
+		getFlagRace(),
 		getFlagRate(),
+		getFlagRawOutput(),
+		getFlagReadyPattern(),
+		getFlagRecordEvents(),
+		getFlagRecordOutput(),
+		getFlagRecordRequests(),
+		getFlagReplayEvents(),
+		getFlagRestart(),
+		getFlagRestartLimit(),
+		getFlagRoute(),
+		getFlagRunCommand(),
+		getFlagRunMain(),
+		getFlagSelfReload(),
+		getFlagShareWorktree(),
+		getFlagShell(),
 		getFlagSilent(),
+		getFlagSkipGroups(),
+		getFlagSnapshotTimeout(),
+		getFlagStampVersion(),
+		getFlagStateDirectory(),
+		getFlagStatusLine(),
+		getFlagStopSignal(),
 		getFlagSuperVerboseLogs(),
+		getFlagTagRuns(),
+		getFlagTags(),
+		getFlagTimeout(),
+		getFlagUseGitignore(),
+		getFlagUser(),
 		getFlagVerboseLogs(),
 		getFlagWatchDirectory(),
+		getFlagWatchEvents(),
 		getFlagWorkDirectory(),
 	}
 }
@@ -29,19 +110,213 @@ func getDefaultAction(config *Config) cli.ActionFunc {
 	return func(c *cli.Context) error {
 		var err error
 		config.RunDefault = true
+		config.AllMains = c.Bool("all-mains")
+		config.BuildCommand = c.String("build-cmd")
 		config.BuildOutput = c.String("output")
+		config.BuildReport = c.Bool("build-report")
+		config.BuildTags = c.String("tags")
+		config.GCFlags = c.String("gcflags")
+		config.GenerateInputs = c.StringSlice("generate-input")
+		if err := validatePatterns(config.GenerateInputs); err != nil {
+			return err
+		}
+		config.Generate = c.Bool("generate") || len(config.GenerateInputs) > 0
+		config.LDFlags = c.String("ldflags")
+		config.Race = c.Bool("race")
+		config.ShareWorktree = c.Bool("share-worktree")
+		config.StampVersion = c.Bool("stamp-version")
+		config.RunCheck = c.Bool("check")
+		config.ChildLogFormat = LogParser(c.String("child-log-format"))
+		if err := config.ChildLogFormat.IsValid(); err != nil {
+			return err
+		}
+		config.ChildLogLevel = LogLevel(c.String("child-log-level"))
 		if config.CommandArguments, err = shellquote.Split(c.String("args")); err != nil {
-			panic(err)
+			return fmt.Errorf("unable to parse --args: %s", err)
 		}
 		config.CommandsDelimiter = c.String("exec-delim")
+		config.ContainerRuntime = c.String("container-runtime")
+		config.ControlAddress = c.String("control")
+		config.DocsAddress = c.String("docs")
+		config.Debug = c.Bool("debug")
+		config.DebugAddress = c.String("debug-address")
+		config.EnvFile = c.String("env-file")
 		config.EnvVars = c.StringSlice("env")
-		config.ExecGroups = c.StringSlice("exec")
+		config.ExcludePatterns = c.StringSlice("exclude")
+		config.UseGitignore = c.Bool("use-gitignore")
+		config.FollowSymlinks = c.Bool("follow-symlinks")
+		if err := validatePatterns(config.ExcludePatterns); err != nil {
+			return err
+		}
+		config.ExecGroups = getExecGroups(c)
+		if len(c.String("make")) > 0 {
+			if len(config.ExecGroups) > 0 {
+				return fmt.Errorf("--make cannot be used with --exec")
+			}
+			config.MakeTargets = strings.Split(c.String("make"), ",")
+		}
+		for _, execGroup := range config.ExecGroups {
+			if err := validateExecutionGroup(execGroup, config.CommandsDelimiter); err != nil {
+				return err
+			}
+		}
 		config.FileExtensions = strings.Split(c.String("exts"), ",")
+		config.WatchEvents = strings.Split(c.String("watch-events"), ",")
+		if _, err := ParseWatcherOperations(config.WatchEvents); err != nil {
+			return err
+		}
+		if config.ForwardedPorts, err = parsePortForwards(c.StringSlice("forward-port")); err != nil {
+			return err
+		}
+		config.IgnoreBinaryFiles = c.Bool("ignore-binary")
 		config.IgnoredNames = strings.Split(c.String("ignore"), ",")
+		config.IncludePatterns = c.StringSlice("include")
+		if err := validatePatterns(config.IncludePatterns); err != nil {
+			return err
+		}
+		config.IsolateNetwork = c.Bool("isolate-network")
+		if len(config.ForwardedPorts) > 0 && !config.IsolateNetwork {
+			return fmt.Errorf("--forward-port can only be used with --isolate-network")
+		}
+		if len(c.String("max-file-size")) > 0 {
+			if config.MaxFileSize, err = parseByteSize(c.String("max-file-size")); err != nil {
+				return err
+			}
+		}
+		if config.MaxProcs = c.Int("max-procs"); config.MaxProcs < 0 {
+			return fmt.Errorf("--max-procs cannot be negative")
+		}
+		config.MaxWarnings = c.Int("max-warnings")
+		if config.MinIntervals, err = parseGroupDurations(c.StringSlice("min-interval")); err != nil {
+			return err
+		}
+		config.MockFiles = c.StringSlice("mock")
+		config.NoDetect = c.Bool("no-detect")
+		config.NoNewPrivileges = c.Bool("no-new-privs")
+		config.NoPrefix = c.Bool("no-prefix")
+		config.NotifyAddresses = c.StringSlice("notify")
+		config.OnlyGroups = c.StringSlice("only-group")
+		config.SkipGroups = c.StringSlice("skip-group")
+		config.Plugins = c.StringSlice("plugin")
+		if config.Policy, err = ResolvePolicy(c.String("policy")); err != nil {
+			return err
+		}
+		config.PollFallback = c.Duration("poll-fallback")
+		config.PollInterval = c.Duration("poll")
 		config.Rate = c.Duration("rate")
+		config.RawOutput = c.Bool("raw-output")
+		config.Pty = c.Bool("pty")
+		if config.KillTimeout = c.Duration("kill-timeout"); config.KillTimeout <= 0 {
+			return fmt.Errorf("--kill-timeout has to be positive")
+		}
+		config.StatusLine = c.Bool("status-line")
+		if config.StopSignal, err = parseStopSignal(c.String("stop-signal")); err != nil {
+			return fmt.Errorf("invalid --stop-signal: %s", err)
+		}
+		config.IsolateRuns = c.Bool("isolate-runs")
+		config.PreHook = c.String("pre-hook")
+		if err := validateHookCommand(config.PreHook); err != nil {
+			return fmt.Errorf("invalid --pre-hook: %s", err)
+		}
+		config.PostHook = c.String("post-hook")
+		if err := validateHookCommand(config.PostHook); err != nil {
+			return fmt.Errorf("invalid --post-hook: %s", err)
+		}
+		config.Manual = c.Bool("manual")
+		if config.Once = c.Bool("once"); config.Once && config.Manual {
+			return fmt.Errorf("--once cannot be used with --manual")
+		}
+		config.TagRuns = c.Bool("tag-runs")
+		if config.Timeout = c.Duration("timeout"); config.Timeout < 0 {
+			return fmt.Errorf("--timeout cannot be negative")
+		}
+		config.Restart = c.Bool("restart")
+		if config.RestartLimit = c.Int("restart-limit"); config.RestartLimit <= 0 {
+			return fmt.Errorf("--restart-limit has to be positive")
+		}
+		config.RecordEvents = c.String("record")
+		config.ReplayEvents = c.String("replay")
+		if len(c.String("ready-pattern")) > 0 {
+			if config.ReadyPattern, err = regexp.Compile(c.String("ready-pattern")); err != nil {
+				return fmt.Errorf("invalid --ready-pattern: %s", err)
+			}
+		}
+		config.RecordOutput = c.Bool("record-output")
+		config.RecordRequests = c.Int("record-requests")
+		if len(c.String("proxy")) > 0 {
+			proxyPorts, err := parsePortForwards([]string{c.String("proxy")})
+			if err != nil {
+				return err
+			}
+			config.Proxy = &proxyPorts[0]
+		}
+		if config.RecordRequests < 0 {
+			return fmt.Errorf("--record-requests cannot be negative")
+		} else if config.RecordRequests > 0 && config.Proxy == nil {
+			return fmt.Errorf("--record-requests can only be used with --proxy")
+		}
+		if config.Routes, err = parseGroupRoutes(c.StringSlice("route")); err != nil {
+			return err
+		}
+		config.RunCommand = c.String("run-cmd")
+		config.RunnableMains = c.StringSlice("run-main")
+		config.SelfReload = c.Bool("self-reload")
+		config.Shell = c.Bool("shell")
+		config.SnapshotTimeout = c.Duration("snapshot-timeout")
+		config.StateDirectory = c.String("state-dir")
+		config.User = c.String("user")
 		config.WatchDirectory = c.String("watch")
 		config.WorkDirectory = c.String("dir")
+		config.Locale = c.String("locale")
+		config.LogLevel = LogLevel(c.String("log-level"))
+		config.LogOutputs = c.StringSlice("log-output")
+		if len(config.LogLevel) > 0 {
+			if err := config.LogLevel.IsValid(); err != nil {
+				return err
+			}
+		}
+		config.Profile = c.String("profile")
+		config.ProjectDirectory = c.String("project-dir")
+		config.PublishTarget = c.String("publish")
+		if err := validatePublishDestination(config.PublishTarget); err != nil {
+			return err
+		}
+		if err := applyConfigFile(c, config); err != nil {
+			return err
+		}
+		if _, err := config.GetProfile(); err != nil {
+			return err
+		}
+		if config.Debug && (len(config.ExecGroups) > 0 || len(config.MakeTargets) > 0 || config.AllMains || len(config.BuildCommand) > 0 || len(config.RunCommand) > 0) {
+			return fmt.Errorf("--debug builds and runs the default pipeline and cannot be used with --exec, --make, --all-mains, --build-cmd or --run-cmd")
+		}
+		if err := config.resolveMainPackages(); err != nil {
+			return err
+		}
+		if config.ShareWorktree {
+			config.Worktree = DetectGitWorktree(config.WorkDirectory)
+		}
 		config.assignDefaults()
+		if len(config.MakeTargets) > 0 {
+			if err := validateMakeTargets(config.WorkDirectory, config.MakeTargets); err != nil {
+				return err
+			}
+		}
+		if len(config.EnvFile) > 0 {
+			if _, err := LoadEnvironmentFile(config.EnvFile); err != nil {
+				return err
+			}
+		}
+		for _, mockFile := range config.MockFiles {
+			if _, err := LoadMockFile(mockFile); err != nil {
+				return err
+			}
+		}
+		if len(config.ReplayEvents) > 0 {
+			if _, err := loadWatcherRecords(config.ReplayEvents); err != nil {
+				return err
+			}
+		}
 		config.LogSilent = c.Bool("silent")
 		config.LogVerbose = c.Bool("verbose")
 		config.LogSuperVerbose = c.Bool("vverbose")
@@ -1,6 +1,8 @@
-package main
+package godev
 
 import (
+	"io/ioutil"
+	"os"
 	"path"
 	"testing"
 	"time"
@@ -29,19 +31,98 @@ func (s *CLIDefaultHandlerTestSuite) SetupTest() {
 func (s *CLIDefaultHandlerTestSuite) Test_getDefaultFlags() {
 	ensureCLIFlags(s.T(),
 		[]string{
+			"all-mains",
 			"args",
+			"build-cmd",
+			"build-report",
+			"check",
+			"child-log-format",
+			"child-log-level",
+			"config",
+			"container-runtime",
+			"control",
+			"debug",
+			"debug-address",
 			"dir",
+			"docs",
 			"env",
+			"env-file",
+			"exclude",
 			"exec-delim",
 			"exec",
 			"exts",
+			"follow-symlinks",
+			"gcflags",
+			"generate",
+			"generate-input",
+			"forward-port",
 			"ignore",
+			"ignore-binary",
+			"include",
+			"isolate-network",
+			"isolate-runs",
+			"kill-timeout",
+			"ldflags",
+			"locale",
+			"log-level",
+			"log-output",
+			"make",
+			"manual",
+			"max-file-size",
+			"max-procs",
+			"max-warnings",
+			"min-interval",
+			"mock",
+			"no-detect",
+			"no-new-privs",
+			"no-prefix",
+			"notify",
+			"once",
+			"only-group",
 			"output",
+			"plugin",
+			"policy",
+			"poll-fallback",
+			"poll",
+			"post-hook",
+			"pre-hook",
+			"profile",
+			"project-dir",
+			"proxy",
+			"publish",
+			"pty",
+			"race",
 			"rate",
+			"raw-output",
+			"ready-pattern",
+			"record",
+			"record-output",
+			"record-requests",
+			"replay",
+			"restart",
+			"restart-limit",
+			"route",
+			"run-cmd",
+			"run-main",
+			"self-reload",
+			"share-worktree",
+			"shell",
 			"silent",
+			"skip-group",
+			"snapshot-timeout",
+			"stamp-version",
+			"state-dir",
+			"status-line",
+			"stop-signal",
+			"tag-runs",
+			"tags",
+			"timeout",
+			"use-gitignore",
+			"user",
 			"verbose",
 			"vverbose",
 			"watch",
+			"watch-events",
 		},
 		getDefaultFlags(),
 	)
@@ -57,7 +138,7 @@ func (s *CLIDefaultHandlerTestSuite) Test_getDefaultAction() {
 		assert.Equal(t, pathToBinary, config.BuildOutput)
 		assert.Equal(t, ",", config.CommandsDelimiter)
 		assert.Equal(t, []string{}, []string(config.EnvVars))
-		assert.Equal(t, []string{"go mod vendor", "go build -o " + pathToBinary, pathToBinary}, []string(config.ExecGroups))
+		assert.Equal(t, []string{"go mod download", "go build -o " + pathToBinary, pathToBinary}, []string(config.ExecGroups))
 		assert.Equal(t, []string{"go", "Makefile"}, []string(config.FileExtensions))
 		assert.Equal(t, []string{"bin", "vendor"}, []string(config.IgnoredNames))
 		assert.Equal(t, 2*time.Second, config.Rate)
@@ -68,3 +149,100 @@ func (s *CLIDefaultHandlerTestSuite) Test_getDefaultAction() {
 		panic(err)
 	}
 }
+
+func (s *CLIDefaultHandlerTestSuite) Test_getDefaultActionWithConfigFile() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-cli")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "godev.yaml"), []byte("exts: [go, proto]\nrate: 500ms\n"), 0644))
+	config := Config{}
+	s.mockApp.Action = getDefaultAction(&config)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run", "--watch", directory, "--dir", directory, "--rate", "5s"}))
+	assert.Equal(t, path.Join(directory, "godev.yaml"), config.ConfigFile)
+	assert.Equal(t, []string{"go", "proto"}, []string(config.FileExtensions))
+	assert.Equal(t, 5*time.Second, config.Rate)
+}
+
+func (s *CLIDefaultHandlerTestSuite) Test_getDefaultActionWithMake() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-cli")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "Makefile"), []byte("build:\n\tgo build\ntest: build\n\tgo test\n"), 0644))
+	config := Config{}
+	s.mockApp.Action = getDefaultAction(&config)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run", "--dir", directory, "--watch", directory, "--make", "build,test"}))
+	assert.Equal(t, []string{"make build", "make test"}, []string(config.ExecGroups))
+
+	err = s.mockApp.Run([]string{"test-run", "--dir", directory, "--watch", directory, "--make", "lint"})
+	assert.Contains(t, err.Error(), "available targets are: build, test")
+	err = s.mockApp.Run([]string{"test-run", "--dir", directory, "--watch", directory, "--make", "build", "--exec", "go vet ./..."})
+	assert.Contains(t, err.Error(), "--make cannot be used with --exec")
+}
+
+func (s *CLIDefaultHandlerTestSuite) Test_getDefaultActionWithBuildFlags() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getDefaultAction(&config)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run", "--no-detect", "--race", "--tags", "a,b", "--ldflags", "-X main.version=dev"}))
+	assert.True(t, config.Race)
+	assert.Equal(t, "a,b", config.BuildTags)
+	assert.Equal(t, "-X main.version=dev", config.LDFlags)
+	assert.Contains(t, config.ExecGroups[len(config.ExecGroups)-2], "go build -race -tags=a,b '-ldflags=-X main.version=dev' -o ")
+}
+
+func (s *CLIDefaultHandlerTestSuite) Test_getDefaultActionWithInvalidArgs() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getDefaultAction(&config)
+	err := s.mockApp.Run([]string{"test-run", "--no-detect", "--args", "'unterminated"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unable to parse --args")
+}
+
+func (s *CLIDefaultHandlerTestSuite) Test_getDefaultActionWithDebug() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getDefaultAction(&config)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run", "--debug", "--no-detect"}))
+	assert.True(t, config.Debug)
+	assert.Equal(t, DefaultDebugAddress, config.DebugAddress)
+	assert.Contains(t, config.ExecGroups[len(config.ExecGroups)-1], "dlv exec --headless --listen=127.0.0.1:2345")
+
+	for _, flags := range [][]string{{"--exec", "go run ."}, {"--run-cmd", "bin/app serve"}, {"--all-mains"}} {
+		err := s.mockApp.Run(append([]string{"test-run", "--debug"}, flags...))
+		assert.NotNil(t, err, "expected --debug not to be usable with %v", flags)
+	}
+}
+
+func (s *CLIDefaultHandlerTestSuite) Test_getDefaultActionWithEnvironment() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-cli")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "godev.yaml"), []byte("exts: [go, proto]\nignore: [bin]\nrate: 500ms\n"), 0644))
+	environment := map[string]string{
+		"GODEV_EXEC":      "go build -o bin/app,go vet ./...;bin/app",
+		"GODEV_EXTS":      "go,mod",
+		"GODEV_LOG_LEVEL": "warn",
+		"GODEV_RATE":      "3s",
+		"GODEV_WATCH":     directory,
+	}
+	for key, value := range environment {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	config := Config{}
+	s.mockApp.Action = getDefaultAction(&config)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run", "--rate", "5s"}))
+	assert.Equal(t, directory, config.WatchDirectory)
+	assert.Equal(t, []string{"go build -o bin/app,go vet ./...", "bin/app"}, []string(config.ExecGroups))
+	assert.Equal(t, []string{"go", "mod"}, []string(config.FileExtensions), "expected the environment to override the config file")
+	assert.Equal(t, []string{"bin"}, []string(config.IgnoredNames), "expected the config file to override defaults")
+	assert.Equal(t, 5*time.Second, config.Rate, "expected flags to override the environment")
+	assert.Equal(t, "warn", string(config.LogLevel))
+
+	os.Setenv("GODEV_LOG_LEVEL", "loud")
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run"}))
+}
@@ -0,0 +1,141 @@
+package godev
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+func getHistoryCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getHistoryAction(config, logger, listHistory),
+		Description: "list the pipeline runs recorded in the project directory of the project at --dir, runs whose output was recorded can be compared with 'diff'",
+		Flags:       getHistoryFlags(),
+		Name:        "history",
+		Usage:       "list and compare pipeline runs",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Action:      getHistoryAction(config, logger, diffHistory),
+				ArgsUsage:   "<runA> <runB>",
+				Description: "compare the test results and the output of the runs <runA> and <runB>, which are run IDs, 'last' or 'last~N' for the run N runs before the last one",
+				Flags:       getHistoryFlags(),
+				Name:        "diff",
+				Usage:       "compare the output of two runs",
+			},
+		},
+	}
+}
+
+func getHistoryFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagProjectDirectory(),
+		getFlagWorkDirectory(),
+	}
+}
+
+func getHistoryAction(config *Config, logger *Logger, operation func(*ProjectDirectory, []string, *Logger) error) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunHistory = true
+		config.ProjectDirectory = c.String("project-dir")
+		config.WorkDirectory = c.String("dir")
+		if !path.IsAbs(config.ProjectDirectory) {
+			config.ProjectDirectory = path.Join(config.WorkDirectory, config.ProjectDirectory)
+		}
+		config.interpretLogLevel()
+		project := InitProjectDirectory(&ProjectDirectoryConfig{
+			LogLevel: config.LogLevel,
+			Path:     config.ProjectDirectory,
+		})
+		return operation(project, c.Args(), logger)
+	}
+}
+
+func listHistory(project *ProjectDirectory, arguments []string, logger *Logger) error {
+	history, err := project.GetHistory()
+	if err != nil {
+		return fmt.Errorf("unable to read the run history: %s", err)
+	}
+	for _, entry := range history {
+		logger.Info(formatRunHistoryEntry(&entry))
+	}
+	return nil
+}
+
+func diffHistory(project *ProjectDirectory, arguments []string, logger *Logger) error {
+	if len(arguments) != 2 {
+		return errors.New("specify the two runs to compare (eg. godev history diff last~1 last)")
+	}
+	history, err := project.GetHistory()
+	if err != nil {
+		return fmt.Errorf("unable to read the run history: %s", err)
+	}
+	var entries []*RunHistoryEntry
+	var outputs []*RunOutput
+	for _, reference := range arguments {
+		index, err := parseRunReference(history, reference)
+		if err != nil {
+			return err
+		}
+		entry := &history[index]
+		if !entry.Recorded {
+			return fmt.Errorf("run '%s' has no recorded output (run godev with --record-output)", entry.ID)
+		}
+		output, err := project.LoadRunOutput(entry.ID)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		outputs = append(outputs, output)
+	}
+	logger.Info(Color("red", "--- "+formatRunHistoryEntry(entries[0])))
+	logger.Info(Color("green", "+++ "+formatRunHistoryEntry(entries[1])))
+	diff := DiffRuns(outputs[0], outputs[1])
+	if diff.IsEmpty() {
+		logger.Info("no differences")
+		return nil
+	}
+	logRunDiffSection(logger, "tests", diff.Tests)
+	logRunDiffSection(logger, "stdout", diff.Stdout)
+	logRunDiffSection(logger, "stderr", diff.Stderr)
+	return nil
+}
+
+// formatRunHistoryEntry renders :entry as a single line for humans
+func formatRunHistoryEntry(entry *RunHistoryEntry) string {
+	result := "passed"
+	if entry.Failed {
+		result = "failed"
+	}
+	summary := []string{
+		entry.ID,
+		fmt.Sprintf("pipeline %v %s in %s", entry.Pipeline, result, entry.Duration),
+	}
+	if entry.Warnings > 0 {
+		summary = append(summary, fmt.Sprintf("%v warnings", entry.Warnings))
+	}
+	if entry.Recorded {
+		summary = append(summary, "recorded")
+	}
+	return strings.Join(summary, ", ")
+}
+
+func logRunDiffSection(logger *Logger, name string, changes []string) {
+	if len(changes) == 0 {
+		logger.Infof("%s: no differences", name)
+		return
+	}
+	logger.Infof("%s:", name)
+	for _, change := range changes {
+		switch change[0] {
+		case '-':
+			logger.Info(Color("red", "  "+change))
+		case '+':
+			logger.Info(Color("green", "  "+change))
+		default:
+			logger.Info("  " + change)
+		}
+	}
+}
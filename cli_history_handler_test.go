@@ -0,0 +1,107 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIHistoryHandlerTestSuite struct {
+	suite.Suite
+	directory string
+	logs      bytes.Buffer
+	logger    *Logger
+	project   *ProjectDirectory
+}
+
+func TestCLIHistoryHandler(t *testing.T) {
+	suite.Run(t, new(CLIHistoryHandlerTestSuite))
+}
+
+func (s *CLIHistoryHandlerTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-cli-history")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getHistoryAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+	s.project = InitProjectDirectory(&ProjectDirectoryConfig{Path: path.Join(directory, DefaultProjectDirectory)})
+	assert.Nil(s.T(), s.project.Init())
+}
+
+func (s *CLIHistoryHandlerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLIHistoryHandlerTestSuite) recordRun(id string, failed bool, stdout string) {
+	recorder := InitRunRecorder()
+	recorder.Stdout.Write([]byte(stdout))
+	assert.Nil(s.T(), s.project.SaveRunOutput(id, recorder))
+	assert.Nil(s.T(), s.project.AppendHistory(&RunHistoryEntry{ID: id, Duration: "1.2s", Failed: failed, Recorded: true}))
+}
+
+func (s *CLIHistoryHandlerTestSuite) runHistory(arguments ...string) (*Config, error) {
+	config := &Config{}
+	app := cli.NewApp()
+	app.Commands = []cli.Command{getHistoryCommand(config, s.logger)}
+	return config, app.Run(append([]string{"godev", "history"}, arguments...))
+}
+
+func (s *CLIHistoryHandlerTestSuite) Test_getHistoryCommand() {
+	t := s.T()
+	command := getHistoryCommand(&Config{}, s.logger)
+	ensureCLICommand(t, command, []string{"history"}, getHistoryFlags())
+	assert.Len(t, command.Subcommands, 1)
+	ensureCLICommand(t, command.Subcommands[0], []string{"diff"}, getHistoryFlags())
+}
+
+func (s *CLIHistoryHandlerTestSuite) Test_getHistoryFlags() {
+	ensureCLIFlags(s.T(), []string{"dir", "project-dir"}, getHistoryFlags())
+}
+
+func (s *CLIHistoryHandlerTestSuite) Test_getHistoryAction_list() {
+	t := s.T()
+	assert.Nil(t, s.project.AppendHistory(&RunHistoryEntry{ID: "20190304-151617-1", Pipeline: 1, Duration: "2s", Warnings: 3}))
+	s.recordRun("20190304-151620-2", true, "")
+	config, err := s.runHistory("--dir", s.directory)
+	assert.Nil(t, err)
+	assert.True(t, config.RunHistory)
+	assert.Equal(t, "panic", config.LogLevel.String())
+	assert.Contains(t, s.logs.String(), "20190304-151617-1, pipeline 1 passed in 2s, 3 warnings\n")
+	assert.Contains(t, s.logs.String(), "20190304-151620-2, pipeline 0 failed in 1.2s, recorded\n")
+}
+
+func (s *CLIHistoryHandlerTestSuite) Test_getHistoryAction_diff() {
+	t := s.T()
+	s.recordRun("20190304-151617-1", true, "--- FAIL: TestSum (0.00s)\nFAIL\texample.com/app\t0.01s\n")
+	s.recordRun("20190304-151620-2", false, "ok  \texample.com/app\t0.02s\n")
+	_, err := s.runHistory("diff", "--dir", s.directory, "last~1", "last")
+	assert.Nil(t, err)
+	assert.Contains(t, s.logs.String(), "--- 20190304-151617-1, pipeline 0 failed in 1.2s, recorded")
+	assert.Contains(t, s.logs.String(), "example.com/app: fail -> ok")
+	assert.Contains(t, s.logs.String(), "example.com/app TestSum: fail -> none")
+	assert.Contains(t, s.logs.String(), "+ok  \texample.com/app")
+	assert.Contains(t, s.logs.String(), "stderr: no differences")
+	s.logs.Reset()
+	_, err = s.runHistory("diff", "--dir", s.directory, "last", "last")
+	assert.Nil(t, err)
+	assert.Contains(t, s.logs.String(), "no differences")
+}
+
+func (s *CLIHistoryHandlerTestSuite) Test_getHistoryAction_diffErrors() {
+	t := s.T()
+	_, err := s.runHistory("diff", "--dir", s.directory, "last")
+	assert.NotNil(t, err, "expected two runs to be required")
+	_, err = s.runHistory("diff", "--dir", s.directory, "last~1", "last")
+	assert.NotNil(t, err, "expected an error without a run history")
+	assert.Nil(t, s.project.AppendHistory(&RunHistoryEntry{ID: "20190304-151617-1"}))
+	s.recordRun("20190304-151620-2", false, "")
+	_, err = s.runHistory("diff", "--dir", s.directory, "20190304-151617-1", "last")
+	assert.EqualError(t, err, "run '20190304-151617-1' has no recorded output (run godev with --record-output)")
+}
@@ -1,4 +1,4 @@
-package main
+package godev
 
 import (
 	"fmt"
@@ -19,6 +19,8 @@ func getInitCommand(config *Config) cli.Command {
 
 func getInitFlags() []cli.Flag {
 	return []cli.Flag{
+		getFlagLocale(),
+		getFlagTemplate(),
 		getFlagWorkDirectory(),
 	}
 }
@@ -26,6 +28,11 @@ func getInitFlags() []cli.Flag {
 func getInitAction(config *Config) cli.ActionFunc {
 	return func(c *cli.Context) error {
 		config.RunInit = true
+		config.InitTemplate = c.String("template")
+		config.Locale = c.String("locale")
+		if _, err := getInitTemplate(config.InitTemplate); err != nil {
+			return err
+		}
 		config.WorkDirectory = c.String("dir")
 		fmt.Println(config.WorkDirectory)
 		config.assignDefaults()
@@ -1,4 +1,4 @@
-package main
+package godev
 
 import (
 	"testing"
@@ -34,6 +34,8 @@ func (s *CLIDefaultHandlerTestSuite) Test_getInitFlags() {
 	ensureCLIFlags(s.T(),
 		[]string{
 			"dir",
+			"locale",
+			"template",
 		},
 		getInitFlags(),
 	)
@@ -50,3 +52,12 @@ func (s *CLIInitHandlerTestSuite) Test_getInitAction() {
 		panic(err)
 	}
 }
+
+func (s *CLIInitHandlerTestSuite) Test_getInitActionWithTemplate() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getInitAction(&config)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-init", "--template", "service"}))
+	assert.Equal(t, "service", config.InitTemplate)
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-init", "--template", "unknown"}))
+}
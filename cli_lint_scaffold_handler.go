@@ -0,0 +1,66 @@
+package godev
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/urfave/cli"
+)
+
+func getLintScaffoldCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getLintScaffoldAction(config, logger, bufio.NewReader(os.Stdin), isTerminal(os.Stdin)),
+		Description: "check the Dockerfile and Makefile at --dir for known issues of the files seeded by init and fix them with --fix or when confirmed",
+		Flags:       getLintScaffoldFlags(),
+		Name:        "lint-scaffold",
+		Usage:       "check and fix known issues of the seeded Dockerfile and Makefile",
+	}
+}
+
+func getLintScaffoldFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagFix(),
+		getFlagWorkDirectory(),
+	}
+}
+
+func getLintScaffoldAction(config *Config, logger *Logger, reader *bufio.Reader, interactive bool) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunLintScaffold = true
+		config.WorkDirectory = c.String("dir")
+		config.interpretLogLevel()
+		findings, err := LintScaffold(config.WorkDirectory, GetScaffoldRules(config.WorkDirectory))
+		if err != nil {
+			return err
+		} else if len(findings) == 0 {
+			logger.Infof("no known issues in the Dockerfile and Makefile at '%s'", config.WorkDirectory)
+			return nil
+		}
+		var filePaths []string
+		for filePath := range findings {
+			filePaths = append(filePaths, filePath)
+		}
+		sort.Strings(filePaths)
+		messages := InitMessages(config.Locale)
+		unfixed := 0
+		for _, filePath := range filePaths {
+			for _, finding := range findings[filePath] {
+				logger.Warn(finding.String())
+			}
+			if c.Bool("fix") || (interactive && confirm(messages, reader, messages.Get(MessageLintScaffoldQuestion, filePath), true, messages.Get(MessageInitRetry))) {
+				if err := FixScaffoldFile(filePath, findings[filePath]); err != nil {
+					return fmt.Errorf("unable to fix '%s': %s", filePath, err)
+				}
+				logger.Infof("fixed %v issue(s) in '%s'", len(findings[filePath]), filePath)
+			} else {
+				unfixed += len(findings[filePath])
+			}
+		}
+		if unfixed > 0 {
+			return fmt.Errorf("%v known issue(s) were not fixed - run 'godev lint-scaffold --fix' to fix them", unfixed)
+		}
+		return nil
+	}
+}
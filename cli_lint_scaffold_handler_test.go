@@ -0,0 +1,76 @@
+package godev
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLILintScaffoldHandlerTestSuite struct {
+	suite.Suite
+	mockApp   *cli.App
+	directory string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestCLILintScaffoldHandler(t *testing.T) {
+	suite.Run(t, new(CLILintScaffoldHandlerTestSuite))
+}
+
+func (s *CLILintScaffoldHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getLintScaffoldFlags()
+	directory, err := ioutil.TempDir("", "godev-cli-lint-scaffold")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	assert.Nil(s.T(), ioutil.WriteFile(path.Join(directory, "Makefile"), []byte(scaffoldMakefileWithIssues), 0644))
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getLintScaffoldAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLILintScaffoldHandlerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLILintScaffoldHandlerTestSuite) Test_getLintScaffoldCommand() {
+	config := Config{}
+	command := getLintScaffoldCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"lint-scaffold"}, getLintScaffoldFlags())
+}
+
+func (s *CLILintScaffoldHandlerTestSuite) Test_getLintScaffoldFlags() {
+	ensureCLIFlags(s.T(), []string{"dir", "fix"}, getLintScaffoldFlags())
+}
+
+func (s *CLILintScaffoldHandlerTestSuite) Test_getLintScaffoldAction() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getLintScaffoldAction(&config, s.logger, bufio.NewReader(strings.NewReader("")), false)
+	err := s.mockApp.Run([]string{"test-run-lint-scaffold", "--dir", s.directory})
+	assert.NotNil(t, err, "expected unfixed issues to fail")
+	assert.Contains(t, err.Error(), "3 known issue(s) were not fixed")
+	assert.True(t, config.RunLintScaffold)
+	assert.Contains(t, s.logs.String(), "(makefile-cgo-typo)")
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-lint-scaffold", "--dir", s.directory, "--fix"}))
+	assert.Contains(t, s.logs.String(), "fixed 3 issue(s)")
+	s.logs.Reset()
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-lint-scaffold", "--dir", s.directory}))
+	assert.Contains(t, s.logs.String(), "no known issues")
+}
+
+func (s *CLILintScaffoldHandlerTestSuite) Test_getLintScaffoldAction_confirmed() {
+	t := s.T()
+	s.mockApp.Action = getLintScaffoldAction(&Config{}, s.logger, bufio.NewReader(strings.NewReader("y\n")), true)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-lint-scaffold", "--dir", s.directory}))
+	assert.Contains(t, s.logs.String(), "fixed 3 issue(s)")
+}
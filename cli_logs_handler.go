@@ -0,0 +1,78 @@
+package godev
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli"
+)
+
+func getLogsCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getLogsAction(config, logger),
+		ArgsUsage:   "[file]",
+		Description: "show the lines of the log of a godev which ran with --tag-runs that belong to a run, the log is read from [file] or from stdin when it is not specified - the runs in the log are listed when --run is not specified",
+		Flags:       getLogsFlags(),
+		Name:        "logs",
+		Usage:       "filter the log of godev by run",
+	}
+}
+
+func getLogsFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagRunFilter(),
+	}
+}
+
+func getLogsAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunLogs = true
+		config.interpretLogLevel()
+		var reader io.Reader = os.Stdin
+		if c.NArg() > 1 {
+			return errors.New("specify at most one log file")
+		} else if c.NArg() == 1 {
+			file, err := os.Open(c.Args().First())
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			reader = file
+		}
+		run := c.Int("run")
+		if run < 0 {
+			return errors.New("--run cannot be negative")
+		} else if run == 0 {
+			return listRunLogs(reader, logger)
+		}
+		count := 0
+		err := scanRunLogs(reader, func(lineRun int, line string) {
+			if lineRun == run {
+				logger.Info(line)
+				count++
+			}
+		})
+		if err != nil {
+			return err
+		} else if count == 0 {
+			return fmt.Errorf("run %v is not in the log (godev tags its log lines with their run when --tag-runs is specified)", run)
+		}
+		return nil
+	}
+}
+
+// listRunLogs logs the runs in :reader with their number of lines
+func listRunLogs(reader io.Reader, logger *Logger) error {
+	runs, counts, err := countRunLogs(reader)
+	if err != nil {
+		return err
+	} else if len(runs) == 0 {
+		return errors.New("the log has no runs (godev tags its log lines with their run when --tag-runs is specified)")
+	}
+	for _, run := range runs {
+		logger.Infof("run %v: %v line(s)", run, counts[run])
+	}
+	return nil
+}
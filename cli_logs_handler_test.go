@@ -0,0 +1,86 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLILogsHandlerTestSuite struct {
+	suite.Suite
+	mockApp   *cli.App
+	directory string
+	logFile   string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestCLILogsHandler(t *testing.T) {
+	suite.Run(t, new(CLILogsHandlerTestSuite))
+}
+
+func (s *CLILogsHandlerTestSuite) SetupTest() {
+	var err error
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getLogsFlags()
+	s.directory, err = ioutil.TempDir("", "godev-logs")
+	assert.Nil(s.T(), err)
+	s.logFile = path.Join(s.directory, "godev.log")
+	assert.Nil(s.T(), ioutil.WriteFile(s.logFile, []byte(
+		"|Jan02/15:04| [main] godev has started\n"+
+			"|Jan02/15:04|run=41+0s| [runner] starting pipeline\n"+
+			"run=41+2s| FAIL\n"+
+			"|Jan02/15:05|run=42+0s| [runner] starting pipeline\n"+
+			"run=42+1s| PASS\n",
+	), 0644))
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getLogsAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLILogsHandlerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLILogsHandlerTestSuite) Test_getLogsCommand() {
+	config := Config{}
+	command := getLogsCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"logs"}, getLogsFlags())
+}
+
+func (s *CLILogsHandlerTestSuite) Test_getLogsFlags() {
+	ensureCLIFlags(s.T(),
+		[]string{
+			"run",
+		},
+		getLogsFlags(),
+	)
+}
+
+func (s *CLILogsHandlerTestSuite) Test_getLogsAction() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getLogsAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-logs", "--run", "41", s.logFile}))
+	assert.True(t, config.RunLogs)
+	assert.Equal(t, "|Jan02/15:04|run=41+0s| [runner] starting pipeline\nrun=41+2s| FAIL\n", s.logs.String())
+	s.logs.Reset()
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-logs", s.logFile}))
+	assert.Equal(t, "run 41: 2 line(s)\nrun 42: 2 line(s)\n", s.logs.String())
+}
+
+func (s *CLILogsHandlerTestSuite) Test_getLogsAction_withInvalidArguments() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getLogsAction(&config, s.logger)
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-logs", "--run", "43", s.logFile}), "expected runs which are not in the log to fail")
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-logs", "--run", "-1", s.logFile}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-logs", path.Join(s.directory, "missing.log")}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-logs", s.logFile, s.logFile}))
+}
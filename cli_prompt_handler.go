@@ -0,0 +1,58 @@
+package godev
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+func getPromptCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getPromptAction(config, logger),
+		Description: "print a short summary of a godev instance running with --control for use in shell prompts and status bars",
+		Flags:       getPromptFlags(),
+		Name:        "prompt",
+		Usage:       "print a prompt-friendly status of a running godev",
+	}
+}
+
+func getPromptFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagControlAddress(),
+	}
+}
+
+// getPromptAction prints nothing when no godev can be reached so that
+// prompts stay clean in directories without a running instance
+func getPromptAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunPrompt = true
+		config.ControlAddress = c.String("control")
+		config.interpretLogLevel()
+		if len(config.ControlAddress) == 0 {
+			return nil
+		}
+		client := InitControlClient(&ControlClientConfig{
+			Address: config.ControlAddress,
+			Timeout: DefaultPromptTimeout,
+		})
+		if status, err := client.GetStatus(); err == nil {
+			logger.Info(formatPrompt(status))
+		}
+		return nil
+	}
+}
+
+// formatPrompt renders :status as a symbol for the result of the last
+// run followed by the number of runs
+func formatPrompt(status *ControlStatus) string {
+	symbol := "…"
+	if status.State == "running" {
+		symbol = "↻"
+	} else if status.LastRun != nil && status.LastRun.Failed {
+		symbol = "✗"
+	} else if status.LastRun != nil {
+		symbol = "✓"
+	}
+	return fmt.Sprintf("%s %v", symbol, status.Pipelines)
+}
@@ -0,0 +1,78 @@
+package godev
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIPromptHandlerTestSuite struct {
+	suite.Suite
+	mockApp *cli.App
+	logs    bytes.Buffer
+	logger  *Logger
+}
+
+func TestCLIPromptHandler(t *testing.T) {
+	suite.Run(t, new(CLIPromptHandlerTestSuite))
+}
+
+func (s *CLIPromptHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getPromptFlags()
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getPromptAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLIPromptHandlerTestSuite) Test_getPromptCommand() {
+	config := Config{}
+	command := getPromptCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"prompt"}, getPromptFlags())
+}
+
+func (s *CLIPromptHandlerTestSuite) Test_getPromptFlags() {
+	ensureCLIFlags(s.T(), []string{"control"}, getPromptFlags())
+}
+
+func (s *CLIPromptHandlerTestSuite) Test_getPromptAction() {
+	t := s.T()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ControlStatus{
+			State:     "idle",
+			Pipelines: 7,
+			LastRun:   &ControlRunStatus{Failed: true},
+		})
+	}))
+	defer server.Close()
+	config := Config{}
+	s.mockApp.Action = getPromptAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-prompt", "--control", server.URL}))
+	assert.True(t, config.RunPrompt)
+	assert.Equal(t, "✗ 7\n", s.logs.String())
+}
+
+func (s *CLIPromptHandlerTestSuite) Test_getPromptActionWithoutGoDev() {
+	t := s.T()
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	config := Config{}
+	s.mockApp.Action = getPromptAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-prompt", "--control", server.URL}))
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-prompt"}))
+	assert.Empty(t, s.logs.String())
+}
+
+func (s *CLIPromptHandlerTestSuite) Test_formatPrompt() {
+	t := s.T()
+	assert.Equal(t, "… 0", formatPrompt(&ControlStatus{State: "idle"}))
+	assert.Equal(t, "↻ 3", formatPrompt(&ControlStatus{State: "running", Pipelines: 3, LastRun: &ControlRunStatus{}}))
+	assert.Equal(t, "✓ 3", formatPrompt(&ControlStatus{State: "idle", Pipelines: 3, LastRun: &ControlRunStatus{}}))
+	assert.Equal(t, "✗ 3", formatPrompt(&ControlStatus{State: "idle", Pipelines: 3, LastRun: &ControlRunStatus{Failed: true}}))
+}
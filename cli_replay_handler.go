@@ -0,0 +1,72 @@
+package godev
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+// DefaultReplayClientTimeout is how long 'godev replay' waits for the
+// requests to be replayed
+const DefaultReplayClientTimeout = 2 * time.Minute
+
+func getReplayCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getReplayAction(config, logger),
+		ArgsUsage:   "last [count]",
+		Description: "make a godev instance running with --control, --proxy and --record-requests send the last [count] (defaults to 1) recorded requests to the application again",
+		Flags:       getReplayFlags(),
+		Name:        "replay",
+		Usage:       "re-send recorded requests to the application of a running godev",
+	}
+}
+
+func getReplayFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagControlAddress(),
+	}
+}
+
+func getReplayAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunReplay = true
+		config.ControlAddress = c.String("control")
+		config.interpretLogLevel()
+		if len(config.ControlAddress) == 0 {
+			return errors.New("specify the --control address of the running godev")
+		} else if c.NArg() == 0 || c.NArg() > 2 || c.Args().First() != "last" {
+			return errors.New("specify what to replay as 'last [count]'")
+		}
+		count := 1
+		if c.NArg() == 2 {
+			var err error
+			if count, err = strconv.Atoi(c.Args().Get(1)); err != nil || count < 1 {
+				return fmt.Errorf("'%s' is not a valid number of requests", c.Args().Get(1))
+			}
+		}
+		client := InitControlClient(&ControlClientConfig{
+			Address: config.ControlAddress,
+			Timeout: DefaultReplayClientTimeout,
+		})
+		results, err := client.Replay(count)
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, result := range results {
+			if len(result.Error) > 0 {
+				failed++
+				logger.Infof("%s %s: %s", result.Method, result.URI, result.Error)
+			} else {
+				logger.Infof("%s %s: %v (%s)", result.Method, result.URI, result.Status, result.Duration)
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%v of %v request(s) could not be replayed", failed, len(results))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package godev
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIReplayHandlerTestSuite struct {
+	suite.Suite
+	mockApp *cli.App
+	server  *httptest.Server
+	counts  []string
+	results []ProxyReplayResult
+	logs    bytes.Buffer
+	logger  *Logger
+}
+
+func TestCLIReplayHandler(t *testing.T) {
+	suite.Run(t, new(CLIReplayHandlerTestSuite))
+}
+
+func (s *CLIReplayHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getReplayFlags()
+	s.counts = nil
+	s.results = []ProxyReplayResult{{Method: "POST", URI: "/orders", Status: 201, Duration: "12ms"}}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.counts = append(s.counts, r.URL.Query().Get("count"))
+		json.NewEncoder(w).Encode(map[string][]ProxyReplayResult{"replayed": s.results})
+	}))
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getReplayAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLIReplayHandlerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *CLIReplayHandlerTestSuite) Test_getReplayCommand() {
+	config := Config{}
+	command := getReplayCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"replay"}, getReplayFlags())
+}
+
+func (s *CLIReplayHandlerTestSuite) Test_getReplayFlags() {
+	ensureCLIFlags(s.T(),
+		[]string{
+			"control",
+		},
+		getReplayFlags(),
+	)
+}
+
+func (s *CLIReplayHandlerTestSuite) Test_getReplayAction() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getReplayAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-replay", "--control", s.server.URL, "last"}))
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-replay", "--control", s.server.URL, "last", "5"}))
+	assert.True(t, config.RunReplay)
+	assert.Equal(t, []string{"1", "5"}, s.counts)
+	assert.Contains(t, s.logs.String(), "POST /orders: 201 (12ms)")
+	s.results = append(s.results, ProxyReplayResult{Method: "GET", URI: "/orders/1", Error: "connection refused"})
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-replay", "--control", s.server.URL, "last", "2"}), "expected requests which could not be replayed to fail the command")
+}
+
+func (s *CLIReplayHandlerTestSuite) Test_getReplayAction_withInvalidArguments() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getReplayAction(&config, s.logger)
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-replay", "--control", s.server.URL}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-replay", "--control", s.server.URL, "first"}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-replay", "--control", s.server.URL, "last", "0"}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-replay", "last"}), "expected the control address to be required")
+	assert.Nil(t, s.counts)
+}
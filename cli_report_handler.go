@@ -0,0 +1,71 @@
+package godev
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+func getReportCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getReportAction(config, logger),
+		Description: "print a snapshot of the health of the project at --dir as markdown (or json with --json) to paste into pull requests and onboarding documents - the build time and test pass rate are of the last session recorded in --project-dir",
+		Flags:       getReportFlags(),
+		Name:        "report",
+		Usage:       "print a snapshot of the health of the project",
+	}
+}
+
+func getReportFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagBuildOutput(),
+		getFlagConfigFile(),
+		getFlagJSON(),
+		getFlagPolicy(),
+		getFlagProjectDirectory(),
+		getFlagWorkDirectory(),
+	}
+}
+
+func getReportAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunReport = true
+		config.WorkDirectory = c.String("dir")
+		config.BuildOutput = c.String("output")
+		config.ConfigFile = c.String("config")
+		config.ProjectDirectory = c.String("project-dir")
+		if !path.IsAbs(config.ProjectDirectory) {
+			config.ProjectDirectory = path.Join(config.WorkDirectory, config.ProjectDirectory)
+		}
+		if len(config.ConfigFile) == 0 {
+			config.ConfigFile = FindConfigFile(config.WorkDirectory)
+		}
+		if configFile, err := LoadConfigFile(config.ConfigFile); err == nil && len(configFile.Output) > 0 && !c.IsSet("output") {
+			config.BuildOutput = configFile.Output
+		}
+		config.interpretLogLevel()
+		var err error
+		if config.Policy, err = ResolvePolicy(c.String("policy")); err != nil {
+			return err
+		}
+		report := GetProjectReport(&ProjectReportConfig{
+			BuildOutput:   config.BuildOutput,
+			ConfigFile:    config.ConfigFile,
+			Policy:        config.Policy,
+			Project:       InitProjectDirectory(&ProjectDirectoryConfig{LogLevel: config.LogLevel, Path: config.ProjectDirectory}),
+			WorkDirectory: config.WorkDirectory,
+		})
+		if c.Bool("json") {
+			encoded, err := json.Marshal(report)
+			if err != nil {
+				return err
+			}
+			logger.Info(string(encoded))
+		} else {
+			logger.Info(strings.TrimSuffix(report.Markdown(), "\n"))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package godev
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIReportHandlerTestSuite struct {
+	suite.Suite
+	directory string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestCLIReportHandler(t *testing.T) {
+	suite.Run(t, new(CLIReportHandlerTestSuite))
+}
+
+func (s *CLIReportHandlerTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-cli-report")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getReportAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLIReportHandlerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLIReportHandlerTestSuite) runReport(arguments ...string) (*Config, error) {
+	config := &Config{}
+	app := cli.NewApp()
+	app.Commands = []cli.Command{getReportCommand(config, s.logger)}
+	return config, app.Run(append([]string{"godev", "report"}, arguments...))
+}
+
+func (s *CLIReportHandlerTestSuite) Test_getReportCommand() {
+	ensureCLICommand(s.T(), getReportCommand(&Config{}, s.logger), []string{"report"}, getReportFlags())
+}
+
+func (s *CLIReportHandlerTestSuite) Test_getReportFlags() {
+	ensureCLIFlags(s.T(), []string{"output", "config", "json", "policy", "project-dir", "dir"}, getReportFlags())
+}
+
+func (s *CLIReportHandlerTestSuite) Test_getReportAction() {
+	t := s.T()
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "godev.yaml"), []byte("output: bin/api\n"), 0644))
+	config, err := s.runReport("--dir", s.directory)
+	assert.Nil(t, err)
+	assert.True(t, config.RunReport)
+	assert.Equal(t, "panic", config.LogLevel.String())
+	assert.Equal(t, "bin/api", config.BuildOutput, "expected the output of the configuration file to be used")
+	assert.Equal(t, path.Join(s.directory, DefaultProjectDirectory), config.ProjectDirectory)
+	assert.Contains(t, s.logs.String(), "## Project report\n")
+	assert.Contains(t, s.logs.String(), "| Configuration | godev.yaml; 0 execution groups |\n")
+}
+
+func (s *CLIReportHandlerTestSuite) Test_getReportAction_json() {
+	t := s.T()
+	_, err := s.runReport("--dir", s.directory, "--json")
+	assert.Nil(t, err)
+	report := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(s.logs.Bytes(), &report))
+	assert.Equal(t, path.Base(s.directory), report["module"])
+	assert.NotContains(t, report, "session")
+}
@@ -0,0 +1,34 @@
+package godev
+
+import (
+	"errors"
+
+	"github.com/urfave/cli"
+)
+
+func getRunCommand(config *Config) cli.Command {
+	return cli.Command{
+		Action:      getRunAction(config),
+		Aliases:     []string{"r"},
+		ArgsUsage:   "<package> [arguments...]",
+		Description: "build and run <package> (eg. ./cmd/api) in live-reload mode where [arguments...] are passed to it",
+		Flags:       getDefaultFlags(),
+		Name:        "run",
+		Usage:       "build and run a package in live-reload mode",
+	}
+}
+
+func getRunAction(config *Config) cli.ActionFunc {
+	defaultAction := getDefaultAction(config)
+	return func(c *cli.Context) error {
+		config.Package = c.Args().First()
+		if len(config.Package) == 0 {
+			return errors.New("specify the package to run (eg. godev run ./cmd/api)")
+		}
+		if err := defaultAction(c); err != nil {
+			return err
+		}
+		config.CommandArguments = append(config.CommandArguments, c.Args().Tail()...)
+		return nil
+	}
+}
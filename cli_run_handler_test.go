@@ -0,0 +1,48 @@
+package godev
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIRunHandlerTestSuite struct {
+	suite.Suite
+	mockApp *cli.App
+}
+
+func TestCLIRunHandler(t *testing.T) {
+	suite.Run(t, new(CLIRunHandlerTestSuite))
+}
+
+func (s *CLIRunHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getDefaultFlags()
+}
+
+func (s *CLIRunHandlerTestSuite) Test_getRunCommand() {
+	config := Config{}
+	command := getRunCommand(&config)
+	ensureCLICommand(s.T(), command, []string{"run", "r"}, getDefaultFlags())
+}
+
+func (s *CLIRunHandlerTestSuite) Test_getRunAction() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getRunAction(&config)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-run", "--no-detect", "--args", "-v", "./cmd/api", "--port", "8080"}))
+	pathToBinary := path.Join(getCurrentWorkingDirectory(), "/bin/app")
+	assert.True(t, config.RunDefault)
+	assert.Equal(t, "./cmd/api", config.Package)
+	assert.Equal(t, []string{"go mod download", "go build -o " + pathToBinary + " ./cmd/api", pathToBinary}, []string(config.ExecGroups))
+	assert.Equal(t, []string{"-v", "--port", "8080"}, []string(config.CommandArguments))
+}
+
+func (s *CLIRunHandlerTestSuite) Test_getRunActionWithoutPackage() {
+	config := Config{}
+	s.mockApp.Action = getRunAction(&config)
+	assert.NotNil(s.T(), s.mockApp.Run([]string{"test-run-run"}))
+}
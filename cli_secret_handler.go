@@ -0,0 +1,97 @@
+package godev
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+func getSecretCommand(config *Config, logger *Logger) cli.Command {
+	store := InitKeychainSecretStore()
+	reader := bufio.NewReader(os.Stdin)
+	interactive := isTerminal(os.Stdin)
+	return cli.Command{
+		Description: "manage the secrets godev keeps in the keychain of the operating system, values of --env and the env of the configuration file which are " + SecretReferencePrefix + "<name> are replaced with the secret <name>",
+		Name:        "secret",
+		Usage:       "manage secrets in the keychain",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Action:      getSecretAction(config, logger, store, reader, interactive, setSecret),
+				ArgsUsage:   "<name>",
+				Description: "store the first line of stdin as the secret <name>, replacing any secret with the same name",
+				Name:        "set",
+				Usage:       "store a secret",
+			},
+			cli.Command{
+				Action:      getSecretAction(config, logger, store, reader, interactive, getSecret),
+				ArgsUsage:   "<name>",
+				Description: "print the secret <name>",
+				Name:        "get",
+				Usage:       "print a secret",
+			},
+			cli.Command{
+				Action:      getSecretAction(config, logger, store, reader, interactive, deleteSecret),
+				ArgsUsage:   "<name>",
+				Description: "remove the secret <name> from the keychain",
+				Name:        "delete",
+				Usage:       "delete a secret",
+			},
+		},
+	}
+}
+
+func getSecretAction(config *Config, logger *Logger, store SecretStore, reader *bufio.Reader, interactive bool, operation func(SecretStore, string, *bufio.Reader, bool, *Logger) error) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunSecret = true
+		config.interpretLogLevel()
+		if len(c.Args()) != 1 {
+			return errors.New("specify the name of the secret")
+		}
+		return operation(store, c.Args().First(), reader, interactive, logger)
+	}
+}
+
+func setSecret(store SecretStore, name string, reader *bufio.Reader, interactive bool, logger *Logger) error {
+	if interactive {
+		fmt.Printf("value of the secret '%s': ", name)
+	}
+	value, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	value = strings.TrimRight(value, "\r\n")
+	if len(value) == 0 {
+		return fmt.Errorf("the value of the secret '%s' is empty", name)
+	} else if err := store.Set(name, value); err != nil {
+		return err
+	}
+	logger.Infof("stored the secret '%s', reference it as %s%s", name, SecretReferencePrefix, name)
+	return nil
+}
+
+func getSecret(store SecretStore, name string, reader *bufio.Reader, interactive bool, logger *Logger) error {
+	value, err := store.Get(name)
+	if err == ErrSecretNotFound {
+		return fmt.Errorf("there is no secret named '%s'", name)
+	} else if err != nil {
+		return err
+	}
+	logger.Info(value)
+	return nil
+}
+
+func deleteSecret(store SecretStore, name string, reader *bufio.Reader, interactive bool, logger *Logger) error {
+	err := store.Delete(name)
+	if err == ErrSecretNotFound {
+		return fmt.Errorf("there is no secret named '%s'", name)
+	} else if err != nil {
+		return err
+	}
+	logger.Infof("deleted the secret '%s'", name)
+	return nil
+}
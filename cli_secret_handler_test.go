@@ -0,0 +1,75 @@
+package godev
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLISecretHandlerTestSuite struct {
+	suite.Suite
+	logs   bytes.Buffer
+	logger *Logger
+	store  memorySecretStore
+}
+
+func TestCLISecretHandler(t *testing.T) {
+	suite.Run(t, new(CLISecretHandlerTestSuite))
+}
+
+func (s *CLISecretHandlerTestSuite) SetupTest() {
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getSecretAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+	s.store = memorySecretStore{}
+}
+
+func (s *CLISecretHandlerTestSuite) runSecret(operation func(SecretStore, string, *bufio.Reader, bool, *Logger) error, input string, arguments ...string) (*Config, error) {
+	config := &Config{}
+	app := cli.NewApp()
+	app.Action = getSecretAction(config, s.logger, s.store, bufio.NewReader(strings.NewReader(input)), false, operation)
+	return config, app.Run(append([]string{"godev"}, arguments...))
+}
+
+func (s *CLISecretHandlerTestSuite) Test_getSecretCommand() {
+	t := s.T()
+	command := getSecretCommand(&Config{}, s.logger)
+	assert.Equal(t, "secret", command.Name)
+	assert.Len(t, command.Subcommands, 3)
+	ensureCLICommand(t, command.Subcommands[0], []string{"set"}, nil)
+	ensureCLICommand(t, command.Subcommands[1], []string{"get"}, nil)
+	ensureCLICommand(t, command.Subcommands[2], []string{"delete"}, nil)
+}
+
+func (s *CLISecretHandlerTestSuite) Test_getSecretAction() {
+	t := s.T()
+	config, err := s.runSecret(setSecret, "t0k3n\nignored\n", "registry-token")
+	assert.Nil(t, err)
+	assert.True(t, config.RunSecret)
+	assert.Equal(t, "panic", config.LogLevel.String())
+	assert.Equal(t, memorySecretStore{"registry-token": "t0k3n"}, s.store)
+	assert.Contains(t, s.logs.String(), "stored the secret 'registry-token', reference it as secret:registry-token")
+	_, err = s.runSecret(getSecret, "", "registry-token")
+	assert.Nil(t, err)
+	assert.Contains(t, s.logs.String(), "t0k3n\n")
+	_, err = s.runSecret(deleteSecret, "", "registry-token")
+	assert.Nil(t, err)
+	assert.Empty(t, s.store)
+}
+
+func (s *CLISecretHandlerTestSuite) Test_getSecretAction_errors() {
+	t := s.T()
+	_, err := s.runSecret(getSecret, "")
+	assert.EqualError(t, err, "specify the name of the secret")
+	_, err = s.runSecret(setSecret, "\n", "registry-token")
+	assert.EqualError(t, err, "the value of the secret 'registry-token' is empty")
+	_, err = s.runSecret(getSecret, "", "registry-token")
+	assert.EqualError(t, err, "there is no secret named 'registry-token'")
+	_, err = s.runSecret(deleteSecret, "", "registry-token")
+	assert.EqualError(t, err, "there is no secret named 'registry-token'")
+}
@@ -0,0 +1,112 @@
+package godev
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+func getShardCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:         getShardAction(config, logger),
+		ArgsUsage:      "<patterns...> [-- <go test flags...>]",
+		Description:    "list the packages matching <patterns...> and run go test on the ones which belong to --shard so that new packages are tested without restarting godev",
+		Flags:          getShardFlags(),
+		Name:           "shard",
+		SkipArgReorder: true,
+		Usage:          "run go test on one shard of the packages",
+	}
+}
+
+func getShardFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagCoverProfile(),
+		getFlagPolicy(),
+		getFlagShard(),
+	}
+}
+
+// getShardAction removes the coverage profile of a shard which has no
+// packages so that the coverage sub-command does not merge a stale one
+func getShardAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunTest = true
+		config.interpretLogLevel()
+		index, shards, err := parseShard(c.String("shard"))
+		if err != nil {
+			return err
+		}
+		if config.Policy, err = ResolvePolicy(c.String("policy")); err != nil {
+			return err
+		}
+		patterns, testFlags := splitShardArguments(c.Args())
+		if len(patterns) == 0 {
+			return errors.New("specify the packages to shard")
+		}
+		workDirectory, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		packages, err := listPackages(workDirectory, patterns)
+		if err != nil {
+			return err
+		}
+		sharded := shardPackages(packages, shards)
+		coverProfile := c.String("coverprofile")
+		if index > len(sharded) {
+			logger.Infof("shard %v/%v has no packages", index, shards)
+			if err := os.Remove(coverProfile); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}
+		arguments := append([]string{"test"}, sharded[index-1]...)
+		arguments = append(append(arguments, testFlags...), "-coverprofile", coverProfile)
+		logger.Debugf("running go %s", strings.Join(arguments, " "))
+		cmd, err := config.Policy.Command(workDirectory, "go", arguments...)
+		if err != nil {
+			return err
+		}
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			if exitError, ok := err.(*exec.ExitError); ok {
+				return &ExitError{Code: exitError.ExitCode()}
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// parseShard parses :shard in the form <index>/<shards> (eg. 1/4) where
+// the index starts at 1
+func parseShard(shard string) (int, int, error) {
+	sections := strings.Split(shard, "/")
+	if len(sections) != 2 {
+		return 0, 0, fmt.Errorf("'%s' is not a shard in the form <index>/<shards>", shard)
+	}
+	index, indexErr := strconv.Atoi(sections[0])
+	shards, shardsErr := strconv.Atoi(sections[1])
+	if indexErr != nil || shardsErr != nil || index < 1 || index > shards {
+		return 0, 0, fmt.Errorf("'%s' is not a shard in the form <index>/<shards>", shard)
+	}
+	return index, shards, nil
+}
+
+// splitShardArguments returns the package patterns before -- in
+// :arguments and the go test flags after it
+func splitShardArguments(arguments []string) ([]string, []string) {
+	for index, argument := range arguments {
+		if argument == "--" {
+			return arguments[:index], arguments[index+1:]
+		}
+	}
+	return arguments, nil
+}
@@ -0,0 +1,99 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIShardHandlerTestSuite struct {
+	suite.Suite
+	mockApp   *cli.App
+	directory string
+	workDir   string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestCLIShardHandler(t *testing.T) {
+	suite.Run(t, new(CLIShardHandlerTestSuite))
+}
+
+func (s *CLIShardHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getShardFlags()
+	s.directory = createTestModule(s.T(), "a", "b")
+	workDir, err := os.Getwd()
+	assert.Nil(s.T(), err)
+	s.workDir = workDir
+	assert.Nil(s.T(), os.Chdir(s.directory))
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getShardAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLIShardHandlerTestSuite) TearDownTest() {
+	os.Chdir(s.workDir)
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLIShardHandlerTestSuite) Test_getShardCommand() {
+	config := Config{}
+	command := getShardCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"shard"}, getShardFlags())
+}
+
+func (s *CLIShardHandlerTestSuite) Test_getShardFlags() {
+	ensureCLIFlags(s.T(), []string{"coverprofile", "policy", "shard"}, getShardFlags())
+}
+
+func (s *CLIShardHandlerTestSuite) Test_getShardAction() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getShardAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-shard", "--shard", "2/2", "--coverprofile", "c.2.out", "./...", "--", "-count=1"}))
+	assert.Contains(t, s.logs.String(), "running go test example.com/shards/b -count=1 -coverprofile c.2.out")
+	assert.FileExists(t, path.Join(s.directory, "c.2.out"))
+}
+
+func (s *CLIShardHandlerTestSuite) Test_getShardAction_listsPackagesOnEveryRun() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getShardAction(&config, s.logger)
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "c.3.out"), []byte("mode: set\n"), 0644))
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-shard", "--shard", "3/3", "--coverprofile", "c.3.out", "./..."}))
+	assert.Contains(t, s.logs.String(), "shard 3/3 has no packages")
+	assert.False(t, fileExists(path.Join(s.directory, "c.3.out")), "expected the stale coverage profile of the empty shard to be removed")
+	assert.Nil(t, os.MkdirAll(path.Join(s.directory, "c"), 0755))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "c", "c.go"), []byte("package c\n"), 0644))
+	s.logs.Reset()
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-shard", "--shard", "3/3", "--coverprofile", "c.3.out", "./..."}))
+	assert.Contains(t, s.logs.String(), "running go test example.com/shards/c -coverprofile c.3.out")
+}
+
+func (s *CLIShardHandlerTestSuite) Test_getShardAction_invalid() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getShardAction(&config, s.logger)
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-shard", "--shard", "0/2", "./..."}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-shard", "--shard", "3/2", "./..."}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-shard", "--shard", "1/2"}))
+}
+
+func (s *CLIShardHandlerTestSuite) Test_parseShard() {
+	t := s.T()
+	index, shards, err := parseShard("2/4")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, index)
+	assert.Equal(t, 4, shards)
+	for _, invalid := range []string{"", "2", "0/4", "5/4", "a/4", "1/2/3"} {
+		_, _, err = parseShard(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
@@ -0,0 +1,82 @@
+package godev
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+func getStatusCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getStatusAction(config, logger),
+		Aliases:     []string{"s"},
+		Description: "print the status of a godev instance running with --control",
+		Flags:       getStatusFlags(),
+		Name:        "status",
+		Usage:       "print the status of a running godev",
+	}
+}
+
+func getStatusFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagControlAddress(),
+		getFlagJSON(),
+	}
+}
+
+func getStatusAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunStatus = true
+		config.ControlAddress = c.String("control")
+		config.interpretLogLevel()
+		if len(config.ControlAddress) == 0 {
+			return errors.New("specify the --control address of the running godev")
+		}
+		client := InitControlClient(&ControlClientConfig{
+			Address: config.ControlAddress,
+			Timeout: DefaultControlClientTimeout,
+		})
+		status, err := client.GetStatus()
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			encoded, err := json.Marshal(status)
+			if err != nil {
+				return err
+			}
+			logger.Info(string(encoded))
+		} else {
+			logger.Info(formatStatus(status))
+		}
+		return nil
+	}
+}
+
+// formatStatus renders :status as a single line for humans
+func formatStatus(status *ControlStatus) string {
+	summary := []string{status.State}
+	if status.LastRun != nil {
+		result := "passed"
+		if status.LastRun.Failed {
+			result = "failed"
+		}
+		if len(status.LastRun.Duration) > 0 {
+			summary = append(summary, fmt.Sprintf("pipeline %v %s in %s", status.Pipelines, result, status.LastRun.Duration))
+		} else {
+			summary = append(summary, fmt.Sprintf("pipeline %v started %s", status.Pipelines, status.LastRun.StartedAt.Format("15:04:05")))
+		}
+	}
+	if status.Warnings > 0 {
+		summary = append(summary, fmt.Sprintf("%v warnings", status.Warnings))
+	}
+	if status.Paused {
+		summary = append(summary, fmt.Sprintf("%v paths watched (paused)", status.WatchedPaths))
+	} else {
+		summary = append(summary, fmt.Sprintf("%v paths watched", status.WatchedPaths))
+	}
+	return strings.Join(summary, ", ")
+}
@@ -0,0 +1,103 @@
+package godev
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIStatusHandlerTestSuite struct {
+	suite.Suite
+	mockApp *cli.App
+	server  *httptest.Server
+	logs    bytes.Buffer
+	logger  *Logger
+}
+
+func TestCLIStatusHandler(t *testing.T) {
+	suite.Run(t, new(CLIStatusHandlerTestSuite))
+}
+
+func (s *CLIStatusHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getStatusFlags()
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ControlStatus{
+			State:        "idle",
+			Pipelines:    3,
+			LastRun:      &ControlRunStatus{StartedAt: time.Now(), Duration: "1.5s"},
+			WatchedPaths: 42,
+		})
+	}))
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getStatusAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLIStatusHandlerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *CLIStatusHandlerTestSuite) Test_getStatusCommand() {
+	config := Config{}
+	command := getStatusCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"status", "s"}, getStatusFlags())
+}
+
+func (s *CLIStatusHandlerTestSuite) Test_getStatusFlags() {
+	ensureCLIFlags(s.T(),
+		[]string{
+			"control",
+			"json",
+		},
+		getStatusFlags(),
+	)
+}
+
+func (s *CLIStatusHandlerTestSuite) Test_getStatusAction() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getStatusAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-status", "--control", s.server.URL}))
+	assert.True(t, config.RunStatus)
+	assert.Contains(t, s.logs.String(), "idle, pipeline 3 passed in 1.5s, 42 paths watched")
+}
+
+func (s *CLIStatusHandlerTestSuite) Test_getStatusActionWithJSON() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getStatusAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-status", "--control", s.server.URL, "--json"}))
+	var status ControlStatus
+	assert.Nil(t, json.Unmarshal(s.logs.Bytes(), &status))
+	assert.Equal(t, 42, status.WatchedPaths)
+}
+
+func (s *CLIStatusHandlerTestSuite) Test_getStatusActionWithoutControl() {
+	config := Config{}
+	s.mockApp.Action = getStatusAction(&config, s.logger)
+	assert.NotNil(s.T(), s.mockApp.Run([]string{"test-run-status"}))
+}
+
+func (s *CLIStatusHandlerTestSuite) Test_formatStatus() {
+	t := s.T()
+	assert.Equal(t, "idle, 0 paths watched", formatStatus(&ControlStatus{State: "idle"}))
+	assert.Equal(t,
+		"running, pipeline 2 failed in 2s, 3 warnings, 10 paths watched",
+		formatStatus(&ControlStatus{
+			State:        "running",
+			Pipelines:    2,
+			LastRun:      &ControlRunStatus{Duration: "2s", Failed: true},
+			Warnings:     3,
+			WatchedPaths: 10,
+		}),
+	)
+	assert.Equal(t, "idle, 4 paths watched (paused)", formatStatus(&ControlStatus{State: "idle", WatchedPaths: 4, Paused: true}))
+}
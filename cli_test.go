@@ -1,7 +1,8 @@
-package main
+package godev
 
 import (
 	"bytes"
+	"fmt"
 	"regexp"
 	"testing"
 
@@ -50,6 +51,36 @@ func (s *CLITestSuite) TestStart_provisionsVersion() {
 	})
 }
 
+func (s *CLITestSuite) TestStart_returnsErrors() {
+	t := s.T()
+	cli := initCLI()
+	cli.logger.SetOutput(&bytes.Buffer{})
+	cli.instance.Writer = &bytes.Buffer{}
+	called := false
+	err := cli.Start([]string{"godev", "--not-a-flag"}, func(config *Config) error {
+		called = true
+		return nil
+	})
+	assert.NotNil(t, err)
+	assert.False(t, called, "expected the configuration not to be used when the inputs could not be parsed")
+	expected := fmt.Errorf("failed")
+	assert.Equal(t, expected, initCLI().Start([]string{"godev", "version"}, func(config *Config) error {
+		return expected
+	}))
+}
+
+func (s *CLITestSuite) TestParseConfig() {
+	t := s.T()
+	config, err := ParseConfig([]string{"godev", "test", "--no-detect"})
+	assert.Nil(t, err)
+	assert.True(t, config.RunTest)
+	assert.Equal(t, getCurrentWorkingDirectory(), config.WorkDirectory)
+	assert.Equal(t, []string{"godev", "test", "--no-detect"}, config.Args, "expected the arguments to be kept for --self-reload")
+	config, err = ParseConfig([]string{"godev", "--args", "'unterminated"})
+	assert.NotNil(t, err)
+	assert.Nil(t, config)
+}
+
 func (s *CLITestSuite) TestStart_provisionsView() {
 	ensureCLIStartSetsRunFlag(s.T(), []string{"godev", "view", "dockerfile"}, "RunView", func(logs bytes.Buffer) {
 		assert.Contains(s.T(), logs.String(), DataDockerfile)
@@ -58,3 +89,18 @@ func (s *CLITestSuite) TestStart_provisionsView() {
 		assert.Contains(s.T(), logs.String(), DataMakefile)
 	})
 }
+
+func (s *CLITestSuite) Test_getFlagEnvVar() {
+	assert.Equal(s.T(), "GODEV_EXEC_DELIM", getFlagEnvVar("exec-delim"))
+	assert.Equal(s.T(), "GODEV_RATE", getFlagEnvVar("rate"))
+}
+
+func (s *CLITestSuite) Test_flagsHaveEnvVars() {
+	for _, flag := range getDefaultFlags() {
+		name := regexp.MustCompile(`^[^, ]+`).FindString(flag.GetName())
+		if name == "exec" {
+			continue
+		}
+		assert.Containsf(s.T(), fmt.Sprintf("%+v", flag), getFlagEnvVar(name), "expected --%s to be settable through %s", name, getFlagEnvVar(name))
+	}
+}
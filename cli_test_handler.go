@@ -1,6 +1,7 @@
-package main
+package godev
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/urfave/cli"
@@ -10,7 +11,8 @@ func getTestCommand(config *Config) cli.Command {
 	return cli.Command{
 		Action:      getTestAction(config),
 		Aliases:     []string{"t"},
-		Description: "run tests in live-reload mode",
+		ArgsUsage:   "[packages...]",
+		Description: "run tests of [packages...] (defaults to ./...) in live-reload mode",
 		Flags:       getTestFlags(),
 		Name:        "test",
 		Usage:       "run tests in live-reload mode",
@@ -19,32 +21,225 @@ func getTestCommand(config *Config) cli.Command {
 
 func getTestFlags() []cli.Flag {
 	return []cli.Flag{
+		getFlagBuildCommand(),
 		getFlagBuildOutput(),
+		getFlagCheck(),
+		getFlagChildLogFormat(),
+		getFlagChildLogLevel(),
 		getFlagCommandsDelimiter(),
+		getFlagConfigFile(),
+		getFlagContainerRuntime(),
+		getFlagControlAddress(),
+		getFlagDocsAddress(),
+		getFlagEnvFile(),
 		getFlagEnvVars(),
+		getFlagExcludePatterns(),
+		getFlagFollowSymlinks(),
 		getFlagFileExtensions(),
+		getFlagGCFlags(),
+		getFlagGenerate(),
+		getFlagGenerateInputs(),
+		getFlagIgnoreBinaryFiles(),
 		getFlagIgnoredNames(),
+		getFlagIncludePatterns(),
+		getFlagIsolateRuns(),
+		getFlagKillTimeout(),
+		getFlagLDFlags(),
+		getFlagLocale(),
+		getFlagLogLevel(),
+		getFlagLogOutput(),
+		getFlagManual(),
+		getFlagMaxFileSize(),
+		getFlagMaxProcs(),
+		getFlagMaxWarnings(),
+		getFlagMinIntervals(),
+		getFlagNoDetect(),
+		getFlagNoNewPrivileges(),
+		getFlagNoPrefix(),
+		getFlagNotify(),
+		getFlagOnce(),
+		getFlagOnlyGroups(),
+		getFlagPlugin(),
+		getFlagPolicy(),
+		getFlagPollFallback(),
+		getFlagPollInterval(),
+		getFlagPostHook(),
+		getFlagPreHook(),
+		getFlagProfile(),
+		getFlagProjectDirectory(),
+		getFlagPublish(),
+		getFlagPty(),
+		getFlagRace(),
 		getFlagRate(),
+		getFlagRawOutput(),
+		getFlagRecordEvents(),
+		getFlagReplayEvents(),
+		getFlagSelfReload(),
+		getFlagShareWorktree(),
+		getFlagShell(),
 		getFlagSilent(),
+		getFlagSkipGroups(),
+		getFlagStampVersion(),
+		getFlagStatusLine(),
+		getFlagStopSignal(),
 		getFlagSuperVerboseLogs(),
+		getFlagTagRuns(),
+		getFlagTags(),
+		getFlagTestShards(),
+		getFlagTimeout(),
+		getFlagUseGitignore(),
+		getFlagUser(),
 		getFlagVerboseLogs(),
 		getFlagWatchDirectory(),
+		getFlagWatchEvents(),
 		getFlagWorkDirectory(),
 	}
 }
 
 func getTestAction(config *Config) cli.ActionFunc {
 	return func(c *cli.Context) error {
+		var err error
 		config.RunTest = true
+		config.RecordOutput = true
+		config.BuildCommand = c.String("build-cmd")
 		config.BuildOutput = c.String("output")
+		config.BuildTags = c.String("tags")
+		config.GCFlags = c.String("gcflags")
+		config.GenerateInputs = c.StringSlice("generate-input")
+		if err := validatePatterns(config.GenerateInputs); err != nil {
+			return err
+		}
+		config.Generate = c.Bool("generate") || len(config.GenerateInputs) > 0
+		config.LDFlags = c.String("ldflags")
+		config.Race = c.Bool("race")
+		config.ShareWorktree = c.Bool("share-worktree")
+		config.StampVersion = c.Bool("stamp-version")
+		config.RunCheck = c.Bool("check")
+		config.ChildLogFormat = LogParser(c.String("child-log-format"))
+		if err := config.ChildLogFormat.IsValid(); err != nil {
+			return err
+		}
+		config.ChildLogLevel = LogLevel(c.String("child-log-level"))
 		config.CommandsDelimiter = c.String("exec-delim")
+		config.ContainerRuntime = c.String("container-runtime")
+		config.ControlAddress = c.String("control")
+		config.DocsAddress = c.String("docs")
+		config.EnvFile = c.String("env-file")
 		config.EnvVars = c.StringSlice("env")
+		config.ExcludePatterns = c.StringSlice("exclude")
+		config.UseGitignore = c.Bool("use-gitignore")
+		config.FollowSymlinks = c.Bool("follow-symlinks")
+		if err := validatePatterns(config.ExcludePatterns); err != nil {
+			return err
+		}
 		config.FileExtensions = strings.Split(c.String("exts"), ",")
+		config.WatchEvents = strings.Split(c.String("watch-events"), ",")
+		if _, err := ParseWatcherOperations(config.WatchEvents); err != nil {
+			return err
+		}
+		config.IgnoreBinaryFiles = c.Bool("ignore-binary")
 		config.IgnoredNames = strings.Split(c.String("ignore"), ",")
+		config.IncludePatterns = c.StringSlice("include")
+		if err := validatePatterns(config.IncludePatterns); err != nil {
+			return err
+		}
+		if len(c.String("max-file-size")) > 0 {
+			if config.MaxFileSize, err = parseByteSize(c.String("max-file-size")); err != nil {
+				return err
+			}
+		}
+		if config.MaxProcs = c.Int("max-procs"); config.MaxProcs < 0 {
+			return fmt.Errorf("--max-procs cannot be negative")
+		}
+		config.MaxWarnings = c.Int("max-warnings")
+		if config.MinIntervals, err = parseGroupDurations(c.StringSlice("min-interval")); err != nil {
+			return err
+		}
+		config.NoDetect = c.Bool("no-detect")
+		config.NoNewPrivileges = c.Bool("no-new-privs")
+		config.NoPrefix = c.Bool("no-prefix")
+		config.NotifyAddresses = c.StringSlice("notify")
+		config.OnlyGroups = c.StringSlice("only-group")
+		config.SkipGroups = c.StringSlice("skip-group")
+		config.Plugins = c.StringSlice("plugin")
+		if config.Policy, err = ResolvePolicy(c.String("policy")); err != nil {
+			return err
+		}
+		config.PollFallback = c.Duration("poll-fallback")
+		config.PollInterval = c.Duration("poll")
 		config.Rate = c.Duration("rate")
+		config.RawOutput = c.Bool("raw-output")
+		config.Pty = c.Bool("pty")
+		if config.KillTimeout = c.Duration("kill-timeout"); config.KillTimeout <= 0 {
+			return fmt.Errorf("--kill-timeout has to be positive")
+		}
+		config.StatusLine = c.Bool("status-line")
+		if config.StopSignal, err = parseStopSignal(c.String("stop-signal")); err != nil {
+			return fmt.Errorf("invalid --stop-signal: %s", err)
+		}
+		config.IsolateRuns = c.Bool("isolate-runs")
+		config.PreHook = c.String("pre-hook")
+		if err := validateHookCommand(config.PreHook); err != nil {
+			return fmt.Errorf("invalid --pre-hook: %s", err)
+		}
+		config.PostHook = c.String("post-hook")
+		if err := validateHookCommand(config.PostHook); err != nil {
+			return fmt.Errorf("invalid --post-hook: %s", err)
+		}
+		config.Manual = c.Bool("manual")
+		if config.Once = c.Bool("once"); config.Once && config.Manual {
+			return fmt.Errorf("--once cannot be used with --manual")
+		}
+		config.TagRuns = c.Bool("tag-runs")
+		if config.Timeout = c.Duration("timeout"); config.Timeout < 0 {
+			return fmt.Errorf("--timeout cannot be negative")
+		}
+		config.RecordEvents = c.String("record")
+		config.ReplayEvents = c.String("replay")
+		config.SelfReload = c.Bool("self-reload")
+		config.Shell = c.Bool("shell")
+		config.TestPackages = c.Args()
+		config.TestShards = c.Int("test-shards")
+		if config.TestShards < 0 {
+			return fmt.Errorf("--test-shards cannot be negative")
+		}
+		config.User = c.String("user")
 		config.WatchDirectory = c.String("watch")
 		config.WorkDirectory = c.String("dir")
+		config.Locale = c.String("locale")
+		config.LogLevel = LogLevel(c.String("log-level"))
+		config.LogOutputs = c.StringSlice("log-output")
+		if len(config.LogLevel) > 0 {
+			if err := config.LogLevel.IsValid(); err != nil {
+				return err
+			}
+		}
+		config.Profile = c.String("profile")
+		config.ProjectDirectory = c.String("project-dir")
+		config.PublishTarget = c.String("publish")
+		if err := validatePublishDestination(config.PublishTarget); err != nil {
+			return err
+		}
+		if err := applyConfigFile(c, config); err != nil {
+			return err
+		}
+		if _, err := config.GetProfile(); err != nil {
+			return err
+		}
+		if config.ShareWorktree {
+			config.Worktree = DetectGitWorktree(config.WorkDirectory)
+		}
 		config.assignDefaults()
+		if len(config.EnvFile) > 0 {
+			if _, err := LoadEnvironmentFile(config.EnvFile); err != nil {
+				return err
+			}
+		}
+		if len(config.ReplayEvents) > 0 {
+			if _, err := loadWatcherRecords(config.ReplayEvents); err != nil {
+				return err
+			}
+		}
 		config.LogSilent = c.Bool("silent")
 		config.LogVerbose = c.Bool("verbose")
 		config.LogSuperVerbose = c.Bool("vverbose")
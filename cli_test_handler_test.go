@@ -1,4 +1,4 @@
-package main
+package godev
 
 import (
 	"path"
@@ -35,17 +35,78 @@ func (s *CLITestHandlerTestSuite) Test_getTestCommand() {
 func (s *CLITestHandlerTestSuite) Test_getTestFlags() {
 	ensureCLIFlags(s.T(),
 		[]string{
+			"build-cmd",
+			"check",
+			"child-log-format",
+			"child-log-level",
+			"config",
+			"container-runtime",
+			"control",
 			"dir",
+			"docs",
 			"env",
+			"env-file",
+			"exclude",
 			"exec-delim",
 			"exts",
+			"follow-symlinks",
+			"gcflags",
+			"generate",
+			"generate-input",
 			"ignore",
+			"ignore-binary",
+			"include",
+			"isolate-runs",
+			"kill-timeout",
+			"ldflags",
+			"locale",
+			"log-level",
+			"log-output",
+			"manual",
+			"max-file-size",
+			"max-procs",
+			"max-warnings",
+			"min-interval",
+			"no-detect",
+			"no-new-privs",
+			"no-prefix",
+			"notify",
+			"once",
+			"only-group",
 			"output",
+			"plugin",
+			"policy",
+			"poll-fallback",
+			"poll",
+			"post-hook",
+			"pre-hook",
+			"profile",
+			"project-dir",
+			"publish",
+			"pty",
+			"race",
 			"rate",
+			"raw-output",
+			"record",
+			"replay",
+			"self-reload",
+			"share-worktree",
+			"shell",
 			"silent",
+			"skip-group",
+			"stamp-version",
+			"status-line",
+			"stop-signal",
+			"tag-runs",
+			"tags",
+			"test-shards",
+			"timeout",
+			"use-gitignore",
+			"user",
 			"verbose",
 			"vverbose",
 			"watch",
+			"watch-events",
 		},
 		getTestFlags(),
 	)
@@ -61,7 +122,7 @@ func (s *CLITestHandlerTestSuite) Test_getTestAction() {
 		assert.Equal(t, pathToBinary, config.BuildOutput)
 		assert.Equal(t, ",", config.CommandsDelimiter)
 		assert.Equal(t, []string{}, []string(config.EnvVars))
-		assert.Equal(t, []string{"go mod vendor", "go build -o " + pathToBinary, "go test ./... -coverprofile c.out"}, []string(config.ExecGroups))
+		assert.Equal(t, []string{"go mod download", "go build -o " + pathToBinary, "go test ./... -coverprofile c.out"}, []string(config.ExecGroups))
 		assert.Equal(t, []string{"go", "Makefile"}, []string(config.FileExtensions))
 		assert.Equal(t, []string{"bin", "vendor"}, []string(config.IgnoredNames))
 		assert.Equal(t, 2*time.Second, config.Rate)
@@ -72,3 +133,18 @@ func (s *CLITestHandlerTestSuite) Test_getTestAction() {
 		panic(err)
 	}
 }
+
+func (s *CLITestHandlerTestSuite) Test_getTestActionWithPackages() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getTestAction(&config)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-test", "--no-detect", "./pkg/...", "./cmd/..."}))
+	assert.Equal(t, []string{"./pkg/...", "./cmd/..."}, config.TestPackages)
+	assert.Equal(t, "go test ./pkg/... ./cmd/... -coverprofile c.out", config.ExecGroups[len(config.ExecGroups)-1])
+}
+
+func (s *CLITestHandlerTestSuite) Test_getTestActionWithNegativeShards() {
+	config := Config{}
+	s.mockApp.Action = getTestAction(&config)
+	assert.NotNil(s.T(), s.mockApp.Run([]string{"test-run-test", "--test-shards", "-1"}))
+}
@@ -0,0 +1,55 @@
+package godev
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/urfave/cli"
+)
+
+func getTouchCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getTouchAction(config, logger),
+		ArgsUsage:   "<path> [paths...]",
+		Description: "make a godev instance running with --control handle changes to <path> [paths...] without modifying them, relative paths are resolved from the current directory",
+		Flags:       getTouchFlags(),
+		Name:        "touch",
+		Usage:       "simulate changes to files for a running godev",
+	}
+}
+
+func getTouchFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagControlAddress(),
+	}
+}
+
+func getTouchAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunTouch = true
+		config.ControlAddress = c.String("control")
+		config.interpretLogLevel()
+		if len(config.ControlAddress) == 0 {
+			return errors.New("specify the --control address of the running godev")
+		} else if c.NArg() == 0 {
+			return errors.New("specify the paths to touch")
+		}
+		var filePaths []string
+		for _, filePath := range c.Args() {
+			absolutePath, err := filepath.Abs(filePath)
+			if err != nil {
+				return err
+			}
+			filePaths = append(filePaths, absolutePath)
+		}
+		client := InitControlClient(&ControlClientConfig{
+			Address: config.ControlAddress,
+			Timeout: DefaultControlClientTimeout,
+		})
+		if err := client.Touch(filePaths); err != nil {
+			return err
+		}
+		logger.Infof("touched %v path(s)", len(filePaths))
+		return nil
+	}
+}
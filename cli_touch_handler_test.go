@@ -0,0 +1,81 @@
+package godev
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLITouchHandlerTestSuite struct {
+	suite.Suite
+	mockApp      *cli.App
+	server       *httptest.Server
+	touchedPaths []string
+	logs         bytes.Buffer
+	logger       *Logger
+}
+
+func TestCLITouchHandler(t *testing.T) {
+	suite.Run(t, new(CLITouchHandlerTestSuite))
+}
+
+func (s *CLITouchHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Flags = getTouchFlags()
+	s.touchedPaths = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.touchedPaths = r.URL.Query()["path"]
+		json.NewEncoder(w).Encode(map[string][]string{"touched": s.touchedPaths})
+	}))
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getTouchAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLITouchHandlerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *CLITouchHandlerTestSuite) Test_getTouchCommand() {
+	config := Config{}
+	command := getTouchCommand(&config, s.logger)
+	ensureCLICommand(s.T(), command, []string{"touch"}, getTouchFlags())
+}
+
+func (s *CLITouchHandlerTestSuite) Test_getTouchFlags() {
+	ensureCLIFlags(s.T(),
+		[]string{
+			"control",
+		},
+		getTouchFlags(),
+	)
+}
+
+func (s *CLITouchHandlerTestSuite) Test_getTouchAction() {
+	t := s.T()
+	config := Config{}
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	s.mockApp.Action = getTouchAction(&config, s.logger)
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-touch", "--control", s.server.URL, "main.go", "/project/go.mod"}))
+	assert.True(t, config.RunTouch)
+	assert.Equal(t, []string{filepath.Join(cwd, "main.go"), "/project/go.mod"}, s.touchedPaths)
+	assert.Contains(t, s.logs.String(), "touched 2 path(s)")
+}
+
+func (s *CLITouchHandlerTestSuite) Test_getTouchAction_withoutPaths() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Action = getTouchAction(&config, s.logger)
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-touch", "--control", s.server.URL}))
+	assert.NotNil(t, s.mockApp.Run([]string{"test-run-touch", "main.go"}), "expected the control address to be required")
+	assert.Nil(t, s.touchedPaths)
+}
@@ -1,4 +1,4 @@
-package main
+package godev
 
 import (
 	"github.com/urfave/cli"
@@ -0,0 +1,47 @@
+package godev
+
+import (
+	"os"
+
+	"github.com/urfave/cli"
+)
+
+func getWarmCommand(config *Config, logger *Logger) cli.Command {
+	return cli.Command{
+		Action:      getWarmAction(config, logger),
+		Description: "pre-download the module graph of the project at --dir and fill the build cache with its packages and tests so that the first build of a new clone is fast and does not need the network - with --image, go is run in a container of the image with its caches in .cache/pkg of --dir",
+		Flags:       getWarmFlags(),
+		Name:        "warm",
+		Usage:       "pre-download the modules and fill the build cache of the project",
+	}
+}
+
+func getWarmFlags() []cli.Flag {
+	return []cli.Flag{
+		getFlagContainerRuntime(),
+		getFlagImage(),
+		getFlagPolicy(),
+		getFlagWorkDirectory(),
+	}
+}
+
+func getWarmAction(config *Config, logger *Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		config.RunWarm = true
+		config.ContainerRuntime = c.String("container-runtime")
+		config.WorkDirectory = c.String("dir")
+		config.interpretLogLevel()
+		var err error
+		if config.Policy, err = ResolvePolicy(c.String("policy")); err != nil {
+			return err
+		}
+		return WarmProject(&WarmConfig{
+			ContainerRuntime: config.ContainerRuntime,
+			Image:            c.String("image"),
+			Logger:           logger,
+			Output:           os.Stdout,
+			Policy:           config.Policy,
+			WorkDirectory:    config.WorkDirectory,
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIWarmHandlerTestSuite struct {
+	suite.Suite
+	directory string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestCLIWarmHandler(t *testing.T) {
+	suite.Run(t, new(CLIWarmHandlerTestSuite))
+}
+
+func (s *CLIWarmHandlerTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-cli-warm")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "getWarmAction", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CLIWarmHandlerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CLIWarmHandlerTestSuite) Test_getWarmCommand() {
+	ensureCLICommand(s.T(), getWarmCommand(&Config{}, s.logger), []string{"warm"}, getWarmFlags())
+}
+
+func (s *CLIWarmHandlerTestSuite) Test_getWarmFlags() {
+	ensureCLIFlags(s.T(), []string{"container-runtime", "image", "policy", "dir"}, getWarmFlags())
+}
+
+func (s *CLIWarmHandlerTestSuite) Test_getWarmAction() {
+	t := s.T()
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "go.mod"), []byte("module example.com/warm\n"), 0644))
+	config := &Config{}
+	app := cli.NewApp()
+	app.Commands = []cli.Command{getWarmCommand(config, s.logger)}
+	err := app.Run([]string{"godev", "warm", "--dir", s.directory, "--image", "app:development", "--container-runtime", "false"})
+	assert.NotNil(t, err)
+	assert.True(t, config.RunWarm)
+	assert.Equal(t, "panic", config.LogLevel.String())
+	assert.Equal(t, "false", config.ContainerRuntime)
+	assert.Contains(t, s.logs.String(), "downloading the module graph")
+}
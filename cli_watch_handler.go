@@ -0,0 +1,16 @@
+package godev
+
+import (
+	"github.com/urfave/cli"
+)
+
+func getWatchCommand(config *Config) cli.Command {
+	return cli.Command{
+		Action:      getDefaultAction(config),
+		Aliases:     []string{"w"},
+		Description: "build and run the project in live-reload mode, this is also what godev does without a sub-command",
+		Flags:       getDefaultFlags(),
+		Name:        "watch",
+		Usage:       "build and run the project in live-reload mode",
+	}
+}
@@ -0,0 +1,38 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/urfave/cli"
+)
+
+type CLIWatchHandlerTestSuite struct {
+	suite.Suite
+	mockApp *cli.App
+}
+
+func TestCLIWatchHandler(t *testing.T) {
+	suite.Run(t, new(CLIWatchHandlerTestSuite))
+}
+
+func (s *CLIWatchHandlerTestSuite) SetupTest() {
+	s.mockApp = cli.NewApp()
+	s.mockApp.Commands = []cli.Command{getWatchCommand(&Config{})}
+}
+
+func (s *CLIWatchHandlerTestSuite) Test_getWatchCommand() {
+	config := Config{}
+	command := getWatchCommand(&config)
+	ensureCLICommand(s.T(), command, []string{"watch", "w"}, getDefaultFlags())
+}
+
+func (s *CLIWatchHandlerTestSuite) Test_getWatchAction() {
+	t := s.T()
+	config := Config{}
+	s.mockApp.Commands = []cli.Command{getWatchCommand(&config)}
+	assert.Nil(t, s.mockApp.Run([]string{"test-run-watch", "watch", "--rate", "1s"}))
+	assert.True(t, config.RunDefault)
+	assert.Equal(t, "1s", config.Rate.String())
+}
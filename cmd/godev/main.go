@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"github.com/zephinzer/godev"
+)
+
+func main() {
+	os.Exit(godev.Main(os.Args))
+}
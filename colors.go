@@ -1,7 +1,8 @@
-package main
+package godev
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -10,6 +11,10 @@ import (
 // can interpret as an ANSI color
 const ColorStub = "\033["
 
+// ColorsDisabledEnvVar disables the colours of the output of the
+// commands when it is set to anything, see https://no-color.org
+const ColorsDisabledEnvVar = "NO_COLOR"
+
 // Colorer is the convenience function to color things
 var Colorer = Colors{}
 
@@ -59,6 +64,13 @@ func Color(color string, value string) string {
 	return fmt.Sprintf("%s%s%s%s", ColorStub, format, finalValue, unformat)
 }
 
+// canColor checks whether output written to :file can be coloured, which
+// is when it is a terminal and colours are not disabled with
+// ColorsDisabledEnvVar
+func canColor(file *os.File) bool {
+	return len(os.Getenv(ColorsDisabledEnvVar)) == 0 && isTerminal(file)
+}
+
 // Colors defines a struct for the Colorer
 type Colors struct{}
 
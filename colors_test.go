@@ -1,7 +1,9 @@
-package main
+package godev
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"strconv"
 	"testing"
 
@@ -47,3 +49,15 @@ func (s *ColorTestSuite) TestColorer() {
 	assert.Contains(t, Colorer.LightBlue("a"), strconv.Itoa(Palette["lblue"]))
 	assert.Contains(t, Colorer.LightViolet("a"), strconv.Itoa(Palette["lviolet"]))
 }
+
+func (s *ColorTestSuite) Test_canColor() {
+	t := s.T()
+	file, err := ioutil.TempFile("", "godev-colors")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+	assert.False(t, canColor(file), "expected files not to be coloured")
+	os.Setenv(ColorsDisabledEnvVar, "1")
+	defer os.Unsetenv(ColorsDisabledEnvVar)
+	assert.False(t, canColor(os.Stdout), "expected NO_COLOR to disable colours")
+}
@@ -0,0 +1,97 @@
+package godev
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ContainerRuntimes are the container runtimes looked for in PATH in
+// order of preference when --container-runtime is not specified
+var ContainerRuntimes = []string{"docker", "podman"}
+
+// findContainerRuntime returns :preferred, or the first of the
+// ContainerRuntimes which is found in PATH
+func findContainerRuntime(preferred string) (string, error) {
+	if len(preferred) > 0 {
+		return preferred, nil
+	}
+	for _, containerRuntime := range ContainerRuntimes {
+		if _, err := exec.LookPath(containerRuntime); err == nil {
+			return containerRuntime, nil
+		}
+	}
+	return "", fmt.Errorf("running commands with image=... requires one of %v in PATH or --container-runtime", ContainerRuntimes)
+}
+
+// ContainerCommand is a command which runs in a container of Image with
+// MountDirectory mounted at the same path so that paths in arguments and
+// output mean the same inside and outside of the container
+type ContainerCommand struct {
+	Image          string
+	Application    string
+	Arguments      []string
+	Directory      string
+	MountDirectory string
+	// EnvironmentKeys are the names of the variables which are passed
+	// from the environment of the runtime into the container
+	EnvironmentKeys []string
+	// OverrideEntrypoint runs Application as the entrypoint of the image
+	// for images whose entrypoint is not a shell, eg. the development
+	// stage of the seeded Dockerfile which runs the application
+	OverrideEntrypoint bool
+	// Volumes are mounted into the container in addition to the
+	// MountDirectory, each in the form <host path>:<container path>
+	Volumes []string
+}
+
+// GetArguments returns the arguments of the runtime which run the
+// command in a container removed after it exits - on Linux the container
+// runs as the current user so that created files are not owned by root
+func (command *ContainerCommand) GetArguments() []string {
+	arguments := []string{"run", "--rm", "-i", "-v", command.MountDirectory + ":" + command.MountDirectory}
+	if command.Directory != command.MountDirectory && !strings.HasPrefix(command.Directory, command.MountDirectory+"/") {
+		arguments = append(arguments, "-v", command.Directory+":"+command.Directory)
+	}
+	for _, volume := range command.Volumes {
+		arguments = append(arguments, "-v", volume)
+	}
+	arguments = append(arguments, "-w", command.Directory)
+	if runtime.GOOS == "linux" && os.Getuid() >= 0 {
+		arguments = append(arguments, "--user", fmt.Sprintf("%v:%v", os.Getuid(), os.Getgid()))
+	}
+	for _, key := range command.EnvironmentKeys {
+		arguments = append(arguments, "-e", key)
+	}
+	if command.OverrideEntrypoint {
+		arguments = append(arguments, "--entrypoint", command.Application, command.Image)
+	} else {
+		arguments = append(arguments, command.Image, command.Application)
+	}
+	return append(arguments, command.Arguments...)
+}
+
+// getEnvironmentKeys returns the unique names of the variables in
+// :environment in the order that they first appear
+func getEnvironmentKeys(environment []string) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, variable := range environment {
+		key := strings.SplitN(variable, "=", 2)[0]
+		if len(key) > 0 && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// validateContainerImage checks that :image can be passed to a runtime
+func validateContainerImage(image string) error {
+	if strings.ContainsAny(image, " \t") || strings.HasPrefix(image, "-") {
+		return fmt.Errorf("'%s' is not a valid image", image)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package godev
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommandContainerTestSuite struct {
+	suite.Suite
+}
+
+func TestCommandContainer(t *testing.T) {
+	suite.Run(t, new(CommandContainerTestSuite))
+}
+
+func (s *CommandContainerTestSuite) TestGetArguments() {
+	t := s.T()
+	container := &ContainerCommand{
+		Image:           "node:16",
+		Application:     "npm",
+		Arguments:       []string{"run", "build"},
+		Directory:       "/project/web",
+		MountDirectory:  "/project",
+		EnvironmentKeys: []string{"NODE_ENV"},
+	}
+	arguments := container.GetArguments()
+	assert.Equal(t, []string{"run", "--rm", "-i", "-v", "/project:/project", "-w", "/project/web"}, arguments[:7])
+	assert.Equal(t, []string{"-e", "NODE_ENV", "node:16", "npm", "run", "build"}, arguments[len(arguments)-6:])
+	if runtime.GOOS == "linux" {
+		assert.Contains(t, arguments, "--user", "expected containers to run as the current user")
+	}
+	container.Directory = "/shared/proto"
+	assert.Contains(t, container.GetArguments(), "/shared/proto:/shared/proto", "expected directories outside of the project to be mounted")
+	container.OverrideEntrypoint = true
+	container.Volumes = []string{"/cache:/root/.npm"}
+	arguments = container.GetArguments()
+	assert.Equal(t, []string{"-e", "NODE_ENV", "--entrypoint", "npm", "node:16", "run", "build"}, arguments[len(arguments)-7:])
+	assert.Contains(t, strings.Join(arguments, " "), "-v /cache:/root/.npm -w /shared/proto")
+}
+
+func (s *CommandContainerTestSuite) Test_findContainerRuntime() {
+	t := s.T()
+	containerRuntime, err := findContainerRuntime("podman")
+	assert.Nil(t, err)
+	assert.Equal(t, "podman", containerRuntime)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", "")
+	_, err = findContainerRuntime("")
+	assert.NotNil(t, err, "expected an error without a runtime in PATH")
+}
+
+func (s *CommandContainerTestSuite) Test_getEnvironmentKeys() {
+	assert.Equal(s.T(), []string{"A", "B"}, getEnvironmentKeys([]string{"A=1", "B=2", "A=3", "=4"}))
+}
+
+func (s *CommandContainerTestSuite) Test_validateContainerImage() {
+	t := s.T()
+	assert.Nil(t, validateContainerImage("ghcr.io/org/protoc:3.15"))
+	assert.NotNil(t, validateContainerImage("--privileged"))
+	assert.NotNil(t, validateContainerImage("node 16"))
+}
@@ -0,0 +1,72 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// getSysProcAttr returns the process attributes needed to run the
+// command as the user specified in the command's configuration
+func (command *Command) getSysProcAttr() (*syscall.SysProcAttr, error) {
+	if len(command.config.User) == 0 {
+		return nil, nil
+	}
+	credential, err := getCredential(command.config.User)
+	if err != nil {
+		return nil, err
+	}
+	return &syscall.SysProcAttr{Credential: credential}, nil
+}
+
+// getCredential resolves :userSpec in the form of "user[:group]" where
+// user and group can either be names or numeric ids
+func getCredential(userSpec string) (*syscall.Credential, error) {
+	sections := strings.SplitN(userSpec, ":", 2)
+	userInfo, err := lookupUser(sections[0])
+	if err != nil {
+		return nil, err
+	}
+	uid, err := strconv.ParseUint(userInfo.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("user '%s' has a non-numeric uid '%s'", sections[0], userInfo.Uid)
+	}
+	groupID := userInfo.Gid
+	if len(sections) > 1 && len(sections[1]) > 0 {
+		groupInfo, err := lookupGroup(sections[1])
+		if err != nil {
+			return nil, err
+		}
+		groupID = groupInfo.Gid
+	}
+	gid, err := strconv.ParseUint(groupID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("group of '%s' has a non-numeric gid '%s'", userSpec, groupID)
+	}
+	return &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}, nil
+}
+
+// lookupUser finds a user by id if :nameOrID is numeric, by name otherwise
+func lookupUser(nameOrID string) (*user.User, error) {
+	if _, err := strconv.Atoi(nameOrID); err == nil {
+		if userInfo, err := user.LookupId(nameOrID); err == nil {
+			return userInfo, nil
+		}
+		// numeric ids without a passwd entry are allowed (common in containers)
+		return &user.User{Uid: nameOrID, Gid: nameOrID}, nil
+	}
+	return user.Lookup(nameOrID)
+}
+
+// lookupGroup finds a group by id if :nameOrID is numeric, by name otherwise
+func lookupGroup(nameOrID string) (*user.Group, error) {
+	if _, err := strconv.Atoi(nameOrID); err == nil {
+		return &user.Group{Gid: nameOrID}, nil
+	}
+	return user.LookupGroup(nameOrID)
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommandCredentialTestSuite struct {
+	suite.Suite
+}
+
+func TestCommandCredential(t *testing.T) {
+	suite.Run(t, new(CommandCredentialTestSuite))
+}
+
+func (s *CommandCredentialTestSuite) Test_getCredential_withNumericIDs() {
+	t := s.T()
+	credential, err := getCredential("1234:5678")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1234), credential.Uid)
+	assert.Equal(t, uint32(5678), credential.Gid)
+}
+
+func (s *CommandCredentialTestSuite) Test_getCredential_withNumericUserOnly() {
+	t := s.T()
+	credential, err := getCredential("0")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), credential.Uid)
+	assert.Equal(t, uint32(0), credential.Gid)
+}
+
+func (s *CommandCredentialTestSuite) Test_getCredential_withUnknownUser() {
+	_, err := getCredential("godev-user-that-should-not-exist")
+	assert.NotNil(s.T(), err)
+}
+
+func (s *CommandCredentialTestSuite) Test_getSysProcAttr() {
+	t := s.T()
+	command := &Command{config: &CommandConfig{}}
+	sysProcAttr, err := command.getSysProcAttr()
+	assert.Nil(t, err)
+	assert.Nil(t, sysProcAttr)
+	command.config.User = "1234:5678"
+	sysProcAttr, err = command.getSysProcAttr()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1234), sysProcAttr.Credential.Uid)
+	assert.Equal(t, uint32(5678), sysProcAttr.Credential.Gid)
+}
@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"errors"
+	"syscall"
+)
+
+// getSysProcAttr returns the process attributes needed to run the
+// command as the user specified in the command's configuration
+func (command *Command) getSysProcAttr() (*syscall.SysProcAttr, error) {
+	if len(command.config.User) == 0 {
+		return nil, nil
+	}
+	return nil, errors.New("running commands as a different user is not supported on windows")
+}
@@ -1,14 +1,21 @@
-package main
+package godev
 
 import (
+	"context"
 	"crypto/md5"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 // CommandDelimiter is used when demarcating boundaries between
@@ -19,14 +26,26 @@ const CommandDelimiter = "──────────────────
 // the start of a command
 const CommandProcessStartSymbol = "►"
 
+// DefaultKillTimeout is how long commands have to exit after they are
+// sent their stop signal before they are killed
+const DefaultKillTimeout = 5 * time.Second
+
+// CommandRunDirectoryEnvVar is the environment variable which holds the
+// temporary directory of the pipeline run with --isolate-runs
+const CommandRunDirectoryEnvVar = "GODEV_RUN_DIR"
+
+// DefaultStopSignal is the name of the signal which is sent to commands
+// to stop them
+const DefaultStopSignal = "SIGINT"
+
 // CommandProcessStopSymbol is the fancy symbol we use to denote
 // the end of a command
 const CommandProcessStopSymbol = "■"
 
 // ICommand is the interface for the Command class
 type ICommand interface {
-	// runs the command
-	Run()
+	// runs the command until it exits or the context is done
+	Run(ctx context.Context)
 	// gets the id of the command
 	GetID() string
 	// get a pointer to the status channel
@@ -46,6 +65,7 @@ func InitCommand(config *CommandConfig) *Command {
 		id: commandHash[:6],
 	}
 	command.config = config
+	command.status = make(chan error, 0)
 	command.logger = InitLogger(&LoggerConfig{
 		Name:   "command",
 		Format: "production",
@@ -53,6 +73,7 @@ func InitCommand(config *CommandConfig) *Command {
 		AdditionalFields: &map[string]interface{}{
 			"submodule": path.Base(fmt.Sprintf("%s", config.Application)),
 		},
+		RunState: config.RunState,
 	})
 	return command
 }
@@ -61,9 +82,75 @@ func InitCommand(config *CommandConfig) *Command {
 type CommandConfig struct {
 	Application string
 	Arguments   []string
-	Directory   string
-	Environment []string
-	LogLevel    LogLevel
+	// Backoff is the time before the first retry of the command, it
+	// doubles on every retry
+	Backoff time.Duration
+	// DenyNetwork runs the command without network access, it is set by
+	// the policy
+	DenyNetwork     bool
+	Directory       string
+	Environment     []string
+	EnvironmentFile string
+	ForwardedPorts  []PortForward
+	// GroupOutput holds the output of the command until it exits so that
+	// it does not interleave with that of the other commands of its
+	// execution group, stderr is written to stdout
+	GroupOutput    bool
+	IsolateNetwork bool
+	// KillTimeout is how long the command has to exit after it is sent
+	// its StopSignal before it is killed, DefaultKillTimeout when it is 0
+	KillTimeout time.Duration
+	// LineBuffered writes the stdout of the command line by line so
+	// that lines of concurrent commands do not interleave in the logs of
+	// containers
+	LineBuffered bool
+	LogLevel     LogLevel
+	OutputLevel  LogLevel
+	// OutputLabel prefixes each line of output of the command in the
+	// OutputLabelColor, lines are not prefixed when it is empty
+	OutputLabel      string
+	OutputLabelColor string
+	OutputParser     LogParser
+	// Pty runs the command in a pseudo-terminal so that it keeps the
+	// formatting it uses in terminals, it is ignored with RawOutput
+	Pty bool
+	// RawOutput connects the command to the stdout and stderr of godev
+	// so that its output is neither processed nor recorded
+	RawOutput    bool
+	ReadyPattern *regexp.Regexp
+	// Recorder captures the output of the command for the run history
+	// when it is set
+	Recorder *RunRecorder
+	// Retries is the number of times the command is run again when it
+	// fails before its execution group fails
+	Retries int
+	// RunState tags the output of the command and collects its lint
+	// findings, it defaults to DefaultRunState
+	RunState *RunState
+	// Session holds the values of the godev session that the arguments
+	// can refer to with template placeholders
+	Session CommandTemplateSession
+	// Shell is set for the commands which run a script with sh -c, the
+	// values their placeholders render are quoted for the shell
+	Shell           bool
+	SnapshotTimeout time.Duration
+	StateDirectory  string
+	// StopSignal is sent to the command to stop it, SIGINT when it is
+	// not set
+	StopSignal os.Signal
+	// SuccessCodes are the exit codes which make the command successful,
+	// only 0 when empty
+	SuccessCodes []int
+	// SuccessPattern has to match a line of output for the command to
+	// be successful when it is set
+	SuccessPattern *regexp.Regexp
+	// Timeout is how long the command can run before it is killed and
+	// fails, it can run for as long as it needs when it is 0
+	Timeout time.Duration
+	User    string
+	// When are the patterns of the changed files which the command runs
+	// for, it runs for all changes when there are none
+	When []string
 }
 
 // Command is the atomic command to run
@@ -73,12 +160,41 @@ type Command struct {
 	status     chan error
 	run        chan error
 	terminated chan error
+	// exited is closed when the process of the current run has exited
+	exited chan struct{}
+	// spawned is closed when the process of the current run has started
+	spawned    chan struct{}
 	config     *CommandConfig
 	cmd        *exec.Cmd
 	logger     *Logger
+	outputs    []*CommandOutput
+	grouped    *groupedOutput
+	pty        *commandPty
+	lastEnv    []string
+	forwarders []net.Listener
 	started    bool
+	startedAt  time.Time
+	ready      bool
+	matched    bool
+	readyMutex sync.Mutex
 	reported   bool
 	stopped    bool
+	// cancelled keeps the process of the current run from starting when
+	// it is stopped before it has started
+	cancelled bool
+	// stateMutex guards started, stopped, cancelled, the channels of the
+	// current run and the start of its process since callers check on
+	// them while the command is restarted
+	stateMutex sync.Mutex
+	// changedFiles are the files whose changes triggered the pipeline,
+	// nil when everything has changed
+	changedFiles []string
+	// runDirectory is the temporary directory of the pipeline run, it is
+	// empty without --isolate-runs
+	runDirectory string
+	// startError fails the current run before its process is started,
+	// eg. when the user to run it as cannot be resolved
+	startError error
 }
 
 // GetID returns the command's ID, used for the execution group
@@ -90,15 +206,27 @@ func (command *Command) GetID() string {
 // GetStatus returns the command's status channel for the execution
 // group to know when the command has terminated
 func (command *Command) GetStatus() *chan error {
+	command.stateMutex.Lock()
+	defer command.stateMutex.Unlock()
 	return &command.status
 }
 
 // IsRunning allows callers to check if the command is running,
 // the logic is tied into the Run()
 func (command *Command) IsRunning() bool {
+	command.stateMutex.Lock()
+	defer command.stateMutex.Unlock()
 	return command.started && !command.stopped
 }
 
+// IsReady allows callers to check if the command has printed a line
+// matching its readiness pattern since it was last started
+func (command *Command) IsReady() bool {
+	command.readyMutex.Lock()
+	defer command.readyMutex.Unlock()
+	return command.ready
+}
+
 // IsValid does some sanity checks on the provided
 // application before we try to run it
 func (command *Command) IsValid() error {
@@ -117,51 +245,321 @@ func (command *Command) IsValid() error {
 	if _, err := exec.LookPath(application); err != nil {
 		return err
 	}
+	if _, err := command.getProcessAttributes(); err != nil {
+		return err
+	}
 	return nil
 }
 
-// Run executes the command
-func (command *Command) Run() {
+// Run executes the command, which is stopped with its stop signal when
+// :ctx is done before it exits
+func (command *Command) Run(ctx context.Context) {
 	command.logger.Tracef("command[%s] is starting", command.id)
 	command.handleInitialisation()
 	go command.handleStart()
-	go command.handleProcessLifecycle()
+	go command.handleProcessLifecycle(ctx)
 	select {
 	case terminateCommand := <-command.terminated:
 		command.handleStopped(terminateCommand)
 	}
 }
 
-// SendInterrupt sends SIGINT to the command
+// SendInterrupt sends the stop signal of the command, SIGINT unless
+// another one is configured, without waiting for the command to take
+// it - callers which need the command to have stopped check IsRunning
 func (command *Command) SendInterrupt() {
 	command.logger.Tracef("SIGINT received by command %s", command.id)
-	command.logger.Tracef("command[%v] status: %v/%v, msg: SIGINT >>> %v", command.id, command.started, command.terminated, &command.signal)
-	command.signal <- syscall.SIGINT
+	go command.stopWith(command.getStopSignal())
+}
+
+// SendStopSignal stops the command with :signal instead of its stop
+// signal, which is how the signals stopping godev reach the commands
+func (command *Command) SendStopSignal(signal os.Signal) {
+	command.logger.Tracef("%v received by command %s", signal, command.id)
+	command.stopWith(signal)
+}
+
+// stopWith has the command stopped with :signal after the application
+// has snapshotted its state, it returns without sending :signal when the
+// process has already exited or does not take it within the kill timeout
+func (command *Command) stopWith(signal os.Signal) {
+	command.snapshotBeforeStop()
+	killTimeout := command.config.KillTimeout
+	if killTimeout <= 0 {
+		killTimeout = DefaultKillTimeout
+	}
+	command.stateMutex.Lock()
+	signals, exited := command.signal, command.exited
+	command.stateMutex.Unlock()
+	select {
+	case signals <- signal:
+	case <-exited:
+	case <-time.After(killTimeout):
+		command.logger.Warnf("command[%s] did not take %v within %v", command.id, signal, killTimeout)
+	}
+}
+
+// snapshotBeforeStop has the running application snapshot its state
+// before it is stopped when it has a state directory
+func (command *Command) snapshotBeforeStop() {
+	if len(command.config.StateDirectory) > 0 && command.IsRunning() && command.getPid() > 0 {
+		command.handleSnapshot()
+	}
+}
+
+// SendSignal sends :signal to the running process and the processes it
+// started without stopping the command
+func (command *Command) SendSignal(signal os.Signal) error {
+	if !command.IsRunning() || command.cmd == nil {
+		return fmt.Errorf("command[%s] is not running", command.id)
+	}
+	return command.signalProcess(signal)
+}
+
+// signalProcess sends :signal to the process of the current run, which
+// is kept from starting when it has not started yet
+func (command *Command) signalProcess(signal os.Signal) error {
+	command.stateMutex.Lock()
+	defer command.stateMutex.Unlock()
+	if !command.started {
+		command.cancelled = true
+		return errors.New("the process has not been started")
+	}
+	return signalProcess(command.cmd, signal)
+}
+
+// getPid returns the process ID of the current run, -1 when its process
+// has not been started
+func (command *Command) getPid() int {
+	command.stateMutex.Lock()
+	defer command.stateMutex.Unlock()
+	if command.cmd == nil || command.cmd.Process == nil {
+		return -1
+	}
+	return command.cmd.Process.Pid
+}
+
+// getStopSignal returns the signal which stops the command
+func (command *Command) getStopSignal() os.Signal {
+	if command.config.StopSignal == nil {
+		return syscall.SIGINT
+	}
+	return command.config.StopSignal
+}
+
+// parseStopSignal returns the signal named :name which commands can be
+// stopped with, the SIG prefix is optional
+func parseStopSignal(name string) (os.Signal, error) {
+	switch strings.TrimPrefix(strings.ToUpper(name), "SIG") {
+	case "INT":
+		return syscall.SIGINT, nil
+	case "TERM":
+		return syscall.SIGTERM, nil
+	}
+	return nil, fmt.Errorf("'%s' is not one of SIGINT or SIGTERM", name)
+}
+
+// getEnvironmentFile returns the variables in the .env file which is
+// read on every run so that changes to it apply without restarting
+func (command *Command) getEnvironmentFile() []string {
+	if len(command.config.EnvironmentFile) == 0 {
+		return nil
+	}
+	environment, err := LoadEnvironmentFile(command.config.EnvironmentFile)
+	if err != nil {
+		command.logger.Warnf("command[%s] environment file could not be loaded: %s", command.id, err)
+	}
+	return environment
+}
+
+// SetChangedFiles sets the changed files which the next run of the
+// command gets in its arguments and environment
+func (command *Command) SetChangedFiles(changedFiles []string) {
+	command.changedFiles = changedFiles
+}
+
+// MatchesChanges checks if the command should run for the changed files
+// it was given, which are matched against its when patterns relative to
+// :baseDirectory and which have to need code generation if it generates
+// code - it runs when the pipeline was not triggered by changes
+func (command *Command) MatchesChanges(baseDirectory string) bool {
+	if command.changedFiles == nil {
+		return true
+	} else if len(command.config.When) > 0 && !matchAnyChangedFile(command.config.When, command.changedFiles, baseDirectory) {
+		return false
+	} else if sliceContainsString(command.config.Arguments, GeneratePackagesPlaceholder) {
+		return len(getGeneratePackages(command.changedFiles, command.config.Directory)) > 0
+	}
+	return true
+}
+
+// getSkipReason describes the changes which the command needs to run
+// when it does not match the changed files it was given
+func (command *Command) getSkipReason() string {
+	if sliceContainsString(command.config.Arguments, GeneratePackagesPlaceholder) {
+		return fmt.Sprintf("has no changes matching %v with code to generate", command.config.When)
+	}
+	return fmt.Sprintf("has no changes matching %v", command.config.When)
+}
+
+// SetRunDirectory sets the temporary directory of the pipeline run which
+// the next run of the command gets as GOTMPDIR and GODEV_RUN_DIR
+func (command *Command) SetRunDirectory(runDirectory string) {
+	command.runDirectory = runDirectory
 }
 
 func (command *Command) handleInitialisation() {
 	if command.config == nil {
 		panic("command.config needs to be defined before initialisation can be done")
 	}
+	command.stateMutex.Lock()
 	command.signal = make(chan os.Signal, 0)
-	command.status = make(chan error, 0)
-	command.run = make(chan error, 0)
+	if command.status == nil {
+		command.status = make(chan error, 0)
+	}
+	command.run = make(chan error, 1)
 	command.terminated = make(chan error, 0)
+	command.exited = make(chan struct{})
+	command.spawned = make(chan struct{})
 	command.started = false
-	command.reported = false
 	command.stopped = false
+	command.cancelled = false
+	command.stateMutex.Unlock()
+	command.reported = false
+	command.readyMutex.Lock()
+	command.ready = false
+	command.matched = false
+	command.readyMutex.Unlock()
 	command.cmd = exec.Command(
 		command.config.Application,
 		command.config.Arguments...,
 	)
 	command.cmd.Dir = command.config.Directory
-	command.cmd.Env = command.config.Environment
+	command.startError = nil
+	if sysProcAttr, err := command.getProcessAttributes(); err != nil {
+		// without its attributes the command would run as the current
+		// user, often root, instead of the one it was configured with
+		command.startError = err
+	} else {
+		command.cmd.SysProcAttr = sysProcAttr
+	}
+	command.cmd.Env = append(command.getEnvironmentFile(), command.config.Environment...)
+	overriddenEnv := parseEnvironment(command.cmd.Env)
 	for _, envvar := range os.Environ() {
-		command.cmd.Env = append(command.cmd.Env, envvar)
+		if _, ok := overriddenEnv[strings.SplitN(envvar, "=", 2)[0]]; !ok {
+			command.cmd.Env = append(command.cmd.Env, envvar)
+		}
+	}
+	if command.lastEnv != nil {
+		if diff := DiffEnvironment(command.lastEnv, command.cmd.Env); !diff.IsEmpty() {
+			command.logger.Infof("command[%s] environment changed - %s", command.id, diff)
+		}
+	}
+	command.lastEnv = command.cmd.Env
+	templateData := getCommandTemplateData(command.changedFiles, command.config.Directory, command.config.Session, command.cmd.Env)
+	if command.config.Shell {
+		templateData = templateData.quoteForShell()
+	}
+	arguments, err := expandCommandArguments(command.config.Arguments, templateData)
+	if err != nil {
+		command.logger.Warnf("command[%s] arguments are passed as-is: %s", command.id, err)
+	} else {
+		command.cmd.Args = append([]string{command.config.Application}, arguments...)
+	}
+	command.cmd.Env = append(command.cmd.Env, CommandChangedFilesEnvVar+"="+strings.Join(command.changedFiles, "\n"))
+	if len(command.runDirectory) > 0 {
+		command.cmd.Env = append(command.cmd.Env, "GOTMPDIR="+command.runDirectory, CommandRunDirectoryEnvVar+"="+command.runDirectory)
+	}
+	if len(command.config.StateDirectory) > 0 {
+		if err := os.MkdirAll(command.config.StateDirectory, 0755); err != nil {
+			command.logger.Warnf("command[%s] state directory could not be created: %s", command.id, err)
+		}
+		command.cmd.Env = append(command.cmd.Env, command.getStateEnvironment()...)
 	}
 	// command.cmd.Env = append(command.config.Environment, "GOCACHE=on")
-	command.cmd.Stderr = os.Stderr
-	command.cmd.Stdout = os.Stdout
+	command.grouped = nil
+	command.outputs = nil
+	command.pty = nil
+	if command.config.RawOutput {
+		command.cmd.Stdout = os.Stdout
+		command.cmd.Stderr = os.Stderr
+		return
+	}
+	stdoutWriter, stderrWriter := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	stdoutColored, stderrColored := canColor(os.Stdout), canColor(os.Stderr)
+	if status := command.config.RunState.get().StatusLine; status.IsEnabled() {
+		stdoutWriter, stderrWriter = status.Wrap(os.Stdout), status.Wrap(os.Stderr)
+	}
+	if command.config.GroupOutput {
+		command.grouped = &groupedOutput{limit: GroupedOutputMemoryLimit, state: command.config.RunState, writer: stdoutWriter}
+		stdoutWriter, stderrWriter = command.grouped, command.grouped
+		stderrColored = stdoutColored
+	}
+	command.cmd.Stdout = stdoutWriter
+	if command.config.OutputParser != LogParserNone || command.isLintCommand() || command.config.ReadyPattern != nil || command.config.SuccessPattern != nil || command.config.RunState.get().RunTags.IsEnabled() || len(command.config.OutputLabel) > 0 || command.config.LineBuffered {
+		command.cmd.Stdout = command.initialiseOutput(stdoutWriter, false, stdoutColored)
+	}
+	command.cmd.Stderr = command.initialiseOutput(stderrWriter, true, stderrColored)
+	if command.config.Recorder != nil {
+		command.cmd.Stdout = io.MultiWriter(command.cmd.Stdout, command.config.Recorder.Stdout)
+		command.cmd.Stderr = io.MultiWriter(command.cmd.Stderr, command.config.Recorder.Stderr)
+	}
+	if command.config.Pty {
+		command.attachPty()
+	}
+}
+
+// getProcessAttributes returns the process attributes for running the
+// command as the configured user and in an isolated network or without
+// network access if needed
+func (command *Command) getProcessAttributes() (*syscall.SysProcAttr, error) {
+	sysProcAttr, err := command.getSysProcAttr()
+	if err != nil {
+		return nil, err
+	}
+	sysProcAttr = setProcessGroup(sysProcAttr)
+	if command.config.IsolateNetwork {
+		return sysProcAttr, canIsolateNetwork()
+	} else if command.config.DenyNetwork {
+		return denyNetwork(sysProcAttr)
+	}
+	return sysProcAttr, nil
+}
+
+// isLintCommand checks if this command is a vet/lint step
+func (command *Command) isLintCommand() bool {
+	return isLintCommand(command.config.Application, command.config.Arguments)
+}
+
+// initialiseOutput wraps :writer so that the child's output is
+// processed line by line before being written to :writer, :isStderr
+// marks the output as stderr so that it stands out from stdout when
+// :colored is set
+func (command *Command) initialiseOutput(writer io.Writer, isStderr, colored bool) *CommandOutput {
+	prefix := ""
+	if len(command.config.OutputLabel) > 0 {
+		prefix = "[" + command.config.OutputLabel + "] "
+		if colored {
+			prefix = Color(command.config.OutputLabelColor, "["+command.config.OutputLabel+"]") + " "
+		}
+	}
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:           path.Base(command.config.Application),
+		Parser:         command.config.OutputParser,
+		Level:          command.config.OutputLevel,
+		Prefix:         prefix,
+		Writer:         writer,
+		DetectFindings: command.isLintCommand(),
+		IsStderr:       isStderr,
+		Colored:        colored,
+		ReadyPattern:   command.config.ReadyPattern,
+		OnReady:        command.handleReady,
+		SuccessPattern: command.config.SuccessPattern,
+		OnSuccessMatch: command.handleSuccessMatch,
+		RunState:       command.config.RunState,
+	})
+	command.outputs = append(command.outputs, output)
+	return output
 }
 
 // handleProcessExited handles the exit status being sent by the process
@@ -171,15 +569,25 @@ func (command *Command) handleProcessExited(status error) error {
 	return nil
 }
 
-func (command *Command) handleProcessLifecycle() error {
+// handleProcessLifecycle waits for the process of the current run to be
+// stopped by the caller, by :ctx or by exiting, and reports it once it
+// has started
+func (command *Command) handleProcessLifecycle(ctx context.Context) error {
+	spawned := command.spawned
 	for {
 		select {
 		case signal := <-command.signal: // caller -> Command: shut down please
 			return command.handleSignalReceived(signal)
+		case <-ctx.Done(): // caller -> Command: everything is shutting down
+			command.logger.Tracef("command[%s] is stopping: %s", command.id, ctx.Err())
+			command.snapshotBeforeStop()
+			return command.handleSignalReceived(command.getStopSignal())
 		case cmdRunStatus := <-command.run: // process -> Command: i'm done here
+			command.handleProcessReporting()
 			return command.handleProcessExited(cmdRunStatus)
-		default: // just run
+		case <-spawned: // process -> Command: i'm up
 			command.handleProcessReporting()
+			spawned = nil
 		}
 	}
 }
@@ -188,13 +596,13 @@ func (command *Command) handleProcessLifecycle() error {
 // has its PID reported
 func (command *Command) handleProcessReporting() {
 	if !command.reported {
-		if command.cmd.Process != nil {
+		if pid := command.getPid(); pid > 0 {
 			command.logger.Infof(
 				"'%v'\n%s %s pid:%v id:%s %s",
 				strings.Join(command.config.Arguments, "', '"),
 				CommandProcessStartSymbol,
 				CommandDelimiter,
-				command.cmd.Process.Pid,
+				pid,
 				command.id,
 				CommandProcessStartSymbol,
 			)
@@ -203,31 +611,164 @@ func (command *Command) handleProcessReporting() {
 	}
 }
 
-// handleSignalReceived handles the signal received by the caller
+// handleReady marks the command as ready the first time its output
+// matches the readiness pattern
+func (command *Command) handleReady() {
+	command.readyMutex.Lock()
+	defer command.readyMutex.Unlock()
+	if command.ready {
+		return
+	}
+	command.ready = true
+	command.logger.Infof(
+		"command[%s] is ready after %v (matched /%s/)",
+		command.id,
+		time.Since(command.startedAt).Round(time.Millisecond),
+		command.config.ReadyPattern.String(),
+	)
+}
+
+// handleSuccessMatch records that the output matched the success pattern
+func (command *Command) handleSuccessMatch() {
+	command.readyMutex.Lock()
+	defer command.readyMutex.Unlock()
+	command.matched = true
+}
+
+// getSuccessError applies the configured success criteria to :err, the
+// result of waiting for the process, and returns nil if they are met
+func (command *Command) getSuccessError(err error) error {
+	if len(command.config.SuccessCodes) > 0 {
+		exitCode := 0
+		if exitError, ok := err.(*exec.ExitError); ok {
+			exitCode = exitError.ExitCode()
+		} else if err != nil {
+			return err
+		}
+		successful := false
+		for _, successCode := range command.config.SuccessCodes {
+			if exitCode == successCode {
+				successful = true
+			}
+		}
+		if !successful {
+			return fmt.Errorf("exit status %v is not one of %v", exitCode, command.config.SuccessCodes)
+		}
+		err = nil
+	}
+	if err == nil && command.config.SuccessPattern != nil {
+		command.readyMutex.Lock()
+		defer command.readyMutex.Unlock()
+		if !command.matched {
+			return fmt.Errorf("output did not match /%s/", command.config.SuccessPattern.String())
+		}
+	}
+	return err
+}
+
+// handleSignalReceived handles the signal received by the caller, the
+// command is terminated once its process has exited
 func (command *Command) handleSignalReceived(signal os.Signal) error {
 	command.logger.Tracef("caller sent signal %v", signal)
+	err := command.stopProcess(signal)
 	command.terminated <- errors.New(signal.String())
-	if err := command.cmd.Process.Signal(signal); err != nil {
+	if err != nil {
 		command.logger.Warn(err)
 		return err
 	}
 	return nil
 }
 
+// stopProcess sends :signal to the process and the processes it started,
+// and kills them when they have not exited within the kill timeout - the
+// current run is over once it returns so that the command can restart
+func (command *Command) stopProcess(signal os.Signal) error {
+	if err := command.signalProcess(signal); err != nil {
+		<-command.exited
+		return err
+	}
+	killTimeout := command.config.KillTimeout
+	if killTimeout <= 0 {
+		killTimeout = DefaultKillTimeout
+	}
+	select {
+	case <-command.exited:
+		return nil
+	case <-time.After(killTimeout):
+	}
+	command.logger.Warnf("command[%s] did not exit within %v of %s - killing it", command.id, killTimeout, signal)
+	if err := command.signalProcess(os.Kill); err != nil {
+		<-command.exited
+		return err
+	}
+	<-command.exited
+	return nil
+}
+
 // handleStart starts the process
 func (command *Command) handleStart() {
+	exited, run, spawned := command.exited, command.run, command.spawned
+	command.stateMutex.Lock()
 	command.started = true
-	command.run <- command.cmd.Run()
+	command.startedAt = time.Now()
+	err := command.startError
+	if err == nil && command.cancelled {
+		err = errors.New("stopped before it was started")
+	} else if err == nil && command.config.IsolateNetwork {
+		err = command.startInIsolatedNetwork()
+	} else if err == nil {
+		err = command.cmd.Start()
+	}
+	command.stateMutex.Unlock()
+	if command.pty != nil {
+		command.pty.start(err)
+	}
+	if err == nil {
+		close(spawned)
+		if command.config.IsolateNetwork {
+			if forwardErr := command.startPortForwarding(); forwardErr != nil {
+				command.logger.Warnf("command[%s] ports could not be forwarded: %s", command.id, forwardErr)
+			}
+		}
+		var timedOut int32
+		if command.config.Timeout > 0 {
+			cmd := command.cmd
+			timer := time.AfterFunc(command.config.Timeout, func() {
+				atomic.StoreInt32(&timedOut, 1)
+				command.logger.Warnf("command[%s] did not exit within %v - killing it", command.id, command.config.Timeout)
+				if killErr := signalProcess(cmd, os.Kill); killErr != nil {
+					command.logger.Warn(killErr)
+				}
+			})
+			defer timer.Stop()
+		}
+		err = command.cmd.Wait()
+		command.stopPortForwarding()
+		if atomic.LoadInt32(&timedOut) == 1 {
+			err = fmt.Errorf("timed out after %v", command.config.Timeout)
+		}
+	}
+	if command.pty != nil {
+		command.pty.wait()
+	}
+	for _, output := range command.outputs {
+		output.Flush()
+	}
+	if command.grouped != nil {
+		header := "> " + strings.TrimSpace(command.config.Application+" "+strings.Join(command.config.Arguments, " "))
+		if flushErr := command.grouped.Flush(header); flushErr != nil {
+			command.logger.Warnf("command[%s] output could not be written: %s", command.id, flushErr)
+		}
+	}
+	close(exited)
+	run <- command.getSuccessError(err)
 }
 
 // handleStopped processes the end of a command as reported
 // by (*exec.Cmd).Run or (*exec.Cmd).Wait
 func (command *Command) handleStopped(terminateCommand error) {
 	command.logger.Tracef("command[%s] is exiting (%v)", command.id, terminateCommand)
-	pid := -1
-	if command.cmd.Process != nil {
-		pid = command.cmd.Process.Pid
-	}
+	pid := command.getPid()
 	command.logger.Infof(
 		"\n%s %s pid:%v id:%s %s",
 		CommandProcessStopSymbol,
@@ -236,6 +777,9 @@ func (command *Command) handleStopped(terminateCommand error) {
 		command.id,
 		CommandProcessStopSymbol,
 	)
+	command.stateMutex.Lock()
 	command.stopped = true
-	command.status <- terminateCommand
+	status := command.status
+	command.stateMutex.Unlock()
+	status <- terminateCommand
 }
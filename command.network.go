@@ -0,0 +1,90 @@
+package godev
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PortForward forwards connections on HostPort of the host to
+// ChildPort inside a command's private network namespace
+type PortForward struct {
+	HostPort  int
+	ChildPort int
+}
+
+// String returns the port forward in the form "host:child"
+func (portForward PortForward) String() string {
+	return fmt.Sprintf("%v:%v", portForward.HostPort, portForward.ChildPort)
+}
+
+// parsePortForwards parses :values in the form "<host port>:<child port>"
+// or "<port>" when both ports are the same
+func parsePortForwards(values []string) ([]PortForward, error) {
+	var portForwards []PortForward
+	for _, value := range values {
+		sections := strings.SplitN(value, ":", 2)
+		var ports []int
+		for _, section := range sections {
+			port, err := strconv.Atoi(strings.TrimSpace(section))
+			if err != nil || port < 1 || port > 65535 {
+				return nil, fmt.Errorf("'%s' should be in the form <host port>:<child port>", value)
+			}
+			ports = append(ports, port)
+		}
+		portForward := PortForward{HostPort: ports[0], ChildPort: ports[0]}
+		if len(ports) > 1 {
+			portForward.ChildPort = ports[1]
+		}
+		portForwards = append(portForwards, portForward)
+	}
+	return portForwards, nil
+}
+
+// forwardConnections accepts connections on :listener and pipes each
+// of them to a connection created by :dial until :listener is closed
+func forwardConnections(listener net.Listener, dial func() (net.Conn, error), logger *Logger) {
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(downstream net.Conn) {
+			defer downstream.Close()
+			upstream, err := dial()
+			if err != nil {
+				logger.Warnf("unable to forward connection from %s: %s", downstream.RemoteAddr(), err)
+				return
+			}
+			defer upstream.Close()
+			var waitGroup sync.WaitGroup
+			waitGroup.Add(2)
+			go pipeConnection(&waitGroup, upstream, downstream)
+			go pipeConnection(&waitGroup, downstream, upstream)
+			waitGroup.Wait()
+		}(connection)
+	}
+}
+
+// pipeConnection copies from :source to :destination and closes the
+// write side of :destination when :source is done
+func pipeConnection(waitGroup *sync.WaitGroup, destination net.Conn, source net.Conn) {
+	defer waitGroup.Done()
+	io.Copy(destination, source)
+	if tcpConnection, ok := destination.(*net.TCPConn); ok {
+		tcpConnection.CloseWrite()
+	} else {
+		destination.Close()
+	}
+}
+
+// stopPortForwarding closes all port forwarding listeners of the command
+func (command *Command) stopPortForwarding() {
+	for _, listener := range command.forwarders {
+		listener.Close()
+	}
+	command.forwarders = nil
+}
@@ -0,0 +1,166 @@
+//go:build linux
+// +build linux
+
+package godev
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// canIsolateNetwork returns an error when commands cannot be started in
+// a private network namespace
+func canIsolateNetwork() error {
+	if os.Geteuid() != 0 {
+		return errors.New("--isolate-network requires godev to be run as root")
+	}
+	return nil
+}
+
+// startInIsolatedNetwork starts the command in a private network
+// namespace whose loopback interface is brought up before the command
+// starts so that it can bind to it straight away - the namespace is
+// created on a locked thread which the command is forked from
+func (command *Command) startInIsolatedNetwork() error {
+	result := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		originalNamespace, err := os.Open(fmt.Sprintf("/proc/self/task/%v/ns/net", unix.Gettid()))
+		if err != nil {
+			runtime.UnlockOSThread()
+			result <- err
+			return
+		}
+		defer originalNamespace.Close()
+		if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+			runtime.UnlockOSThread()
+			result <- fmt.Errorf("unable to create a network namespace: %s", err)
+			return
+		}
+		if err = bringUpLoopback(); err != nil {
+			err = fmt.Errorf("unable to bring up the loopback interface: %s", err)
+		} else {
+			err = command.cmd.Start()
+		}
+		// the thread is left locked if it cannot be restored so that
+		// the runtime discards it when this goroutine exits
+		if unix.Setns(int(originalNamespace.Fd()), unix.CLONE_NEWNET) == nil {
+			runtime.UnlockOSThread()
+		}
+		result <- err
+	}()
+	return <-result
+}
+
+// denyNetwork modifies :sysProcAttr so that the command is started in a
+// network namespace without any network access, unprivileged users get
+// a user namespace which maps them to themselves for that
+func denyNetwork(sysProcAttr *syscall.SysProcAttr) (*syscall.SysProcAttr, error) {
+	if sysProcAttr == nil {
+		sysProcAttr = &syscall.SysProcAttr{}
+	}
+	sysProcAttr.Cloneflags |= syscall.CLONE_NEWNET
+	if os.Geteuid() != 0 {
+		if sysProcAttr.Credential != nil {
+			return nil, errors.New("commands without network access can only be run as another user when godev is run as root")
+		}
+		sysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER
+		sysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		sysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	}
+	return sysProcAttr, nil
+}
+
+// startPortForwarding forwards the configured ports into the command's
+// network namespace
+func (command *Command) startPortForwarding() error {
+	namespacePath := fmt.Sprintf("/proc/%v/ns/net", command.cmd.Process.Pid)
+	for _, portForward := range command.config.ForwardedPorts {
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%v", portForward.HostPort))
+		if err != nil {
+			command.stopPortForwarding()
+			return err
+		}
+		command.forwarders = append(command.forwarders, listener)
+		childAddress := fmt.Sprintf("127.0.0.1:%v", portForward.ChildPort)
+		go forwardConnections(listener, func() (net.Conn, error) {
+			var connection net.Conn
+			err := inNetworkNamespace(namespacePath, func() error {
+				var err error
+				connection, err = net.Dial("tcp", childAddress)
+				return err
+			})
+			return connection, err
+		}, command.logger)
+		command.logger.Debugf("command[%s] forwarding 127.0.0.1:%v to port %v", command.id, portForward.HostPort, portForward.ChildPort)
+	}
+	return nil
+}
+
+// inNetworkNamespace runs :handler on a thread which has joined the
+// network namespace at :namespacePath - sockets created by :handler
+// stay in that namespace after it returns
+func inNetworkNamespace(namespacePath string, handler func() error) error {
+	result := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		originalNamespace, err := os.Open(fmt.Sprintf("/proc/self/task/%v/ns/net", unix.Gettid()))
+		if err != nil {
+			runtime.UnlockOSThread()
+			result <- err
+			return
+		}
+		defer originalNamespace.Close()
+		targetNamespace, err := os.Open(namespacePath)
+		if err != nil {
+			runtime.UnlockOSThread()
+			result <- err
+			return
+		}
+		defer targetNamespace.Close()
+		if err := unix.Setns(int(targetNamespace.Fd()), unix.CLONE_NEWNET); err != nil {
+			runtime.UnlockOSThread()
+			result <- err
+			return
+		}
+		err = handler()
+		// the thread is left locked if it cannot be restored so that
+		// the runtime discards it when this goroutine exits
+		if unix.Setns(int(originalNamespace.Fd()), unix.CLONE_NEWNET) == nil {
+			runtime.UnlockOSThread()
+		}
+		result <- err
+	}()
+	return <-result
+}
+
+// bringUpLoopback sets the loopback interface of the current network
+// namespace to be up since new namespaces start with it down
+func bringUpLoopback() error {
+	socket, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(socket)
+	var request struct {
+		name  [unix.IFNAMSIZ]byte
+		flags uint16
+		_     [22]byte
+	}
+	copy(request.name[:], "lo")
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(socket), unix.SIOCGIFFLAGS, uintptr(unsafe.Pointer(&request))); errno != 0 {
+		return errno
+	}
+	request.flags |= unix.IFF_UP
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(socket), unix.SIOCSIFFLAGS, uintptr(unsafe.Pointer(&request))); errno != 0 {
+		return errno
+	}
+	return nil
+}
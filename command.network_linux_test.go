@@ -0,0 +1,89 @@
+//go:build linux
+// +build linux
+
+package godev
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *CommandNetworkTestSuite) Test_inNetworkNamespace() {
+	t := s.T()
+	if os.Geteuid() != 0 {
+		t.Skip("network namespaces require root")
+	}
+	assert.Nil(t, canIsolateNetwork())
+	command := &Command{cmd: exec.Command("sleep", "10"), logger: s.logger}
+	assert.Nil(t, command.startInIsolatedNetwork())
+	cmd := command.cmd
+	defer cmd.Process.Kill()
+	namespacePath := fmt.Sprintf("/proc/%v/ns/net", cmd.Process.Pid)
+	var err error
+	var childListener net.Listener
+	assert.Nil(t, inNetworkNamespace(namespacePath, func() error {
+		childListener, err = net.Listen("tcp", "127.0.0.1:0")
+		return err
+	}))
+	defer childListener.Close()
+	assert.Nil(t, inNetworkNamespace(namespacePath, func() error {
+		connection, err := net.Dial("tcp", childListener.Addr().String())
+		if err == nil {
+			connection.Close()
+		}
+		return err
+	}), "expected the loopback interface to be up when the command starts")
+	go func() {
+		for {
+			connection, err := childListener.Accept()
+			if err != nil {
+				return
+			}
+			connection.Write([]byte("from the namespace\n"))
+			connection.Close()
+		}
+	}()
+	_, err = net.Dial("tcp", childListener.Addr().String())
+	assert.NotNil(t, err, "expected the child's port to be unreachable from the host network")
+	hostListener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer hostListener.Close()
+	go forwardConnections(hostListener, func() (net.Conn, error) {
+		var connection net.Conn
+		err := inNetworkNamespace(namespacePath, func() error {
+			var err error
+			connection, err = net.Dial("tcp", childListener.Addr().String())
+			return err
+		})
+		return connection, err
+	}, s.logger)
+	connection, err := net.Dial("tcp", hostListener.Addr().String())
+	assert.Nil(t, err)
+	defer connection.Close()
+	response, err := bufio.NewReader(connection).ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "from the namespace\n", response)
+}
+
+func (s *CommandNetworkTestSuite) Test_denyNetwork() {
+	t := s.T()
+	sysProcAttr, err := denyNetwork(nil)
+	if err != nil {
+		t.Skipf("network access cannot be denied here: %s", err)
+	}
+	cmd := exec.Command("cat", "/proc/self/net/dev")
+	cmd.SysProcAttr = sysProcAttr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Skipf("namespaces are not available here: %s", err)
+	}
+	interfaces := strings.Split(strings.TrimSpace(string(output)), "\n")[2:]
+	assert.Len(t, interfaces, 1, "expected only the loopback interface")
+	assert.Contains(t, interfaces[0], "lo:")
+}
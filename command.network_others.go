@@ -0,0 +1,30 @@
+//go:build !linux
+// +build !linux
+
+package godev
+
+import (
+	"errors"
+	"syscall"
+)
+
+// canIsolateNetwork is only available on linux
+func canIsolateNetwork() error {
+	return errors.New("--isolate-network is only supported on linux")
+}
+
+// startInIsolatedNetwork is only available on linux
+func (command *Command) startInIsolatedNetwork() error {
+	return canIsolateNetwork()
+}
+
+// denyNetwork is only available on linux, commands which should not
+// have network access are not run elsewhere
+func denyNetwork(sysProcAttr *syscall.SysProcAttr) (*syscall.SysProcAttr, error) {
+	return nil, errors.New("commands can only be run without network access on linux")
+}
+
+// startPortForwarding is only available on linux
+func (command *Command) startPortForwarding() error {
+	return errors.New("--isolate-network is only supported on linux")
+}
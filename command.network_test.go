@@ -0,0 +1,68 @@
+package godev
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommandNetworkTestSuite struct {
+	suite.Suite
+	logs   bytes.Buffer
+	logger *Logger
+}
+
+func TestCommandNetwork(t *testing.T) {
+	suite.Run(t, new(CommandNetworkTestSuite))
+}
+
+func (s *CommandNetworkTestSuite) SetupTest() {
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "CommandNetworkTestSuite", Format: "production", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *CommandNetworkTestSuite) Test_parsePortForwards() {
+	t := s.T()
+	portForwards, err := parsePortForwards([]string{"18080:8080", "9000"})
+	assert.Nil(t, err)
+	assert.Equal(t, []PortForward{{HostPort: 18080, ChildPort: 8080}, {HostPort: 9000, ChildPort: 9000}}, portForwards)
+	assert.Equal(t, "18080:8080", portForwards[0].String())
+	for _, invalid := range []string{"", "abc", "8080:", "0:80", "80:70000"} {
+		_, err = parsePortForwards([]string{invalid})
+		assert.NotNilf(t, err, "expected '%s' to be invalid", invalid)
+	}
+}
+
+func (s *CommandNetworkTestSuite) Test_forwardConnections() {
+	t := s.T()
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer upstream.Close()
+	go func() {
+		connection, err := upstream.Accept()
+		if err != nil {
+			return
+		}
+		defer connection.Close()
+		line, _ := bufio.NewReader(connection).ReadString('\n')
+		connection.Write([]byte("echo: " + line))
+	}()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	go forwardConnections(listener, func() (net.Conn, error) {
+		return net.Dial("tcp", upstream.Addr().String())
+	}, s.logger)
+	connection, err := net.Dial("tcp", listener.Addr().String())
+	assert.Nil(t, err)
+	defer connection.Close()
+	connection.Write([]byte("hello\n"))
+	response, err := bufio.NewReader(connection).ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "echo: hello\n", response)
+}
@@ -0,0 +1,282 @@
+package godev
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// LogLevelCounter is a thread-safe counter of log lines by level
+type LogLevelCounter struct {
+	counts map[LogLevel]int
+	mutex  sync.Mutex
+}
+
+// Add increments the count for :level
+func (counter *LogLevelCounter) Add(level LogLevel) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	counter.counts[level]++
+}
+
+// Get returns the count for :level
+func (counter *LogLevelCounter) Get(level LogLevel) int {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	return counter.counts[level]
+}
+
+// String returns the counts in the form "error=1 info=20", or an
+// empty string if nothing was counted
+func (counter *LogLevelCounter) String() string {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	var summary []string
+	for level, count := range counter.counts {
+		summary = append(summary, fmt.Sprintf("%s=%v", level, count))
+	}
+	sort.Strings(summary)
+	return strings.Join(summary, " ")
+}
+
+// OutputLabelColors are the colours of the labels of the output of the
+// commands, which are assigned to the commands in turn
+var OutputLabelColors = []string{"cyan", "violet", "lgreen", "lblue", "lyellow", "lred", "lcyan", "lviolet"}
+
+// outputLabelWord matches the words which describe what a command does
+// after the name of its application (eg. test in go test) as opposed to
+// flags and paths
+var outputLabelWord = regexp.MustCompile(`^[a-zA-Z][\w:-]*$`)
+
+// getOutputLabel returns a short label for the output of :command in the
+// execution group with the 1-based :groupIndex (eg. 2:go test)
+func getOutputLabel(groupIndex int, command string) string {
+	trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(command), ShellCommandPrefix))
+	words, err := shellquote.Split(trimmed)
+	if err != nil || len(words) == 0 {
+		words = strings.Fields(trimmed)
+	}
+	if len(words) == 0 {
+		return fmt.Sprintf("%v", groupIndex)
+	}
+	label := path.Base(words[0])
+	if len(words) > 1 && outputLabelWord.MatchString(words[1]) {
+		label += " " + words[1]
+	}
+	return fmt.Sprintf("%v:%s", groupIndex, label)
+}
+
+// CommandStderrColor is the colour of the lines of the stderr of the
+// commands which are not logs, so that they stand out from stdout
+const CommandStderrColor = "lred"
+
+// CommandOutputConfig configures CommandOutput
+type CommandOutputConfig struct {
+	Name   string
+	Parser LogParser
+	Level  LogLevel
+	// Prefix is written before each line of output, it is usually the
+	// coloured label of the command
+	Prefix         string
+	Writer         io.Writer
+	DetectFindings bool
+	// IsStderr renders the lines which are not logs in the
+	// CommandStderrColor when Colored is set
+	IsStderr bool
+	// Colored colours the lines of stderr and lint findings, it is set
+	// when the output goes to a terminal and colours are not disabled
+	Colored        bool
+	ReadyPattern   *regexp.Regexp
+	OnReady        func()
+	SuccessPattern *regexp.Regexp
+	OnSuccessMatch func()
+	// RunState tags the lines, collects the lint findings and counts the
+	// levels of the log lines, it defaults to DefaultRunState
+	RunState *RunState
+}
+
+// InitCommandOutput creates a writer which processes the output of a
+// child process line by line before writing it to the configured writer
+func InitCommandOutput(config *CommandOutputConfig) *CommandOutput {
+	output := &CommandOutput{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:   "output",
+			Format: "production",
+			Level:  config.Level,
+			AdditionalFields: &map[string]interface{}{
+				"submodule": config.Name,
+			},
+			RunState: config.RunState,
+		}),
+	}
+	output.logger.SetOutput(config.Writer)
+	return output
+}
+
+// CommandOutput is an io.Writer for child process output
+type CommandOutput struct {
+	config *CommandOutputConfig
+	logger *Logger
+	buffer bytes.Buffer
+	mutex  sync.Mutex
+}
+
+// Write implements io.Writer - complete lines are processed immediately
+// while partial lines are held until a newline arrives or Flush is called
+func (output *CommandOutput) Write(data []byte) (int, error) {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	output.buffer.Write(data)
+	for {
+		newlineIndex := bytes.IndexByte(output.buffer.Bytes(), '\n')
+		if newlineIndex < 0 {
+			break
+		}
+		line := string(output.buffer.Next(newlineIndex + 1))
+		output.handleLine(strings.TrimRight(line, "\r\n"))
+	}
+	return len(data), nil
+}
+
+// Flush processes any partial line remaining in the buffer
+func (output *CommandOutput) Flush() {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	if output.buffer.Len() > 0 {
+		output.handleLine(output.buffer.String())
+		output.buffer.Reset()
+	}
+}
+
+func (output *CommandOutput) handleLine(line string) {
+	if output.config.ReadyPattern != nil && output.config.OnReady != nil && output.config.ReadyPattern.MatchString(line) {
+		output.config.OnReady()
+	}
+	if output.config.SuccessPattern != nil && output.config.OnSuccessMatch != nil && output.config.SuccessPattern.MatchString(line) {
+		output.config.OnSuccessMatch()
+	}
+	state := output.config.RunState.get()
+	tag := state.RunTags.Get()
+	if len(tag) > 0 {
+		tag += "| "
+	}
+	tag += output.config.Prefix
+	if output.config.DetectFindings {
+		if finding, ok := ParseLintFinding(line); ok {
+			state.LintFindings.Add(finding)
+			if output.config.Colored {
+				line = Color("yellow", line)
+			}
+			fmt.Fprintln(output.config.Writer, tag+line)
+			return
+		}
+	}
+	if parsed, ok := output.config.Parser.Parse(line); ok {
+		state.LogCounts.Add(parsed.Level)
+		level := parsed.Level
+		if level == "panic" {
+			// logrus panics when logging at the panic level
+			level = "fatal"
+		}
+		output.logger.Log(level, output.config.Prefix+parsed.String())
+		return
+	}
+	if output.config.IsStderr && output.config.Colored && len(line) > 0 {
+		line = Color(CommandStderrColor, line)
+	}
+	fmt.Fprintln(output.config.Writer, tag+line)
+}
+
+// GroupedOutputMemoryLimit is the number of bytes of output which a
+// command with output=grouped holds in memory, the rest of its output
+// is spilled to a temporary file until it is flushed
+const GroupedOutputMemoryLimit = 1024 * 1024
+
+// groupedOutput holds the output of a command which runs with
+// output=grouped so that it is written in one piece when the command
+// exits instead of interleaving with that of the commands running
+// alongside it. Up to :limit bytes are held in memory, the rest is
+// spilled to a temporary file and when that fails it is dropped. The
+// outputs of the commands of one RunState are flushed one at a time
+type groupedOutput struct {
+	buffer  bytes.Buffer
+	dropped int
+	limit   int
+	mutex   sync.Mutex
+	spill   *os.File
+	state   *RunState
+	writer  io.Writer
+}
+
+func (output *groupedOutput) Write(data []byte) (int, error) {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	if output.spill == nil && output.dropped == 0 && output.buffer.Len()+len(data) <= output.limit {
+		return output.buffer.Write(data)
+	}
+	if output.spill == nil && output.dropped == 0 {
+		if spill, err := ioutil.TempFile("", "godev-output-"); err == nil {
+			output.spill = spill
+		}
+	}
+	if output.spill == nil || output.dropped > 0 {
+		output.dropped += len(data)
+	} else if written, err := output.spill.Write(data); err != nil {
+		output.dropped += len(data) - written
+	}
+	return len(data), nil
+}
+
+// Flush writes :header followed by the held output, nothing is written
+// when there is no output
+func (output *groupedOutput) Flush(header string) error {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	if output.buffer.Len() == 0 && output.spill == nil && output.dropped == 0 {
+		return nil
+	}
+	state := output.state.get()
+	state.groupedOutputMutex.Lock()
+	defer state.groupedOutputMutex.Unlock()
+	defer output.reset()
+	if _, err := fmt.Fprintln(output.writer, header); err != nil {
+		return err
+	}
+	if _, err := output.writer.Write(output.buffer.Bytes()); err != nil {
+		return err
+	}
+	if output.spill != nil {
+		if _, err := output.spill.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("unable to read the spilled output: %s", err)
+		}
+		if _, err := io.Copy(output.writer, output.spill); err != nil {
+			return err
+		}
+	}
+	if output.dropped > 0 {
+		_, err := fmt.Fprintf(output.writer, "... %d bytes of output could not be held and were dropped\n", output.dropped)
+		return err
+	}
+	return nil
+}
+
+// reset clears the held output and removes the file it was spilled to
+func (output *groupedOutput) reset() {
+	output.buffer.Reset()
+	output.dropped = 0
+	if output.spill != nil {
+		output.spill.Close()
+		os.Remove(output.spill.Name())
+		output.spill = nil
+	}
+}
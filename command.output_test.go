@@ -0,0 +1,219 @@
+package godev
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommandOutputTestSuite struct {
+	suite.Suite
+	logs bytes.Buffer
+}
+
+func TestCommandOutput(t *testing.T) {
+	suite.Run(t, new(CommandOutputTestSuite))
+}
+
+func (s *CommandOutputTestSuite) SetupTest() {
+	s.logs.Reset()
+}
+
+func (s *CommandOutputTestSuite) TestWrite_rendersParsedLines() {
+	t := s.T()
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:   "app",
+		Parser: LogParserJSON,
+		Level:  "trace",
+		Writer: &s.logs,
+	})
+	output.Write([]byte(`{"level":"info","msg":"hello"}` + "\n" + `plain text` + "\n"))
+	logs := s.logs.String()
+	assert.Contains(t, logs, "[output/app] hello")
+	assert.Contains(t, logs, "plain text\n")
+}
+
+func (s *CommandOutputTestSuite) TestWrite_buffersPartialLines() {
+	t := s.T()
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:   "app",
+		Parser: LogParserLogfmt,
+		Level:  "trace",
+		Writer: &s.logs,
+	})
+	output.Write([]byte(`level=info msg=`))
+	assert.Empty(t, s.logs.String())
+	output.Write([]byte("partial\nno newline"))
+	assert.Contains(t, s.logs.String(), "partial")
+	assert.NotContains(t, s.logs.String(), "no newline")
+	output.Flush()
+	assert.Contains(t, s.logs.String(), "no newline")
+}
+
+func (s *CommandOutputTestSuite) TestWrite_filtersByLevel() {
+	t := s.T()
+	state := InitRunState()
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:     "app",
+		Parser:   LogParserLogfmt,
+		Level:    "warn",
+		Writer:   &s.logs,
+		RunState: state,
+	})
+	output.Write([]byte("level=debug msg=hidden\nlevel=error msg=shown\n"))
+	assert.NotContains(t, s.logs.String(), "hidden")
+	assert.Contains(t, s.logs.String(), "shown")
+	assert.Equal(t, 1, state.LogCounts.Get("debug"))
+	assert.Equal(t, 0, DefaultRunState.LogCounts.Get("debug"), "expected the lines to be counted in the given state only")
+}
+
+func (s *CommandOutputTestSuite) TestLogLevelCounter() {
+	t := s.T()
+	counter := &LogLevelCounter{counts: map[LogLevel]int{}}
+	assert.Equal(t, "", counter.String())
+	counter.Add("warn")
+	counter.Add("error")
+	counter.Add("warn")
+	assert.Equal(t, 2, counter.Get("warn"))
+	assert.Equal(t, "error=1 warn=2", counter.String())
+}
+
+func (s *CommandOutputTestSuite) TestWrite_detectsFindings() {
+	t := s.T()
+	state := InitRunState()
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:           "go",
+		Writer:         &s.logs,
+		DetectFindings: true,
+		Colored:        true,
+		RunState:       state,
+	})
+	output.Write([]byte("# github.com/zephinzer/godev\n./main.go:1:2: unreachable code\n"))
+	assert.Equal(t, 1, state.LintFindings.Count())
+	assert.Contains(t, s.logs.String(), Color("yellow", "./main.go:1:2: unreachable code"))
+}
+
+func (s *CommandOutputTestSuite) TestWrite_prefixesLines() {
+	t := s.T()
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:   "app",
+		Parser: LogParserLogfmt,
+		Level:  "trace",
+		Prefix: "[2:go test] ",
+		Writer: &s.logs,
+	})
+	output.Write([]byte("plain text\nlevel=info msg=hello\n"))
+	logs := s.logs.String()
+	assert.Contains(t, logs, "[2:go test] plain text\n")
+	assert.Contains(t, logs, "[2:go test] hello")
+}
+
+func (s *CommandOutputTestSuite) TestWrite_coloursStderr() {
+	t := s.T()
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:     "app",
+		Parser:   LogParserLogfmt,
+		Level:    "trace",
+		Writer:   &s.logs,
+		IsStderr: true,
+		Colored:  true,
+	})
+	output.Write([]byte("panic: oops\nlevel=info msg=hello\n\n"))
+	logs := s.logs.String()
+	assert.Contains(t, logs, Color(CommandStderrColor, "panic: oops")+"\n")
+	assert.Contains(t, logs, "[output/app] hello")
+	assert.NotContains(t, logs, Color(CommandStderrColor, "hello"))
+	assert.NotContains(t, logs, Color(CommandStderrColor, ""), "expected empty lines to be left uncoloured")
+}
+
+func (s *CommandOutputTestSuite) TestWrite_withoutColors() {
+	t := s.T()
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:           "golint",
+		Writer:         &s.logs,
+		DetectFindings: true,
+		IsStderr:       true,
+	})
+	output.Write([]byte("panic: oops\nmain.go:1:1: exported function Main should have comment\n"))
+	assert.Equal(t, "panic: oops\nmain.go:1:1: exported function Main should have comment\n", s.logs.String(), "expected no colours when the output is not coloured")
+}
+
+func (s *CommandOutputTestSuite) Test_getOutputLabel() {
+	t := s.T()
+	assert.Equal(t, "2:go test", getOutputLabel(2, "go test ./..."))
+	assert.Equal(t, "1:go build", getOutputLabel(1, "  go build -o bin/app"))
+	assert.Equal(t, "3:app", getOutputLabel(3, "./bin/app --port 8080"))
+	assert.Equal(t, "1:make lint", getOutputLabel(1, "sh: make lint && make test"))
+	assert.Equal(t, "4:echo", getOutputLabel(4, `echo "hello world"`))
+	assert.Equal(t, "5", getOutputLabel(5, ""))
+}
+
+func (s *CommandOutputTestSuite) TestWrite_matchesReadyPattern() {
+	t := s.T()
+	readyCount := 0
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:         "app",
+		Level:        "trace",
+		Writer:       &s.logs,
+		ReadyPattern: regexp.MustCompile(`listening on :\d+`),
+		OnReady:      func() { readyCount++ },
+	})
+	output.Write([]byte("starting up\n"))
+	assert.Equal(t, 0, readyCount)
+	output.Write([]byte("grpc server listening on :50051\n"))
+	assert.Equal(t, 1, readyCount)
+	assert.Contains(t, s.logs.String(), "listening on :50051\n")
+}
+
+func (s *CommandOutputTestSuite) TestWrite_matchesSuccessPattern() {
+	t := s.T()
+	matchCount := 0
+	output := InitCommandOutput(&CommandOutputConfig{
+		Name:           "gate",
+		Level:          "trace",
+		Writer:         &s.logs,
+		SuccessPattern: regexp.MustCompile(`^ok\s`),
+		OnSuccessMatch: func() { matchCount++ },
+	})
+	output.Write([]byte("FAIL\tpkg/a\n"))
+	assert.Equal(t, 0, matchCount)
+	output.Write([]byte("ok  \tpkg/b"))
+	output.Flush()
+	assert.Equal(t, 1, matchCount)
+}
+
+func (s *CommandOutputTestSuite) Test_groupedOutput() {
+	t := s.T()
+	output := &groupedOutput{limit: GroupedOutputMemoryLimit, writer: &s.logs}
+	assert.Nil(t, output.Flush("> go vet ./..."))
+	assert.Empty(t, s.logs.String(), "expected nothing to be written without output")
+	output.Write([]byte("main.go:1: unreachable code\n"))
+	output.Write([]byte("exit status 2\n"))
+	assert.Empty(t, s.logs.String(), "expected the output to be held until it is flushed")
+	assert.Nil(t, output.Flush("> go vet ./..."))
+	assert.Equal(t, "> go vet ./...\nmain.go:1: unreachable code\nexit status 2\n", s.logs.String())
+	s.logs.Reset()
+	assert.Nil(t, output.Flush("> go vet ./..."))
+	assert.Empty(t, s.logs.String(), "expected flushed output to be cleared")
+}
+
+func (s *CommandOutputTestSuite) Test_groupedOutput_spillsBeyondItsLimit() {
+	t := s.T()
+	output := &groupedOutput{limit: 8, writer: &s.logs}
+	output.Write([]byte("line 1\n"))
+	assert.Nil(t, output.spill, "expected output within the limit to be held in memory")
+	output.Write([]byte("line 2\n"))
+	output.Write([]byte("line 3\n"))
+	assert.Equal(t, 7, output.buffer.Len(), "expected output beyond the limit not to be held in memory")
+	assert.NotNil(t, output.spill)
+	spillPath := output.spill.Name()
+	assert.Nil(t, output.Flush("> go test ./..."))
+	assert.Equal(t, "> go test ./...\nline 1\nline 2\nline 3\n", s.logs.String())
+	_, err := os.Stat(spillPath)
+	assert.True(t, os.IsNotExist(err), "expected the spilled output to be removed once flushed")
+	assert.Nil(t, output.spill)
+}
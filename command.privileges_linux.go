@@ -0,0 +1,15 @@
+//go:build linux
+// +build linux
+
+package godev
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// setNoNewPrivileges sets the no_new_privs bit on the godev process so
+// that all commands spawned from here on cannot gain privileges through
+// setuid/setgid binaries or file capabilities
+func setNoNewPrivileges() error {
+	return unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package godev
+
+import (
+	"errors"
+)
+
+// setNoNewPrivileges is only available on linux
+func setNoNewPrivileges() error {
+	return errors.New("--no-new-privs is only supported on linux")
+}
@@ -0,0 +1,71 @@
+package godev
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// CommandPtyDrainTimeout is how long the output of a command which runs
+// in a pseudo-terminal is read for after it exits, processes it started
+// which are still running keep the terminal open
+const CommandPtyDrainTimeout = time.Second
+
+// commandPty is the pseudo-terminal a command runs in so that it keeps
+// its colours and progress bars while its output is still processed
+type commandPty struct {
+	master *os.File
+	slave  *os.File
+	writer io.Writer
+	copied chan struct{}
+}
+
+// attachPty runs the command in a new pseudo-terminal whose output is
+// written to the writers of its stdout, stderr is written to the
+// terminal too - the command keeps its pipes when no pseudo-terminal
+// could be opened
+func (command *Command) attachPty() {
+	master, slave, err := openPty()
+	if err != nil {
+		command.logger.Warnf("command[%s] is not run in a pseudo-terminal: %s", command.id, err)
+		return
+	}
+	setPtySize(master, os.Stdout)
+	command.pty = &commandPty{
+		master: master,
+		slave:  slave,
+		writer: command.cmd.Stdout,
+		copied: make(chan struct{}),
+	}
+	command.cmd.Stdin = slave
+	command.cmd.Stdout = slave
+	command.cmd.Stderr = slave
+	command.cmd.SysProcAttr = setPtyProcessAttributes(command.cmd.SysProcAttr)
+}
+
+// start copies the output of the pseudo-terminal once the command has
+// started with it, or closes it when the command could not be started
+func (pty *commandPty) start(startErr error) {
+	pty.slave.Close()
+	if startErr != nil {
+		pty.master.Close()
+		close(pty.copied)
+		return
+	}
+	go func() {
+		// reading fails with EIO on linux once the terminal is closed
+		io.Copy(pty.writer, pty.master)
+		close(pty.copied)
+	}()
+}
+
+// wait waits up to CommandPtyDrainTimeout for the output of the exited
+// command to be copied before closing the pseudo-terminal
+func (pty *commandPty) wait() {
+	select {
+	case <-pty.copied:
+	case <-time.After(CommandPtyDrainTimeout):
+	}
+	pty.master.Close()
+	<-pty.copied
+}
@@ -0,0 +1,23 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package godev
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// openPty is not implemented for this platform yet
+func openPty() (*os.File, *os.File, error) {
+	return nil, nil, errors.New("pseudo-terminals are not supported on this platform")
+}
+
+// setPtyProcessAttributes is not needed without pseudo-terminals
+func setPtyProcessAttributes(sysProcAttr *syscall.SysProcAttr) *syscall.SysProcAttr {
+	return sysProcAttr
+}
+
+// setPtySize is not needed without pseudo-terminals
+func setPtySize(master *os.File, terminal *os.File) {}
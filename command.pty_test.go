@@ -0,0 +1,62 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package godev
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommandPtyTestSuite struct {
+	suite.Suite
+	logs bytes.Buffer
+}
+
+func TestCommandPty(t *testing.T) {
+	suite.Run(t, new(CommandPtyTestSuite))
+}
+
+func (s *CommandPtyTestSuite) SetupTest() {
+	s.logs.Reset()
+	if master, slave, err := openPty(); err != nil {
+		s.T().Skipf("pseudo-terminals are not available: %s", err)
+	} else {
+		master.Close()
+		slave.Close()
+	}
+}
+
+func (s *CommandPtyTestSuite) newCommand(script string) *Command {
+	logger := InitLogger(&LoggerConfig{Name: "CommandPtyTestSuite", Format: "production", Level: "trace"})
+	logger.SetOutput(&s.logs)
+	return &Command{
+		id:     "CommandPtyTestSuiteCommandID",
+		config: &CommandConfig{Application: "sh", Arguments: []string{"-c", script}, Pty: true, Recorder: InitRunRecorder()},
+		logger: logger,
+	}
+}
+
+func (s *CommandPtyTestSuite) Test_attachPty() {
+	t := s.T()
+	command := s.newCommand("test -t 0 && test -t 1 && test -t 2 && echo terminal; echo error >&2")
+	command.handleInitialisation()
+	assert.NotNil(t, command.pty)
+	assert.True(t, command.cmd.SysProcAttr.Setsid)
+	go command.handleStart()
+	assert.Nil(t, <-command.run)
+	assert.Contains(t, command.config.Recorder.Stdout.String(), "terminal\r\n")
+	assert.Contains(t, command.config.Recorder.Stdout.String(), "error\r\n", "expected stderr to be written to the terminal")
+}
+
+func (s *CommandPtyTestSuite) Test_attachPty_startFailure() {
+	t := s.T()
+	command := s.newCommand("")
+	command.config.Application = "/does/not/exist"
+	command.handleInitialisation()
+	go command.handleStart()
+	assert.NotNil(t, <-command.run)
+}
@@ -0,0 +1,58 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package godev
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// DefaultPtyRows and DefaultPtyColumns are the size of the
+// pseudo-terminals of commands when godev is not run in a terminal
+const (
+	DefaultPtyRows    = 24
+	DefaultPtyColumns = 80
+)
+
+// ptyWindowSize is the winsize structure of the TIOCGWINSZ and
+// TIOCSWINSZ ioctls
+type ptyWindowSize struct {
+	Rows    uint16
+	Columns uint16
+	X       uint16
+	Y       uint16
+}
+
+// setPtyProcessAttributes makes the command the leader of a new session
+// whose controlling terminal is its stdin - the session is also its own
+// process group so signals still reach the processes it starts
+func setPtyProcessAttributes(sysProcAttr *syscall.SysProcAttr) *syscall.SysProcAttr {
+	if sysProcAttr == nil {
+		sysProcAttr = &syscall.SysProcAttr{}
+	}
+	sysProcAttr.Setpgid = false
+	sysProcAttr.Setsid = true
+	sysProcAttr.Setctty = true
+	sysProcAttr.Ctty = 0
+	return sysProcAttr
+}
+
+// setPtySize sets the size of the pseudo-terminal :master to that of
+// the terminal :terminal or to the default size when it is not one
+func setPtySize(master *os.File, terminal *os.File) {
+	size := ptyWindowSize{Rows: DefaultPtyRows, Columns: DefaultPtyColumns}
+	if err := ioctl(terminal.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size))); err != nil || size.Rows == 0 || size.Columns == 0 {
+		size = ptyWindowSize{Rows: DefaultPtyRows, Columns: DefaultPtyColumns}
+	}
+	ioctl(master.Fd(), syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&size)))
+}
+
+// ioctl performs the :request on the file descriptor :fd
+func ioctl(fd uintptr, request uintptr, argument uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, argument); errno != 0 {
+		return errno
+	}
+	return nil
+}
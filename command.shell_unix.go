@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+// getShellCommand returns the application and arguments which run a
+// script in the shell, the script is appended to them
+func getShellCommand() []string {
+	return []string{"sh", "-c"}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package godev
+
+// getShellCommand returns the application and arguments which run a
+// script in cmd.exe, the script is appended to them
+func getShellCommand() []string {
+	return []string{"cmd", "/C"}
+}
@@ -0,0 +1,62 @@
+package godev
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+// SnapshotFileName is the name of the file in the state directory which
+// the command creates once it has finished saving its state
+const SnapshotFileName = ".godev-snapshot"
+
+// snapshotPollInterval is how often to check if the snapshot is done
+const snapshotPollInterval = 50 * time.Millisecond
+
+// getSnapshotFile returns the path to the file which marks a completed snapshot
+func (command *Command) getSnapshotFile() string {
+	return path.Join(command.config.StateDirectory, SnapshotFileName)
+}
+
+// getStateEnvironment returns the environment variables which tell the
+// command where to save its state and whether there is state to restore
+func (command *Command) getStateEnvironment() []string {
+	restore := fileExists(command.getSnapshotFile())
+	return []string{
+		fmt.Sprintf("GODEV_STATE_DIR=%s", command.config.StateDirectory),
+		fmt.Sprintf("GODEV_SNAPSHOT_FILE=%s", command.getSnapshotFile()),
+		fmt.Sprintf("GODEV_SNAPSHOT_SIGNAL=%s", getSnapshotSignalName()),
+		fmt.Sprintf("GODEV_RESTORE=%v", restore),
+	}
+}
+
+// handleSnapshot asks the command to save its state and waits until it
+// has done so, it has exited or the snapshot timeout has passed
+func (command *Command) handleSnapshot() {
+	snapshotFile := command.getSnapshotFile()
+	if err := os.Remove(snapshotFile); err != nil && !os.IsNotExist(err) {
+		command.logger.Warnf("command[%s] previous snapshot could not be removed: %s", command.id, err)
+	}
+	signal, err := getSnapshotSignal()
+	if err != nil {
+		command.logger.Warn(err)
+		return
+	}
+	if err := command.cmd.Process.Signal(signal); err != nil {
+		command.logger.Warnf("command[%s] could not be asked to snapshot its state: %s", command.id, err)
+		return
+	}
+	startedAt := time.Now()
+	for time.Since(startedAt) < command.config.SnapshotTimeout {
+		if fileExists(snapshotFile) {
+			command.logger.Infof("command[%s] snapshotted its state in %v", command.id, time.Since(startedAt).Round(time.Millisecond))
+			return
+		}
+		if !command.IsRunning() {
+			break
+		}
+		time.Sleep(snapshotPollInterval)
+	}
+	command.logger.Warnf("command[%s] did not snapshot its state within %v", command.id, command.config.SnapshotTimeout)
+}
@@ -0,0 +1,91 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommandSnapshotTestSuite struct {
+	suite.Suite
+	command        *Command
+	logs           bytes.Buffer
+	stateDirectory string
+}
+
+func TestCommandSnapshot(t *testing.T) {
+	suite.Run(t, new(CommandSnapshotTestSuite))
+}
+
+func (s *CommandSnapshotTestSuite) SetupTest() {
+	stateDirectory, err := ioutil.TempDir("", "godev-state")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.stateDirectory = stateDirectory
+	s.logs.Reset()
+	s.command = InitCommand(&CommandConfig{
+		Application:     "sh",
+		SnapshotTimeout: 2 * time.Second,
+		StateDirectory:  stateDirectory,
+	})
+	s.command.logger.SetOutput(&s.logs)
+}
+
+func (s *CommandSnapshotTestSuite) TearDownTest() {
+	os.RemoveAll(s.stateDirectory)
+}
+
+// startScript starts :script in the background as the command's process
+func (s *CommandSnapshotTestSuite) startScript(script string) {
+	s.command.cmd = exec.Command("sh", "-c", script)
+	s.command.cmd.Env = append(os.Environ(), s.command.getStateEnvironment()...)
+	assert.Nil(s.T(), s.command.cmd.Start())
+	s.command.started = true
+	s.command.stopped = false
+	// give the shell time to install its trap
+	time.Sleep(200 * time.Millisecond)
+}
+
+func (s *CommandSnapshotTestSuite) TestGetStateEnvironment() {
+	t := s.T()
+	snapshotFile := path.Join(s.stateDirectory, SnapshotFileName)
+	environment := s.command.getStateEnvironment()
+	assert.Contains(t, environment, "GODEV_STATE_DIR="+s.stateDirectory)
+	assert.Contains(t, environment, "GODEV_SNAPSHOT_FILE="+snapshotFile)
+	assert.Contains(t, environment, "GODEV_SNAPSHOT_SIGNAL=SIGUSR1")
+	assert.Contains(t, environment, "GODEV_RESTORE=false")
+	assert.Nil(t, ioutil.WriteFile(snapshotFile, []byte{}, 0644))
+	assert.Contains(t, s.command.getStateEnvironment(), "GODEV_RESTORE=true")
+}
+
+func (s *CommandSnapshotTestSuite) Test_handleSnapshot() {
+	t := s.T()
+	s.startScript(`trap 'echo state > "$GODEV_STATE_DIR/cache"; touch "$GODEV_SNAPSHOT_FILE"' USR1; while true; do sleep 0.05; done`)
+	defer s.command.cmd.Process.Kill()
+	s.command.handleSnapshot()
+	assert.True(t, fileExists(path.Join(s.stateDirectory, SnapshotFileName)))
+	assert.True(t, fileExists(path.Join(s.stateDirectory, "cache")))
+	assert.Contains(t, s.logs.String(), "snapshotted its state")
+}
+
+func (s *CommandSnapshotTestSuite) Test_handleSnapshot_timesOut() {
+	t := s.T()
+	s.command.config.SnapshotTimeout = 100 * time.Millisecond
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.stateDirectory, SnapshotFileName), []byte{}, 0644))
+	s.startScript(`trap '' USR1; while true; do sleep 0.05; done`)
+	defer s.command.cmd.Process.Kill()
+	s.command.handleSnapshot()
+	assert.False(t, fileExists(path.Join(s.stateDirectory, SnapshotFileName)), "expected the stale snapshot to be removed")
+	assert.Contains(t, s.logs.String(), "did not snapshot its state within 100ms")
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"os"
+	"syscall"
+)
+
+// getSnapshotSignal returns the signal sent to commands to snapshot their state
+func getSnapshotSignal() (os.Signal, error) {
+	return syscall.SIGUSR1, nil
+}
+
+// getSnapshotSignalName returns the name of the snapshot signal for commands
+func getSnapshotSignalName() string {
+	return "SIGUSR1"
+}
@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"errors"
+	"os"
+)
+
+// getSnapshotSignal is not available on windows which has no SIGUSR1
+func getSnapshotSignal() (os.Signal, error) {
+	return nil, errors.New("snapshotting state is not supported on windows")
+}
+
+// getSnapshotSignalName returns the name of the snapshot signal for commands
+func getSnapshotSignalName() string {
+	return ""
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// ForwardedSignals are the signals godev passes on to the application
+// when it receives them
+var ForwardedSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}
+
+// setProcessGroup makes the command run in its own process group so that
+// signals reach the processes it starts too
+func setProcessGroup(sysProcAttr *syscall.SysProcAttr) *syscall.SysProcAttr {
+	if sysProcAttr == nil {
+		sysProcAttr = &syscall.SysProcAttr{}
+	}
+	sysProcAttr.Setpgid = true
+	return sysProcAttr
+}
+
+// signalProcess sends :signal to the process group of :cmd, or only to
+// its process when it was not started in its own process group or
+// session
+func signalProcess(cmd *exec.Cmd, signal os.Signal) error {
+	if cmd.Process == nil || cmd.Process.Pid <= 0 {
+		return errors.New("the process has not been started")
+	}
+	systemSignal, ok := signal.(syscall.Signal)
+	if !ok || cmd.SysProcAttr == nil || !(cmd.SysProcAttr.Setpgid || cmd.SysProcAttr.Setsid) {
+		return cmd.Process.Signal(signal)
+	}
+	return syscall.Kill(-cmd.Process.Pid, systemSignal)
+}
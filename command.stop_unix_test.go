@@ -0,0 +1,34 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"syscall"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *CommandTestSuite) TestSendSignal() {
+	t := s.T()
+	s.command.config.Application = "sh"
+	s.command.config.Arguments = []string{"-c", `trap "exit 0" USR1; while true; do sleep 0.05; done`}
+	s.startProcess()
+	startedAt := time.Now()
+	assert.Nil(t, s.command.SendSignal(syscall.SIGUSR1))
+	assert.Nil(t, <-s.command.run, "expected the command to handle the signal")
+	assert.True(t, time.Since(startedAt) < time.Second)
+}
+
+func (s *CommandTestSuite) TestSendStopSignal() {
+	t := s.T()
+	s.command.config.Application = "sleep"
+	s.command.config.Arguments = []string{"5"}
+	s.command.config.StopSignal = syscall.SIGINT
+	s.startProcess()
+	go s.command.SendStopSignal(syscall.SIGTERM)
+	assert.Equal(t, syscall.SIGTERM, <-s.command.signal, "expected the signal to be sent instead of the stop signal")
+	assert.Nil(t, s.command.stopProcess(syscall.SIGTERM))
+	assert.NotNil(t, <-s.command.run)
+}
@@ -0,0 +1,33 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// ForwardedSignals are the signals godev passes on to the application
+// when it receives them, windows has none of them
+var ForwardedSignals = []os.Signal{}
+
+// setProcessGroup is a no-op on windows where processes do not have
+// process groups
+func setProcessGroup(sysProcAttr *syscall.SysProcAttr) *syscall.SysProcAttr {
+	return sysProcAttr
+}
+
+// signalProcess sends :signal to the process of :cmd, windows only
+// supports killing processes
+func signalProcess(cmd *exec.Cmd, signal os.Signal) error {
+	if cmd.Process == nil || cmd.Process.Pid <= 0 {
+		return errors.New("the process has not been started")
+	}
+	if signal == os.Kill {
+		return cmd.Process.Kill()
+	}
+	return cmd.Process.Signal(signal)
+}
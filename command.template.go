@@ -0,0 +1,390 @@
+package godev
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	shellquote "github.com/kballard/go-shellquote"
+)
+
+// CommandChangedFilesEnvVar is the environment variable which holds the
+// changed files of the pipeline separated by newlines
+const CommandChangedFilesEnvVar = "GODEV_CHANGED_FILES"
+
+// CommandTemplateFields are the placeholders of CommandTemplateData,
+// arguments with templates which refer to anything else are passed on
+// unchanged to the commands which use templates of their own (eg. the
+// {{.Dir}} of go list -f)
+var CommandTemplateFields = map[string]bool{
+	"BuildOutput":      true,
+	"ChangedFiles":     true,
+	"ChangedPackages":  true,
+	"Env":              true,
+	"GeneratePackages": true,
+	"GitCommit":        true,
+	"GitDescribe":      true,
+	"Timestamp":        true,
+	"WatchDirectory":   true,
+	"WorkDirectory":    true,
+}
+
+// CommandTemplateData is what the arguments of commands can refer to
+// with template placeholders (eg. {{.ChangedFiles}})
+type CommandTemplateData struct {
+	CommandTemplateSession
+	// ChangedFiles are the absolute paths of the files whose changes
+	// triggered the pipeline, empty when it was triggered otherwise
+	ChangedFiles CommandTemplateList
+	// ChangedPackages are the packages of the changed files relative to
+	// the directory of the command, the closest directory with .go files
+	// for other files, ./... when there is none or the pipeline was
+	// triggered otherwise
+	ChangedPackages CommandTemplateList
+	// GeneratePackages are the directories of the changed .go files with
+	// go:generate directives relative to the directory of the command,
+	// ./... when a changed file is not a .go file or the pipeline was
+	// triggered otherwise
+	GeneratePackages CommandTemplateList
+	// Env holds the environment variables of the command, unset ones
+	// render as nothing (eg. {{.Env.PORT}})
+	Env map[string]CommandTemplateValue
+	// Timestamp is when the command was started in RFC 3339 format
+	// and UTC
+	Timestamp CommandTemplateValue
+	directory string
+	// quoted has the values of git quoted for a shell like the others
+	// of quoteForShell
+	quoted bool
+}
+
+// CommandTemplateSession holds the values of the godev session that
+// commands can refer to with template placeholders
+type CommandTemplateSession struct {
+	BuildOutput    CommandTemplateValue
+	WatchDirectory CommandTemplateValue
+	WorkDirectory  CommandTemplateValue
+}
+
+// GitCommit returns the commit checked out in the directory of the
+// command, empty when it is not in a git repository
+func (data *CommandTemplateData) GitCommit() CommandTemplateValue {
+	return data.git("rev-parse", "HEAD")
+}
+
+// GitDescribe returns the closest tag of the commit checked out in the
+// directory of the command as described by git (eg. v1.2.0-3-g1a2b3c4
+// or v1.2.0-dirty), the abbreviated commit when there are no tags and
+// empty when it is not in a git repository
+func (data *CommandTemplateData) GitDescribe() CommandTemplateValue {
+	return data.git("describe", "--tags", "--always", "--dirty")
+}
+
+// git returns the trimmed output of git run with :arguments in the
+// directory of the command, empty when it fails
+func (data *CommandTemplateData) git(arguments ...string) CommandTemplateValue {
+	if len(data.directory) == 0 {
+		return ""
+	}
+	cmd := exec.Command("git", arguments...)
+	cmd.Dir = data.directory
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	value := CommandTemplateValue(strings.TrimSpace(string(output)))
+	if data.quoted {
+		return value.quote()
+	}
+	return value
+}
+
+// quoteForShell returns a copy of :data with its values quoted for a
+// shell, which is what the scripts of commands run with sh -c refer to
+func (data *CommandTemplateData) quoteForShell() *CommandTemplateData {
+	quoted := *data
+	quoted.quoted = true
+	quoted.BuildOutput = data.BuildOutput.quote()
+	quoted.WatchDirectory = data.WatchDirectory.quote()
+	quoted.WorkDirectory = data.WorkDirectory.quote()
+	quoted.Timestamp = data.Timestamp.quote()
+	quoted.ChangedFiles = data.ChangedFiles.quote()
+	quoted.ChangedPackages = data.ChangedPackages.quote()
+	quoted.GeneratePackages = data.GeneratePackages.quote()
+	quoted.Env = map[string]CommandTemplateValue{}
+	for key, value := range data.Env {
+		quoted.Env[key] = value.quote()
+	}
+	return &quoted
+}
+
+// CommandTemplateList renders as its values separated by spaces, an
+// argument which is only a CommandTemplateList becomes one argument per
+// value instead
+type CommandTemplateList []string
+
+func (list CommandTemplateList) String() string {
+	return strings.Join(list, " ")
+}
+
+// quote returns the values of :list quoted for a shell
+func (list CommandTemplateList) quote() CommandTemplateList {
+	quoted := CommandTemplateList{}
+	for _, value := range list {
+		quoted = append(quoted, shellquote.Join(value))
+	}
+	return quoted
+}
+
+// CommandTemplateValue renders as its value as it is since arguments are
+// not passed through a shell
+type CommandTemplateValue string
+
+func (value CommandTemplateValue) String() string {
+	return string(value)
+}
+
+// quote returns :value quoted for a shell, empty values stay empty
+func (value CommandTemplateValue) quote() CommandTemplateValue {
+	if len(value) == 0 {
+		return ""
+	}
+	return CommandTemplateValue(shellquote.Join(string(value)))
+}
+
+// getList returns the CommandTemplateList named :name, false when there
+// is no list with that name
+func (data *CommandTemplateData) getList(name string) (CommandTemplateList, bool) {
+	switch name {
+	case "ChangedFiles":
+		return data.ChangedFiles, true
+	case "ChangedPackages":
+		return data.ChangedPackages, true
+	case "GeneratePackages":
+		return data.GeneratePackages, true
+	}
+	return nil, false
+}
+
+// getCommandTemplateData returns the template data of a command in
+// :directory with :environment for the :changedFiles, nil meaning
+// everything has changed
+func getCommandTemplateData(changedFiles []string, directory string, session CommandTemplateSession, environment []string) *CommandTemplateData {
+	data := &CommandTemplateData{
+		CommandTemplateSession: session,
+		Env:                    map[string]CommandTemplateValue{},
+		Timestamp:              CommandTemplateValue(time.Now().UTC().Format(time.RFC3339)),
+		directory:              directory,
+	}
+	for key, value := range parseEnvironment(environment) {
+		data.Env[key] = CommandTemplateValue(value)
+	}
+	if changedFiles == nil {
+		data.ChangedFiles = CommandTemplateList{}
+		data.ChangedPackages = CommandTemplateList{"./..."}
+		data.GeneratePackages = CommandTemplateList{"./..."}
+		return data
+	}
+	packages := map[string]bool{}
+	for _, changedFile := range changedFiles {
+		if filepath.Ext(changedFile) != ".go" {
+			changedFile = getClosestPackageFile(changedFile, directory)
+		}
+		if len(changedFile) == 0 {
+			continue
+		} else if packagePath, ok := getPackagePath(changedFile, directory); ok {
+			packages[packagePath] = true
+		}
+	}
+	data.ChangedFiles = append(CommandTemplateList{}, changedFiles...)
+	data.ChangedPackages = sortedTemplateList(packages)
+	if len(data.ChangedPackages) == 0 {
+		data.ChangedPackages = CommandTemplateList{"./..."}
+	}
+	data.GeneratePackages = getGeneratePackages(changedFiles, directory)
+	return data
+}
+
+// getClosestPackageFile returns a path in the closest directory of
+// :filePath inside :directory which has .go files, an empty path when
+// there is none
+func getClosestPackageFile(filePath string, directory string) string {
+	for packageDirectory := filepath.Dir(filePath); ; packageDirectory = filepath.Dir(packageDirectory) {
+		if relativePath, err := filepath.Rel(directory, packageDirectory); err != nil || strings.HasPrefix(relativePath, "..") {
+			return ""
+		}
+		if listings, err := ioutil.ReadDir(packageDirectory); err == nil {
+			for _, listing := range listings {
+				if !listing.IsDir() && filepath.Ext(listing.Name()) == ".go" {
+					return filepath.Join(packageDirectory, listing.Name())
+				}
+			}
+		}
+		if packageDirectory == filepath.Dir(packageDirectory) {
+			return ""
+		}
+	}
+}
+
+// getPackagePath returns the directory of :filePath relative to
+// :directory as a package path (eg. ./internal/api) if it exists and is
+// inside of :directory
+func getPackagePath(filePath string, directory string) (string, bool) {
+	packageDirectory := filepath.Dir(filePath)
+	if fileInfo, err := os.Stat(packageDirectory); err != nil || !fileInfo.IsDir() {
+		return "", false
+	}
+	relativePath, err := filepath.Rel(directory, packageDirectory)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		return "", false
+	} else if relativePath == "." {
+		return ".", true
+	}
+	return "./" + filepath.ToSlash(relativePath), true
+}
+
+// sortedTemplateList returns the keys of :values sorted
+func sortedTemplateList(values map[string]bool) CommandTemplateList {
+	list := CommandTemplateList{}
+	for value := range values {
+		list = append(list, value)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// expandCommandArguments renders the template placeholders in each of
+// :arguments with :data - an argument which is only a list placeholder
+// becomes one argument per value, arguments which are not templates of
+// CommandTemplateData are left as they are
+func expandCommandArguments(arguments []string, data *CommandTemplateData) ([]string, error) {
+	var expanded []string
+	for _, argument := range arguments {
+		argumentTemplate := parseCommandTemplate(argument)
+		if argumentTemplate == nil {
+			expanded = append(expanded, argument)
+			continue
+		}
+		if list, ok := data.getList(getCommandTemplateField(argumentTemplate)); ok {
+			expanded = append(expanded, list...)
+			continue
+		}
+		var rendered bytes.Buffer
+		if err := argumentTemplate.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("'%s' could not be rendered: %s", argument, err)
+		}
+		expanded = append(expanded, rendered.String())
+	}
+	return expanded, nil
+}
+
+// getCommandTemplateField returns the name of the field when
+// :argumentTemplate is only a field (eg. {{.ChangedFiles}}), an empty
+// string otherwise
+func getCommandTemplateField(argumentTemplate *template.Template) string {
+	nodes := argumentTemplate.Tree.Root.Nodes
+	if len(nodes) != 1 {
+		return ""
+	}
+	action, ok := nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Decl) > 0 || len(action.Pipe.Cmds) != 1 || len(action.Pipe.Cmds[0].Args) != 1 {
+		return ""
+	}
+	field, ok := action.Pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	if !ok || len(field.Ident) != 1 {
+		return ""
+	}
+	return field.Ident[0]
+}
+
+// parseCommandTemplate returns :argument as a template when it only
+// refers to CommandTemplateFields, nil when it is not a template or is
+// one which is meant for the command
+func parseCommandTemplate(argument string) *template.Template {
+	if !strings.Contains(argument, "{{") {
+		return nil
+	}
+	argumentTemplate, err := template.New("argument").Option("missingkey=zero").Parse(argument)
+	if err != nil || !isCommandTemplateNode(argumentTemplate.Tree.Root) {
+		return nil
+	}
+	return argumentTemplate
+}
+
+// isCommandTemplateNode checks that the fields :node refers to, and those
+// of the nodes below it, are CommandTemplateFields
+func isCommandTemplateNode(node parse.Node) bool {
+	switch node := node.(type) {
+	case nil:
+		return true
+	case *parse.FieldNode:
+		return CommandTemplateFields[node.Ident[0]]
+	case *parse.ListNode:
+		if node == nil {
+			return true
+		}
+		for _, child := range node.Nodes {
+			if !isCommandTemplateNode(child) {
+				return false
+			}
+		}
+		return true
+	case *parse.ActionNode:
+		return isCommandTemplateNode(node.Pipe)
+	case *parse.PipeNode:
+		if node == nil {
+			return true
+		}
+		for _, command := range node.Cmds {
+			if !isCommandTemplateNode(command) {
+				return false
+			}
+		}
+		return true
+	case *parse.CommandNode:
+		for _, argument := range node.Args {
+			if !isCommandTemplateNode(argument) {
+				return false
+			}
+		}
+		return true
+	case *parse.ChainNode:
+		return isCommandTemplateNode(node.Node)
+	case *parse.IfNode:
+		return isCommandTemplateBranch(&node.BranchNode)
+	case *parse.WithNode:
+		return isCommandTemplateBranch(&node.BranchNode)
+	case *parse.RangeNode:
+		return isCommandTemplateBranch(&node.BranchNode)
+	case *parse.TemplateNode:
+		return false
+	}
+	return true
+}
+
+// isCommandTemplateBranch checks the pipeline and lists of :branch with
+// isCommandTemplateNode
+func isCommandTemplateBranch(branch *parse.BranchNode) bool {
+	return isCommandTemplateNode(branch.Pipe) && isCommandTemplateNode(branch.List) && isCommandTemplateNode(branch.ElseList)
+}
+
+// expandCommandApplication renders the template placeholders in
+// :application with :data, the values it renders after the first one
+// are put before the :arguments
+func expandCommandApplication(application string, arguments []string, data *CommandTemplateData) (string, []string, error) {
+	expanded, err := expandCommandArguments([]string{application}, data)
+	if err != nil {
+		return "", nil, err
+	} else if len(expanded) == 0 {
+		return "", nil, fmt.Errorf("'%s' renders no command", application)
+	}
+	return expanded[0], append(expanded[1:], arguments...), nil
+}
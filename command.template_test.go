@@ -0,0 +1,129 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommandTemplateTestSuite struct {
+	suite.Suite
+}
+
+func TestCommandTemplate(t *testing.T) {
+	suite.Run(t, new(CommandTemplateTestSuite))
+}
+
+func (s *CommandTemplateTestSuite) Test_getCommandTemplateData() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-command-template")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, os.MkdirAll(path.Join(directory, "pkg/user"), os.ModePerm))
+	data := getCommandTemplateData([]string{
+		path.Join(directory, "pkg/user/user.go"),
+		path.Join(directory, "pkg/user/user_test.go"),
+		path.Join(directory, "main.go"),
+		path.Join(directory, "README.md"),
+		path.Join(directory, "deleted/deleted.go"),
+		"/elsewhere/lib.go",
+	}, directory, CommandTemplateSession{}, nil)
+	assert.Len(t, data.ChangedFiles, 6)
+	assert.Equal(t, CommandTemplateList{".", "./pkg/user"}, data.ChangedPackages)
+	assert.Equal(t, CommandTemplateList{"./..."}, data.GeneratePackages, "expected a changed file which is not a .go file to generate every package")
+	assert.Nil(t, os.MkdirAll(path.Join(directory, "pkg/user/testdata"), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "pkg/user/user.go"), []byte("package user\n"), 0644))
+	data = getCommandTemplateData([]string{path.Join(directory, "pkg/user/testdata/user.json")}, directory, CommandTemplateSession{}, nil)
+	assert.Equal(t, CommandTemplateList{"./pkg/user"}, data.ChangedPackages, "expected other files to change the closest package")
+	data = getCommandTemplateData([]string{path.Join(directory, "README.md")}, directory, CommandTemplateSession{}, nil)
+	assert.Equal(t, CommandTemplateList{"./..."}, data.ChangedPackages, "expected every package to change without a closest package")
+	data = getCommandTemplateData(nil, directory, CommandTemplateSession{BuildOutput: "/work/bin/app"}, []string{"PORT=8080", "PORT=9090"})
+	assert.Empty(t, data.ChangedFiles)
+	assert.Equal(t, CommandTemplateList{"./..."}, data.ChangedPackages)
+	assert.Equal(t, CommandTemplateList{"./..."}, data.GeneratePackages)
+	assert.Equal(t, CommandTemplateValue("/work/bin/app"), data.BuildOutput)
+	assert.Equal(t, map[string]CommandTemplateValue{"PORT": "9090"}, data.Env)
+	timestamp, err := time.Parse(time.RFC3339, string(data.Timestamp))
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now(), timestamp, time.Minute)
+}
+
+func (s *CommandTemplateTestSuite) TestGitCommit() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-command-template")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Empty(t, getCommandTemplateData(nil, directory, CommandTemplateSession{}, nil).GitCommit(), "expected no commit outside of git repositories")
+	assert.Empty(t, getCommandTemplateData(nil, "", CommandTemplateSession{}, nil).GitCommit())
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	cmd := exec.Command("git", "init")
+	cmd.Dir = directory
+	assert.Nil(t, cmd.Run())
+	cmd = exec.Command("git", "-c", "user.name=godev", "-c", "user.email=godev@localhost", "commit", "--allow-empty", "-m", "initial")
+	cmd.Dir = directory
+	assert.Nil(t, cmd.Run())
+	assert.Regexp(t, "^[0-9a-f]{40}$", string(getCommandTemplateData(nil, directory, CommandTemplateSession{}, nil).GitCommit()))
+	assert.Regexp(t, "^[0-9a-f]{7,}$", string(getCommandTemplateData(nil, directory, CommandTemplateSession{}, nil).GitDescribe()), "expected the abbreviated commit without tags")
+	cmd = exec.Command("git", "tag", "v1.2.0")
+	cmd.Dir = directory
+	assert.Nil(t, cmd.Run())
+	assert.Equal(t, "v1.2.0", string(getCommandTemplateData(nil, directory, CommandTemplateSession{}, nil).GitDescribe()))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "main.go"), []byte("package main\n"), 0644))
+	cmd = exec.Command("git", "add", "main.go")
+	cmd.Dir = directory
+	assert.Nil(t, cmd.Run())
+	assert.Equal(t, "v1.2.0-dirty", string(getCommandTemplateData(nil, directory, CommandTemplateSession{}, nil).GitDescribe()))
+	assert.Empty(t, getCommandTemplateData(nil, "", CommandTemplateSession{}, nil).GitDescribe())
+}
+
+func (s *CommandTemplateTestSuite) Test_expandCommandArguments() {
+	t := s.T()
+	data := &CommandTemplateData{
+		ChangedFiles:    CommandTemplateList{"/work/a.go", "/work/b c.go"},
+		ChangedPackages: CommandTemplateList{"./pkg/a"},
+	}
+	arguments, err := expandCommandArguments([]string{"test", "-v", "{{.ChangedPackages}}"}, data)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test", "-v", "./pkg/a"}, arguments)
+	arguments, err = expandCommandArguments([]string{"{{.ChangedFiles}}", "--first={{index .ChangedFiles 0}}"}, data)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/work/a.go", "/work/b c.go", "--first=/work/a.go"}, arguments)
+	arguments, err = expandCommandArguments([]string{"lint", "{{.ChangedFiles}}"}, &CommandTemplateData{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"lint"}, arguments)
+	arguments, err = expandCommandArguments([]string{"ps", "--format", "{{.Names}} {{.Status}}", "{{.ChangedFiles", "{{json .}}", "{{with .Env}}{{.PORT}}{{end}}"}, data)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ps", "--format", "{{.Names}} {{.Status}}", "{{.ChangedFiles", "{{json .}}", "{{with .Env}}{{.PORT}}{{end}}"}, arguments, "expected other templates to be passed on to the command")
+	_, err = expandCommandArguments([]string{"{{index .ChangedPackages 1}}"}, data)
+	assert.NotNil(t, err, "expected placeholders which cannot be rendered to be rejected")
+	data = &CommandTemplateData{
+		CommandTemplateSession: CommandTemplateSession{BuildOutput: "/my work/bin/app", WorkDirectory: "/work"},
+		Env:                    map[string]CommandTemplateValue{"PORT": "8080"},
+	}
+	arguments, err = expandCommandArguments([]string{"{{.BuildOutput}}", "-o={{.BuildOutput}}", "--config={{.WorkDirectory}}/app.yaml", "--port={{.Env.PORT}}", "--host={{.Env.HOST}}"}, data)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/my work/bin/app", "-o=/my work/bin/app", "--config=/work/app.yaml", "--port=8080", "--host="}, arguments, "expected values to be rendered as they are")
+}
+
+func (s *CommandTemplateTestSuite) Test_expandCommandApplication() {
+	t := s.T()
+	data := &CommandTemplateData{CommandTemplateSession: CommandTemplateSession{BuildOutput: "/work/bin/app"}}
+	application, arguments, err := expandCommandApplication("{{.BuildOutput}}", []string{"--port", "8080"}, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "/work/bin/app", application)
+	assert.Equal(t, []string{"--port", "8080"}, arguments)
+	application, arguments, err = expandCommandApplication("go", []string{"build"}, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "go", application)
+	assert.Equal(t, []string{"build"}, arguments)
+	_, _, err = expandCommandApplication("{{.ChangedFiles}}", nil, &CommandTemplateData{})
+	assert.NotNil(t, err, "expected commands which render nothing to be rejected")
+}
@@ -1,11 +1,15 @@
-package main
+package godev
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path"
+	"regexp"
+	"strings"
 	"sync"
 	"syscall"
 	"testing"
@@ -18,7 +22,7 @@ import (
 type CommandTestSuite struct {
 	suite.Suite
 	command    *Command
-	logs       bytes.Buffer
+	logs       syncBuffer
 	expectedID string
 }
 
@@ -28,6 +32,7 @@ func TestCommandTestSuite(t *testing.T) {
 
 func (s *CommandTestSuite) SetupTest() {
 	s.expectedID = "CommandTestSuiteCommandID"
+	s.logs.Reset()
 	logger := InitLogger(&LoggerConfig{
 		Name:   "CommandTestSuite",
 		Format: "production",
@@ -82,6 +87,17 @@ func (s *CommandTestSuite) TestIsRunning() {
 	assert.False(t, s.command.IsRunning())
 }
 
+func (s *CommandTestSuite) TestIsReady() {
+	t := s.T()
+	s.command.config.ReadyPattern = regexp.MustCompile("ready")
+	assert.False(t, s.command.IsReady())
+	s.command.handleReady()
+	assert.True(t, s.command.IsReady())
+	assert.Contains(t, s.logs.String(), "is ready after")
+	s.command.handleInitialisation()
+	assert.False(t, s.command.IsReady())
+}
+
 func (s *CommandTestSuite) TestIsValid_FromRegisteredPath() {
 	err := s.command.IsValid()
 	assert.Nil(s.T(), err)
@@ -119,7 +135,7 @@ func (s *CommandTestSuite) TestRun() {
 			}
 		}
 	}()
-	s.command.Run()
+	s.command.Run(context.Background())
 	wg.Wait()
 }
 
@@ -139,6 +155,53 @@ func (s *CommandTestSuite) TestSendInterrupt() {
 	wg.Wait()
 }
 
+func (s *CommandTestSuite) TestSendInterrupt_withStopSignal() {
+	s.command.config.StopSignal = syscall.SIGTERM
+	s.command.SendInterrupt()
+	assert.Equal(s.T(), "terminated", (<-s.command.signal).String())
+}
+
+func (s *CommandTestSuite) TestSendInterrupt_doesNotBlock() {
+	t := s.T()
+	s.command.config.KillTimeout = 50 * time.Millisecond
+	s.command.signal = make(chan os.Signal)
+	returned := make(chan struct{})
+	go func() {
+		s.command.SendInterrupt()
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		assert.FailNow(t, "expected SendInterrupt to return before the command takes the signal")
+	}
+	select {
+	case <-s.command.signal:
+	case <-time.After(time.Second):
+		assert.FailNow(t, "expected the signal to be sent after SendInterrupt returned")
+	}
+}
+
+func (s *CommandTestSuite) TestSendSignal_notRunning() {
+	assert.NotNil(s.T(), s.command.SendSignal(syscall.SIGTERM))
+}
+
+func (s *CommandTestSuite) Test_parseStopSignal() {
+	t := s.T()
+	for name, expected := range map[string]os.Signal{
+		"SIGINT":  syscall.SIGINT,
+		"int":     syscall.SIGINT,
+		"SIGTERM": syscall.SIGTERM,
+		"TERM":    syscall.SIGTERM,
+	} {
+		signal, err := parseStopSignal(name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, signal, name)
+	}
+	_, err := parseStopSignal("SIGKILL")
+	assert.NotNil(t, err)
+}
+
 func (s *CommandTestSuite) Test_handleInitialisation() {
 	t := s.T()
 	expectedDir := "/some/directory"
@@ -170,6 +233,206 @@ func (s *CommandTestSuite) Test_handleInitialisation() {
 	}
 }
 
+func (s *CommandTestSuite) Test_handleInitialisation_separatesStderr() {
+	t := s.T()
+	cmd := &Command{config: &CommandConfig{Application: "go", Arguments: []string{"version"}}}
+	cmd.handleInitialisation()
+	assert.Equal(t, os.Stdout, cmd.cmd.Stdout, "expected stdout to be passed through when it needs no processing")
+	stderr, ok := cmd.cmd.Stderr.(*CommandOutput)
+	assert.True(t, ok)
+	assert.True(t, stderr.config.IsStderr)
+	assert.Equal(t, canColor(os.Stderr), stderr.config.Colored, "expected stderr to be coloured only on a terminal")
+	cmd.config.RawOutput = true
+	cmd.config.OutputLabel = "1:go version"
+	cmd.handleInitialisation()
+	assert.Equal(t, os.Stdout, cmd.cmd.Stdout)
+	assert.Equal(t, os.Stderr, cmd.cmd.Stderr)
+	assert.Empty(t, cmd.outputs)
+}
+
+func (s *CommandTestSuite) Test_handleInitialisation_passesChangedFiles() {
+	t := s.T()
+	s.command.config.Arguments = []string{"--files={{.ChangedFiles}}", "{{.ChangedFiles}}"}
+	s.command.SetChangedFiles([]string{"/work/main.go", "/work/a b.go"})
+	s.command.handleInitialisation()
+	assert.Equal(t, []string{s.command.config.Application, "--files=/work/main.go /work/a b.go", "/work/main.go", "/work/a b.go"}, s.command.cmd.Args)
+	assert.Equal(t, "GODEV_CHANGED_FILES=/work/main.go\n/work/a b.go", s.command.cmd.Env[len(s.command.cmd.Env)-1])
+	s.command.SetChangedFiles(nil)
+	s.command.handleInitialisation()
+	assert.Equal(t, []string{s.command.config.Application, "--files="}, s.command.cmd.Args)
+	assert.Equal(t, "GODEV_CHANGED_FILES=", s.command.cmd.Env[len(s.command.cmd.Env)-1])
+	assert.NotContains(t, s.logs.String(), "environment changed", "expected changed files not to count as environment changes")
+}
+
+func (s *CommandTestSuite) TestMatchesChanges() {
+	t := s.T()
+	assert.True(t, s.command.MatchesChanges("/work"), "expected commands without when patterns to always run")
+	s.command.config.When = []string{"**/*.proto", "buf.yaml"}
+	assert.True(t, s.command.MatchesChanges("/work"), "expected commands to run when there are no changes")
+	s.command.SetChangedFiles([]string{"/work/main.go"})
+	assert.False(t, s.command.MatchesChanges("/work"))
+	s.command.SetChangedFiles([]string{"/work/main.go", "/work/buf.yaml"})
+	assert.True(t, s.command.MatchesChanges("/work"))
+	s.command.SetChangedFiles([]string{"/work/api/v1/service.proto"})
+	assert.True(t, s.command.MatchesChanges("/work"))
+}
+
+func (s *CommandTestSuite) TestMatchesChanges_generatesCode() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-command-generate")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "main.go"), []byte("package main\n"), 0644))
+	s.command.config.Arguments = []string{"generate", GeneratePackagesPlaceholder}
+	s.command.config.Directory = directory
+	assert.True(t, s.command.MatchesChanges(directory), "expected commands to run when there are no changes")
+	s.command.SetChangedFiles([]string{path.Join(directory, "main.go")})
+	assert.False(t, s.command.MatchesChanges(directory), "expected nothing to be generated without directives")
+	assert.Equal(t, "has no changes matching [] with code to generate", s.command.getSkipReason())
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "main.go"), []byte("package main\n\n//go:generate echo\n"), 0644))
+	assert.True(t, s.command.MatchesChanges(directory))
+}
+
+func (s *CommandTestSuite) Test_handleInitialisation_passesSessionValues() {
+	t := s.T()
+	s.command.config.Arguments = []string{"--output={{.BuildOutput}}", "--port={{.Env.APP_PORT}}", "{{.WorkDirectory}}"}
+	s.command.config.Environment = []string{"APP_PORT=8080"}
+	s.command.config.Session = CommandTemplateSession{BuildOutput: "/work/bin/app", WorkDirectory: "/my work"}
+	s.command.handleInitialisation()
+	assert.Equal(t, []string{s.command.config.Application, "--output=/work/bin/app", "--port=8080", "/my work"}, s.command.cmd.Args)
+}
+
+func (s *CommandTestSuite) Test_handleInitialisation_quotesValuesForTheShell() {
+	t := s.T()
+	s.command.config.Arguments = []string{"-c", "go build -o={{.BuildOutput}} && cd {{.WorkDirectory}}"}
+	s.command.config.Session = CommandTemplateSession{BuildOutput: "/work/bin/app", WorkDirectory: "/my work"}
+	s.command.config.Shell = true
+	s.command.handleInitialisation()
+	assert.Equal(t, []string{s.command.config.Application, "-c", "go build -o=/work/bin/app && cd '/my work'"}, s.command.cmd.Args)
+}
+
+func (s *CommandTestSuite) Test_handleInitialisation_passesRunDirectory() {
+	t := s.T()
+	s.command.SetRunDirectory("/tmp/godev-run-1")
+	s.command.handleInitialisation()
+	assert.Equal(t, []string{"GOTMPDIR=/tmp/godev-run-1", "GODEV_RUN_DIR=/tmp/godev-run-1"}, s.command.cmd.Env[len(s.command.cmd.Env)-2:])
+	s.command.SetRunDirectory("")
+	s.command.handleInitialisation()
+	assert.Contains(t, s.command.cmd.Env[len(s.command.cmd.Env)-1], CommandChangedFilesEnvVar, "expected commands of runs which are not isolated not to get a run directory")
+}
+
+func (s *CommandTestSuite) Test_handleInitialisation_logsEnvironmentChanges() {
+	t := s.T()
+	s.command.handleInitialisation()
+	assert.NotContains(t, s.logs.String(), "environment changed")
+	s.command.config.Environment = []string{"GODEV_TEST_SECRET=hunter2"}
+	s.command.handleInitialisation()
+	assert.Contains(t, s.logs.String(), "environment changed - 1 added, 0 removed, 0 changed: +GODEV_TEST_SECRET")
+	assert.NotContains(t, s.logs.String(), "hunter2")
+}
+
+func (s *CommandTestSuite) Test_handleInitialisation_loadsEnvironmentFile() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-command-env")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	envFile := path.Join(directory, ".env")
+	assert.Nil(t, ioutil.WriteFile(envFile, []byte("PORT=8080\nAPP_ENV=development\n"), 0644))
+	s.command.config.Environment = []string{"PORT=9090"}
+	s.command.config.EnvironmentFile = envFile
+	s.command.handleInitialisation()
+	assert.Equal(t, []string{"PORT=8080", "APP_ENV=development", "PORT=9090"}, s.command.cmd.Env[:3])
+	assert.Nil(t, ioutil.WriteFile(envFile, []byte("PORT=8080\nAPP_ENV=staging\n"), 0644))
+	s.command.handleInitialisation()
+	assert.Equal(t, "APP_ENV=staging", s.command.cmd.Env[1])
+	assert.Contains(t, s.logs.String(), "~APP_ENV")
+	os.Remove(envFile)
+	s.command.handleInitialisation()
+	assert.Contains(t, s.logs.String(), "environment file could not be loaded")
+	assert.Equal(t, "PORT=9090", s.command.cmd.Env[0])
+}
+
+func (s *CommandTestSuite) Test_handleInitialisation_overridesInheritedEnvironment() {
+	t := s.T()
+	os.Setenv("GODEV_TEST_GOFLAGS", "-mod=mod")
+	defer os.Unsetenv("GODEV_TEST_GOFLAGS")
+	s.command.config.Environment = []string{"GODEV_TEST_GOFLAGS=-mod=vendor"}
+	s.command.handleInitialisation()
+	assert.Contains(t, s.command.cmd.Env, "GODEV_TEST_GOFLAGS=-mod=vendor")
+	assert.NotContains(t, s.command.cmd.Env, "GODEV_TEST_GOFLAGS=-mod=mod")
+}
+
+func (s *CommandTestSuite) Test_getSuccessError() {
+	t := s.T()
+	exitError := exec.Command("go", "unknown-subcommand").Run()
+	assert.NotNil(t, s.command.getSuccessError(exitError))
+	assert.Nil(t, s.command.getSuccessError(nil))
+	s.command.config.SuccessCodes = []int{0, 2}
+	assert.Nil(t, s.command.getSuccessError(exitError), "expected exit status 2 to be successful")
+	s.command.config.SuccessCodes = []int{1}
+	assert.EqualError(t, s.command.getSuccessError(nil), "exit status 0 is not one of [1]")
+	assert.EqualError(t, s.command.getSuccessError(exitError), "exit status 2 is not one of [1]")
+	s.command.config.SuccessCodes = nil
+	s.command.config.SuccessPattern = regexp.MustCompile("^ok")
+	assert.EqualError(t, s.command.getSuccessError(nil), "output did not match /^ok/")
+	s.command.handleSuccessMatch()
+	assert.Nil(t, s.command.getSuccessError(nil))
+	assert.NotNil(t, s.command.getSuccessError(exitError), "expected the exit code to still have to be 0")
+}
+
+func (s *CommandTestSuite) Test_handleStart_withSuccessPattern() {
+	t := s.T()
+	s.command.config.SuccessPattern = regexp.MustCompile("^go version go")
+	s.command.handleInitialisation()
+	go s.command.handleStart()
+	assert.Nil(t, <-s.command.run)
+	s.command.config.SuccessPattern = regexp.MustCompile("^PASS")
+	s.command.handleInitialisation()
+	go s.command.handleStart()
+	assert.EqualError(t, <-s.command.run, "output did not match /^PASS/")
+}
+
+func (s *CommandTestSuite) Test_handleStart_withTimeout() {
+	t := s.T()
+	s.command.config.Application = "sleep"
+	s.command.config.Arguments = []string{"5"}
+	s.command.config.Timeout = 50 * time.Millisecond
+	s.command.handleInitialisation()
+	startedAt := time.Now()
+	go s.command.handleStart()
+	assert.EqualError(t, <-s.command.run, "timed out after 50ms")
+	assert.True(t, time.Since(startedAt) < 5*time.Second, "expected commands which time out to be killed")
+	assert.Contains(t, s.logs.String(), "did not exit within 50ms - killing it")
+	s.command.config.Arguments = []string{"0"}
+	s.command.config.Timeout = 5 * time.Second
+	s.command.handleInitialisation()
+	go s.command.handleStart()
+	assert.Nil(t, <-s.command.run, "expected commands which exit in time to succeed")
+}
+
+func (s *CommandTestSuite) Test_handleStart_withUnresolvableUser() {
+	t := s.T()
+	s.command.config.Application = "touch"
+	s.command.config.Arguments = []string{path.Join(os.TempDir(), "godev-run-as-unknown-user")}
+	s.command.config.User = "godev-user-that-should-not-exist"
+	defer os.Remove(s.command.config.Arguments[0])
+	s.command.handleInitialisation()
+	go s.command.handleStart()
+	assert.NotNil(t, <-s.command.run, "expected the command to fail rather than run as the current user")
+	assert.False(t, fileExists(s.command.config.Arguments[0]), "expected the command not to be run")
+}
+
+func (s *CommandTestSuite) Test_handleStart_withRecorder() {
+	t := s.T()
+	recorder := InitRunRecorder()
+	s.command.config.Recorder = recorder
+	s.command.handleInitialisation()
+	go s.command.handleStart()
+	assert.Nil(t, <-s.command.run)
+	assert.Contains(t, recorder.Stdout.String(), "go version go")
+	assert.Empty(t, recorder.Stderr.String())
+}
+
 func (s *CommandTestSuite) Test_handleProcessExited() {
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -187,6 +450,8 @@ func (s *CommandTestSuite) Test_handleProcessExited() {
 func (s *CommandTestSuite) Test_handleProcessLifecycleCallerSaysStop() {
 	var wg sync.WaitGroup
 	s.command.cmd.Process = &os.Process{}
+	// the process is not started so its run is over as soon as it is stopped
+	close(s.command.exited)
 	wg.Add(1)
 	go func() {
 		select {
@@ -202,10 +467,20 @@ func (s *CommandTestSuite) Test_handleProcessLifecycleCallerSaysStop() {
 			return
 		}
 	}(time.After(100 * time.Millisecond))
-	go s.command.handleProcessLifecycle()
+	go s.command.handleProcessLifecycle(context.Background())
 	wg.Wait()
 }
 
+func (s *CommandTestSuite) Test_handleProcessLifecycleContextIsDone() {
+	s.command.cmd.Process = &os.Process{}
+	s.command.config.StopSignal = syscall.SIGTERM
+	close(s.command.exited)
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.command.handleProcessLifecycle(ctx)
+	cancel()
+	assert.Equal(s.T(), "terminated", (<-s.command.terminated).Error(), "expected the command to be stopped with its stop signal")
+}
+
 func (s *CommandTestSuite) Test_handleProcessLifecycleProcessSaysStopped() {
 	var wg sync.WaitGroup
 	s.command.cmd.Process = &os.Process{}
@@ -224,16 +499,29 @@ func (s *CommandTestSuite) Test_handleProcessLifecycleProcessSaysStopped() {
 			return
 		}
 	}(time.After(100 * time.Millisecond))
-	go s.command.handleProcessLifecycle()
+	go s.command.handleProcessLifecycle(context.Background())
 	wg.Wait()
 }
 
+func (s *CommandTestSuite) Test_handleProcessLifecycleProcessIsSpawned() {
+	t := s.T()
+	s.command.cmd.Process = &os.Process{Pid: 1234}
+	go s.command.handleProcessLifecycle(context.Background())
+	assert.NotContains(t, s.logs.String(), "pid:1234", "expected the process not to be reported before it has started")
+	close(s.command.spawned)
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline) && !strings.Contains(s.logs.String(), "pid:1234"); time.Sleep(10 * time.Millisecond) {
+	}
+	assert.Contains(t, s.logs.String(), "pid:1234 id:CommandTestSuiteCommandID", "expected the process to be reported once it has started")
+	s.command.run <- nil
+	assert.Nil(t, <-s.command.terminated)
+}
+
 func (s *CommandTestSuite) Test_handleProcessReporting() {
 	s.command.reported = false
-	s.command.cmd.Process = &os.Process{Pid: -1}
+	s.command.cmd.Process = &os.Process{Pid: 1234}
 	s.command.handleProcessReporting()
 	assert.True(s.T(), s.command.reported)
-	assert.Contains(s.T(), s.logs.String(), "pid:-1 id:CommandTestSuiteCommandID")
+	assert.Contains(s.T(), s.logs.String(), "pid:1234 id:CommandTestSuiteCommandID")
 }
 
 func (s *CommandTestSuite) Test_handleSignalReceived() {
@@ -241,6 +529,7 @@ func (s *CommandTestSuite) Test_handleSignalReceived() {
 	sigstring := []string{"interrupt", "terminated", "killed"}
 	var wg sync.WaitGroup
 	s.command.cmd.Process = &os.Process{}
+	close(s.command.exited)
 	for i := 0; i < len(sigcalls); i++ {
 		wg.Add(1)
 		go func(j int) {
@@ -255,6 +544,43 @@ func (s *CommandTestSuite) Test_handleSignalReceived() {
 	}
 }
 
+// startProcess starts the command and waits for its process to be
+// running
+func (s *CommandTestSuite) startProcess() {
+	s.command.handleInitialisation()
+	go s.command.handleStart()
+	for s.command.getPid() < 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func (s *CommandTestSuite) Test_stopProcess() {
+	t := s.T()
+	s.command.config.Application = "sleep"
+	s.command.config.Arguments = []string{"5"}
+	s.command.config.KillTimeout = 5 * time.Second
+	s.startProcess()
+	startedAt := time.Now()
+	assert.Nil(t, s.command.stopProcess(syscall.SIGTERM))
+	assert.True(t, time.Since(startedAt) < 5*time.Second, "expected commands which handle the stop signal to exit")
+	assert.NotNil(t, <-s.command.run)
+	assert.NotContains(t, s.logs.String(), "killing it")
+}
+
+func (s *CommandTestSuite) Test_stopProcess_killsProcessGroup() {
+	t := s.T()
+	s.command.config.Application = "sh"
+	s.command.config.Arguments = []string{"-c", `trap "" INT; sleep 5`}
+	s.command.config.KillTimeout = 50 * time.Millisecond
+	s.startProcess()
+	startedAt := time.Now()
+	assert.Nil(t, s.command.stopProcess(syscall.SIGINT))
+	assert.True(t, time.Since(startedAt) < 5*time.Second, "expected commands which ignore the stop signal to be killed")
+	assert.NotNil(t, <-s.command.run)
+	assert.Contains(t, s.logs.String(), "did not exit within 50ms of interrupt - killing it")
+}
+
 func (s *CommandTestSuite) Test_handleStart() {
 	t := s.T()
 	var wg sync.WaitGroup
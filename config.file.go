@@ -0,0 +1,456 @@
+package godev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	shellquote "github.com/kballard/go-shellquote"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ConfigFileNames are the names of configuration files that are looked
+// for in the watch directory, in order of precedence
+var ConfigFileNames = []string{
+	"godev.yaml",
+	"godev.yml",
+	".godev.yaml",
+	".godev.yml",
+	"godev.toml",
+	".godev.toml",
+}
+
+// ConfigFile is the representation of a project-level configuration
+// file - keys are named after their corresponding flags
+type ConfigFile struct {
+	AllMains     bool                     `yaml:"all-mains" toml:"all-mains"`
+	Args         string                   `yaml:"args" toml:"args"`
+	Assets       map[string]AssetConfig   `yaml:"assets" toml:"assets"`
+	BuildCommand string                   `yaml:"build-cmd" toml:"build-cmd"`
+	DependsOn    map[string][]string      `yaml:"depends-on" toml:"depends-on"`
+	Env          map[string]string        `yaml:"env" toml:"env"`
+	EnvFile      string                   `yaml:"env-file" toml:"env-file"`
+	Exclude      []string                 `yaml:"exclude" toml:"exclude"`
+	Exec         []string                 `yaml:"exec" toml:"exec"`
+	ExecDelim    string                   `yaml:"exec-delim" toml:"exec-delim"`
+	Exts         []string                 `yaml:"exts" toml:"exts"`
+	FollowLinks  bool                     `yaml:"follow-symlinks" toml:"follow-symlinks"`
+	GoEnv        map[string]string        `yaml:"go-env" toml:"go-env"`
+	Ignore       []string                 `yaml:"ignore" toml:"ignore"`
+	Include      []string                 `yaml:"include" toml:"include"`
+	Keys         map[string]string        `yaml:"keys" toml:"keys"`
+	MinInterval  []string                 `yaml:"min-interval" toml:"min-interval"`
+	Notify       []string                 `yaml:"notify" toml:"notify"`
+	Output       string                   `yaml:"output" toml:"output"`
+	Plugins      []string                 `yaml:"plugins" toml:"plugins"`
+	Poll         string                   `yaml:"poll" toml:"poll"`
+	PollFallback string                   `yaml:"poll-fallback" toml:"poll-fallback"`
+	PostHook     string                   `yaml:"post-hook" toml:"post-hook"`
+	PreHook      string                   `yaml:"pre-hook" toml:"pre-hook"`
+	Profiles     map[string]ProfileConfig `yaml:"profiles" toml:"profiles"`
+	Publish      string                   `yaml:"publish" toml:"publish"`
+	Rate         string                   `yaml:"rate" toml:"rate"`
+	RecordOutput bool                     `yaml:"record-output" toml:"record-output"`
+	Routes       map[string][]string      `yaml:"routes" toml:"routes"`
+	RunCommand   string                   `yaml:"run-cmd" toml:"run-cmd"`
+	RunMain      []string                 `yaml:"run-main" toml:"run-main"`
+	Scripts      ScriptsConfig            `yaml:"scripts" toml:"scripts"`
+	Services     map[string]ServiceConfig `yaml:"services" toml:"services"`
+	Shell        bool                     `yaml:"shell" toml:"shell"`
+	Timeout      string                   `yaml:"timeout" toml:"timeout"`
+	UseGitignore bool                     `yaml:"use-gitignore" toml:"use-gitignore"`
+	WatchEvents  []string                 `yaml:"watch-events" toml:"watch-events"`
+}
+
+// ScriptsConfig holds the scripts which decide whether the changes that
+// triggered the pipeline run it and which of its named execution groups
+type ScriptsConfig struct {
+	Skip       string            `yaml:"skip" toml:"skip"`
+	SkipGroups map[string]string `yaml:"skip-groups" toml:"skip-groups"`
+}
+
+// ProfileConfig is a named set of execution groups, environment
+// variables and watch settings selected with --profile which replace
+// those at the top level of the configuration file
+type ProfileConfig struct {
+	Args   string            `yaml:"args" toml:"args"`
+	Env    map[string]string `yaml:"env" toml:"env"`
+	Exec   []string          `yaml:"exec" toml:"exec"`
+	Exts   []string          `yaml:"exts" toml:"exts"`
+	GoEnv  map[string]string `yaml:"go-env" toml:"go-env"`
+	Ignore []string          `yaml:"ignore" toml:"ignore"`
+	Rate   string            `yaml:"rate" toml:"rate"`
+}
+
+// GetEnv returns the environment variables of the profile as KEY=value
+// pairs sorted by their keys
+func (profile *ProfileConfig) GetEnv() []string {
+	return getSortedEnv(profile.Env)
+}
+
+// GetGoEnv returns the go environment overrides of the profile as
+// KEY=value pairs sorted by their keys
+func (profile *ProfileConfig) GetGoEnv() []string {
+	return getSortedEnv(profile.GoEnv)
+}
+
+// FindConfigFile returns the path to the configuration file in
+// :directory or an empty string if there is none
+func FindConfigFile(directory string) string {
+	for _, fileName := range ConfigFileNames {
+		filePath := path.Join(directory, fileName)
+		if fileExists(filePath) {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// LoadConfigFile reads and parses the YAML or TOML configuration file
+// at :filePath depending on its extension
+func LoadConfigFile(filePath string) (*ConfigFile, error) {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	configFile := &ConfigFile{}
+	if path.Ext(filePath) == ".toml" {
+		metadata, err := toml.Decode(string(contents), configFile)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid configuration file: %s", filePath, err)
+		}
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("'%s' has unknown keys: %v", filePath, undecoded)
+		}
+	} else if err := yaml.UnmarshalStrict(contents, configFile); err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid configuration file: %s", filePath, err)
+	}
+	for _, plugin := range configFile.Plugins {
+		if sections, err := shellquote.Split(plugin); err != nil || len(sections) == 0 {
+			return nil, fmt.Errorf("'%s' has an invalid plugin '%s'", filePath, plugin)
+		}
+	}
+	if len(configFile.Poll) > 0 {
+		if _, err := time.ParseDuration(configFile.Poll); err != nil {
+			return nil, fmt.Errorf("'%s' has an invalid poll: %s", filePath, err)
+		}
+	}
+	if len(configFile.PollFallback) > 0 {
+		if _, err := time.ParseDuration(configFile.PollFallback); err != nil {
+			return nil, fmt.Errorf("'%s' has an invalid poll-fallback: %s", filePath, err)
+		}
+	}
+	if err := validateHookCommand(configFile.PreHook); err != nil {
+		return nil, fmt.Errorf("'%s' has an invalid pre-hook: %s", filePath, err)
+	}
+	if err := validateHookCommand(configFile.PostHook); err != nil {
+		return nil, fmt.Errorf("'%s' has an invalid post-hook: %s", filePath, err)
+	}
+	if len(configFile.Rate) > 0 {
+		if _, err := time.ParseDuration(configFile.Rate); err != nil {
+			return nil, fmt.Errorf("'%s' has an invalid rate: %s", filePath, err)
+		}
+	}
+	if len(configFile.Timeout) > 0 {
+		if timeout, err := time.ParseDuration(configFile.Timeout); err != nil || timeout < 0 {
+			return nil, fmt.Errorf("'%s' has an invalid timeout '%s'", filePath, configFile.Timeout)
+		}
+	}
+	if err := validatePatterns(append(append([]string{}, configFile.Exclude...), configFile.Include...)); err != nil {
+		return nil, fmt.Errorf("'%s' has an invalid pattern: %s", filePath, err)
+	}
+	if err := validateGoEnvironment(configFile.GoEnv); err != nil {
+		return nil, fmt.Errorf("'%s' has an invalid go-env: %s", filePath, err)
+	}
+	if _, err := ParseKeyBindings(configFile.Keys); err != nil {
+		return nil, fmt.Errorf("'%s' has invalid keys: %s", filePath, err)
+	}
+	if _, err := parseGroupDurations(configFile.MinInterval); err != nil {
+		return nil, fmt.Errorf("'%s' has an invalid min-interval: %s", filePath, err)
+	}
+	for pattern, groupNames := range configFile.Routes {
+		if err := validatePatterns([]string{pattern}); err != nil {
+			return nil, fmt.Errorf("'%s' has an invalid route: %s", filePath, err)
+		} else if len(groupNames) == 0 {
+			return nil, fmt.Errorf("'%s' has a route for '%s' without execution groups", filePath, pattern)
+		}
+	}
+	if _, _, err := compileConfigScripts(configFile.Scripts); err != nil {
+		return nil, fmt.Errorf("'%s' has an invalid script: %s", filePath, err)
+	}
+	for name, asset := range configFile.Assets {
+		if err := validateAssetName(name); err != nil {
+			return nil, fmt.Errorf("'%s' has an invalid asset: %s", filePath, err)
+		} else if err := asset.Validate(configFile.ExecDelim); err != nil {
+			return nil, fmt.Errorf("'%s' has an invalid asset '%s': %s", filePath, name, err)
+		}
+	}
+	for name, service := range configFile.Services {
+		if err := service.Validate(); err != nil {
+			return nil, fmt.Errorf("'%s' has an invalid service '%s': %s", filePath, name, err)
+		}
+	}
+	for groupName, dependencies := range configFile.DependsOn {
+		if sliceContainsString(dependencies, groupName) {
+			return nil, fmt.Errorf("'%s' has an execution group '%s' which depends on itself", filePath, groupName)
+		}
+	}
+	if _, err := ParseWatcherOperations(configFile.WatchEvents); err != nil {
+		return nil, fmt.Errorf("'%s' has invalid watch-events: %s", filePath, err)
+	}
+	if err := validatePublishDestination(configFile.Publish); err != nil {
+		return nil, fmt.Errorf("'%s' has an invalid publish: %s", filePath, err)
+	}
+	for name, profile := range configFile.Profiles {
+		if err := validateGoEnvironment(profile.GoEnv); err != nil {
+			return nil, fmt.Errorf("'%s' has an invalid go-env in profile '%s': %s", filePath, name, err)
+		}
+		if len(profile.Rate) > 0 {
+			if _, err := time.ParseDuration(profile.Rate); err != nil {
+				return nil, fmt.Errorf("'%s' has an invalid rate in profile '%s': %s", filePath, name, err)
+			}
+		}
+		if _, err := shellquote.Split(profile.Args); err != nil {
+			return nil, fmt.Errorf("'%s' has invalid args in profile '%s': %s", filePath, name, err)
+		}
+		for _, execGroup := range profile.Exec {
+			if err := validateExecutionGroup(execGroup, configFile.ExecDelim); err != nil {
+				return nil, fmt.Errorf("'%s' has an invalid execution group in profile '%s': %s", filePath, name, err)
+			}
+		}
+	}
+	return configFile, nil
+}
+
+// GetEnv returns the environment variables of the configuration file
+// as KEY=value pairs sorted by their keys
+func (configFile *ConfigFile) GetEnv() []string {
+	return getSortedEnv(configFile.Env)
+}
+
+// GetGoEnv returns the go environment overrides of the configuration
+// file as KEY=value pairs sorted by their keys
+func (configFile *ConfigFile) GetGoEnv() []string {
+	return getSortedEnv(configFile.GoEnv)
+}
+
+// getSortedEnv converts :env into KEY=value pairs sorted by their keys
+func getSortedEnv(env map[string]string) []string {
+	var keys []string
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, env[key]))
+	}
+	return pairs
+}
+
+// InitConfig merges :configFile into :config for every value whose flag
+// was not specified according to :isSet - environment variables are
+// combined with those from flags coming last so that flags take precedence,
+// values of profiles which were also specified as flags are dropped
+func InitConfig(config *Config, configFile *ConfigFile, isSet func(flag string) bool) error {
+	var err error
+	config.Profiles = getProfilesWithoutFlags(configFile.Profiles, config, isSet)
+	if configFile.AllMains && !config.RunTest && !isSet("all-mains") {
+		config.AllMains = true
+	}
+	if len(configFile.Args) > 0 && !isSet("args") {
+		if config.CommandArguments, err = shellquote.Split(configFile.Args); err != nil {
+			return err
+		}
+	}
+	if len(configFile.Assets) > 0 {
+		config.Assets = configFile.Assets
+	}
+	if len(configFile.BuildCommand) > 0 && !isSet("build-cmd") {
+		config.BuildCommand = configFile.BuildCommand
+	}
+	config.EnvVars = append(append(configFile.GetEnv(), configFile.GetGoEnv()...), config.EnvVars...)
+	if len(configFile.EnvFile) > 0 && !isSet("env-file") {
+		config.EnvFile = configFile.EnvFile
+	}
+	if len(configFile.Exec) > 0 && !config.RunTest && !isSet("exec") {
+		config.ExecGroups = configFile.Exec
+	}
+	if len(configFile.ExecDelim) > 0 && !isSet("exec-delim") {
+		config.CommandsDelimiter = configFile.ExecDelim
+	}
+	if len(configFile.Exclude) > 0 && !isSet("exclude") {
+		config.ExcludePatterns = configFile.Exclude
+	}
+	if len(configFile.Exts) > 0 && !isSet("exts") {
+		config.FileExtensions = configFile.Exts
+	}
+	if configFile.FollowLinks && !isSet("follow-symlinks") {
+		config.FollowSymlinks = true
+	}
+	if len(configFile.Ignore) > 0 && !isSet("ignore") {
+		config.IgnoredNames = configFile.Ignore
+	}
+	if len(configFile.Include) > 0 && !isSet("include") {
+		config.IncludePatterns = configFile.Include
+	}
+	if len(configFile.Keys) > 0 {
+		if config.KeyBindings, err = ParseKeyBindings(configFile.Keys); err != nil {
+			return err
+		}
+	}
+	if len(configFile.MinInterval) > 0 && !isSet("min-interval") {
+		if config.MinIntervals, err = parseGroupDurations(configFile.MinInterval); err != nil {
+			return err
+		}
+	}
+	if len(configFile.Notify) > 0 && !isSet("notify") {
+		config.NotifyAddresses = configFile.Notify
+	}
+	if len(configFile.Output) > 0 && !isSet("output") {
+		config.BuildOutput = configFile.Output
+	}
+	if len(configFile.Plugins) > 0 && !isSet("plugin") {
+		config.Plugins = configFile.Plugins
+	}
+	if len(configFile.Poll) > 0 && !isSet("poll") {
+		if config.PollInterval, err = time.ParseDuration(configFile.Poll); err != nil {
+			return err
+		}
+	}
+	if len(configFile.PollFallback) > 0 && !isSet("poll-fallback") {
+		if config.PollFallback, err = time.ParseDuration(configFile.PollFallback); err != nil {
+			return err
+		}
+	}
+	if len(configFile.PostHook) > 0 && !isSet("post-hook") {
+		config.PostHook = configFile.PostHook
+	}
+	if len(configFile.PreHook) > 0 && !isSet("pre-hook") {
+		config.PreHook = configFile.PreHook
+	}
+	if len(configFile.Publish) > 0 && !isSet("publish") {
+		config.PublishTarget = configFile.Publish
+	}
+	if len(configFile.Rate) > 0 && !isSet("rate") {
+		if config.Rate, err = time.ParseDuration(configFile.Rate); err != nil {
+			return err
+		}
+	}
+	if len(configFile.Timeout) > 0 && !isSet("timeout") {
+		if config.Timeout, err = time.ParseDuration(configFile.Timeout); err != nil {
+			return err
+		}
+	}
+	if configFile.RecordOutput && !isSet("record-output") {
+		config.RecordOutput = true
+	}
+	if len(configFile.DependsOn) > 0 && !config.RunTest {
+		config.DependsOn = configFile.DependsOn
+	}
+	if len(configFile.Routes) > 0 && !config.RunTest && !isSet("route") {
+		config.Routes = configFile.Routes
+	}
+	if len(configFile.RunCommand) > 0 && !config.RunTest && !isSet("run-cmd") {
+		config.RunCommand = configFile.RunCommand
+	}
+	if len(configFile.RunMain) > 0 && !isSet("run-main") {
+		config.RunnableMains = configFile.RunMain
+	}
+	skipScript, skipGroupScripts, err := compileConfigScripts(configFile.Scripts)
+	if err != nil {
+		return err
+	}
+	config.SkipScript = skipScript
+	if !config.RunTest {
+		config.SkipGroupScripts = skipGroupScripts
+	}
+	if len(configFile.Services) > 0 {
+		config.Services = configFile.Services
+	}
+	if configFile.Shell && !isSet("shell") {
+		config.Shell = true
+	}
+	if configFile.UseGitignore && !isSet("use-gitignore") {
+		config.UseGitignore = true
+	}
+	if len(configFile.WatchEvents) > 0 && !isSet("watch-events") {
+		config.WatchEvents = configFile.WatchEvents
+	}
+	for _, execGroup := range config.ExecGroups {
+		if err := validateExecutionGroup(execGroup, config.CommandsDelimiter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getProfilesWithoutFlags returns a copy of :profiles without the values
+// that were specified as flags according to :isSet so that flags keep
+// their precedence over profiles
+func getProfilesWithoutFlags(profiles map[string]ProfileConfig, config *Config, isSet func(flag string) bool) map[string]ProfileConfig {
+	flagEnv := parseEnvironment(config.EnvVars)
+	filtered := map[string]ProfileConfig{}
+	for name, profile := range profiles {
+		if isSet("args") {
+			profile.Args = ""
+		}
+		env := map[string]string{}
+		for key, value := range profile.Env {
+			if _, ok := flagEnv[key]; !ok {
+				env[key] = value
+			}
+		}
+		profile.Env = env
+		goEnv := map[string]string{}
+		for key, value := range profile.GoEnv {
+			if _, ok := flagEnv[key]; !ok {
+				goEnv[key] = value
+			}
+		}
+		profile.GoEnv = goEnv
+		if config.RunTest || isSet("exec") {
+			profile.Exec = nil
+		}
+		if isSet("exts") {
+			profile.Exts = nil
+		}
+		if isSet("ignore") {
+			profile.Ignore = nil
+		}
+		if isSet("rate") {
+			profile.Rate = ""
+		}
+		filtered[name] = profile
+	}
+	return filtered
+}
+
+// compileConfigScripts compiles the skip script and the skip scripts of
+// the named execution groups in :scripts
+func compileConfigScripts(scripts ScriptsConfig) (*Script, map[string]*Script, error) {
+	var skipScript *Script
+	if len(strings.TrimSpace(scripts.Skip)) > 0 {
+		script, err := CompileScript(scripts.Skip)
+		if err != nil {
+			return nil, nil, fmt.Errorf("skip %s", err)
+		}
+		skipScript = script
+	}
+	var skipGroupScripts map[string]*Script
+	for name, source := range scripts.SkipGroups {
+		script, err := CompileScript(source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("skip-groups for '%s' %s", name, err)
+		}
+		if skipGroupScripts == nil {
+			skipGroupScripts = map[string]*Script{}
+		}
+		skipGroupScripts[name] = script
+	}
+	return skipScript, skipGroupScripts, nil
+}
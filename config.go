@@ -1,10 +1,16 @@
-package main
+package godev
 
 import (
 	"fmt"
+	"os"
 	"path"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
+
+	shellquote "github.com/kballard/go-shellquote"
 )
 
 // DefaultBuildOutput - default relative path to watch directory to place built binaries in
@@ -16,43 +22,265 @@ const DefaultCommandArguments = ""
 // DefaultCommandsDelimiter - default string to split --execs into commands with
 const DefaultCommandsDelimiter = ","
 
-// DefaultExecutionGroupsBase - default commands to run when no --execs are specified
-var DefaultExecutionGroupsBase = []string{"go mod vendor"}
+// DefaultCoverProfile - default path relative to the work directory to write coverage profiles to
+const DefaultCoverProfile = "c.out"
+
+// DefaultSessionCoverProfile - default name of the coverage profile merged across runs in test mode, placed in the project directory
+const DefaultSessionCoverProfile = "c.session.out"
+
+// DefaultDebugAddress - default address delve listens at with --debug
+const DefaultDebugAddress = "127.0.0.1:2345"
+
+// DebugGCFlags - compiler flags used with --debug to disable the optimisations and inlining which get in the way of debuggers
+const DebugGCFlags = "all=-N -l"
+
+// StampVersionLDFlags - linker flags used with --stamp-version to set the version, commit and build time variables of the main package, rendered whenever the build command starts
+const StampVersionLDFlags = "{{with .GitDescribe}}-X main.version={{.}} {{end}}{{with .GitCommit}}-X main.commit={{.}} {{end}}-X main.buildTime={{.Timestamp}}"
+
+// DefaultEnvFile - default .env file relative to the work directory which is loaded if it exists
+const DefaultEnvFile = ".env"
+
+// DefaultDownloadCommand - default command to run before building modules which do not vendor their dependencies
+const DefaultDownloadCommand = "go mod download"
+
+// DefaultVendorCommand - default command to run before building modules which vendor their dependencies
+const DefaultVendorCommand = "go mod vendor"
 
 // DefaultFileExtensions - default commma-separated list of file extensions to watch for
 const DefaultFileExtensions = "go,Makefile"
 
+// DefaultWatchEvents - default comma-separated list of file system events to handle
+const DefaultWatchEvents = "create,write,remove,rename,chmod"
+
 // DefaultIgnoredNames - default comma-separated list of file/dir names to ignore
 const DefaultIgnoredNames = "bin,vendor"
 
+// DefaultInitTemplate - default template of the main.go seeded by the init sub-command
+const DefaultInitTemplate = "default"
+
 // DefaultLogLevel - default log level from 'trace', 'debug', 'info', 'warn', 'error', 'panic'
 const DefaultLogLevel = "info"
 
+// DefaultMaxProcs - default maximum number of commands running at the same time, 0 for no limit
+const DefaultMaxProcs = 0
+
+// DefaultMaxWarnings - default maximum number of vet/lint findings before a run fails, negative to disable
+const DefaultMaxWarnings = -1
+
+// DefaultPromptTimeout - default duration to wait for a running godev to respond to `godev prompt`
+const DefaultPromptTimeout = 250 * time.Millisecond
+
+// DefaultProjectDirectory - default path relative to the work directory of the directory godev keeps its artifacts in
+const DefaultProjectDirectory = ".godev"
+
 // DefaultRefreshRate - default duration at which to handle file system events
 const DefaultRefreshRate = 2 * time.Second
 
+// DefaultSnapshotTimeout - default duration to wait for the application to snapshot its state
+const DefaultSnapshotTimeout = 5 * time.Second
+
+// DefaultSelfReloadTimeout - default duration to wait for commands to stop before godev re-executes itself
+const DefaultSelfReloadTimeout = 5 * time.Second
+
+// DefaultSelfWatchInterval - default interval at which the godev executable is checked for upgrades
+const DefaultSelfWatchInterval = 2 * time.Second
+
+// DefaultTestShards - default number of go test invocations to split packages across in test mode
+const DefaultTestShards = 1
+
+// DefaultChildLogLevel - default minimum level of parsed child process logs to display
+const DefaultChildLogLevel = "trace"
+
 // Config configures the main application entrypoint
 type Config struct {
+	AllMains          bool
+	Args              []string
+	Assets            map[string]AssetConfig
+	BuildCommand      string
 	BuildOutput       string
+	BuildReport       bool
+	BuildTags         string
+	ChildLogFormat    LogParser
+	ChildLogLevel     LogLevel
 	CommandArguments  ConfigCommaDelimitedString
 	CommandsDelimiter string
+	ConfigFile        string
+	ContainerRuntime  string
+	ControlAddress    string
+	DocsAddress       string
+	Debug             bool
+	DebugAddress      string
+	DependsOn         map[string][]string
+	DetectedFramework *FrameworkDetection
+	EnvFile           string
 	EnvVars           ConfigMultiflagString
 	ExecGroups        ConfigMultiflagString
+	ExcludePatterns   ConfigMultiflagString
 	FileExtensions    ConfigCommaDelimitedString
+	FollowSymlinks    bool
+	ForwardedPorts    []PortForward
+	GCFlags           string
+	Generate          bool
+	GenerateInputs    ConfigMultiflagString
+	IgnoreBinaryFiles bool
 	IgnoredNames      ConfigCommaDelimitedString
+	IgnoreSignals     bool
+	IncludePatterns   ConfigMultiflagString
+	InitMode          bool
+	InitTemplate      string
+	IsolateNetwork    bool
+	IsolateRuns       bool
+	KeyBindings       map[string]*KeyBinding
+	KillTimeout       time.Duration
+	LDFlags           string
+	Locale            string
 	LogLevel          LogLevel
+	LogOutputs        ConfigMultiflagString
 	LogSilent         bool
 	LogSuperVerbose   bool
 	LogVerbose        bool
+	MainPackages      []string
+	MakeTargets       []string
+	Manual            bool
+	MaxFileSize       int64
+	MaxProcs          int
+	MaxWarnings       int
+	MinIntervals      map[int]time.Duration
+	MockFiles         []string
+	NoDetect          bool
+	NoNewPrivileges   bool
+	NoPrefix          bool
+	NotifyAddresses   ConfigMultiflagString
+	Once              bool
+	OnlyGroups        []string
+	Package           string
+	Plugins           ConfigMultiflagString
+	Policy            *Policy
+	PollFallback      time.Duration
+	PollInterval      time.Duration
+	PostHook          string
+	PreHook           string
+	Profile           string
+	ProjectDirectory  string
+	Profiles          map[string]ProfileConfig
+	Proxy             *PortForward
+	PublishTarget     string
+	Race              bool
+	Pty               bool
 	Rate              time.Duration
+	RawOutput         bool
+	ReadyPattern      *regexp.Regexp
+	RecordEvents      string
+	RecordOutput      bool
+	RecordRequests    int
+	ReplayEvents      string
+	Restart           bool
+	RestartLimit      int
+	Routes            map[string][]string
+	RunCerts          bool
+	RunCheck          bool
+	RunClean          bool
+	RunLintScaffold   bool
+	RunCoverage       bool
+	RunDaemon         bool
 	RunDefault        bool
+	RunHistory        bool
 	RunInit           bool
+	RunLogs           bool
+	RunPrompt         bool
+	RunReplay         bool
+	RunReport         bool
+	RunSecret         bool
+	RunStatus         bool
+	RunTouch          bool
 	RunTest           bool
 	RunVersion        bool
+	RunCommand        string
+	RunnableMains     ConfigMultiflagString
 	RunView           bool
+	RunWarm           bool
+	SelfReload        bool
+	Services          map[string]ServiceConfig
+	ShareWorktree     bool
+	Shell             bool
+	SkipGroups        []string
+	SkipGroupScripts  map[string]*Script
+	SkipScript        *Script
+	SnapshotTimeout   time.Duration
+	StampVersion      bool
+	StateDirectory    string
+	StatusLine        bool
+	StopSignal        os.Signal
+	TagRuns           bool
+	TestPackages      []string
+	TestShards        int
+	Timeout           time.Duration
+	UseGitignore      bool
+	User              string
 	View              string
 	WatchDirectory    string
+	WatchEvents       ConfigCommaDelimitedString
 	WorkDirectory     string
+	Worktree          *GitWorktree
+	assetsResolved    bool
+	profileResolved   bool
+}
+
+// GetProfile returns the profile selected with --profile, nil if no
+// profile was selected or an error if it is not defined
+func (config *Config) GetProfile() (*ProfileConfig, error) {
+	if len(config.Profile) == 0 {
+		return nil, nil
+	}
+	profile, ok := config.Profiles[config.Profile]
+	if !ok {
+		var names []string
+		for name := range config.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(config.ConfigFile) == 0 {
+			return nil, fmt.Errorf("profile '%s' cannot be used without a configuration file", config.Profile)
+		}
+		return nil, fmt.Errorf("profile '%s' is not defined in '%s' (available profiles: %v)", config.Profile, config.ConfigFile, names)
+	}
+	return &profile, nil
+}
+
+// resolveProfile replaces the execution groups, environment variables
+// and watch settings with those from the selected profile - only the
+// first call has an effect
+func (config *Config) resolveProfile() error {
+	if config.profileResolved {
+		return nil
+	}
+	profile, err := config.GetProfile()
+	if err != nil || profile == nil {
+		return err
+	}
+	config.profileResolved = true
+	if len(profile.Args) > 0 {
+		if config.CommandArguments, err = shellquote.Split(profile.Args); err != nil {
+			return err
+		}
+	}
+	config.EnvVars = append(config.EnvVars, profile.GetEnv()...)
+	config.EnvVars = append(config.EnvVars, profile.GetGoEnv()...)
+	if len(profile.Exec) > 0 {
+		config.ExecGroups = profile.Exec
+	}
+	if len(profile.Exts) > 0 {
+		config.FileExtensions = profile.Exts
+	}
+	if len(profile.Ignore) > 0 {
+		config.IgnoredNames = profile.Ignore
+	}
+	if len(profile.Rate) > 0 {
+		if config.Rate, err = time.ParseDuration(profile.Rate); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (config *Config) interpretLogLevel() {
@@ -62,14 +290,41 @@ func (config *Config) interpretLogLevel() {
 	if config.LogSuperVerbose {
 		config.LogLevel = "trace"
 	}
-	if config.LogSilent || config.RunVersion || config.RunView {
+	if config.LogSilent || config.RunCerts || config.RunCheck || config.RunClean || config.RunCoverage || config.RunDaemon || config.RunHistory || config.RunLintScaffold || config.RunLogs || config.RunPrompt || config.RunReplay || config.RunReport || config.RunSecret || config.RunStatus || config.RunTouch || config.RunVersion || config.RunView || config.RunWarm {
 		config.LogLevel = "panic"
 	}
 }
 
 func (config *Config) assignDefaults() {
-	config.LogLevel = DefaultLogLevel
+	if len(config.LogLevel) == 0 {
+		config.LogLevel = DefaultLogLevel
+	}
 	config.BuildOutput = path.Join(config.WorkDirectory, "/"+config.BuildOutput)
+	if len(config.EnvFile) > 0 && !path.IsAbs(config.EnvFile) {
+		config.EnvFile = path.Join(config.WorkDirectory, config.EnvFile)
+	} else if len(config.EnvFile) == 0 && fileExists(path.Join(config.WorkDirectory, DefaultEnvFile)) {
+		config.EnvFile = path.Join(config.WorkDirectory, DefaultEnvFile)
+	}
+	if len(config.RecordEvents) > 0 && !path.IsAbs(config.RecordEvents) {
+		config.RecordEvents = path.Join(config.WorkDirectory, config.RecordEvents)
+	}
+	if len(config.ReplayEvents) > 0 && !path.IsAbs(config.ReplayEvents) {
+		config.ReplayEvents = path.Join(config.WorkDirectory, config.ReplayEvents)
+	}
+	for index, mockFile := range config.MockFiles {
+		if !path.IsAbs(mockFile) {
+			config.MockFiles[index] = path.Join(config.WorkDirectory, mockFile)
+		}
+	}
+	if len(config.ProjectDirectory) == 0 {
+		config.ProjectDirectory = DefaultProjectDirectory
+	}
+	if !path.IsAbs(config.ProjectDirectory) {
+		config.ProjectDirectory = path.Join(config.WorkDirectory, config.ProjectDirectory)
+	}
+	if len(config.StateDirectory) > 0 && !path.IsAbs(config.StateDirectory) {
+		config.StateDirectory = path.Join(config.WorkDirectory, config.StateDirectory)
+	}
 	config.RunView = len(config.View) > 0
 	if len(config.IgnoredNames) == 0 {
 		config.IgnoredNames = strings.Split(DefaultIgnoredNames, ",")
@@ -77,23 +332,389 @@ func (config *Config) assignDefaults() {
 	if len(config.FileExtensions) == 0 {
 		config.FileExtensions = strings.Split(DefaultFileExtensions, ",")
 	}
+	if len(config.WatchEvents) == 0 {
+		config.WatchEvents = strings.Split(DefaultWatchEvents, ",")
+	}
+	if config.Generate {
+		if len(config.GenerateInputs) == 0 {
+			config.GenerateInputs = strings.Split(DefaultGenerateInputs, ",")
+		}
+		config.IncludePatterns = append(config.IncludePatterns, config.GenerateInputs...)
+	}
+	if len(config.MakeTargets) > 0 {
+		config.ExecGroups = getMakeExecGroups(config.MakeTargets)
+	}
 	if len(config.ExecGroups) == 0 {
+		buildCommand := fmt.Sprintf("go build -o %s", config.BuildOutput)
+		var preBuildCommands []string
+		if !config.NoDetect {
+			config.DetectedFramework = DetectFrameworks(config.WorkDirectory)
+			buildCommand = config.DetectedFramework.GetBuildCommand(config.BuildOutput)
+			preBuildCommands = config.DetectedFramework.PreBuild
+		}
+		if len(config.Package) > 0 {
+			buildCommand = fmt.Sprintf("go build -o %s %s", config.BuildOutput, config.Package)
+		}
+		needsModVendorFlag := config.needsModVendorFlag()
+		if needsModVendorFlag {
+			buildCommand = strings.Replace(buildCommand, "go build ", "go build -mod=vendor ", 1)
+		}
+		buildFlags := config.getBuildFlags()
+		buildCommand = strings.Replace(buildCommand, "go build ", "go build "+buildFlags, 1)
+		if len(config.BuildCommand) > 0 {
+			buildCommand = config.BuildCommand
+		}
+		defaultExecutionGroups := config.getDependencyExecutionGroups()
+		if config.Generate {
+			defaultExecutionGroups = append(defaultExecutionGroups, config.getGenerateExecutionGroup())
+		}
+		defaultExecutionGroups = append(defaultExecutionGroups, preBuildCommands...)
 		if config.RunTest {
-			testFlags := "-coverprofile c.out"
+			testFlags := buildFlags
+			if needsModVendorFlag {
+				testFlags = "-mod=vendor " + testFlags
+			}
 			if config.LogVerbose || config.LogSuperVerbose {
-				testFlags = fmt.Sprintf("-v %s", testFlags)
+				testFlags += "-v "
 			}
 			config.ExecGroups = append(
-				DefaultExecutionGroupsBase,
-				fmt.Sprintf("go build -o %s", config.BuildOutput),
-				fmt.Sprintf("go test ./... %s", testFlags),
+				append(defaultExecutionGroups, buildCommand),
+				config.getTestExecutionGroups(testFlags)...,
+			)
+		} else if config.AllMains {
+			config.ExecGroups = append(
+				defaultExecutionGroups,
+				config.getMainsExecutionGroups(needsModVendorFlag)...,
 			)
 		} else {
+			runCommand := config.BuildOutput
+			if len(config.RunCommand) > 0 {
+				runCommand = config.RunCommand
+			}
+			if config.Debug {
+				runCommand = config.getDebugRunCommand()
+			}
 			config.ExecGroups = append(
-				DefaultExecutionGroupsBase,
-				fmt.Sprintf("go build -o %s", config.BuildOutput),
-				config.BuildOutput,
+				defaultExecutionGroups,
+				buildCommand,
+				runCommand,
 			)
 		}
 	}
 }
+
+// getBuildFlags returns the flags from --race, --tags, --gcflags,
+// --ldflags, --stamp-version, --share-worktree and --debug for the
+// default go build and go test commands, followed by a space if there
+// are any - -trimpath keeps the paths of the worktree out of the build
+// cache keys so that worktrees share compiled packages
+func (config *Config) getBuildFlags() string {
+	var buildFlags []string
+	if config.Race {
+		buildFlags = append(buildFlags, "-race")
+	}
+	if config.Worktree != nil && !config.Debug {
+		buildFlags = append(buildFlags, "-trimpath")
+	}
+	if len(config.BuildTags) > 0 {
+		buildFlags = append(buildFlags, "-tags="+config.BuildTags)
+	}
+	buildFlags = append(buildFlags, config.getGCFlags()...)
+	ldFlags := config.LDFlags
+	if config.StampVersion {
+		ldFlags = strings.TrimSpace(ldFlags + " " + StampVersionLDFlags)
+	}
+	if len(ldFlags) > 0 {
+		buildFlags = append(buildFlags, "-ldflags="+ldFlags)
+	}
+	if len(buildFlags) == 0 {
+		return ""
+	}
+	return shellquote.Join(buildFlags...) + " "
+}
+
+// getGCFlags returns the -gcflags from --debug and --gcflags. go only
+// applies the last -gcflags which matches a package, so the debug flags
+// are merged into the value of --gcflags instead of being dropped for
+// the packages it matches - a pattern other than all= still needs the
+// debug flags for the rest of the packages before it
+func (config *Config) getGCFlags() []string {
+	if !config.Debug {
+		if len(config.GCFlags) > 0 {
+			return []string{"-gcflags=" + config.GCFlags}
+		}
+		return nil
+	} else if len(config.GCFlags) == 0 {
+		return []string{"-gcflags=" + DebugGCFlags}
+	}
+	debugPattern, debugFlags := splitGCFlags(DebugGCFlags)
+	pattern, flags := splitGCFlags(config.GCFlags)
+	merged := strings.TrimSpace(debugFlags + " " + flags)
+	if pattern == debugPattern {
+		return []string{"-gcflags=" + pattern + "=" + merged}
+	} else if len(pattern) > 0 {
+		merged = pattern + "=" + merged
+	}
+	return []string{"-gcflags=" + DebugGCFlags, "-gcflags=" + merged}
+}
+
+// splitGCFlags splits :gcFlags into the package pattern it is for, which
+// is empty when it is for the packages named on the command line, and
+// the flags
+func splitGCFlags(gcFlags string) (string, string) {
+	if !strings.HasPrefix(gcFlags, "-") {
+		if sections := strings.SplitN(gcFlags, "=", 2); len(sections) == 2 {
+			return sections[0], sections[1]
+		}
+	}
+	return "", gcFlags
+}
+
+// getCertsDirectory returns the directory of the development
+// certificate, which is the one of the main worktree with
+// --share-worktree so that it only has to be trusted once
+func (config *Config) getCertsDirectory() string {
+	certsDirectory := path.Join(config.ProjectDirectory, ProjectCertsDirectoryName)
+	if config.Worktree != nil {
+		if mainCertsDirectory := config.Worktree.GetMainPath(certsDirectory); len(mainCertsDirectory) > 0 {
+			return mainCertsDirectory
+		}
+	}
+	return certsDirectory
+}
+
+// getDebugRunCommand returns the command which runs the built binary
+// under delve for --debug, it accepts multiple clients and continues
+// without one so that the application starts like it does otherwise
+func (config *Config) getDebugRunCommand() string {
+	return fmt.Sprintf(
+		"dlv exec --headless --listen=%s --api-version=2 --accept-multiclient --continue %s --",
+		config.getDebugAddress(),
+		config.BuildOutput,
+	)
+}
+
+// getDebugAddress returns the address delve listens at with --debug
+func (config *Config) getDebugAddress() string {
+	if len(config.DebugAddress) == 0 {
+		return DefaultDebugAddress
+	}
+	return config.DebugAddress
+}
+
+// resolveAssets adds an execution group named after each asset to the
+// start of the pipeline, moving the --min-interval of the others, routes
+// the watch patterns of the assets to their groups and ignores changes
+// to their outputs
+func (config *Config) resolveAssets() error {
+	if config.assetsResolved || len(config.Assets) == 0 {
+		return nil
+	}
+	if err := config.resolveProfile(); err != nil {
+		return err
+	}
+	config.assetsResolved = true
+	names := getSortedAssetNames(config.Assets)
+	routes := map[string][]string{}
+	for pattern, groupNames := range config.Routes {
+		routes[pattern] = append([]string{}, groupNames...)
+	}
+	var execGroups []string
+	for _, name := range names {
+		asset := config.Assets[name]
+		execGroups = append(execGroups, fmt.Sprintf("name=%s:%s", name, asset.Run))
+		for _, pattern := range asset.Watch {
+			routes[pattern] = append(routes[pattern], name)
+		}
+		config.IncludePatterns = append(config.IncludePatterns, asset.Watch...)
+		config.ExcludePatterns = append(config.ExcludePatterns, asset.Outputs...)
+	}
+	config.Routes = routes
+	minIntervals := map[int]time.Duration{}
+	for index, minInterval := range config.MinIntervals {
+		minIntervals[index+len(execGroups)] = minInterval
+	}
+	config.MinIntervals = minIntervals
+	config.ExecGroups = append(execGroups, config.ExecGroups...)
+	return nil
+}
+
+// insertExecutionGroups adds :execGroups before the last execution group
+// of the resolved profile, moving the --min-interval of the last one
+func (config *Config) insertExecutionGroups(execGroups []string) error {
+	if len(execGroups) == 0 {
+		return nil
+	}
+	if err := config.resolveProfile(); err != nil {
+		return err
+	}
+	lastIndex := len(config.ExecGroups)
+	if lastIndex == 0 {
+		config.ExecGroups = execGroups
+		return nil
+	}
+	updated := append([]string{}, config.ExecGroups[:lastIndex-1]...)
+	updated = append(updated, execGroups...)
+	config.ExecGroups = append(updated, config.ExecGroups[lastIndex-1])
+	if minInterval, ok := config.MinIntervals[lastIndex]; ok {
+		delete(config.MinIntervals, lastIndex)
+		config.MinIntervals[lastIndex+len(execGroups)] = minInterval
+	}
+	return nil
+}
+
+// resolveMainPackages discovers the main packages under ./cmd when
+// --all-mains is specified and checks that the ones to run exist
+func (config *Config) resolveMainPackages() error {
+	if !config.AllMains {
+		return nil
+	}
+	if len(config.BuildCommand) > 0 || len(config.RunCommand) > 0 || len(config.Package) > 0 {
+		return fmt.Errorf("--all-mains cannot be used with --build-cmd, --run-cmd or a package to run")
+	}
+	mains, err := DiscoverMainPackages(config.WorkDirectory)
+	if err != nil || len(mains) == 0 {
+		return fmt.Errorf("--all-mains found no main packages in '%s'", path.Join(config.WorkDirectory, "/cmd"))
+	}
+	config.MainPackages = mains
+	if len(config.RunnableMains) == 0 {
+		return fmt.Errorf("--all-mains needs at least one --run-main to run (available: %v)", mains)
+	}
+	for _, runnable := range config.RunnableMains {
+		found := false
+		for _, mainPackage := range mains {
+			found = found || mainPackage == runnable
+		}
+		if !found {
+			return fmt.Errorf("--run-main '%s' is not a main package in ./cmd (available: %v)", runnable, mains)
+		}
+	}
+	return nil
+}
+
+// getMainsExecutionGroups returns an execution group which builds all
+// of the discovered main packages in parallel into the directory of the
+// build output and one which runs the runnable ones in parallel
+func (config *Config) getMainsExecutionGroups(needsModVendorFlag bool) []string {
+	commandsDelimiter := config.CommandsDelimiter
+	if len(commandsDelimiter) == 0 {
+		commandsDelimiter = DefaultCommandsDelimiter
+	}
+	buildFlags := config.getBuildFlags()
+	if needsModVendorFlag {
+		buildFlags = "-mod=vendor " + buildFlags
+	}
+	outputDirectory := path.Dir(config.BuildOutput)
+	var buildCommands []string
+	var runCommands []string
+	for _, mainPackage := range config.MainPackages {
+		output := path.Join(outputDirectory, mainPackage)
+		buildCommands = append(buildCommands, fmt.Sprintf("go build %s-o %s ./cmd/%s", buildFlags, output, mainPackage))
+		for _, runnable := range config.RunnableMains {
+			if runnable == mainPackage {
+				runCommands = append(runCommands, output)
+				break
+			}
+		}
+	}
+	return []string{
+		strings.Join(buildCommands, commandsDelimiter),
+		strings.Join(runCommands, commandsDelimiter),
+	}
+}
+
+// getBuiltBinaries returns the paths of the binaries which the default
+// build commands output, one for each main package with --all-mains
+func (config *Config) getBuiltBinaries() []string {
+	if config.RunTest || len(config.BuildCommand) > 0 || len(config.MakeTargets) > 0 {
+		return []string{}
+	} else if !config.AllMains {
+		return []string{config.BuildOutput}
+	}
+	outputDirectory := path.Dir(config.BuildOutput)
+	binaries := []string{}
+	for _, mainPackage := range config.MainPackages {
+		binaries = append(binaries, path.Join(outputDirectory, mainPackage))
+	}
+	return binaries
+}
+
+// getDependencyExecutionGroups returns the execution groups which update
+// vendor/ for modules that vendor their dependencies and which download
+// them into the module cache for other modules - there are none outside
+// of modules
+func (config *Config) getDependencyExecutionGroups() []string {
+	if !fileExists(path.Join(config.WorkDirectory, "go.mod")) {
+		return []string{}
+	} else if config.usesVendor() {
+		return []string{DefaultVendorCommand}
+	}
+	return []string{DefaultDownloadCommand}
+}
+
+// getGoModFlag returns the -mod flag from GOFLAGS in the environment
+// that commands will run with
+func (config *Config) getGoModFlag() string {
+	goFlags := os.Getenv("GOFLAGS")
+	if value, ok := parseEnvironment(config.EnvVars)["GOFLAGS"]; ok {
+		goFlags = value
+	}
+	return GoEnvironment{"GOFLAGS": goFlags}.GetModFlag()
+}
+
+// needsModVendorFlag checks whether builds have to be told to use vendor/
+// because go does not use it by default and GOFLAGS does not set -mod
+func (config *Config) needsModVendorFlag() bool {
+	return config.usesVendor() && len(config.getGoModFlag()) == 0 && !isVendorDefault(config.WorkDirectory)
+}
+
+// usesVendor checks whether the module vendors its dependencies
+func (config *Config) usesVendor() bool {
+	return directoryExists(path.Join(config.WorkDirectory, "vendor")) || config.getGoModFlag() == "vendor"
+}
+
+// getTestExecutionGroups returns the execution groups which run the
+// tests, when --test-shards is more than 1 the packages are split across
+// that many parallel go test invocations whose coverage profiles are
+// merged into DefaultCoverProfile after - each invocation lists the
+// packages again with the shard sub-command so that packages added
+// after godev started are tested too
+func (config *Config) getTestExecutionGroups(testFlags string) []string {
+	testPackages := []string{"./..."}
+	if len(config.TestPackages) > 0 {
+		testPackages = config.TestPackages
+	}
+	unsharded := []string{fmt.Sprintf("go test %s %s-coverprofile %s", strings.Join(testPackages, " "), testFlags, DefaultCoverProfile)}
+	shards := config.TestShards
+	if shards == 0 {
+		shards = runtime.NumCPU()
+	}
+	if shards < 2 {
+		return unsharded
+	}
+	packages, err := listPackages(config.WorkDirectory, testPackages)
+	if err != nil || len(packages) < 2 {
+		return unsharded
+	} else if shards > len(packages) {
+		shards = len(packages)
+	}
+	commandsDelimiter := config.CommandsDelimiter
+	if len(commandsDelimiter) == 0 {
+		commandsDelimiter = DefaultCommandsDelimiter
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "godev"
+	}
+	var testCommands []string
+	var coverProfiles []string
+	for index := 1; index <= shards; index++ {
+		coverProfile := path.Join(config.ProjectDirectory, ProjectCoverageDirectoryName, fmt.Sprintf("c.%v.out", index))
+		coverProfiles = append(coverProfiles, coverProfile)
+		testCommands = append(testCommands, strings.TrimSpace(fmt.Sprintf("%s shard --shard %v/%v --coverprofile %s %s -- %s", shellquote.Join(executable), index, shards, coverProfile, strings.Join(testPackages, " "), testFlags)))
+	}
+	return []string{
+		strings.Join(testCommands, commandsDelimiter),
+		fmt.Sprintf("%s coverage --coverprofile %s %s", shellquote.Join(executable), DefaultCoverProfile, strings.Join(coverProfiles, " ")),
+	}
+}
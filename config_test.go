@@ -1,8 +1,14 @@
-package main
+package godev
 
 import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
 	"testing"
+	"time"
 
+	shellquote "github.com/kballard/go-shellquote"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -28,9 +34,8 @@ func (s *ConfigTestSuite) Test_assignDefaultsRun() {
 	assert.Equal(t, false, c.RunView)
 	assert.Equal(t, []string{"bin", "vendor"}, []string(c.IgnoredNames))
 	assert.Equal(t, []string{"go", "Makefile"}, []string(c.FileExtensions))
-	assert.Equal(t, "go mod vendor", c.ExecGroups[0])
-	assert.Equal(t, "go build -o /some/path/to/work/bin/app", c.ExecGroups[1])
-	assert.Equal(t, "/some/path/to/work/bin/app", c.ExecGroups[2])
+	assert.Equal(t, []string{"create", "write", "remove", "rename", "chmod"}, []string(c.WatchEvents))
+	assert.Equal(t, []string{"go build -o /some/path/to/work/bin/app", "/some/path/to/work/bin/app"}, []string(c.ExecGroups), "expected no dependency step outside of modules")
 }
 
 func (s *ConfigTestSuite) Test_assignDefaultsTest() {
@@ -47,9 +52,447 @@ func (s *ConfigTestSuite) Test_assignDefaultsTest() {
 	assert.Equal(t, false, c.RunView)
 	assert.Equal(t, []string{"bin", "vendor"}, []string(c.IgnoredNames))
 	assert.Equal(t, []string{"go", "Makefile"}, []string(c.FileExtensions))
-	assert.Equal(t, "go mod vendor", c.ExecGroups[0])
-	assert.Equal(t, "go build -o /some/path/to/work/bin/app", c.ExecGroups[1])
-	assert.Equal(t, "go test ./... -coverprofile c.out", c.ExecGroups[2])
+	assert.Equal(t, []string{"go build -o /some/path/to/work/bin/app", "go test ./... -coverprofile c.out"}, []string(c.ExecGroups), "expected no dependency step outside of modules")
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithGenerate() {
+	t := s.T()
+	c := &Config{
+		BuildOutput:   "bin/app",
+		Generate:      true,
+		NoDetect:      true,
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{"*.proto", "*.sql"}, []string(c.GenerateInputs))
+	assert.Equal(t, []string{"*.proto", "*.sql"}, []string(c.IncludePatterns), "expected generator inputs to be watched")
+	assert.Equal(t, []string{
+		"when=*.go|*.proto|*.sql: go generate {{.GeneratePackages}}",
+		"go build -o /some/path/to/work/bin/app",
+		"/some/path/to/work/bin/app",
+	}, []string(c.ExecGroups))
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithPackages() {
+	t := s.T()
+	c := &Config{
+		BuildOutput:   "bin/app",
+		NoDetect:      true,
+		Package:       "./cmd/api",
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, "go build -o /some/path/to/work/bin/app ./cmd/api", c.ExecGroups[0])
+	c = &Config{
+		BuildOutput:   "bin/app",
+		NoDetect:      true,
+		RunTest:       true,
+		TestPackages:  []string{"./pkg/...", "./internal/..."},
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, "go test ./pkg/... ./internal/... -coverprofile c.out", c.ExecGroups[1])
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithTestShards() {
+	t := s.T()
+	directory := createTestModule(t, "a", "b", "c")
+	defer os.RemoveAll(directory)
+	c := &Config{
+		BuildOutput:   "bin/app",
+		NoDetect:      true,
+		RunTest:       true,
+		TestShards:    2,
+		WorkDirectory: directory,
+	}
+	c.assignDefaults()
+	assert.Len(t, c.ExecGroups, 4)
+	shardCommands := strings.Split(c.ExecGroups[2], ",")
+	assert.Len(t, shardCommands, 2)
+	assert.Contains(t, shardCommands[0], " shard --shard 1/2 --coverprofile "+path.Join(directory, ".godev/coverage/c.1.out")+" ./... --")
+	assert.Contains(t, shardCommands[1], " shard --shard 2/2 --coverprofile "+path.Join(directory, ".godev/coverage/c.2.out")+" ./... --")
+	assert.Contains(t, c.ExecGroups[3], " coverage --coverprofile c.out "+path.Join(directory, ".godev/coverage/c.1.out")+" "+path.Join(directory, ".godev/coverage/c.2.out"))
+	c = &Config{
+		BuildOutput:   "bin/app",
+		NoDetect:      true,
+		RunTest:       true,
+		TestShards:    2,
+		WorkDirectory: path.Join(directory, "a"),
+	}
+	c.assignDefaults()
+	assert.Equal(t, "go test ./... -coverprofile c.out", c.ExecGroups[1], "expected a single package to not be sharded")
+	c = &Config{
+		BuildOutput:   "bin/app",
+		NoDetect:      true,
+		RunTest:       true,
+		TestShards:    8,
+		WorkDirectory: directory,
+	}
+	c.assignDefaults()
+	assert.Len(t, strings.Split(c.ExecGroups[2], ","), 3, "expected no more shards than packages")
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithVendor() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "/go.mod"), []byte("module app\n"), 0644))
+	assert.Nil(t, os.Mkdir(path.Join(directory, "/vendor"), 0755))
+	buildOutput := path.Join(directory, "/bin/app")
+	c := &Config{BuildOutput: "bin/app", EnvVars: []string{"GOFLAGS="}, NoDetect: true, WorkDirectory: directory}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go mod vendor", "go build -mod=vendor -o " + buildOutput, buildOutput}, []string(c.ExecGroups))
+	c = &Config{BuildOutput: "bin/app", EnvVars: []string{"GOFLAGS="}, NoDetect: true, RunTest: true, WorkDirectory: directory}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go mod vendor", "go build -mod=vendor -o " + buildOutput, "go test ./... -mod=vendor -coverprofile c.out"}, []string(c.ExecGroups))
+	c = &Config{BuildOutput: "bin/app", EnvVars: []string{"GOFLAGS=-mod=mod"}, NoDetect: true, WorkDirectory: directory}
+	c.assignDefaults()
+	assert.Equal(t, "go build -o "+buildOutput, c.ExecGroups[1], "expected -mod in GOFLAGS to be respected")
+
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "/go.mod"), []byte("module app\n\ngo 1.14\n"), 0644))
+	c = &Config{BuildOutput: "bin/app", EnvVars: []string{"GOFLAGS="}, NoDetect: true, WorkDirectory: directory}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go mod vendor", "go build -o " + buildOutput, buildOutput}, []string(c.ExecGroups), "expected vendor/ to be used by default from go 1.14")
+
+	assert.Nil(t, os.Remove(path.Join(directory, "/vendor")))
+	c = &Config{BuildOutput: "bin/app", EnvVars: []string{"GOFLAGS="}, NoDetect: true, WorkDirectory: directory}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go mod download", "go build -o " + buildOutput, buildOutput}, []string(c.ExecGroups))
+	c = &Config{BuildOutput: "bin/app", EnvVars: []string{"GOFLAGS=-mod=vendor"}, NoDetect: true, WorkDirectory: directory}
+	c.assignDefaults()
+	assert.Equal(t, DefaultVendorCommand, c.ExecGroups[0], "expected -mod=vendor in GOFLAGS to create vendor/")
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithBuildAndRunCommands() {
+	t := s.T()
+	c := &Config{
+		BuildCommand:  "go build -o bin/api ./cmd/api",
+		BuildOutput:   "bin/app",
+		RunCommand:    "bin/api --port 8080",
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go build -o bin/api ./cmd/api", "bin/api --port 8080"}, []string(c.ExecGroups))
+	c = &Config{
+		BuildCommand:  "go build ./...",
+		RunCommand:    "bin/api",
+		RunTest:       true,
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go build ./...", "go test ./... -coverprofile c.out"}, []string(c.ExecGroups))
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithBuildFlags() {
+	t := s.T()
+	c := &Config{
+		BuildOutput:   "bin/app",
+		BuildTags:     "integration,sqlite",
+		GCFlags:       "all=-m",
+		LDFlags:       "-s -w",
+		Race:          true,
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{
+		"go build -race -tags=integration,sqlite -gcflags=all=-m '-ldflags=-s -w' -o /some/path/to/work/bin/app",
+		"/some/path/to/work/bin/app",
+	}, []string(c.ExecGroups))
+	c = &Config{
+		BuildOutput:   "bin/app",
+		Race:          true,
+		RunTest:       true,
+		TestShards:    1,
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go build -race -o /some/path/to/work/bin/app", "go test ./... -race -coverprofile c.out"}, []string(c.ExecGroups))
+	c = &Config{
+		BuildCommand:  "go build -o bin/api ./cmd/api",
+		Race:          true,
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, "go build -o bin/api ./cmd/api", c.ExecGroups[0], "expected --build-cmd not to be changed")
+	c = &Config{
+		BuildOutput:   "bin/app",
+		LDFlags:       "-s -w",
+		StampVersion:  true,
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, "go build "+shellquote.Join("-ldflags=-s -w "+StampVersionLDFlags)+" -o /some/path/to/work/bin/app", c.ExecGroups[0])
+	arguments, err := shellquote.Split(c.ExecGroups[0])
+	assert.Nil(t, err)
+	expanded, err := expandCommandArguments(arguments, &CommandTemplateData{Timestamp: "2019-10-17T10:10:10Z"})
+	assert.Nil(t, err)
+	assert.Equal(t, "-ldflags=-s -w -X main.buildTime=2019-10-17T10:10:10Z", expanded[2], "expected the git values to be left out outside of git repositories")
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithWorktree() {
+	t := s.T()
+	c := &Config{
+		BuildOutput:   "bin/app",
+		RunTest:       true,
+		TestShards:    1,
+		WorkDirectory: "/review",
+		Worktree:      &GitWorktree{Path: "/review", MainPath: "/main"},
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go build -trimpath -o /review/bin/app", "go test ./... -trimpath -coverprofile c.out"}, []string(c.ExecGroups))
+	assert.Equal(t, "/main/.godev/certs", c.getCertsDirectory())
+	c = &Config{
+		BuildOutput:   "bin/app",
+		Debug:         true,
+		WorkDirectory: "/review",
+		Worktree:      &GitWorktree{Path: "/review", MainPath: "/main"},
+	}
+	c.assignDefaults()
+	assert.NotContains(t, c.ExecGroups[0], "-trimpath", "expected -trimpath not to hide the source paths from the debugger")
+	c.Worktree = nil
+	assert.Equal(t, "/review/.godev/certs", c.getCertsDirectory())
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithDebug() {
+	t := s.T()
+	c := &Config{
+		BuildOutput:   "bin/app",
+		Debug:         true,
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{
+		"go build '-gcflags=all=-N -l' -o /some/path/to/work/bin/app",
+		"dlv exec --headless --listen=127.0.0.1:2345 --api-version=2 --accept-multiclient --continue /some/path/to/work/bin/app --",
+	}, []string(c.ExecGroups))
+	c = &Config{
+		BuildOutput:   "bin/app",
+		Debug:         true,
+		GCFlags:       "all=-m",
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, "go build '-gcflags=all=-N -l -m' -o /some/path/to/work/bin/app", c.ExecGroups[0], "expected --gcflags to be merged with the debug flags")
+	c = &Config{
+		BuildOutput:   "bin/app",
+		Debug:         true,
+		DebugAddress:  ":40000",
+		Package:       "./cmd/api",
+		WorkDirectory: "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{
+		"go build '-gcflags=all=-N -l' -o /some/path/to/work/bin/app ./cmd/api",
+		"dlv exec --headless --listen=:40000 --api-version=2 --accept-multiclient --continue /some/path/to/work/bin/app --",
+	}, []string(c.ExecGroups))
+}
+
+func (s *ConfigTestSuite) Test_getGCFlags() {
+	t := s.T()
+	assert.Nil(t, (&Config{}).getGCFlags())
+	assert.Equal(t, []string{"-gcflags=all=-m"}, (&Config{GCFlags: "all=-m"}).getGCFlags())
+	assert.Equal(t, []string{"-gcflags=all=-N -l"}, (&Config{Debug: true}).getGCFlags())
+	assert.Equal(t, []string{"-gcflags=all=-N -l -m"}, (&Config{Debug: true, GCFlags: "all=-m"}).getGCFlags(), "expected the flags for all packages to be merged into one value")
+	assert.Equal(t, []string{"-gcflags=all=-N -l", "-gcflags=-N -l -m"}, (&Config{Debug: true, GCFlags: "-m"}).getGCFlags(), "expected the named packages to keep the debug flags")
+	assert.Equal(t, []string{"-gcflags=all=-N -l", "-gcflags=./cmd/...=-N -l -d=checkptr"}, (&Config{Debug: true, GCFlags: "./cmd/...=-d=checkptr"}).getGCFlags())
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithDetectedFrameworks() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, os.MkdirAll(path.Join(directory, "/cmd/api"), 0755))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "/go.mod"), []byte("module app\n\nrequire github.com/google/wire v0.2.1\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "/cmd/api/main.go"), []byte("package main\n\nimport \"github.com/labstack/echo/v4\"\n"), 0644))
+	c := &Config{BuildOutput: "bin/app", WorkDirectory: directory}
+	c.assignDefaults()
+	buildOutput := path.Join(directory, "/bin/app")
+	assert.Equal(t, []string{"go mod download", "wire ./...", "go build -o " + buildOutput + " ./cmd/api", buildOutput}, []string(c.ExecGroups))
+	c = &Config{BuildOutput: "bin/app", NoDetect: true, WorkDirectory: directory}
+	c.assignDefaults()
+	assert.Equal(t, []string{"go mod download", "go build -o " + buildOutput, buildOutput}, []string(c.ExecGroups))
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsWithAllMains() {
+	t := s.T()
+	c := &Config{
+		AllMains:          true,
+		BuildOutput:       "bin/app",
+		CommandsDelimiter: ",",
+		MainPackages:      []string{"cli", "server", "worker"},
+		NoDetect:          true,
+		RunnableMains:     []string{"worker", "server"},
+		WorkDirectory:     "/some/path/to/work",
+	}
+	c.assignDefaults()
+	assert.Equal(t, []string{
+		"go build -o /some/path/to/work/bin/cli ./cmd/cli,go build -o /some/path/to/work/bin/server ./cmd/server,go build -o /some/path/to/work/bin/worker ./cmd/worker",
+		"/some/path/to/work/bin/server,/some/path/to/work/bin/worker",
+	}, []string(c.ExecGroups))
+}
+
+func (s *ConfigTestSuite) Test_getBuiltBinaries() {
+	t := s.T()
+	c := &Config{BuildOutput: "/app/bin/app"}
+	assert.Equal(t, []string{"/app/bin/app"}, c.getBuiltBinaries())
+	c.AllMains = true
+	c.MainPackages = []string{"cli", "server"}
+	assert.Equal(t, []string{"/app/bin/cli", "/app/bin/server"}, c.getBuiltBinaries())
+	c.BuildCommand = "make build"
+	assert.Empty(t, c.getBuiltBinaries(), "expected no binaries to be known with a custom build command")
+}
+
+func (s *ConfigTestSuite) Test_resolveMainPackages() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	c := &Config{WorkDirectory: directory}
+	assert.Nil(t, c.resolveMainPackages(), "expected nothing to be resolved without --all-mains")
+	c.AllMains = true
+	assert.Contains(t, c.resolveMainPackages().Error(), "found no main packages")
+	assert.Nil(t, os.MkdirAll(path.Join(directory, "/cmd/server"), 0755))
+	assert.Nil(t, os.MkdirAll(path.Join(directory, "/cmd/worker"), 0755))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "/cmd/server/main.go"), []byte("package main\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "/cmd/worker/main.go"), []byte("package main\n"), 0644))
+	assert.Contains(t, c.resolveMainPackages().Error(), "needs at least one --run-main")
+	c.RunnableMains = []string{"server", "cli"}
+	assert.Contains(t, c.resolveMainPackages().Error(), "'cli' is not a main package")
+	c.RunnableMains = []string{"server"}
+	assert.Nil(t, c.resolveMainPackages())
+	assert.Equal(t, []string{"server", "worker"}, c.MainPackages)
+	c.RunCommand = "bin/server"
+	assert.Contains(t, c.resolveMainPackages().Error(), "cannot be used with")
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsStateDirectory() {
+	t := s.T()
+	c := &Config{StateDirectory: ".state", WorkDirectory: "/some/path/to/work"}
+	c.assignDefaults()
+	assert.Equal(t, "/some/path/to/work/.state", c.StateDirectory)
+	c = &Config{StateDirectory: "/tmp/state", WorkDirectory: "/some/path/to/work"}
+	c.assignDefaults()
+	assert.Equal(t, "/tmp/state", c.StateDirectory)
+}
+
+func (s *ConfigTestSuite) TestGetProfile() {
+	t := s.T()
+	c := &Config{}
+	profile, err := c.GetProfile()
+	assert.Nil(t, err)
+	assert.Nil(t, profile)
+	c.Profile = "debug"
+	_, err = c.GetProfile()
+	assert.NotNil(t, err, "expected profiles to require a configuration file")
+	c.ConfigFile = "godev.yaml"
+	c.Profiles = map[string]ProfileConfig{"test": ProfileConfig{}}
+	_, err = c.GetProfile()
+	assert.Contains(t, err.Error(), "profile 'debug' is not defined in 'godev.yaml'")
+	c.Profiles["debug"] = ProfileConfig{Exec: []string{"dlv debug"}}
+	profile, err = c.GetProfile()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dlv debug"}, profile.Exec)
+}
+
+func (s *ConfigTestSuite) Test_insertExecutionGroups() {
+	t := s.T()
+	c := &Config{
+		ExecGroups:   []string{"go build -o bin/app", "bin/app"},
+		MinIntervals: map[int]time.Duration{2: time.Minute},
+	}
+	assert.Nil(t, c.insertExecutionGroups(nil))
+	assert.Len(t, c.ExecGroups, 2)
+	assert.Nil(t, c.insertExecutionGroups([]string{"./lint", "./policy"}))
+	assert.Equal(t, []string{"go build -o bin/app", "./lint", "./policy", "bin/app"}, []string(c.ExecGroups))
+	assert.Equal(t, map[int]time.Duration{4: time.Minute}, c.MinIntervals)
+	c = &Config{}
+	assert.Nil(t, c.insertExecutionGroups([]string{"./lint"}))
+	assert.Equal(t, []string{"./lint"}, []string(c.ExecGroups))
+}
+
+func (s *ConfigTestSuite) Test_resolveAssets() {
+	t := s.T()
+	c := &Config{
+		Assets: map[string]AssetConfig{
+			"templ": AssetConfig{Watch: []string{"**/*.templ"}, Run: "templ generate", Outputs: []string{"**/*_templ.go"}},
+			"css":   AssetConfig{Watch: []string{"styles/**"}, Run: "sass styles:static"},
+		},
+		ExcludePatterns: []string{"vendor/**"},
+		ExecGroups:      []string{"go build -o bin/app", "bin/app"},
+		MinIntervals:    map[int]time.Duration{2: time.Minute},
+		Routes:          map[string][]string{"styles/**": []string{"app"}},
+	}
+	assert.Nil(t, c.resolveAssets())
+	assert.Nil(t, c.resolveAssets())
+	assert.Equal(t, []string{"name=css:sass styles:static", "name=templ:templ generate", "go build -o bin/app", "bin/app"}, []string(c.ExecGroups))
+	assert.Equal(t, map[int]time.Duration{4: time.Minute}, c.MinIntervals)
+	assert.Equal(t, map[string][]string{"styles/**": []string{"app", "css"}, "**/*.templ": []string{"templ"}}, c.Routes)
+	assert.Equal(t, []string{"styles/**", "**/*.templ"}, []string(c.IncludePatterns))
+	assert.Equal(t, []string{"vendor/**", "**/*_templ.go"}, []string(c.ExcludePatterns))
+	c = &Config{ExecGroups: []string{"bin/app"}}
+	assert.Nil(t, c.resolveAssets())
+	assert.Equal(t, []string{"bin/app"}, []string(c.ExecGroups))
+	assert.Nil(t, c.Routes)
+}
+
+func (s *ConfigTestSuite) Test_resolveProfile() {
+	t := s.T()
+	c := &Config{
+		ConfigFile:     "godev.yaml",
+		EnvVars:        []string{"PORT=8080"},
+		ExecGroups:     []string{"go build -o bin/app", "bin/app"},
+		FileExtensions: []string{"go"},
+		IgnoredNames:   []string{"bin"},
+		Profile:        "docker",
+		Profiles: map[string]ProfileConfig{
+			"docker": ProfileConfig{
+				Args:   "--port 80",
+				Env:    map[string]string{"APP_ENV": "docker"},
+				Exec:   []string{"docker build -t app .", "docker run --rm app"},
+				Exts:   []string{"go", "Dockerfile"},
+				Ignore: []string{"bin", "data"},
+				Rate:   "5s",
+			},
+		},
+		Rate: time.Second,
+	}
+	assert.Nil(t, c.resolveProfile())
+	assert.Nil(t, c.resolveProfile())
+	assert.Equal(t, []string{"--port", "80"}, []string(c.CommandArguments))
+	assert.Equal(t, []string{"PORT=8080", "APP_ENV=docker"}, []string(c.EnvVars))
+	assert.Equal(t, []string{"docker build -t app .", "docker run --rm app"}, []string(c.ExecGroups))
+	assert.Equal(t, []string{"go", "Dockerfile"}, []string(c.FileExtensions))
+	assert.Equal(t, []string{"bin", "data"}, []string(c.IgnoredNames))
+	assert.Equal(t, 5*time.Second, c.Rate)
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsProjectDirectory() {
+	t := s.T()
+	c := &Config{WorkDirectory: "/some/path/to/work", NoDetect: true}
+	c.assignDefaults()
+	assert.Equal(t, "/some/path/to/work/.godev", c.ProjectDirectory)
+	c = &Config{ProjectDirectory: "/tmp/godev", WorkDirectory: "/some/path/to/work", NoDetect: true}
+	c.assignDefaults()
+	assert.Equal(t, "/tmp/godev", c.ProjectDirectory)
+}
+
+func (s *ConfigTestSuite) Test_assignDefaultsEnvFile() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-config-env")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	c := &Config{WorkDirectory: directory, NoDetect: true}
+	c.assignDefaults()
+	assert.Empty(t, c.EnvFile)
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, ".env"), []byte("A=1\n"), 0644))
+	c = &Config{WorkDirectory: directory, NoDetect: true}
+	c.assignDefaults()
+	assert.Equal(t, path.Join(directory, ".env"), c.EnvFile)
+	c = &Config{EnvFile: "config/local.env", WorkDirectory: directory, NoDetect: true}
+	c.assignDefaults()
+	assert.Equal(t, path.Join(directory, "config/local.env"), c.EnvFile)
 }
 
 func (s *ConfigTestSuite) Test_interpretLogLevel() {
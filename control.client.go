@@ -0,0 +1,101 @@
+package godev
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultControlClientTimeout - default duration to wait for the control API to respond
+const DefaultControlClientTimeout = 2 * time.Second
+
+// ControlClientConfig configures ControlClient
+type ControlClientConfig struct {
+	Address string
+	Timeout time.Duration
+}
+
+// InitControlClient creates a client for the control API of a running
+// godev instance
+func InitControlClient(config *ControlClientConfig) *ControlClient {
+	baseURL := strings.TrimRight(config.Address, "/")
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		baseURL = "http://" + baseURL
+	}
+	return &ControlClient{
+		config:  config,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// ControlClient talks to the control API of a running godev instance
+type ControlClient struct {
+	config  *ControlClientConfig
+	baseURL string
+	client  *http.Client
+}
+
+// GetStatus retrieves the status of the godev instance
+func (client *ControlClient) GetStatus() (*ControlStatus, error) {
+	status := &ControlStatus{}
+	if err := client.request(http.MethodGet, "/status", status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Trigger restarts the pipeline of the godev instance, :source is
+// logged by the instance to identify who triggered it
+func (client *ControlClient) Trigger(source string) error {
+	var body map[string]bool
+	return client.request(http.MethodPost, "/trigger?source="+url.QueryEscape(source), &body)
+}
+
+// Touch makes the godev instance handle changes to the absolute
+// :filePaths without modifying them
+func (client *ControlClient) Touch(filePaths []string) error {
+	query := url.Values{"path": filePaths}
+	var body map[string][]string
+	return client.request(http.MethodPost, "/touch?"+query.Encode(), &body)
+}
+
+// Replay makes the godev instance replay the last :count requests
+// recorded by its proxy
+func (client *ControlClient) Replay(count int) ([]ProxyReplayResult, error) {
+	var body map[string][]ProxyReplayResult
+	if err := client.request(http.MethodPost, fmt.Sprintf("/replay?count=%v", count), &body); err != nil {
+		return nil, err
+	}
+	return body["replayed"], nil
+}
+
+// request sends a request to :endpoint and decodes the JSON response
+// into :body, control API errors are returned as errors
+func (client *ControlClient) request(method, endpoint string, body interface{}) error {
+	request, err := http.NewRequest(method, client.baseURL+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	response, err := client.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("unable to reach godev at '%s': %s", client.config.Address, err)
+	}
+	defer response.Body.Close()
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		var controlError map[string]string
+		if json.Unmarshal(contents, &controlError) == nil && len(controlError["error"]) > 0 {
+			return fmt.Errorf("godev at '%s' responded with: %s", client.config.Address, controlError["error"])
+		}
+		return fmt.Errorf("godev at '%s' responded with status %v", client.config.Address, response.StatusCode)
+	}
+	return json.Unmarshal(contents, body)
+}
@@ -0,0 +1,345 @@
+package godev
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ControlServerConfig configures ControlServer
+type ControlServerConfig struct {
+	Address  string
+	LogLevel LogLevel
+	Proxy    *DevProxy
+	RunState *RunState
+	Runner   *Runner
+	Watcher  *Watcher
+}
+
+// InitControlServer creates a ControlServer which exposes an HTTP API
+// for controlling the running godev instance
+func InitControlServer(config *ControlServerConfig) *ControlServer {
+	server := &ControlServer{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:     "control",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: config.RunState,
+		}),
+		mux: http.NewServeMux(),
+	}
+	server.mux.HandleFunc("/cancel", server.handleCancel)
+	server.mux.HandleFunc("/groups", server.handleGroups)
+	server.mux.HandleFunc("/groups/", server.handleGroup)
+	server.mux.HandleFunc("/pause", server.handleWatcherState)
+	server.mux.HandleFunc("/ready", server.handleReady)
+	server.mux.HandleFunc("/replay", server.handleReplay)
+	server.mux.HandleFunc("/resume", server.handleWatcherState)
+	server.mux.HandleFunc("/status", server.handleStatus)
+	server.mux.HandleFunc("/touch", server.handleTouch)
+	server.mux.HandleFunc("/trigger", server.handleTrigger)
+	return server
+}
+
+// ControlServer is the component for controlling godev at runtime
+type ControlServer struct {
+	config   *ControlServerConfig
+	logger   *Logger
+	listener net.Listener
+	mux      *http.ServeMux
+}
+
+// ControlGroupStatus is the representation of an execution group
+// returned by the control API
+type ControlGroupStatus struct {
+	Index        int      `json:"index"`
+	Name         string   `json:"name,omitempty"`
+	Commands     []string `json:"commands"`
+	Enabled      bool     `json:"enabled"`
+	Running      bool     `json:"running"`
+	LastDuration string   `json:"lastDuration,omitempty"`
+	LastErrors   []string `json:"lastErrors,omitempty"`
+}
+
+// ControlStatus is the status of the running godev instance returned
+// by the control API
+type ControlStatus struct {
+	State        string               `json:"state"`
+	Ready        bool                 `json:"ready"`
+	Paused       bool                 `json:"paused"`
+	Pipelines    int                  `json:"pipelines"`
+	LastRun      *ControlRunStatus    `json:"lastRun,omitempty"`
+	Warnings     int                  `json:"warnings"`
+	WatchedPaths int                  `json:"watchedPaths"`
+	Groups       []ControlGroupStatus `json:"groups"`
+}
+
+// ControlRunStatus describes the last pipeline run
+type ControlRunStatus struct {
+	StartedAt time.Time `json:"startedAt"`
+	Duration  string    `json:"duration,omitempty"`
+	Failed    bool      `json:"failed"`
+}
+
+// Start begins listening for requests in the background
+func (server *ControlServer) Start() error {
+	listener, err := net.Listen("tcp", server.config.Address)
+	if err != nil {
+		return err
+	}
+	server.listener = listener
+	server.logger.Infof("control api listening at 'http://%s'", listener.Addr().String())
+	go func() {
+		if err := http.Serve(listener, server.mux); err != nil {
+			server.logger.Debugf("control api stopped: %s", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the control server from accepting requests
+func (server *ControlServer) Close() error {
+	if server.listener == nil {
+		return nil
+	}
+	return server.listener.Close()
+}
+
+// ServeHTTP implements http.Handler
+func (server *ControlServer) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	server.mux.ServeHTTP(response, request)
+}
+
+// handleGroups handles GET /groups
+func (server *ControlServer) handleGroups(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	server.respondJSON(response, server.getGroupStatuses())
+}
+
+// handleGroup handles POST /groups/:index/enable and POST /groups/:index/disable
+func (server *ControlServer) handleGroup(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	sections := strings.Split(strings.Trim(strings.TrimPrefix(request.URL.Path, "/groups/"), "/"), "/")
+	if len(sections) != 2 || (sections[1] != "enable" && sections[1] != "disable") {
+		server.respondError(response, http.StatusNotFound, fmt.Errorf("'%s' was not found", request.URL.Path))
+		return
+	}
+	index, err := strconv.Atoi(sections[0])
+	if err != nil {
+		server.respondError(response, http.StatusBadRequest, fmt.Errorf("'%s' is not a valid group index", sections[0]))
+		return
+	}
+	if err := server.config.Runner.SetGroupEnabled(index, sections[1] == "enable"); err != nil {
+		server.respondError(response, http.StatusBadRequest, err)
+		return
+	}
+	server.logger.Infof("execution group %v was %sd", index, sections[1])
+	server.respondJSON(response, server.getGroupStatuses()[index-1])
+}
+
+// handleWatcherState handles POST /pause and POST /resume, which stop
+// and restart file system changes from triggering the pipeline
+func (server *ControlServer) handleWatcherState(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	watcher := server.config.Watcher
+	if watcher == nil {
+		server.respondError(response, http.StatusServiceUnavailable, fmt.Errorf("there is no watcher to %s", strings.Trim(request.URL.Path, "/")))
+		return
+	}
+	if request.URL.Path == "/pause" {
+		watcher.Pause("paused from the control api by " + request.RemoteAddr)
+	} else {
+		watcher.Resume("resumed from the control api by " + request.RemoteAddr)
+	}
+	server.respondJSON(response, map[string]bool{"paused": watcher.IsPaused()})
+}
+
+// handleTouch handles POST /touch?path=..., which handles changes to
+// the absolute paths without modifying the files
+func (server *ControlServer) handleTouch(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	watcher := server.config.Watcher
+	if watcher == nil {
+		server.respondError(response, http.StatusServiceUnavailable, fmt.Errorf("there is no watcher to touch paths with"))
+		return
+	}
+	paths := request.URL.Query()["path"]
+	if len(paths) == 0 {
+		server.respondError(response, http.StatusBadRequest, fmt.Errorf("specify the paths to touch with ?path=..."))
+		return
+	}
+	for _, touchedPath := range paths {
+		if !filepath.IsAbs(touchedPath) {
+			server.respondError(response, http.StatusBadRequest, fmt.Errorf("'%s' is not an absolute path", touchedPath))
+			return
+		}
+	}
+	if err := watcher.Touch(paths); err != nil {
+		server.respondError(response, http.StatusServiceUnavailable, err)
+		return
+	}
+	server.logger.Infof("%v path(s) touched from the control api by %s", len(paths), request.RemoteAddr)
+	server.respondJSON(response, map[string][]string{"touched": paths})
+}
+
+// handleReplay handles POST /replay?count=N which replays the last N
+// requests recorded by the proxy, 1 when count is not specified
+func (server *ControlServer) handleReplay(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	proxy := server.config.Proxy
+	if proxy == nil {
+		server.respondError(response, http.StatusServiceUnavailable, fmt.Errorf("there is no proxy to replay requests through (use --proxy)"))
+		return
+	}
+	count := 1
+	if value := request.URL.Query().Get("count"); len(value) > 0 {
+		var err error
+		if count, err = strconv.Atoi(value); err != nil {
+			server.respondError(response, http.StatusBadRequest, fmt.Errorf("'%s' is not a valid count", value))
+			return
+		}
+	}
+	results, err := proxy.Replay(count)
+	if err != nil {
+		server.respondError(response, http.StatusConflict, err)
+		return
+	}
+	server.logger.Infof("%v request(s) replayed from the control api by %s", len(results), request.RemoteAddr)
+	server.respondJSON(response, map[string][]ProxyReplayResult{"replayed": results})
+}
+
+// handleReady handles GET /ready, responding with 503 until the service
+// in the last execution group is ready
+func (server *ControlServer) handleReady(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	ready := server.config.Runner.IsReady()
+	if !ready {
+		response.Header().Set("Content-Type", "application/json")
+		response.WriteHeader(http.StatusServiceUnavailable)
+	}
+	server.respondJSON(response, map[string]bool{"ready": ready})
+}
+
+// handleStatus handles GET /status
+func (server *ControlServer) handleStatus(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	server.respondJSON(response, server.getStatus())
+}
+
+// handleTrigger handles POST /trigger, restarting the pipeline as if a
+// file had changed - the optional ?source= names who triggered it
+func (server *ControlServer) handleTrigger(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	source := request.URL.Query().Get("source")
+	if len(source) == 0 {
+		source = request.RemoteAddr
+	}
+	server.logger.Infof("pipeline triggered by '%s'", source)
+	server.config.Runner.Trigger()
+	server.respondJSON(response, map[string]bool{"triggered": true})
+}
+
+// handleCancel handles POST /cancel, stopping the running pipeline
+// without starting a new one until the next change or trigger
+func (server *ControlServer) handleCancel(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		server.respondError(response, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", request.Method))
+		return
+	}
+	cancelled := server.config.Runner.Cancel()
+	if cancelled {
+		server.logger.Infof("pipeline %v was cancelled by '%s'", server.config.Runner.GetRunState().GetPipeline(), request.RemoteAddr)
+	}
+	server.respondJSON(response, map[string]bool{"cancelled": cancelled})
+}
+
+func (server *ControlServer) getStatus() *ControlStatus {
+	runner := server.config.Runner
+	lastRun := runner.GetLastRun()
+	status := &ControlStatus{
+		State:     "idle",
+		Ready:     runner.IsReady(),
+		Pipelines: lastRun.Pipeline,
+		Warnings:  runner.GetRunState().LintFindings.Count(),
+		Groups:    server.getGroupStatuses(),
+	}
+	if runner.IsRunning() {
+		status.State = "running"
+	}
+	if !lastRun.StartedAt.IsZero() {
+		status.LastRun = &ControlRunStatus{
+			StartedAt: lastRun.StartedAt,
+			Failed:    lastRun.Failed,
+		}
+		if lastRun.Duration > 0 {
+			status.LastRun.Duration = lastRun.Duration.Round(time.Millisecond).String()
+		}
+	}
+	if server.config.Watcher != nil {
+		status.WatchedPaths = server.config.Watcher.GetWatchedPathCount()
+		status.Paused = server.config.Watcher.IsPaused()
+	}
+	return status
+}
+
+func (server *ControlServer) getGroupStatuses() []ControlGroupStatus {
+	statuses := []ControlGroupStatus{}
+	for index, executionGroup := range server.config.Runner.config.Pipeline {
+		groupStatus := ControlGroupStatus{
+			Index:      index + 1,
+			Name:       executionGroup.name,
+			Commands:   executionGroup.GetCommandStrings(),
+			Enabled:    server.config.Runner.IsGroupEnabled(index + 1),
+			Running:    executionGroup.IsRunning(),
+			LastErrors: executionGroup.GetLastErrors(),
+		}
+		if lastDuration := executionGroup.GetLastDuration(); lastDuration > 0 {
+			groupStatus.LastDuration = lastDuration.Round(time.Millisecond).String()
+		}
+		statuses = append(statuses, groupStatus)
+	}
+	return statuses
+}
+
+func (server *ControlServer) respondJSON(response http.ResponseWriter, body interface{}) {
+	response.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		server.logger.Warn(err)
+	}
+}
+
+func (server *ControlServer) respondError(response http.ResponseWriter, status int, err error) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	json.NewEncoder(response).Encode(map[string]string{"error": err.Error()})
+}
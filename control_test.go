@@ -0,0 +1,231 @@
+package godev
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ControlServerTestSuite struct {
+	suite.Suite
+	server *ControlServer
+	logs   syncBuffer
+}
+
+func TestControlServer(t *testing.T) {
+	suite.Run(t, new(ControlServerTestSuite))
+}
+
+func (s *ControlServerTestSuite) SetupTest() {
+	runner := InitRunner(&RunnerConfig{
+		Pipeline: []*ExecutionGroup{
+			&ExecutionGroup{commands: []*Command{mockCommand("go", []string{"mod", "vendor"}, &s.logs)}},
+			&ExecutionGroup{commands: []*Command{mockCommand("go", []string{"test", "./..."}, &s.logs)}},
+		},
+	})
+	s.server = InitControlServer(&ControlServerConfig{
+		Address: "127.0.0.1:0",
+		Runner:  runner,
+	})
+	s.server.logger.SetOutput(&s.logs)
+}
+
+func (s *ControlServerTestSuite) request(method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.server.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+func (s *ControlServerTestSuite) TestGetGroups() {
+	t := s.T()
+	response := s.request(http.MethodGet, "/groups")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var statuses []ControlGroupStatus
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &statuses))
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, 2, statuses[1].Index)
+	assert.Equal(t, []string{"go test ./..."}, statuses[1].Commands)
+	assert.True(t, statuses[1].Enabled)
+}
+
+func (s *ControlServerTestSuite) TestDisableAndEnableGroup() {
+	t := s.T()
+	response := s.request(http.MethodPost, "/groups/2/disable")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.False(t, s.server.config.Runner.IsGroupEnabled(2))
+	assert.Contains(t, s.logs.String(), "execution group 2 was disabled")
+	response = s.request(http.MethodPost, "/groups/2/enable")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, s.server.config.Runner.IsGroupEnabled(2))
+}
+
+func (s *ControlServerTestSuite) TestGetReady() {
+	t := s.T()
+	response := s.request(http.MethodGet, "/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.JSONEq(t, `{"ready":false}`, response.Body.String())
+	lastCommand := s.server.config.Runner.config.Pipeline[1].commands[0]
+	lastCommand.started = true
+	lastCommand.config.ReadyPattern = regexp.MustCompile("ok")
+	assert.Equal(t, http.StatusServiceUnavailable, s.request(http.MethodGet, "/ready").Code)
+	lastCommand.handleReady()
+	response = s.request(http.MethodGet, "/ready")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"ready":true}`, response.Body.String())
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request(http.MethodPost, "/ready").Code)
+}
+
+func (s *ControlServerTestSuite) TestGetStatus() {
+	t := s.T()
+	response := s.request(http.MethodGet, "/status")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var status ControlStatus
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &status))
+	assert.Equal(t, "idle", status.State)
+	assert.Nil(t, status.LastRun)
+	assert.Equal(t, 0, status.WatchedPaths)
+	assert.Len(t, status.Groups, 2)
+	assert.False(t, status.Groups[0].Running)
+}
+
+func (s *ControlServerTestSuite) TestGetStatus_whilePipelineRuns() {
+	t := s.T()
+	runner := s.server.config.Runner
+	completed := make(chan bool, 1)
+	runner.config.Events = InitEventBus(&EventBusConfig{})
+	runner.config.Events.Subscribe(EventBuildFinished, func(*Event) { completed <- true })
+	assert.Nil(t, runner.SetGroupEnabled(1, false))
+	assert.Nil(t, runner.SetGroupEnabled(2, false))
+	runner.Trigger()
+	for finished := false; !finished; {
+		select {
+		case <-completed:
+			finished = true
+		default:
+			assert.Equal(t, http.StatusOK, s.request(http.MethodGet, "/status").Code)
+		}
+	}
+	var status ControlStatus
+	assert.Nil(t, json.Unmarshal(s.request(http.MethodGet, "/status").Body.Bytes(), &status))
+	assert.NotNil(t, status.LastRun)
+	assert.NotEmpty(t, status.LastRun.Duration)
+}
+
+func (s *ControlServerTestSuite) TestTrigger() {
+	t := s.T()
+	runner := s.server.config.Runner
+	assert.Nil(t, runner.SetGroupEnabled(1, false))
+	assert.Nil(t, runner.SetGroupEnabled(2, false))
+	completed := make(chan bool, 1)
+	runner.config.Events = InitEventBus(&EventBusConfig{})
+	runner.config.Events.Subscribe(EventBuildFinished, func(*Event) { completed <- true })
+	response := s.request(http.MethodPost, "/trigger?source=/path/to/library")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"triggered":true}`, response.Body.String())
+	<-completed
+	assert.Contains(t, s.logs.String(), "pipeline triggered by '/path/to/library'")
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request(http.MethodGet, "/trigger").Code)
+}
+
+func (s *ControlServerTestSuite) TestCancel() {
+	t := s.T()
+	response := s.request(http.MethodPost, "/cancel")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"cancelled":false}`, response.Body.String(), "expected nothing to be cancelled when the pipeline is idle")
+	runner := s.server.config.Runner
+	runner.config.Pipeline[0].commands = []*Command{mockCommand("sleep", []string{"10"}, &s.logs)}
+	completed := make(chan bool, 1)
+	runner.config.Events = InitEventBus(&EventBusConfig{})
+	runner.config.Events.Subscribe(EventBuildFinished, func(*Event) { completed <- true })
+	runner.Trigger()
+	for deadline := time.Now().Add(time.Second); !runner.IsRunning() && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	response = s.request(http.MethodPost, "/cancel")
+	assert.JSONEq(t, `{"cancelled":true}`, response.Body.String())
+	<-completed
+	assert.Contains(t, s.logs.String(), "was cancelled by")
+	assert.True(t, runner.config.Pipeline[1].lastRun.IsZero(), "expected the remaining execution groups not to run")
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request(http.MethodGet, "/cancel").Code)
+}
+
+func (s *ControlServerTestSuite) TestPauseAndResume() {
+	t := s.T()
+	assert.Equal(t, http.StatusServiceUnavailable, s.request(http.MethodPost, "/pause").Code)
+	var events []string
+	bus := InitEventBus(&EventBusConfig{})
+	bus.Subscribe(EventAll, func(event *Event) { events = append(events, event.Name) })
+	s.server.config.Watcher = initTestWatcher(s.T(), &WatcherConfig{Events: bus})
+	defer s.server.config.Watcher.Close()
+	response := s.request(http.MethodPost, "/pause")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"paused":true}`, response.Body.String())
+	assert.True(t, s.server.getStatus().Paused)
+	response = s.request(http.MethodPost, "/resume")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"paused":false}`, response.Body.String())
+	assert.Equal(t, []string{EventWatcherPaused, EventWatcherResumed}, events)
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request(http.MethodGet, "/pause").Code)
+}
+
+func (s *ControlServerTestSuite) TestTouch() {
+	t := s.T()
+	assert.Equal(t, http.StatusServiceUnavailable, s.request(http.MethodPost, "/touch?path=/project/main.go").Code)
+	s.server.config.Watcher = initTestWatcher(s.T(), &WatcherConfig{})
+	defer s.server.config.Watcher.Close()
+	response := s.request(http.MethodPost, "/touch?path=/project/main.go&path=/project/go.mod")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"touched":["/project/main.go","/project/go.mod"]}`, response.Body.String())
+	assert.Len(t, s.server.config.Watcher.touched, 2)
+	assert.Equal(t, http.StatusBadRequest, s.request(http.MethodPost, "/touch").Code)
+	assert.Equal(t, http.StatusBadRequest, s.request(http.MethodPost, "/touch?path=main.go").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request(http.MethodGet, "/touch?path=/project/main.go").Code)
+}
+
+func (s *ControlServerTestSuite) TestReplay() {
+	t := s.T()
+	assert.Equal(t, http.StatusServiceUnavailable, s.request(http.MethodPost, "/replay").Code)
+	application := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer application.Close()
+	s.server.config.Proxy = InitDevProxy(&DevProxyConfig{
+		Ports:       PortForward{ChildPort: application.Listener.Addr().(*net.TCPAddr).Port},
+		RecordLimit: 10,
+	})
+	s.server.config.Proxy.logger.SetOutput(&s.logs)
+	assert.Equal(t, http.StatusConflict, s.request(http.MethodPost, "/replay").Code, "expected replays without recorded requests to fail")
+	s.server.config.Proxy.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	response := s.request(http.MethodPost, "/replay?count=3")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var body map[string][]ProxyReplayResult
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Len(t, body["replayed"], 1)
+	assert.Equal(t, http.StatusOK, body["replayed"][0].Status)
+	assert.Equal(t, http.StatusBadRequest, s.request(http.MethodPost, "/replay?count=all").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request(http.MethodGet, "/replay").Code)
+}
+
+func (s *ControlServerTestSuite) TestInvalidRequests() {
+	t := s.T()
+	assert.Equal(t, http.StatusBadRequest, s.request(http.MethodPost, "/groups/3/disable").Code)
+	assert.Equal(t, http.StatusBadRequest, s.request(http.MethodPost, "/groups/abc/disable").Code)
+	assert.Equal(t, http.StatusNotFound, s.request(http.MethodPost, "/groups/1/toggle").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request(http.MethodGet, "/groups/1/disable").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request(http.MethodPost, "/groups").Code)
+}
+
+func (s *ControlServerTestSuite) TestStartAndClose() {
+	t := s.T()
+	assert.Nil(t, s.server.Start())
+	response, err := http.Get("http://" + s.server.listener.Addr().String() + "/groups")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response.Body.Close()
+	assert.Nil(t, s.server.Close())
+}
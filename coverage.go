@@ -0,0 +1,211 @@
+package godev
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CoverageProfile is a coverage profile as written by
+// `go test -coverprofile` which can be merged with others
+type CoverageProfile struct {
+	Mode   string
+	blocks map[string]*coverageBlock
+}
+
+// coverageBlock is a single line of a coverage profile
+type coverageBlock struct {
+	statements int
+	count      int
+}
+
+// ParseCoverageProfile reads a coverage profile from :reader
+func ParseCoverageProfile(reader io.Reader) (*CoverageProfile, error) {
+	profile := &CoverageProfile{blocks: map[string]*coverageBlock{}}
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		if strings.HasPrefix(line, "mode: ") {
+			mode := strings.TrimPrefix(line, "mode: ")
+			if len(profile.Mode) > 0 && profile.Mode != mode {
+				return nil, fmt.Errorf("line %v: mode '%s' does not match '%s'", lineNumber, mode, profile.Mode)
+			}
+			profile.Mode = mode
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %v: '%s' is not a coverage block", lineNumber, line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: invalid number of statements: %s", lineNumber, err)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: invalid count: %s", lineNumber, err)
+		}
+		profile.addBlock(fields[0], statements, count)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// LoadCoverageProfile reads the coverage profile at :filePath
+func LoadCoverageProfile(filePath string) (*CoverageProfile, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	profile, err := ParseCoverageProfile(file)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid coverage profile: %s", filePath, err)
+	}
+	return profile, nil
+}
+
+// Merge adds the blocks of :other into the profile, counts of blocks
+// found in both are summed except in 'set' mode where they are capped at 1
+func (profile *CoverageProfile) Merge(other *CoverageProfile) error {
+	if len(profile.Mode) == 0 {
+		profile.Mode = other.Mode
+	} else if len(other.Mode) > 0 && other.Mode != profile.Mode {
+		return fmt.Errorf("unable to merge a '%s' coverage profile into a '%s' one", other.Mode, profile.Mode)
+	}
+	if profile.blocks == nil {
+		profile.blocks = map[string]*coverageBlock{}
+	}
+	for location, block := range other.blocks {
+		profile.addBlock(location, block.statements, block.count)
+	}
+	return nil
+}
+
+// Replace replaces the blocks of every file found in :other with those
+// from :other while keeping the blocks of other files, this is used
+// when only some packages were re-tested
+func (profile *CoverageProfile) Replace(other *CoverageProfile) error {
+	if len(profile.Mode) > 0 && len(other.Mode) > 0 && profile.Mode != other.Mode {
+		profile.Mode = ""
+		profile.blocks = nil
+	}
+	replacedFiles := map[string]bool{}
+	for location := range other.blocks {
+		replacedFiles[getCoverageBlockFile(location)] = true
+	}
+	for location := range profile.blocks {
+		if replacedFiles[getCoverageBlockFile(location)] {
+			delete(profile.blocks, location)
+		}
+	}
+	return profile.Merge(other)
+}
+
+// GetCoverage returns the percentage of statements which were covered
+func (profile *CoverageProfile) GetCoverage() float64 {
+	statements := 0
+	covered := 0
+	for _, block := range profile.blocks {
+		statements += block.statements
+		if block.count > 0 {
+			covered += block.statements
+		}
+	}
+	if statements == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(statements)
+}
+
+// Write writes the profile to :writer in the format of `go test -coverprofile`
+func (profile *CoverageProfile) Write(writer io.Writer) error {
+	mode := profile.Mode
+	if len(mode) == 0 {
+		mode = "set"
+	}
+	if _, err := fmt.Fprintf(writer, "mode: %s\n", mode); err != nil {
+		return err
+	}
+	var locations []string
+	for location := range profile.blocks {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+	for _, location := range locations {
+		block := profile.blocks[location]
+		if _, err := fmt.Fprintf(writer, "%s %v %v\n", location, block.statements, block.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile writes the profile to the file at :filePath
+func (profile *CoverageProfile) WriteFile(filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return profile.Write(file)
+}
+
+func (profile *CoverageProfile) addBlock(location string, statements, count int) {
+	block, ok := profile.blocks[location]
+	if !ok {
+		block = &coverageBlock{statements: statements}
+		profile.blocks[location] = block
+	}
+	block.count += count
+	if profile.Mode == "set" && block.count > 1 {
+		block.count = 1
+	}
+}
+
+// getCoverageBlockFile returns the file of the block at :location
+// which is in the form of file:startLine.startColumn,endLine.endColumn
+func getCoverageBlockFile(location string) string {
+	if index := strings.LastIndex(location, ":"); index >= 0 {
+		return location[:index]
+	}
+	return location
+}
+
+// shardPackages distributes :packages over at most :shards lists in a
+// round-robin fashion so that each shard has a similar number of packages
+func shardPackages(packages []string, shards int) [][]string {
+	if shards > len(packages) {
+		shards = len(packages)
+	}
+	if shards < 1 {
+		return nil
+	}
+	sharded := make([][]string, shards)
+	for index, pkg := range packages {
+		sharded[index%shards] = append(sharded[index%shards], pkg)
+	}
+	return sharded
+}
+
+// listPackages returns the import paths of packages matching
+// :patterns in :workDirectory using `go list`
+func listPackages(workDirectory string, patterns []string) ([]string, error) {
+	command := exec.Command("go", append([]string{"list"}, patterns...)...)
+	command.Dir = workDirectory
+	output, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list packages matching %v: %s", patterns, err)
+	}
+	return strings.Fields(string(output)), nil
+}
@@ -0,0 +1,70 @@
+package godev
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// CoverageTrackerConfig configures CoverageTracker
+type CoverageTrackerConfig struct {
+	LogLevel           LogLevel
+	ProfilePath        string
+	RunState           *RunState
+	SessionProfilePath string
+}
+
+// InitCoverageTracker creates a tracker that maintains the coverage of
+// all packages tested in a session
+func InitCoverageTracker(config *CoverageTrackerConfig) *CoverageTracker {
+	return &CoverageTracker{
+		config:  config,
+		logger:  InitLogger(&LoggerConfig{Name: "coverage", Format: "production", Level: config.LogLevel, RunState: config.RunState}),
+		session: &CoverageProfile{},
+	}
+}
+
+// CoverageTracker merges the coverage profile written by each run into
+// a session profile so that the total coverage stays accurate when only
+// changed packages are re-tested
+type CoverageTracker struct {
+	config       *CoverageTrackerConfig
+	logger       *Logger
+	session      *CoverageProfile
+	lastModified time.Time
+	mutex        sync.Mutex
+}
+
+// GetCoverage returns the percentage of statements covered this session
+func (tracker *CoverageTracker) GetCoverage() float64 {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	return tracker.session.GetCoverage()
+}
+
+// Update merges the profile at ProfilePath into the session profile if
+// it was written since the last update and writes the session profile
+// to SessionProfilePath
+func (tracker *CoverageTracker) Update() {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	fileInfo, err := os.Stat(tracker.config.ProfilePath)
+	if err != nil || !fileInfo.ModTime().After(tracker.lastModified) {
+		tracker.logger.Tracef("no new coverage profile at '%s'", tracker.config.ProfilePath)
+		return
+	}
+	tracker.lastModified = fileInfo.ModTime()
+	profile, err := LoadCoverageProfile(tracker.config.ProfilePath)
+	if err != nil {
+		tracker.logger.Warn(err)
+		return
+	}
+	if err := tracker.session.Replace(profile); err != nil {
+		tracker.logger.Warn(err)
+		return
+	}
+	if err := tracker.session.WriteFile(tracker.config.SessionProfilePath); err != nil {
+		tracker.logger.Warnf("unable to write the session coverage profile to '%s': %s", tracker.config.SessionProfilePath, err)
+	}
+	tracker.logger.Infof("coverage: %.1f%% of statements in this run, %.1f%% in this session", profile.GetCoverage(), tracker.session.GetCoverage())
+}
@@ -0,0 +1,75 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CoverageTrackerTestSuite struct {
+	suite.Suite
+	directory string
+	tracker   *CoverageTracker
+	logs      bytes.Buffer
+}
+
+func TestCoverageTracker(t *testing.T) {
+	suite.Run(t, new(CoverageTrackerTestSuite))
+}
+
+func (s *CoverageTrackerTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-coverage-tracker")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.tracker = InitCoverageTracker(&CoverageTrackerConfig{
+		LogLevel:           "trace",
+		ProfilePath:        path.Join(directory, "c.out"),
+		SessionProfilePath: path.Join(directory, "c.session.out"),
+	})
+	s.logs.Reset()
+	s.tracker.logger.SetOutput(&s.logs)
+}
+
+func (s *CoverageTrackerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *CoverageTrackerTestSuite) writeProfile(contents string, modTime time.Time) {
+	profilePath := path.Join(s.directory, "c.out")
+	assert.Nil(s.T(), ioutil.WriteFile(profilePath, []byte(contents), 0644))
+	assert.Nil(s.T(), os.Chtimes(profilePath, modTime, modTime))
+}
+
+func (s *CoverageTrackerTestSuite) TestUpdate() {
+	t := s.T()
+	s.tracker.Update()
+	assert.Equal(t, 0.0, s.tracker.GetCoverage())
+	now := time.Now()
+	s.writeProfile("mode: set\napp/a/a.go:1.1,2.2 1 1\napp/b/b.go:1.1,2.2 1 0\n", now.Add(-time.Minute))
+	s.tracker.Update()
+	assert.Equal(t, 50.0, s.tracker.GetCoverage())
+	s.writeProfile("mode: set\napp/b/b.go:1.1,2.2 1 1\n", now)
+	s.tracker.Update()
+	assert.Equal(t, 100.0, s.tracker.GetCoverage(), "expected app/a to remain covered after only app/b was re-tested")
+	assert.Contains(t, s.logs.String(), "coverage: 100.0% of statements in this run, 100.0% in this session")
+	session, err := LoadCoverageProfile(path.Join(s.directory, "c.session.out"))
+	assert.Nil(t, err)
+	assert.Equal(t, 100.0, session.GetCoverage())
+}
+
+func (s *CoverageTrackerTestSuite) TestUpdateSkipsStaleProfiles() {
+	t := s.T()
+	modTime := time.Now().Add(-time.Minute)
+	s.writeProfile("mode: set\napp/a/a.go:1.1,2.2 1 1\n", modTime)
+	s.tracker.Update()
+	s.writeProfile("mode: set\napp/a/a.go:1.1,2.2 1 0\n", modTime)
+	s.tracker.Update()
+	assert.Equal(t, 100.0, s.tracker.GetCoverage())
+	assert.Contains(t, s.logs.String(), "no new coverage profile")
+}
@@ -0,0 +1,117 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CoverageTestSuite struct {
+	suite.Suite
+}
+
+func TestCoverage(t *testing.T) {
+	suite.Run(t, new(CoverageTestSuite))
+}
+
+func (s *CoverageTestSuite) TestParseCoverageProfile() {
+	t := s.T()
+	profile, err := ParseCoverageProfile(strings.NewReader(`mode: set
+app/main.go:5.13,7.2 1 1
+app/main.go:9.20,11.2 3 0
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, "set", profile.Mode)
+	assert.Equal(t, 25.0, profile.GetCoverage())
+	_, err = ParseCoverageProfile(strings.NewReader("mode: set\napp/main.go:5.13,7.2 one 1\n"))
+	assert.NotNil(t, err)
+	_, err = ParseCoverageProfile(strings.NewReader("mode: set\nmode: count\n"))
+	assert.NotNil(t, err)
+}
+
+func (s *CoverageTestSuite) TestMerge() {
+	t := s.T()
+	first, _ := ParseCoverageProfile(strings.NewReader("mode: count\napp/a.go:1.1,2.2 2 1\napp/b.go:1.1,2.2 2 0\n"))
+	second, _ := ParseCoverageProfile(strings.NewReader("mode: count\napp/a.go:1.1,2.2 2 3\napp/c.go:1.1,2.2 4 1\n"))
+	merged := &CoverageProfile{}
+	assert.Nil(t, merged.Merge(first))
+	assert.Nil(t, merged.Merge(second))
+	var output bytes.Buffer
+	assert.Nil(t, merged.Write(&output))
+	assert.Equal(t, "mode: count\napp/a.go:1.1,2.2 2 4\napp/b.go:1.1,2.2 2 0\napp/c.go:1.1,2.2 4 1\n", output.String())
+	set, _ := ParseCoverageProfile(strings.NewReader("mode: set\napp/a.go:1.1,2.2 2 1\n"))
+	assert.NotNil(t, merged.Merge(set), "expected profiles of different modes to not be merged")
+	assert.Nil(t, set.Merge(set))
+	output.Reset()
+	set.Write(&output)
+	assert.Contains(t, output.String(), "app/a.go:1.1,2.2 2 1\n")
+}
+
+func (s *CoverageTestSuite) TestReplace() {
+	t := s.T()
+	session, _ := ParseCoverageProfile(strings.NewReader("mode: set\napp/a.go:1.1,2.2 2 1\napp/a.go:3.1,4.2 2 1\napp/b.go:1.1,2.2 2 0\n"))
+	rerun, _ := ParseCoverageProfile(strings.NewReader("mode: set\napp/a.go:1.1,2.2 2 0\napp/a.go:3.1,4.2 2 0\n"))
+	assert.Nil(t, session.Replace(rerun))
+	assert.Equal(t, 0.0, session.GetCoverage())
+	covered, _ := ParseCoverageProfile(strings.NewReader("mode: set\napp/b.go:1.1,2.2 2 1\n"))
+	assert.Nil(t, session.Replace(covered))
+	assert.InDelta(t, 33.3, session.GetCoverage(), 0.1)
+	count, _ := ParseCoverageProfile(strings.NewReader("mode: count\napp/c.go:1.1,2.2 2 5\n"))
+	assert.Nil(t, session.Replace(count), "expected a profile of a different mode to replace the session")
+	assert.Equal(t, "count", session.Mode)
+	assert.Equal(t, 100.0, session.GetCoverage())
+}
+
+func (s *CoverageTestSuite) TestLoadCoverageProfileAndWriteFile() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-coverage")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	profile, _ := ParseCoverageProfile(strings.NewReader("mode: atomic\napp/a.go:1.1,2.2 2 1\n"))
+	filePath := path.Join(directory, "c.out")
+	assert.Nil(t, profile.WriteFile(filePath))
+	loaded, err := LoadCoverageProfile(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, "atomic", loaded.Mode)
+	assert.Equal(t, 100.0, loaded.GetCoverage())
+	_, err = LoadCoverageProfile(path.Join(directory, "missing.out"))
+	assert.NotNil(t, err)
+}
+
+func (s *CoverageTestSuite) Test_shardPackages() {
+	t := s.T()
+	packages := []string{"app/a", "app/b", "app/c", "app/d", "app/e"}
+	assert.Equal(t, [][]string{{"app/a", "app/c", "app/e"}, {"app/b", "app/d"}}, shardPackages(packages, 2))
+	assert.Len(t, shardPackages(packages, 8), 5)
+	assert.Nil(t, shardPackages(nil, 4))
+}
+
+func (s *CoverageTestSuite) Test_listPackages() {
+	t := s.T()
+	directory := createTestModule(t, "a", "b")
+	defer os.RemoveAll(directory)
+	packages, err := listPackages(directory, []string{"./..."})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"example.com/shards/a", "example.com/shards/b"}, packages)
+	_, err = listPackages(directory, []string{"./missing"})
+	assert.NotNil(t, err)
+}
+
+// createTestModule creates a module in a temporary directory with a
+// package for each of :packageNames
+func createTestModule(t *testing.T, packageNames ...string) string {
+	directory, err := ioutil.TempDir("", "godev-module")
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "go.mod"), []byte("module example.com/shards\n"), 0644))
+	for _, packageName := range packageNames {
+		assert.Nil(t, os.MkdirAll(path.Join(directory, packageName), 0755))
+		assert.Nil(t, ioutil.WriteFile(path.Join(directory, packageName, packageName+".go"), []byte("package "+packageName+"\n"), 0644))
+	}
+	return directory
+}
@@ -0,0 +1,83 @@
+package godev
+
+import (
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DaemonTaskPrefix is prepended to the names of registered background tasks
+const DaemonTaskPrefix = "godev"
+
+// DaemonConfig configures Daemon
+type DaemonConfig struct {
+	Arguments      []string
+	Executable     string
+	WatchDirectory string
+	WorkDirectory  string
+}
+
+// InitDaemon creates a Daemon which registers godev as a background
+// task for the project at the configured work directory, it returns an
+// error when the godev executable cannot be found
+func InitDaemon(config *DaemonConfig) (*Daemon, error) {
+	if len(config.Executable) == 0 {
+		executable, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the godev executable: %s", err)
+		}
+		config.Executable = executable
+	}
+	return &Daemon{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:   "daemon",
+			Format: "raw",
+			Level:  "trace",
+		}),
+	}, nil
+}
+
+// Daemon handles the registration of godev as a per-user
+// background task using the operating system's scheduler
+type Daemon struct {
+	config *DaemonConfig
+	logger *Logger
+}
+
+// GetTaskName returns a name for the background task which is unique
+// to the project's work directory
+func (daemon *Daemon) GetTaskName() string {
+	directoryHash := fmt.Sprintf("%x", md5.Sum([]byte(daemon.config.WorkDirectory)))
+	return fmt.Sprintf("%s-%s-%s", DaemonTaskPrefix, filepath.Base(daemon.config.WorkDirectory), directoryHash[:6])
+}
+
+// GetTaskCommand returns the command line the background task will run
+// quoted for the scheduler of the operating system
+func (daemon *Daemon) GetTaskCommand() string {
+	command := []string{
+		daemon.config.Executable,
+		"--dir", daemon.config.WorkDirectory,
+		"--watch", daemon.config.WatchDirectory,
+	}
+	return joinDaemonTaskCommand(append(command, daemon.config.Arguments...))
+}
+
+// Install registers the background task
+func (daemon *Daemon) Install() error {
+	if err := installDaemonTask(daemon.GetTaskName(), daemon.GetTaskCommand()); err != nil {
+		return err
+	}
+	daemon.logger.Infof("registered '%s' to run: %s", daemon.GetTaskName(), daemon.GetTaskCommand())
+	return nil
+}
+
+// Uninstall removes the background task
+func (daemon *Daemon) Uninstall() error {
+	if err := uninstallDaemonTask(daemon.GetTaskName()); err != nil {
+		return err
+	}
+	daemon.logger.Infof("removed '%s'", daemon.GetTaskName())
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"errors"
+
+	shellquote "github.com/kballard/go-shellquote"
+)
+
+var errDaemonUnsupported = errors.New("background task registration is only supported on windows at the moment")
+
+// joinDaemonTaskCommand quotes :command for POSIX shells
+func joinDaemonTaskCommand(command []string) string {
+	return shellquote.Join(command...)
+}
+
+// installDaemonTask is not implemented for this platform yet
+func installDaemonTask(name string, command string) error {
+	return errDaemonUnsupported
+}
+
+// uninstallDaemonTask is not implemented for this platform yet
+func uninstallDaemonTask(name string) error {
+	return errDaemonUnsupported
+}
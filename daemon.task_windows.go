@@ -0,0 +1,43 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// joinDaemonTaskCommand quotes :command the way the Windows runtime
+// splits command lines so that schtasks runs it with the same arguments
+func joinDaemonTaskCommand(command []string) string {
+	var escaped []string
+	for _, argument := range command {
+		escaped = append(escaped, syscall.EscapeArg(argument))
+	}
+	return strings.Join(escaped, " ")
+}
+
+// installDaemonTask registers :command with the Windows Task Scheduler
+// to run at logon of the current user
+func installDaemonTask(name string, command string) error {
+	return runScheduledTasks("/Create", "/F", "/SC", "ONLOGON", "/RL", "LIMITED", "/IT", "/TN", name, "/TR", command)
+}
+
+// uninstallDaemonTask removes the task named :name from the Windows
+// Task Scheduler
+func uninstallDaemonTask(name string) error {
+	return runScheduledTasks("/Delete", "/F", "/TN", name)
+}
+
+func runScheduledTasks(arguments ...string) error {
+	if _, err := exec.LookPath("schtasks"); err != nil {
+		return err
+	}
+	cmd := exec.Command("schtasks", arguments...)
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = os.Stdout
+	return cmd.Run()
+}
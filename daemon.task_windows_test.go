@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinDaemonTaskCommand(t *testing.T) {
+	assert.Equal(
+		t,
+		`"C:\Program Files\godev.exe" --exec "go build -o \"bin\app.exe\""`,
+		joinDaemonTaskCommand([]string{`C:\Program Files\godev.exe`, "--exec", `go build -o "bin\app.exe"`}),
+	)
+}
@@ -0,0 +1,58 @@
+package godev
+
+import (
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DaemonTestSuite struct {
+	suite.Suite
+	daemon *Daemon
+}
+
+func TestDaemon(t *testing.T) {
+	suite.Run(t, new(DaemonTestSuite))
+}
+
+func (s *DaemonTestSuite) SetupTest() {
+	var err error
+	s.daemon, err = InitDaemon(&DaemonConfig{
+		Arguments:      []string{"--exec", "go build -o bin/app"},
+		Executable:     "/path/to/godev",
+		WatchDirectory: "/path/to/watch",
+		WorkDirectory:  "/path/to/project",
+	})
+	assert.Nil(s.T(), err)
+}
+
+func (s *DaemonTestSuite) TestInitDaemon_resolvesExecutable() {
+	daemon, err := InitDaemon(&DaemonConfig{})
+	assert.Nil(s.T(), err)
+	assert.NotEmpty(s.T(), daemon.config.Executable)
+}
+
+func (s *DaemonTestSuite) TestGetTaskName() {
+	t := s.T()
+	assert.Regexp(t, regexp.MustCompile(`^godev-project-[a-f0-9]{6}$`), s.daemon.GetTaskName())
+	other, err := InitDaemon(&DaemonConfig{
+		Executable:    "/path/to/godev",
+		WorkDirectory: "/path/to/another/project",
+	})
+	assert.Nil(t, err)
+	assert.NotEqual(t, s.daemon.GetTaskName(), other.GetTaskName())
+}
+
+func (s *DaemonTestSuite) TestGetTaskCommand() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("the task command is quoted for the Windows runtime")
+	}
+	assert.Equal(
+		s.T(),
+		"/path/to/godev --dir /path/to/project --watch /path/to/watch --exec 'go build -o bin/app'",
+		s.daemon.GetTaskCommand(),
+	)
+}
@@ -8,7 +8,7 @@
 //
 // FILE GENERATED USING ~/app/data/generate.go
 
-package main
+package godev
 
 // Version is used by godev for reporting the version when installed via 'go get'
 const Version = "0.6.2"
@@ -17,14 +17,14 @@ const Version = "0.6.2"
 const Commit = "c787f3f"
 
 // DataDockerfile defines the 'Dockerfile' contents when --init is used
-// hash:fc3c6491cb0d101ae17e2e68aec4714f
+// hash:06808b24a86559e1859445723dd2aeaf
 const DataDockerfile = `## 
 ## base image - defines the operating system layer for the build
 ## -------------------------------------------------------------
 ## use this to adjust the version of golang you want a build with
-ARG GOLANG_VERSION=1.11.5
+ARG GOLANG_VERSION=1.22.12
 ## use this to adjust the version of alpine to run for the build
-ARG ALPINE_VERSION=3.9
+ARG ALPINE_VERSION=3.21
 FROM golang:${GOLANG_VERSION}-alpine${ALPINE_VERSION} AS base
 ## allow for passing in of any additional packages you might need
 ARG ADDITIONAL_APKS
@@ -41,7 +41,7 @@ RUN apk add --no-cache make
 ##
 ## development image - where things are actually built
 ## ---------------------------------------------------
-FROM base as development
+FROM base AS development
 ## what should we name our binary? (default indicates "app")
 ARG BIN_NAME=app
 ## any extension we would like for our binary? (default indicates nothing)
@@ -75,6 +75,10 @@ RUN ln -s /${BIN_NAME} /_
 RUN chmod +x /_
 ## let it start
 ENTRYPOINT ["/_"]
+## to develop in the container, use godev as the entrypoint instead - as
+## PID 1 it reaps zombie processes and forwards signals to your commands
+# RUN go get github.com/zephinzer/godev
+# ENTRYPOINT ["godev", "--dir", "."]
 
 ##
 # production image - the really small image
@@ -96,7 +100,7 @@ ENTRYPOINT ["/_"]
 `
 
 // DataMakefile defines the 'Makefile' contents when --init is used
-// hash:5ae20a94ab0d7cb71f695bdbf916bce9
+// hash:a50c4e3f5786da98f47b6601ad3144f8
 const DataMakefile = `##
 ## Makefile constants - extract to a separate file if needed
 ## ---------------------------------------------------------
@@ -140,8 +144,8 @@ compile.windows:
 	@$(MAKE) GOARCH=386 GOOS=windows BIN_EXT=.exe .compile
 ## compilation driver
 .compile:
-	@CGO_EMABLED=0 GO111MODULE=on \
-		go build -a -ldflags "-extldflags -static" -o $(CURDIR)/$(BIN_PATH)/$(BIN_NAME)-${GOOS}-${GOARCH}${BIN_EXT}
+	@CGO_ENABLED=0 GO111MODULE=on \
+		go build -ldflags "-extldflags -static" -o $(CURDIR)/$(BIN_PATH)/$(BIN_NAME)-${GOOS}-${GOARCH}${BIN_EXT}
 	@chmod +x $(CURDIR)/$(BIN_PATH)/$(BIN_NAME)-${GOOS}-${GOARCH}${BIN_EXT}
 	@sha256sum $(CURDIR)/$(BIN_PATH)/$(BIN_NAME)-${GOOS}-${GOARCH}${BIN_EXT} | cut -d " " -f 1 > $(CURDIR)/$(BIN_PATH)/$(BIN_NAME)-${GOOS}-${GOARCH}${BIN_EXT}.sha256
 ## dockerisation for production
@@ -157,7 +161,6 @@ docker.dev:
 		--target ${STAGE} \
 		--build-arg BIN_NAME=$(BIN_NAME) \
 		--build-arg BIN_PATH=$(BIN_PATH) \
-		--target=production \
 		-t $(DOCKER_IMAGE_NAMESPACE)/$(DOCKER_IMAGE_NAME):latest \
 		.
 docker.prepare: docker
@@ -239,9 +242,11 @@ func main() {
 `
 
 // DataGoDotMod defines the 'go.mod' contents when --init is used
-// hash:b6791696ce7f0e334775b206f1fa9dea
+// hash:6a8266d02aac33d13061dd42399f9cce
 const DataGoDotMod = `module app
 
+go 1.22
+
 `
 
 
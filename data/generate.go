@@ -142,7 +142,7 @@ const generatedFileWarning = `
 
 var DataDotGoTemplate = template.Must(template.New("test").Parse(`// > data.go
 ` + generatedFileWarning + `
-package main
+package godev
 
 // Version is used by godev for reporting the version when installed via 'go get'
 const Version = "{{.AppVersion}}"
@@ -0,0 +1,313 @@
+package godev
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"html/template"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DocsServerConfig configures DocsServer
+type DocsServerConfig struct {
+	Address      string
+	Directory    string
+	IgnoredNames []string
+	LogLevel     LogLevel
+	RunState     *RunState
+}
+
+// InitDocsServer creates a DocsServer which serves the documentation of
+// the packages in the configured directory
+func InitDocsServer(config *DocsServerConfig) *DocsServer {
+	server := &DocsServer{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:     "docs",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: config.RunState,
+		}),
+		mux: http.NewServeMux(),
+	}
+	server.mux.HandleFunc("/", server.handleIndex)
+	server.mux.HandleFunc("/pkg/", server.handlePackage)
+	return server
+}
+
+// DocsServer is the component which serves the API documentation of the
+// module being developed - packages are parsed on every request so that
+// the documentation is always that of the files on disk
+type DocsServer struct {
+	config   *DocsServerConfig
+	logger   *Logger
+	listener net.Listener
+	mux      *http.ServeMux
+}
+
+// DocsPackage is a package listed in the index of the documentation
+type DocsPackage struct {
+	// Path is the directory of the package relative to the module
+	Path       string
+	ImportPath string
+	Name       string
+	Synopsis   string
+}
+
+// URL returns the path which the documentation of the package is at
+func (docsPackage DocsPackage) URL() string {
+	if docsPackage.Path == "." {
+		return "/pkg/"
+	}
+	return "/pkg/" + docsPackage.Path
+}
+
+// Start listens on the configured address and serves the documentation
+// in the background
+func (server *DocsServer) Start() error {
+	listener, err := net.Listen("tcp", server.config.Address)
+	if err != nil {
+		return err
+	}
+	server.listener = listener
+	server.logger.Infof("documentation served at 'http://%s'", listener.Addr().String())
+	go func() {
+		if err := http.Serve(listener, server.mux); err != nil {
+			server.logger.Debugf("documentation server stopped: %s", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the documentation server from accepting requests
+func (server *DocsServer) Close() error {
+	if server.listener == nil {
+		return nil
+	}
+	return server.listener.Close()
+}
+
+// ServeHTTP implements http.Handler
+func (server *DocsServer) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	server.mux.ServeHTTP(response, request)
+}
+
+// handleIndex handles GET / with the list of packages of the module
+func (server *DocsServer) handleIndex(response http.ResponseWriter, request *http.Request) {
+	if request.URL.Path != "/" {
+		http.NotFound(response, request)
+		return
+	}
+	server.render(response, docsIndexTemplate, map[string]interface{}{
+		"Module":   server.getModulePath(),
+		"Packages": server.GetPackages(),
+	})
+}
+
+// handlePackage handles GET /pkg/<path> with the documentation of the
+// package in the directory <path> of the module
+func (server *DocsServer) handlePackage(response http.ResponseWriter, request *http.Request) {
+	relativePath := strings.Trim(strings.TrimPrefix(request.URL.Path, "/pkg/"), "/")
+	if len(relativePath) == 0 {
+		relativePath = "."
+	}
+	if strings.HasPrefix(path.Clean(relativePath), "..") {
+		http.NotFound(response, request)
+		return
+	}
+	fset := token.NewFileSet()
+	docPackage, err := server.parsePackage(fset, relativePath)
+	if err != nil {
+		server.logger.Debugf("unable to document the package at '%s': %s", relativePath, err)
+		http.NotFound(response, request)
+		return
+	}
+	server.render(response, docsPackageTemplate, &docsPackagePage{fset: fset, Package: docPackage, Path: relativePath})
+}
+
+// render writes :page rendered with :data to :response
+func (server *DocsServer) render(response http.ResponseWriter, page *template.Template, data interface{}) {
+	var output bytes.Buffer
+	if err := page.Execute(&output, data); err != nil {
+		server.logger.Warnf("unable to render the documentation: %s", err)
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	response.Write(output.Bytes())
+}
+
+// GetPackages returns the packages in the directory of the server and
+// the directories below it which are neither hidden, ignored, test data
+// nor other modules, sorted by their paths
+func (server *DocsServer) GetPackages() []DocsPackage {
+	packages := []DocsPackage{}
+	filepath.Walk(server.config.Directory, func(filePath string, fileInfo os.FileInfo, err error) error {
+		if err != nil || !fileInfo.IsDir() {
+			return nil
+		}
+		name := fileInfo.Name()
+		isExcluded := strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" ||
+			sliceContainsString(server.config.IgnoredNames, name) ||
+			fileExists(filepath.Join(filePath, "go.mod"))
+		if filePath != server.config.Directory && isExcluded {
+			return filepath.SkipDir
+		}
+		relativePath, err := filepath.Rel(server.config.Directory, filePath)
+		if err != nil {
+			return nil
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		docPackage, err := server.parsePackage(token.NewFileSet(), relativePath)
+		if err != nil {
+			return nil
+		}
+		packages = append(packages, DocsPackage{
+			Path:       relativePath,
+			ImportPath: docPackage.ImportPath,
+			Name:       docPackage.Name,
+			Synopsis:   docPackage.Synopsis(docPackage.Doc),
+		})
+		return nil
+	})
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	return packages
+}
+
+// parsePackage parses the .go files of the package in the directory
+// :relativePath which are built for the current platform, excluding
+// tests, into its documentation
+func (server *DocsServer) parsePackage(fset *token.FileSet, relativePath string) (*doc.Package, error) {
+	directory := filepath.Join(server.config.Directory, filepath.FromSlash(relativePath))
+	listings, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, listing := range listings {
+		name := listing.Name()
+		if listing.IsDir() || path.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		} else if matched, err := build.Default.MatchFile(directory, name); err != nil || !matched {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(directory, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		} else if len(files) > 0 && file.Name.Name != files[0].Name.Name {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("'%s' has no go files", directory)
+	}
+	importPath := server.getModulePath()
+	if relativePath != "." {
+		importPath = path.Join(importPath, relativePath)
+	}
+	mode := doc.Mode(0)
+	if files[0].Name.Name == "main" {
+		mode = doc.AllDecls
+	}
+	return doc.NewFromFiles(fset, files, importPath, mode)
+}
+
+// getModulePath returns the path of the module declared in the go.mod
+// of the directory of the server, or the name of the directory outside
+// of modules
+func (server *DocsServer) getModulePath() string {
+	return getModulePath(server.config.Directory)
+}
+
+// getModulePath returns the path of the module declared in the go.mod in
+// :directory, or the name of :directory outside of modules
+func getModulePath(directory string) string {
+	contents, err := ioutil.ReadFile(filepath.Join(directory, "go.mod"))
+	if err == nil {
+		for _, line := range strings.Split(string(contents), "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+				return strings.Trim(fields[1], `"`)
+			}
+		}
+	}
+	return filepath.Base(directory)
+}
+
+// docsPackagePage is what the documentation of a package is rendered from
+type docsPackagePage struct {
+	*doc.Package
+	Path string
+	fset *token.FileSet
+}
+
+// Comment renders the doc comment :text as HTML
+func (page *docsPackagePage) Comment(text string) template.HTML {
+	return template.HTML(page.Package.HTML(text))
+}
+
+// Declaration renders the source of :declaration without the body of
+// functions
+func (page *docsPackagePage) Declaration(declaration ast.Decl) string {
+	if function, ok := declaration.(*ast.FuncDecl); ok {
+		withoutBody := *function
+		withoutBody.Body = nil
+		withoutBody.Doc = nil
+		declaration = &withoutBody
+	} else if general, ok := declaration.(*ast.GenDecl); ok {
+		withoutDoc := *general
+		withoutDoc.Doc = nil
+		declaration = &withoutDoc
+	}
+	var output bytes.Buffer
+	if err := printer.Fprint(&output, page.fset, declaration); err != nil {
+		return err.Error()
+	}
+	return output.String()
+}
+
+const docsStyle = `<style>
+body { font-family: sans-serif; max-width: 960px; margin: 0 auto; padding: 1em; color: #202224; }
+pre { background: #f6f8fa; padding: 0.75em; overflow-x: auto; }
+a { color: #007d9c; text-decoration: none; }
+h2, h3 { margin-top: 1.5em; }
+td { padding: 0.25em 1em 0.25em 0; vertical-align: top; }
+</style>`
+
+var docsIndexTemplate = template.Must(template.New("index").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Module}}</title>` + docsStyle + `</head><body>
+<h1>{{.Module}}</h1>
+<table>
+{{range .Packages}}<tr><td><a href="{{.URL}}">{{.ImportPath}}</a></td><td>{{.Synopsis}}</td></tr>
+{{else}}<tr><td>there are no packages to document</td></tr>
+{{end}}</table>
+</body></html>`))
+
+var docsPackageTemplate = template.Must(template.New("package").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Name}} - {{.ImportPath}}</title>` + docsStyle + `</head><body>
+<p><a href="/">index</a></p>
+<h1>package {{.Name}}</h1>
+<pre>import "{{.ImportPath}}"</pre>
+{{$.Comment .Doc}}
+{{with .Consts}}<h2>Constants</h2>{{range .}}<pre>{{$.Declaration .Decl}}</pre>{{$.Comment .Doc}}{{end}}{{end}}
+{{with .Vars}}<h2>Variables</h2>{{range .}}<pre>{{$.Declaration .Decl}}</pre>{{$.Comment .Doc}}{{end}}{{end}}
+{{with .Funcs}}<h2>Functions</h2>{{range .}}<h3 id="{{.Name}}">func {{.Name}}</h3><pre>{{$.Declaration .Decl}}</pre>{{$.Comment .Doc}}{{end}}{{end}}
+{{with .Types}}<h2>Types</h2>{{range .}}<h3 id="{{.Name}}">type {{.Name}}</h3><pre>{{$.Declaration .Decl}}</pre>{{$.Comment .Doc}}
+{{range .Consts}}<pre>{{$.Declaration .Decl}}</pre>{{$.Comment .Doc}}{{end}}
+{{range .Vars}}<pre>{{$.Declaration .Decl}}</pre>{{$.Comment .Doc}}{{end}}
+{{range .Funcs}}<h4 id="{{.Name}}">func {{.Name}}</h4><pre>{{$.Declaration .Decl}}</pre>{{$.Comment .Doc}}{{end}}
+{{range .Methods}}<h4 id="{{.Recv}}.{{.Name}}">func ({{.Recv}}) {{.Name}}</h4><pre>{{$.Declaration .Decl}}</pre>{{$.Comment .Doc}}{{end}}
+{{end}}{{end}}
+</body></html>`))
@@ -0,0 +1,99 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DocsServerTestSuite struct {
+	suite.Suite
+	directory string
+	server    *DocsServer
+	logs      bytes.Buffer
+}
+
+func TestDocsServer(t *testing.T) {
+	suite.Run(t, new(DocsServerTestSuite))
+}
+
+func (s *DocsServerTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-docs")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.writeFile("go.mod", "module example.com/app\n")
+	s.writeFile("main.go", "// Command app serves the app.\npackage main\n\nfunc main() {}\n")
+	s.writeFile("pkg/user/user.go", "// Package user manages users.\npackage user\n\n// User is a user of the app\ntype User struct {\n\tName string\n}\n\n// New creates a User called <name>\nfunc New(name string) *User {\n\treturn &User{Name: name}\n}\n")
+	s.writeFile("pkg/user/user_test.go", "package user\n\nfunc TestHidden() {}\n")
+	s.writeFile("vendor/lib/lib.go", "package lib\n")
+	s.writeFile("tools/go.mod", "module example.com/tools\n")
+	s.writeFile("tools/tools.go", "package tools\n")
+	s.server = InitDocsServer(&DocsServerConfig{
+		Address:      "127.0.0.1:0",
+		Directory:    directory,
+		IgnoredNames: []string{"vendor"},
+	})
+	s.logs.Reset()
+	s.server.logger.SetOutput(&s.logs)
+}
+
+func (s *DocsServerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *DocsServerTestSuite) writeFile(relativePath, contents string) {
+	filePath := path.Join(s.directory, relativePath)
+	assert.Nil(s.T(), os.MkdirAll(path.Dir(filePath), os.ModePerm))
+	assert.Nil(s.T(), ioutil.WriteFile(filePath, []byte(contents), 0644))
+}
+
+func (s *DocsServerTestSuite) request(path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+func (s *DocsServerTestSuite) TestGetPackages() {
+	t := s.T()
+	assert.Equal(t, []DocsPackage{
+		{Path: ".", ImportPath: "example.com/app", Name: "main", Synopsis: "Command app serves the app."},
+		{Path: "pkg/user", ImportPath: "example.com/app/pkg/user", Name: "user", Synopsis: "Package user manages users."},
+	}, s.server.GetPackages(), "expected ignored directories and other modules to be skipped")
+}
+
+func (s *DocsServerTestSuite) TestIndex() {
+	t := s.T()
+	response := s.request("/")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `<a href="/pkg/">example.com/app</a>`)
+	assert.Contains(t, response.Body.String(), `<a href="/pkg/pkg/user">example.com/app/pkg/user</a>`)
+	assert.Equal(t, http.StatusNotFound, s.request("/missing").Code)
+}
+
+func (s *DocsServerTestSuite) TestPackage() {
+	t := s.T()
+	response := s.request("/pkg/pkg/user")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "func New(name string) *User")
+	assert.Contains(t, response.Body.String(), "New creates a User called &lt;name&gt;")
+	assert.NotContains(t, response.Body.String(), "return &amp;User", "expected function bodies to be left out")
+	assert.NotContains(t, response.Body.String(), "TestHidden")
+	s.writeFile("pkg/user/user.go", "package user\n\n// Delete removes a user\nfunc Delete() {}\n")
+	assert.Contains(t, s.request("/pkg/pkg/user").Body.String(), "func Delete()", "expected changes to be documented without restarting")
+	assert.NotEqual(t, http.StatusOK, s.request("/pkg/../etc").Code, "expected paths outside of the directory to not be served")
+	assert.Equal(t, http.StatusNotFound, s.request("/pkg/missing").Code)
+}
+
+func (s *DocsServerTestSuite) TestStartAndClose() {
+	t := s.T()
+	assert.Nil(t, s.server.Start())
+	assert.Contains(t, s.logs.String(), "documentation served at 'http://127.0.0.1:")
+	assert.Nil(t, s.server.Close())
+}
@@ -0,0 +1,129 @@
+package godev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// EnvironmentDiff holds the names of environment variables which
+// differ between two environments
+type EnvironmentDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// DiffEnvironment compares the KEY=value pairs in :previous and :current,
+// later duplicates of a key override earlier ones as they do for exec
+func DiffEnvironment(previous, current []string) *EnvironmentDiff {
+	previousValues := parseEnvironment(previous)
+	currentValues := parseEnvironment(current)
+	diff := &EnvironmentDiff{}
+	for key, value := range currentValues {
+		if previousValue, ok := previousValues[key]; !ok {
+			diff.Added = append(diff.Added, key)
+		} else if previousValue != value {
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for key := range previousValues {
+		if _, ok := currentValues[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// IsEmpty returns true if both environments were the same
+func (diff *EnvironmentDiff) IsEmpty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0
+}
+
+// String returns the diff in the form "+ADDED -REMOVED ~CHANGED" - values
+// are never included so that secrets do not end up in the logs
+func (diff *EnvironmentDiff) String() string {
+	var entries []string
+	for _, key := range diff.Added {
+		entries = append(entries, "+"+key)
+	}
+	for _, key := range diff.Removed {
+		entries = append(entries, "-"+key)
+	}
+	for _, key := range diff.Changed {
+		entries = append(entries, "~"+key)
+	}
+	return fmt.Sprintf(
+		"%v added, %v removed, %v changed: %s",
+		len(diff.Added),
+		len(diff.Removed),
+		len(diff.Changed),
+		strings.Join(entries, " "),
+	)
+}
+
+// parseEnvironment converts KEY=value pairs into a map
+func parseEnvironment(environment []string) map[string]string {
+	values := map[string]string{}
+	for _, keyValue := range environment {
+		sections := strings.SplitN(keyValue, "=", 2)
+		if len(sections) == 2 {
+			values[sections[0]] = sections[1]
+		} else {
+			values[sections[0]] = ""
+		}
+	}
+	return values
+}
+
+// LoadEnvironmentFile reads the .env file at :filePath and returns its
+// variables as KEY=value pairs in the order they were defined
+func LoadEnvironmentFile(filePath string) ([]string, error) {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	environment, err := parseEnvironmentFile(string(contents))
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid .env file: %s", filePath, err)
+	}
+	return environment, nil
+}
+
+// parseEnvironmentFile parses the contents of a .env file where each
+// line is KEY=value optionally preceded by 'export', values may be
+// wrapped in single or double quotes and lines starting with # are comments
+func parseEnvironmentFile(contents string) ([]string, error) {
+	var environment []string
+	for lineNumber, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		sections := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(sections[0])
+		if len(sections) != 2 || len(key) == 0 || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %v: expected KEY=value but got '%s'", lineNumber+1, line)
+		}
+		value := strings.TrimSpace(sections[1])
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') {
+			if value[len(value)-1] != value[0] {
+				return nil, fmt.Errorf("line %v: unterminated quote in value of '%s'", lineNumber+1, key)
+			}
+			if value[0] == '"' {
+				value = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value[1 : len(value)-1])
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		} else if index := strings.Index(value, " #"); index >= 0 {
+			value = strings.TrimSpace(value[:index])
+		}
+		environment = append(environment, fmt.Sprintf("%s=%s", key, value))
+	}
+	return environment, nil
+}
@@ -0,0 +1,61 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvironmentTestSuite struct {
+	suite.Suite
+}
+
+func TestEnvironment(t *testing.T) {
+	suite.Run(t, new(EnvironmentTestSuite))
+}
+
+func (s *EnvironmentTestSuite) TestDiffEnvironment() {
+	t := s.T()
+	diff := DiffEnvironment(
+		[]string{"KEEP=1", "REMOVED=secret", "CHANGED=old", "OVERRIDDEN=a", "OVERRIDDEN=b"},
+		[]string{"KEEP=1", "CHANGED=new", "ADDED=value", "OVERRIDDEN=b"},
+	)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []string{"ADDED"}, diff.Added)
+	assert.Equal(t, []string{"REMOVED"}, diff.Removed)
+	assert.Equal(t, []string{"CHANGED"}, diff.Changed)
+	assert.Equal(t, "1 added, 1 removed, 1 changed: +ADDED -REMOVED ~CHANGED", diff.String())
+	assert.NotContains(t, diff.String(), "secret")
+	assert.NotContains(t, diff.String(), "new")
+}
+
+func (s *EnvironmentTestSuite) TestDiffEnvironment_identical() {
+	assert.True(s.T(), DiffEnvironment([]string{"A=1", "B"}, []string{"B", "A=1"}).IsEmpty())
+}
+
+func (s *EnvironmentTestSuite) Test_parseEnvironmentFile() {
+	t := s.T()
+	environment, err := parseEnvironmentFile(`
+# database settings
+DB_HOST=localhost
+export DB_PORT=5432
+DB_PASSWORD="p@ss word \"quoted\""
+GREETING='hello # world'
+EMPTY=
+INLINE=value # comment
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"DB_HOST=localhost",
+		"DB_PORT=5432",
+		`DB_PASSWORD=p@ss word "quoted"`,
+		"GREETING=hello # world",
+		"EMPTY=",
+		"INLINE=value",
+	}, environment)
+	for _, invalid := range []string{"NO_VALUE", "=value", "BAD KEY=1", `QUOTE="unterminated`} {
+		_, err := parseEnvironmentFile(invalid)
+		assert.NotNilf(t, err, "expected '%s' to be invalid", invalid)
+	}
+}
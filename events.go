@@ -0,0 +1,140 @@
+package godev
+
+import (
+	"sync"
+	"time"
+)
+
+// names of the events published on the EventBus
+const (
+	// EventBuildStarted is published when a pipeline starts
+	EventBuildStarted = "build-started"
+	// EventBuildCompleted is published when the execution groups which
+	// build the application have finished, before the application is
+	// started, or with EventBuildFinished when there is no application -
+	// it is failed when one of them failed or the build was stopped
+	EventBuildCompleted = "build-completed"
+	// EventBuildFinished is published when a pipeline has run all of its
+	// execution groups or has stopped because one of them failed, which
+	// is when the application exits in watch mode
+	EventBuildFinished = "build-finished"
+	// EventTestFailed is published after a pipeline in test mode with
+	// the tests which failed
+	EventTestFailed = "test-failed"
+	// EventProcessCrashed is published when the application run by the
+	// last execution group exits with an error without being terminated
+	EventProcessCrashed = "process-crashed"
+	// EventWatcherPaused is published when file system changes stop
+	// triggering the pipeline
+	EventWatcherPaused = "watcher-paused"
+	// EventWatcherResumed is published when file system changes trigger
+	// the pipeline again
+	EventWatcherResumed = "watcher-resumed"
+)
+
+// EventAll can be subscribed to for every event published
+const EventAll = "*"
+
+// Event is published on the EventBus, fields which do not apply to the
+// event are left empty - Duration is in nanoseconds when encoded
+type Event struct {
+	Name     string    `json:"name"`
+	Time     time.Time `json:"time"`
+	Pipeline int       `json:"pipeline,omitempty"`
+	// ChangedFiles are the files whose changes triggered the pipeline,
+	// nil when it was triggered otherwise
+	ChangedFiles []string      `json:"changedFiles,omitempty"`
+	Duration     time.Duration `json:"duration,omitempty"`
+	Failed       bool          `json:"failed,omitempty"`
+	Command      string        `json:"command,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Tests        []TestResult  `json:"tests,omitempty"`
+	Reason       string        `json:"reason,omitempty"`
+}
+
+// EventHandler is called with each event that it was subscribed to
+type EventHandler func(*Event)
+
+// EventBusConfig configures the EventBus
+type EventBusConfig struct {
+	LogLevel LogLevel
+	RunState *RunState
+}
+
+// InitEventBus returns an EventBus without subscribers
+func InitEventBus(config *EventBusConfig) *EventBus {
+	return &EventBus{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:     "events",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: config.RunState,
+		}),
+	}
+}
+
+// EventBus passes the events published by the runner, the execution
+// groups and the watcher to the features which act on them - a nil
+// EventBus drops everything published to it
+type EventBus struct {
+	config        *EventBusConfig
+	logger        *Logger
+	subscriptions []*eventSubscription
+	mutex         sync.RWMutex
+}
+
+type eventSubscription struct {
+	name    string
+	handler EventHandler
+}
+
+// Subscribe calls :handler with every event named :name (or every event
+// for EventAll) in the order of subscription and returns a function to
+// unsubscribe it
+func (bus *EventBus) Subscribe(name string, handler EventHandler) func() {
+	subscription := &eventSubscription{name: name, handler: handler}
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	bus.subscriptions = append(bus.subscriptions, subscription)
+	return func() {
+		bus.mutex.Lock()
+		defer bus.mutex.Unlock()
+		for index, existing := range bus.subscriptions {
+			if existing == subscription {
+				bus.subscriptions = append(bus.subscriptions[:index], bus.subscriptions[index+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish calls the handlers subscribed to :event before returning, its
+// Time is set if it was not - a panicking handler is logged and does not
+// stop the others
+func (bus *EventBus) Publish(event *Event) {
+	if bus == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	bus.mutex.RLock()
+	subscriptions := append([]*eventSubscription{}, bus.subscriptions...)
+	bus.mutex.RUnlock()
+	bus.logger.Tracef("publishing %s to %v subscription(s)", event.Name, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if subscription.name == event.Name || subscription.name == EventAll {
+			bus.callHandler(subscription.handler, event)
+		}
+	}
+}
+
+func (bus *EventBus) callHandler(handler EventHandler, event *Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			bus.logger.Warnf("a handler of %s failed: %v", event.Name, r)
+		}
+	}()
+	handler(event)
+}
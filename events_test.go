@@ -0,0 +1,63 @@
+package godev
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+	bus  *EventBus
+	logs bytes.Buffer
+}
+
+func TestEventBus(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
+
+func (s *EventBusTestSuite) SetupTest() {
+	s.logs.Reset()
+	s.bus = InitEventBus(&EventBusConfig{})
+	s.bus.logger.SetOutput(&s.logs)
+}
+
+func (s *EventBusTestSuite) TestPublish() {
+	t := s.T()
+	var received []string
+	s.bus.Subscribe(EventBuildStarted, func(event *Event) { received = append(received, "started:"+event.Name) })
+	s.bus.Subscribe(EventAll, func(event *Event) { received = append(received, "all:"+event.Name) })
+	event := &Event{Name: EventBuildStarted}
+	s.bus.Publish(event)
+	s.bus.Publish(&Event{Name: EventBuildFinished})
+	assert.Equal(t, []string{"started:build-started", "all:build-started", "all:build-finished"}, received)
+	assert.False(t, event.Time.IsZero(), "expected the time to be set")
+}
+
+func (s *EventBusTestSuite) TestPublish_recoversFromPanics() {
+	t := s.T()
+	called := false
+	s.bus.Subscribe(EventBuildFinished, func(*Event) { panic("oops") })
+	s.bus.Subscribe(EventBuildFinished, func(*Event) { called = true })
+	s.bus.Publish(&Event{Name: EventBuildFinished})
+	assert.True(t, called, "expected the handlers after a panicking one to be called")
+	assert.Contains(t, s.logs.String(), "a handler of build-finished failed: oops")
+}
+
+func (s *EventBusTestSuite) TestPublish_withoutBus() {
+	var bus *EventBus
+	assert.NotPanics(s.T(), func() { bus.Publish(&Event{Name: EventBuildStarted}) })
+}
+
+func (s *EventBusTestSuite) TestSubscribe_unsubscribe() {
+	t := s.T()
+	calls := 0
+	unsubscribe := s.bus.Subscribe(EventAll, func(*Event) { calls++ })
+	s.bus.Publish(&Event{Name: EventWatcherPaused})
+	unsubscribe()
+	unsubscribe()
+	s.bus.Publish(&Event{Name: EventWatcherPaused})
+	assert.Equal(t, 1, calls)
+}
@@ -1,24 +1,814 @@
-package main
+package godev
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
-)
+	"time"
 
-// ExecutionGroupCount keeps track of the execution group count for
-// display in the verbose logs - helps to differentiate between
-// the different execution groups
-var ExecutionGroupCount = 0
+	shellquote "github.com/kballard/go-shellquote"
+)
 
 // ExecutionGroup runs all commands in parallel
 type ExecutionGroup struct {
-	commands  []*Command
-	waitGroup sync.WaitGroup
-	logger    *Logger
+	commands     []*Command
+	waitGroup    sync.WaitGroup
+	logger       *Logger
+	minInterval  time.Duration
+	lastRun      time.Time
+	lastRunMutex sync.Mutex
+	onlyOn       []string
+	lastDuration time.Duration
+	lastErrors   []string
+	lastExitCode int
+	errorsMutex  sync.Mutex
+	events       *EventBus
+	// supervised is set for the execution group which runs the
+	// application, its commands exiting with an error without being
+	// terminated are published as EventProcessCrashed
+	supervised bool
+	// terminating is set while the execution group is being terminated,
+	// it is read by the goroutines of its commands and the Runner so it
+	// is only accessed through isTerminating and setTerminating
+	terminating      bool
+	terminatingMutex sync.Mutex
+	// name is declared with a name=... option so that --route can route
+	// the changes matching routes to the execution group
+	name   string
+	routes []string
+	// skipScript is given to named execution groups by the skip-groups
+	// scripts of the configuration file
+	skipScript *Script
+	// dependencies are the indices in the pipeline of the execution
+	// groups which have to succeed before this one runs, they are only
+	// set when the pipeline is run as a graph
+	dependencies []int
+	// retrying counts the commands waiting out their backoff before they
+	// are run again, cancel is called by Terminate to cancel the context
+	// of the current run which stops its commands and those retries
+	retrying   int
+	cancel     context.CancelFunc
+	retryMutex sync.Mutex
+	// procs is the semaphore of --max-procs shared by the execution
+	// groups of the pipeline and waiting counts the commands waiting for
+	// it, it is nil without a limit
+	procs   chan struct{}
+	waiting int
+	// position is the 1-based index of the execution group and the
+	// number of groups in the pipeline which the status line shows
+	position string
+	// runState is the state of the runner, the pipeline of the crashes
+	// which are published is taken from it
+	runState *RunState
+	// restartLimit is how many times in a row the commands of the
+	// supervised execution group are restarted after crashing, it is
+	// only set with --restart
+	restartLimit int
+	// skipped are the commands whose when patterns do not match the
+	// changes of the current run with the reasons they are skipped
+	skipped map[*Command]string
+}
+
+// parseExecutionGroupFilters splits an --exec value with an optional
+// "[pattern,...]" prefix into its file patterns and its commands
+func parseExecutionGroupFilters(execGroup string) ([]string, string, error) {
+	trimmed := strings.TrimSpace(execGroup)
+	if !strings.HasPrefix(trimmed, "[") {
+		return nil, execGroup, nil
+	}
+	closingIndex := strings.Index(trimmed, "]")
+	if closingIndex < 0 {
+		return nil, "", fmt.Errorf("'%s' has an unclosed file pattern prefix", execGroup)
+	}
+	patterns := strings.FieldsFunc(trimmed[1:closingIndex], func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(patterns) == 0 {
+		return nil, "", fmt.Errorf("'%s' has an empty file pattern prefix", execGroup)
+	}
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, "", fmt.Errorf("'%s' is not a valid file pattern: %s", pattern, err)
+		}
+	}
+	commands := strings.TrimSpace(trimmed[closingIndex+1:])
+	if len(commands) == 0 {
+		return nil, "", fmt.Errorf("'%s' has no commands after its file pattern prefix", execGroup)
+	}
+	return patterns, commands, nil
+}
+
+// ShellCommandPrefix is the prefix of commands which are run in the
+// shell so that they can use pipes, redirections and && chains
+const ShellCommandPrefix = "sh:"
+
+// ExecutionOptionKeys are the keys of the options which can prefix an
+// execution group or command
+var ExecutionOptionKeys = []string{"backoff", "dir", "env", "exit", "image", "match", "name", "output", "retries", "timeout", "when"}
+
+// DefaultExecutionBackoff is the time before the first retry of a failed
+// command which declares retries=... without a backoff=...
+const DefaultExecutionBackoff = time.Second
+
+// DefaultRestartLimit is the number of times in a row a crashing command
+// is restarted with --restart before it is considered to be crash-looping
+const DefaultRestartLimit = 5
+
+// MaxRestartBackoff is the longest time before a crashed command is
+// restarted, the backoff doubles after every crash until it reaches it
+const MaxRestartBackoff = 30 * time.Second
+
+// RestartResetAfter is how long a restarted command has to run before
+// its next crash is counted as the first one again
+const RestartResetAfter = 30 * time.Second
+
+// ExecutionOutputModes are the values of the output=... option, with
+// "grouped" the output of each command is held until it exits while
+// "live" writes it as it comes
+var ExecutionOutputModes = []string{"grouped", "live"}
+
+// ExecutionOptions are the working directory, environment overrides and
+// success criteria declared by an execution group or command with a
+// "dir=...,env=KEY=value,exit=0|2,match=REGEX:" prefix, only execution
+// groups can be given a name=... for --route - commands with an
+// image=... run in a container of the image, commands with
+// output=grouped hold their output until they exit and failed commands
+// are run again up to retries=N times after a backoff=DURATION which
+// doubles on every retry - commands running longer than timeout=DURATION
+// are killed and commands with when=PATTERN|PATTERN only run when a
+// changed file matches one of the patterns
+type ExecutionOptions struct {
+	Directory      string
+	Environment    []string
+	SuccessCodes   []int
+	SuccessPattern *regexp.Regexp
+	Name           string
+	Image          string
+	Output         string
+	Retries        int
+	Backoff        time.Duration
+	Timeout        time.Duration
+	When           []string
+}
+
+// GetDirectory returns the working directory resolved from
+// :baseDirectory, or :baseDirectory if none was declared
+func (options *ExecutionOptions) GetDirectory(baseDirectory string) string {
+	if len(options.Directory) == 0 {
+		return baseDirectory
+	} else if path.IsAbs(options.Directory) {
+		return path.Clean(options.Directory)
+	}
+	return path.Join(baseDirectory, options.Directory)
+}
+
+// GetEnvironment returns :baseEnvironment followed by the declared
+// environment variables so that they take precedence
+func (options *ExecutionOptions) GetEnvironment(baseEnvironment []string) []string {
+	environment := append([]string{}, baseEnvironment...)
+	return append(environment, options.Environment...)
+}
+
+// GetImage returns the declared container image, falling back to that of
+// :parent
+func (options *ExecutionOptions) GetImage(parent *ExecutionOptions) string {
+	if len(options.Image) > 0 {
+		return options.Image
+	}
+	return parent.Image
+}
+
+// IsOutputGrouped checks whether output=grouped was declared, falling back
+// to the output mode of :parent
+func (options *ExecutionOptions) IsOutputGrouped(parent *ExecutionOptions) bool {
+	if len(options.Output) > 0 {
+		return options.Output == "grouped"
+	}
+	return parent.Output == "grouped"
+}
+
+// GetRetryPolicy returns the declared number of retries and the backoff
+// before the first retry, falling back to those of :parent
+func (options *ExecutionOptions) GetRetryPolicy(parent *ExecutionOptions) (int, time.Duration) {
+	retries := options.Retries
+	if retries == 0 {
+		retries = parent.Retries
+	}
+	backoff := options.Backoff
+	if backoff == 0 {
+		backoff = parent.Backoff
+	}
+	if backoff == 0 {
+		backoff = DefaultExecutionBackoff
+	}
+	return retries, backoff
+}
+
+// GetTimeout returns the declared timeout, falling back to that of
+// :parent, 0 when neither declares one
+func (options *ExecutionOptions) GetTimeout(parent *ExecutionOptions) time.Duration {
+	if options.Timeout > 0 {
+		return options.Timeout
+	}
+	return parent.Timeout
+}
+
+// GetWhen returns the declared patterns of the changed files which the
+// command runs for, falling back to those of :parent
+func (options *ExecutionOptions) GetWhen(parent *ExecutionOptions) []string {
+	if len(options.When) > 0 {
+		return options.When
+	}
+	return parent.When
+}
+
+// GetSuccessCriteria returns the declared exit codes and output pattern
+// which make a command successful, falling back to those of :parent
+func (options *ExecutionOptions) GetSuccessCriteria(parent *ExecutionOptions) ([]int, *regexp.Regexp) {
+	successCodes := options.SuccessCodes
+	if len(successCodes) == 0 {
+		successCodes = parent.SuccessCodes
+	}
+	successPattern := options.SuccessPattern
+	if successPattern == nil {
+		successPattern = parent.SuccessPattern
+	}
+	return successCodes, successPattern
+}
+
+// parseExecutionOptions splits an execution group or command with an
+// optional "dir=...,env=KEY=value,exit=0|2,match=REGEX:" prefix into its
+// options and the rest of it - commas and colons in values have to be
+// escaped with a backslash
+func parseExecutionOptions(execString string) (*ExecutionOptions, string, error) {
+	options := &ExecutionOptions{}
+	trimmed := strings.TrimSpace(execString)
+	if !hasExecutionOptions(trimmed) {
+		return options, execString, nil
+	}
+	colonIndex := indexUnescaped(trimmed, ':')
+	if colonIndex < 0 {
+		return nil, "", fmt.Errorf("'%s' has an options prefix without a ':' before its commands", execString)
+	}
+	for _, option := range splitUnescaped(trimmed[:colonIndex], ',') {
+		keyValue := strings.SplitN(strings.TrimSpace(option), "=", 2)
+		if len(keyValue) != 2 || len(keyValue[1]) == 0 {
+			return nil, "", fmt.Errorf("'%s' is not a valid option (expected KEY=value with a key in %v)", option, ExecutionOptionKeys)
+		}
+		value := strings.NewReplacer(`\,`, ",", `\:`, ":").Replace(keyValue[1])
+		switch keyValue[0] {
+		case "backoff":
+			backoff, err := time.ParseDuration(value)
+			if err != nil || backoff <= 0 {
+				return nil, "", fmt.Errorf("'%s' is not a valid backoff (expected a positive duration like backoff=2s)", value)
+			}
+			options.Backoff = backoff
+		case "dir":
+			options.Directory = value
+		case "env":
+			if strings.Index(value, "=") < 1 {
+				return nil, "", fmt.Errorf("'%s' is not a valid environment variable (expected env=KEY=value)", option)
+			}
+			options.Environment = append(options.Environment, value)
+		case "exit":
+			for _, exitCode := range strings.Split(value, "|") {
+				successCode, err := strconv.Atoi(strings.TrimSpace(exitCode))
+				if err != nil {
+					return nil, "", fmt.Errorf("'%s' is not a valid exit code (expected exit=CODE|CODE...)", exitCode)
+				}
+				options.SuccessCodes = append(options.SuccessCodes, successCode)
+			}
+		case "match":
+			successPattern, err := regexp.Compile(value)
+			if err != nil {
+				return nil, "", fmt.Errorf("'%s' is not a valid output pattern: %s", value, err)
+			}
+			options.SuccessPattern = successPattern
+		case "image":
+			if err := validateContainerImage(value); err != nil {
+				return nil, "", err
+			}
+			options.Image = value
+		case "name":
+			options.Name = value
+		case "output":
+			if !sliceContainsString(ExecutionOutputModes, value) {
+				return nil, "", fmt.Errorf("'%s' is not a valid output mode (expected one of %v)", value, ExecutionOutputModes)
+			}
+			options.Output = value
+		case "retries":
+			retries, err := strconv.Atoi(value)
+			if err != nil || retries < 0 {
+				return nil, "", fmt.Errorf("'%s' is not a valid number of retries (expected retries=N with N of at least 0)", value)
+			}
+			options.Retries = retries
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return nil, "", fmt.Errorf("'%s' is not a valid timeout (expected a positive duration like timeout=5m)", value)
+			}
+			options.Timeout = timeout
+		case "when":
+			patterns := strings.Split(value, "|")
+			if err := validatePatterns(patterns); err != nil {
+				return nil, "", fmt.Errorf("'%s' is not a valid when pattern: %s", value, err)
+			}
+			options.When = append(options.When, patterns...)
+		default:
+			return nil, "", fmt.Errorf("'%s' is not a known option (expected one of %v)", keyValue[0], ExecutionOptionKeys)
+		}
+	}
+	commands := strings.TrimSpace(trimmed[colonIndex+1:])
+	if len(commands) == 0 {
+		return nil, "", fmt.Errorf("'%s' has no commands after its options prefix", execString)
+	}
+	return options, commands, nil
+}
+
+// isShellCommand checks whether :command is run through the shell, which
+// it is when :shell is set or it has the sh: prefix
+func isShellCommand(command string, shell bool) bool {
+	return shell || strings.HasPrefix(strings.TrimSpace(command), ShellCommandPrefix)
+}
+
+// splitCommand splits :command into the application and arguments which
+// run it with :arguments appended, it is run through the shell instead of
+// being split when isShellCommand
+func splitCommand(command string, shell bool, arguments []string) ([]string, error) {
+	trimmed := strings.TrimSpace(command)
+	if strings.HasPrefix(trimmed, ShellCommandPrefix) {
+		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, ShellCommandPrefix))
+	}
+	if !isShellCommand(command, shell) {
+		sections, err := shellquote.Split(command)
+		if err != nil || len(sections) == 0 {
+			return sections, err
+		}
+		return append(sections, arguments...), nil
+	} else if len(trimmed) == 0 {
+		return nil, fmt.Errorf("'%s' has no script to run in the shell", command)
+	}
+	if len(arguments) > 0 {
+		trimmed += " " + shellquote.Join(arguments...)
+	}
+	return append(getShellCommand(), trimmed), nil
+}
+
+// hasExecutionOptions checks whether :execString starts with one of the
+// ExecutionOptionKeys
+func hasExecutionOptions(execString string) bool {
+	for _, key := range ExecutionOptionKeys {
+		if strings.HasPrefix(execString, key+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// indexUnescaped returns the index of the first :separator in :value
+// which is not preceded by a backslash, or -1 if there is none
+func indexUnescaped(value string, separator byte) int {
+	for index := 0; index < len(value); index++ {
+		if value[index] == '\\' {
+			index++
+		} else if value[index] == separator {
+			return index
+		}
+	}
+	return -1
+}
+
+// splitUnescaped splits :value at every :separator which is not preceded
+// by a backslash, escapes are left in place
+func splitUnescaped(value string, separator byte) []string {
+	var split []string
+	for {
+		index := indexUnescaped(value, separator)
+		if index < 0 {
+			return append(split, value)
+		}
+		split = append(split, value[:index])
+		value = value[index+1:]
+	}
+}
+
+// splitCommands splits :commands by :delimiter except where the delimiter
+// is inside single or double quotes or is escaped with a backslash - an
+// escaped delimiter is replaced by the delimiter itself, everything else
+// is left as-is to be split into arguments by shell quoting rules. The
+// options prefix of a command is kept whole up to its first unescaped
+// ':' so that the commas between its options are not taken for the
+// delimiter (eg. go vet,retries=2,timeout=5s:go test)
+func splitCommands(commands, delimiter string) []string {
+	if len(delimiter) == 0 {
+		return []string{commands}
+	}
+	var split []string
+	var current strings.Builder
+	var quote byte
+	inOptions := hasExecutionOptions(strings.TrimSpace(commands))
+	for index := 0; index < len(commands); index++ {
+		character := commands[index]
+		switch {
+		case inOptions && character == '\\' && index+1 < len(commands):
+			current.WriteByte(character)
+			current.WriteByte(commands[index+1])
+			index++
+		case inOptions:
+			inOptions = character != ':'
+			current.WriteByte(character)
+		case character == '\\' && strings.HasPrefix(commands[index+1:], delimiter):
+			current.WriteString(delimiter)
+			index += len(delimiter)
+		case character == '\\' && quote != '\'' && index+1 < len(commands):
+			current.WriteByte(character)
+			current.WriteByte(commands[index+1])
+			index++
+		case quote == 0 && (character == '\'' || character == '"'):
+			quote = character
+			current.WriteByte(character)
+		case quote != 0 && character == quote:
+			quote = 0
+			current.WriteByte(character)
+		case quote == 0 && strings.HasPrefix(commands[index:], delimiter):
+			split = append(split, current.String())
+			current.Reset()
+			index += len(delimiter) - 1
+			inOptions = hasExecutionOptions(strings.TrimSpace(commands[index+1:]))
+		default:
+			current.WriteByte(character)
+		}
+	}
+	return append(split, current.String())
+}
+
+// validateExecutionGroup checks the file patterns and options of
+// :execGroup and of each of its commands split by :delimiter
+func validateExecutionGroup(execGroup, delimiter string) error {
+	if len(delimiter) == 0 {
+		delimiter = DefaultCommandsDelimiter
+	}
+	_, commands, err := parseExecutionGroupFilters(execGroup)
+	if err != nil {
+		return err
+	}
+	if _, commands, err = parseExecutionOptions(commands); err != nil {
+		return err
+	}
+	for _, command := range splitCommands(commands, delimiter) {
+		var options *ExecutionOptions
+		if options, command, err = parseExecutionOptions(command); err != nil {
+			return err
+		} else if len(options.Name) > 0 {
+			return fmt.Errorf("'%s' is named but only execution groups can be named", command)
+		}
+		if _, err := splitCommand(command, false, nil); err != nil {
+			return fmt.Errorf("'%s' is not a valid command: %s", command, err)
+		}
+	}
+	return nil
+}
+
+// GetCommandStrings returns a human-readable representation of each
+// command in the execution group
+func (executionGroup *ExecutionGroup) GetCommandStrings() []string {
+	var commandStrings []string
+	for _, command := range executionGroup.commands {
+		commandStrings = append(
+			commandStrings,
+			strings.TrimSpace(command.config.Application+" "+strings.Join(command.config.Arguments, " ")),
+		)
+	}
+	return commandStrings
+}
+
+// GetCooldown returns how long more the execution group has to wait
+// before it can run again, or zero if it can run now
+func (executionGroup *ExecutionGroup) GetCooldown() time.Duration {
+	lastRun := executionGroup.GetLastRun()
+	if executionGroup.minInterval <= 0 || lastRun.IsZero() {
+		return 0
+	}
+	if cooldown := executionGroup.minInterval - time.Since(lastRun); cooldown > 0 {
+		return cooldown
+	}
+	return 0
+}
+
+// GetLastDuration returns how long the last run of the execution group
+// took, zero while it runs for the first time
+func (executionGroup *ExecutionGroup) GetLastDuration() time.Duration {
+	executionGroup.lastRunMutex.Lock()
+	defer executionGroup.lastRunMutex.Unlock()
+	return executionGroup.lastDuration
+}
+
+// GetLastRun returns when the execution group was last run, or the zero
+// time if it has not run yet
+func (executionGroup *ExecutionGroup) GetLastRun() time.Time {
+	executionGroup.lastRunMutex.Lock()
+	defer executionGroup.lastRunMutex.Unlock()
+	return executionGroup.lastRun
+}
+
+// GetChangePatterns returns the file patterns of the execution group
+// followed by the patterns routed to it
+func (executionGroup *ExecutionGroup) GetChangePatterns() []string {
+	return append(append([]string{}, executionGroup.onlyOn...), executionGroup.routes...)
+}
+
+// MatchesChanges checks if the execution group should run for the
+// :changedFiles - patterns containing a slash are matched against the
+// path relative to :baseDirectory while others are matched against the
+// file name, a nil :changedFiles means everything has changed
+func (executionGroup *ExecutionGroup) MatchesChanges(changedFiles []string, baseDirectory string) bool {
+	if (len(executionGroup.onlyOn) == 0 && len(executionGroup.routes) == 0) || changedFiles == nil {
+		return true
+	}
+	for _, changedFile := range changedFiles {
+		relativePath, err := filepath.Rel(baseDirectory, changedFile)
+		if err != nil {
+			relativePath = changedFile
+		}
+		for _, pattern := range executionGroup.onlyOn {
+			target := filepath.Base(changedFile)
+			if strings.Contains(pattern, "/") {
+				target = filepath.ToSlash(relativePath)
+			}
+			if matched, _ := filepath.Match(pattern, target); matched {
+				return true
+			}
+		}
+	}
+	return matchAnyChangedFile(executionGroup.routes, changedFiles, baseDirectory)
+}
+
+// matchAnyChangedFile checks if any of the :changedFiles matches any of
+// the :patterns, which are matched like those of --include against the
+// paths relative to :baseDirectory
+func matchAnyChangedFile(patterns []string, changedFiles []string, baseDirectory string) bool {
+	for _, changedFile := range changedFiles {
+		relativePath, err := filepath.Rel(baseDirectory, changedFile)
+		if err != nil {
+			relativePath = changedFile
+		}
+		if matchAnyPattern(patterns, relativePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignRoutes gives the execution groups in :pipeline the patterns
+// which :routes route to their names
+func assignRoutes(pipeline []*ExecutionGroup, routes map[string][]string) error {
+	namedGroups, err := getNamedExecutionGroups(pipeline)
+	if err != nil {
+		return err
+	}
+	var patterns []string
+	for pattern := range routes {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		for _, name := range routes[pattern] {
+			executionGroup, exists := namedGroups[name]
+			if !exists {
+				return fmt.Errorf("'%s' is routed to '%s' but no execution group has a name=%s: prefix", pattern, name, name)
+			}
+			executionGroup.routes = append(executionGroup.routes, pattern)
+		}
+	}
+	return nil
+}
+
+// assignSkipScripts gives the execution groups in :pipeline the scripts
+// of :skipScripts keyed by their names
+func assignSkipScripts(pipeline []*ExecutionGroup, skipScripts map[string]*Script) error {
+	namedGroups, err := getNamedExecutionGroups(pipeline)
+	if err != nil {
+		return err
+	}
+	for name, script := range skipScripts {
+		executionGroup, exists := namedGroups[name]
+		if !exists {
+			return fmt.Errorf("a skip script is given to '%s' but no execution group has a name=%s: prefix", name, name)
+		}
+		executionGroup.skipScript = script
+	}
+	return nil
+}
+
+// assignDependencies turns :pipeline into a graph where the named
+// execution groups in :dependsOn run after the groups that they depend on
+// and the others run after the group before them - nothing is assigned
+// when :dependsOn is empty so that the pipeline runs in sequence
+func assignDependencies(pipeline []*ExecutionGroup, dependsOn map[string][]string) error {
+	if len(dependsOn) == 0 {
+		return nil
+	}
+	namedGroups, err := getNamedExecutionGroups(pipeline)
+	if err != nil {
+		return err
+	}
+	indices := map[*ExecutionGroup]int{}
+	for index, executionGroup := range pipeline {
+		indices[executionGroup] = index
+		executionGroup.dependencies = []int{}
+		if index > 0 {
+			executionGroup.dependencies = []int{index - 1}
+		}
+	}
+	for name, dependencyNames := range dependsOn {
+		executionGroup, exists := namedGroups[name]
+		if !exists {
+			return fmt.Errorf("'%s' has dependencies but no execution group has a name=%s: prefix", name, name)
+		}
+		executionGroup.dependencies = []int{}
+		for _, dependencyName := range dependencyNames {
+			dependency, exists := namedGroups[dependencyName]
+			if !exists {
+				return fmt.Errorf("'%s' depends on '%s' but no execution group has a name=%s: prefix", name, dependencyName, dependencyName)
+			} else if dependency.supervised {
+				return fmt.Errorf("'%s' depends on '%s' which runs the application and does not finish", name, dependencyName)
+			}
+			executionGroup.dependencies = append(executionGroup.dependencies, indices[dependency])
+		}
+	}
+	return validateDependencies(pipeline)
+}
+
+// isDependencyGraph checks whether dependencies were assigned to the
+// execution groups of :pipeline
+func isDependencyGraph(pipeline []*ExecutionGroup) bool {
+	for _, executionGroup := range pipeline {
+		if executionGroup.dependencies != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDependencies checks that the dependencies of the execution
+// groups in :pipeline do not form a cycle
+func validateDependencies(pipeline []*ExecutionGroup) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(pipeline))
+	var visit func(index int) error
+	visit = func(index int) error {
+		switch states[index] {
+		case visiting:
+			return fmt.Errorf("execution group %s is part of a dependency cycle", pipeline[index].GetLabel(index))
+		case visited:
+			return nil
+		}
+		states[index] = visiting
+		for _, dependency := range pipeline[index].dependencies {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		states[index] = visited
+		return nil
+	}
+	for index := range pipeline {
+		if err := visit(index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLabel returns the name of the execution group or its 1-based
+// position in the pipeline from its 0-based :index if it has no name
+func (executionGroup *ExecutionGroup) GetLabel(index int) string {
+	if len(executionGroup.name) > 0 {
+		return fmt.Sprintf("'%s'", executionGroup.name)
+	}
+	return fmt.Sprintf("%v", index+1)
+}
+
+// findExecutionGroups returns the 1-based indices of the execution groups
+// in :pipeline which :selectors name or give the 1-based index of
+func findExecutionGroups(pipeline []*ExecutionGroup, selectors []string) ([]int, error) {
+	namedGroups, err := getNamedExecutionGroups(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var indices []int
+	for _, selector := range selectors {
+		if index, err := strconv.Atoi(selector); err == nil {
+			if index < 1 || index > len(pipeline) {
+				return nil, fmt.Errorf("execution group %v does not exist (there are %v execution groups)", index, len(pipeline))
+			}
+			indices = append(indices, index)
+		} else if executionGroup, exists := namedGroups[selector]; exists {
+			for index, candidate := range pipeline {
+				if candidate == executionGroup {
+					indices = append(indices, index+1)
+				}
+			}
+		} else {
+			return nil, fmt.Errorf("no execution group has a name=%s: prefix or the index '%s'", selector, selector)
+		}
+	}
+	return indices, nil
+}
+
+func getNamedExecutionGroups(pipeline []*ExecutionGroup) (map[string]*ExecutionGroup, error) {
+	namedGroups := map[string]*ExecutionGroup{}
+	for index, executionGroup := range pipeline {
+		if len(executionGroup.name) == 0 {
+			continue
+		} else if _, exists := namedGroups[executionGroup.name]; exists {
+			return nil, fmt.Errorf("execution group %v is named '%s' like an earlier one", index+1, executionGroup.name)
+		}
+		namedGroups[executionGroup.name] = executionGroup
+	}
+	return namedGroups, nil
+}
+
+// IsSkippedByScript evaluates the skip script of the execution group for
+// the :changedFiles of :pipeline - it is never skipped when the pipeline
+// was not triggered by changes
+func (executionGroup *ExecutionGroup) IsSkippedByScript(changedFiles []string, watchDirectory string, pipeline int) (bool, error) {
+	if executionGroup.skipScript == nil || changedFiles == nil {
+		return false, nil
+	}
+	variables := getScriptVariables(changedFiles, watchDirectory, pipeline)
+	variables["group"] = executionGroup.name
+	return executionGroup.skipScript.EvalBool(variables)
+}
+
+// GetLastErrors returns the errors of commands from the last run
+func (executionGroup *ExecutionGroup) GetLastErrors() []string {
+	executionGroup.errorsMutex.Lock()
+	defer executionGroup.errorsMutex.Unlock()
+	return append([]string{}, executionGroup.lastErrors...)
+}
+
+// GetExitCode returns the exit code of the first command which failed in
+// the last run, 0 when none of them failed
+func (executionGroup *ExecutionGroup) GetExitCode() int {
+	executionGroup.errorsMutex.Lock()
+	defer executionGroup.errorsMutex.Unlock()
+	return executionGroup.lastExitCode
+}
+
+// getExitCode returns the exit code of the process which exited with
+// :err, it is 1 for failures without one such as being killed by a
+// signal or not meeting the success criteria
+func getExitCode(err error) int {
+	if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() > 0 {
+		return exitError.ExitCode()
+	}
+	return 1
+}
+
+// HasSuccessCriteria checks whether any command in the execution group
+// declares exit codes or an output pattern which make it successful
+func (executionGroup *ExecutionGroup) HasSuccessCriteria() bool {
+	for _, command := range executionGroup.commands {
+		if len(command.config.SuccessCodes) > 0 || command.config.SuccessPattern != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReady is for the Runner to check if all commands in the
+// execution group which have a readiness pattern have matched it
+func (executionGroup *ExecutionGroup) IsReady() bool {
+	for _, command := range executionGroup.commands {
+		if command.config.ReadyPattern != nil && !command.IsReady() {
+			return false
+		}
+	}
+	return true
 }
 
 // IsRunning is for the Runner to check if the execution group
 // is still running
 func (executionGroup *ExecutionGroup) IsRunning() bool {
+	executionGroup.retryMutex.Lock()
+	retrying := executionGroup.retrying + executionGroup.waiting
+	executionGroup.retryMutex.Unlock()
+	if retrying > 0 {
+		return true
+	}
 	for _, command := range executionGroup.commands {
 		if command.IsRunning() {
 			return true
@@ -27,40 +817,135 @@ func (executionGroup *ExecutionGroup) IsRunning() bool {
 	return false
 }
 
+// SetChangedFiles passes the :changedFiles of the pipeline on to the
+// commands for their next run, which skips the commands whose when
+// patterns do not match them relative to :baseDirectory
+func (executionGroup *ExecutionGroup) SetChangedFiles(changedFiles []string, baseDirectory string) {
+	executionGroup.skipped = map[*Command]string{}
+	for _, command := range executionGroup.commands {
+		command.SetChangedFiles(changedFiles)
+		if !command.MatchesChanges(baseDirectory) {
+			executionGroup.skipped[command] = command.getSkipReason()
+		}
+	}
+}
+
+// SetRunDirectory passes the temporary directory of the pipeline run on
+// to the commands for their next run
+func (executionGroup *ExecutionGroup) SetRunDirectory(runDirectory string) {
+	for _, command := range executionGroup.commands {
+		command.SetRunDirectory(runDirectory)
+	}
+}
+
 // Run starts the execution group's commands in parallel
-// and waits for all of them to exit
-func (executionGroup *ExecutionGroup) Run() {
-	ExecutionGroupCount++
-	defer executionGroup.logger.Debugf("execution group[%v] exited", ExecutionGroupCount)
-	executionGroup.logger.Debugf("execution group[%v] is starting...", ExecutionGroupCount)
+// and waits for all of them to exit, they are stopped when :ctx is done
+func (executionGroup *ExecutionGroup) Run(ctx context.Context) {
+	count := executionGroup.runState.countExecutionGroup()
+	startedAt := time.Now()
+	executionGroup.lastRunMutex.Lock()
+	executionGroup.lastRun = startedAt
+	executionGroup.lastRunMutex.Unlock()
+	executionGroup.setTerminating(false)
+	executionGroup.errorsMutex.Lock()
+	executionGroup.lastErrors = nil
+	executionGroup.lastExitCode = 0
+	executionGroup.errorsMutex.Unlock()
+	defer func() {
+		executionGroup.lastRunMutex.Lock()
+		executionGroup.lastDuration = time.Since(startedAt)
+		executionGroup.lastRunMutex.Unlock()
+	}()
+	defer executionGroup.logger.Debugf("execution group[%v] exited", count)
+	executionGroup.logger.Debugf("execution group[%v] is starting...", count)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	executionGroup.retryMutex.Lock()
+	executionGroup.cancel = cancel
+	executionGroup.retryMutex.Unlock()
 	for _, command := range executionGroup.commands {
 		if err := command.IsValid(); err != nil {
 			executionGroup.logger.Error(err)
+			executionGroup.errorsMutex.Lock()
+			executionGroup.lastErrors = append(executionGroup.lastErrors, fmt.Sprintf("%s: %s", command.GetID(), err))
+			if executionGroup.lastExitCode == 0 {
+				executionGroup.lastExitCode = 1
+			}
+			executionGroup.errorsMutex.Unlock()
+		} else if reason, skipped := executionGroup.skipped[command]; skipped {
+			executionGroup.logger.Infof("command[%s] %s - skipping", command.GetID(), reason)
 		} else {
-			go func(commandStatus *chan error) {
-				for {
-					select {
-					case err := <-*commandStatus: // Command letting us know its done
+			executionGroup.logger.Tracef("command[%s] is starting", command.GetID())
+			executionGroup.waitGroup.Add(1)
+			go func(command *Command) {
+				if !executionGroup.startCommand(ctx, command) {
+					executionGroup.handleCommandStatus(command, ctx.Err())
+					return
+				}
+				startedAt := time.Now()
+				status := *command.GetStatus()
+				for attempt, crashes := 0, 0; ; {
+					// the command reports on its status when :ctx is done too, so
+					// that it is stopped before the group exits
+					err := <-status // Command letting us know its done
+					executionGroup.releaseProcess(command)
+					restart := false
+					if err != nil && executionGroup.waitToRetry(ctx, command, attempt, err) {
+						attempt++
+						restart = true
+					} else if time.Since(startedAt) >= RestartResetAfter {
+						crashes = 0
+					}
+					if !restart && err != nil && executionGroup.waitToRestart(ctx, command, crashes, err) {
+						crashes++
+						restart = true
+					}
+					if !restart {
 						executionGroup.handleCommandStatus(command, err)
 						return
-					default:
+					} else if !executionGroup.startCommand(ctx, command) {
+						executionGroup.handleCommandStatus(command, ctx.Err())
+						return
 					}
+					startedAt = time.Now()
 				}
-			}(command.GetStatus())
-			executionGroup.logger.Tracef("command[%s] is starting", command.GetID())
-			executionGroup.waitGroup.Add(1)
-			go command.Run()
+			}(command)
 		}
 	}
 	executionGroup.logger.Tracef("waiting for commands to complete running...")
 	executionGroup.waitGroup.Wait()
 }
 
+// isTerminating checks whether the execution group is being terminated
+func (executionGroup *ExecutionGroup) isTerminating() bool {
+	executionGroup.terminatingMutex.Lock()
+	defer executionGroup.terminatingMutex.Unlock()
+	return executionGroup.terminating
+}
+
+// setTerminating records whether the execution group is being terminated
+func (executionGroup *ExecutionGroup) setTerminating(terminating bool) {
+	executionGroup.terminatingMutex.Lock()
+	defer executionGroup.terminatingMutex.Unlock()
+	executionGroup.terminating = terminating
+}
+
 // Terminate terminates this instance of the execution group, used when
 // the Runner receives a signal to start a new pipeline
 func (executionGroup *ExecutionGroup) Terminate() {
+	executionGroup.TerminateWithSignal(nil)
+}
+
+// TerminateWithSignal terminates this instance of the execution group by
+// sending :signal to its commands, or their stop signal when it is nil
+func (executionGroup *ExecutionGroup) TerminateWithSignal(signal os.Signal) {
+	executionGroup.setTerminating(true)
+	defer executionGroup.cancelRun()
 	for _, command := range executionGroup.commands {
-		if command.IsRunning() {
+		if command.IsRunning() && signal != nil {
+			executionGroup.logger.Tracef("sending %v to command %v", signal, command.GetID())
+			command.SendStopSignal(signal)
+		} else if command.IsRunning() {
 			executionGroup.logger.Tracef("sending SIGINT to command %v", command.GetID())
 			command.SendInterrupt()
 			executionGroup.logger.Tracef("SIGINT sent to command %v", command.GetID())
@@ -68,16 +953,192 @@ func (executionGroup *ExecutionGroup) Terminate() {
 	}
 }
 
+// startCommand starts :command once it can take a process slot of
+// --max-procs and returns whether it did, it does not when :ctx is done
+// before then - the commands of the application do not take a slot
+func (executionGroup *ExecutionGroup) startCommand(ctx context.Context, command *Command) bool {
+	if executionGroup.procs != nil && !executionGroup.supervised {
+		select {
+		case executionGroup.procs <- struct{}{}:
+		default:
+			executionGroup.logger.Debugf("command[%s] is waiting for one of the %v running commands to exit", command.GetID(), cap(executionGroup.procs))
+			executionGroup.retryMutex.Lock()
+			executionGroup.waiting++
+			executionGroup.retryMutex.Unlock()
+			defer func() {
+				executionGroup.retryMutex.Lock()
+				executionGroup.waiting--
+				executionGroup.retryMutex.Unlock()
+			}()
+			select {
+			case executionGroup.procs <- struct{}{}:
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+	if !executionGroup.supervised {
+		executionGroup.runState.get().StatusLine.Begin(command, fmt.Sprintf(
+			"group %s: %s",
+			executionGroup.position,
+			strings.TrimSpace(command.config.Application+" "+strings.Join(command.config.Arguments, " ")),
+		))
+	}
+	go command.Run(ctx)
+	return true
+}
+
+// releaseProcess gives back the process slot of an exited command and
+// removes it from the status line
+func (executionGroup *ExecutionGroup) releaseProcess(command *Command) {
+	executionGroup.runState.get().StatusLine.End(command)
+	if executionGroup.procs != nil && !executionGroup.supervised {
+		<-executionGroup.procs
+	}
+}
+
+// cancelRun cancels the context of the current run, which stops the
+// commands that were not sent a signal and cancels pending retries
+func (executionGroup *ExecutionGroup) cancelRun() {
+	executionGroup.retryMutex.Lock()
+	defer executionGroup.retryMutex.Unlock()
+	if executionGroup.cancel != nil {
+		executionGroup.cancel()
+		executionGroup.cancel = nil
+	}
+}
+
+// WaitUntilStopped waits for the commands of the terminated execution
+// group to exit and returns whether they did - commands are killed when
+// they do not exit within their kill timeout, so they are given twice
+// the longest of those of the running commands
+func (executionGroup *ExecutionGroup) WaitUntilStopped() bool {
+	longestKillTimeout := time.Duration(0)
+	for _, command := range executionGroup.commands {
+		if !command.IsRunning() {
+			continue
+		}
+		killTimeout := command.config.KillTimeout
+		if killTimeout <= 0 {
+			killTimeout = DefaultKillTimeout
+		}
+		if killTimeout > longestKillTimeout {
+			longestKillTimeout = killTimeout
+		}
+	}
+	deadline := time.Now().Add(2 * longestKillTimeout)
+	for executionGroup.IsRunning() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// waitToRetry waits out the backoff of :command after its failed
+// :attempt, starting from 0, and returns whether it should be run again -
+// it is not when it has no retries left, the execution group is
+// terminated or :ctx is done
+func (executionGroup *ExecutionGroup) waitToRetry(ctx context.Context, command *Command, attempt int, err error) bool {
+	if attempt >= command.config.Retries || executionGroup.isTerminating() || ctx.Err() != nil {
+		return false
+	}
+	backoff := command.config.Backoff << uint(attempt)
+	executionGroup.logger.Warnf("command[%s] exited with: %s - retrying in %v (%v of %v)", command.GetID(), err, backoff, attempt+1, command.config.Retries)
+	return executionGroup.waitForBackoff(ctx, backoff)
+}
+
+// waitToRestart waits out the backoff of :command of the supervised
+// execution group after it crashed for the :crashes+1th time in a row
+// and returns whether it should be restarted - it is not without
+// --restart, once it crashed more than the restart limit or when the
+// execution group is terminated or :ctx is done
+func (executionGroup *ExecutionGroup) waitToRestart(ctx context.Context, command *Command, crashes int, err error) bool {
+	if !executionGroup.supervised || executionGroup.restartLimit == 0 || executionGroup.isTerminating() || ctx.Err() != nil {
+		return false
+	} else if crashes >= executionGroup.restartLimit {
+		executionGroup.logger.Errorf("command[%s] crashed %v times in a row - not restarting it until the next run", command.GetID(), crashes+1)
+		return false
+	}
+	backoff := getRestartBackoff(command.config.Backoff, crashes)
+	executionGroup.logger.Warnf("command[%s] crashed with: %s - restarting in %v (%v of %v)", command.GetID(), err, backoff, crashes+1, executionGroup.restartLimit)
+	executionGroup.publishCrash(command, err)
+	return executionGroup.waitForBackoff(ctx, backoff)
+}
+
+// getRestartBackoff returns the time before a command is restarted after
+// it crashed :crashes times in a row before, it starts at :backoff, or
+// DefaultExecutionBackoff if it is not set, and doubles up to
+// MaxRestartBackoff
+func getRestartBackoff(backoff time.Duration, crashes int) time.Duration {
+	if backoff <= 0 {
+		backoff = DefaultExecutionBackoff
+	}
+	for ; crashes > 0 && backoff < MaxRestartBackoff; crashes-- {
+		backoff *= 2
+	}
+	if backoff > MaxRestartBackoff {
+		return MaxRestartBackoff
+	}
+	return backoff
+}
+
+// waitForBackoff waits for :backoff while the execution group counts as
+// running and returns whether it was not terminated in the meantime
+func (executionGroup *ExecutionGroup) waitForBackoff(ctx context.Context, backoff time.Duration) bool {
+	executionGroup.retryMutex.Lock()
+	executionGroup.retrying++
+	executionGroup.retryMutex.Unlock()
+	defer func() {
+		executionGroup.retryMutex.Lock()
+		executionGroup.retrying--
+		executionGroup.retryMutex.Unlock()
+	}()
+	select {
+	case <-time.After(backoff):
+		return !executionGroup.isTerminating()
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// handleCommandStatus records how :command exited with :err, commands
+// which exited because the execution group was terminated do not count
+// as failed since they were stopped for a new pipeline or because godev
+// is stopping
 func (executionGroup *ExecutionGroup) handleCommandStatus(command *Command, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			executionGroup.logger.Warn(r)
 		}
 	}()
-	if err != nil {
+	if err != nil && executionGroup.isTerminating() {
+		executionGroup.logger.Debugf("command[%s] was stopped with: %s", command.GetID(), err)
+	} else if err != nil {
+		executionGroup.errorsMutex.Lock()
+		executionGroup.lastErrors = append(executionGroup.lastErrors, fmt.Sprintf("%s: %s", command.GetID(), err))
+		if executionGroup.lastExitCode == 0 {
+			executionGroup.lastExitCode = getExitCode(err)
+		}
+		executionGroup.errorsMutex.Unlock()
 		executionGroup.logger.Warnf("command[%s] exited with: %s", command.GetID(), err)
+		if executionGroup.supervised {
+			executionGroup.publishCrash(command, err)
+		}
 	} else {
 		executionGroup.logger.Debugf("command[%s] exited without error", command.GetID())
 	}
 	executionGroup.waitGroup.Done()
 }
+
+// publishCrash publishes the crash of :command with :err as
+// EventProcessCrashed
+func (executionGroup *ExecutionGroup) publishCrash(command *Command, err error) {
+	executionGroup.events.Publish(&Event{
+		Name:     EventProcessCrashed,
+		Pipeline: executionGroup.runState.GetPipeline(),
+		Command:  strings.TrimSpace(command.config.Application + " " + strings.Join(command.config.Arguments, " ")),
+		Error:    err.Error(),
+	})
+}
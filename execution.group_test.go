@@ -1,10 +1,15 @@
-package main
+package godev
 
 import (
-	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
 	"regexp"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -13,7 +18,7 @@ import (
 type ExecutionGroupTestSuite struct {
 	suite.Suite
 	executionGroup *ExecutionGroup
-	logs           bytes.Buffer
+	logs           syncBuffer
 	logger         *Logger
 }
 
@@ -33,6 +38,25 @@ func (s *ExecutionGroupTestSuite) SetupTest() {
 	}
 }
 
+func (s *ExecutionGroupTestSuite) TestGetCommandStrings() {
+	s.executionGroup.commands = []*Command{
+		mockCommand("echo", []string{"1", "2"}, &s.logs),
+		mockCommand("true", []string{}, &s.logs),
+	}
+	assert.Equal(s.T(), []string{"echo 1 2", "true"}, s.executionGroup.GetCommandStrings())
+}
+
+func (s *ExecutionGroupTestSuite) TestGetCooldown() {
+	t := s.T()
+	assert.Equal(t, time.Duration(0), s.executionGroup.GetCooldown())
+	s.executionGroup.lastRun = time.Now()
+	assert.Equal(t, time.Duration(0), s.executionGroup.GetCooldown())
+	s.executionGroup.minInterval = time.Minute
+	assert.True(t, s.executionGroup.GetCooldown() > 59*time.Second)
+	s.executionGroup.lastRun = time.Now().Add(-2 * time.Minute)
+	assert.Equal(t, time.Duration(0), s.executionGroup.GetCooldown())
+}
+
 func (s *ExecutionGroupTestSuite) TestIsRunning() {
 	t := s.T()
 	s.executionGroup.commands = []*Command{
@@ -46,6 +70,126 @@ func (s *ExecutionGroupTestSuite) TestIsRunning() {
 	assert.False(t, s.executionGroup.IsRunning())
 }
 
+func (s *ExecutionGroupTestSuite) TestWaitUntilStopped() {
+	t := s.T()
+	s.executionGroup.commands = []*Command{
+		mockCommand("echo", []string{"1"}, &s.logs),
+		mockCommand("echo", []string{"2"}, &s.logs),
+	}
+	assert.True(t, s.executionGroup.WaitUntilStopped())
+	s.executionGroup.commands[0].started = true
+	s.executionGroup.commands[0].config.KillTimeout = 10 * time.Millisecond
+	startedAt := time.Now()
+	assert.False(t, s.executionGroup.WaitUntilStopped())
+	assert.True(t, time.Since(startedAt) < DefaultKillTimeout, "expected only the kill timeouts of running commands to count")
+	command := s.executionGroup.commands[0]
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		command.stateMutex.Lock()
+		command.stopped = true
+		command.stateMutex.Unlock()
+	}()
+	assert.True(t, s.executionGroup.WaitUntilStopped())
+}
+
+func (s *ExecutionGroupTestSuite) TestIsReady() {
+	t := s.T()
+	s.executionGroup.commands = []*Command{
+		mockCommand("echo", []string{"1"}, &s.logs),
+		mockCommand("echo", []string{"2"}, &s.logs),
+	}
+	assert.True(t, s.executionGroup.IsReady())
+	s.executionGroup.commands[1].config.ReadyPattern = regexp.MustCompile("ready")
+	assert.False(t, s.executionGroup.IsReady())
+	s.executionGroup.commands[1].handleReady()
+	assert.True(t, s.executionGroup.IsReady())
+}
+
+func (s *ExecutionGroupTestSuite) TestMatchesChanges() {
+	t := s.T()
+	assert.True(t, s.executionGroup.MatchesChanges([]string{"/project/main.go"}, "/project"))
+	s.executionGroup.onlyOn = []string{"*.proto", "api/*.yaml"}
+	assert.True(t, s.executionGroup.MatchesChanges(nil, "/project"))
+	assert.False(t, s.executionGroup.MatchesChanges([]string{"/project/main.go"}, "/project"))
+	assert.True(t, s.executionGroup.MatchesChanges([]string{"/project/main.go", "/project/proto/user.proto"}, "/project"))
+	assert.True(t, s.executionGroup.MatchesChanges([]string{"/project/api/openapi.yaml"}, "/project"))
+	assert.False(t, s.executionGroup.MatchesChanges([]string{"/project/config/app.yaml"}, "/project"))
+	s.executionGroup.routes = []string{"web/**"}
+	assert.True(t, s.executionGroup.MatchesChanges([]string{"/project/web/src/app.ts"}, "/project"))
+	assert.True(t, s.executionGroup.MatchesChanges([]string{"/project/proto/user.proto"}, "/project"))
+	s.executionGroup.onlyOn = nil
+	assert.False(t, s.executionGroup.MatchesChanges([]string{"/project/main.go"}, "/project"))
+	assert.Equal(t, []string{"web/**"}, s.executionGroup.GetChangePatterns())
+}
+
+func (s *ExecutionGroupTestSuite) Test_assignRoutes() {
+	t := s.T()
+	assets := &ExecutionGroup{name: "assets"}
+	build := &ExecutionGroup{name: "build"}
+	pipeline := []*ExecutionGroup{assets, build, &ExecutionGroup{}}
+	assert.Nil(t, assignRoutes(pipeline, map[string][]string{
+		"web/**":  []string{"assets"},
+		"**/*.go": []string{"build"},
+		"go.mod":  []string{"build"},
+	}))
+	assert.Equal(t, []string{"web/**"}, assets.routes)
+	assert.Equal(t, []string{"**/*.go", "go.mod"}, build.routes)
+	assert.Empty(t, pipeline[2].routes)
+	assert.NotNil(t, assignRoutes(pipeline, map[string][]string{"*.sql": []string{"migrate"}}), "expected unknown groups to be rejected")
+	assert.NotNil(t, assignRoutes(append(pipeline, &ExecutionGroup{name: "build"}), nil), "expected duplicate names to be rejected")
+}
+
+func (s *ExecutionGroupTestSuite) Test_assignSkipScripts() {
+	t := s.T()
+	test := &ExecutionGroup{name: "test"}
+	script, err := CompileScript(`all(files, "docs/**")`)
+	assert.Nil(t, err)
+	assert.Nil(t, assignSkipScripts([]*ExecutionGroup{test, &ExecutionGroup{}}, map[string]*Script{"test": script}))
+	assert.Equal(t, script, test.skipScript)
+	assert.NotNil(t, assignSkipScripts([]*ExecutionGroup{test}, map[string]*Script{"lint": script}), "expected unknown groups to be rejected")
+}
+
+func (s *ExecutionGroupTestSuite) Test_assignDependencies() {
+	t := s.T()
+	build := &ExecutionGroup{name: "build"}
+	lint := &ExecutionGroup{name: "lint"}
+	test := &ExecutionGroup{name: "test"}
+	app := &ExecutionGroup{supervised: true}
+	pipeline := []*ExecutionGroup{build, lint, test, app}
+	assert.Nil(t, assignDependencies(pipeline, nil))
+	assert.False(t, isDependencyGraph(pipeline), "expected pipelines without dependencies to run in sequence")
+	assert.Nil(t, assignDependencies(pipeline, map[string][]string{"lint": []string{}, "test": []string{"build"}}))
+	assert.True(t, isDependencyGraph(pipeline))
+	assert.Equal(t, []int{}, build.dependencies)
+	assert.Equal(t, []int{}, lint.dependencies)
+	assert.Equal(t, []int{0}, test.dependencies)
+	assert.Equal(t, []int{2}, app.dependencies, "expected undeclared execution groups to depend on the one before them")
+	assert.NotNil(t, assignDependencies(pipeline, map[string][]string{"migrate": []string{"build"}}), "expected unknown execution groups to be rejected")
+	assert.NotNil(t, assignDependencies(pipeline, map[string][]string{"test": []string{"migrate"}}), "expected unknown dependencies to be rejected")
+	assert.NotNil(t, assignDependencies(pipeline, map[string][]string{"build": []string{"test"}}), "expected cycles to be rejected")
+	app.name = "app"
+	assert.NotNil(t, assignDependencies(pipeline, map[string][]string{"test": []string{"app"}}), "expected dependencies on the application to be rejected")
+}
+
+func (s *ExecutionGroupTestSuite) TestIsSkippedByScript() {
+	t := s.T()
+	script, err := CompileScript(`group == "test" and all(files, "docs/**")`)
+	assert.Nil(t, err)
+	executionGroup := &ExecutionGroup{name: "test", skipScript: script}
+	skipped, err := executionGroup.IsSkippedByScript([]string{"/project/docs/index.md"}, "/project", 1)
+	assert.Nil(t, err)
+	assert.True(t, skipped)
+	skipped, err = executionGroup.IsSkippedByScript([]string{"/project/main.go"}, "/project", 1)
+	assert.Nil(t, err)
+	assert.False(t, skipped)
+	skipped, err = executionGroup.IsSkippedByScript(nil, "/project", 1)
+	assert.Nil(t, err)
+	assert.False(t, skipped, "expected pipelines not triggered by changes to run the execution group")
+	executionGroup.skipScript, _ = CompileScript(`len(files)`)
+	_, err = executionGroup.IsSkippedByScript([]string{"/project/main.go"}, "/project", 1)
+	assert.NotNil(t, err)
+}
+
 func (s *ExecutionGroupTestSuite) TestRun() {
 	s.executionGroup.commands = []*Command{
 		mockCommand("echo", []string{"1"}, &s.logs),
@@ -54,7 +198,7 @@ func (s *ExecutionGroupTestSuite) TestRun() {
 	}
 	s.executionGroup.logger.SetOutput(&s.logs)
 	t := s.T()
-	s.executionGroup.Run()
+	s.executionGroup.Run(context.Background())
 	assert.Contains(t, s.logs.String(), "command[echo[1]] is starting")
 	assert.Contains(t, s.logs.String(), "command[echo[2]] is starting")
 	assert.Contains(t, s.logs.String(), "command[echo[3]] is starting")
@@ -62,6 +206,177 @@ func (s *ExecutionGroupTestSuite) TestRun() {
 	assert.Regexp(t, regexp.MustCompile(`execution group\[\d\] exited`), s.logs.String())
 }
 
+func (s *ExecutionGroupTestSuite) TestRun_skipsCommandsWithoutMatchingChanges() {
+	t := s.T()
+	defer s.logs.Reset()
+	protoc := mockCommand("echo", []string{"protoc"}, &s.logs)
+	protoc.config.When = []string{"**/*.proto"}
+	s.executionGroup.commands = []*Command{protoc, mockCommand("echo", []string{"build"}, &s.logs)}
+	s.executionGroup.SetChangedFiles([]string{"/work/cmd/main.go"}, "/work")
+	s.executionGroup.Run(context.Background())
+	assert.Contains(t, s.logs.String(), "command[echo[protoc]] has no changes matching [**/*.proto] - skipping")
+	assert.NotContains(t, s.logs.String(), "command[echo[protoc]] is starting")
+	assert.Contains(t, s.logs.String(), "command[echo[build]] is starting")
+	s.logs.Reset()
+	s.executionGroup.SetChangedFiles([]string{"/work/api/v1/service.proto"}, "/work")
+	s.executionGroup.Run(context.Background())
+	assert.Contains(t, s.logs.String(), "command[echo[protoc]] is starting")
+	s.logs.Reset()
+	s.executionGroup.SetChangedFiles(nil, "/work")
+	s.executionGroup.Run(context.Background())
+	assert.Contains(t, s.logs.String(), "command[echo[protoc]] is starting", "expected runs without changes to run every command")
+}
+
+func (s *ExecutionGroupTestSuite) TestRun_stopsCommandsWhenTheContextIsDone() {
+	t := s.T()
+	defer s.logs.Reset()
+	failing := mockCommand("sleep", []string{"10"}, &s.logs)
+	failing.config.Retries = 1
+	failing.config.Backoff = time.Hour
+	s.executionGroup.commands = []*Command{failing}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	startedAt := time.Now()
+	s.executionGroup.Run(ctx)
+	assert.True(t, time.Since(startedAt) < DefaultKillTimeout, "expected commands to be stopped when the context is done")
+	assert.NotContains(t, s.logs.String(), "retrying in", "expected commands not to be retried once the context is done")
+	assert.Len(t, s.executionGroup.GetLastErrors(), 1)
+}
+
+func (s *ExecutionGroupTestSuite) TestRun_limitsRunningCommands() {
+	t := s.T()
+	defer s.logs.Reset()
+	s.executionGroup.commands = []*Command{
+		mockCommand("sleep", []string{"0.2"}, &s.logs),
+		mockCommand("sleep", []string{"0.21"}, &s.logs),
+		mockCommand("sleep", []string{"0.22"}, &s.logs),
+	}
+	s.executionGroup.procs = make(chan struct{}, 1)
+	startedAt := time.Now()
+	s.executionGroup.Run(context.Background())
+	assert.True(t, time.Since(startedAt) >= 600*time.Millisecond, "expected the commands to run one after another")
+	assert.Contains(t, s.logs.String(), "is waiting for one of the 1 running commands to exit")
+	assert.Len(t, s.executionGroup.procs, 0, "expected exited commands to give back their slots")
+	assert.Empty(t, s.executionGroup.runState.get().StatusLine.steps, "expected exited commands to be removed from the status line")
+	assert.Empty(t, s.executionGroup.GetLastErrors())
+
+	s.logs.Reset()
+	s.executionGroup.supervised = true
+	s.executionGroup.procs <- struct{}{}
+	s.executionGroup.Run(context.Background())
+	assert.NotContains(t, s.logs.String(), "is waiting for", "expected the application not to be limited")
+}
+
+func (s *ExecutionGroupTestSuite) TestRun_retriesFailedCommands() {
+	t := s.T()
+	failing := mockCommand("sh", []string{"-c", "exit 3"}, &s.logs)
+	failing.config.Retries = 2
+	failing.config.Backoff = time.Millisecond
+	s.executionGroup.commands = []*Command{failing}
+	s.executionGroup.Run(context.Background())
+	assert.Contains(t, s.logs.String(), "retrying in 1ms (1 of 2)")
+	assert.Contains(t, s.logs.String(), "retrying in 2ms (2 of 2)", "expected the backoff to double on every retry")
+	assert.Equal(t, []string{"sh[-c exit 3]: exit status 3"}, s.executionGroup.GetLastErrors(), "expected only the last attempt to fail the execution group")
+
+	s.logs.Reset()
+	marker := path.Join(t.TempDir(), "attempted")
+	flaky := mockCommand("sh", []string{"-c", "test -f " + marker + " || { touch " + marker + "; exit 1; }"}, &s.logs)
+	flaky.config.Retries = 3
+	flaky.config.Backoff = time.Millisecond
+	s.executionGroup.commands = []*Command{flaky}
+	s.executionGroup.Run(context.Background())
+	assert.Contains(t, s.logs.String(), "(1 of 3)")
+	assert.NotContains(t, s.logs.String(), "(2 of 3)")
+	assert.Empty(t, s.executionGroup.GetLastErrors(), "expected commands which succeed when retried not to fail the execution group")
+}
+
+func (s *ExecutionGroupTestSuite) Test_waitToRetry() {
+	t := s.T()
+	command := mockCommand("sh", nil, &s.logs)
+	command.config.Retries = 1
+	command.config.Backoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.executionGroup.cancel = cancel
+	assert.False(t, s.executionGroup.waitToRetry(ctx, command, 1, errors.New("exit status 1")), "expected commands without retries left not to be retried")
+	retried := make(chan bool)
+	go func() { retried <- s.executionGroup.waitToRetry(ctx, command, 0, errors.New("exit status 1")) }()
+	for deadline := time.Now().Add(time.Second); !s.executionGroup.IsRunning() && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, s.executionGroup.IsRunning(), "expected execution groups waiting to retry to be running")
+	s.executionGroup.Terminate()
+	assert.False(t, <-retried, "expected terminated execution groups to cancel their retries")
+	assert.False(t, s.executionGroup.IsRunning())
+}
+
+func (s *ExecutionGroupTestSuite) TestRun_failsInvalidCommands() {
+	t := s.T()
+	s.executionGroup.commands = []*Command{mockCommand("godev-application-that-should-not-exist", nil, &s.logs)}
+	s.executionGroup.Run(context.Background())
+	assert.Len(t, s.executionGroup.GetLastErrors(), 1)
+	assert.Contains(t, s.executionGroup.GetLastErrors()[0], "godev-application-that-should-not-exist[]: ")
+	assert.Equal(t, 1, s.executionGroup.GetExitCode())
+}
+
+func (s *ExecutionGroupTestSuite) TestGetExitCode() {
+	t := s.T()
+	s.executionGroup.commands = []*Command{mockCommand("sh", []string{"-c", "exit 5"}, &s.logs)}
+	s.executionGroup.Run(context.Background())
+	assert.Equal(t, 5, s.executionGroup.GetExitCode())
+	s.executionGroup.commands = []*Command{mockCommand("true", nil, &s.logs)}
+	s.executionGroup.Run(context.Background())
+	assert.Equal(t, 0, s.executionGroup.GetExitCode(), "expected the exit code to be reset on every run")
+	assert.Equal(t, 1, getExitCode(errors.New("output did not match /ok/")))
+}
+
+func (s *ExecutionGroupTestSuite) TestRun_restartsCrashedCommands() {
+	t := s.T()
+	var crashes []*Event
+	s.executionGroup.events = InitEventBus(&EventBusConfig{})
+	s.executionGroup.events.Subscribe(EventProcessCrashed, func(event *Event) { crashes = append(crashes, event) })
+	crashing := mockCommand("sh", []string{"-c", "exit 2"}, &s.logs)
+	crashing.config.Backoff = time.Millisecond
+	s.executionGroup.commands = []*Command{crashing}
+	s.executionGroup.supervised = true
+	s.executionGroup.restartLimit = 2
+	s.executionGroup.Run(context.Background())
+	assert.Contains(t, s.logs.String(), "restarting in 1ms (1 of 2)")
+	assert.Contains(t, s.logs.String(), "restarting in 2ms (2 of 2)", "expected the backoff to double on every restart")
+	assert.Contains(t, s.logs.String(), "crashed 3 times in a row - not restarting it until the next run")
+	assert.Len(t, crashes, 3, "expected every crash to be published")
+	assert.Equal(t, []string{"sh[-c exit 2]: exit status 2"}, s.executionGroup.GetLastErrors())
+}
+
+func (s *ExecutionGroupTestSuite) Test_waitToRestart() {
+	t := s.T()
+	command := mockCommand("sh", nil, &s.logs)
+	command.config.Backoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.executionGroup.cancel = cancel
+	s.executionGroup.restartLimit = 1
+	assert.False(t, s.executionGroup.waitToRestart(ctx, command, 0, errors.New("exit status 1")), "expected commands of unsupervised execution groups not to be restarted")
+	s.executionGroup.supervised = true
+	assert.False(t, s.executionGroup.waitToRestart(ctx, command, 1, errors.New("exit status 1")), "expected crash-looping commands not to be restarted")
+	restarted := make(chan bool)
+	go func() { restarted <- s.executionGroup.waitToRestart(ctx, command, 0, errors.New("exit status 1")) }()
+	for deadline := time.Now().Add(time.Second); !s.executionGroup.IsRunning() && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, s.executionGroup.IsRunning(), "expected execution groups waiting to restart to be running")
+	s.executionGroup.Terminate()
+	assert.False(t, <-restarted, "expected terminated execution groups to cancel their restarts")
+}
+
+func (s *ExecutionGroupTestSuite) Test_getRestartBackoff() {
+	t := s.T()
+	assert.Equal(t, DefaultExecutionBackoff, getRestartBackoff(0, 0))
+	assert.Equal(t, 4*time.Second, getRestartBackoff(time.Second, 2))
+	assert.Equal(t, MaxRestartBackoff, getRestartBackoff(time.Second, 10))
+	assert.Equal(t, MaxRestartBackoff, getRestartBackoff(time.Hour, 0))
+}
+
 func (s *ExecutionGroupTestSuite) TestTerminate() {
 	t := s.T()
 	s.executionGroup.commands = []*Command{
@@ -70,15 +385,32 @@ func (s *ExecutionGroupTestSuite) TestTerminate() {
 	s.executionGroup.commands[0].handleInitialisation()
 	s.executionGroup.commands[0].started = true
 	s.executionGroup.commands[0].stopped = false
+	signals := s.executionGroup.commands[0].signal
 	go func() {
 		select {
-		case signal := <-s.executionGroup.commands[0].signal:
+		case signal := <-signals:
 			assert.Equal(t, signal, syscall.SIGINT)
 		}
 	}()
 	s.executionGroup.Terminate()
 }
 
+func (s *ExecutionGroupTestSuite) TestTerminateWithSignal() {
+	t := s.T()
+	s.executionGroup.commands = []*Command{
+		mockCommand("echo", []string{"1"}, &s.logs),
+	}
+	s.executionGroup.commands[0].handleInitialisation()
+	s.executionGroup.commands[0].started = true
+	s.executionGroup.commands[0].stopped = false
+	received := make(chan os.Signal, 1)
+	go func() {
+		received <- <-s.executionGroup.commands[0].signal
+	}()
+	s.executionGroup.TerminateWithSignal(syscall.SIGTERM)
+	assert.Equal(t, syscall.SIGTERM, <-received, "expected the signal to be sent instead of the stop signal")
+}
+
 func (s *ExecutionGroupTestSuite) Test_handleCommandStatus() {
 	t := s.T()
 	testCommand := mockCommand("echo", []string{"1"}, &s.logs)
@@ -86,4 +418,200 @@ func (s *ExecutionGroupTestSuite) Test_handleCommandStatus() {
 	s.executionGroup.handleCommandStatus(testCommand, nil)
 	s.executionGroup.waitGroup.Wait()
 	assert.Contains(t, s.logs.String(), "command[echo[1]] exited without error")
+	assert.Empty(t, s.executionGroup.GetLastErrors())
+	s.executionGroup.waitGroup.Add(1)
+	s.executionGroup.handleCommandStatus(testCommand, errors.New("exit status 1"))
+	s.executionGroup.waitGroup.Wait()
+	assert.Equal(t, []string{"echo[1]: exit status 1"}, s.executionGroup.GetLastErrors())
+	s.executionGroup.setTerminating(true)
+	s.executionGroup.waitGroup.Add(1)
+	s.executionGroup.handleCommandStatus(testCommand, errors.New("signal: interrupt"))
+	s.executionGroup.waitGroup.Wait()
+	assert.Equal(t, []string{"echo[1]: exit status 1"}, s.executionGroup.GetLastErrors(), "expected terminated commands not to fail the execution group")
+	assert.Contains(t, s.logs.String(), "command[echo[1]] was stopped with: signal: interrupt")
+}
+
+func (s *ExecutionGroupTestSuite) Test_handleCommandStatus_publishesCrashes() {
+	t := s.T()
+	var crashes []*Event
+	s.executionGroup.events = InitEventBus(&EventBusConfig{})
+	s.executionGroup.events.Subscribe(EventProcessCrashed, func(event *Event) { crashes = append(crashes, event) })
+	testCommand := mockCommand("echo", []string{"1"}, &s.logs)
+	for _, supervised := range []bool{false, true} {
+		s.executionGroup.supervised = supervised
+		s.executionGroup.waitGroup.Add(1)
+		s.executionGroup.handleCommandStatus(testCommand, errors.New("exit status 2"))
+	}
+	assert.Len(t, crashes, 1, "expected only supervised commands to crash")
+	assert.Equal(t, "echo 1", crashes[0].Command)
+	assert.Equal(t, "exit status 2", crashes[0].Error)
+	s.executionGroup.setTerminating(true)
+	s.executionGroup.waitGroup.Add(1)
+	s.executionGroup.handleCommandStatus(testCommand, errors.New("signal: interrupt"))
+	assert.Len(t, crashes, 1, "expected terminated commands not to crash")
+}
+
+func (s *ExecutionGroupTestSuite) Test_parseExecutionGroupFilters() {
+	t := s.T()
+	patterns, commands, err := parseExecutionGroupFilters("go build")
+	assert.Nil(t, err)
+	assert.Nil(t, patterns)
+	assert.Equal(t, "go build", commands)
+	patterns, commands, err = parseExecutionGroupFilters("[*.proto, api/*.yaml] protoc --go_out=. api.proto,go generate")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"*.proto", "api/*.yaml"}, patterns)
+	assert.Equal(t, "protoc --go_out=. api.proto,go generate", commands)
+	for _, invalid := range []string{"[*.proto protoc", "[] protoc", "[*.proto]", "[[] protoc"} {
+		_, _, err = parseExecutionGroupFilters(invalid)
+		assert.NotNilf(t, err, "expected '%s' to be invalid", invalid)
+	}
+}
+
+func (s *ExecutionGroupTestSuite) Test_parseExecutionOptions() {
+	t := s.T()
+	options, commands, err := parseExecutionOptions("go run .")
+	assert.Nil(t, err)
+	assert.Empty(t, options.Directory)
+	assert.Empty(t, options.Environment)
+	assert.Equal(t, "go run .", commands)
+	options, commands, err = parseExecutionOptions("dir=./cmd/api,env=PORT=8080,env=APP_ENV=dev:go run .,go vet")
+	assert.Nil(t, err)
+	assert.Equal(t, "./cmd/api", options.Directory)
+	assert.Equal(t, []string{"PORT=8080", "APP_ENV=dev"}, options.Environment)
+	assert.Equal(t, "go run .,go vet", commands)
+	options, commands, err = parseExecutionOptions(" env=GOFLAGS=-mod=vendor:go build")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GOFLAGS=-mod=vendor"}, options.Environment)
+	assert.Equal(t, "go build", commands)
+	options, commands, err = parseExecutionOptions(`exit=0|2,match=^ok\:\s+\d{1\,3}:golangci-lint run`)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, options.SuccessCodes)
+	assert.Equal(t, `^ok:\s+\d{1,3}`, options.SuccessPattern.String())
+	assert.Equal(t, "golangci-lint run", commands)
+	options, _, err = parseExecutionOptions("name=assets,dir=web:npm run build")
+	assert.Nil(t, err)
+	assert.Equal(t, "assets", options.Name)
+	options, commands, err = parseExecutionOptions(`image=ghcr.io/org/protoc\:3.15:protoc --version`)
+	assert.Nil(t, err)
+	assert.Equal(t, "ghcr.io/org/protoc:3.15", options.Image)
+	assert.Equal(t, "ghcr.io/org/protoc:3.15", (&ExecutionOptions{}).GetImage(options), "expected commands to use the image of their group")
+	assert.Equal(t, "protoc --version", commands)
+	options, _, err = parseExecutionOptions("output=grouped:go vet ./...,golint ./...")
+	assert.Nil(t, err)
+	assert.True(t, (&ExecutionOptions{}).IsOutputGrouped(options), "expected commands to use the output mode of their group")
+	assert.False(t, (&ExecutionOptions{Output: "live"}).IsOutputGrouped(options), "expected commands to override the output mode of their group")
+	options, _, err = parseExecutionOptions("retries=3,backoff=2s:buf generate")
+	assert.Nil(t, err)
+	retries, backoff := (&ExecutionOptions{}).GetRetryPolicy(options)
+	assert.Equal(t, 3, retries)
+	assert.Equal(t, 2*time.Second, backoff)
+	retries, backoff = (&ExecutionOptions{Retries: 1}).GetRetryPolicy(&ExecutionOptions{})
+	assert.Equal(t, 1, retries)
+	assert.Equal(t, DefaultExecutionBackoff, backoff)
+	options, _, err = parseExecutionOptions("timeout=10m:go test ./...")
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, (&ExecutionOptions{}).GetTimeout(options))
+	assert.Equal(t, time.Minute, (&ExecutionOptions{Timeout: time.Minute}).GetTimeout(options), "expected commands to override the timeout of their group")
+	options, commands, err = parseExecutionOptions("when=**/*.proto|buf.yaml:buf generate")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"**/*.proto", "buf.yaml"}, (&ExecutionOptions{}).GetWhen(options), "expected commands to use the when patterns of their group")
+	assert.Equal(t, []string{"*.go"}, (&ExecutionOptions{When: []string{"*.go"}}).GetWhen(options), "expected commands to override the when patterns of their group")
+	assert.Equal(t, "buf generate", commands)
+	for _, invalid := range []string{"timeout=0s:go test ./...", "timeout=forever:go test ./...", "retries=-1:go mod download", "retries=many:go mod download", "backoff=0s:go mod download", "backoff=soon:go mod download", "output=buffered:go vet", "image=-it:sh", "dir=./api go run .", "dir=:go run .", "env=PORT:go run .", "env==1:go run .", "dir=api,user=root:go run .", "dir=api:", "exit=zero:go vet", "match=[:go vet", "when=[:go vet", "when=:go vet"} {
+		_, _, err = parseExecutionOptions(invalid)
+		assert.NotNilf(t, err, "expected '%s' to be invalid", invalid)
+	}
+}
+
+func (s *ExecutionGroupTestSuite) TestExecutionOptions_GetDirectory() {
+	t := s.T()
+	assert.Equal(t, "/work", (&ExecutionOptions{}).GetDirectory("/work"))
+	assert.Equal(t, "/work/cmd/api", (&ExecutionOptions{Directory: "./cmd/api"}).GetDirectory("/work"))
+	assert.Equal(t, "/srv", (&ExecutionOptions{Directory: "/srv/"}).GetDirectory("/work"))
+}
+
+func (s *ExecutionGroupTestSuite) Test_validateExecutionGroup() {
+	t := s.T()
+	assert.Nil(t, validateExecutionGroup("[*.go] dir=api:go build,env=A=1:go vet", ","))
+	assert.Nil(t, validateExecutionGroup("dir=api:go build;go vet", ";"))
+	assert.NotNil(t, validateExecutionGroup("[*.go go build", ","))
+	assert.Nil(t, validateExecutionGroup("name=build:go build", ","))
+	assert.NotNil(t, validateExecutionGroup("go vet,name=build:go build", ","), "expected named commands to be rejected")
+	assert.Nil(t, validateExecutionGroup("go list -f {{.Dir}} ./...", ","), "expected other templates to be passed on to the command")
+	assert.NotNil(t, validateExecutionGroup("dir=api go build", ","))
+	assert.NotNil(t, validateExecutionGroup("go build,env=A:go vet", ","))
+	assert.NotNil(t, validateExecutionGroup("echo 'unclosed", ","))
+}
+
+func (s *ExecutionGroupTestSuite) Test_splitCommands() {
+	t := s.T()
+	assert.Equal(t, []string{"go build", "go vet"}, splitCommands("go build,go vet", ","))
+	assert.Equal(t, []string{`printf "%s\n" a,b`, "go vet"}, splitCommands(`printf "%s\n" a\,b,go vet`, ","))
+	assert.Equal(t, []string{`curl -H 'Accept: a, b' localhost`, "echo done"}, splitCommands(`curl -H 'Accept: a, b' localhost,echo done`, ","))
+	assert.Equal(t, []string{`echo "a, \"b, c\""`}, splitCommands(`echo "a, \"b, c\""`, ","))
+	assert.Equal(t, []string{`echo 'it\'`, "echo b"}, splitCommands(`echo 'it\',echo b`, ","), "expected backslashes in single quotes to be literal")
+	assert.Equal(t, []string{"echo a && b", "echo c"}, splitCommands("echo a \\&& b&&echo c", "&&"))
+	assert.Equal(t, []string{"go build", ""}, splitCommands("go build,", ","))
+	assert.Equal(t, []string{"go build,go vet"}, splitCommands("go build,go vet", ""))
+	assert.Equal(t, []string{"go vet", "retries=2,timeout=5s:go test ./..."}, splitCommands("go vet,retries=2,timeout=5s:go test ./...", ","), "expected the options of a command to not be split")
+	assert.Equal(t, []string{`retries=2,timeout=5s:go test`, "go vet"}, splitCommands("retries=2,timeout=5s:go test,go vet", ","))
+	assert.Equal(t, []string{"go vet", ` exit=0|2,match=^ok\:\s+\d{1\,3}:golangci-lint run`}, splitCommands(`go vet, exit=0|2,match=^ok\:\s+\d{1\,3}:golangci-lint run`, ","), "expected escapes in options to be left for the options to parse")
+	options, command, err := parseExecutionOptions(splitCommands("go vet,retries=2,timeout=5s:go test ./...", ",")[1])
+	assert.Nil(t, err)
+	assert.Equal(t, 2, options.Retries)
+	assert.Equal(t, 5*time.Second, options.Timeout)
+	assert.Equal(t, "go test ./...", command)
+	assert.Nil(t, validateExecutionGroup("go vet,retries=2,timeout=5s:go test ./...", ","))
+}
+
+func (s *ExecutionGroupTestSuite) Test_splitCommand() {
+	t := s.T()
+	sections, err := splitCommand("go test './...'", false, []string{"-v"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go", "test", "./...", "-v"}, sections)
+	sections, err = splitCommand("sh: go test ./... | tee test.log", false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, append(getShellCommand(), "go test ./... | tee test.log"), sections)
+	sections, err = splitCommand("go build && bin/app", true, []string{"--port", "a b"})
+	assert.Nil(t, err)
+	assert.Equal(t, append(getShellCommand(), "go build && bin/app --port 'a b'"), sections, "expected the arguments to be quoted into the script")
+	sections, err = splitCommand("", false, []string{"-v"})
+	assert.Nil(t, err)
+	assert.Empty(t, sections, "expected empty commands not to run their arguments")
+	_, err = splitCommand("sh: ", false, nil)
+	assert.NotNil(t, err)
+	_, err = splitCommand("echo 'unclosed", false, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, validateExecutionGroup("sh:go test ./... 2>&1 | tee test.log,sh:echo 'unclosed", ","), "expected shell commands not to be split")
+}
+
+func (s *ExecutionGroupTestSuite) TestRun_runsShellCommands() {
+	t := s.T()
+	output := path.Join(t.TempDir(), "output")
+	sections, _ := splitCommand("sh:echo piped | tr a-z A-Z > "+output, false, nil)
+	s.executionGroup.commands = []*Command{mockCommand(sections[0], sections[1:], &s.logs)}
+	s.executionGroup.Run(context.Background())
+	assert.Empty(t, s.executionGroup.GetLastErrors())
+	contents, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, "PIPED\n", string(contents))
+}
+
+func (s *ExecutionGroupTestSuite) TestExecutionOptions_GetSuccessCriteria() {
+	t := s.T()
+	group := &ExecutionOptions{SuccessCodes: []int{0, 1}, SuccessPattern: regexp.MustCompile("ok")}
+	successCodes, successPattern := (&ExecutionOptions{}).GetSuccessCriteria(group)
+	assert.Equal(t, []int{0, 1}, successCodes)
+	assert.Equal(t, "ok", successPattern.String())
+	successCodes, successPattern = (&ExecutionOptions{SuccessCodes: []int{2}}).GetSuccessCriteria(group)
+	assert.Equal(t, []int{2}, successCodes)
+	assert.Equal(t, "ok", successPattern.String())
+}
+
+func (s *ExecutionGroupTestSuite) TestHasSuccessCriteria() {
+	t := s.T()
+	s.executionGroup.commands = []*Command{mockCommand("echo", nil, &s.logs)}
+	assert.False(t, s.executionGroup.HasSuccessCriteria())
+	s.executionGroup.commands[0].config.SuccessCodes = []int{0, 1}
+	assert.True(t, s.executionGroup.HasSuccessCriteria())
 }
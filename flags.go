@@ -1,41 +1,167 @@
-package main
+package godev
 
 import (
+	"strings"
+
 	"github.com/urfave/cli"
 )
 
+// getFlagAllMains provisions --all-mains
+func getFlagAllMains() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_ALL_MAINS",
+		Name:   "all-mains",
+		Usage:  "| build every main package in ./cmd in parallel and run the ones specified with --run-main",
+	}
+}
+
+// getFlagBuildCommand provisions --build-cmd
+func getFlagBuildCommand() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_BUILD_CMD",
+		Name:   "build-cmd",
+		Usage:  "| where <value> is a command to replace the default build step with (ignored if --exec is specified)",
+	}
+}
+
+// getFlagBuildReport provisions --build-report
+func getFlagBuildReport() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_BUILD_REPORT",
+		Name:   "build-report",
+		Usage:  "| reports the size of the binary against the last build and the modules added, removed, upgraded or downgraded in go.mod after every successful pipeline",
+	}
+}
+
 // getFlagBuildOutput provisions --output
 func getFlagBuildOutput() cli.Flag {
 	return cli.StringFlag{
-		Name:  "output, o",
-		Usage: "| where <value> is the relative path to the binary",
-		Value: DefaultBuildOutput,
+		EnvVar: "GODEV_OUTPUT",
+		Name:   "output, o",
+		Usage:  "| where <value> is the relative path to the binary",
+		Value:  DefaultBuildOutput,
+	}
+}
+
+// getFlagChildLogFormat provisions --child-log-format
+func getFlagChildLogFormat() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_CHILD_LOG_FORMAT",
+		Name:   "child-log-format",
+		Usage:  "| where <value> is one of 'json' or 'logfmt' - parses output of commands in this format and re-renders it in godev's format",
+	}
+}
+
+// getFlagChildLogLevel provisions --child-log-level
+func getFlagChildLogLevel() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_CHILD_LOG_LEVEL",
+		Name:   "child-log-level",
+		Usage:  "| where <value> is the minimum level of parsed command logs to display (see --child-log-format)",
+		Value:  DefaultChildLogLevel,
 	}
 }
 
 // getFlagCommandArguments provisions --output
 func getFlagCommandArguments() cli.Flag {
 	return cli.StringFlag{
-		Name:  "args",
-		Usage: "| where <value> is a comma delimited string containing arguments to pass to commands in the final execution group",
-		Value: DefaultCommandArguments,
+		EnvVar: "GODEV_ARGS",
+		Name:   "args",
+		Usage:  "| where <value> is a comma delimited string containing arguments to pass to commands in the final execution group",
+		Value:  DefaultCommandArguments,
 	}
 }
 
 // getFlagCommandsDelimiter provisions --exec-delim
 func getFlagCommandsDelimiter() cli.Flag {
 	return cli.StringFlag{
-		Name:  "exec-delim",
-		Usage: "| where <value> is the delimiter for commands in an execution group",
-		Value: DefaultCommandsDelimiter,
+		EnvVar: "GODEV_EXEC_DELIM",
+		Name:   "exec-delim",
+		Usage:  "| where <value> is the delimiter for commands in an execution group",
+		Value:  DefaultCommandsDelimiter,
+	}
+}
+
+// getFlagConfigFile provisions --config
+func getFlagConfigFile() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_CONFIG",
+		Name:   "config",
+		Usage:  "| where <value> is the path to a godev.yaml or .godev.toml configuration file (defaults to one found in the watch directory)",
+	}
+}
+
+// getFlagContainerRuntime provisions --container-runtime
+func getFlagContainerRuntime() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_CONTAINER_RUNTIME",
+		Name:   "container-runtime",
+		Usage:  "| where <value> is the container runtime (eg. podman) to run commands with an image=... option with, docker or else podman is used if not specified",
+	}
+}
+
+// getFlagControlAddress provisions --control
+func getFlagControlAddress() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_CONTROL",
+		Name:   "control",
+		Usage:  "| where <value> is an address (eg. 127.0.0.1:7275) to serve the control api at",
+	}
+}
+
+// getFlagDocsAddress provisions --docs
+func getFlagDocsAddress() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_DOCS",
+		Name:   "docs",
+		Usage:  "| where <value> is an address (eg. :6061) to serve the documentation of the packages in the work directory at",
+	}
+}
+
+// getFlagCoverProfile provisions --coverprofile
+func getFlagCoverProfile() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_COVERPROFILE",
+		Name:   "coverprofile",
+		Usage:  "| where <value> is the path to write the merged coverage profile to",
+		Value:  DefaultCoverProfile,
+	}
+}
+
+// getFlagDebug provisions --debug
+func getFlagDebug() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_DEBUG",
+		Name:   "debug",
+		Usage:  "| builds without optimisations and runs the binary under delve (dlv) so that a debugger can attach to it after every rebuild",
+	}
+}
+
+// getFlagDebugAddress provisions --debug-address
+func getFlagDebugAddress() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_DEBUG_ADDRESS",
+		Name:   "debug-address",
+		Usage:  "| where <value> is the address (eg. :2345 in a container) delve listens at with --debug",
+		Value:  DefaultDebugAddress,
+	}
+}
+
+// getFlagEnvFile provisions --env-file
+func getFlagEnvFile() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_ENV_FILE",
+		Name:   "env-file",
+		Usage:  "| where <value> is the path to a .env file whose variables are passed to all commands (defaults to .env in the work directory if it exists)",
 	}
 }
 
 // getFlagEnvVars provisions --env
 func getFlagEnvVars() cli.Flag {
 	return cli.StringSliceFlag{
-		Name:  "env, e",
-		Usage: "| where <value> is the relative path to the binary - specify multiple of these to pass in multiple environment variables",
+		EnvVar: "GODEV_ENV",
+		Name:   "env, e",
+		Usage:  "| where <value> is the relative path to the binary - specify multiple of these to pass in multiple environment variables",
 	}
 }
 
@@ -47,47 +173,558 @@ func getFlagExecGroups() cli.Flag {
 	}
 }
 
+// getFlagExcludePatterns provisions --exclude
+func getFlagExcludePatterns() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_EXCLUDE",
+		Name:   "exclude",
+		Usage:  "| where <value> is a glob pattern (** matches any directories) of paths whose changes are ignored - specify multiple of these to exclude multiple patterns",
+	}
+}
+
 // getFlagFileExtensions provisions --ext
 func getFlagFileExtensions() cli.Flag {
 	return cli.StringFlag{
-		Name:  "exts",
-		Usage: "| where <value> is a comma-delimited set of file extensions without the period (.)",
-		Value: DefaultFileExtensions,
+		EnvVar: "GODEV_EXTS",
+		Name:   "exts",
+		Usage:  "| where <value> is a comma-delimited set of file extensions without the period (.)",
+		Value:  DefaultFileExtensions,
+	}
+}
+
+// getFlagHosts provisions --host
+func getFlagHosts() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_HOST",
+		Name:   "host",
+		Usage:  "| where <value> is a DNS name or IP address the certificate is valid for (defaults to localhost, 127.0.0.1 and ::1) - specify multiple of these for multiple hosts",
+	}
+}
+
+// getFlagGCFlags provisions --gcflags
+func getFlagGCFlags() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_GCFLAGS",
+		Name:   "gcflags",
+		Usage:  "| where <value> is passed as -gcflags to the default go build and go test commands (eg. all=-m)",
+	}
+}
+
+// getFlagGenerate provisions --generate
+func getFlagGenerate() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_GENERATE",
+		Name:   "generate",
+		Usage:  "| runs go generate before the default build for the packages whose .go files with //go:generate directives changed, or for all packages when a generator input changed",
+	}
+}
+
+// getFlagGenerateInputs provisions --generate-input
+func getFlagGenerateInputs() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_GENERATE_INPUT",
+		Name:   "generate-input",
+		Usage:  "| where <value> is a glob pattern (** matches any directories) of the files which code is generated from, implies --generate (default: " + DefaultGenerateInputs + ") - specify multiple of these to include multiple patterns",
+	}
+}
+
+// getFlagLDFlags provisions --ldflags
+func getFlagLDFlags() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_LDFLAGS",
+		Name:   "ldflags",
+		Usage:  "| where <value> is passed as -ldflags to the default go build and go test commands (eg. -s -w)",
+	}
+}
+
+// getFlagIgnoreBinaryFiles provisions --ignore-binary
+func getFlagIgnoreBinaryFiles() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_IGNORE_BINARY",
+		Name:   "ignore-binary",
+		Usage:  "| ignore changes to binary files even if their extension is watched",
+	}
+}
+
+// getFlagFix provisions --fix
+func getFlagFix() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_FIX",
+		Name:   "fix",
+		Usage:  "| fixes the issues which were found without asking",
+	}
+}
+
+// getFlagFollowSymlinks provisions --follow-symlinks
+func getFlagFollowSymlinks() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_FOLLOW_SYMLINKS",
+		Name:   "follow-symlinks",
+		Usage:  "| watch symlinked directories (eg. shared packages linked into a monorepo service) as if they were directories",
+	}
+}
+
+// getFlagForwardedPorts provisions --forward-port
+func getFlagForwardedPorts() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_FORWARD_PORT",
+		Name:   "forward-port",
+		Usage:  "| where <value> is in the form <host port>:<child port> (eg. 18080:8080) - forwards a port on localhost into the isolated network, specify multiple of these for multiple ports",
 	}
 }
 
 // getFlagIgnoredNames provisions --ignore
 func getFlagIgnoredNames() cli.Flag {
 	return cli.StringFlag{
-		Name:  "ignore",
-		Usage: "| where <value> is a comma-delimited set of file/directory names to not watch",
-		Value: DefaultIgnoredNames,
+		EnvVar: "GODEV_IGNORE",
+		Name:   "ignore",
+		Usage:  "| where <value> is a comma-delimited set of file/directory names to not watch",
+		Value:  DefaultIgnoredNames,
+	}
+}
+
+// getFlagIncludePatterns provisions --include
+func getFlagIncludePatterns() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_INCLUDE",
+		Name:   "include",
+		Usage:  "| where <value> is a glob pattern (** matches any directories) of paths to watch regardless of their extension - specify multiple of these to include multiple patterns",
+	}
+}
+
+// getFlagIsolateRuns provisions --isolate-runs
+func getFlagIsolateRuns() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_ISOLATE_RUNS",
+		Name:   "isolate-runs",
+		Usage:  "| gives every run of the pipeline its own temporary directory in GOTMPDIR and GODEV_RUN_DIR which is removed when the run succeeds and kept when it fails",
+	}
+}
+
+// getFlagIsolateNetwork provisions --isolate-network
+func getFlagIsolateNetwork() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_ISOLATE_NETWORK",
+		Name:   "isolate-network",
+		Usage:  "| run the last execution group in a private network namespace (linux only, requires root)",
+	}
+}
+
+// getFlagKillTimeout provisions --kill-timeout
+func getFlagKillTimeout() cli.Flag {
+	return cli.DurationFlag{
+		EnvVar: "GODEV_KILL_TIMEOUT",
+		Name:   "kill-timeout",
+		Usage:  "| where <value> is how long a command has to exit after it is sent the --stop-signal before it and the processes it started are killed",
+		Value:  DefaultKillTimeout,
+	}
+}
+
+// getFlagLocale provisions --locale
+func getFlagLocale() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_LOCALE",
+		Name:   "locale",
+		Usage:  "| where <value> is the locale (eg. en or pt_BR) of the messages shown (defaults to the locale in LC_ALL, LC_MESSAGES or LANG)",
+	}
+}
+
+// getFlagLogLevel provisions --log-level
+func getFlagLogLevel() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_LOG_LEVEL",
+		Name:   "log-level",
+		Usage:  "| where <value> is one of trace, debug, info, warn, error or panic (overridden by --silent, --vv and --vvv)",
+	}
+}
+
+// getFlagLogOutput provisions --log-output
+func getFlagLogOutput() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_LOG_OUTPUT",
+		Name:   "log-output",
+		Usage:  "| where <value> is stdout, syslog, syslog://host:port, syslog+tcp://host:port, journald or the path of a file to write godev's logs to in addition to stderr - specify multiple of these to write to multiple outputs",
+	}
+}
+
+// getFlagCheck provisions --check
+func getFlagCheck() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_CHECK",
+		Name:   "check",
+		Usage:  "| validates the configuration, prints the resolved pipeline and exits without watching",
+	}
+}
+
+// getFlagImage provisions --image
+func getFlagImage() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_IMAGE",
+		Name:   "image",
+		Usage:  "| where <value> is the image (eg. the development stage of the Dockerfile) to run go in, its caches are kept in .cache/pkg of the work directory, which is mounted as /go/pkg",
+	}
+}
+
+// getFlagJSON provisions --json
+func getFlagJSON() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_JSON",
+		Name:   "json",
+		Usage:  "| prints the output as json",
+	}
+}
+
+// getFlagMake provisions --make, listing the targets of the Makefile in
+// the current directory if there is one
+func getFlagMake() cli.Flag {
+	usage := "| where <value> is a comma-delimited list of Makefile targets to run with make in their own execution groups instead of --exec"
+	if targets := GetMakeTargets("."); len(targets) > 0 {
+		usage += " (available: " + strings.Join(targets, ", ") + ")"
+	}
+	return cli.StringFlag{
+		EnvVar: "GODEV_MAKE",
+		Name:   "make",
+		Usage:  usage,
+	}
+}
+
+// getFlagManual provisions --manual
+func getFlagManual() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_MANUAL",
+		Name:   "manual",
+		Usage:  "| runs the pipeline once and then only when enter is pressed or the control api's /trigger is called instead of when files change",
+	}
+}
+
+// getFlagMaxFileSize provisions --max-file-size
+func getFlagMaxFileSize() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_MAX_FILE_SIZE",
+		Name:   "max-file-size",
+		Usage:  "| where <value> is a size (eg. 512KB, 1MB) above which changes to a file are ignored",
+	}
+}
+
+// getFlagMaxProcs provisions --max-procs
+func getFlagMaxProcs() cli.Flag {
+	return cli.IntFlag{
+		EnvVar: "GODEV_MAX_PROCS",
+		Name:   "max-procs",
+		Usage:  "| where <value> is the number of commands which can run at the same time across all execution groups (0 for no limit)",
+		Value:  DefaultMaxProcs,
+	}
+}
+
+// getFlagMaxWarnings provisions --max-warnings
+func getFlagMaxWarnings() cli.Flag {
+	return cli.IntFlag{
+		EnvVar: "GODEV_MAX_WARNINGS",
+		Name:   "max-warnings",
+		Usage:  "| where <value> is the number of vet/lint findings above which a run is marked failed (negative to disable)",
+		Value:  DefaultMaxWarnings,
+	}
+}
+
+// getFlagMinIntervals provisions --min-interval
+func getFlagMinIntervals() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_MIN_INTERVAL",
+		Name:   "min-interval",
+		Usage:  "| where <value> is in the form <group index>=<duration> (eg. 3=5m) - the execution group runs at most once per duration, specify multiple of these for multiple execution groups",
+	}
+}
+
+// getFlagMock provisions --mock
+func getFlagMock() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_MOCK",
+		Name:   "mock",
+		Usage:  "| where <value> is the path to a YAML file of routes and responses which a mock server serves while godev runs - specify multiple of these to run multiple mock servers",
+	}
+}
+
+// getFlagNoDetect provisions --no-detect
+func getFlagNoDetect() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_NO_DETECT",
+		Name:   "no-detect",
+		Usage:  "| disable tailoring the default pipeline to detected frameworks (buffalo, gin, echo, mage, wire)",
+	}
+}
+
+// getFlagNoNewPrivileges provisions --no-new-privs
+func getFlagNoNewPrivileges() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_NO_NEW_PRIVS",
+		Name:   "no-new-privs",
+		Usage:  "| prevent commands from gaining privileges via setuid/setgid binaries (linux only)",
+	}
+}
+
+// getFlagNoPrefix provisions --no-prefix
+func getFlagNoPrefix() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_NO_PREFIX",
+		Name:   "no-prefix",
+		Usage:  "| stops prefixing each line of output of the commands with the execution group and command it is from (eg. [2:go test])",
+	}
+}
+
+// getFlagNotify provisions --notify
+func getFlagNotify() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_NOTIFY",
+		Name:   "notify",
+		Usage:  "| where <value> is the --control address (eg. 127.0.0.1:7275) of another godev to trigger whenever the pipeline succeeds - specify multiple of these to notify multiple instances",
+	}
+}
+
+// getFlagOnce provisions --once
+func getFlagOnce() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_ONCE",
+		Name:   "once",
+		Usage:  "| runs the pipeline once without watching for changes and exits with the exit code of the first command which failed (eg. in ci)",
+	}
+}
+
+// getFlagOnlyGroups provisions --only-group
+func getFlagOnlyGroups() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_ONLY_GROUP",
+		Name:   "only-group",
+		Usage:  "| where <value> is the name or the index (starting from 1) of the only execution group to run - specify multiple of these to run multiple execution groups",
+	}
+}
+
+// getFlagPolicy provisions --policy
+func getFlagPolicy() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_POLICY",
+		Name:   "policy",
+		Usage:  "| where <value> is the path to a policy file restricting the executables commands can run and which of them have network access - defaults to godev/" + PolicyFileName + " in the user's configuration directory if it exists",
+	}
+}
+
+// getFlagPlugin provisions --plugin
+func getFlagPlugin() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_PLUGIN",
+		Name:   "plugin",
+		Usage:  "| where <value> is the command of an executable which extends godev by exchanging JSON messages over its stdin and stdout - specify multiple of these to run multiple plugins",
+	}
+}
+
+// getFlagPollInterval provisions --poll
+func getFlagPollInterval() cli.Flag {
+	return cli.DurationFlag{
+		EnvVar: "GODEV_POLL",
+		Name:   "poll",
+		Usage:  "| where <value> is an interval (eg. 500ms) to check the watched directories for changes at instead of relying on file system events which do not fire on NFS and some docker mounts",
+	}
+}
+
+// getFlagPollFallback provisions --poll-fallback
+func getFlagPollFallback() cli.Flag {
+	return cli.DurationFlag{
+		EnvVar: "GODEV_POLL_FALLBACK",
+		Name:   "poll-fallback",
+		Usage:  "| where <value> is an interval (eg. 2s) to check the directories which cannot be watched because the limit of inotify watches is reached at instead of missing their changes",
+	}
+}
+
+// getFlagPostHook provisions --post-hook
+func getFlagPostHook() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_POST_HOOK",
+		Name:   "post-hook",
+		Usage:  "| where <value> is a command which is run after every pipeline with GODEV_RESULT set to success, failure or terminated",
+	}
+}
+
+// getFlagPreHook provisions --pre-hook
+func getFlagPreHook() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_PRE_HOOK",
+		Name:   "pre-hook",
+		Usage:  "| where <value> is a command which is run before every pipeline, before the running one is terminated",
+	}
+}
+
+// getFlagProfile provisions --profile
+func getFlagProfile() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_PROFILE",
+		Name:   "profile",
+		Usage:  "| where <value> is the name of a profile in the configuration file whose execution groups, environment and watch settings should be used",
+	}
+}
+
+// getFlagProjectDirectory provisions --project-dir
+func getFlagProjectDirectory() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_PROJECT_DIR",
+		Name:   "project-dir",
+		Usage:  "| where <value> is the path relative to the work directory to keep caches, run history and lock files in",
+		Value:  DefaultProjectDirectory,
+	}
+}
+
+// getFlagProxy provisions --proxy
+func getFlagProxy() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_PROXY",
+		Name:   "proxy",
+		Usage:  "| where <value> is in the form <proxy port>:<application port> (eg. 8080:8081) - proxies HTTP requests on localhost to the application so that they are answered with 502 instead of being refused while it restarts",
+	}
+}
+
+// getFlagPublish provisions --publish
+func getFlagPublish() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_PUBLISH",
+		Name:   "publish",
+		Usage:  "| where <value> is a directory to copy the built binary to, or a docker registry repository (eg. docker://localhost:5000/app) to push an image built from the Dockerfile to, with run metadata after every successful pipeline",
+	}
+}
+
+// getFlagPty provisions --pty
+func getFlagPty() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_PTY",
+		Name:   "pty",
+		Usage:  "| runs the commands in pseudo-terminals so that they keep their colours and progress bars while their output is still processed",
+	}
+}
+
+// getFlagRace provisions --race
+func getFlagRace() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_RACE",
+		Name:   "race",
+		Usage:  "| enables the race detector in the default go build and go test commands",
 	}
 }
 
 // getFlagRate provisions --rate
 func getFlagRate() cli.Flag {
 	return cli.DurationFlag{
-		Name:  "rate",
-		Usage: "| where <value> is a duration",
-		Value: DefaultRefreshRate,
+		EnvVar: "GODEV_RATE",
+		Name:   "rate",
+		Usage:  "| where <value> is a duration",
+		Value:  DefaultRefreshRate,
+	}
+}
+
+// getFlagRawOutput provisions --raw-output
+func getFlagRawOutput() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_RAW_OUTPUT",
+		Name:   "raw-output",
+		Usage:  "| passes the stdout and stderr of the commands through untouched (without prefixes, colours, parsing or recording) so that commands which need a terminal get one",
+	}
+}
+
+// getFlagTimeout provisions --timeout
+func getFlagTimeout() cli.Flag {
+	return cli.DurationFlag{
+		EnvVar: "GODEV_TIMEOUT",
+		Name:   "timeout",
+		Usage:  "| where <value> is how long a command can run before it is killed and fails (eg. 5m) - it does not apply to the application being watched and a timeout=DURATION option overrides it for an execution group or command",
+	}
+}
+
+// getFlagTags provisions --tags
+func getFlagTags() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_TAGS",
+		Name:   "tags",
+		Usage:  "| where <value> is a comma-delimited list of build tags for the default go build and go test commands",
+	}
+}
+
+// getFlagTagRuns provisions --tag-runs
+func getFlagTagRuns() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_TAG_RUNS",
+		Name:   "tag-runs",
+		Usage:  "| tags every log line of godev and the commands with the run it belongs to and the time since the run started so that 'godev logs --run N' can find them",
+	}
+}
+
+// getFlagTemplate provisions --template
+func getFlagTemplate() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_TEMPLATE",
+		Name:   "template",
+		Usage:  "| where <value> is one of 'default', 'cli' or 'service' to choose the main.go to seed",
+		Value:  DefaultInitTemplate,
+	}
+}
+
+// getFlagShard provisions --shard
+func getFlagShard() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_SHARD",
+		Name:   "shard",
+		Usage:  "| where <value> is the shard to test in the form <index>/<shards> (eg. 1/4)",
+		Value:  "1/1",
+	}
+}
+
+// getFlagTestShards provisions --test-shards
+func getFlagTestShards() cli.Flag {
+	return cli.IntFlag{
+		EnvVar: "GODEV_TEST_SHARDS",
+		Name:   "test-shards",
+		Usage:  "| where <value> is the number of parallel go test invocations to split packages across, 0 for one per cpu",
+		Value:  DefaultTestShards,
+	}
+}
+
+// getFlagUseGitignore provisions --use-gitignore
+func getFlagUseGitignore() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_USE_GITIGNORE",
+		Name:   "use-gitignore",
+		Usage:  "| skip paths ignored by the .gitignore files in the watch directory, both when watching directories and when handling changes",
+	}
+}
+
+// getFlagUser provisions --user
+func getFlagUser() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_USER",
+		Name:   "user, u",
+		Usage:  "| where <value> is the user (and optionally group) to run commands as in the form user[:group] - names or numeric ids are accepted (not supported on windows)",
 	}
 }
 
 // etFlagWatchDirectory provisions --watch
 func getFlagWatchDirectory() cli.Flag {
 	return cli.StringFlag{
-		EnvVar: "watch",
+		EnvVar: "GODEV_WATCH,watch",
 		Name:   "watch",
 		Usage:  "| where <value> is an absolute path to a directory to watch",
 		Value:  getCurrentWorkingDirectory(),
 	}
 }
 
+// getFlagWatchEvents provisions --watch-events
+func getFlagWatchEvents() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_WATCH_EVENTS",
+		Name:   "watch-events",
+		Usage:  "| where <value> is a comma-delimited set of file system events to handle out of create, write, remove, rename and chmod",
+		Value:  DefaultWatchEvents,
+	}
+}
+
 // getFlagWorkDirectory provisions --dir
 func getFlagWorkDirectory() cli.Flag {
 	return cli.StringFlag{
-		EnvVar: "DIR",
+		EnvVar: "GODEV_DIR,DIR",
 		Name:   "dir",
 		Usage:  "| where <value> is an absolute path to a directory to use as the current working directory",
 		Value:  getCurrentWorkingDirectory(),
@@ -97,7 +734,7 @@ func getFlagWorkDirectory() cli.Flag {
 // getFlagCommit provisions --commit
 func getFlagCommit() cli.Flag {
 	return cli.BoolFlag{
-		EnvVar: "COMMIT",
+		EnvVar: "GODEV_COMMIT,COMMIT",
 		Name:   "commit",
 		Usage:  "| set the display to only the commit hash",
 	}
@@ -106,16 +743,199 @@ func getFlagCommit() cli.Flag {
 // getFlagSemver provisions --semver
 func getFlagSemver() cli.Flag {
 	return cli.BoolFlag{
-		EnvVar: "SEMVER",
+		EnvVar: "GODEV_SEMVER,SEMVER",
 		Name:   "semver",
 		Usage:  "| set the display to only the semver version",
 	}
 }
 
+// getFlagReadyPattern provisions --ready-pattern
+func getFlagReadyPattern() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_READY_PATTERN",
+		Name:   "ready-pattern",
+		Usage:  "| where <value> is a regular expression which marks the service as ready when matched in its output",
+	}
+}
+
+// getFlagRecordEvents provisions --record
+func getFlagRecordEvents() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_RECORD",
+		Name:   "record",
+		Usage:  "| where <value> is a file (eg. events.jsonl) to write the file system events received to for --replay",
+	}
+}
+
+// getFlagRecordOutput provisions --record-output
+func getFlagRecordOutput() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_RECORD_OUTPUT",
+		Name:   "record-output",
+		Usage:  "| record the output of every run in the project directory for 'godev history diff' (always on in test mode)",
+	}
+}
+
+// getFlagRecordRequests provisions --record-requests
+func getFlagRecordRequests() cli.Flag {
+	return cli.IntFlag{
+		EnvVar: "GODEV_RECORD_REQUESTS",
+		Name:   "record-requests",
+		Usage:  "| where <value> is the number of the last requests through --proxy to record for 'godev replay last'",
+	}
+}
+
+// getFlagReplayEvents provisions --replay
+func getFlagReplayEvents() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_REPLAY",
+		Name:   "replay",
+		Usage:  "| where <value> is a file written by --record whose file system events should be replayed instead of watching for changes",
+	}
+}
+
+// getFlagRestart provisions --restart
+func getFlagRestart() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_RESTART",
+		Name:   "restart",
+		Usage:  "| restarts the commands of the last execution group with an exponential backoff when they crash",
+	}
+}
+
+// getFlagRestartLimit provisions --restart-limit
+func getFlagRestartLimit() cli.Flag {
+	return cli.IntFlag{
+		EnvVar: "GODEV_RESTART_LIMIT",
+		Name:   "restart-limit",
+		Usage:  "| where <value> is the number of times in a row a crashing command is restarted with --restart before godev gives up until the next run",
+		Value:  DefaultRestartLimit,
+	}
+}
+
+// getFlagRoute provisions --route
+func getFlagRoute() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_ROUTE",
+		Name:   "route",
+		Usage:  "| where <value> is in the form <pattern>=<group name> (eg. web/**=assets) - the execution group named with a name=<group name>: prefix only runs when a changed file matches one of the patterns routed to it, specify multiple of these for multiple routes",
+	}
+}
+
+// getFlagRunCommand provisions --run-cmd
+func getFlagRunCommand() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_RUN_CMD",
+		Name:   "run-cmd",
+		Usage:  "| where <value> is a command to replace the default run step with (ignored if --exec is specified)",
+	}
+}
+
+// getFlagRunFilter provisions --run
+func getFlagRunFilter() cli.Flag {
+	return cli.IntFlag{
+		EnvVar: "GODEV_RUN",
+		Name:   "run",
+		Usage:  "| where <value> is the run whose log lines are shown",
+	}
+}
+
+// getFlagRunMain provisions --run-main
+func getFlagRunMain() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_RUN_MAIN",
+		Name:   "run-main",
+		Usage:  "| where <value> is the name of a main package in ./cmd (eg. server) to run after it is built with --all-mains - specify multiple of these to run multiple binaries",
+	}
+}
+
+// getFlagSnapshotTimeout provisions --snapshot-timeout
+func getFlagSnapshotTimeout() cli.Flag {
+	return cli.DurationFlag{
+		EnvVar: "GODEV_SNAPSHOT_TIMEOUT",
+		Name:   "snapshot-timeout",
+		Usage:  "| where <value> is the duration to wait for the application to snapshot its state before it is stopped",
+		Value:  DefaultSnapshotTimeout,
+	}
+}
+
+// getFlagStampVersion provisions --stamp-version
+func getFlagStampVersion() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_STAMP_VERSION",
+		Name:   "stamp-version",
+		Usage:  "| sets main.version, main.commit and main.buildTime with -ldflags in the default go build command from git whenever it runs",
+	}
+}
+
+// getFlagStatusLine provisions --status-line
+func getFlagStatusLine() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_STATUS_LINE",
+		Name:   "status-line",
+		Usage:  "| show a spinner with the elapsed time and the running command while commands run without output (only in a terminal)",
+	}
+}
+
+// getFlagStopSignal provisions --stop-signal
+func getFlagStopSignal() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_STOP_SIGNAL",
+		Name:   "stop-signal",
+		Usage:  "| where <value> is one of 'SIGINT' or 'SIGTERM' to choose the signal which is sent to stop a command and the processes it started",
+		Value:  DefaultStopSignal,
+	}
+}
+
+// getFlagStateDirectory provisions --state-dir
+func getFlagStateDirectory() cli.Flag {
+	return cli.StringFlag{
+		EnvVar: "GODEV_STATE_DIR",
+		Name:   "state-dir",
+		Usage:  "| where <value> is a directory relative to the working directory where the application can snapshot its state between restarts",
+	}
+}
+
+// getFlagSelfReload provisions --self-reload
+func getFlagSelfReload() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_SELF_RELOAD",
+		Name:   "self-reload",
+		Usage:  "| automatically restart godev with the current session when its executable is upgraded",
+	}
+}
+
+// getFlagSkipGroups provisions --skip-group
+func getFlagSkipGroups() cli.Flag {
+	return cli.StringSliceFlag{
+		EnvVar: "GODEV_SKIP_GROUP",
+		Name:   "skip-group",
+		Usage:  "| where <value> is the name or the index (starting from 1) of an execution group not to run - specify multiple of these to skip multiple execution groups",
+	}
+}
+
+// getFlagShareWorktree provisions --share-worktree
+func getFlagShareWorktree() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_SHARE_WORKTREE",
+		Name:   "share-worktree",
+		Usage:  "| in a linked git worktree, uses the development certificate of the main worktree and builds with -trimpath so that compiled packages are shared through the build cache",
+	}
+}
+
+// getFlagShell provisions --shell
+func getFlagShell() cli.Flag {
+	return cli.BoolFlag{
+		EnvVar: "GODEV_SHELL",
+		Name:   "shell",
+		Usage:  "| runs every command with sh -c (cmd /C on windows) so that commands can use pipes, redirections and && chains like commands prefixed with sh:",
+	}
+}
+
 // getFlagSilent provisions --silent
 func getFlagSilent() cli.Flag {
 	return cli.BoolFlag{
-		EnvVar: "SILENT",
+		EnvVar: "GODEV_SILENT,SILENT",
 		Name:   "silent, s",
 		Usage:  "| silence the logs",
 	}
@@ -124,7 +944,7 @@ func getFlagSilent() cli.Flag {
 // getFlagVerboseLogs provisions --verbose
 func getFlagVerboseLogs() cli.Flag {
 	return cli.BoolFlag{
-		EnvVar: "VERBOSE",
+		EnvVar: "GODEV_VERBOSE,VERBOSE",
 		Name:   "verbose, vv",
 		Usage:  "| print verbose (debug level) logs (use for debugging)",
 	}
@@ -133,7 +953,7 @@ func getFlagVerboseLogs() cli.Flag {
 // getFlagSuperVerboseLogs provisions --vverbose
 func getFlagSuperVerboseLogs() cli.Flag {
 	return cli.BoolFlag{
-		EnvVar: "VVERBOSE",
+		EnvVar: "GODEV_VVERBOSE,VVERBOSE",
 		Name:   "vverbose, vvv",
 		Usage:  "| print very verbose (trace level) logs (use for development of godev itself)",
 	}
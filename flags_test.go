@@ -1,4 +1,4 @@
-package main
+package godev
 
 import (
 	"testing"
@@ -15,10 +15,34 @@ func TestFlags(t *testing.T) {
 	suite.Run(t, new(FlagsTestSuite))
 }
 
+func (s *FlagsTestSuite) Test_getFlagAllMains() {
+	ensureFlag(s.T(), getFlagAllMains(), cli.BoolFlag{}, `^all-mains$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagBuildCommand() {
+	ensureFlag(s.T(), getFlagBuildCommand(), cli.StringFlag{}, `^build-cmd$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagBuildReport() {
+	ensureFlag(s.T(), getFlagBuildReport(), cli.BoolFlag{}, `^build-report$`)
+}
+
 func (s *FlagsTestSuite) Test_getFlagBuildOutput() {
 	ensureFlag(s.T(), getFlagBuildOutput(), cli.StringFlag{}, `^output.*`)
 }
 
+func (s *FlagsTestSuite) Test_getFlagCheck() {
+	ensureFlag(s.T(), getFlagCheck(), cli.BoolFlag{}, `^check$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagChildLogFormat() {
+	ensureFlag(s.T(), getFlagChildLogFormat(), cli.StringFlag{}, `^child-log-format$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagChildLogLevel() {
+	ensureFlag(s.T(), getFlagChildLogLevel(), cli.StringFlag{}, `^child-log-level$`)
+}
+
 func (s *FlagsTestSuite) Test_getFlagCommandArguments() {
 	ensureFlag(s.T(), getFlagCommandArguments(), cli.StringFlag{}, `^args`)
 }
@@ -27,6 +51,34 @@ func (s *FlagsTestSuite) Test_getFlagCommandsDelimiter() {
 	ensureFlag(s.T(), getFlagCommandsDelimiter(), cli.StringFlag{}, `^exec-delim.*`)
 }
 
+func (s *FlagsTestSuite) Test_getFlagConfigFile() {
+	ensureFlag(s.T(), getFlagConfigFile(), cli.StringFlag{}, `^config$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagControlAddress() {
+	ensureFlag(s.T(), getFlagControlAddress(), cli.StringFlag{}, `^control$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagCoverProfile() {
+	ensureFlag(s.T(), getFlagCoverProfile(), cli.StringFlag{}, `^coverprofile$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagDebug() {
+	ensureFlag(s.T(), getFlagDebug(), cli.BoolFlag{}, `^debug$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagDocsAddress() {
+	ensureFlag(s.T(), getFlagDocsAddress(), cli.StringFlag{}, `^docs$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagDebugAddress() {
+	ensureFlag(s.T(), getFlagDebugAddress(), cli.StringFlag{}, `^debug-address$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagEnvFile() {
+	ensureFlag(s.T(), getFlagEnvFile(), cli.StringFlag{}, `^env-file$`)
+}
+
 func (s *FlagsTestSuite) Test_getFlagEnvVars() {
 	ensureFlag(s.T(), getFlagEnvVars(), cli.StringSliceFlag{}, `^env.*`)
 }
@@ -39,18 +91,294 @@ func (s *FlagsTestSuite) Test_getFlagFileExtensions() {
 	ensureFlag(s.T(), getFlagFileExtensions(), cli.StringFlag{}, `^exts.*`)
 }
 
+func (s *FlagsTestSuite) Test_getFlagHosts() {
+	ensureFlag(s.T(), getFlagHosts(), cli.StringSliceFlag{}, `^host$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagIgnoreBinaryFiles() {
+	ensureFlag(s.T(), getFlagIgnoreBinaryFiles(), cli.BoolFlag{}, `^ignore-binary$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagFollowSymlinks() {
+	ensureFlag(s.T(), getFlagFollowSymlinks(), cli.BoolFlag{}, `^follow-symlinks$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagForwardedPorts() {
+	ensureFlag(s.T(), getFlagForwardedPorts(), cli.StringSliceFlag{}, `^forward-port$`)
+}
+
 func (s *FlagsTestSuite) Test_getFlagIgnoredNames() {
 	ensureFlag(s.T(), getFlagIgnoredNames(), cli.StringFlag{}, `^ignore.*`)
 }
 
+func (s *FlagsTestSuite) Test_getFlagIsolateNetwork() {
+	ensureFlag(s.T(), getFlagIsolateNetwork(), cli.BoolFlag{}, `^isolate-network$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagKillTimeout() {
+	ensureFlag(s.T(), getFlagKillTimeout(), cli.DurationFlag{}, `^kill-timeout$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagExcludePatterns() {
+	ensureFlag(s.T(), getFlagExcludePatterns(), cli.StringSliceFlag{}, `^exclude$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagIncludePatterns() {
+	ensureFlag(s.T(), getFlagIncludePatterns(), cli.StringSliceFlag{}, `^include$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagImage() {
+	ensureFlag(s.T(), getFlagImage(), cli.StringFlag{}, `^image$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagJSON() {
+	ensureFlag(s.T(), getFlagJSON(), cli.BoolFlag{}, `^json$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagFix() {
+	ensureFlag(s.T(), getFlagFix(), cli.BoolFlag{}, `^fix$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagNoPrefix() {
+	ensureFlag(s.T(), getFlagNoPrefix(), cli.BoolFlag{}, `^no-prefix$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagGCFlags() {
+	ensureFlag(s.T(), getFlagGCFlags(), cli.StringFlag{}, `^gcflags$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagGenerate() {
+	ensureFlag(s.T(), getFlagGenerate(), cli.BoolFlag{}, `^generate$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagGenerateInputs() {
+	ensureFlag(s.T(), getFlagGenerateInputs(), cli.StringSliceFlag{}, `^generate-input$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagLDFlags() {
+	ensureFlag(s.T(), getFlagLDFlags(), cli.StringFlag{}, `^ldflags$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRace() {
+	ensureFlag(s.T(), getFlagRace(), cli.BoolFlag{}, `^race$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagShareWorktree() {
+	ensureFlag(s.T(), getFlagShareWorktree(), cli.BoolFlag{}, `^share-worktree$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagStampVersion() {
+	ensureFlag(s.T(), getFlagStampVersion(), cli.BoolFlag{}, `^stamp-version$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagTags() {
+	ensureFlag(s.T(), getFlagTags(), cli.StringFlag{}, `^tags$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagLocale() {
+	ensureFlag(s.T(), getFlagLocale(), cli.StringFlag{}, `^locale$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagLogLevel() {
+	ensureFlag(s.T(), getFlagLogLevel(), cli.StringFlag{}, `^log-level$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagLogOutput() {
+	ensureFlag(s.T(), getFlagLogOutput(), cli.StringSliceFlag{}, `^log-output$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagMake() {
+	ensureFlag(s.T(), getFlagMake(), cli.StringFlag{}, `^make$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagManual() {
+	ensureFlag(s.T(), getFlagManual(), cli.BoolFlag{}, `^manual$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagMaxFileSize() {
+	ensureFlag(s.T(), getFlagMaxFileSize(), cli.StringFlag{}, `^max-file-size$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagStatusLine() {
+	ensureFlag(s.T(), getFlagStatusLine(), cli.BoolFlag{}, `^status-line$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagMaxProcs() {
+	ensureFlag(s.T(), getFlagMaxProcs(), cli.IntFlag{}, `^max-procs$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagMaxWarnings() {
+	ensureFlag(s.T(), getFlagMaxWarnings(), cli.IntFlag{}, `^max-warnings$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagMinIntervals() {
+	ensureFlag(s.T(), getFlagMinIntervals(), cli.StringSliceFlag{}, `^min-interval$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagMock() {
+	ensureFlag(s.T(), getFlagMock(), cli.StringSliceFlag{}, `^mock$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagNoDetect() {
+	ensureFlag(s.T(), getFlagNoDetect(), cli.BoolFlag{}, `^no-detect$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagNoNewPrivileges() {
+	ensureFlag(s.T(), getFlagNoNewPrivileges(), cli.BoolFlag{}, `^no-new-privs$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagNotify() {
+	ensureFlag(s.T(), getFlagNotify(), cli.StringSliceFlag{}, `^notify$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagPolicy() {
+	ensureFlag(s.T(), getFlagPolicy(), cli.StringFlag{}, `^policy$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagPlugin() {
+	ensureFlag(s.T(), getFlagPlugin(), cli.StringSliceFlag{}, `^plugin$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagPollFallback() {
+	ensureFlag(s.T(), getFlagPollFallback(), cli.DurationFlag{}, `^poll-fallback$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagPollInterval() {
+	ensureFlag(s.T(), getFlagPollInterval(), cli.DurationFlag{}, `^poll$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagProfile() {
+	ensureFlag(s.T(), getFlagProfile(), cli.StringFlag{}, `^profile$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagProjectDirectory() {
+	ensureFlag(s.T(), getFlagProjectDirectory(), cli.StringFlag{}, `^project-dir$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagOnlyGroups() {
+	ensureFlag(s.T(), getFlagOnlyGroups(), cli.StringSliceFlag{}, `^only-group$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagSkipGroups() {
+	ensureFlag(s.T(), getFlagSkipGroups(), cli.StringSliceFlag{}, `^skip-group$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagProxy() {
+	ensureFlag(s.T(), getFlagProxy(), cli.StringFlag{}, `^proxy$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagPublish() {
+	ensureFlag(s.T(), getFlagPublish(), cli.StringFlag{}, `^publish$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagPty() {
+	ensureFlag(s.T(), getFlagPty(), cli.BoolFlag{}, `^pty$`)
+}
+
 func (s *FlagsTestSuite) Test_getFlagRate() {
 	ensureFlag(s.T(), getFlagRate(), cli.DurationFlag{}, `^rate.*`)
 }
 
+func (s *FlagsTestSuite) Test_getFlagRawOutput() {
+	ensureFlag(s.T(), getFlagRawOutput(), cli.BoolFlag{}, `^raw-output$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagTemplate() {
+	ensureFlag(s.T(), getFlagTemplate(), cli.StringFlag{}, `^template$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagTagRuns() {
+	ensureFlag(s.T(), getFlagTagRuns(), cli.BoolFlag{}, `^tag-runs$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRunFilter() {
+	ensureFlag(s.T(), getFlagRunFilter(), cli.IntFlag{}, `^run$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagTimeout() {
+	ensureFlag(s.T(), getFlagTimeout(), cli.DurationFlag{}, `^timeout$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagTestShards() {
+	ensureFlag(s.T(), getFlagTestShards(), cli.IntFlag{}, `^test-shards$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagUseGitignore() {
+	ensureFlag(s.T(), getFlagUseGitignore(), cli.BoolFlag{}, `^use-gitignore$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagUser() {
+	ensureFlag(s.T(), getFlagUser(), cli.StringFlag{}, `^user.*`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagReadyPattern() {
+	ensureFlag(s.T(), getFlagReadyPattern(), cli.StringFlag{}, `^ready-pattern$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRecordEvents() {
+	ensureFlag(s.T(), getFlagRecordEvents(), cli.StringFlag{}, `^record$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagReplayEvents() {
+	ensureFlag(s.T(), getFlagReplayEvents(), cli.StringFlag{}, `^replay$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagIsolateRuns() {
+	ensureFlag(s.T(), getFlagIsolateRuns(), cli.BoolFlag{}, `^isolate-runs$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagOnce() {
+	ensureFlag(s.T(), getFlagOnce(), cli.BoolFlag{}, `^once$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagPostHook() {
+	ensureFlag(s.T(), getFlagPostHook(), cli.StringFlag{}, `^post-hook$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagPreHook() {
+	ensureFlag(s.T(), getFlagPreHook(), cli.StringFlag{}, `^pre-hook$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagShell() {
+	ensureFlag(s.T(), getFlagShell(), cli.BoolFlag{}, `^shell$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRestart() {
+	ensureFlag(s.T(), getFlagRestart(), cli.BoolFlag{}, `^restart$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRestartLimit() {
+	ensureFlag(s.T(), getFlagRestartLimit(), cli.IntFlag{}, `^restart-limit$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRecordOutput() {
+	ensureFlag(s.T(), getFlagRecordOutput(), cli.BoolFlag{}, `^record-output$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRecordRequests() {
+	ensureFlag(s.T(), getFlagRecordRequests(), cli.IntFlag{}, `^record-requests$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRoute() {
+	ensureFlag(s.T(), getFlagRoute(), cli.StringSliceFlag{}, `^route$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRunCommand() {
+	ensureFlag(s.T(), getFlagRunCommand(), cli.StringFlag{}, `^run-cmd$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagRunMain() {
+	ensureFlag(s.T(), getFlagRunMain(), cli.StringSliceFlag{}, `^run-main$`)
+}
+
 func (s *FlagsTestSuite) Test_getFlagWatchDirectory() {
 	ensureFlag(s.T(), getFlagWatchDirectory(), cli.StringFlag{}, `^watch.*`)
 }
 
+func (s *FlagsTestSuite) Test_getFlagWatchEvents() {
+	ensureFlag(s.T(), getFlagWatchEvents(), cli.StringFlag{}, `^watch-events$`)
+}
+
 func (s *FlagsTestSuite) Test_getFlagWorkDirectory() {
 	ensureFlag(s.T(), getFlagWorkDirectory(), cli.StringFlag{}, `^dir.*`)
 }
@@ -63,6 +391,22 @@ func (s *FlagsTestSuite) Test_getFlagSemver() {
 	ensureFlag(s.T(), getFlagSemver(), cli.BoolFlag{}, `^semver.*`)
 }
 
+func (s *FlagsTestSuite) Test_getFlagSelfReload() {
+	ensureFlag(s.T(), getFlagSelfReload(), cli.BoolFlag{}, `^self-reload$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagSnapshotTimeout() {
+	ensureFlag(s.T(), getFlagSnapshotTimeout(), cli.DurationFlag{}, `^snapshot-timeout$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagStateDirectory() {
+	ensureFlag(s.T(), getFlagStateDirectory(), cli.StringFlag{}, `^state-dir$`)
+}
+
+func (s *FlagsTestSuite) Test_getFlagStopSignal() {
+	ensureFlag(s.T(), getFlagStopSignal(), cli.StringFlag{}, `^stop-signal$`)
+}
+
 func (s *FlagsTestSuite) Test_getFlagSilent() {
 	ensureFlag(s.T(), getFlagSilent(), cli.BoolFlag{}, `^silent.*`)
 }
@@ -0,0 +1,167 @@
+package godev
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+)
+
+// frameworkModules maps framework names to the module paths which
+// indicate their usage when found in a go.mod
+var frameworkModules = map[string]string{
+	"buffalo": "github.com/gobuffalo/buffalo",
+	"echo":    "github.com/labstack/echo",
+	"gin":     "github.com/gin-gonic/gin",
+	"mage":    "github.com/magefile/mage",
+	"wire":    "github.com/google/wire",
+}
+
+// serverFrameworks are frameworks whose entrypoints are usually
+// found in a directory under ./cmd
+var serverFrameworks = []string{"echo", "gin"}
+
+// FrameworkDetection holds the results of inspecting a project
+// for commonly used frameworks and tooling
+type FrameworkDetection struct {
+	// Frameworks is a sorted list of the names of detected frameworks
+	Frameworks []string
+	// Entrypoint is the package to build if it is not the root package
+	Entrypoint string
+	// PreBuild is a list of commands to run before the build step
+	PreBuild []string
+	// UseBuffalo is true when the project should be built with `buffalo build`
+	UseBuffalo bool
+}
+
+// DetectFrameworks inspects the project at :workDirectory and returns
+// the frameworks and tooling it uses
+func DetectFrameworks(workDirectory string) *FrameworkDetection {
+	detection := &FrameworkDetection{}
+	goMod, _ := ioutil.ReadFile(path.Join(workDirectory, "/go.mod"))
+	for framework, module := range frameworkModules {
+		if strings.Contains(string(goMod), module) {
+			detection.Frameworks = append(detection.Frameworks, framework)
+		}
+	}
+	magefile, _ := ioutil.ReadFile(path.Join(workDirectory, "/magefile.go"))
+	if len(magefile) > 0 && !detection.Has("mage") {
+		detection.Frameworks = append(detection.Frameworks, "mage")
+	}
+	sort.Strings(detection.Frameworks)
+	if detection.Has("wire") {
+		detection.PreBuild = append(detection.PreBuild, "wire ./...")
+	}
+	if strings.Contains(string(magefile), "func Generate(") {
+		detection.PreBuild = append(detection.PreBuild, "mage generate")
+	}
+	if detection.Has("buffalo") {
+		detection.UseBuffalo = true
+	} else {
+		detection.Entrypoint = detectServerEntrypoint(workDirectory)
+	}
+	return detection
+}
+
+// Has returns true if :framework was detected
+func (detection *FrameworkDetection) Has(framework string) bool {
+	for _, detected := range detection.Frameworks {
+		if detected == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty returns true if nothing was detected that would change the
+// default pipeline
+func (detection *FrameworkDetection) IsEmpty() bool {
+	return len(detection.PreBuild) == 0 && len(detection.Entrypoint) == 0 && !detection.UseBuffalo
+}
+
+// GetBuildCommand returns the build step tailored to the detected
+// frameworks which outputs the binary at :buildOutput
+func (detection *FrameworkDetection) GetBuildCommand(buildOutput string) string {
+	if detection.UseBuffalo {
+		return fmt.Sprintf("buffalo build -o %s", buildOutput)
+	}
+	if len(detection.Entrypoint) > 0 {
+		return fmt.Sprintf("go build -o %s %s", buildOutput, detection.Entrypoint)
+	}
+	return fmt.Sprintf("go build -o %s", buildOutput)
+}
+
+// detectServerEntrypoint returns the relative path to the only package
+// under ./cmd which imports a server framework, or an empty string if
+// there is none or more than one
+func detectServerEntrypoint(workDirectory string) string {
+	commandsDirectory := path.Join(workDirectory, "/cmd")
+	if !directoryExists(commandsDirectory) {
+		return ""
+	}
+	listings, err := ioutil.ReadDir(commandsDirectory)
+	if err != nil {
+		return ""
+	}
+	var entrypoints []string
+	for _, listing := range listings {
+		if !listing.IsDir() {
+			continue
+		}
+		mainFile, err := ioutil.ReadFile(path.Join(commandsDirectory, listing.Name(), "/main.go"))
+		if err != nil {
+			continue
+		}
+		for _, framework := range serverFrameworks {
+			if strings.Contains(string(mainFile), fmt.Sprintf("%q", frameworkModules[framework])) ||
+				strings.Contains(string(mainFile), frameworkModules[framework]+"/") {
+				entrypoints = append(entrypoints, "./cmd/"+listing.Name())
+				break
+			}
+		}
+	}
+	if len(entrypoints) != 1 {
+		return ""
+	}
+	return entrypoints[0]
+}
+
+// DiscoverMainPackages returns the names of the directories under ./cmd
+// in :workDirectory which contain a main package, sorted by name
+func DiscoverMainPackages(workDirectory string) ([]string, error) {
+	commandsDirectory := path.Join(workDirectory, "/cmd")
+	listings, err := ioutil.ReadDir(commandsDirectory)
+	if err != nil {
+		return nil, err
+	}
+	var mains []string
+	for _, listing := range listings {
+		if listing.IsDir() && isMainPackage(path.Join(commandsDirectory, listing.Name())) {
+			mains = append(mains, listing.Name())
+		}
+	}
+	return mains, nil
+}
+
+// isMainPackage checks whether any non-test go file in :directoryPath
+// declares package main
+func isMainPackage(directoryPath string) bool {
+	listings, err := ioutil.ReadDir(directoryPath)
+	if err != nil {
+		return false
+	}
+	for _, listing := range listings {
+		name := listing.Name()
+		if listing.IsDir() || path.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path.Join(directoryPath, name), nil, parser.PackageClauseOnly)
+		if err == nil && file.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
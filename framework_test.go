@@ -0,0 +1,95 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FrameworkTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestFramework(t *testing.T) {
+	suite.Run(t, new(FrameworkTestSuite))
+}
+
+func (s *FrameworkTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-framework")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+}
+
+func (s *FrameworkTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *FrameworkTestSuite) writeFile(relativePath string, contents string) {
+	filePath := path.Join(s.directory, relativePath)
+	assert.Nil(s.T(), os.MkdirAll(path.Dir(filePath), 0755))
+	assert.Nil(s.T(), ioutil.WriteFile(filePath, []byte(contents), 0644))
+}
+
+func (s *FrameworkTestSuite) TestDetectFrameworksNone() {
+	t := s.T()
+	s.writeFile("go.mod", "module example.com/app\n")
+	detection := DetectFrameworks(s.directory)
+	assert.Empty(t, detection.Frameworks)
+	assert.True(t, detection.IsEmpty())
+	assert.Equal(t, "go build -o bin/app", detection.GetBuildCommand("bin/app"))
+}
+
+func (s *FrameworkTestSuite) TestDetectFrameworksServerWithWire() {
+	t := s.T()
+	s.writeFile("go.mod", "module example.com/app\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.3.0\n\tgithub.com/google/wire v0.2.1\n)\n")
+	s.writeFile("cmd/api/main.go", "package main\n\nimport \"github.com/gin-gonic/gin\"\n")
+	s.writeFile("cmd/migrate/main.go", "package main\n\nimport \"database/sql\"\n")
+	detection := DetectFrameworks(s.directory)
+	assert.Equal(t, []string{"gin", "wire"}, detection.Frameworks)
+	assert.Equal(t, []string{"wire ./..."}, detection.PreBuild)
+	assert.Equal(t, "./cmd/api", detection.Entrypoint)
+	assert.Equal(t, "go build -o bin/app ./cmd/api", detection.GetBuildCommand("bin/app"))
+}
+
+func (s *FrameworkTestSuite) TestDetectFrameworksAmbiguousEntrypoint() {
+	t := s.T()
+	s.writeFile("go.mod", "module example.com/app\n\nrequire github.com/labstack/echo/v4 v4.0.0\n")
+	s.writeFile("cmd/api/main.go", "package main\n\nimport \"github.com/labstack/echo/v4\"\n")
+	s.writeFile("cmd/admin/main.go", "package main\n\nimport \"github.com/labstack/echo/v4\"\n")
+	detection := DetectFrameworks(s.directory)
+	assert.Equal(t, []string{"echo"}, detection.Frameworks)
+	assert.Empty(t, detection.Entrypoint)
+	assert.True(t, detection.IsEmpty())
+}
+
+func (s *FrameworkTestSuite) TestDetectFrameworksBuffaloAndMage() {
+	t := s.T()
+	s.writeFile("go.mod", "module example.com/app\n\nrequire github.com/gobuffalo/buffalo v0.14.0\n")
+	s.writeFile("magefile.go", "// +build mage\n\npackage main\n\nfunc Generate() error { return nil }\n")
+	detection := DetectFrameworks(s.directory)
+	assert.Equal(t, []string{"buffalo", "mage"}, detection.Frameworks)
+	assert.Equal(t, []string{"mage generate"}, detection.PreBuild)
+	assert.Equal(t, "buffalo build -o bin/app", detection.GetBuildCommand("bin/app"))
+}
+
+func (s *FrameworkTestSuite) TestDiscoverMainPackages() {
+	t := s.T()
+	_, err := DiscoverMainPackages(s.directory)
+	assert.NotNil(t, err, "expected an error without a ./cmd directory")
+	s.writeFile("cmd/worker/worker.go", "// Package main runs jobs\npackage main\n")
+	s.writeFile("cmd/server/main.go", "package main\n\nfunc main() {}\n")
+	s.writeFile("cmd/server/main_test.go", "package main\n")
+	s.writeFile("cmd/shared/shared.go", "package shared\n")
+	s.writeFile("cmd/shared/shared_test.go", "package main\n")
+	s.writeFile("cmd/README.md", "# commands\n")
+	mains, err := DiscoverMainPackages(s.directory)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"server", "worker"}, mains)
+}
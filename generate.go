@@ -0,0 +1,63 @@
+package godev
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultGenerateInputs - default comma-separated list of patterns of the files which code is generated from with --generate
+const DefaultGenerateInputs = "*.proto,*.sql"
+
+// GenerateDirective is the prefix of the lines of .go files which
+// declare a command for go generate to run
+const GenerateDirective = "//go:generate "
+
+// GeneratePackagesPlaceholder is the placeholder which renders as the
+// packages to run go generate for, commands with an argument which is
+// only this placeholder are skipped when there are none
+const GeneratePackagesPlaceholder = "{{.GeneratePackages}}"
+
+// getGenerateExecutionGroup returns the execution group which runs go
+// generate for the packages whose directives or generator inputs changed
+func (config *Config) getGenerateExecutionGroup() string {
+	return fmt.Sprintf("when=%s: go generate %s", strings.Join(append([]string{"*.go"}, config.GenerateInputs...), "|"), GeneratePackagesPlaceholder)
+}
+
+// getGeneratePackages returns the packages of the :changedFiles which
+// have go:generate directives relative to :directory, or ./... when any
+// of them is not a .go file since a generator input could be used by a
+// directive in any package
+func getGeneratePackages(changedFiles []string, directory string) CommandTemplateList {
+	packages := map[string]bool{}
+	for _, changedFile := range changedFiles {
+		if filepath.Ext(changedFile) != ".go" {
+			return CommandTemplateList{"./..."}
+		} else if !hasGenerateDirectives(changedFile) {
+			continue
+		}
+		if packagePath, ok := getPackagePath(changedFile, directory); ok {
+			packages[packagePath] = true
+		}
+	}
+	return sortedTemplateList(packages)
+}
+
+// hasGenerateDirectives checks if the .go file at :filePath declares
+// any commands for go generate to run
+func hasGenerateDirectives(filePath string) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), GenerateDirective) {
+			return true
+		}
+	}
+	return false
+}
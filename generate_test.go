@@ -0,0 +1,74 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GenerateTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestGenerate(t *testing.T) {
+	suite.Run(t, new(GenerateTestSuite))
+}
+
+func (s *GenerateTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-generate")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.writeFile("api/api.go", "package api\n\n//go:generate protoc --go_out=. api.proto\n")
+	s.writeFile("api/handler.go", "package api\n")
+	s.writeFile("store/store.go", "package store\n\n// go:generate is not a directive\n")
+	s.writeFile("main.go", "//go:generate stringer -type=Level\npackage main\n")
+}
+
+func (s *GenerateTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *GenerateTestSuite) writeFile(relativePath, contents string) {
+	filePath := path.Join(s.directory, relativePath)
+	assert.Nil(s.T(), os.MkdirAll(path.Dir(filePath), os.ModePerm))
+	assert.Nil(s.T(), ioutil.WriteFile(filePath, []byte(contents), 0644))
+}
+
+func (s *GenerateTestSuite) Test_getGenerateExecutionGroup() {
+	t := s.T()
+	config := &Config{GenerateInputs: []string{"*.proto", "migrations/*.sql"}}
+	assert.Equal(t, "when=*.go|*.proto|migrations/*.sql: go generate {{.GeneratePackages}}", config.getGenerateExecutionGroup())
+	options, command, err := parseExecutionOptions(config.getGenerateExecutionGroup())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"*.go", "*.proto", "migrations/*.sql"}, options.When)
+	assert.Equal(t, "go generate {{.GeneratePackages}}", command)
+	assert.NotNil(t, parseCommandTemplate(GeneratePackagesPlaceholder))
+}
+
+func (s *GenerateTestSuite) Test_getGeneratePackages() {
+	t := s.T()
+	assert.Equal(t, CommandTemplateList{".", "./api"}, getGeneratePackages([]string{
+		path.Join(s.directory, "api/api.go"),
+		path.Join(s.directory, "api/handler.go"),
+		path.Join(s.directory, "store/store.go"),
+		path.Join(s.directory, "main.go"),
+	}, s.directory))
+	assert.Empty(t, getGeneratePackages([]string{path.Join(s.directory, "api/handler.go")}, s.directory), "expected packages without directives to be skipped")
+	assert.Equal(t, CommandTemplateList{"./..."}, getGeneratePackages([]string{
+		path.Join(s.directory, "api/handler.go"),
+		path.Join(s.directory, "api/api.proto"),
+	}, s.directory), "expected generator inputs to generate every package")
+}
+
+func (s *GenerateTestSuite) Test_hasGenerateDirectives() {
+	t := s.T()
+	assert.True(t, hasGenerateDirectives(path.Join(s.directory, "api/api.go")))
+	assert.True(t, hasGenerateDirectives(path.Join(s.directory, "main.go")))
+	assert.False(t, hasGenerateDirectives(path.Join(s.directory, "store/store.go")))
+	assert.False(t, hasGenerateDirectives(path.Join(s.directory, "missing.go")))
+}
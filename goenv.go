@@ -0,0 +1,155 @@
+package godev
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GoEnvironmentKeys are the go environment variables which change how
+// dependencies are resolved and how every go command behaves
+var GoEnvironmentKeys = []string{
+	"GOFLAGS",
+	"GONOPROXY",
+	"GONOSUMDB",
+	"GOPRIVATE",
+	"GOPROXY",
+	"GOSUMDB",
+}
+
+// goModVersionPattern matches the go directive of a go.mod file
+var goModVersionPattern = regexp.MustCompile(`(?m)^go\s+(\d+)\.(\d+)`)
+
+// GoEnvironment holds the effective values of the GoEnvironmentKeys
+type GoEnvironment map[string]string
+
+// GetGoEnvironment returns the effective go environment for commands run
+// in :workDirectory with :overrides (KEY=value pairs) applied on top of
+// the current environment - values are read with `go env` so that the
+// go env file is honoured, falling back to the environment if go is not
+// available
+func GetGoEnvironment(workDirectory string, overrides []string) GoEnvironment {
+	overrideValues := parseEnvironment(overrides)
+	environment := GoEnvironment{}
+	for _, key := range GoEnvironmentKeys {
+		environment[key] = os.Getenv(key)
+		if value, ok := overrideValues[key]; ok {
+			environment[key] = value
+		}
+	}
+	cmd := exec.Command("go", append([]string{"env", "-json"}, GoEnvironmentKeys...)...)
+	cmd.Dir = workDirectory
+	cmd.Env = append(os.Environ(), overrides...)
+	output, err := cmd.Output()
+	if err != nil {
+		return environment
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(output, &values); err != nil {
+		return environment
+	}
+	for _, key := range GoEnvironmentKeys {
+		if value, ok := values[key]; ok {
+			environment[key] = value
+		}
+	}
+	return environment
+}
+
+// String returns the non-empty values as space-separated KEY=value pairs
+func (environment GoEnvironment) String() string {
+	var pairs []string
+	for _, key := range GoEnvironmentKeys {
+		if value := environment[key]; len(value) > 0 {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	return strings.Join(pairs, " ")
+}
+
+// GetModFlag returns the value of -mod in GOFLAGS or an empty string
+func (environment GoEnvironment) GetModFlag() string {
+	for _, flag := range strings.Fields(environment["GOFLAGS"]) {
+		flag = strings.TrimLeft(flag, "-")
+		if strings.HasPrefix(flag, "mod=") {
+			return strings.TrimPrefix(flag, "mod=")
+		}
+	}
+	return ""
+}
+
+// GetWarnings returns descriptions of the ways in which the environment
+// will materially change how the go commands of :execGroups behave in
+// :workDirectory
+func (environment GoEnvironment) GetWarnings(workDirectory string, execGroups []string) []string {
+	var warnings []string
+	hasVendor := directoryExists(path.Join(workDirectory, "vendor"))
+	vendorsInPipeline := false
+	for _, execGroup := range execGroups {
+		if strings.Contains(execGroup, "go mod vendor") {
+			vendorsInPipeline = true
+		}
+	}
+	switch modFlag := environment.GetModFlag(); modFlag {
+	case "":
+		if hasVendor && !isVendorDefault(workDirectory) {
+			warnings = append(warnings, "vendor/ exists but GOFLAGS does not include -mod=vendor - dependencies will be loaded from the module cache instead")
+		}
+	case "vendor":
+		if !hasVendor && !vendorsInPipeline {
+			warnings = append(warnings, "GOFLAGS includes -mod=vendor but vendor/ does not exist - builds will fail until `go mod vendor` is run")
+		}
+	default:
+		if hasVendor {
+			warnings = append(warnings, fmt.Sprintf("GOFLAGS includes -mod=%s - vendor/ will be ignored", modFlag))
+		}
+	}
+	if environment["GOPROXY"] == "off" {
+		warnings = append(warnings, "GOPROXY is off - modules which are not in the module cache cannot be downloaded")
+	}
+	if environment["GOSUMDB"] == "off" {
+		warnings = append(warnings, "GOSUMDB is off - downloaded modules will not be verified against the checksum database")
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// isVendorDefault checks whether the go directive in the go.mod of
+// :workDirectory is at least 1.14, from which vendor/ is used by default
+func isVendorDefault(workDirectory string) bool {
+	contents, err := ioutil.ReadFile(path.Join(workDirectory, "go.mod"))
+	if err != nil {
+		return false
+	}
+	matches := goModVersionPattern.FindSubmatch(contents)
+	if matches == nil {
+		return false
+	}
+	major, _ := strconv.Atoi(string(matches[1]))
+	minor, _ := strconv.Atoi(string(matches[2]))
+	return major > 1 || (major == 1 && minor >= 14)
+}
+
+// validateGoEnvironment returns an error if :env has keys which are not
+// one of the GoEnvironmentKeys
+func validateGoEnvironment(env map[string]string) error {
+	for key := range env {
+		valid := false
+		for _, goKey := range GoEnvironmentKeys {
+			if key == goKey {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("'%s' is not one of %v", key, GoEnvironmentKeys)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GoEnvironmentTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestGoEnvironment(t *testing.T) {
+	suite.Run(t, new(GoEnvironmentTestSuite))
+}
+
+func (s *GoEnvironmentTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-goenv")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+}
+
+func (s *GoEnvironmentTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *GoEnvironmentTestSuite) writeGoMod(goVersion string) {
+	contents := "module example.com/app\n"
+	if len(goVersion) > 0 {
+		contents += "\ngo " + goVersion + "\n"
+	}
+	assert.Nil(s.T(), ioutil.WriteFile(path.Join(s.directory, "go.mod"), []byte(contents), 0644))
+}
+
+func (s *GoEnvironmentTestSuite) TestGetGoEnvironment_overrides() {
+	t := s.T()
+	s.writeGoMod("")
+	environment := GetGoEnvironment(s.directory, []string{"GOFLAGS=-mod=vendor", "GOPROXY=off", "PORT=8080"})
+	assert.Equal(t, "-mod=vendor", environment["GOFLAGS"])
+	assert.Equal(t, "off", environment["GOPROXY"])
+	assert.NotContains(t, environment, "PORT")
+	assert.Contains(t, environment.String(), "GOFLAGS=-mod=vendor")
+	assert.Contains(t, environment.String(), "GOPROXY=off")
+}
+
+func (s *GoEnvironmentTestSuite) TestGoEnvironment_String() {
+	environment := GoEnvironment{"GOFLAGS": "-race", "GOPROXY": "direct", "GOPRIVATE": ""}
+	assert.Equal(s.T(), "GOFLAGS=-race GOPROXY=direct", environment.String())
+}
+
+func (s *GoEnvironmentTestSuite) TestGoEnvironment_GetModFlag() {
+	t := s.T()
+	assert.Equal(t, "vendor", GoEnvironment{"GOFLAGS": "-race -mod=vendor"}.GetModFlag())
+	assert.Equal(t, "readonly", GoEnvironment{"GOFLAGS": "--mod=readonly"}.GetModFlag())
+	assert.Empty(t, GoEnvironment{"GOFLAGS": "-race"}.GetModFlag())
+}
+
+func (s *GoEnvironmentTestSuite) TestGoEnvironment_GetWarnings_vendor() {
+	t := s.T()
+	s.writeGoMod("1.13")
+	assert.Empty(t, GoEnvironment{}.GetWarnings(s.directory, nil))
+	assert.Len(t, GoEnvironment{"GOFLAGS": "-mod=vendor"}.GetWarnings(s.directory, nil), 1)
+	assert.Empty(t, GoEnvironment{"GOFLAGS": "-mod=vendor"}.GetWarnings(s.directory, []string{"go mod vendor", "go build"}))
+
+	assert.Nil(t, os.Mkdir(path.Join(s.directory, "vendor"), 0755))
+	warnings := GoEnvironment{}.GetWarnings(s.directory, nil)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "does not include -mod=vendor")
+	assert.Empty(t, GoEnvironment{"GOFLAGS": "-mod=vendor"}.GetWarnings(s.directory, nil))
+	assert.Contains(t, GoEnvironment{"GOFLAGS": "-mod=mod"}.GetWarnings(s.directory, nil)[0], "vendor/ will be ignored")
+
+	s.writeGoMod("1.14")
+	assert.Empty(t, GoEnvironment{}.GetWarnings(s.directory, nil), "expected vendor/ to be used by default from go 1.14")
+}
+
+func (s *GoEnvironmentTestSuite) TestGoEnvironment_GetWarnings_proxy() {
+	t := s.T()
+	warnings := GoEnvironment{"GOPROXY": "off", "GOSUMDB": "off"}.GetWarnings(s.directory, nil)
+	assert.Len(t, warnings, 2)
+}
+
+func (s *GoEnvironmentTestSuite) Test_validateGoEnvironment() {
+	t := s.T()
+	assert.Nil(t, validateGoEnvironment(map[string]string{"GOFLAGS": "-mod=vendor", "GOPRIVATE": "example.com"}))
+	assert.NotNil(t, validateGoEnvironment(map[string]string{"GOFLAG": "-mod=vendor"}))
+}
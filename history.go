@@ -0,0 +1,276 @@
+package godev
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunRecorderLimit is the maximum number of bytes of each output stream
+// that is recorded per run, output beyond it is dropped
+const RunRecorderLimit = 1024 * 1024
+
+// RunDiffLimit is the maximum number of lines compared for each output
+// stream, more lines are reported as replaced without being compared
+const RunDiffLimit = 4000
+
+// getRunID returns the identifier of the :pipeline which started at
+// :startedAt, which is unique across sessions unlike pipeline numbers
+func getRunID(pipeline int, startedAt time.Time) string {
+	return fmt.Sprintf("%s-%v", startedAt.Format("20060102-150405"), pipeline)
+}
+
+// InitRunRecorder creates a recorder for the output of commands
+func InitRunRecorder() *RunRecorder {
+	recorder := &RunRecorder{}
+	recorder.Stdout = &runRecorderStream{recorder: recorder}
+	recorder.Stderr = &runRecorderStream{recorder: recorder}
+	return recorder
+}
+
+// RunRecorder captures the stdout and stderr of the commands in a
+// pipeline so that runs can be compared after
+type RunRecorder struct {
+	Stdout *runRecorderStream
+	Stderr *runRecorderStream
+	mutex  sync.Mutex
+}
+
+// Reset clears the recorded output for the next run
+func (recorder *RunRecorder) Reset() {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.Stdout.buffer.Reset()
+	recorder.Stdout.truncated = false
+	recorder.Stderr.buffer.Reset()
+	recorder.Stderr.truncated = false
+}
+
+// runRecorderStream is an io.Writer for one output stream of a run
+type runRecorderStream struct {
+	recorder  *RunRecorder
+	buffer    bytes.Buffer
+	truncated bool
+}
+
+func (stream *runRecorderStream) Write(data []byte) (int, error) {
+	stream.recorder.mutex.Lock()
+	defer stream.recorder.mutex.Unlock()
+	if remaining := RunRecorderLimit - stream.buffer.Len(); remaining < len(data) {
+		stream.buffer.Write(data[:remaining])
+		stream.truncated = true
+	} else {
+		stream.buffer.Write(data)
+	}
+	return len(data), nil
+}
+
+// String returns the recorded output, noting whether it was truncated
+func (stream *runRecorderStream) String() string {
+	stream.recorder.mutex.Lock()
+	defer stream.recorder.mutex.Unlock()
+	if stream.truncated {
+		return stream.buffer.String() + fmt.Sprintf("\n[output truncated at %v bytes]\n", RunRecorderLimit)
+	}
+	return stream.buffer.String()
+}
+
+// TestResult is the outcome of a test or of a package as reported by
+// go test, Test is empty for packages
+type TestResult struct {
+	Package string `json:"package"`
+	Test    string `json:"test,omitempty"`
+	Status  string `json:"status"`
+}
+
+// GetName returns the package and test name of the result
+func (result TestResult) GetName() string {
+	return strings.TrimSpace(result.Package + " " + result.Test)
+}
+
+var (
+	testResultPattern    = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+)`)
+	packageResultPattern = regexp.MustCompile(`^(ok|FAIL|\?)\s+(\S+)`)
+	testDurationPattern  = regexp.MustCompile(`\(([0-9.]+s|cached)\)|\t[0-9.]+s\b`)
+)
+
+// ParseTestResults returns the test and package results found in the
+// output of go test sorted by name, tests are only reported by go test
+// when they fail or with -v and are attributed to the package reported
+// after them
+func ParseTestResults(output string) []TestResult {
+	var results []TestResult
+	var pending []TestResult
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), RunRecorderLimit)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := testResultPattern.FindStringSubmatch(line); match != nil {
+			pending = append(pending, TestResult{Test: match[2], Status: strings.ToLower(match[1])})
+		} else if match := packageResultPattern.FindStringSubmatch(line); match != nil {
+			status := map[string]string{"ok": "ok", "FAIL": "fail", "?": "no tests"}[match[1]]
+			results = append(results, TestResult{Package: match[2], Status: status})
+			for _, result := range pending {
+				result.Package = match[2]
+				results = append(results, result)
+			}
+			pending = nil
+		}
+	}
+	results = append(results, pending...)
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].GetName() < results[j].GetName()
+	})
+	return results
+}
+
+// RunDiff is the difference between two recorded runs
+type RunDiff struct {
+	Tests  []string
+	Stdout []string
+	Stderr []string
+}
+
+// IsEmpty checks whether the runs produced the same results and output
+func (diff *RunDiff) IsEmpty() bool {
+	return len(diff.Tests) == 0 && len(diff.Stdout) == 0 && len(diff.Stderr) == 0
+}
+
+// DiffRuns compares the recorded output and test results of :from with
+// those of :to
+func DiffRuns(from, to *RunOutput) *RunDiff {
+	return &RunDiff{
+		Tests:  diffTestResults(from.Tests, to.Tests),
+		Stdout: diffLines(splitOutputLines(from.Stdout), splitOutputLines(to.Stdout)),
+		Stderr: diffLines(splitOutputLines(from.Stderr), splitOutputLines(to.Stderr)),
+	}
+}
+
+// diffTestResults returns a "<name>: <status> -> <status>" line for each
+// test or package whose status changed, "none" marks missing results
+func diffTestResults(from, to []TestResult) []string {
+	statuses := map[string][2]string{}
+	var names []string
+	for index, results := range [][]TestResult{from, to} {
+		for _, result := range results {
+			name := result.GetName()
+			status, exists := statuses[name]
+			if !exists {
+				status = [2]string{"none", "none"}
+				names = append(names, name)
+			}
+			status[index] = result.Status
+			statuses[name] = status
+		}
+	}
+	sort.Strings(names)
+	var changes []string
+	for _, name := range names {
+		if status := statuses[name]; status[0] != status[1] {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, status[0], status[1]))
+		}
+	}
+	return changes
+}
+
+// splitOutputLines splits :output into lines with the durations printed
+// by go test removed so that they do not show up as changes
+func splitOutputLines(output string) []string {
+	output = strings.TrimRight(output, "\n")
+	if len(output) == 0 {
+		return nil
+	}
+	lines := strings.Split(output, "\n")
+	for index, line := range lines {
+		lines[index] = strings.TrimRight(testDurationPattern.ReplaceAllString(line, ""), " \t\r")
+	}
+	return lines
+}
+
+// diffLines returns the lines removed from :from prefixed with "-" and
+// the lines added in :to prefixed with "+" in the order they appear
+func diffLines(from, to []string) []string {
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+	from = from[prefix : len(from)-suffix]
+	to = to[prefix : len(to)-suffix]
+	var changes []string
+	if len(from) > RunDiffLimit || len(to) > RunDiffLimit {
+		for _, line := range from {
+			changes = append(changes, "-"+line)
+		}
+		for _, line := range to {
+			changes = append(changes, "+"+line)
+		}
+		return changes
+	}
+	// lengths[i][j] is the length of the longest common subsequence of
+	// from[i:] and to[j:]
+	lengths := make([][]int, len(from)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			i++
+			j++
+		case j == len(to) || (i < len(from) && lengths[i+1][j] >= lengths[i][j+1]):
+			changes = append(changes, "-"+from[i])
+			i++
+		default:
+			changes = append(changes, "+"+to[j])
+			j++
+		}
+	}
+	return changes
+}
+
+// parseRunReference returns the index in :history of the run referred
+// to by :reference, which is a run ID, "last" or "last~N" for the run N
+// runs before the last one
+func parseRunReference(history []RunHistoryEntry, reference string) (int, error) {
+	if reference == "last" || strings.HasPrefix(reference, "last~") {
+		offset := 0
+		if reference != "last" {
+			var err error
+			if offset, err = strconv.Atoi(strings.TrimPrefix(reference, "last~")); err != nil || offset < 0 {
+				return -1, fmt.Errorf("'%s' is not a valid run (expected last~N)", reference)
+			}
+		}
+		if offset >= len(history) {
+			return -1, fmt.Errorf("'%s' does not exist, there are %v runs in the history", reference, len(history))
+		}
+		return len(history) - 1 - offset, nil
+	}
+	for index, entry := range history {
+		if entry.ID == reference {
+			return index, nil
+		}
+	}
+	return -1, fmt.Errorf("run '%s' is not in the history", reference)
+}
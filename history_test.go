@@ -0,0 +1,118 @@
+package godev
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type HistoryTestSuite struct {
+	suite.Suite
+}
+
+func TestHistory(t *testing.T) {
+	suite.Run(t, new(HistoryTestSuite))
+}
+
+func (s *HistoryTestSuite) Test_getRunID() {
+	startedAt := time.Date(2019, 3, 4, 15, 16, 17, 0, time.UTC)
+	assert.Equal(s.T(), "20190304-151617-12", getRunID(12, startedAt))
+}
+
+func (s *HistoryTestSuite) TestRunRecorder() {
+	t := s.T()
+	recorder := InitRunRecorder()
+	recorder.Stdout.Write([]byte("hello\n"))
+	recorder.Stderr.Write([]byte("oops\n"))
+	assert.Equal(t, "hello\n", recorder.Stdout.String())
+	assert.Equal(t, "oops\n", recorder.Stderr.String())
+	count, err := recorder.Stdout.Write([]byte(strings.Repeat("a", RunRecorderLimit)))
+	assert.Nil(t, err)
+	assert.Equal(t, RunRecorderLimit, count, "expected dropped output to be reported as written")
+	assert.Contains(t, recorder.Stdout.String(), "[output truncated at")
+	recorder.Reset()
+	assert.Empty(t, recorder.Stdout.String())
+	assert.Empty(t, recorder.Stderr.String())
+}
+
+func (s *HistoryTestSuite) TestParseTestResults() {
+	results := ParseTestResults(strings.Join([]string{
+		"--- FAIL: TestSum (0.00s)",
+		"    sum_test.go:9: expected 3",
+		"    --- FAIL: TestSum/negative (0.00s)",
+		"FAIL",
+		"FAIL\texample.com/app/math\t0.012s",
+		"=== RUN   TestParse",
+		"--- PASS: TestParse (0.00s)",
+		"--- SKIP: TestSlow (0.00s)",
+		"ok  \texample.com/app/parser\t(cached)",
+		"?   \texample.com/app/cmd\t[no test files]",
+	}, "\n"))
+	assert.Equal(s.T(), []TestResult{
+		{Package: "example.com/app/cmd", Status: "no tests"},
+		{Package: "example.com/app/math", Status: "fail"},
+		{Package: "example.com/app/math", Test: "TestSum", Status: "fail"},
+		{Package: "example.com/app/math", Test: "TestSum/negative", Status: "fail"},
+		{Package: "example.com/app/parser", Status: "ok"},
+		{Package: "example.com/app/parser", Test: "TestParse", Status: "pass"},
+		{Package: "example.com/app/parser", Test: "TestSlow", Status: "skip"},
+	}, results)
+}
+
+func (s *HistoryTestSuite) TestDiffRuns() {
+	t := s.T()
+	from := &RunOutput{
+		Stdout: "--- FAIL: TestSum (0.01s)\nFAIL\texample.com/app/math\t0.012s\nstarting\nlistening on :8080\n",
+		Stderr: "warning: deprecated\n",
+	}
+	from.Tests = ParseTestResults(from.Stdout)
+	to := &RunOutput{
+		Stdout: "ok  \texample.com/app/math\t0.020s\nstarting\nconnected to db\nlistening on :8080\n",
+		Stderr: "warning: deprecated\n",
+	}
+	to.Tests = ParseTestResults(to.Stdout)
+	diff := DiffRuns(from, to)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []string{
+		"example.com/app/math: fail -> ok",
+		"example.com/app/math TestSum: fail -> none",
+	}, diff.Tests)
+	assert.Equal(t, []string{
+		"---- FAIL: TestSum",
+		"-FAIL\texample.com/app/math",
+		"+ok  \texample.com/app/math",
+		"+connected to db",
+	}, diff.Stdout, "expected durations to be ignored")
+	assert.Empty(t, diff.Stderr)
+	assert.True(t, DiffRuns(to, to).IsEmpty())
+}
+
+func (s *HistoryTestSuite) Test_diffLines() {
+	t := s.T()
+	assert.Empty(t, diffLines([]string{"a", "b"}, []string{"a", "b"}))
+	assert.Equal(t, []string{"-b", "+x", "+y"}, diffLines([]string{"a", "b", "c"}, []string{"a", "x", "y", "c"}))
+	assert.Equal(t, []string{"+a"}, diffLines(nil, []string{"a"}))
+	assert.Equal(t, []string{"-a", "-b"}, diffLines([]string{"a", "b"}, nil))
+}
+
+func (s *HistoryTestSuite) Test_parseRunReference() {
+	t := s.T()
+	history := []RunHistoryEntry{{ID: "20190304-151617-1"}, {ID: "20190304-151620-2"}, {ID: "20190304-151700-3"}}
+	for reference, expected := range map[string]int{
+		"last":              2,
+		"last~0":            2,
+		"last~2":            0,
+		"20190304-151620-2": 1,
+	} {
+		index, err := parseRunReference(history, reference)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, index, reference)
+	}
+	for _, reference := range []string{"last~3", "last~x", "last~-1", "20190304-000000-9"} {
+		_, err := parseRunReference(history, reference)
+		assert.NotNil(t, err, reference)
+	}
+}
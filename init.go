@@ -0,0 +1,18 @@
+package godev
+
+import (
+	"os"
+)
+
+// InitModeEnvironmentKey is the environment variable which marks a
+// godev that was started by a godev running as PID 1, whose output is
+// line buffered for the logs of the container
+const InitModeEnvironmentKey = "GODEV_INIT_CHILD"
+
+// popInitMode checks if this godev was started by a godev running as
+// PID 1 and removes the marker so that commands do not inherit it
+func popInitMode() bool {
+	_, ok := os.LookupEnv(InitModeEnvironmentKey)
+	os.Unsetenv(InitModeEnvironmentKey)
+	return ok
+}
@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+package godev
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// isInitProcess checks if godev is the init process of its PID
+// namespace, usually as the entrypoint of a container
+func isInitProcess() bool {
+	return os.Getpid() == 1
+}
+
+// runInit starts godev again with :arguments as a child process and
+// does what an init process has to until it exits - it forwards the
+// signals it receives to the child, since PID 1 ignores the signals it
+// does not handle, and reaps the orphaned processes which are
+// re-parented to it so that they do not linger as zombies - it returns
+// the exit code of the child
+func runInit(arguments []string, environment []string) int {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = arguments[0]
+	}
+	signals := make(chan os.Signal, 32)
+	signal.Notify(signals)
+	defer signal.Stop(signals)
+	cmd := exec.Command(executable)
+	cmd.Args = arguments
+	cmd.Env = append(environment, InitModeEnvironmentKey+"=1")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// the child is the foreground process group of the terminal of
+	// docker run -it so that it can read keys and receives ^C directly
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Foreground: isTerminal(os.Stdin)}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "godev could not be started as a child of the init process: %s\n", err)
+		return 1
+	}
+	for receivedSignal := range signals {
+		switch receivedSignal {
+		case syscall.SIGCHLD:
+			if exitCode, exited := reapProcesses(cmd.Process.Pid); exited {
+				return exitCode
+			}
+		case syscall.SIGURG, syscall.SIGTTIN, syscall.SIGTTOU:
+			// used by the go runtime and by job control, not for the child
+		default:
+			cmd.Process.Signal(receivedSignal)
+		}
+	}
+	return 0
+}
+
+// reapProcesses waits for every child process which exited, returning
+// the exit code of the process :childPID when it is one of them
+func reapProcesses(childPID int) (int, bool) {
+	exitCode, exited := 0, false
+	for {
+		var status syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+		if err == syscall.EINTR {
+			continue
+		} else if err != nil || pid <= 0 {
+			return exitCode, exited
+		} else if pid == childPID {
+			exitCode, exited = getWaitStatusExitCode(status), true
+		}
+	}
+}
+
+// getWaitStatusExitCode returns the exit code a shell would report for
+// :status, which is 128 plus the signal for processes killed by one
+func getWaitStatusExitCode(status syscall.WaitStatus) int {
+	if status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return status.ExitStatus()
+}
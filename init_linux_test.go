@@ -0,0 +1,25 @@
+//go:build linux
+// +build linux
+
+package godev
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func (s *InitTestSuite) Test_getWaitStatusExitCode() {
+	for script, expectedExitCode := range map[string]int{
+		"exit 0":        0,
+		"exit 3":        3,
+		"kill -TERM $$": 128 + int(syscall.SIGTERM),
+	} {
+		cmd := exec.Command("sh", "-c", script)
+		cmd.Run()
+		s.Equal(expectedExitCode, getWaitStatusExitCode(cmd.ProcessState.Sys().(syscall.WaitStatus)), script)
+	}
+}
+
+func (s *InitTestSuite) Test_isInitProcess() {
+	s.False(isInitProcess())
+}
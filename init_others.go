@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package godev
+
+// isInitProcess is only needed in linux containers
+func isInitProcess() bool {
+	return false
+}
+
+// runInit is only needed in linux containers
+func runInit(arguments []string, environment []string) int {
+	return 0
+}
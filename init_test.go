@@ -0,0 +1,24 @@
+package godev
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InitTestSuite struct {
+	suite.Suite
+}
+
+func TestInit(t *testing.T) {
+	suite.Run(t, new(InitTestSuite))
+}
+
+func (s *InitTestSuite) Test_popInitMode() {
+	os.Setenv(InitModeEnvironmentKey, "1")
+	s.True(popInitMode())
+	_, stillSet := os.LookupEnv(InitModeEnvironmentKey)
+	s.False(stillSet)
+	s.False(popInitMode())
+}
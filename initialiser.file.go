@@ -1,8 +1,7 @@
-package main
+package godev
 
 import (
 	"bufio"
-	"fmt"
 	"os"
 	"path"
 	"strings"
@@ -11,6 +10,7 @@ import (
 // FileInitialiserConfig holds the configuration for initialising the files
 type FileInitialiserConfig struct {
 	Data     []byte
+	Messages *Messages
 	Path     string
 	Question string
 }
@@ -22,6 +22,7 @@ func InitFileInitialiser(config *FileInitialiserConfig) *FileInitialiser {
 		Data:     config.Data,
 		Path:     config.Path,
 		Question: config.Question,
+		messages: config.Messages,
 		logger: InitLogger(&LoggerConfig{
 			Format: "raw",
 		}),
@@ -39,6 +40,7 @@ type FileInitialiser struct {
 	Question string
 	handler  func() error
 	logger   *Logger
+	messages *Messages
 }
 
 // Check verifies if the Question should be popped
@@ -49,10 +51,11 @@ func (fi FileInitialiser) Check() bool {
 // Confirm seeks advice from the user whether we should proceed
 func (fi FileInitialiser) Confirm(reader *bufio.Reader) bool {
 	return confirm(
+		fi.messages,
 		reader,
 		Color("white", "godev> "+fi.Question),
 		false,
-		Color("bold", Color("red", initialiserRetryText)),
+		Color("bold", Color("red", fi.messages.Get(MessageInitRetry))),
 	)
 }
 
@@ -65,9 +68,7 @@ func (fi *FileInitialiser) GetKey() string {
 func (fi FileInitialiser) Handle(skip ...bool) error {
 	if len(skip) > 0 && skip[0] {
 		fi.logger.Info(
-			Color("gray",
-				fmt.Sprintf("godev> skipping '%s' - already exists", path.Base(fi.Path)),
-			),
+			Color("gray", fi.messages.Get(MessageInitFileSkipped, path.Base(fi.Path))),
 		)
 		return nil
 	}
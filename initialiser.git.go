@@ -1,8 +1,7 @@
-package main
+package godev
 
 import (
 	"bufio"
-	"fmt"
 	"os"
 	"os/exec"
 	"path"
@@ -10,7 +9,8 @@ import (
 
 // GitInitialiserConfig holds the configurations for the GitInitialiser
 type GitInitialiserConfig struct {
-	Path string
+	Messages *Messages
+	Path     string
 }
 
 // InitGitInitialiser initialises the Git initialiser that assists in
@@ -22,15 +22,17 @@ func InitGitInitialiser(config *GitInitialiserConfig) *GitInitialiser {
 		logger: InitLogger(&LoggerConfig{
 			Format: "raw",
 		}),
+		messages: config.Messages,
 	}
 	return gi
 }
 
 // GitInitialiser assists in initialising a directory as a Git repository
 type GitInitialiser struct {
-	Key    string
-	Path   string
-	logger *Logger
+	Key      string
+	Path     string
+	logger   *Logger
+	messages *Messages
 }
 
 // Check verifies that the path exists
@@ -42,10 +44,11 @@ func (gi *GitInitialiser) Check() bool {
 // Git repository initialisation
 func (gi *GitInitialiser) Confirm(reader *bufio.Reader) bool {
 	return confirm(
+		gi.messages,
 		reader,
-		Color("white", fmt.Sprintf("godev> initialise git repository at '%s'?", gi.Path)),
+		Color("white", gi.messages.Get(MessageInitGitQuestion, gi.Path)),
 		false,
-		Color("bold", Color("red", initialiserRetryText)),
+		Color("bold", Color("red", gi.messages.Get(MessageInitRetry))),
 	)
 }
 
@@ -58,9 +61,7 @@ func (gi *GitInitialiser) GetKey() string {
 func (gi *GitInitialiser) Handle(skip ...bool) error {
 	if len(skip) > 0 && skip[0] {
 		gi.logger.Info(
-			Color("gray",
-				fmt.Sprintf("godev> skipping git repository initialisation at '%s'", gi.Path),
-			),
+			Color("gray", gi.messages.Get(MessageInitGitSkipped, gi.Path)),
 		)
 		return nil
 	}
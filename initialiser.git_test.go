@@ -1,9 +1,8 @@
-package main
+package godev
 
 import (
 	"bufio"
 	"bytes"
-	"fmt"
 	"os"
 	"path"
 	"strings"
@@ -79,10 +78,7 @@ func (s *GitInitialiserTestSuite) TestHandle_skip() {
 	assert.Contains(
 		s.T(),
 		s.logs.String(),
-		fmt.Sprintf(
-			"skipping git repository initialisation at '%s'",
-			s.pathWithGit,
-		),
+		s.gitInitialiser.messages.Get(MessageInitGitSkipped, s.pathWithGit),
 	)
 }
 
@@ -1,4 +1,4 @@
-package main
+package godev
 
 import (
 	"bufio"
@@ -12,5 +12,3 @@ type Initialiser interface {
 	GetKey() string
 	Handle(...bool) error
 }
-
-const initialiserRetryText = "godev> sorry, i didn't get that"
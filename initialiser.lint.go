@@ -0,0 +1,184 @@
+package godev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ScaffoldRule is a known issue of the Dockerfile or Makefile which init
+// seeded, or which was written after them
+type ScaffoldRule struct {
+	// ID identifies the rule in findings (eg. makefile-cgo-typo)
+	ID string
+	// FileName is the name of the file the rule checks
+	FileName    string
+	Description string
+	// Pattern matches the lines which have the issue
+	Pattern *regexp.Regexp
+	// Replacement replaces the matches of Pattern to fix the issue, lines
+	// which are left blank are removed
+	Replacement string
+	// Applies checks whether the submatches of Pattern are an issue, all
+	// matches are when it is nil
+	Applies func(submatches []string) bool
+}
+
+// ScaffoldFinding is a line of a file which has the issue of a rule
+type ScaffoldFinding struct {
+	Rule     *ScaffoldRule
+	FilePath string
+	// Line is the 1-based line number
+	Line int
+	Text string
+}
+
+func (finding ScaffoldFinding) String() string {
+	return fmt.Sprintf("%s:%v: %s (%s)", finding.FilePath, finding.Line, finding.Rule.Description, finding.Rule.ID)
+}
+
+// ScaffoldRules are the known issues of the Dockerfiles and Makefiles
+// which earlier versions of godev seeded
+var ScaffoldRules = []*ScaffoldRule{
+	{
+		ID:          "makefile-cgo-typo",
+		FileName:    "Makefile",
+		Description: "CGO_EMABLED is a typo of CGO_ENABLED which leaves cgo enabled and the binaries dynamically linked",
+		Pattern:     regexp.MustCompile(`\bCGO_EMABLED=`),
+		Replacement: "CGO_ENABLED=",
+	},
+	{
+		ID:          "makefile-build-all",
+		FileName:    "Makefile",
+		Description: "go build -a rebuilds every package on every build and defeats the build cache",
+		Pattern:     regexp.MustCompile(`(\bgo build\b.*?) -a\b`),
+		Replacement: "${1}",
+	},
+	{
+		ID:          "makefile-docker-target",
+		FileName:    "Makefile",
+		Description: "--target=production overrides --target ${STAGE} so that docker.dev builds the production image",
+		Pattern:     regexp.MustCompile(`^\s*--target=production\s*\\\s*$`),
+	},
+	{
+		ID:          "dockerfile-from-as-casing",
+		FileName:    "Dockerfile",
+		Description: "the 'as' keyword of FROM does not match the casing of FROM and docker build warns about it",
+		Pattern:     regexp.MustCompile(`^(FROM\s+\S+)\s+as(\s+)`),
+		Replacement: "${1} AS${2}",
+	},
+}
+
+// getGoVersionRule returns the rule which checks that the Go version of
+// the Dockerfile is at least :goVersion, the version which go.mod
+// requires
+func getGoVersionRule(goVersion string) *ScaffoldRule {
+	return &ScaffoldRule{
+		ID:          "dockerfile-golang-version",
+		FileName:    "Dockerfile",
+		Description: fmt.Sprintf("the golang image is older than go %s which go.mod requires", goVersion),
+		Pattern:     regexp.MustCompile(`^(ARG GOLANG_VERSION=)(\d+(?:\.\d+)*)\s*$`),
+		Replacement: "${1}" + goVersion,
+		Applies: func(submatches []string) bool {
+			return compareModuleVersions(submatches[2], goVersion) < 0
+		},
+	}
+}
+
+// GetScaffoldRules returns the rules for the files in :directory
+func GetScaffoldRules(directory string) []*ScaffoldRule {
+	rules := append([]*ScaffoldRule{}, ScaffoldRules...)
+	contents, err := ioutil.ReadFile(path.Join(directory, "go.mod"))
+	if err != nil {
+		return rules
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "go" {
+			rules = append(rules, getGoVersionRule(fields[1]))
+		}
+	}
+	return rules
+}
+
+// LintScaffold checks the Dockerfile and Makefile in :directory against
+// :rules and returns the findings by the paths of the files
+func LintScaffold(directory string, rules []*ScaffoldRule) (map[string][]ScaffoldFinding, error) {
+	findings := map[string][]ScaffoldFinding{}
+	for _, fileName := range []string{"Dockerfile", "Makefile"} {
+		filePath := path.Join(directory, fileName)
+		if fileName == "Makefile" {
+			filePath = FindMakefile(directory)
+		}
+		if len(filePath) == 0 || !fileExists(filePath) {
+			continue
+		}
+		fileFindings, err := LintScaffoldFile(filePath, fileName, rules)
+		if err != nil {
+			return nil, err
+		} else if len(fileFindings) > 0 {
+			findings[filePath] = fileFindings
+		}
+	}
+	return findings, nil
+}
+
+// LintScaffoldFile returns the lines of the file at :filePath which have
+// the issues of the :rules for files named :fileName
+func LintScaffoldFile(filePath string, fileName string, rules []*ScaffoldRule) ([]ScaffoldFinding, error) {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var findings []ScaffoldFinding
+	for index, line := range strings.Split(string(contents), "\n") {
+		for _, rule := range rules {
+			if rule.FileName == fileName && rule.matches(line) {
+				findings = append(findings, ScaffoldFinding{Rule: rule, FilePath: filePath, Line: index + 1, Text: line})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// FixScaffoldFile fixes the :findings of the file at :filePath
+func FixScaffoldFile(filePath string, findings []ScaffoldFinding) error {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(contents), "\n")
+	removed := map[int]bool{}
+	for _, finding := range findings {
+		index := finding.Line - 1
+		if index >= len(lines) || !finding.Rule.matches(lines[index]) {
+			continue
+		}
+		lines[index] = finding.Rule.Pattern.ReplaceAllString(lines[index], finding.Rule.Replacement)
+		if len(strings.TrimSpace(lines[index])) == 0 {
+			removed[index] = true
+		}
+	}
+	var fixed []string
+	for index, line := range lines {
+		if !removed[index] {
+			fixed = append(fixed, line)
+		}
+	}
+	return ioutil.WriteFile(filePath, []byte(strings.Join(fixed, "\n")), fileInfo.Mode())
+}
+
+// matches checks if :line has the issue of the rule
+func (rule *ScaffoldRule) matches(line string) bool {
+	submatches := rule.Pattern.FindStringSubmatch(line)
+	if submatches == nil {
+		return false
+	}
+	return rule.Applies == nil || rule.Applies(submatches)
+}
@@ -0,0 +1,114 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const scaffoldMakefileWithIssues = `.compile:
+	@CGO_EMABLED=0 GO111MODULE=on \
+		go build -a -ldflags "-extldflags -static" -o $(CURDIR)/bin/app
+.docker:
+	@docker build \
+		--target ${STAGE} \
+		--target=production \
+		.
+`
+
+const scaffoldDockerfileWithIssues = `ARG GOLANG_VERSION=1.11.5
+FROM golang:${GOLANG_VERSION}-alpine AS base
+FROM base as development
+`
+
+type InitialiserLintTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestInitialiserLint(t *testing.T) {
+	suite.Run(t, new(InitialiserLintTestSuite))
+}
+
+func (s *InitialiserLintTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-lint-scaffold")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+}
+
+func (s *InitialiserLintTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *InitialiserLintTestSuite) writeFile(name, contents string) {
+	assert.Nil(s.T(), ioutil.WriteFile(path.Join(s.directory, name), []byte(contents), 0644))
+}
+
+func (s *InitialiserLintTestSuite) readFile(name string) string {
+	contents, err := ioutil.ReadFile(path.Join(s.directory, name))
+	assert.Nil(s.T(), err)
+	return string(contents)
+}
+
+func (s *InitialiserLintTestSuite) TestLintScaffold() {
+	t := s.T()
+	s.writeFile("Makefile", scaffoldMakefileWithIssues)
+	s.writeFile("Dockerfile", scaffoldDockerfileWithIssues)
+	s.writeFile("go.mod", "module app\n\ngo 1.21\n")
+	findings, err := LintScaffold(s.directory, GetScaffoldRules(s.directory))
+	assert.Nil(t, err)
+	var ruleIDs []string
+	for _, finding := range append(findings[path.Join(s.directory, "Dockerfile")], findings[path.Join(s.directory, "Makefile")]...) {
+		ruleIDs = append(ruleIDs, finding.Rule.ID)
+	}
+	assert.Equal(t, []string{"dockerfile-golang-version", "dockerfile-from-as-casing", "makefile-cgo-typo", "makefile-build-all", "makefile-docker-target"}, ruleIDs)
+	assert.Equal(t, 7, findings[path.Join(s.directory, "Makefile")][2].Line)
+}
+
+func (s *InitialiserLintTestSuite) TestFixScaffoldFile() {
+	t := s.T()
+	s.writeFile("Makefile", scaffoldMakefileWithIssues)
+	s.writeFile("Dockerfile", scaffoldDockerfileWithIssues)
+	s.writeFile("go.mod", "module app\n\ngo 1.21\n")
+	findings, err := LintScaffold(s.directory, GetScaffoldRules(s.directory))
+	assert.Nil(t, err)
+	for filePath, fileFindings := range findings {
+		assert.Nil(t, FixScaffoldFile(filePath, fileFindings))
+	}
+	assert.Equal(t, `.compile:
+	@CGO_ENABLED=0 GO111MODULE=on \
+		go build -ldflags "-extldflags -static" -o $(CURDIR)/bin/app
+.docker:
+	@docker build \
+		--target ${STAGE} \
+		.
+`, s.readFile("Makefile"))
+	assert.Equal(t, "ARG GOLANG_VERSION=1.21\nFROM golang:${GOLANG_VERSION}-alpine AS base\nFROM base AS development\n", s.readFile("Dockerfile"))
+	findings, err = LintScaffold(s.directory, GetScaffoldRules(s.directory))
+	assert.Nil(t, err)
+	assert.Empty(t, findings)
+}
+
+func (s *InitialiserLintTestSuite) TestSeededFilesHaveNoIssues() {
+	t := s.T()
+	s.writeFile("Makefile", DataMakefile)
+	s.writeFile("Dockerfile", DataDockerfile)
+	s.writeFile("go.mod", DataGoDotMod)
+	assert.Len(t, GetScaffoldRules(s.directory), len(ScaffoldRules)+1, "expected the seeded go.mod to declare the go version of the seeded Dockerfile")
+	findings, err := LintScaffold(s.directory, GetScaffoldRules(s.directory))
+	assert.Nil(t, err)
+	assert.Empty(t, findings)
+}
+
+func (s *InitialiserLintTestSuite) Test_getGoVersionRule() {
+	t := s.T()
+	rule := getGoVersionRule("1.21")
+	assert.True(t, rule.matches("ARG GOLANG_VERSION=1.11.5"))
+	assert.False(t, rule.matches("ARG GOLANG_VERSION=1.22.1"))
+	assert.False(t, rule.matches("ARG GOLANG_VERSION=1.21"))
+	assert.False(t, rule.matches("ARG ALPINE_VERSION=1.9"))
+}
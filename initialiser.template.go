@@ -0,0 +1,88 @@
+package godev
+
+import (
+	"fmt"
+	"sort"
+)
+
+// dataMainDotgoCLI is the main.go seeded by `godev init --template cli`
+const dataMainDotgoCLI = `package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	name := flag.String("name", "world", "who to greet")
+	flag.Parse()
+	if flag.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected arguments: %v\n", flag.Args())
+		os.Exit(1)
+	}
+	fmt.Printf("hello %s!\n", *name)
+}
+`
+
+// dataMainDotgoService is the main.go seeded by `godev init --template service`
+const dataMainDotgoService = `package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	addr := ":8080"
+	if port := os.Getenv("PORT"); len(port) > 0 {
+		addr = ":" + port
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		log.Printf("listening on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+`
+
+// InitTemplates maps the names accepted by --template to the main.go
+// which the init sub-command seeds
+var InitTemplates = map[string]string{
+	"cli":     dataMainDotgoCLI,
+	"default": DataMainDotgo,
+	"service": dataMainDotgoService,
+}
+
+// getInitTemplate returns the main.go for the template named :name
+func getInitTemplate(name string) (string, error) {
+	mainDotGo, ok := InitTemplates[name]
+	if !ok {
+		var names []string
+		for templateName := range InitTemplates {
+			names = append(names, templateName)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("'%s' is not a known template (use one of %v)", name, names)
+	}
+	return mainDotGo, nil
+}
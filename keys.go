@@ -0,0 +1,171 @@
+package godev
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeyEnter is the key which is pressed without typing anything before it
+const KeyEnter = "enter"
+
+// KeyHelp is the key which lists the bound keys, it cannot be bound
+const KeyHelp = "?"
+
+// KeyActionClear clears the screen
+const KeyActionClear = "clear"
+
+// KeyActionRun runs its argument as a command in the work directory
+const KeyActionRun = "run"
+
+// KeyActionTrigger runs the pipeline
+const KeyActionTrigger = "trigger"
+
+// KeyActionTriggerGroup runs only the execution group its argument
+// selects by name or 1-based index
+const KeyActionTriggerGroup = "trigger-group"
+
+// KeyActionVerbose toggles between the configured log levels and debug
+const KeyActionVerbose = "verbose"
+
+// KeyBinding is the action a key is bound to
+type KeyBinding struct {
+	Action   string
+	Argument string
+}
+
+// String returns the binding as it is written in the configuration file
+func (binding *KeyBinding) String() string {
+	if len(binding.Argument) == 0 {
+		return binding.Action
+	}
+	return binding.Action + " " + binding.Argument
+}
+
+// GetDefaultKeyBindings returns the keys which are bound unless the
+// configuration file binds them to something else
+func GetDefaultKeyBindings() map[string]*KeyBinding {
+	return map[string]*KeyBinding{
+		KeyEnter: {Action: KeyActionTrigger},
+	}
+}
+
+// ParseKeyBinding parses an :action of the keys of the configuration
+// file such as "trigger-group test" or "run make migrate"
+func ParseKeyBinding(action string) (*KeyBinding, error) {
+	sections := strings.SplitN(strings.TrimSpace(action), " ", 2)
+	binding := &KeyBinding{Action: sections[0]}
+	if len(sections) > 1 {
+		binding.Argument = strings.TrimSpace(sections[1])
+	}
+	switch binding.Action {
+	case KeyActionClear, KeyActionTrigger, KeyActionVerbose:
+		if len(binding.Argument) > 0 {
+			return nil, fmt.Errorf("'%s' does not take an argument", binding.Action)
+		}
+	case KeyActionRun, KeyActionTriggerGroup:
+		if len(binding.Argument) == 0 {
+			return nil, fmt.Errorf("'%s' needs an argument", binding.Action)
+		}
+	default:
+		return nil, fmt.Errorf("'%s' is not one of %s", binding.Action, strings.Join([]string{KeyActionClear, KeyActionRun, KeyActionTrigger, KeyActionTriggerGroup, KeyActionVerbose}, ", "))
+	}
+	return binding, nil
+}
+
+// ParseKeyBindings parses the :keys of the configuration file, which map
+// keys to actions
+func ParseKeyBindings(keys map[string]string) (map[string]*KeyBinding, error) {
+	bindings := map[string]*KeyBinding{}
+	for key, action := range keys {
+		if len(key) == 0 || strings.IndexFunc(key, unicode.IsSpace) >= 0 {
+			return nil, fmt.Errorf("'%s' is not a valid key", key)
+		} else if key == KeyHelp {
+			return nil, fmt.Errorf("'%s' lists the keys and cannot be bound", KeyHelp)
+		}
+		binding, err := ParseKeyBinding(action)
+		if err != nil {
+			return nil, fmt.Errorf("key '%s': %s", key, err)
+		}
+		bindings[key] = binding
+	}
+	return bindings, nil
+}
+
+// KeyReaderConfig configures KeyReader
+type KeyReaderConfig struct {
+	Bindings map[string]*KeyBinding
+	Input    io.Reader
+	LogLevel LogLevel
+	RunState *RunState
+}
+
+// InitKeyReader creates a KeyReader which handles keys typed into the
+// terminal - a key is the line typed before enter is pressed
+func InitKeyReader(config *KeyReaderConfig) *KeyReader {
+	return &KeyReader{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:     "keys",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: config.RunState,
+		}),
+	}
+}
+
+// KeyReader reads the keys bound with the keys of the configuration
+// file and --manual
+type KeyReader struct {
+	config *KeyReaderConfig
+	logger *Logger
+}
+
+// Start begins reading keys from the input in the background, calling
+// :onKey with the binding of every bound key until the input ends - the
+// next key is read once :onKey returns
+func (reader *KeyReader) Start(onKey func(key string, binding *KeyBinding)) {
+	if binding, ok := reader.config.Bindings[KeyEnter]; ok && binding.Action == KeyActionTrigger {
+		reader.logger.Infof("press enter to run the pipeline, or type %s and press enter to list the keys", KeyHelp)
+	} else {
+		reader.logger.Infof("type %s and press enter to list the keys", KeyHelp)
+	}
+	go func() {
+		scanner := bufio.NewScanner(reader.config.Input)
+		for scanner.Scan() {
+			key := strings.TrimSpace(scanner.Text())
+			if len(key) == 0 {
+				key = KeyEnter
+			}
+			if key == KeyHelp {
+				reader.logger.Infof("keys: %s", reader.GetHelp())
+			} else if binding, ok := reader.config.Bindings[key]; ok {
+				reader.logger.Debugf("key '%s' pressed: %s", key, binding)
+				onKey(key, binding)
+			} else {
+				reader.logger.Warnf("'%s' is not bound to anything, the keys are: %s", key, reader.GetHelp())
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			reader.logger.Warnf("unable to read keys: %s", err)
+		}
+		reader.logger.Info("the input has ended - keys cannot be used anymore")
+	}()
+}
+
+// GetHelp lists the bound keys and their actions sorted by key
+func (reader *KeyReader) GetHelp() string {
+	var keys []string
+	for key := range reader.config.Bindings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var help []string
+	for _, key := range keys {
+		help = append(help, fmt.Sprintf("%s=%s", key, reader.config.Bindings[key]))
+	}
+	return strings.Join(help, ", ")
+}
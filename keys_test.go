@@ -0,0 +1,80 @@
+package godev
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type KeyReaderTestSuite struct {
+	suite.Suite
+	logs syncBuffer
+}
+
+func TestKeyReader(t *testing.T) {
+	suite.Run(t, new(KeyReaderTestSuite))
+}
+
+func (s *KeyReaderTestSuite) SetupTest() {
+	s.logs.Reset()
+}
+
+func (s *KeyReaderTestSuite) TestStart() {
+	t := s.T()
+	bindings := GetDefaultKeyBindings()
+	bindings["t"] = &KeyBinding{Action: KeyActionTriggerGroup, Argument: "test"}
+	reader := InitKeyReader(&KeyReaderConfig{
+		Bindings: bindings,
+		Input:    strings.NewReader("\n?\nx\n t \n"),
+	})
+	reader.logger.SetOutput(&s.logs)
+	pressed := make(chan string, 2)
+	reader.Start(func(key string, binding *KeyBinding) { pressed <- key + ":" + binding.String() })
+	for _, expected := range []string{"enter:trigger", "t:trigger-group test"} {
+		select {
+		case key := <-pressed:
+			assert.Equal(t, expected, key)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "expected bound keys to be handled", expected)
+		}
+	}
+	assert.Contains(t, s.logs.String(), "press enter to run the pipeline")
+	assert.Contains(t, s.logs.String(), "keys: enter=trigger, t=trigger-group test")
+	assert.Contains(t, s.logs.String(), "'x' is not bound to anything")
+}
+
+func (s *KeyReaderTestSuite) TestParseKeyBinding() {
+	t := s.T()
+	binding, err := ParseKeyBinding("run make migrate")
+	assert.Nil(t, err)
+	assert.Equal(t, &KeyBinding{Action: KeyActionRun, Argument: "make migrate"}, binding)
+	binding, err = ParseKeyBinding(" clear ")
+	assert.Nil(t, err)
+	assert.Equal(t, &KeyBinding{Action: KeyActionClear}, binding)
+	for _, action := range []string{"", "restart", "trigger now", "trigger-group", "run"} {
+		_, err := ParseKeyBinding(action)
+		assert.NotNilf(t, err, "expected '%s' to be invalid", action)
+	}
+}
+
+func (s *KeyReaderTestSuite) TestParseKeyBindings() {
+	t := s.T()
+	bindings, err := ParseKeyBindings(map[string]string{"enter": "verbose", "1": "trigger-group 1"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*KeyBinding{
+		"enter": &KeyBinding{Action: KeyActionVerbose},
+		"1":     &KeyBinding{Action: KeyActionTriggerGroup, Argument: "1"},
+	}, bindings)
+	for _, keys := range []map[string]string{
+		{"?": "trigger"},
+		{"a b": "trigger"},
+		{"": "trigger"},
+		{"t": "test"},
+	} {
+		_, err := ParseKeyBindings(keys)
+		assert.NotNilf(t, err, "expected %v to be invalid", keys)
+	}
+}
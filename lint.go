@@ -0,0 +1,98 @@
+package godev
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// lintFindingPattern matches lines like 'pkg/file.go:12:3: message (linter)'
+var lintFindingPattern = regexp.MustCompile(`^\s*(\S+?\.go):(\d+)(?::(\d+))?:\s*(.+?)(?:\s+\((\w[\w-]*)\))?\s*$`)
+
+// lintApplications are applications whose output is scanned for findings
+var lintApplications = []string{"golangci-lint", "golint", "staticcheck", "revive", "errcheck"}
+
+// LintFinding is a single issue reported by a vet/lint tool
+type LintFinding struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Linter  string
+}
+
+// String returns the finding in the conventional file:line:column format
+func (finding *LintFinding) String() string {
+	location := fmt.Sprintf("%s:%v", finding.File, finding.Line)
+	if finding.Column > 0 {
+		location = fmt.Sprintf("%s:%v", location, finding.Column)
+	}
+	if len(finding.Linter) > 0 {
+		return fmt.Sprintf("%s: %s (%s)", location, finding.Message, finding.Linter)
+	}
+	return fmt.Sprintf("%s: %s", location, finding.Message)
+}
+
+// ParseLintFinding attempts to parse :line as a vet/lint finding
+func ParseLintFinding(line string) (*LintFinding, bool) {
+	matches := lintFindingPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, false
+	}
+	finding := &LintFinding{
+		File:    matches[1],
+		Message: matches[4],
+		Linter:  matches[5],
+	}
+	finding.Line, _ = strconv.Atoi(matches[2])
+	finding.Column, _ = strconv.Atoi(matches[3])
+	return finding, true
+}
+
+// isLintCommand checks whether :application with :arguments is a
+// vet/lint step whose output should be scanned for findings
+func isLintCommand(application string, arguments []string) bool {
+	name := path.Base(application)
+	if name == "go" {
+		return len(arguments) > 0 && arguments[0] == "vet"
+	}
+	return sliceContainsString(lintApplications, name)
+}
+
+// LintFindings is a thread-safe collection of findings
+type LintFindings struct {
+	findings []*LintFinding
+	mutex    sync.Mutex
+}
+
+// Add records :finding
+func (lf *LintFindings) Add(finding *LintFinding) {
+	lf.mutex.Lock()
+	defer lf.mutex.Unlock()
+	lf.findings = append(lf.findings, finding)
+}
+
+// Count returns the number of recorded findings
+func (lf *LintFindings) Count() int {
+	lf.mutex.Lock()
+	defer lf.mutex.Unlock()
+	return len(lf.findings)
+}
+
+// Reset clears all recorded findings
+func (lf *LintFindings) Reset() {
+	lf.mutex.Lock()
+	defer lf.mutex.Unlock()
+	lf.findings = nil
+}
+
+// Badge returns a short summary of the findings for display
+func (lf *LintFindings) Badge() string {
+	count := lf.Count()
+	if count == 1 {
+		return "⚠ 1 warning"
+	}
+	return fmt.Sprintf("⚠ %v warnings", count)
+}
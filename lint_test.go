@@ -0,0 +1,71 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LintTestSuite struct {
+	suite.Suite
+}
+
+func TestLint(t *testing.T) {
+	suite.Run(t, new(LintTestSuite))
+}
+
+func (s *LintTestSuite) TestParseLintFinding_vet() {
+	t := s.T()
+	finding, ok := ParseLintFinding("./main.go:12:3: Printf format %d has arg of wrong type")
+	assert.True(t, ok)
+	assert.Equal(t, "./main.go", finding.File)
+	assert.Equal(t, 12, finding.Line)
+	assert.Equal(t, 3, finding.Column)
+	assert.Equal(t, "Printf format %d has arg of wrong type", finding.Message)
+	assert.Equal(t, "", finding.Linter)
+}
+
+func (s *LintTestSuite) TestParseLintFinding_golangciLint() {
+	t := s.T()
+	finding, ok := ParseLintFinding("pkg/api/server.go:40:2: ineffectual assignment to `err` (ineffassign)")
+	assert.True(t, ok)
+	assert.Equal(t, "pkg/api/server.go", finding.File)
+	assert.Equal(t, "ineffassign", finding.Linter)
+	assert.Equal(t, "pkg/api/server.go:40:2: ineffectual assignment to `err` (ineffassign)", finding.String())
+}
+
+func (s *LintTestSuite) TestParseLintFinding_withoutColumn() {
+	finding, ok := ParseLintFinding("main.go:7: exported function Run should have comment")
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), "main.go:7: exported function Run should have comment", finding.String())
+}
+
+func (s *LintTestSuite) TestParseLintFinding_withOtherOutput() {
+	t := s.T()
+	_, ok := ParseLintFinding("# github.com/zephinzer/godev")
+	assert.False(t, ok)
+	_, ok = ParseLintFinding("ok  	github.com/zephinzer/godev	0.721s")
+	assert.False(t, ok)
+}
+
+func (s *LintTestSuite) Test_isLintCommand() {
+	t := s.T()
+	assert.True(t, isLintCommand("go", []string{"vet", "./..."}))
+	assert.True(t, isLintCommand("/usr/local/bin/golangci-lint", []string{"run"}))
+	assert.False(t, isLintCommand("go", []string{"build"}))
+	assert.False(t, isLintCommand("go", []string{}))
+	assert.False(t, isLintCommand("echo", []string{"vet"}))
+}
+
+func (s *LintTestSuite) TestLintFindings() {
+	t := s.T()
+	findings := &LintFindings{}
+	assert.Equal(t, "⚠ 0 warnings", findings.Badge())
+	findings.Add(&LintFinding{File: "a.go", Line: 1})
+	assert.Equal(t, "⚠ 1 warning", findings.Badge())
+	findings.Add(&LintFinding{File: "b.go", Line: 2})
+	assert.Equal(t, 2, findings.Count())
+	findings.Reset()
+	assert.Equal(t, 0, findings.Count())
+}
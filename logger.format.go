@@ -1,7 +1,8 @@
-package main
+package godev
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -53,6 +54,9 @@ func (f *productionFormat) Format(entry *logrus.Entry) ([]byte, error) {
 	var submoduleLabel string
 	timestamp := entry.Time.Format("Jan02/15:04")
 	data := entry.Data
+	if tag, ok := data[RunTagKey]; ok {
+		timestamp = fmt.Sprintf("%s|%v", timestamp, tag)
+	}
 	if data["module"] != nil {
 		moduleLabel = fmt.Sprintf("%v", data["module"])
 	}
@@ -63,7 +67,7 @@ func (f *productionFormat) Format(entry *logrus.Entry) ([]byte, error) {
 	if entry.Level > logrus.InfoLevel {
 		var otherKeys string
 		for key, value := range data {
-			if key != "module" && key != "submodule" {
+			if key != "module" && key != "submodule" && key != RunTagKey {
 				otherKeys = fmt.Sprintf("%s\n  %s: %v", otherKeys, key, value)
 			}
 		}
@@ -73,3 +77,19 @@ func (f *productionFormat) Format(entry *logrus.Entry) ([]byte, error) {
 	log := []byte(Color(color, fmt.Sprintf("%s\n", message)))
 	return log, nil
 }
+
+// plainFormat is the production format without colours and with the
+// date, for destinations which are not terminals such as files
+type plainFormat struct{}
+
+func (f *plainFormat) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(fmt.Sprintf("%s %-5s [%s] %s\n", entry.Time.Format(time.RFC3339), entry.Level.String(), getEntryModule(entry), entry.Message)), nil
+}
+
+// discardFormat formats nothing for loggers which write to their sinks
+// instead of their output
+type discardFormat struct{}
+
+func (f *discardFormat) Format(entry *logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
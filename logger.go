@@ -1,7 +1,10 @@
-package main
+package godev
 
 import (
-	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 
 	"github.com/sirupsen/logrus"
 )
@@ -12,12 +15,19 @@ type LoggerConfig struct {
 	Format           LogFormat
 	Level            LogLevel
 	AdditionalFields *map[string]interface{}
+	// Outputs are written to in addition to stderr (or the writer set
+	// with SetOutput) and the outputs added to the RunState
+	Outputs []LoggerSink
+	// RunState tags the log lines with its pipeline and adds its outputs,
+	// it defaults to DefaultRunState
+	RunState *RunState
 }
 
 // InitLogger is used for setting up a new logger for a component
 func InitLogger(config *LoggerConfig) *Logger {
 	log := logrus.New()
-	log.SetFormatter(config.Format.Get())
+	log.SetOutput(ioutil.Discard)
+	log.SetFormatter(new(discardFormat))
 	log.SetLevel(config.Level.Get())
 	fields := logrus.Fields{
 		"module": config.Name,
@@ -31,7 +41,9 @@ func InitLogger(config *LoggerConfig) *Logger {
 		config:      config,
 		instanceRaw: log,
 		instance:    log.WithFields(fields),
+		output:      &WriterSink{Writer: config.RunState.get().StatusLine.Wrap(os.Stderr), Formatter: config.Format.Get()},
 	}
+	log.AddHook(&loggerSinkHook{logger: logger})
 	return logger
 }
 
@@ -40,81 +52,125 @@ type Logger struct {
 	config      *LoggerConfig
 	instance    *logrus.Entry
 	instanceRaw *logrus.Logger
+	// output is where the entries are written to before the Outputs of
+	// the configuration
+	output *WriterSink
 }
 
-// SetOutput exists for characterisation testing
-func (l *Logger) SetOutput(buffer *bytes.Buffer) {
-	l.instanceRaw.SetOutput(buffer)
+// getInstance returns the entry to log with after applying the
+// verbosity toggled with RunState.ToggleVerboseLogs
+func (l *Logger) getInstance() *logrus.Entry {
+	level := l.config.Level.Get()
+	if l.config.RunState.isVerbose() && level < logrus.DebugLevel {
+		level = logrus.DebugLevel
+	}
+	if l.instanceRaw.GetLevel() != level {
+		l.instanceRaw.SetLevel(level)
+	}
+	return l.instance
+}
+
+// SetOutput changes where logs are written to instead of stderr, also
+// used for characterisation testing, the other outputs are kept
+func (l *Logger) SetOutput(writer io.Writer) {
+	l.output.setWriter(writer)
+}
+
+// getSinks returns every sink that the entries of the logger are
+// written to
+func (l *Logger) getSinks() []LoggerSink {
+	sinks := append([]LoggerSink{l.output}, l.config.Outputs...)
+	return append(sinks, l.config.RunState.getLoggerOutputs()...)
+}
+
+// tagEntry returns :entry with the run tag of the RunState of the logger
+// under RunTagKey, :entry itself when there is no tag
+func (l *Logger) tagEntry(entry *logrus.Entry) *logrus.Entry {
+	tag := l.config.RunState.get().RunTags.Get()
+	if len(tag) == 0 {
+		return entry
+	}
+	tagged := *entry
+	tagged.Data = logrus.Fields{RunTagKey: tag}
+	for key, value := range entry.Data {
+		tagged.Data[key] = value
+	}
+	return &tagged
+}
+
+// Log logs at the provided :level
+func (l *Logger) Log(level LogLevel, log ...interface{}) {
+	l.getInstance().Log(level.Get(), log...)
 }
 
 // Trace logs at the trace level
 func (l *Logger) Trace(log ...interface{}) {
-	l.instance.Trace(log...)
+	l.getInstance().Trace(log...)
 }
 
 // Tracef logs at the trace level with formatting
 func (l *Logger) Tracef(format string, log ...interface{}) {
-	l.instance.Tracef(format, log...)
+	l.getInstance().Tracef(format, log...)
 }
 
 // Debug logs at the debug level
 func (l *Logger) Debug(log ...interface{}) {
-	l.instance.Debug(log...)
+	l.getInstance().Debug(log...)
 }
 
 // Debugf logs at the debug level with formatting
 func (l *Logger) Debugf(format string, log ...interface{}) {
-	l.instance.Debugf(format, log...)
+	l.getInstance().Debugf(format, log...)
 }
 
 // Info logs at the info level
 func (l *Logger) Info(log ...interface{}) {
-	l.instance.Info(log...)
+	l.getInstance().Info(log...)
 }
 
 // Infof logs at the info level with formatting
 func (l *Logger) Infof(format string, log ...interface{}) {
-	l.instance.Infof(format, log...)
+	l.getInstance().Infof(format, log...)
 }
 
 // Warn logs at the warn level
 func (l *Logger) Warn(log ...interface{}) {
-	l.instance.Warn(log...)
+	l.getInstance().Warn(log...)
 }
 
 // Warnf logs at the warn level with formatting
 func (l *Logger) Warnf(format string, log ...interface{}) {
-	l.instance.Warnf(format, log...)
+	l.getInstance().Warnf(format, log...)
 }
 
 // Error logs at the error level
 func (l *Logger) Error(log ...interface{}) {
-	l.instance.Error(log...)
+	l.getInstance().Error(log...)
 }
 
 // Errorf logs at the error level with formatting
 func (l *Logger) Errorf(format string, log ...interface{}) {
-	l.instance.Errorf(format, log...)
+	l.getInstance().Errorf(format, log...)
 }
 
 // Fatal logs at the fatal level
 func (l *Logger) Fatal(log ...interface{}) {
-	l.instance.Fatal(log...)
+	l.getInstance().Fatal(log...)
 }
 
 // Fatalf logs at the fatal level with formatting
 func (l *Logger) Fatalf(format string, log ...interface{}) {
-	l.instance.Fatalf(format, log...)
+	l.getInstance().Fatalf(format, log...)
 }
 
 // Panic logs at the panic level
 func (l *Logger) Panic(log ...interface{}) {
-	l.instance.Panic(log...)
+	l.getInstance().Panic(log...)
 }
 
 // Panicf logs at the panic level with formatting
 func (l *Logger) Panicf(format string, log ...interface{}) {
-	l.instance.Panicf(format, log...)
+	l.getInstance().Panicf(format, log...)
 }
 
 // LogLevel is a string represent of the log level
@@ -125,6 +181,15 @@ func (ll *LogLevel) String() string {
 	return string(*ll)
 }
 
+// IsValid checks that the LogLevel is one that logrus supports
+func (ll *LogLevel) IsValid() error {
+	switch *ll {
+	case "trace", "debug", "info", "warn", "error", "fatal", "panic":
+		return nil
+	}
+	return fmt.Errorf("'%s' is not a valid log level (use one of trace, debug, info, warn, error, fatal, panic)", *ll)
+}
+
 // Get retrieves the LogLevel for logrus to use
 func (ll *LogLevel) Get() logrus.Level {
 	switch *ll {
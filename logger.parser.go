@@ -0,0 +1,200 @@
+package godev
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LogParser represents the possible formats that child process
+// output can be parsed from
+type LogParser string
+
+const (
+	// LogParserNone indicates child output is passed through as-is
+	LogParserNone LogParser = ""
+	// LogParserJSON parses lines like {"level":"info","msg":"hello"}
+	LogParserJSON LogParser = "json"
+	// LogParserLogfmt parses lines like level=info msg="hello"
+	LogParserLogfmt LogParser = "logfmt"
+)
+
+// logParserLevelKeys are keys commonly used by logging libraries to
+// store the level of a log line
+var logParserLevelKeys = []string{"level", "lvl", "severity"}
+
+// logParserMessageKeys are keys commonly used by logging libraries to
+// store the message of a log line
+var logParserMessageKeys = []string{"msg", "message"}
+
+// logParserOmittedKeys are keys dropped when re-rendering because godev
+// adds its own timestamp
+var logParserOmittedKeys = []string{"time", "ts", "timestamp"}
+
+// logParserLevelAliases maps level names used by logging libraries to
+// the ones godev understands
+var logParserLevelAliases = map[string]LogLevel{
+	"trace":    "trace",
+	"debug":    "debug",
+	"info":     "info",
+	"notice":   "info",
+	"warn":     "warn",
+	"warning":  "warn",
+	"err":      "error",
+	"error":    "error",
+	"critical": "error",
+	"dpanic":   "error",
+	"fatal":    "fatal",
+	"panic":    "panic",
+}
+
+// ParsedLogLine is a structured representation of a line of
+// child process output
+type ParsedLogLine struct {
+	Level   LogLevel
+	Message string
+	Fields  map[string]string
+}
+
+// String renders the message followed by the sorted fields
+// in logfmt style
+func (line *ParsedLogLine) String() string {
+	keys := make([]string, 0, len(line.Fields))
+	for key := range line.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	rendered := []string{line.Message}
+	for _, key := range keys {
+		value := line.Fields[key]
+		if strings.ContainsAny(value, " \"=") {
+			value = strconv.Quote(value)
+		}
+		rendered = append(rendered, fmt.Sprintf("%s=%s", key, value))
+	}
+	return strings.TrimSpace(strings.Join(rendered, " "))
+}
+
+// IsValid checks if the parser is one that godev supports
+func (lp LogParser) IsValid() error {
+	switch lp {
+	case LogParserNone, LogParserJSON, LogParserLogfmt:
+		return nil
+	}
+	return fmt.Errorf("'%s' is not a supported log format (use one of: %s, %s)", lp, LogParserJSON, LogParserLogfmt)
+}
+
+// Parse attempts to parse :line, returning false if the line
+// is not in the parser's format
+func (lp LogParser) Parse(line string) (*ParsedLogLine, bool) {
+	var fields map[string]string
+	switch lp {
+	case LogParserJSON:
+		fields = parseJSONLogLine(line)
+	case LogParserLogfmt:
+		fields = parseLogfmtLogLine(line)
+	}
+	if fields == nil {
+		return nil, false
+	}
+	parsed := &ParsedLogLine{Level: "info", Fields: fields}
+	foundKey := false
+	for _, key := range logParserLevelKeys {
+		if value, ok := fields[key]; ok {
+			if level, ok := logParserLevelAliases[strings.ToLower(value)]; ok {
+				parsed.Level = level
+			}
+			delete(fields, key)
+			foundKey = true
+			break
+		}
+	}
+	for _, key := range logParserMessageKeys {
+		if value, ok := fields[key]; ok {
+			parsed.Message = value
+			delete(fields, key)
+			foundKey = true
+			break
+		}
+	}
+	if !foundKey {
+		return nil, false
+	}
+	for _, key := range logParserOmittedKeys {
+		delete(fields, key)
+	}
+	return parsed, true
+}
+
+func parseJSONLogLine(line string) map[string]string {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &raw); err != nil {
+		return nil
+	}
+	fields := map[string]string{}
+	for key, value := range raw {
+		switch typedValue := value.(type) {
+		case string:
+			fields[key] = typedValue
+		case nil:
+			fields[key] = "null"
+		case map[string]interface{}, []interface{}:
+			encoded, _ := json.Marshal(typedValue)
+			fields[key] = string(encoded)
+		default:
+			fields[key] = fmt.Sprintf("%v", typedValue)
+		}
+	}
+	return fields
+}
+
+func parseLogfmtLogLine(line string) map[string]string {
+	fields := map[string]string{}
+	remaining := strings.TrimSpace(line)
+	for len(remaining) > 0 {
+		equalsIndex := strings.IndexAny(remaining, "= ")
+		if equalsIndex <= 0 || remaining[equalsIndex] != '=' {
+			return nil
+		}
+		key := remaining[:equalsIndex]
+		remaining = remaining[equalsIndex+1:]
+		var value string
+		if strings.HasPrefix(remaining, "\"") {
+			closingIndex := 1
+			for ; closingIndex < len(remaining); closingIndex++ {
+				if remaining[closingIndex] == '\\' {
+					closingIndex++
+				} else if remaining[closingIndex] == '"' {
+					break
+				}
+			}
+			if closingIndex >= len(remaining) {
+				return nil
+			}
+			unquoted, err := strconv.Unquote(remaining[:closingIndex+1])
+			if err != nil {
+				return nil
+			}
+			value = unquoted
+			remaining = remaining[closingIndex+1:]
+		} else if spaceIndex := strings.Index(remaining, " "); spaceIndex >= 0 {
+			value = remaining[:spaceIndex]
+			remaining = remaining[spaceIndex:]
+		} else {
+			value = remaining
+			remaining = ""
+		}
+		fields[key] = value
+		remaining = strings.TrimLeft(remaining, " ")
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
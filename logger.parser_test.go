@@ -0,0 +1,66 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogParserTestSuite struct {
+	suite.Suite
+}
+
+func TestLogParser(t *testing.T) {
+	suite.Run(t, new(LogParserTestSuite))
+}
+
+func (s *LogParserTestSuite) TestIsValid() {
+	t := s.T()
+	assert.Nil(t, LogParser("").IsValid())
+	assert.Nil(t, LogParser("json").IsValid())
+	assert.Nil(t, LogParser("logfmt").IsValid())
+	assert.NotNil(t, LogParser("xml").IsValid())
+}
+
+func (s *LogParserTestSuite) TestParse_json() {
+	t := s.T()
+	parsed, ok := LogParserJSON.Parse(`{"level":"warning","ts":1554000000.1,"msg":"disk almost full","used":0.93,"mount":"/data"}`)
+	assert.True(t, ok)
+	assert.Equal(t, LogLevel("warn"), parsed.Level)
+	assert.Equal(t, "disk almost full", parsed.Message)
+	assert.Equal(t, map[string]string{"used": "0.93", "mount": "/data"}, parsed.Fields)
+	assert.Equal(t, "disk almost full mount=/data used=0.93", parsed.String())
+}
+
+func (s *LogParserTestSuite) TestParse_jsonWithoutKnownKeys() {
+	_, ok := LogParserJSON.Parse(`{"id":1}`)
+	assert.False(s.T(), ok)
+}
+
+func (s *LogParserTestSuite) TestParse_jsonWithPlainText() {
+	_, ok := LogParserJSON.Parse(`listening on :8080`)
+	assert.False(s.T(), ok)
+}
+
+func (s *LogParserTestSuite) TestParse_logfmt() {
+	t := s.T()
+	parsed, ok := LogParserLogfmt.Parse(`time=2019-04-01T00:00:00Z level=error msg="could not connect: \"db\"" attempt=3`)
+	assert.True(t, ok)
+	assert.Equal(t, LogLevel("error"), parsed.Level)
+	assert.Equal(t, `could not connect: "db"`, parsed.Message)
+	assert.Equal(t, map[string]string{"attempt": "3"}, parsed.Fields)
+}
+
+func (s *LogParserTestSuite) TestParse_logfmtWithPlainText() {
+	t := s.T()
+	_, ok := LogParserLogfmt.Parse(`listening on :8080`)
+	assert.False(t, ok)
+	_, ok = LogParserLogfmt.Parse(`msg="unterminated`)
+	assert.False(t, ok)
+}
+
+func (s *LogParserTestSuite) TestParse_none() {
+	_, ok := LogParserNone.Parse(`level=info msg=hi`)
+	assert.False(s.T(), ok)
+}
@@ -0,0 +1,114 @@
+package godev
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RunTagKey is the field of the log entries which holds their run tag
+const RunTagKey = "run"
+
+// RunTagPattern matches the tag at the start of a log line which has had
+// its colours removed, the first group is the timestamp of godev's own
+// log lines and the second is the run
+var RunTagPattern = regexp.MustCompile(`^(\|[^|]*\|)?run=(\d+)\+[^|]*\|`)
+
+// colorCodePattern matches the terminal colour codes added by Color,
+// including the ColorStub it puts in front of them
+var colorCodePattern = regexp.MustCompile("\x1b\\[([0-9;]*m)?")
+
+// RunTagger keeps track of the current pipeline for tagging the log lines
+// of godev and of the commands with the pipeline they belong to when
+// --tag-runs is specified, every RunState has its own
+type RunTagger struct {
+	enabled   bool
+	run       int
+	startedAt time.Time
+	mutex     sync.Mutex
+}
+
+// Enable turns tagging on
+func (tagger *RunTagger) Enable() {
+	tagger.mutex.Lock()
+	defer tagger.mutex.Unlock()
+	tagger.enabled = true
+}
+
+// IsEnabled checks whether log lines are being tagged
+func (tagger *RunTagger) IsEnabled() bool {
+	tagger.mutex.Lock()
+	defer tagger.mutex.Unlock()
+	return tagger.enabled
+}
+
+// Start marks the start of pipeline :run, the time in the tags of its
+// log lines is relative to it
+func (tagger *RunTagger) Start(run int) {
+	tagger.mutex.Lock()
+	defer tagger.mutex.Unlock()
+	tagger.run = run
+	tagger.startedAt = time.Now()
+}
+
+// Get returns the tag of a log line written now in the form
+// "run=42+1.204s", it is empty when tagging is off or before the first
+// pipeline
+func (tagger *RunTagger) Get() string {
+	tagger.mutex.Lock()
+	defer tagger.mutex.Unlock()
+	if !tagger.enabled || tagger.run == 0 {
+		return ""
+	}
+	return fmt.Sprintf("run=%v+%s", tagger.run, time.Since(tagger.startedAt).Round(time.Millisecond))
+}
+
+// getLineRun returns the run in the tag of :line and whether it has one
+func getLineRun(line string) (int, bool) {
+	matches := RunTagPattern.FindStringSubmatch(colorCodePattern.ReplaceAllString(line, ""))
+	if matches == nil {
+		return 0, false
+	}
+	run, err := strconv.Atoi(matches[2])
+	return run, err == nil
+}
+
+// scanRunLogs calls :handle with every line of :reader and the run it
+// belongs to, lines without a tag belong to the run of the tagged line
+// before them since they continue it - lines before the first tag belong
+// to run 0
+func scanRunLogs(reader io.Reader, handle func(run int, line string)) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	currentRun := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if lineRun, ok := getLineRun(line); ok {
+			currentRun = lineRun
+		}
+		handle(currentRun, line)
+	}
+	return scanner.Err()
+}
+
+// countRunLogs returns the runs in :reader in ascending order with the
+// number of lines belonging to each of them
+func countRunLogs(reader io.Reader) ([]int, map[int]int, error) {
+	counts := map[int]int{}
+	err := scanRunLogs(reader, func(run int, line string) {
+		if run > 0 {
+			counts[run]++
+		}
+	})
+	var runs []int
+	for run := range counts {
+		runs = append(runs, run)
+	}
+	sort.Ints(runs)
+	return runs, counts, err
+}
@@ -0,0 +1,94 @@
+package godev
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RunTaggerTestSuite struct {
+	suite.Suite
+	state *RunState
+	logs  bytes.Buffer
+}
+
+func TestRunTagger(t *testing.T) {
+	suite.Run(t, new(RunTaggerTestSuite))
+}
+
+func (s *RunTaggerTestSuite) SetupTest() {
+	s.state = InitRunState()
+	s.logs.Reset()
+}
+
+func (s *RunTaggerTestSuite) TestGet() {
+	t := s.T()
+	tagger := &RunTagger{}
+	tagger.Start(3)
+	assert.Empty(t, tagger.Get(), "expected no tags unless --tag-runs is specified")
+	tagger = &RunTagger{}
+	tagger.Enable()
+	assert.Empty(t, tagger.Get(), "expected no tags before the first run")
+	tagger.Start(42)
+	assert.Regexp(t, `^run=42\+\d+(\.\d+)?m?s$`, tagger.Get())
+}
+
+func (s *RunTaggerTestSuite) TestTaggedLines() {
+	t := s.T()
+	s.state.RunTags.Enable()
+	s.state.SetPipeline(6)
+	assert.Equal(t, 7, s.state.StartPipeline())
+	logger := InitLogger(&LoggerConfig{Name: "runner", Format: "production", Level: "trace", RunState: s.state})
+	logger.SetOutput(&s.logs)
+	logger.Info("starting pipeline")
+	output := InitCommandOutput(&CommandOutputConfig{Name: "app", Level: "trace", Writer: &s.logs, RunState: s.state})
+	output.Write([]byte("listening on :8080\n"))
+	lines := strings.Split(strings.TrimSpace(s.logs.String()), "\n")
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		run, ok := getLineRun(line)
+		assert.Truef(t, ok, "expected '%s' to be tagged", line)
+		assert.Equal(t, 7, run)
+	}
+	assert.Contains(t, lines[0], "[runner] starting pipeline")
+	assert.Regexp(t, `^run=7\+[^|]+\| listening on :8080$`, colorCodePattern.ReplaceAllString(lines[1], ""))
+}
+
+func (s *RunTaggerTestSuite) TestTaggedLines_ofAnotherRunState() {
+	t := s.T()
+	s.state.RunTags.Enable()
+	s.state.StartPipeline()
+	logger := InitLogger(&LoggerConfig{Name: "runner", Format: "production", Level: "trace", RunState: InitRunState()})
+	logger.SetOutput(&s.logs)
+	logger.Info("starting pipeline")
+	_, ok := getLineRun(s.logs.String())
+	assert.False(t, ok, "expected the lines of another RunState not to be tagged")
+}
+
+func (s *RunTaggerTestSuite) Test_scanRunLogs() {
+	t := s.T()
+	log := strings.Join([]string{
+		"|Jan02/15:04| [main] godev has started",
+		"|Jan02/15:04|run=1+0s| [runner] starting pipeline",
+		"run=1+1.5s| compiled",
+		"a continued line",
+		Color("green", "|Jan02/15:04|run=2+0s| [runner] starting pipeline"),
+		"run=2+20ms| " + Color("yellow", "main.go:1:1: unused"),
+		"run=10+0s| a later run",
+	}, "\n")
+	runs := map[int][]string{}
+	assert.Nil(t, scanRunLogs(strings.NewReader(log), func(run int, line string) {
+		runs[run] = append(runs[run], line)
+	}))
+	assert.Equal(t, []string{"|Jan02/15:04| [main] godev has started"}, runs[0])
+	assert.Equal(t, []string{"|Jan02/15:04|run=1+0s| [runner] starting pipeline", "run=1+1.5s| compiled", "a continued line"}, runs[1])
+	assert.Len(t, runs[2], 2)
+	assert.Len(t, runs[10], 1)
+	ids, counts, err := countRunLogs(strings.NewReader(log))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 10}, ids)
+	assert.Equal(t, map[int]int{1: 3, 2: 2, 10: 1}, counts)
+}
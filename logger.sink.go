@@ -0,0 +1,185 @@
+package godev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoggerSink is a destination of the entries of loggers, each sink
+// formats the entries as its destination expects them
+type LoggerSink interface {
+	WriteEntry(entry *logrus.Entry) error
+}
+
+// WriterSink writes entries formatted with Formatter to Writer
+type WriterSink struct {
+	Writer    io.Writer
+	Formatter logrus.Formatter
+	// Closer is closed with CloseLoggerOutputs when it is set, eg. the
+	// file that Writer writes to
+	Closer io.Closer
+	mutex  sync.Mutex
+}
+
+// WriteEntry implements LoggerSink
+func (sink *WriterSink) WriteEntry(entry *logrus.Entry) error {
+	line, err := sink.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	_, err = sink.Writer.Write(line)
+	return err
+}
+
+// setWriter changes the writer of the sink
+func (sink *WriterSink) setWriter(writer io.Writer) {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	sink.Writer = writer
+}
+
+// Close implements io.Closer
+func (sink *WriterSink) Close() error {
+	if sink.Closer == nil {
+		return nil
+	}
+	return sink.Closer.Close()
+}
+
+// ParseLoggerOutput returns the sink for :output which is one of stdout,
+// stderr, syslog (the local syslog), syslog://host:port (udp),
+// syslog+tcp://host:port, journald, or the path of a file which entries
+// are appended to as plain text
+func ParseLoggerOutput(output string) (LoggerSink, error) {
+	switch {
+	case output == "stdout":
+		return &WriterSink{Writer: os.Stdout, Formatter: new(productionFormat)}, nil
+	case output == "stderr":
+		return &WriterSink{Writer: os.Stderr, Formatter: new(productionFormat)}, nil
+	case output == "syslog":
+		return initSyslogSink("", "")
+	case strings.HasPrefix(output, "syslog://"):
+		return initSyslogSink("udp", strings.TrimPrefix(output, "syslog://"))
+	case strings.HasPrefix(output, "syslog+tcp://"):
+		return initSyslogSink("tcp", strings.TrimPrefix(output, "syslog+tcp://"))
+	case output == "journald":
+		return initJournaldSink()
+	case len(output) == 0:
+		return nil, fmt.Errorf("the log output cannot be empty")
+	}
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &WriterSink{Writer: file, Formatter: new(plainFormat), Closer: file}, nil
+}
+
+// loggerSinkHook writes the entries of a logger to its sinks
+type loggerSinkHook struct {
+	logger *Logger
+}
+
+// Levels implements logrus.Hook
+func (hook *loggerSinkHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook, every sink is written to even when one
+// of them fails
+func (hook *loggerSinkHook) Fire(entry *logrus.Entry) error {
+	entry = hook.logger.tagEntry(entry)
+	var errs []string
+	for _, sink := range hook.logger.getSinks() {
+		if err := sink.WriteEntry(entry); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to write the log: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// getSyslogPriority returns the syslog severity of :level, panics and
+// fatal errors are critical rather than emergencies which are broadcast
+// to every terminal
+func getSyslogPriority(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 1
+	case logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// getEntryModule returns the module of :entry and its submodule in the
+// form module/submodule
+func getEntryModule(entry *logrus.Entry) string {
+	module := fmt.Sprintf("%v", entry.Data["module"])
+	if submodule, ok := entry.Data["submodule"]; ok {
+		module += fmt.Sprintf("/%v", submodule)
+	}
+	return module
+}
+
+// encodeJournalEntry encodes :entry in the native protocol of journald,
+// the fields of the entry are prefixed with GODEV_ and values spanning
+// lines are length-prefixed as the protocol requires
+func encodeJournalEntry(entry *logrus.Entry) []byte {
+	fields := map[string]string{
+		"MESSAGE":           entry.Message,
+		"PRIORITY":          fmt.Sprintf("%v", getSyslogPriority(entry.Level)),
+		"SYSLOG_IDENTIFIER": "godev",
+	}
+	for key, value := range entry.Data {
+		fields["GODEV_"+getJournalFieldName(key)] = fmt.Sprintf("%v", value)
+	}
+	keys := []string{}
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var encoded bytes.Buffer
+	for _, key := range keys {
+		value := fields[key]
+		if !strings.Contains(value, "\n") {
+			encoded.WriteString(key + "=" + value + "\n")
+			continue
+		}
+		encoded.WriteString(key + "\n")
+		binary.Write(&encoded, binary.LittleEndian, uint64(len(value)))
+		encoded.WriteString(value + "\n")
+	}
+	return encoded.Bytes()
+}
+
+// getJournalFieldName returns :key in upper case with the characters
+// that journald does not accept in field names replaced by underscores
+func getJournalFieldName(key string) string {
+	return strings.Map(func(character rune) rune {
+		if (character >= 'A' && character <= 'Z') || (character >= '0' && character <= '9') {
+			return character
+		} else if character >= 'a' && character <= 'z' {
+			return character - 'a' + 'A'
+		}
+		return '_'
+	}, key)
+}
@@ -0,0 +1,118 @@
+package godev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggerSinkTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestLoggerSink(t *testing.T) {
+	suite.Run(t, new(LoggerSinkTestSuite))
+}
+
+func (s *LoggerSinkTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-logger-sink")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+}
+
+func (s *LoggerSinkTestSuite) TearDownTest() {
+	DefaultRunState.CloseLoggerOutputs()
+	os.RemoveAll(s.directory)
+}
+
+// failingSink is a LoggerSink which cannot be written to
+type failingSink struct{}
+
+func (sink *failingSink) WriteEntry(entry *logrus.Entry) error {
+	return errors.New("unavailable")
+}
+
+func (s *LoggerSinkTestSuite) TestLoggerConfigOutputs() {
+	t := s.T()
+	var logs, output bytes.Buffer
+	logger := InitLogger(&LoggerConfig{
+		Name:    "sinks",
+		Format:  "raw",
+		Level:   "info",
+		Outputs: []LoggerSink{&WriterSink{Writer: &output, Formatter: new(plainFormat)}},
+	})
+	logger.SetOutput(&logs)
+	logger.Info("started")
+	logger.Debug("not logged")
+	assert.Equal(t, "started\n", logs.String())
+	assert.Regexp(t, `^\S+ info  \[sinks\] started\n$`, output.String(), "expected the outputs to be kept by SetOutput")
+}
+
+func (s *LoggerSinkTestSuite) TestAddLoggerOutputs() {
+	t := s.T()
+	var logs, output bytes.Buffer
+	logger := InitLogger(&LoggerConfig{Name: "sinks", Format: "raw", Level: "info"})
+	logger.SetOutput(&logs)
+	DefaultRunState.AddLoggerOutputs(&WriterSink{Writer: &output, Formatter: new(rawFormat)}, &failingSink{})
+	logger.Warn("written everywhere")
+	assert.Equal(t, "written everywhere\n", logs.String())
+	assert.Equal(t, "written everywhere\n", output.String(), "expected sinks after a failing one to be written to")
+	DefaultRunState.CloseLoggerOutputs()
+	logger.Warn("only to the logger")
+	assert.Equal(t, "written everywhere\n", output.String())
+}
+
+func (s *LoggerSinkTestSuite) TestParseLoggerOutput() {
+	t := s.T()
+	filePath := path.Join(s.directory, "godev.log")
+	sink, err := ParseLoggerOutput(filePath)
+	assert.Nil(t, err)
+	DefaultRunState.AddLoggerOutputs(sink)
+	logger := InitLogger(&LoggerConfig{Name: "main", Format: "production", Level: "info"})
+	logger.SetOutput(&bytes.Buffer{})
+	logger.Info("written to the file")
+	DefaultRunState.CloseLoggerOutputs()
+	contents, err := ioutil.ReadFile(filePath)
+	assert.Nil(t, err)
+	assert.Regexp(t, `^\S+ info  \[main\] written to the file\n$`, string(contents), "expected files to be written without colours")
+
+	sink, err = ParseLoggerOutput("stdout")
+	assert.Nil(t, err)
+	assert.Equal(t, os.Stdout, sink.(*WriterSink).Writer)
+	_, err = ParseLoggerOutput("")
+	assert.NotNil(t, err)
+	_, err = ParseLoggerOutput(path.Join(s.directory, "missing", "godev.log"))
+	assert.NotNil(t, err)
+}
+
+func (s *LoggerSinkTestSuite) Test_encodeJournalEntry() {
+	t := s.T()
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{"module": "runner", "exec-group": 2})
+	entry.Level = logrus.WarnLevel
+	entry.Message = "line 1\nline 2"
+	encoded := encodeJournalEntry(entry)
+	var expected bytes.Buffer
+	expected.WriteString("GODEV_EXEC_GROUP=2\nGODEV_MODULE=runner\nMESSAGE\n")
+	binary.Write(&expected, binary.LittleEndian, uint64(13))
+	expected.WriteString("line 1\nline 2\nPRIORITY=4\nSYSLOG_IDENTIFIER=godev\n")
+	assert.Equal(t, expected.Bytes(), encoded)
+}
+
+func (s *LoggerSinkTestSuite) Test_getSyslogPriority() {
+	t := s.T()
+	assert.Equal(t, 7, getSyslogPriority(logrus.TraceLevel))
+	assert.Equal(t, 7, getSyslogPriority(logrus.DebugLevel))
+	assert.Equal(t, 6, getSyslogPriority(logrus.InfoLevel))
+	assert.Equal(t, 4, getSyslogPriority(logrus.WarnLevel))
+	assert.Equal(t, 3, getSyslogPriority(logrus.ErrorLevel))
+	assert.Equal(t, 2, getSyslogPriority(logrus.FatalLevel))
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"fmt"
+	"log/syslog"
+	"net"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JournaldSocketPath is where journald receives entries in its native
+// protocol
+var JournaldSocketPath = "/run/systemd/journal/socket"
+
+// syslogSink writes entries to syslog with the severity of their level
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// initSyslogSink connects to the syslog at :address over :network, the
+// local syslog is used when :network is empty
+func initSyslogSink(network, address string) (LoggerSink, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_DAEMON, "godev")
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to syslog: %s", err)
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+// WriteEntry implements LoggerSink
+func (sink *syslogSink) WriteEntry(entry *logrus.Entry) error {
+	message := fmt.Sprintf("[%s] %s", getEntryModule(entry), entry.Message)
+	switch getSyslogPriority(entry.Level) {
+	case 1:
+		return sink.writer.Alert(message)
+	case 2:
+		return sink.writer.Crit(message)
+	case 3:
+		return sink.writer.Err(message)
+	case 4:
+		return sink.writer.Warning(message)
+	case 6:
+		return sink.writer.Info(message)
+	default:
+		return sink.writer.Debug(message)
+	}
+}
+
+// Close implements io.Closer
+func (sink *syslogSink) Close() error {
+	return sink.writer.Close()
+}
+
+// journaldSink writes entries to journald with their fields
+type journaldSink struct {
+	connection *net.UnixConn
+}
+
+// initJournaldSink connects to the socket of journald
+func initJournaldSink() (LoggerSink, error) {
+	connection, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: JournaldSocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to journald: %s", err)
+	}
+	return &journaldSink{connection: connection}, nil
+}
+
+// WriteEntry implements LoggerSink
+func (sink *journaldSink) WriteEntry(entry *logrus.Entry) error {
+	_, err := sink.connection.Write(encodeJournalEntry(entry))
+	return err
+}
+
+// Close implements io.Closer
+func (sink *journaldSink) Close() error {
+	return sink.connection.Close()
+}
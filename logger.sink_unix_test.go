@@ -0,0 +1,62 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"bytes"
+	"net"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *LoggerSinkTestSuite) TestParseLoggerOutput_journald() {
+	t := s.T()
+	socketPath := path.Join(s.directory, "journal.socket")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	assert.Nil(t, err)
+	defer listener.Close()
+	defer func(original string) { JournaldSocketPath = original }(JournaldSocketPath)
+	JournaldSocketPath = socketPath
+	sink, err := ParseLoggerOutput("journald")
+	assert.Nil(t, err)
+	DefaultRunState.AddLoggerOutputs(sink)
+	logger := InitLogger(&LoggerConfig{Name: "watcher", Format: "production", Level: "info"})
+	logger.SetOutput(&bytes.Buffer{})
+	logger.Error("watch limit reached")
+	received := make([]byte, 1024)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	length, err := listener.Read(received)
+	assert.Nil(t, err)
+	assert.Contains(t, string(received[:length]), "MESSAGE=watch limit reached\n")
+	assert.Contains(t, string(received[:length]), "PRIORITY=3\n")
+	assert.Contains(t, string(received[:length]), "GODEV_MODULE=watcher\n")
+
+	JournaldSocketPath = path.Join(s.directory, "missing.socket")
+	_, err = ParseLoggerOutput("journald")
+	assert.NotNil(t, err)
+}
+
+func (s *LoggerSinkTestSuite) TestParseLoggerOutput_syslog() {
+	t := s.T()
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	sink, err := ParseLoggerOutput("syslog://" + listener.LocalAddr().String())
+	assert.Nil(t, err)
+	DefaultRunState.AddLoggerOutputs(sink)
+	logger := InitLogger(&LoggerConfig{Name: "runner", Format: "production", Level: "info"})
+	logger.SetOutput(&bytes.Buffer{})
+	logger.Warn("pipeline failed")
+	received := make([]byte, 1024)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	length, _, err := listener.ReadFrom(received)
+	assert.Nil(t, err)
+	message := string(received[:length])
+	assert.True(t, strings.HasPrefix(message, "<28>"), "expected a warning of the daemon facility in %q", message)
+	assert.Contains(t, message, "godev")
+	assert.Contains(t, message, "[runner] pipeline failed")
+}
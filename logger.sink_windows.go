@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"errors"
+)
+
+// initSyslogSink is not supported because windows has no syslog
+func initSyslogSink(network, address string) (LoggerSink, error) {
+	return nil, errors.New("logging to syslog is not supported on windows")
+}
+
+// initJournaldSink is not supported because windows has no journald
+func initJournaldSink() (LoggerSink, error) {
+	return nil, errors.New("logging to journald is not supported on windows")
+}
@@ -1,4 +1,4 @@
-package main
+package godev
 
 import (
 	"bytes"
@@ -45,6 +45,33 @@ func (s *LoggerTestSuite) TestLogLevelGet() {
 	assert.Equal(s.T(), logLevel.Get(), logrus.TraceLevel)
 }
 
+func (s *LoggerTestSuite) TestToggleVerboseLogs() {
+	t := s.T()
+	state := InitRunState()
+	logger := InitLogger(&LoggerConfig{Name: "LoggerTestSuite", Format: "raw", Level: "info", RunState: state})
+	logger.SetOutput(&s.logs)
+	other := InitLogger(&LoggerConfig{Name: "LoggerTestSuite", Format: "raw", Level: "info"})
+	other.SetOutput(&s.logs)
+	tracer := InitLogger(&LoggerConfig{Name: "LoggerTestSuite", Format: "raw", Level: "trace", RunState: state})
+	tracer.SetOutput(&s.logs)
+	logger.Debug("hidden")
+	assert.True(t, state.ToggleVerboseLogs())
+	logger.Debug("shown")
+	other.Debug("verbose in another state")
+	tracer.Trace("still traced")
+	assert.False(t, state.ToggleVerboseLogs())
+	logger.Debug("hidden again")
+	assert.NotContains(t, s.logs.String(), "hidden")
+	assert.Contains(t, s.logs.String(), "shown")
+	assert.Contains(t, s.logs.String(), "still traced", "expected loggers below the debug level to keep their level")
+	assert.NotContains(t, s.logs.String(), "verbose in another state", "expected only the loggers of the state to be verbose")
+}
+
+func (s *LoggerTestSuite) TestLog() {
+	s.logger.Log("warn", "goL")
+	assert.Contains(s.T(), s.logs.String(), "goL")
+}
+
 func (s *LoggerTestSuite) TestTrace() {
 	s.logger.Trace("Trace")
 	s.logger.Tracef("%s", "ecarT")
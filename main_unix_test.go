@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"syscall"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *MainTestSuite) Test_handleSignals() {
+	t := s.T()
+	stopped := make(chan bool, 1)
+	defer s.godev.handleSignals(func() { stopped <- true })()
+	assert.False(t, s.godev.isStopped())
+	assert.Nil(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected SIGTERM to stop the session")
+	}
+	assert.True(t, s.godev.isStopped())
+	assert.Equal(t, ErrStopped, s.godev.getStopError())
+}
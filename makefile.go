@@ -0,0 +1,102 @@
+package godev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// MakefileNames are the names of Makefiles in the order which make
+// looks for them
+var MakefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+// makefileRule matches the targets of a rule, eg. 'build test: deps',
+// and not variable assignments such as 'VERSION := 1.0.0'
+var makefileRule = regexp.MustCompile(`^([^\s#:=$%][^#:=$%]*?)\s*::?([^=]|$)`)
+
+// makefileInclude matches directives which include other Makefiles
+// whose targets are not known without running make
+var makefileInclude = regexp.MustCompile(`^-?s?include\s`)
+
+// FindMakefile returns the path to the Makefile in :directory or an
+// empty string if there is none
+func FindMakefile(directory string) string {
+	for _, fileName := range MakefileNames {
+		filePath := path.Join(directory, fileName)
+		if fileExists(filePath) {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// ParseMakeTargets returns the targets defined in the Makefile
+// :contents in the order they are defined, skipping special and
+// internal targets which start with a dot
+func ParseMakeTargets(contents string) []string {
+	var targets []string
+	for _, line := range strings.Split(contents, "\n") {
+		matches := makefileRule.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		for _, target := range strings.Fields(matches[1]) {
+			if !strings.HasPrefix(target, ".") && !sliceContainsString(targets, target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+// GetMakeTargets returns the targets of the Makefile in :directory or
+// nothing if there is no Makefile
+func GetMakeTargets(directory string) []string {
+	makefilePath := FindMakefile(directory)
+	if len(makefilePath) == 0 {
+		return nil
+	}
+	contents, err := ioutil.ReadFile(makefilePath)
+	if err != nil {
+		return nil
+	}
+	return ParseMakeTargets(string(contents))
+}
+
+// getMakeExecGroups returns an execution group running make for each
+// of :targets so that a failing target stops the following ones
+func getMakeExecGroups(targets []string) []string {
+	execGroups := make([]string, 0, len(targets))
+	for _, target := range targets {
+		execGroups = append(execGroups, "make "+target)
+	}
+	return execGroups
+}
+
+// validateMakeTargets checks that there is a Makefile in :directory
+// which defines all of :targets, targets are not checked when the
+// Makefile includes other Makefiles which could define them
+func validateMakeTargets(directory string, targets []string) error {
+	makefilePath := FindMakefile(directory)
+	if len(makefilePath) == 0 {
+		return fmt.Errorf("--make needs a Makefile in '%s'", directory)
+	}
+	contents, err := ioutil.ReadFile(makefilePath)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if makefileInclude.MatchString(line) {
+			return nil
+		}
+	}
+	available := ParseMakeTargets(string(contents))
+	for _, target := range targets {
+		if !sliceContainsString(available, target) {
+			return fmt.Errorf("make target '%s' is not defined in '%s' - available targets are: %s", target, makefilePath, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
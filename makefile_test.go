@@ -0,0 +1,78 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MakefileTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestMakefile(t *testing.T) {
+	suite.Run(t, new(MakefileTestSuite))
+}
+
+func (s *MakefileTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-makefile")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+}
+
+func (s *MakefileTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *MakefileTestSuite) TestParseMakeTargets() {
+	t := s.T()
+	contents := "BINARY := app\nVERSION ?= 1.0.0\n.PHONY: build\n" +
+		"build: deps\n\tgo build -o $(BINARY)\n" +
+		"deps:\n\tgo mod download\n" +
+		"lint vet:: deps # checks\n\tgo vet ./...\n" +
+		".compile:\n\tgo build\n" +
+		"%.pb.go: %.proto\n\tprotoc $<\n" +
+		"build:\n\t@echo again\n"
+	assert.Equal(t, []string{"build", "deps", "lint", "vet"}, ParseMakeTargets(contents))
+	assert.Nil(t, ParseMakeTargets(""))
+}
+
+func (s *MakefileTestSuite) TestParseMakeTargets_seededMakefile() {
+	targets := ParseMakeTargets(DataMakefile)
+	assert.Contains(s.T(), targets, "start")
+	assert.Contains(s.T(), targets, "test")
+	assert.NotContains(s.T(), targets, ".compile")
+}
+
+func (s *MakefileTestSuite) TestGetMakeTargets() {
+	t := s.T()
+	assert.Nil(t, GetMakeTargets(s.directory))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "Makefile"), []byte("build:\n\tgo build\n"), 0644))
+	assert.Equal(t, []string{"build"}, GetMakeTargets(s.directory))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "GNUmakefile"), []byte("test:\n\tgo test\n"), 0644))
+	assert.Equal(t, []string{"test"}, GetMakeTargets(s.directory), "expected GNUmakefile to take precedence like it does for make")
+}
+
+func (s *MakefileTestSuite) Test_getMakeExecGroups() {
+	assert.Equal(s.T(), []string{"make deps", "make build"}, getMakeExecGroups([]string{"deps", "build"}))
+}
+
+func (s *MakefileTestSuite) Test_validateMakeTargets() {
+	t := s.T()
+	err := validateMakeTargets(s.directory, []string{"build"})
+	assert.Contains(t, err.Error(), "--make needs a Makefile in '"+s.directory+"'")
+	makefilePath := path.Join(s.directory, "Makefile")
+	assert.Nil(t, ioutil.WriteFile(makefilePath, []byte("build:\n\tgo build\n"), 0644))
+	assert.Nil(t, validateMakeTargets(s.directory, []string{"build"}))
+	err = validateMakeTargets(s.directory, []string{"build", "release"})
+	assert.Equal(t, "make target 'release' is not defined in '"+makefilePath+"' - available targets are: build", err.Error())
+	assert.Nil(t, ioutil.WriteFile(makefilePath, []byte("-include release.mk\nbuild:\n\tgo build\n"), 0644))
+	assert.Nil(t, validateMakeTargets(s.directory, []string{"release"}), "expected targets not to be checked when other Makefiles are included")
+}
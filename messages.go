@@ -0,0 +1,155 @@
+package godev
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MessageID identifies a user-facing message in the message catalogs so
+// that it can be translated and asserted on independently of its text
+type MessageID string
+
+const (
+	// MessageConfirmNo is the comma-delimited list of answers which
+	// decline a question, the first one is shown as the option
+	MessageConfirmNo MessageID = "confirm.no"
+	// MessageConfirmYes is the comma-delimited list of answers which
+	// accept a question, the first one is shown as the option
+	MessageConfirmYes MessageID = "confirm.yes"
+	// MessageInitAccepted is shown when a step of the init wizard is accepted
+	MessageInitAccepted MessageID = "init.accepted"
+	// MessageInitDirectoryMissing is the error for a missing work directory
+	MessageInitDirectoryMissing MessageID = "init.directory.missing"
+	// MessageInitFileQuestion asks whether to seed a file
+	MessageInitFileQuestion MessageID = "init.file.question"
+	// MessageInitFileSkipped is shown for files which already exist
+	MessageInitFileSkipped MessageID = "init.file.skipped"
+	// MessageInitGitQuestion asks whether to initialise a git repository
+	MessageInitGitQuestion MessageID = "init.git.question"
+	// MessageInitGitSkipped is shown when there is a git repository already
+	MessageInitGitSkipped MessageID = "init.git.skipped"
+	// MessageInitRetry is shown when an answer is not understood
+	MessageInitRetry MessageID = "init.retry"
+	// MessageInitSkipped is shown when a step of the init wizard is declined
+	MessageInitSkipped MessageID = "init.skipped"
+	// MessageLintScaffoldQuestion asks whether to fix the known issues of
+	// a seeded file
+	MessageLintScaffoldQuestion MessageID = "lint-scaffold.question"
+	// MessageOnboardingCommand suggests seeding the directory with init
+	MessageOnboardingCommand MessageID = "onboarding.command"
+	// MessageOnboardingConfig suggests adding a configuration file
+	MessageOnboardingConfig MessageID = "onboarding.config"
+	// MessageOnboardingEmpty is the warning for a directory without go
+	// files or configuration files
+	MessageOnboardingEmpty MessageID = "onboarding.empty"
+	// MessageOnboardingGuide introduces the quickstart guidance
+	MessageOnboardingGuide MessageID = "onboarding.guide"
+	// MessageOnboardingQuestion asks whether to run the init wizard
+	MessageOnboardingQuestion MessageID = "onboarding.question"
+	// MessageOnboardingWatching explains that the directory is still watched
+	MessageOnboardingWatching MessageID = "onboarding.watching"
+)
+
+// DefaultLocale is the locale whose catalog is used for locales without
+// a catalog and for messages missing from a catalog
+const DefaultLocale = "en"
+
+// MessageCatalogs are the user-facing messages by locale, messages are
+// formatted with fmt and have to take the same arguments in every locale
+var MessageCatalogs = map[string]map[MessageID]string{
+	"en": {
+		MessageConfirmNo:            "n,no,nope,nah,neh,stop,dont",
+		MessageConfirmYes:           "y,yes,yupp,yeah,yea,ok,okay",
+		MessageInitAccepted:         "godev> sure thing",
+		MessageInitDirectoryMissing: "the directory at '%[1]s' does not exist - create it first with:\n  mkdir -p %[1]s",
+		MessageInitFileQuestion:     "godev> seed a %s?",
+		MessageInitFileSkipped:      "godev> skipping '%s' - already exists",
+		MessageInitGitQuestion:      "godev> initialise git repository at '%s'?",
+		MessageInitGitSkipped:       "godev> skipping git repository initialisation at '%s'",
+		MessageInitRetry:            "godev> sorry, i didn't get that",
+		MessageInitSkipped:          "godev> lets skip that then",
+		MessageLintScaffoldQuestion: "godev> fix the known issues of '%s'?",
+		MessageOnboardingCommand:    "  - run 'godev init --dir %s' to seed a go.mod, main.go and more",
+		MessageOnboardingConfig:     "  - or add a %s to configure the commands which godev runs",
+		MessageOnboardingEmpty:      "there are no go files or configuration files in '%s'",
+		MessageOnboardingGuide:      "to get started:",
+		MessageOnboardingQuestion:   "godev> run the init wizard to bootstrap this directory?",
+		MessageOnboardingWatching:   "godev will keep watching '%s' for new files",
+	},
+}
+
+// LocaleEnvVars are the environment variables which the locale is taken
+// from when none is specified, in order of precedence
+var LocaleEnvVars = []string{"LC_ALL", "LC_MESSAGES", "LANG"}
+
+// Messages formats the user-facing messages with the catalog of one
+// locale, every GoDev has its own so that a program can embed several
+// of them with different locales
+type Messages struct {
+	locale string
+}
+
+// InitMessages selects the catalog for :locale, or for the locale in
+// the environment if :locale is empty
+func InitMessages(locale string) *Messages {
+	return &Messages{locale: resolveLocale(getEnvironmentLocale(locale))}
+}
+
+// GetLocale returns the locale of the catalog messages are taken from,
+// the default one when :messages is nil
+func (messages *Messages) GetLocale() string {
+	if messages == nil {
+		return DefaultLocale
+	}
+	return messages.locale
+}
+
+// Get returns the message :id from the catalog of the locale formatted
+// with :args
+func (messages *Messages) Get(id MessageID, args ...interface{}) string {
+	message, ok := MessageCatalogs[messages.GetLocale()][id]
+	if !ok {
+		if message, ok = MessageCatalogs[DefaultLocale][id]; !ok {
+			return string(id)
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// getEnvironmentLocale returns :locale, or the first of LocaleEnvVars
+// which is set if :locale is empty
+func getEnvironmentLocale(locale string) string {
+	if len(locale) > 0 {
+		return locale
+	}
+	for _, envVar := range LocaleEnvVars {
+		if locale = os.Getenv(envVar); len(locale) > 0 {
+			break
+		}
+	}
+	return locale
+}
+
+// resolveLocale returns the locale with a catalog which matches
+// :locale, eg. 'pt_BR.UTF-8' matches 'pt_BR' and then 'pt', or the
+// default locale when none matches
+func resolveLocale(locale string) string {
+	locale = strings.SplitN(locale, ".", 2)[0]
+	locale = strings.SplitN(locale, "@", 2)[0]
+	locale = strings.Replace(locale, "-", "_", -1)
+	for len(locale) > 0 {
+		if _, ok := MessageCatalogs[locale]; ok {
+			return locale
+		}
+		index := strings.LastIndex(locale, "_")
+		if index < 0 {
+			break
+		}
+		locale = locale[:index]
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,91 @@
+package godev
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MessagesTestSuite struct {
+	suite.Suite
+	environment map[string]string
+}
+
+func TestMessages(t *testing.T) {
+	suite.Run(t, new(MessagesTestSuite))
+}
+
+func (s *MessagesTestSuite) SetupTest() {
+	MessageCatalogs["xx_YY"] = map[MessageID]string{
+		MessageInitAccepted:    "godev> xx",
+		MessageInitFileSkipped: "godev> xx '%s'",
+	}
+	s.environment = map[string]string{}
+	for _, envVar := range LocaleEnvVars {
+		s.environment[envVar] = os.Getenv(envVar)
+		os.Setenv(envVar, "")
+	}
+}
+
+func (s *MessagesTestSuite) TearDownTest() {
+	delete(MessageCatalogs, "xx_YY")
+	for envVar, value := range s.environment {
+		os.Setenv(envVar, value)
+	}
+}
+
+func (s *MessagesTestSuite) TestGet() {
+	t := s.T()
+	messages := InitMessages(DefaultLocale)
+	assert.Equal(t, "godev> skipping 'go.mod' - already exists", messages.Get(MessageInitFileSkipped, "go.mod"))
+	assert.Equal(t, "godev> sure thing", messages.Get(MessageInitAccepted))
+	assert.Equal(t, "the directory at '/a' does not exist - create it first with:\n  mkdir -p /a", messages.Get(MessageInitDirectoryMissing, "/a"))
+	assert.Equal(t, "unknown.message", messages.Get(MessageID("unknown.message")))
+	messages = InitMessages("xx_YY")
+	assert.Equal(t, "godev> xx 'go.mod'", messages.Get(MessageInitFileSkipped, "go.mod"))
+	assert.Equal(t, "godev> lets skip that then", messages.Get(MessageInitSkipped), "expected messages missing from a catalog to be taken from the default one")
+}
+
+func (s *MessagesTestSuite) TestInitMessages_resolvesLocale() {
+	t := s.T()
+	assert.Equal(t, DefaultLocale, InitMessages("").GetLocale())
+	assert.Equal(t, "xx_YY", InitMessages("xx_YY.UTF-8").GetLocale())
+	assert.Equal(t, "xx_YY", InitMessages("xx-YY").GetLocale())
+	assert.Equal(t, DefaultLocale, InitMessages("xx").GetLocale(), "expected a language not to match catalogs of its regions")
+	assert.Equal(t, DefaultLocale, InitMessages("C").GetLocale())
+	assert.Equal(t, "en", InitMessages("en_GB.UTF-8@euro").GetLocale())
+	os.Setenv("LANG", "de_DE.UTF-8")
+	os.Setenv("LC_MESSAGES", "xx_YY.UTF-8")
+	assert.Equal(t, "xx_YY", InitMessages("").GetLocale(), "expected LC_MESSAGES to take precedence over LANG")
+	assert.Equal(t, DefaultLocale, InitMessages("en").GetLocale(), "expected the specified locale to take precedence over the environment")
+}
+
+func (s *MessagesTestSuite) TestInitMessages() {
+	t := s.T()
+	messages := InitMessages("xx_YY.UTF-8")
+	other := InitMessages("")
+	assert.Equal(t, "xx_YY", messages.GetLocale())
+	assert.Equal(t, DefaultLocale, other.GetLocale())
+	assert.Equal(t, "godev> xx 'go.mod'", messages.Get(MessageInitFileSkipped, "go.mod"))
+	assert.Equal(t, "godev> lets skip that then", messages.Get(MessageInitSkipped))
+	assert.Equal(t, "godev> skipping 'go.mod' - already exists", other.Get(MessageInitFileSkipped, "go.mod"), "expected every Messages to keep its own locale")
+	os.Setenv("LC_ALL", "xx_YY")
+	assert.Equal(t, "xx_YY", InitMessages("").GetLocale())
+	var none *Messages
+	assert.Equal(t, "godev> sure thing", none.Get(MessageInitAccepted), "expected nil Messages to use the default locale")
+}
+
+func (s *MessagesTestSuite) TestMessageCatalogs() {
+	t := s.T()
+	verbs := regexp.MustCompile(`%(\[\d+\])?[a-z]`)
+	for locale, catalog := range MessageCatalogs {
+		for id, message := range catalog {
+			defaultMessage, ok := MessageCatalogs[DefaultLocale][id]
+			assert.True(t, ok, "expected '%s' of the '%s' catalog to be in the default catalog", id, locale)
+			assert.Equal(t, verbs.FindAllString(defaultMessage, -1), verbs.FindAllString(message, -1), "expected '%s' of the '%s' catalog to take the same arguments", id, locale)
+		}
+	}
+}
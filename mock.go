@@ -0,0 +1,223 @@
+package godev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// MockHost is the host that mock servers listen on
+const MockHost = "127.0.0.1"
+
+// MockFile is the YAML file given to --mock which maps routes to the
+// responses of a mock server
+type MockFile struct {
+	Port   int         `yaml:"port"`
+	Routes []MockRoute `yaml:"routes"`
+}
+
+// MockRoute is a response of a mock server
+type MockRoute struct {
+	// Method matches any method when it is not specified
+	Method string `yaml:"method"`
+	// Path is matched against the path of requests where * matches a
+	// segment and ** matches any number of segments
+	Path    string            `yaml:"path"`
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+	// File is read for the body on every request and is relative to the
+	// directory of the mock file
+	File  string `yaml:"file"`
+	Delay string `yaml:"delay"`
+}
+
+// Matches checks whether the route responds to :method and :requestPath
+func (route *MockRoute) Matches(method, requestPath string) bool {
+	if len(route.Method) > 0 && !strings.EqualFold(route.Method, method) {
+		return false
+	}
+	return matchPatternSegments(
+		strings.Split(strings.Trim(route.Path, "/"), "/"),
+		strings.Split(strings.Trim(requestPath, "/"), "/"),
+	)
+}
+
+// LoadMockFile loads and validates the mock file at :filePath
+func LoadMockFile(filePath string) (*MockFile, error) {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	mockFile := &MockFile{}
+	if err := yaml.UnmarshalStrict(contents, mockFile); err != nil {
+		return nil, fmt.Errorf("mock file '%s' is invalid: %s", filePath, err)
+	}
+	if mockFile.Port < 1 || mockFile.Port > 65535 {
+		return nil, fmt.Errorf("mock file '%s' has no valid port", filePath)
+	}
+	for index, route := range mockFile.Routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			return nil, fmt.Errorf("route %v of mock file '%s' has a path which does not start with '/'", index+1, filePath)
+		} else if route.Status != 0 && (route.Status < 100 || route.Status > 599) {
+			return nil, fmt.Errorf("route %v of mock file '%s' has an invalid status %v", index+1, filePath, route.Status)
+		} else if len(route.Body) > 0 && len(route.File) > 0 {
+			return nil, fmt.Errorf("route %v of mock file '%s' cannot have both a body and a file", index+1, filePath)
+		}
+		if len(route.Delay) > 0 {
+			if _, err := time.ParseDuration(route.Delay); err != nil {
+				return nil, fmt.Errorf("route %v of mock file '%s' has an invalid delay: %s", index+1, filePath, err)
+			}
+		}
+	}
+	return mockFile, nil
+}
+
+// MockServerConfig configures MockServer
+type MockServerConfig struct {
+	FilePath string
+	LogLevel LogLevel
+	RunState *RunState
+}
+
+// InitMockServer creates a MockServer from the mock file at
+// :config.FilePath
+func InitMockServer(config *MockServerConfig) (*MockServer, error) {
+	server := &MockServer{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:     "mock",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: config.RunState,
+		}),
+	}
+	info, err := os.Stat(config.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if server.mockFile, err = LoadMockFile(config.FilePath); err != nil {
+		return nil, err
+	}
+	server.modifiedAt = info.ModTime()
+	return server, nil
+}
+
+// MockServer serves the routes of a mock file and reloads them whenever
+// the mock file changes
+type MockServer struct {
+	config     *MockServerConfig
+	logger     *Logger
+	listener   net.Listener
+	mockFile   *MockFile
+	modifiedAt time.Time
+	mutex      sync.Mutex
+}
+
+// Start begins listening for requests in the background
+func (server *MockServer) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%v", MockHost, server.mockFile.Port))
+	if err != nil {
+		return err
+	}
+	server.listener = listener
+	server.logger.Infof("mocking %v route(s) of '%s' at 'http://%s'", len(server.mockFile.Routes), server.config.FilePath, listener.Addr().String())
+	go func() {
+		if err := http.Serve(listener, server); err != nil {
+			server.logger.Debugf("mock server stopped: %s", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the mock server from accepting requests
+func (server *MockServer) Close() error {
+	if server.listener == nil {
+		return nil
+	}
+	return server.listener.Close()
+}
+
+// ServeHTTP implements http.Handler
+func (server *MockServer) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	mockFile := server.reload()
+	for _, route := range mockFile.Routes {
+		if route.Matches(request.Method, request.URL.Path) {
+			server.respond(response, request, route)
+			return
+		}
+	}
+	server.logger.Warnf("%s %s matches no route of '%s'", request.Method, request.URL.RequestURI(), server.config.FilePath)
+	response.WriteHeader(http.StatusNotFound)
+	fmt.Fprintf(response, "godev: no mock route matches %s %s\n", request.Method, request.URL.Path)
+}
+
+// respond writes the response of :route
+func (server *MockServer) respond(response http.ResponseWriter, request *http.Request, route MockRoute) {
+	body := []byte(route.Body)
+	if len(route.File) > 0 {
+		filePath := route.File
+		if !path.IsAbs(filePath) {
+			filePath = path.Join(path.Dir(server.config.FilePath), filePath)
+		}
+		contents, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			server.logger.Warnf("%s %s could not be mocked: %s", request.Method, request.URL.RequestURI(), err)
+			response.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(response, "godev: %s\n", err)
+			return
+		}
+		body = contents
+	}
+	if len(route.Delay) > 0 {
+		delay, _ := time.ParseDuration(route.Delay)
+		time.Sleep(delay)
+	}
+	var keys []string
+	for key := range route.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		response.Header().Set(key, route.Headers[key])
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	server.logger.Debugf("%s %s mocked with %s: %v", request.Method, request.URL.RequestURI(), route.Path, status)
+	response.WriteHeader(status)
+	response.Write(body)
+}
+
+// reload loads the mock file again if it was modified since it was last
+// loaded, the previous routes are kept when it cannot be loaded
+func (server *MockServer) reload() *MockFile {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	info, err := os.Stat(server.config.FilePath)
+	if err != nil || info.ModTime().Equal(server.modifiedAt) {
+		return server.mockFile
+	}
+	server.modifiedAt = info.ModTime()
+	mockFile, err := LoadMockFile(server.config.FilePath)
+	if err != nil {
+		server.logger.Warnf("keeping the previous routes: %s", err)
+		return server.mockFile
+	}
+	if mockFile.Port != server.mockFile.Port {
+		server.logger.Warnf("the port of '%s' changed to %v but only takes effect when godev is restarted", server.config.FilePath, mockFile.Port)
+	}
+	server.mockFile = mockFile
+	server.logger.Infof("reloaded %v route(s) of '%s'", len(mockFile.Routes), server.config.FilePath)
+	return mockFile
+}
@@ -0,0 +1,135 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockServerTestSuite struct {
+	suite.Suite
+	directory string
+	filePath  string
+	logs      bytes.Buffer
+}
+
+func TestMockServer(t *testing.T) {
+	suite.Run(t, new(MockServerTestSuite))
+}
+
+func (s *MockServerTestSuite) SetupTest() {
+	var err error
+	s.logs.Reset()
+	s.directory, err = ioutil.TempDir("", "godev-mock")
+	assert.Nil(s.T(), err)
+	s.filePath = path.Join(s.directory, "mock.yaml")
+}
+
+func (s *MockServerTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+// writeMockFile writes :contents to the mock file with a modification
+// time of :modifiedAt
+func (s *MockServerTestSuite) writeMockFile(contents string, modifiedAt time.Time) {
+	assert.Nil(s.T(), ioutil.WriteFile(s.filePath, []byte(contents), 0644))
+	assert.Nil(s.T(), os.Chtimes(s.filePath, modifiedAt, modifiedAt))
+}
+
+// request sends :method :target to :server and returns the response
+func (s *MockServerTestSuite) request(server *MockServer, method, target string) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(method, target, nil))
+	return response
+}
+
+func (s *MockServerTestSuite) TestServeHTTP() {
+	t := s.T()
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "user.json"), []byte(`{"id":1}`), 0644))
+	s.writeMockFile(`
+port: 9090
+routes:
+  - method: POST
+    path: /v1/charges
+    status: 201
+    headers:
+      Content-Type: application/json
+    body: '{"id":"ch_1"}'
+  - path: /v1/users/*
+    file: user.json
+  - path: /v1/**
+    status: 503
+`, time.Now())
+	server, err := InitMockServer(&MockServerConfig{FilePath: s.filePath})
+	assert.Nil(t, err)
+	server.logger.SetOutput(&s.logs)
+
+	response := s.request(server, "POST", "/v1/charges")
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assert.Equal(t, `{"id":"ch_1"}`, response.Body.String())
+
+	response = s.request(server, "GET", "/v1/users/1?expand=true")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `{"id":1}`, response.Body.String())
+
+	assert.Equal(t, http.StatusServiceUnavailable, s.request(server, "GET", "/v1/charges").Code, "expected routes to match their method")
+	assert.Equal(t, http.StatusServiceUnavailable, s.request(server, "GET", "/v1/users/1/cards").Code)
+
+	response = s.request(server, "GET", "/v2/charges")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Contains(t, response.Body.String(), "no mock route matches GET /v2/charges")
+	assert.Contains(t, s.logs.String(), "GET /v2/charges matches no route")
+}
+
+func (s *MockServerTestSuite) TestServeHTTP_reloadsRoutes() {
+	t := s.T()
+	modifiedAt := time.Now().Add(-time.Hour)
+	s.writeMockFile("port: 9090\nroutes:\n  - path: /health\n    body: ok\n", modifiedAt)
+	server, err := InitMockServer(&MockServerConfig{FilePath: s.filePath})
+	assert.Nil(t, err)
+	server.logger.SetOutput(&s.logs)
+	assert.Equal(t, "ok", s.request(server, "GET", "/health").Body.String())
+
+	s.writeMockFile("port: 9090\nroutes:\n  - path: /health\n    body: degraded\n", modifiedAt.Add(time.Minute))
+	assert.Equal(t, "degraded", s.request(server, "GET", "/health").Body.String())
+	assert.Contains(t, s.logs.String(), "reloaded 1 route(s)")
+
+	s.writeMockFile("port: 9090\nroutes:\n  - path: health\n", modifiedAt.Add(2*time.Minute))
+	assert.Equal(t, "degraded", s.request(server, "GET", "/health").Body.String(), "expected invalid mock files to keep the previous routes")
+	assert.Contains(t, s.logs.String(), "keeping the previous routes")
+}
+
+func (s *MockServerTestSuite) TestLoadMockFile_invalid() {
+	t := s.T()
+	for _, contents := range []string{
+		"routes: []\n",
+		"port: 70000\n",
+		"port: 9090\nunknown: true\n",
+		"port: 9090\nroutes:\n  - path: users\n",
+		"port: 9090\nroutes:\n  - path: /users\n    status: 1000\n",
+		"port: 9090\nroutes:\n  - path: /users\n    body: '[]'\n    file: users.json\n",
+		"port: 9090\nroutes:\n  - path: /users\n    delay: soon\n",
+	} {
+		s.writeMockFile(contents, time.Now())
+		_, err := LoadMockFile(s.filePath)
+		assert.NotNilf(t, err, "expected %q to be invalid", contents)
+	}
+}
+
+func (s *MockServerTestSuite) TestMockRoute_Matches() {
+	t := s.T()
+	assert.True(t, (&MockRoute{Path: "/"}).Matches("GET", "/"))
+	assert.False(t, (&MockRoute{Path: "/"}).Matches("GET", "/users"))
+	assert.True(t, (&MockRoute{Method: "get", Path: "/users/*/cards"}).Matches("GET", "/users/1/cards"))
+	assert.False(t, (&MockRoute{Method: "POST", Path: "/users"}).Matches("GET", "/users"))
+	assert.True(t, (&MockRoute{Path: "/**"}).Matches("DELETE", "/users/1"))
+}
@@ -0,0 +1,59 @@
+package godev
+
+import (
+	"bufio"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// needsOnboarding checks if godev was started in a watch directory
+// without any Go files and without a configuration file, which is
+// usually a first run in the wrong or a new directory
+func (godev *GoDev) needsOnboarding() bool {
+	config := godev.config
+	return len(config.ConfigFile) == 0 &&
+		directoryExists(config.WatchDirectory) &&
+		!containsGoFiles(config.WatchDirectory, config.IgnoredNames)
+}
+
+// onboard explains that there is nothing to build yet and, when
+// :interactive, offers to run the init wizard with answers read from
+// :reader
+func (godev *GoDev) onboard(reader *bufio.Reader, interactive bool) error {
+	godev.logger.Warn(godev.messages.Get(MessageOnboardingEmpty, godev.config.WatchDirectory))
+	if interactive && confirm(
+		godev.messages,
+		reader,
+		Color("white", godev.messages.Get(MessageOnboardingQuestion)),
+		false,
+		Color("bold", Color("red", godev.messages.Get(MessageInitRetry))),
+	) {
+		return godev.initialiseDirectory()
+	}
+	godev.logger.Info(godev.messages.Get(MessageOnboardingGuide))
+	godev.logger.Info(godev.messages.Get(MessageOnboardingCommand, godev.config.WorkDirectory))
+	godev.logger.Info(godev.messages.Get(MessageOnboardingConfig, ConfigFileNames[0]))
+	godev.logger.Info(godev.messages.Get(MessageOnboardingWatching, godev.config.WatchDirectory))
+	return nil
+}
+
+// containsGoFiles checks if :directory or any directory below it which
+// is neither hidden nor one of :ignoredNames contains a .go file
+func containsGoFiles(directory string, ignoredNames []string) bool {
+	listings, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return false
+	}
+	for _, listing := range listings {
+		name := listing.Name()
+		if strings.HasPrefix(name, ".") || sliceContainsString(ignoredNames, name) {
+			continue
+		} else if !listing.IsDir() && path.Ext(name) == ".go" {
+			return true
+		} else if listing.IsDir() && containsGoFiles(path.Join(directory, name), ignoredNames) {
+			return true
+		}
+	}
+	return false
+}
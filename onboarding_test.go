@@ -0,0 +1,92 @@
+package godev
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OnboardingTestSuite struct {
+	suite.Suite
+	directory string
+	logs      bytes.Buffer
+}
+
+func TestOnboarding(t *testing.T) {
+	suite.Run(t, new(OnboardingTestSuite))
+}
+
+func (s *OnboardingTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-onboarding")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+	s.logs.Reset()
+}
+
+func (s *OnboardingTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *OnboardingTestSuite) initGoDev() *GoDev {
+	godev := New(&Config{
+		IgnoredNames:   []string{"bin", "vendor"},
+		LogLevel:       "info",
+		WatchDirectory: s.directory,
+		WorkDirectory:  s.directory,
+	})
+	godev.logger.SetOutput(&s.logs)
+	return godev
+}
+
+func (s *OnboardingTestSuite) writeFile(relativePath string) {
+	filePath := path.Join(s.directory, relativePath)
+	os.MkdirAll(path.Dir(filePath), os.ModePerm)
+	if err := ioutil.WriteFile(filePath, []byte("package main\n"), 0644); err != nil {
+		s.T().Errorf("error while writing '%s': %s", filePath, err)
+	}
+}
+
+func (s *OnboardingTestSuite) Test_containsGoFiles() {
+	t := s.T()
+	ignoredNames := []string{"bin", "vendor"}
+	assert.False(t, containsGoFiles(s.directory, ignoredNames))
+	s.writeFile("README.md")
+	s.writeFile("vendor/github.com/x/y/y.go")
+	s.writeFile(".cache/z.go")
+	assert.False(t, containsGoFiles(s.directory, ignoredNames), "expected ignored and hidden directories to be skipped")
+	s.writeFile("cmd/app/main.go")
+	assert.True(t, containsGoFiles(s.directory, ignoredNames))
+	assert.False(t, containsGoFiles(path.Join(s.directory, "missing"), ignoredNames))
+}
+
+func (s *OnboardingTestSuite) Test_needsOnboarding() {
+	t := s.T()
+	godev := s.initGoDev()
+	assert.True(t, godev.needsOnboarding())
+	godev.config.ConfigFile = path.Join(s.directory, "godev.yaml")
+	assert.False(t, godev.needsOnboarding(), "expected a configuration file to skip onboarding")
+	godev.config.ConfigFile = ""
+	s.writeFile("main.go")
+	assert.False(t, godev.needsOnboarding(), "expected go files to skip onboarding")
+}
+
+func (s *OnboardingTestSuite) Test_onboard() {
+	t := s.T()
+	godev := s.initGoDev()
+	assert.Nil(t, godev.onboard(bufio.NewReader(strings.NewReader("")), false))
+	assert.Contains(t, s.logs.String(), godev.messages.Get(MessageOnboardingEmpty, s.directory))
+	assert.Contains(t, s.logs.String(), godev.messages.Get(MessageOnboardingCommand, s.directory))
+	s.logs.Reset()
+	assert.Nil(t, godev.onboard(bufio.NewReader(strings.NewReader("n\n")), true))
+	assert.Contains(t, s.logs.String(), godev.messages.Get(MessageOnboardingCommand, s.directory), "expected guidance when the init wizard is declined")
+	assert.False(t, fileExists(path.Join(s.directory, "main.go")))
+}
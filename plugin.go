@@ -0,0 +1,321 @@
+package godev
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	shellquote "github.com/kballard/go-shellquote"
+)
+
+// DefaultPluginTimeout is how long godev waits for a plugin to reply to
+// an init or trigger message before carrying on without it
+const DefaultPluginTimeout = 5 * time.Second
+
+// plugin message types, see PluginMessage
+const (
+	PluginMessageInit     = "init"
+	PluginMessageEvent    = "event"
+	PluginMessageTrigger  = "trigger"
+	PluginMessageResponse = "response"
+	PluginMessageLog      = "log"
+)
+
+// PluginConfig configures a Plugin
+type PluginConfig struct {
+	// Command is the executable of the plugin followed by its arguments,
+	// relative paths are resolved from WorkDirectory
+	Command string
+	// Executable is a private copy of the executable of Command which
+	// was verified by the policy, it is started instead of the executable
+	// and removed with its directory once the plugin exits
+	Executable    string
+	LogLevel      LogLevel
+	RunState      *RunState
+	Timeout       time.Duration
+	WorkDirectory string
+}
+
+// PluginMessage is a line of JSON exchanged with a plugin over its stdin
+// and stdout:
+//
+//   - godev sends "init" once with its version and the work directory,
+//     the plugin replies with an "init" of the same id with its name and
+//     the execution groups it adds before the last one as steps
+//   - godev sends "event" with every Event published
+//   - godev sends "trigger" with the changed files before file changes
+//     trigger the pipeline, the plugin replies with a "response" of the
+//     same id which vetoes the pipeline with a reason if veto is true
+//   - the plugin sends "log" at any time with a level and a message
+type PluginMessage struct {
+	Type          string   `json:"type"`
+	ID            int      `json:"id,omitempty"`
+	Version       string   `json:"version,omitempty"`
+	WorkDirectory string   `json:"workDirectory,omitempty"`
+	Event         *Event   `json:"event,omitempty"`
+	Files         []string `json:"files,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Steps         []string `json:"steps,omitempty"`
+	Veto          bool     `json:"veto,omitempty"`
+	Reason        string   `json:"reason,omitempty"`
+	Level         string   `json:"level,omitempty"`
+	Message       string   `json:"message,omitempty"`
+}
+
+// InitPlugin returns a Plugin which is not started yet
+func InitPlugin(config *PluginConfig) *Plugin {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultPluginTimeout
+	}
+	sections, _ := shellquote.Split(config.Command)
+	name := config.Command
+	if len(sections) > 0 {
+		name = path.Base(sections[0])
+	}
+	return &Plugin{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:   "plugin",
+			Format: "production",
+			Level:  config.LogLevel,
+			AdditionalFields: &map[string]interface{}{
+				"submodule": name,
+			},
+			RunState: config.RunState,
+		}),
+		name:     name,
+		outgoing: make(chan *PluginMessage, 64),
+		replies:  map[int]chan *PluginMessage{},
+		exited:   make(chan bool),
+		stopping: make(chan bool),
+	}
+}
+
+// Plugin is an executable which extends godev by exchanging
+// PluginMessages with it over its stdin and stdout
+type Plugin struct {
+	config       *PluginConfig
+	logger       *Logger
+	name         string
+	cmd          *exec.Cmd
+	stdin        io.WriteCloser
+	outgoing     chan *PluginMessage
+	replies      map[int]chan *PluginMessage
+	repliesMutex sync.Mutex
+	nextID       int
+	exited       chan bool
+	stopping     chan bool
+	stopOnce     sync.Once
+}
+
+// GetName returns the name the plugin replied to init with, or the
+// name of its executable
+func (plugin *Plugin) GetName() string {
+	return plugin.name
+}
+
+// Start runs the plugin and returns the steps it adds to the pipeline
+// once it has replied to init
+func (plugin *Plugin) Start() ([]string, error) {
+	sections, err := shellquote.Split(plugin.config.Command)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid plugin: %s", plugin.config.Command, err)
+	} else if len(sections) == 0 {
+		return nil, errors.New("no plugin was specified")
+	}
+	application := sections[0]
+	if len(plugin.config.Executable) > 0 {
+		application = plugin.config.Executable
+	} else if strings.Contains(application, "/") && !path.IsAbs(application) {
+		application = path.Join(plugin.config.WorkDirectory, application)
+	}
+	plugin.cmd = exec.Command(application, sections[1:]...)
+	plugin.cmd.Dir = plugin.config.WorkDirectory
+	plugin.cmd.Stderr = os.Stderr
+	if plugin.stdin, err = plugin.cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	stdout, err := plugin.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := plugin.cmd.Start(); err != nil {
+		plugin.removeExecutable()
+		return nil, fmt.Errorf("unable to start plugin '%s': %s", plugin.config.Command, err)
+	}
+	go plugin.readRoutine(stdout)
+	go plugin.writeRoutine()
+	reply := plugin.request(&PluginMessage{
+		Type:          PluginMessageInit,
+		Version:       Version,
+		WorkDirectory: plugin.config.WorkDirectory,
+	})
+	if reply == nil {
+		plugin.Stop()
+		return nil, fmt.Errorf("plugin '%s' did not reply to init within %v", plugin.config.Command, plugin.config.Timeout)
+	}
+	if len(reply.Name) > 0 {
+		plugin.name = reply.Name
+	}
+	return reply.Steps, nil
+}
+
+// Stop closes the stdin of the plugin once the messages queued for it
+// are sent and kills it if it has not exited within the timeout
+func (plugin *Plugin) Stop() {
+	if plugin.cmd == nil || plugin.cmd.Process == nil {
+		return
+	}
+	plugin.stopOnce.Do(func() { close(plugin.stopping) })
+	select {
+	case <-plugin.exited:
+	case <-time.After(plugin.config.Timeout):
+		plugin.logger.Warnf("plugin '%s' did not exit - killing it", plugin.name)
+		plugin.cmd.Process.Kill()
+	}
+}
+
+// HandleEvent passes :event on to the plugin without waiting for it,
+// events are dropped if the plugin falls too far behind
+func (plugin *Plugin) HandleEvent(event *Event) {
+	select {
+	case <-plugin.exited:
+	case plugin.outgoing <- &PluginMessage{Type: PluginMessageEvent, Event: event}:
+	default:
+		plugin.logger.Warnf("plugin '%s' is not keeping up - dropped %s", plugin.name, event.Name)
+	}
+}
+
+// AllowTrigger asks the plugin whether the pipeline should run for the
+// :changedFiles and returns the reason if it should not, plugins which
+// do not reply in time are ignored
+func (plugin *Plugin) AllowTrigger(changedFiles []string) (bool, string) {
+	reply := plugin.request(&PluginMessage{Type: PluginMessageTrigger, Files: changedFiles})
+	if reply == nil {
+		plugin.logger.Warnf("plugin '%s' did not reply to trigger within %v - ignoring it", plugin.name, plugin.config.Timeout)
+		return true, ""
+	}
+	return !reply.Veto, reply.Reason
+}
+
+// request sends :message with a new id and returns the reply of the
+// plugin, or nil if it exited or did not reply in time
+func (plugin *Plugin) request(message *PluginMessage) *PluginMessage {
+	reply := make(chan *PluginMessage, 1)
+	plugin.repliesMutex.Lock()
+	plugin.nextID++
+	message.ID = plugin.nextID
+	plugin.replies[message.ID] = reply
+	plugin.repliesMutex.Unlock()
+	defer func() {
+		plugin.repliesMutex.Lock()
+		delete(plugin.replies, message.ID)
+		plugin.repliesMutex.Unlock()
+	}()
+	timeout := time.After(plugin.config.Timeout)
+	select {
+	case plugin.outgoing <- message:
+	case <-plugin.exited:
+		return nil
+	case <-timeout:
+		return nil
+	}
+	select {
+	case message := <-reply:
+		return message
+	case <-plugin.exited:
+		return nil
+	case <-timeout:
+		return nil
+	}
+}
+
+func (plugin *Plugin) writeRoutine() {
+	encoder := json.NewEncoder(plugin.stdin)
+	send := func(message *PluginMessage) {
+		if err := encoder.Encode(message); err != nil {
+			plugin.logger.Debugf("unable to send %s to plugin '%s': %s", message.Type, plugin.name, err)
+		}
+	}
+	for {
+		select {
+		case message := <-plugin.outgoing:
+			send(message)
+		case <-plugin.stopping:
+			for len(plugin.outgoing) > 0 {
+				send(<-plugin.outgoing)
+			}
+			plugin.stdin.Close()
+			return
+		case <-plugin.exited:
+			return
+		}
+	}
+}
+
+func (plugin *Plugin) readRoutine(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		message := &PluginMessage{}
+		if err := json.Unmarshal(scanner.Bytes(), message); err != nil {
+			plugin.logger.Warnf("plugin '%s' sent an invalid message: %s", plugin.name, err)
+			continue
+		}
+		plugin.handleMessage(message)
+	}
+	err := plugin.cmd.Wait()
+	plugin.removeExecutable()
+	close(plugin.exited)
+	if err != nil {
+		plugin.logger.Warnf("plugin '%s' exited with: %s", plugin.name, err)
+	} else {
+		plugin.logger.Debugf("plugin '%s' exited", plugin.name)
+	}
+}
+
+// removeExecutable removes the private copy of the executable of the
+// plugin which the policy verified, if there is one
+func (plugin *Plugin) removeExecutable() {
+	if len(plugin.config.Executable) > 0 {
+		os.RemoveAll(filepath.Dir(plugin.config.Executable))
+	}
+}
+
+func (plugin *Plugin) handleMessage(message *PluginMessage) {
+	switch message.Type {
+	case PluginMessageInit, PluginMessageResponse:
+		plugin.repliesMutex.Lock()
+		reply, ok := plugin.replies[message.ID]
+		plugin.repliesMutex.Unlock()
+		if ok {
+			select {
+			case reply <- message:
+			default:
+			}
+		} else {
+			plugin.logger.Debugf("plugin '%s' replied to %v after it timed out", plugin.name, message.ID)
+		}
+	case PluginMessageLog:
+		switch message.Level {
+		case "error":
+			plugin.logger.Error(message.Message)
+		case "warn":
+			plugin.logger.Warn(message.Message)
+		case "debug":
+			plugin.logger.Debug(message.Message)
+		default:
+			plugin.logger.Info(message.Message)
+		}
+	default:
+		plugin.logger.Warnf("plugin '%s' sent an unknown message type '%s'", plugin.name, message.Type)
+	}
+}
@@ -0,0 +1,125 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// testPluginScript replies to init with a step, vetoes triggers which
+// include a file named frozen.go and logs the events it receives
+const testPluginScript = `#!/bin/sh
+while read -r line; do
+  id=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  case "$line" in
+    *'"type":"init"'*) echo "{\"type\":\"init\",\"id\":$id,\"name\":\"policy\",\"steps\":[\"echo policy\"]}" ;;
+    *'"type":"trigger"'*frozen.go*) echo "{\"type\":\"response\",\"id\":$id,\"veto\":true,\"reason\":\"frozen\"}" ;;
+    *'"type":"trigger"'*) echo "{\"type\":\"response\",\"id\":$id}" ;;
+    *'"type":"event"'*) echo '{"type":"log","level":"warn","message":"received an event"}' ;;
+  esac
+done
+`
+
+type PluginTestSuite struct {
+	suite.Suite
+	directory string
+	logs      syncBuffer
+}
+
+func TestPlugin(t *testing.T) {
+	suite.Run(t, new(PluginTestSuite))
+}
+
+func (s *PluginTestSuite) SetupTest() {
+	if runtime.GOOS == "windows" {
+		s.T().Skip("the test plugin is a shell script")
+	}
+	directory, err := ioutil.TempDir("", "godev-plugin")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.logs.Reset()
+	assert.Nil(s.T(), ioutil.WriteFile(path.Join(directory, "policy.sh"), []byte(testPluginScript), 0755))
+}
+
+func (s *PluginTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *PluginTestSuite) initPlugin(command string) *Plugin {
+	plugin := InitPlugin(&PluginConfig{
+		Command:       command,
+		Timeout:       time.Second,
+		WorkDirectory: s.directory,
+	})
+	plugin.logger.SetOutput(&s.logs)
+	return plugin
+}
+
+func (s *PluginTestSuite) TestStart() {
+	t := s.T()
+	plugin := s.initPlugin("./policy.sh")
+	assert.Equal(t, "policy.sh", plugin.GetName())
+	steps, err := plugin.Start()
+	defer plugin.Stop()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"echo policy"}, steps)
+	assert.Equal(t, "policy", plugin.GetName())
+}
+
+func (s *PluginTestSuite) TestStart_fromVerifiedExecutable() {
+	t := s.T()
+	contents, err := ioutil.ReadFile(path.Join(s.directory, "policy.sh"))
+	assert.Nil(t, err)
+	executable, err := writePrivateExecutable("./policy.sh", contents)
+	assert.Nil(t, err)
+	assert.Nil(t, os.Remove(path.Join(s.directory, "policy.sh")))
+	plugin := s.initPlugin("./policy.sh")
+	plugin.config.Executable = executable
+	steps, err := plugin.Start()
+	assert.Nil(t, err, "expected the verified copy to be started instead of the executable")
+	assert.Equal(t, []string{"echo policy"}, steps)
+	plugin.Stop()
+	assert.False(t, fileExists(executable), "expected the verified copy to be removed once the plugin exits")
+}
+
+func (s *PluginTestSuite) TestStart_withoutReply() {
+	t := s.T()
+	plugin := s.initPlugin("sleep 10")
+	plugin.config.Timeout = 100 * time.Millisecond
+	_, err := plugin.Start()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "did not reply to init")
+	_, err = s.initPlugin("./missing.sh").Start()
+	assert.NotNil(t, err)
+}
+
+func (s *PluginTestSuite) TestAllowTrigger() {
+	t := s.T()
+	plugin := s.initPlugin("./policy.sh")
+	_, err := plugin.Start()
+	defer plugin.Stop()
+	assert.Nil(t, err)
+	allowed, _ := plugin.AllowTrigger([]string{"/work/main.go"})
+	assert.True(t, allowed)
+	allowed, reason := plugin.AllowTrigger([]string{"/work/frozen.go"})
+	assert.False(t, allowed)
+	assert.Equal(t, "frozen", reason)
+}
+
+func (s *PluginTestSuite) TestHandleEvent() {
+	t := s.T()
+	plugin := s.initPlugin("./policy.sh")
+	_, err := plugin.Start()
+	assert.Nil(t, err)
+	plugin.HandleEvent(&Event{Name: EventBuildFinished})
+	plugin.Stop()
+	assert.Contains(t, s.logs.String(), "received an event")
+	allowed, _ := plugin.AllowTrigger(nil)
+	assert.True(t, allowed, "expected plugins which exited to be ignored")
+}
@@ -0,0 +1,251 @@
+package godev
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// PolicyFileName is the name of the policy file in the godev directory of
+// the user's configuration directory, it is used when --policy is not
+// specified
+const PolicyFileName = "policy.yaml"
+
+// PluginSignatureExtension is appended to the path of the executable of
+// a plugin to find its detached signature
+const PluginSignatureExtension = ".sig"
+
+// Policy restricts the executables which commands can run so that the
+// configuration files of shared repositories cannot run anything they
+// like - it belongs to the user and not to the project
+type Policy struct {
+	// Allow are the patterns of the executables commands can run, any
+	// executable can be run when it is empty
+	Allow []string `yaml:"allow"`
+	// DenyNetwork are the patterns of the executables which are run
+	// without network access
+	DenyNetwork []string `yaml:"deny-network"`
+	// Plugins are the SHA-256 checksums of the executables of the plugins
+	// which can be started, keyed by the executable as it is written in
+	// the plugin's command - any plugin can be started when it and
+	// PluginKeys are empty
+	Plugins map[string]string `yaml:"plugins"`
+	// PluginKeys are the ed25519 public keys in base64 whose detached
+	// signatures of the executables of plugins are trusted, a plugin
+	// which is not pinned in Plugins can only be started with one
+	PluginKeys []string `yaml:"plugin-keys"`
+	// Path is where the policy was loaded from
+	Path string `yaml:"-"`
+}
+
+// GetDefaultPolicyPath returns the path of the policy file which is used
+// when --policy is not specified, it is empty when the user has no
+// configuration directory
+func GetDefaultPolicyPath() string {
+	configDirectory, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return path.Join(filepath.ToSlash(configDirectory), "godev", PolicyFileName)
+}
+
+// LoadPolicy reads the policy at :filePath
+func LoadPolicy(filePath string) (*Policy, error) {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{Path: filePath}
+	if err := yaml.UnmarshalStrict(contents, policy); err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid policy: %s", filePath, err)
+	}
+	for _, pattern := range append(append([]string{}, policy.Allow...), policy.DenyNetwork...) {
+		if _, err := path.Match(pattern, ""); err != nil || len(pattern) == 0 {
+			return nil, fmt.Errorf("'%s' has an invalid pattern '%s'", filePath, pattern)
+		}
+	}
+	for application, checksum := range policy.Plugins {
+		if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("'%s' has an invalid checksum '%s' for the plugin '%s' (expected a sha256 hash in hex)", filePath, checksum, application)
+		}
+	}
+	for _, key := range policy.PluginKeys {
+		if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("'%s' has an invalid plugin key '%s' (expected an ed25519 public key in base64)", filePath, key)
+		}
+	}
+	return policy, nil
+}
+
+// ResolvePolicy loads the policy at :filePath, or at the default path
+// when it is empty and there is a policy there - it returns nil when
+// there is no policy
+func ResolvePolicy(filePath string) (*Policy, error) {
+	if len(filePath) == 0 {
+		if filePath = GetDefaultPolicyPath(); len(filePath) == 0 || !fileExists(filePath) {
+			return nil, nil
+		}
+	}
+	return LoadPolicy(filePath)
+}
+
+// Check returns an error when the policy does not allow :application to
+// be run in :directory, a nil policy allows everything
+func (policy *Policy) Check(application, directory string) error {
+	if policy == nil || len(policy.Allow) == 0 || matchAnyPolicyPattern(policy.Allow, application, directory) {
+		return nil
+	}
+	return fmt.Errorf("'%s' is not allowed by the policy at '%s' - add it to its allow list to run it", application, policy.Path)
+}
+
+// DeniesNetwork checks whether :application is run in :directory
+// without network access, a nil policy denies nothing
+func (policy *Policy) DeniesNetwork(application, directory string) bool {
+	return policy != nil && matchAnyPolicyPattern(policy.DenyNetwork, application, directory)
+}
+
+// Command returns the command which runs :application with :arguments
+// in :directory once the policy allows it, it has no network access when
+// the policy denies it - commands which godev runs for a project are
+// created with it so that none of them bypass the policy
+func (policy *Policy) Command(directory, application string, arguments ...string) (*exec.Cmd, error) {
+	if err := policy.Check(application, directory); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(application, arguments...)
+	cmd.Dir = directory
+	if policy.DeniesNetwork(application, directory) {
+		var err error
+		if cmd.SysProcAttr, err = denyNetwork(nil); err != nil {
+			return nil, err
+		}
+	}
+	return cmd, nil
+}
+
+// VerifyPlugin verifies the executable :application of a plugin started
+// in :directory when the policy pins the executables of plugins, either
+// to their checksums or to the keys which sign them, and returns the path
+// of a private copy of the bytes which were verified so that the plugin
+// is started from them and not from an executable which was swapped
+// after its verification - the path is empty when the policy pins
+// nothing and the plugin is started as it is
+func (policy *Policy) VerifyPlugin(application, directory string) (string, error) {
+	if policy == nil || (len(policy.Plugins) == 0 && len(policy.PluginKeys) == 0) {
+		return "", nil
+	} else if _, ok := policy.Plugins[application]; !ok && len(policy.PluginKeys) == 0 {
+		return "", fmt.Errorf("'%s' is not pinned by the policy at '%s' - add its checksum to its plugins to start it", application, policy.Path)
+	}
+	executablePath, err := getExecutablePath(application, directory)
+	if err != nil {
+		return "", fmt.Errorf("'%s' could not be verified: %s", application, err)
+	}
+	executable, err := ioutil.ReadFile(executablePath)
+	if err != nil {
+		return "", fmt.Errorf("'%s' could not be verified: %s", application, err)
+	}
+	if err := policy.verifyPluginExecutable(application, executablePath, executable); err != nil {
+		return "", err
+	}
+	privatePath, err := writePrivateExecutable(application, executable)
+	if err != nil {
+		return "", fmt.Errorf("'%s' could not be copied for it to be started: %s", application, err)
+	}
+	return privatePath, nil
+}
+
+// verifyPluginExecutable checks :executable, the contents of the plugin
+// :application at :executablePath, against its checksum when it is
+// pinned and against its detached signature otherwise
+func (policy *Policy) verifyPluginExecutable(application, executablePath string, executable []byte) error {
+	hash := sha256.Sum256(executable)
+	checksum := hex.EncodeToString(hash[:])
+	if expectedChecksum, ok := policy.Plugins[application]; ok {
+		if !strings.EqualFold(checksum, expectedChecksum) {
+			return fmt.Errorf("'%s' does not match its checksum in the policy at '%s' (its checksum is %s)", application, policy.Path, checksum)
+		}
+		return nil
+	}
+	signaturePath := executablePath + PluginSignatureExtension
+	contents, err := ioutil.ReadFile(signaturePath)
+	if err != nil {
+		return fmt.Errorf("'%s' is neither pinned nor signed for the policy at '%s': %s", application, policy.Path, err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(contents)))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("'%s' is not a valid signature (expected an ed25519 signature in base64)", signaturePath)
+	}
+	for _, key := range policy.PluginKeys {
+		if publicKey, err := base64.StdEncoding.DecodeString(key); err == nil && len(publicKey) == ed25519.PublicKeySize && ed25519.Verify(publicKey, executable, signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' is not signed by any of the plugin keys of the policy at '%s'", application, policy.Path)
+}
+
+// getExecutablePath returns the path of the executable :application,
+// which is looked up in the PATH when it is a name and relative to
+// :directory when it is a relative path
+func getExecutablePath(application, directory string) (string, error) {
+	if !strings.ContainsAny(application, "/\\") {
+		return exec.LookPath(application)
+	} else if !filepath.IsAbs(application) {
+		return filepath.Join(directory, application), nil
+	}
+	return application, nil
+}
+
+// writePrivateExecutable writes :executable to a new directory which only
+// the current user can access and returns the path of the copy, which
+// keeps the name of :application
+func writePrivateExecutable(application string, executable []byte) (string, error) {
+	privateDirectory, err := ioutil.TempDir("", "godev-plugin-")
+	if err != nil {
+		return "", err
+	}
+	privatePath := filepath.Join(privateDirectory, filepath.Base(filepath.FromSlash(application)))
+	if err := ioutil.WriteFile(privatePath, executable, 0700); err != nil {
+		os.RemoveAll(privateDirectory)
+		return "", err
+	}
+	return privatePath, nil
+}
+
+// matchAnyPolicyPattern checks whether :application as it is written in
+// a command matches any of :patterns - patterns without a slash match
+// executables looked up in the PATH by their name, other patterns match
+// the path of the executable where relative paths are relative to
+// :directory, which the command runs in, and "**" matches any number of
+// directories
+func matchAnyPolicyPattern(patterns []string, application, directory string) bool {
+	application = filepath.ToSlash(application)
+	applicationPath := application
+	if !path.IsAbs(applicationPath) {
+		applicationPath = path.Join(filepath.ToSlash(directory), applicationPath)
+	}
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, application); matched && !strings.Contains(application, "/") {
+				return true
+			}
+			continue
+		}
+		if !path.IsAbs(pattern) {
+			pattern = path.Join(filepath.ToSlash(directory), pattern)
+		}
+		if matchPatternSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(strings.Trim(applicationPath, "/"), "/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package godev
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PolicyTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestPolicy(t *testing.T) {
+	suite.Run(t, new(PolicyTestSuite))
+}
+
+func (s *PolicyTestSuite) SetupTest() {
+	var err error
+	s.directory, err = ioutil.TempDir("", "godev-policy")
+	assert.Nil(s.T(), err)
+}
+
+func (s *PolicyTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+// writePolicy writes :contents to a policy file and returns its path
+func (s *PolicyTestSuite) writePolicy(contents string) string {
+	filePath := path.Join(s.directory, PolicyFileName)
+	assert.Nil(s.T(), ioutil.WriteFile(filePath, []byte(contents), 0644))
+	return filePath
+}
+
+func (s *PolicyTestSuite) TestLoadPolicy() {
+	t := s.T()
+	filePath := s.writePolicy("allow: [go, make, bin/*]\ndeny-network: [npm]\n")
+	policy, err := LoadPolicy(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, &Policy{
+		Allow:       []string{"go", "make", "bin/*"},
+		DenyNetwork: []string{"npm"},
+		Path:        filePath,
+	}, policy)
+	for _, contents := range []string{
+		"allow: go\n",
+		"allow: [\"[\"]\n",
+		"allow: [\"\"]\n",
+		"deny: [curl]\n",
+		"plugins: {./plugin: abc}\n",
+		"plugins: {./plugin: " + strings.Repeat("z", 64) + "}\n",
+		"plugin-keys: [abc]\n",
+	} {
+		_, err := LoadPolicy(s.writePolicy(contents))
+		assert.NotNilf(t, err, "expected %q to be invalid", contents)
+	}
+}
+
+func (s *PolicyTestSuite) TestResolvePolicy() {
+	t := s.T()
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	os.Setenv("XDG_CONFIG_HOME", s.directory)
+	policy, err := ResolvePolicy("")
+	assert.Nil(t, err)
+	assert.Nil(t, policy, "expected no policy when there is none at the default path")
+	assert.Nil(t, os.MkdirAll(path.Join(s.directory, "godev"), 0755))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "godev", PolicyFileName), []byte("allow: [go]\n"), 0644))
+	policy, err = ResolvePolicy("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go"}, policy.Allow)
+	policy, err = ResolvePolicy(s.writePolicy("allow: [make]\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"make"}, policy.Allow, "expected --policy to take precedence")
+}
+
+func (s *PolicyTestSuite) TestCheck() {
+	t := s.T()
+	policy := &Policy{Allow: []string{"go", "golang*", "bin/*", "/usr/local/bin/**"}, Path: "policy.yaml"}
+	for _, application := range []string{"go", "golangci-lint", "bin/app", "./bin/app", "/work/bin/app", "/usr/local/bin/tools/protoc"} {
+		assert.Nilf(t, policy.Check(application, "/work"), "expected '%s' to be allowed", application)
+	}
+	for _, application := range []string{"curl", "./go", "tools/go", "bin/tools/app", "../bin/app", "/bin/sh"} {
+		assert.NotNilf(t, policy.Check(application, "/work"), "expected '%s' to be denied", application)
+	}
+	assert.Contains(t, policy.Check("curl", "/work").Error(), "'curl' is not allowed by the policy at 'policy.yaml'")
+	assert.Nil(t, (&Policy{}).Check("curl", "/work"), "expected an empty allow list to allow everything")
+	var noPolicy *Policy
+	assert.Nil(t, noPolicy.Check("curl", "/work"))
+}
+
+func (s *PolicyTestSuite) TestCommand() {
+	t := s.T()
+	policy := &Policy{Allow: []string{"go"}, Path: "policy.yaml"}
+	cmd, err := policy.Command("/work", "go", "env", "GOVERSION")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go", "env", "GOVERSION"}, cmd.Args)
+	assert.Equal(t, "/work", cmd.Dir)
+	assert.Nil(t, cmd.SysProcAttr, "expected commands with network access to keep their attributes")
+	_, err = policy.Command("/work", "curl", "example.com")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'curl' is not allowed by the policy at 'policy.yaml'")
+	var noPolicy *Policy
+	cmd, err = noPolicy.Command("/work", "curl", "example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"curl", "example.com"}, cmd.Args)
+}
+
+func (s *PolicyTestSuite) TestVerifyPlugin() {
+	t := s.T()
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "plugin"), []byte("#!/bin/sh\n"), 0755))
+	checksum := "a8076d3d28d21e02012b20eaf7dbf75409a6277134439025f282e368e3305abf"
+	policy := &Policy{Plugins: map[string]string{"./plugin": strings.ToUpper(checksum)}, Path: "policy.yaml"}
+	executable, err := policy.VerifyPlugin("./plugin", s.directory)
+	assert.Nil(t, err, "expected checksums to be compared case-insensitively")
+	defer os.RemoveAll(path.Dir(executable))
+	assert.NotEqual(t, path.Join(s.directory, "plugin"), executable, "expected the plugin to be started from a private copy")
+	assert.Equal(t, "plugin", path.Base(executable))
+	contents, err := ioutil.ReadFile(executable)
+	assert.Nil(t, err)
+	assert.Equal(t, "#!/bin/sh\n", string(contents))
+	_, err = policy.VerifyPlugin("./other-plugin", s.directory)
+	assert.Contains(t, err.Error(), "'./other-plugin' is not pinned by the policy at 'policy.yaml'")
+	policy.Plugins["./plugin"] = strings.Repeat("0", 64)
+	_, err = policy.VerifyPlugin("./plugin", s.directory)
+	assert.Contains(t, err.Error(), "'./plugin' does not match its checksum in the policy at 'policy.yaml' (its checksum is "+checksum+")")
+	policy.Plugins["./missing"] = checksum
+	_, err = policy.VerifyPlugin("./missing", s.directory)
+	assert.Contains(t, err.Error(), "'./missing' could not be verified")
+	executable, err = (&Policy{}).VerifyPlugin("./other-plugin", s.directory)
+	assert.Nil(t, err, "expected policies without plugins to start any plugin")
+	assert.Empty(t, executable, "expected unverified plugins to be started as they are")
+	var noPolicy *Policy
+	_, err = noPolicy.VerifyPlugin("./other-plugin", s.directory)
+	assert.Nil(t, err)
+}
+
+func (s *PolicyTestSuite) TestVerifyPlugin_withSignature() {
+	t := s.T()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	contents := []byte("#!/bin/sh\n")
+	pluginPath := path.Join(s.directory, "plugin")
+	assert.Nil(t, ioutil.WriteFile(pluginPath, contents, 0755))
+	policy := &Policy{PluginKeys: []string{base64.StdEncoding.EncodeToString(publicKey)}, Path: "policy.yaml"}
+	_, err = policy.VerifyPlugin("./plugin", s.directory)
+	assert.Contains(t, err.Error(), "'./plugin' is neither pinned nor signed for the policy at 'policy.yaml'")
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, contents))
+	assert.Nil(t, ioutil.WriteFile(pluginPath+PluginSignatureExtension, []byte(signature+"\n"), 0644))
+	executable, err := policy.VerifyPlugin("./plugin", s.directory)
+	assert.Nil(t, err)
+	defer os.RemoveAll(path.Dir(executable))
+	assert.NotEmpty(t, executable)
+	assert.Nil(t, ioutil.WriteFile(pluginPath, []byte("#!/bin/sh\nrm -rf ~\n"), 0755))
+	_, err = policy.VerifyPlugin("./plugin", s.directory)
+	assert.Contains(t, err.Error(), "'./plugin' is not signed by any of the plugin keys of the policy at 'policy.yaml'")
+	copied, err := ioutil.ReadFile(executable)
+	assert.Nil(t, err)
+	assert.Equal(t, contents, copied, "expected the verified copy to keep the bytes which were verified")
+	assert.Nil(t, ioutil.WriteFile(pluginPath+PluginSignatureExtension, []byte("not a signature"), 0644))
+	_, err = policy.VerifyPlugin("./plugin", s.directory)
+	assert.Contains(t, err.Error(), "is not a valid signature")
+}
+
+func (s *PolicyTestSuite) TestDeniesNetwork() {
+	t := s.T()
+	policy := &Policy{DenyNetwork: []string{"npm", "scripts/**"}}
+	assert.True(t, policy.DeniesNetwork("npm", "/work"))
+	assert.True(t, policy.DeniesNetwork("scripts/seed/run.sh", "/work"))
+	assert.False(t, policy.DeniesNetwork("go", "/work"))
+	var noPolicy *Policy
+	assert.False(t, noPolicy.DeniesNetwork("npm", "/work"))
+}
@@ -0,0 +1,314 @@
+package godev
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProjectLockFileName is the name of the file in the project directory
+// which holds the process ID of the godev using it
+const ProjectLockFileName = "godev.lock"
+
+// ProjectMarkerFileName is the name of the file which marks a directory
+// as a project directory created by godev so that clean never removes
+// a directory which godev did not create
+const ProjectMarkerFileName = "godev.project"
+
+// ProjectHistoryFileName is the name of the file in the project directory
+// which pipeline runs are appended to as JSON lines
+const ProjectHistoryFileName = "history.jsonl"
+
+// ProjectRunsDirectoryName is the name of the directory in the project
+// directory which holds the recorded output of runs, only the last
+// ProjectRunsKept runs are kept
+const (
+	ProjectRunsDirectoryName = "runs"
+	ProjectRunsKept          = 20
+)
+
+// names of the files in the directory of a recorded run
+const (
+	RunStdoutFileName = "stdout.log"
+	RunStderrFileName = "stderr.log"
+	RunTestsFileName  = "tests.json"
+)
+
+// ProjectCoverageDirectoryName is the name of the directory in the
+// project directory which holds coverage profiles
+const ProjectCoverageDirectoryName = "coverage"
+
+// ProjectDirectoryConfig configures ProjectDirectory
+type ProjectDirectoryConfig struct {
+	LogLevel LogLevel
+	Path     string
+	RunState *RunState
+}
+
+// InitProjectDirectory creates a handle on the per-project directory
+// which holds godev's caches, run history and lock file
+func InitProjectDirectory(config *ProjectDirectoryConfig) *ProjectDirectory {
+	return &ProjectDirectory{
+		config: config,
+		logger: InitLogger(&LoggerConfig{Name: "project", Format: "production", Level: config.LogLevel, RunState: config.RunState}),
+	}
+}
+
+// ProjectDirectory is the per-project directory (.godev by default)
+// which godev keeps its artifacts in
+type ProjectDirectory struct {
+	config *ProjectDirectoryConfig
+	logger *Logger
+	locked bool
+}
+
+// RunHistoryEntry is a record of a pipeline run in the run history
+type RunHistoryEntry struct {
+	ID        string    `json:"id,omitempty"`
+	Pipeline  int       `json:"pipeline"`
+	StartedAt time.Time `json:"startedAt"`
+	Duration  string    `json:"duration"`
+	Failed    bool      `json:"failed"`
+	Warnings  int       `json:"warnings"`
+	Recorded  bool      `json:"recorded,omitempty"`
+}
+
+// RunOutput is the recorded output of a run and the test results
+// parsed from it
+type RunOutput struct {
+	Stdout string
+	Stderr string
+	Tests  []TestResult
+}
+
+// GetPath returns the path to :elements in the project directory
+func (project *ProjectDirectory) GetPath(elements ...string) string {
+	return path.Join(append([]string{project.config.Path}, elements...)...)
+}
+
+// Init creates the project directory with a .gitignore so that it
+// never ends up in version control
+func (project *ProjectDirectory) Init() error {
+	if err := os.MkdirAll(project.GetPath(ProjectCoverageDirectoryName), 0755); err != nil {
+		return fmt.Errorf("unable to create the project directory at '%s': %s", project.config.Path, err)
+	}
+	gitignorePath := project.GetPath(".gitignore")
+	if !fileExists(gitignorePath) {
+		if err := ioutil.WriteFile(gitignorePath, []byte("*\n"), 0644); err != nil {
+			return err
+		}
+	}
+	markerPath := project.GetPath(ProjectMarkerFileName)
+	if !fileExists(markerPath) {
+		if err := ioutil.WriteFile(markerPath, []byte("created by godev, removed by godev clean\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLockingProcess returns the process ID of the godev which holds the
+// lock on the project directory or 0 if it is not locked by a live process
+func (project *ProjectDirectory) GetLockingProcess() int {
+	contents, err := ioutil.ReadFile(project.GetPath(ProjectLockFileName))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil || !processExists(pid) {
+		return 0
+	}
+	return pid
+}
+
+// Lock records this process as the one using the project directory, an
+// error is returned if another live godev holds the lock - the lock file
+// is created exclusively so that two godevs starting together cannot
+// both take it
+func (project *ProjectDirectory) Lock() error {
+	lockPath := project.GetPath(ProjectLockFileName)
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) {
+			if pid := project.GetLockingProcess(); pid == os.Getpid() {
+				project.locked = true
+				return nil
+			} else if pid > 0 {
+				return fmt.Errorf("another godev (pid %v) is using '%s'", pid, project.config.Path)
+			}
+			if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		_, err = file.WriteString(strconv.Itoa(os.Getpid()))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(lockPath)
+			return err
+		}
+		project.locked = true
+		return nil
+	}
+	return fmt.Errorf("unable to lock '%s'", project.config.Path)
+}
+
+// Unlock releases the lock if this process holds it
+func (project *ProjectDirectory) Unlock() {
+	if project.locked {
+		os.Remove(project.GetPath(ProjectLockFileName))
+		project.locked = false
+	}
+}
+
+// AppendHistory appends :entry to the run history
+func (project *ProjectDirectory) AppendHistory(entry *RunHistoryEntry) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(project.GetPath(ProjectHistoryFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(encoded, '\n'))
+	return err
+}
+
+// GetHistory returns the runs in the run history from oldest to newest
+func (project *ProjectDirectory) GetHistory() ([]RunHistoryEntry, error) {
+	contents, err := ioutil.ReadFile(project.GetPath(ProjectHistoryFileName))
+	if err != nil {
+		return nil, err
+	}
+	var history []RunHistoryEntry
+	for index, line := range strings.Split(string(contents), "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry RunHistoryEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("line %v of '%s' is not a valid run: %s", index+1, project.GetPath(ProjectHistoryFileName), err)
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+// SaveRunOutput writes the output captured by :recorder and the test
+// results parsed from it for the run with ID :id, removing the oldest
+// recorded runs beyond ProjectRunsKept
+func (project *ProjectDirectory) SaveRunOutput(id string, recorder *RunRecorder) error {
+	runDirectory := project.GetPath(ProjectRunsDirectoryName, id)
+	if err := os.MkdirAll(runDirectory, 0755); err != nil {
+		return err
+	}
+	stdout := recorder.Stdout.String()
+	if err := ioutil.WriteFile(path.Join(runDirectory, RunStdoutFileName), []byte(stdout), 0644); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(runDirectory, RunStderrFileName), []byte(recorder.Stderr.String()), 0644); err != nil {
+		return err
+	}
+	tests, err := json.MarshalIndent(ParseTestResults(stdout), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(runDirectory, RunTestsFileName), tests, 0644); err != nil {
+		return err
+	}
+	return project.pruneRunOutputs()
+}
+
+// LoadRunOutput reads the recorded output of the run with ID :id
+func (project *ProjectDirectory) LoadRunOutput(id string) (*RunOutput, error) {
+	runDirectory := project.GetPath(ProjectRunsDirectoryName, id)
+	if !directoryExists(runDirectory) {
+		return nil, fmt.Errorf("run '%s' has no recorded output", id)
+	}
+	stdout, err := ioutil.ReadFile(path.Join(runDirectory, RunStdoutFileName))
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := ioutil.ReadFile(path.Join(runDirectory, RunStderrFileName))
+	if err != nil {
+		return nil, err
+	}
+	output := &RunOutput{Stdout: string(stdout), Stderr: string(stderr)}
+	tests, err := ioutil.ReadFile(path.Join(runDirectory, RunTestsFileName))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tests, &output.Tests); err != nil {
+		return nil, fmt.Errorf("unable to read the test results of run '%s': %s", id, err)
+	}
+	return output, nil
+}
+
+// pruneRunOutputs removes the oldest recorded runs so that at most
+// ProjectRunsKept remain, run IDs sort by the time they started
+func (project *ProjectDirectory) pruneRunOutputs() error {
+	listings, err := ioutil.ReadDir(project.GetPath(ProjectRunsDirectoryName))
+	if err != nil {
+		return err
+	}
+	for index := 0; index < len(listings)-ProjectRunsKept; index++ {
+		if err := os.RemoveAll(project.GetPath(ProjectRunsDirectoryName, listings[index].Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clean removes the project directory unless a live godev is using it,
+// the certificates are kept so that a CA which has been trusted stays
+// valid - directories which contain :workDirectory or were not created
+// by godev are never removed
+func (project *ProjectDirectory) Clean(workDirectory string) error {
+	projectPath, err := filepath.Abs(project.config.Path)
+	if err != nil {
+		return err
+	}
+	workPath, err := filepath.Abs(workDirectory)
+	if err != nil {
+		return err
+	}
+	if relativePath, err := filepath.Rel(projectPath, workPath); err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("'%s' contains the work directory '%s' - refusing to clean it", project.config.Path, workDirectory)
+	}
+	if !fileExists(project.GetPath(ProjectMarkerFileName)) && !fileExists(project.GetPath(ProjectLockFileName)) {
+		return fmt.Errorf("'%s' was not created by godev - refusing to clean it", project.config.Path)
+	}
+	if pid := project.GetLockingProcess(); pid > 0 && pid != os.Getpid() {
+		return fmt.Errorf("'%s' is in use by godev (pid %v) - stop it before cleaning", project.config.Path, pid)
+	}
+	if !directoryExists(project.GetPath(ProjectCertsDirectoryName)) {
+		return os.RemoveAll(project.config.Path)
+	}
+	listings, err := ioutil.ReadDir(project.config.Path)
+	if err != nil {
+		return err
+	}
+	for _, listing := range listings {
+		switch listing.Name() {
+		case ProjectCertsDirectoryName, ProjectMarkerFileName, ".gitignore":
+			continue
+		}
+		if err := os.RemoveAll(project.GetPath(listing.Name())); err != nil {
+			return err
+		}
+	}
+	project.locked = false
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"os"
+	"syscall"
+)
+
+// processExists checks whether a process with :pid is running
+func processExists(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"os"
+)
+
+// processExists checks whether a process with :pid is running
+func processExists(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
@@ -0,0 +1,180 @@
+package godev
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProjectDirectoryTestSuite struct {
+	suite.Suite
+	directory string
+	project   *ProjectDirectory
+}
+
+func TestProjectDirectory(t *testing.T) {
+	suite.Run(t, new(ProjectDirectoryTestSuite))
+}
+
+func (s *ProjectDirectoryTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-project")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.project = InitProjectDirectory(&ProjectDirectoryConfig{Path: path.Join(directory, ".godev")})
+}
+
+func (s *ProjectDirectoryTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *ProjectDirectoryTestSuite) TestInit() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	assert.True(t, directoryExists(s.project.GetPath(ProjectCoverageDirectoryName)))
+	gitignore, err := ioutil.ReadFile(s.project.GetPath(".gitignore"))
+	assert.Nil(t, err)
+	assert.Equal(t, "*\n", string(gitignore))
+	assert.True(t, fileExists(s.project.GetPath(ProjectMarkerFileName)))
+	assert.Nil(t, s.project.Init(), "expected initialising an existing project directory to succeed")
+}
+
+func (s *ProjectDirectoryTestSuite) TestLockAndUnlock() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	assert.Equal(t, 0, s.project.GetLockingProcess())
+	assert.Nil(t, s.project.Lock())
+	assert.Equal(t, os.Getpid(), s.project.GetLockingProcess())
+	s.project.Unlock()
+	assert.False(t, fileExists(s.project.GetPath(ProjectLockFileName)))
+	parentPid := os.Getppid()
+	ioutil.WriteFile(s.project.GetPath(ProjectLockFileName), []byte(strconv.Itoa(parentPid)), 0644)
+	assert.NotNil(t, s.project.Lock(), "expected a lock held by a live process to be respected")
+	assert.NotNil(t, s.project.Clean(s.directory))
+	ioutil.WriteFile(s.project.GetPath(ProjectLockFileName), []byte("999999999"), 0644)
+	assert.Equal(t, 0, s.project.GetLockingProcess(), "expected a stale lock to be ignored")
+	assert.Nil(t, s.project.Lock())
+}
+
+func (s *ProjectDirectoryTestSuite) TestAppendHistory() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	startedAt := time.Now()
+	assert.Nil(t, s.project.AppendHistory(&RunHistoryEntry{Pipeline: 1, StartedAt: startedAt, Duration: "1s"}))
+	assert.Nil(t, s.project.AppendHistory(&RunHistoryEntry{Pipeline: 2, StartedAt: startedAt, Duration: "2s", Failed: true}))
+	file, err := os.Open(s.project.GetPath(ProjectHistoryFileName))
+	assert.Nil(t, err)
+	defer file.Close()
+	var entries []RunHistoryEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry RunHistoryEntry
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	assert.Len(t, entries, 2)
+	assert.True(t, entries[1].Failed)
+}
+
+func (s *ProjectDirectoryTestSuite) TestClean() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	assert.Nil(t, s.project.Lock())
+	assert.Nil(t, s.project.Clean(s.directory), "expected the lock of this process to not prevent cleaning")
+	assert.False(t, directoryExists(s.project.GetPath()))
+}
+
+func (s *ProjectDirectoryTestSuite) TestClean_keepsCertificates() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	assert.Nil(t, os.MkdirAll(s.project.GetPath(ProjectCertsDirectoryName), 0700))
+	assert.Nil(t, ioutil.WriteFile(s.project.GetPath(ProjectCertsDirectoryName, CertsCAFileName), []byte("ca"), 0644))
+	assert.Nil(t, s.project.AppendHistory(&RunHistoryEntry{Pipeline: 1}))
+	assert.Nil(t, s.project.Clean(s.directory))
+	assert.True(t, fileExists(s.project.GetPath(ProjectCertsDirectoryName, CertsCAFileName)), "expected the CA to be kept")
+	assert.False(t, fileExists(s.project.GetPath(ProjectHistoryFileName)))
+	assert.False(t, directoryExists(s.project.GetPath(ProjectCoverageDirectoryName)))
+}
+
+func (s *ProjectDirectoryTestSuite) TestClean_refusesUnsafeDirectories() {
+	t := s.T()
+	unmarked := path.Join(s.directory, "src")
+	assert.Nil(t, os.MkdirAll(unmarked, 0755))
+	project := InitProjectDirectory(&ProjectDirectoryConfig{Path: unmarked})
+	assert.NotNil(t, project.Clean(s.directory), "expected a directory not created by godev to be refused")
+	assert.True(t, directoryExists(unmarked))
+	assert.Nil(t, project.Init())
+	assert.NotNil(t, project.Clean(unmarked), "expected the work directory to be refused")
+	assert.NotNil(t, project.Clean(path.Join(unmarked, "cmd")), "expected a parent of the work directory to be refused")
+	assert.True(t, directoryExists(unmarked))
+	assert.Nil(t, project.Clean(s.directory))
+}
+
+func (s *ProjectDirectoryTestSuite) TestLock_isExclusive() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	assert.Nil(t, s.project.Lock())
+	assert.Nil(t, s.project.Lock(), "expected locking again from the same process to succeed")
+	s.project.Unlock()
+	parentPid := strconv.Itoa(os.Getppid())
+	assert.Nil(t, ioutil.WriteFile(s.project.GetPath(ProjectLockFileName), []byte(parentPid), 0644))
+	assert.NotNil(t, s.project.Lock())
+	contents, err := ioutil.ReadFile(s.project.GetPath(ProjectLockFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, parentPid, string(contents), "expected the lock of another process to be left alone")
+}
+
+func (s *ProjectDirectoryTestSuite) TestGetHistory() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	_, err := s.project.GetHistory()
+	assert.NotNil(t, err, "expected an error without a run history")
+	assert.Nil(t, s.project.AppendHistory(&RunHistoryEntry{ID: "20190304-151617-1", Pipeline: 1}))
+	assert.Nil(t, s.project.AppendHistory(&RunHistoryEntry{ID: "20190304-151620-2", Pipeline: 2, Failed: true, Recorded: true}))
+	history, err := s.project.GetHistory()
+	assert.Nil(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "20190304-151617-1", history[0].ID)
+	assert.True(t, history[1].Failed)
+	assert.True(t, history[1].Recorded)
+}
+
+func (s *ProjectDirectoryTestSuite) TestSaveAndLoadRunOutput() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	recorder := InitRunRecorder()
+	recorder.Stdout.Write([]byte("--- FAIL: TestSum (0.00s)\nFAIL\texample.com/app\t0.01s\n"))
+	recorder.Stderr.Write([]byte("exit status 1\n"))
+	assert.Nil(t, s.project.SaveRunOutput("20190304-151617-1", recorder))
+	output, err := s.project.LoadRunOutput("20190304-151617-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "exit status 1\n", output.Stderr)
+	assert.Contains(t, output.Stdout, "--- FAIL: TestSum")
+	assert.Equal(t, []TestResult{
+		{Package: "example.com/app", Status: "fail"},
+		{Package: "example.com/app", Test: "TestSum", Status: "fail"},
+	}, output.Tests)
+	_, err = s.project.LoadRunOutput("20190304-151620-2")
+	assert.NotNil(t, err)
+}
+
+func (s *ProjectDirectoryTestSuite) TestSaveRunOutputPrunesOldRuns() {
+	t := s.T()
+	assert.Nil(t, s.project.Init())
+	recorder := InitRunRecorder()
+	for index := 1; index <= ProjectRunsKept+2; index++ {
+		assert.Nil(t, s.project.SaveRunOutput(fmt.Sprintf("20190304-1516%02d-%v", index, index), recorder))
+	}
+	listings, err := ioutil.ReadDir(s.project.GetPath(ProjectRunsDirectoryName))
+	assert.Nil(t, err)
+	assert.Len(t, listings, ProjectRunsKept)
+	assert.Equal(t, "20190304-151603-3", listings[0].Name(), "expected the oldest runs to be removed")
+}
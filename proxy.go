@@ -0,0 +1,235 @@
+package godev
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ProxyRecordBodyLimit is the size above which request bodies are not
+// recorded, requests with larger bodies are proxied but not recorded
+const ProxyRecordBodyLimit = 1 << 20
+
+// ProxyReplayTimeout is how long a replayed request can take
+const ProxyReplayTimeout = 30 * time.Second
+
+// DevProxyConfig configures DevProxy
+type DevProxyConfig struct {
+	// Ports are the port the proxy listens on at 127.0.0.1 and the port
+	// of the application it proxies to
+	Ports PortForward
+	// RecordLimit is the number of the last requests which are recorded
+	// for Replay, nothing is recorded when it is 0
+	RecordLimit int
+	// CertFile and KeyFile are the certificate and key the proxy serves
+	// TLS with, it serves plain HTTP when they are empty
+	CertFile string
+	KeyFile  string
+	LogLevel LogLevel
+	RunState *RunState
+}
+
+// InitDevProxy creates a DevProxy
+func InitDevProxy(config *DevProxyConfig) *DevProxy {
+	proxy := &DevProxy{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:     "proxy",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: config.RunState,
+		}),
+		target: &url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%v", config.Ports.ChildPort)},
+	}
+	proxy.reverseProxy = httputil.NewSingleHostReverseProxy(proxy.target)
+	proxy.reverseProxy.ErrorHandler = proxy.handleError
+	return proxy
+}
+
+// DevProxy is an HTTP proxy in front of the application which stays up
+// while the application restarts and can record the requests it proxies
+// to replay them after a rebuild
+type DevProxy struct {
+	config       *DevProxyConfig
+	logger       *Logger
+	target       *url.URL
+	reverseProxy *httputil.ReverseProxy
+	listener     net.Listener
+	stopped      chan struct{}
+	records      []ProxyRecord
+	recordsMutex sync.Mutex
+}
+
+// ProxyRecord is a request recorded by the DevProxy
+type ProxyRecord struct {
+	Method     string
+	URI        string
+	Header     http.Header
+	Body       []byte
+	RecordedAt time.Time
+}
+
+// ProxyReplayResult is the outcome of replaying a ProxyRecord
+type ProxyReplayResult struct {
+	Method   string `json:"method"`
+	URI      string `json:"uri"`
+	Status   int    `json:"status,omitempty"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Start begins listening for requests in the background, with TLS when
+// the proxy has a certificate
+func (proxy *DevProxy) Start() error {
+	scheme := "http"
+	var tlsConfig *tls.Config
+	if len(proxy.config.CertFile) > 0 {
+		certificate, err := tls.LoadX509KeyPair(proxy.config.CertFile, proxy.config.KeyFile)
+		if err != nil {
+			return fmt.Errorf("unable to load the certificate at '%s': %s", proxy.config.CertFile, err)
+		}
+		scheme = "https"
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%v", proxy.config.Ports.HostPort))
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	proxy.listener = listener
+	proxy.stopped = make(chan struct{})
+	proxy.logger.Infof("proxying '%s://%s' to '%s'", scheme, listener.Addr().String(), proxy.target.String())
+	go func() {
+		defer close(proxy.stopped)
+		if err := http.Serve(listener, proxy); err != nil {
+			proxy.logger.Debugf("proxy stopped: %s", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the proxy from accepting requests and waits for it to stop
+func (proxy *DevProxy) Close() error {
+	if proxy.listener == nil {
+		return nil
+	}
+	err := proxy.listener.Close()
+	<-proxy.stopped
+	return err
+}
+
+// ServeHTTP implements http.Handler
+func (proxy *DevProxy) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	if proxy.config.RecordLimit > 0 {
+		proxy.record(request)
+	}
+	proxy.reverseProxy.ServeHTTP(response, request)
+}
+
+// handleError responds with 502 when the application cannot be reached,
+// usually because it is being rebuilt
+func (proxy *DevProxy) handleError(response http.ResponseWriter, request *http.Request, err error) {
+	proxy.logger.Debugf("%s %s could not be proxied: %s", request.Method, request.URL.RequestURI(), err)
+	response.WriteHeader(http.StatusBadGateway)
+	fmt.Fprintf(response, "godev: the application at '%s' is not reachable: %s\n", proxy.target.String(), err)
+}
+
+// record keeps :request for Replay, the body of :request is replaced
+// by one which can still be read
+func (proxy *DevProxy) record(request *http.Request) {
+	var body []byte
+	if request.Body != nil {
+		contents, err := ioutil.ReadAll(io.LimitReader(request.Body, ProxyRecordBodyLimit+1))
+		request.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(contents), request.Body))
+		if err != nil || len(contents) > ProxyRecordBodyLimit {
+			proxy.logger.Debugf("%s %s is not recorded because its body could not be read or is too large", request.Method, request.URL.RequestURI())
+			return
+		}
+		body = contents
+	}
+	proxy.recordsMutex.Lock()
+	defer proxy.recordsMutex.Unlock()
+	proxy.records = append(proxy.records, ProxyRecord{
+		Method:     request.Method,
+		URI:        request.URL.RequestURI(),
+		Header:     request.Header.Clone(),
+		Body:       body,
+		RecordedAt: time.Now(),
+	})
+	if overflow := len(proxy.records) - proxy.config.RecordLimit; overflow > 0 {
+		proxy.records = proxy.records[overflow:]
+	}
+}
+
+// GetRecords returns the recorded requests from the oldest to the latest
+func (proxy *DevProxy) GetRecords() []ProxyRecord {
+	proxy.recordsMutex.Lock()
+	defer proxy.recordsMutex.Unlock()
+	return append([]ProxyRecord{}, proxy.records...)
+}
+
+// Replay sends the last :count recorded requests to the application again
+// in the order they were received, replayed requests are not recorded
+func (proxy *DevProxy) Replay(count int) ([]ProxyReplayResult, error) {
+	if proxy.config.RecordLimit == 0 {
+		return nil, fmt.Errorf("requests are not being recorded (use --record-requests)")
+	} else if count < 1 {
+		return nil, fmt.Errorf("the number of requests to replay should be at least 1")
+	}
+	records := proxy.GetRecords()
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no requests have been recorded yet")
+	} else if count < len(records) {
+		records = records[len(records)-count:]
+	}
+	client := &http.Client{
+		Timeout: ProxyReplayTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	var results []ProxyReplayResult
+	for _, record := range records {
+		results = append(results, proxy.replay(client, record))
+	}
+	return results, nil
+}
+
+// replay sends :record to the application with :client
+func (proxy *DevProxy) replay(client *http.Client, record ProxyRecord) ProxyReplayResult {
+	result := ProxyReplayResult{Method: record.Method, URI: record.URI}
+	startedAt := time.Now()
+	defer func() {
+		if len(result.Error) > 0 {
+			proxy.logger.Warnf("replaying %s %s failed: %s", record.Method, record.URI, result.Error)
+		} else {
+			proxy.logger.Infof("replayed %s %s: %v in %s", record.Method, record.URI, result.Status, result.Duration)
+		}
+	}()
+	request, err := http.NewRequest(record.Method, proxy.target.String()+record.URI, bytes.NewReader(record.Body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	request.Header = record.Header.Clone()
+	response, err := client.Do(request)
+	result.Duration = time.Since(startedAt).Round(time.Millisecond).String()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+	result.Status = response.StatusCode
+	return result
+}
@@ -0,0 +1,179 @@
+package godev
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DevProxyTestSuite struct {
+	suite.Suite
+	application   *httptest.Server
+	received      []string
+	receivedMutex sync.Mutex
+	logs          syncBuffer
+}
+
+func TestDevProxy(t *testing.T) {
+	suite.Run(t, new(DevProxyTestSuite))
+}
+
+func (s *DevProxyTestSuite) SetupTest() {
+	s.resetReceived()
+	s.logs.Reset()
+	s.application = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.receivedMutex.Lock()
+		defer s.receivedMutex.Unlock()
+		s.received = append(s.received, r.Method+" "+r.URL.RequestURI()+" "+string(body)+" "+r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+}
+
+// getReceived returns the requests the application received
+func (s *DevProxyTestSuite) getReceived() []string {
+	s.receivedMutex.Lock()
+	defer s.receivedMutex.Unlock()
+	return append([]string{}, s.received...)
+}
+
+func (s *DevProxyTestSuite) resetReceived() {
+	s.receivedMutex.Lock()
+	defer s.receivedMutex.Unlock()
+	s.received = nil
+}
+
+func (s *DevProxyTestSuite) TearDownTest() {
+	s.application.Close()
+}
+
+// initProxy returns a proxy to the application which records the last
+// :recordLimit requests
+func (s *DevProxyTestSuite) initProxy(recordLimit int) *DevProxy {
+	applicationPort := s.application.Listener.Addr().(*net.TCPAddr).Port
+	proxy := InitDevProxy(&DevProxyConfig{
+		Ports:       PortForward{HostPort: 0, ChildPort: applicationPort},
+		RecordLimit: recordLimit,
+		LogLevel:    "trace",
+	})
+	proxy.logger.SetOutput(&s.logs)
+	return proxy
+}
+
+func (s *DevProxyTestSuite) send(proxy *DevProxy, method, uri, body string) int {
+	request := httptest.NewRequest(method, uri, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer dev")
+	recorder := httptest.NewRecorder()
+	proxy.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func (s *DevProxyTestSuite) TestRecordAndReplay() {
+	t := s.T()
+	proxy := s.initProxy(2)
+	assert.Equal(t, http.StatusCreated, s.send(proxy, http.MethodGet, "/health", ""))
+	assert.Equal(t, http.StatusCreated, s.send(proxy, http.MethodPost, "/orders?dry=1", `{"id":1}`))
+	assert.Equal(t, http.StatusCreated, s.send(proxy, http.MethodPut, "/orders/1", `{"id":2}`))
+	assert.Equal(t, []string{
+		"GET /health  Bearer dev",
+		`POST /orders?dry=1 {"id":1} Bearer dev`,
+		`PUT /orders/1 {"id":2} Bearer dev`,
+	}, s.getReceived(), "expected recorded requests to be proxied unchanged")
+	records := proxy.GetRecords()
+	assert.Len(t, records, 2, "expected only the last requests to be kept")
+	assert.Equal(t, "/orders?dry=1", records[0].URI)
+
+	s.resetReceived()
+	results, err := proxy.Replay(5)
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, http.StatusCreated, results[0].Status)
+	assert.Equal(t, []string{
+		`POST /orders?dry=1 {"id":1} Bearer dev`,
+		`PUT /orders/1 {"id":2} Bearer dev`,
+	}, s.getReceived())
+	assert.Len(t, proxy.GetRecords(), 2, "expected replayed requests not to be recorded")
+
+	s.resetReceived()
+	results, err = proxy.Replay(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`PUT /orders/1 {"id":2} Bearer dev`}, s.getReceived())
+	assert.Equal(t, "/orders/1", results[0].URI)
+	_, err = proxy.Replay(0)
+	assert.NotNil(t, err)
+}
+
+func (s *DevProxyTestSuite) TestReplay_withoutRecords() {
+	t := s.T()
+	_, err := s.initProxy(0).Replay(1)
+	assert.NotNil(t, err, "expected replays to require recording")
+	_, err = s.initProxy(10).Replay(1)
+	assert.NotNil(t, err, "expected replays to require recorded requests")
+}
+
+func (s *DevProxyTestSuite) TestServeHTTP_withUnreachableApplication() {
+	t := s.T()
+	proxy := s.initProxy(10)
+	s.application.Close()
+	assert.Equal(t, http.StatusBadGateway, s.send(proxy, http.MethodGet, "/health", ""))
+	results, err := proxy.Replay(1)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, results[0].Error)
+}
+
+func (s *DevProxyTestSuite) TestStartAndClose() {
+	t := s.T()
+	proxy := s.initProxy(0)
+	assert.Nil(t, proxy.Start())
+	response, err := http.Get("http://" + proxy.listener.Addr().String() + "/health")
+	assert.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.Nil(t, proxy.Close())
+	assert.Contains(t, s.logs.String(), "proxy stopped", "expected Close to wait for the proxy to stop")
+}
+
+func (s *DevProxyTestSuite) TestStart_withCertificate() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-proxy")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	certs := InitCerts(&CertsConfig{Directory: path.Join(directory, "certs")})
+	assert.Nil(t, certs.Generate())
+	proxy := s.initProxy(0)
+	proxy.config.CertFile = certs.GetPath(CertsCertFileName)
+	proxy.config.KeyFile = certs.GetPath(CertsKeyFileName)
+	assert.Nil(t, proxy.Start())
+	assert.Contains(t, s.logs.String(), "proxying 'https://")
+	ca, err := ioutil.ReadFile(certs.GetPath(CertsCAFileName))
+	assert.Nil(t, err)
+	roots := x509.NewCertPool()
+	assert.True(t, roots.AppendCertsFromPEM(ca))
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	response, err := client.Get("https://" + proxy.listener.Addr().String() + "/health")
+	assert.Nil(t, err, "expected the proxy to serve TLS with the development certificate")
+	response.Body.Close()
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	client.CloseIdleConnections()
+	assert.Nil(t, proxy.Close())
+	assert.Contains(t, s.logs.String(), "proxy stopped")
+}
+
+func (s *DevProxyTestSuite) TestStart_withInvalidCertificate() {
+	proxy := s.initProxy(0)
+	proxy.config.CertFile = "/non/existent/cert.pem"
+	proxy.config.KeyFile = "/non/existent/key.pem"
+	assert.NotNil(s.T(), proxy.Start())
+	assert.Nil(s.T(), proxy.listener, "expected nothing to listen without the certificate")
+}
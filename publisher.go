@@ -0,0 +1,198 @@
+package godev
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// PublishDockerPrefix marks a --publish destination as a docker registry
+// repository rather than a directory
+const PublishDockerPrefix = "docker://"
+
+// PublishMetadataFileName is the name of the file describing a published
+// artifact, the latest one is also kept in the destination directory as
+// PublishLatestFileName
+const (
+	PublishMetadataFileName = "metadata.json"
+	PublishLatestFileName   = "latest.json"
+)
+
+// PublisherConfig configures Publisher
+type PublisherConfig struct {
+	BinaryPath string
+	// Destination is a directory to copy the binary to or a docker
+	// registry repository prefixed with PublishDockerPrefix to push an
+	// image built from the Dockerfile in WorkDirectory to
+	Destination string
+	// Policy restricts the executables which are run to publish
+	Policy         *Policy
+	WatchDirectory string
+	WorkDirectory  string
+}
+
+// InitPublisher creates a Publisher which publishes the artifacts of
+// successful pipelines so that exact dev builds can be shared
+func InitPublisher(config *PublisherConfig) *Publisher {
+	return &Publisher{
+		config: config,
+		run: func(directory, name string, arguments ...string) (string, error) {
+			return runPublishCommand(config.Policy, directory, name, arguments...)
+		},
+	}
+}
+
+// Publisher is the component for publishing dev builds
+type Publisher struct {
+	config *PublisherConfig
+	run    func(directory, name string, arguments ...string) (string, error)
+}
+
+// ArtifactMetadata describes a published artifact and the run that
+// produced it
+type ArtifactMetadata struct {
+	ID        string    `json:"id"`
+	Pipeline  int       `json:"pipeline"`
+	StartedAt time.Time `json:"startedAt"`
+	Duration  string    `json:"duration"`
+	Source    string    `json:"source"`
+	Commit    string    `json:"commit,omitempty"`
+	Dirty     bool      `json:"dirty"`
+	Host      string    `json:"host,omitempty"`
+	Platform  string    `json:"platform"`
+	Binary    string    `json:"binary,omitempty"`
+	SHA256    string    `json:"sha256,omitempty"`
+	Size      int64     `json:"size,omitempty"`
+	Image     string    `json:"image,omitempty"`
+}
+
+// IsDocker checks whether artifacts are pushed to a docker registry
+func (publisher *Publisher) IsDocker() bool {
+	return strings.HasPrefix(publisher.config.Destination, PublishDockerPrefix)
+}
+
+// Publish publishes the artifact of the :pipeline which started at
+// :startedAt and took :duration
+func (publisher *Publisher) Publish(pipeline int, startedAt time.Time, duration time.Duration) (*ArtifactMetadata, error) {
+	metadata := publisher.getMetadata(pipeline, startedAt, duration)
+	if publisher.IsDocker() {
+		return metadata, publisher.publishImage(metadata)
+	}
+	return metadata, publisher.publishBinary(metadata)
+}
+
+// getMetadata collects the details of the run and the source it was
+// built from, git details are left empty outside of a repository
+func (publisher *Publisher) getMetadata(pipeline int, startedAt time.Time, duration time.Duration) *ArtifactMetadata {
+	metadata := &ArtifactMetadata{
+		ID:        getRunID(pipeline, startedAt),
+		Pipeline:  pipeline,
+		StartedAt: startedAt,
+		Duration:  duration.Round(time.Millisecond).String(),
+		Source:    publisher.config.WatchDirectory,
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	metadata.Host, _ = os.Hostname()
+	if commit, err := publisher.run(publisher.config.WatchDirectory, "git", "rev-parse", "HEAD"); err == nil {
+		metadata.Commit = strings.TrimSpace(commit)
+		changes, err := publisher.run(publisher.config.WatchDirectory, "git", "status", "--porcelain")
+		metadata.Dirty = err == nil && len(strings.TrimSpace(changes)) > 0
+	}
+	return metadata
+}
+
+// publishBinary copies the binary into a directory named after the run
+// with its metadata alongside it
+func (publisher *Publisher) publishBinary(metadata *ArtifactMetadata) error {
+	binaryPath := publisher.config.BinaryPath
+	source, err := os.Open(binaryPath)
+	if err != nil {
+		return fmt.Errorf("unable to open the binary '%s': %s", binaryPath, err)
+	}
+	defer source.Close()
+	artifactDirectory := path.Join(publisher.config.Destination, metadata.ID)
+	if err := os.MkdirAll(artifactDirectory, os.ModePerm); err != nil {
+		return err
+	}
+	metadata.Binary = path.Join(artifactDirectory, path.Base(binaryPath))
+	destination, err := os.OpenFile(metadata.Binary, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(destination, hash), source)
+	if err != nil {
+		return fmt.Errorf("unable to copy the binary to '%s': %s", metadata.Binary, err)
+	}
+	metadata.Size = size
+	metadata.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	contents, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(artifactDirectory, PublishMetadataFileName), contents, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(publisher.config.Destination, PublishLatestFileName), contents, 0644)
+}
+
+// publishImage builds an image tagged with the run ID from the
+// Dockerfile in the work directory and pushes it, the metadata is
+// attached to the image as labels
+func (publisher *Publisher) publishImage(metadata *ArtifactMetadata) error {
+	metadata.Image = strings.TrimPrefix(publisher.config.Destination, PublishDockerPrefix) + ":" + metadata.ID
+	arguments := []string{"build", "--tag", metadata.Image}
+	for _, label := range []string{
+		"dev.godev.id=" + metadata.ID,
+		"dev.godev.commit=" + metadata.Commit,
+		fmt.Sprintf("dev.godev.dirty=%v", metadata.Dirty),
+		"dev.godev.source=" + metadata.Source,
+		"dev.godev.started-at=" + metadata.StartedAt.Format(time.RFC3339),
+	} {
+		arguments = append(arguments, "--label", label)
+	}
+	arguments = append(arguments, ".")
+	if output, err := publisher.run(publisher.config.WorkDirectory, "docker", arguments...); err != nil {
+		return fmt.Errorf("unable to build the image '%s': %s\n%s", metadata.Image, err, output)
+	}
+	if output, err := publisher.run(publisher.config.WorkDirectory, "docker", "push", metadata.Image); err != nil {
+		return fmt.Errorf("unable to push the image '%s': %s\n%s", metadata.Image, err, output)
+	}
+	return nil
+}
+
+// validatePublishDestination returns an error if :destination is a
+// docker destination without a repository or includes a tag
+func validatePublishDestination(destination string) error {
+	if !strings.HasPrefix(destination, PublishDockerPrefix) {
+		return nil
+	}
+	repository := strings.TrimPrefix(destination, PublishDockerPrefix)
+	if len(repository) == 0 {
+		return fmt.Errorf("'%s' does not specify a repository to push to", destination)
+	}
+	if strings.Contains(repository[strings.LastIndex(repository, "/")+1:], ":") {
+		return fmt.Errorf("'%s' should not include a tag, images are tagged with the run ID", destination)
+	}
+	return nil
+}
+
+// runPublishCommand returns the combined output of :name run with
+// :arguments in :directory once :policy allows it
+func runPublishCommand(policy *Policy, directory, name string, arguments ...string) (string, error) {
+	cmd, err := policy.Command(directory, name, arguments...)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
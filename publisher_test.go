@@ -0,0 +1,123 @@
+package godev
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PublisherTestSuite struct {
+	suite.Suite
+	directory string
+	commands  []string
+}
+
+func TestPublisher(t *testing.T) {
+	suite.Run(t, new(PublisherTestSuite))
+}
+
+func (s *PublisherTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-publish")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+	s.commands = nil
+}
+
+func (s *PublisherTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *PublisherTestSuite) initPublisher(destination string) *Publisher {
+	publisher := InitPublisher(&PublisherConfig{
+		BinaryPath:     path.Join(s.directory, "bin", "app"),
+		Destination:    destination,
+		WatchDirectory: s.directory,
+		WorkDirectory:  s.directory,
+	})
+	publisher.run = func(directory, name string, arguments ...string) (string, error) {
+		s.commands = append(s.commands, name+" "+strings.Join(arguments, " "))
+		switch arguments[0] {
+		case "rev-parse":
+			return "0123abcd\n", nil
+		case "status":
+			return " M main.go\n", nil
+		case "push":
+			return "denied", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+	return publisher
+}
+
+func (s *PublisherTestSuite) Test_Publish_toDirectory() {
+	t := s.T()
+	assert.Nil(t, os.MkdirAll(path.Join(s.directory, "bin"), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "bin", "app"), []byte("binary"), 0755))
+	destination := path.Join(s.directory, "artifacts")
+	startedAt := time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)
+	metadata, err := s.initPublisher(destination).Publish(3, startedAt, 1500*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, "20190304-050607-3", metadata.ID)
+	assert.Equal(t, "0123abcd", metadata.Commit)
+	assert.True(t, metadata.Dirty)
+	assert.Equal(t, "1.5s", metadata.Duration)
+	assert.Equal(t, path.Join(destination, "20190304-050607-3", "app"), metadata.Binary)
+	assert.Equal(t, int64(6), metadata.Size)
+	assert.Equal(t, "9a3a45d01531a20e89ac6ae10b0b0beb0492acd7216a368aa062d1a5fecaf9cd", metadata.SHA256)
+	contents, err := ioutil.ReadFile(metadata.Binary)
+	assert.Nil(t, err)
+	assert.Equal(t, "binary", string(contents))
+	var published ArtifactMetadata
+	contents, err = ioutil.ReadFile(path.Join(destination, "20190304-050607-3", PublishMetadataFileName))
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(contents, &published))
+	assert.Equal(t, metadata.SHA256, published.SHA256)
+	latest, err := ioutil.ReadFile(path.Join(destination, PublishLatestFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, contents, latest)
+}
+
+func (s *PublisherTestSuite) Test_Publish_withoutBinary() {
+	t := s.T()
+	_, err := s.initPublisher(path.Join(s.directory, "artifacts")).Publish(1, time.Now(), time.Second)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unable to open the binary")
+}
+
+func (s *PublisherTestSuite) Test_Publish_toDocker() {
+	t := s.T()
+	startedAt := time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)
+	metadata, err := s.initPublisher("docker://localhost:5000/app").Publish(3, startedAt, time.Second)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unable to push the image 'localhost:5000/app:20190304-050607-3'")
+	assert.Equal(t, "localhost:5000/app:20190304-050607-3", metadata.Image)
+	assert.Len(t, s.commands, 4)
+	assert.Contains(t, s.commands[2], "docker build --tag localhost:5000/app:20190304-050607-3 --label dev.godev.id=20190304-050607-3 --label dev.godev.commit=0123abcd")
+	assert.Equal(t, "docker push localhost:5000/app:20190304-050607-3", s.commands[3])
+}
+
+func (s *PublisherTestSuite) Test_runPublishCommand() {
+	t := s.T()
+	_, err := runPublishCommand(&Policy{Allow: []string{"git"}, Path: "policy.yaml"}, s.directory, "docker", "push", "localhost:5000/app")
+	assert.NotNil(t, err, "expected the policy to be checked before publishing")
+	assert.Contains(t, err.Error(), "'docker' is not allowed by the policy at 'policy.yaml'")
+}
+
+func (s *PublisherTestSuite) Test_validatePublishDestination() {
+	t := s.T()
+	assert.Nil(t, validatePublishDestination(""))
+	assert.Nil(t, validatePublishDestination("./artifacts"))
+	assert.Nil(t, validatePublishDestination("docker://localhost:5000/team/app"))
+	assert.NotNil(t, validatePublishDestination("docker://"))
+	assert.NotNil(t, validatePublishDestination("docker://localhost:5000/app:latest"))
+}
@@ -0,0 +1,269 @@
+package godev
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProjectReportConfig configures GetProjectReport
+type ProjectReportConfig struct {
+	BuildOutput   string
+	ConfigFile    string
+	Policy        *Policy
+	Project       *ProjectDirectory
+	WorkDirectory string
+}
+
+// ProjectReport is a snapshot of the health of a project which can be
+// pasted into pull requests and onboarding documents
+type ProjectReport struct {
+	GeneratedAt time.Time            `json:"generatedAt"`
+	Module      string               `json:"module,omitempty"`
+	GoVersion   string               `json:"goVersion,omitempty"`
+	ModuleGraph *ProjectReportGraph  `json:"moduleGraph,omitempty"`
+	Session     *ProjectReportRuns   `json:"session,omitempty"`
+	Coverage    *float64             `json:"coverage,omitempty"`
+	Binary      *ProjectReportBinary `json:"binary,omitempty"`
+	ConfigFile  *ProjectReportFile   `json:"config,omitempty"`
+}
+
+// ProjectReportGraph is the size of the module graph of the project
+type ProjectReportGraph struct {
+	// Modules is the number of modules in the graph other than the
+	// project's own
+	Modules int `json:"modules"`
+	// Requirements is the number of edges of the graph
+	Requirements int `json:"requirements"`
+}
+
+// ProjectReportRuns summarises the pipeline runs of the last session
+type ProjectReportRuns struct {
+	Runs            int    `json:"runs"`
+	Passed          int    `json:"passed"`
+	LastDuration    string `json:"lastDuration"`
+	AverageDuration string `json:"averageDuration"`
+	// PackagesPassed and PackagesTested are from the last run whose
+	// output was recorded, they are zero when none was
+	PackagesPassed int `json:"packagesPassed,omitempty"`
+	PackagesTested int `json:"packagesTested,omitempty"`
+}
+
+// ProjectReportBinary is the binary built by the pipeline
+type ProjectReportBinary struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// ProjectReportFile summarises the configuration file of the project
+type ProjectReportFile struct {
+	Path       string   `json:"path"`
+	ExecGroups int      `json:"execGroups"`
+	Profiles   []string `json:"profiles,omitempty"`
+	Services   []string `json:"services,omitempty"`
+	Plugins    []string `json:"plugins,omitempty"`
+}
+
+// GetProjectReport collects the report of the project in the configured
+// work directory, parts of the report which are unavailable (eg. the
+// module graph outside of modules) are left out
+func GetProjectReport(config *ProjectReportConfig) *ProjectReport {
+	report := &ProjectReport{GeneratedAt: time.Now(), Module: getModulePath(config.WorkDirectory)}
+	if output, err := runReportCommand(config.Policy, config.WorkDirectory, "go", "env", "GOVERSION"); err == nil {
+		report.GoVersion = strings.TrimSpace(output)
+	}
+	if fileExists(path.Join(config.WorkDirectory, "go.mod")) {
+		if output, err := runReportCommand(config.Policy, config.WorkDirectory, "go", "mod", "graph"); err == nil {
+			report.ModuleGraph = parseModuleGraph(output)
+		}
+	}
+	if history, err := config.Project.GetHistory(); err == nil {
+		report.Session = getSessionRuns(config.Project, getSessionHistory(history))
+	}
+	for _, profilePath := range []string{
+		config.Project.GetPath(ProjectCoverageDirectoryName, DefaultSessionCoverProfile),
+		path.Join(config.WorkDirectory, DefaultCoverProfile),
+	} {
+		if profile, err := LoadCoverageProfile(profilePath); err == nil {
+			coverage := profile.GetCoverage()
+			report.Coverage = &coverage
+			break
+		}
+	}
+	if fileInfo, err := os.Stat(path.Join(config.WorkDirectory, config.BuildOutput)); err == nil && !fileInfo.IsDir() {
+		report.Binary = &ProjectReportBinary{Path: config.BuildOutput, Size: fileInfo.Size()}
+	}
+	if len(config.ConfigFile) > 0 {
+		if configFile, err := LoadConfigFile(config.ConfigFile); err == nil {
+			report.ConfigFile = summariseConfigFile(config.ConfigFile, configFile)
+		}
+	}
+	return report
+}
+
+// Markdown renders the report as a markdown table
+func (report *ProjectReport) Markdown() string {
+	rows := [][2]string{}
+	addRow := func(name, value string) { rows = append(rows, [2]string{name, value}) }
+	addRow("Module", orNotAvailable(report.Module))
+	addRow("Go version", orNotAvailable(report.GoVersion))
+	if report.ModuleGraph != nil {
+		addRow("Module graph", fmt.Sprintf("%v modules, %v requirements", report.ModuleGraph.Modules, report.ModuleGraph.Requirements))
+	} else {
+		addRow("Module graph", orNotAvailable(""))
+	}
+	if report.Session != nil {
+		addRow("Build time", fmt.Sprintf("%s (last), %s (average)", report.Session.LastDuration, report.Session.AverageDuration))
+		passRate := fmt.Sprintf("%v/%v runs passed (%.0f%%)", report.Session.Passed, report.Session.Runs, float64(report.Session.Passed)*100/float64(report.Session.Runs))
+		if report.Session.PackagesTested > 0 {
+			passRate += fmt.Sprintf(", %v/%v packages passed in the last recorded run", report.Session.PackagesPassed, report.Session.PackagesTested)
+		}
+		addRow("Test pass rate", passRate)
+	} else {
+		addRow("Build time", orNotAvailable(""))
+		addRow("Test pass rate", orNotAvailable(""))
+	}
+	if report.Coverage != nil {
+		addRow("Coverage", fmt.Sprintf("%.1f%% of statements", *report.Coverage))
+	} else {
+		addRow("Coverage", orNotAvailable(""))
+	}
+	if report.Binary != nil {
+		addRow("Binary size", fmt.Sprintf("%s (%s)", formatByteSize(report.Binary.Size), report.Binary.Path))
+	} else {
+		addRow("Binary size", orNotAvailable(""))
+	}
+	if report.ConfigFile != nil {
+		summary := []string{path.Base(report.ConfigFile.Path), fmt.Sprintf("%v execution groups", report.ConfigFile.ExecGroups)}
+		if len(report.ConfigFile.Profiles) > 0 {
+			summary = append(summary, "profiles: "+strings.Join(report.ConfigFile.Profiles, ", "))
+		}
+		if len(report.ConfigFile.Services) > 0 {
+			summary = append(summary, "services: "+strings.Join(report.ConfigFile.Services, ", "))
+		}
+		if len(report.ConfigFile.Plugins) > 0 {
+			summary = append(summary, "plugins: "+strings.Join(report.ConfigFile.Plugins, ", "))
+		}
+		addRow("Configuration", strings.Join(summary, "; "))
+	} else {
+		addRow("Configuration", "defaults (no configuration file)")
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("## Project report\n\nGenerated by godev %s at %s\n\n", Version, report.GeneratedAt.Format(time.RFC3339)))
+	output.WriteString("| | |\n| --- | --- |\n")
+	for _, row := range rows {
+		output.WriteString(fmt.Sprintf("| %s | %s |\n", row[0], strings.Replace(row[1], "|", `\|`, -1)))
+	}
+	return output.String()
+}
+
+// getSessionHistory returns the entries of the last session in
+// :history, pipeline numbers start from 1 in every session
+func getSessionHistory(history []RunHistoryEntry) []RunHistoryEntry {
+	for index := len(history) - 1; index >= 0; index-- {
+		if history[index].Pipeline <= 1 {
+			return history[index:]
+		}
+	}
+	return history
+}
+
+// getSessionRuns summarises the runs of :session, the test results are
+// from the last run in it whose output was recorded in :project
+func getSessionRuns(project *ProjectDirectory, session []RunHistoryEntry) *ProjectReportRuns {
+	if len(session) == 0 {
+		return nil
+	}
+	runs := &ProjectReportRuns{Runs: len(session)}
+	var total time.Duration
+	for _, entry := range session {
+		if !entry.Failed {
+			runs.Passed++
+		}
+		duration, _ := time.ParseDuration(entry.Duration)
+		total += duration
+	}
+	runs.LastDuration = session[len(session)-1].Duration
+	runs.AverageDuration = (total / time.Duration(len(session))).Round(time.Millisecond).String()
+	for index := len(session) - 1; index >= 0; index-- {
+		if !session[index].Recorded {
+			continue
+		}
+		if output, err := project.LoadRunOutput(session[index].ID); err == nil {
+			for _, result := range output.Tests {
+				if len(result.Test) > 0 || result.Status == "no tests" {
+					continue
+				}
+				runs.PackagesTested++
+				if result.Status == "ok" {
+					runs.PackagesPassed++
+				}
+			}
+		}
+		break
+	}
+	return runs
+}
+
+// parseModuleGraph returns the size of the module graph printed by
+// go mod graph as :output, the go and toolchain requirements are not
+// modules and are left out
+func parseModuleGraph(output string) *ProjectReportGraph {
+	graph := &ProjectReportGraph{}
+	modules := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		modulePath := strings.SplitN(fields[1], "@", 2)[0]
+		if modulePath == "go" || modulePath == "toolchain" {
+			continue
+		}
+		graph.Requirements++
+		modules[modulePath] = true
+	}
+	graph.Modules = len(modules)
+	return graph
+}
+
+// summariseConfigFile returns the summary of :configFile loaded from
+// :filePath
+func summariseConfigFile(filePath string, configFile *ConfigFile) *ProjectReportFile {
+	summary := &ProjectReportFile{
+		Path:       filePath,
+		ExecGroups: len(configFile.Exec),
+		Plugins:    configFile.Plugins,
+	}
+	for name := range configFile.Profiles {
+		summary.Profiles = append(summary.Profiles, name)
+	}
+	for name := range configFile.Services {
+		summary.Services = append(summary.Services, name)
+	}
+	sort.Strings(summary.Profiles)
+	sort.Strings(summary.Services)
+	return summary
+}
+
+// runReportCommand returns the output of :application run with
+// :arguments in :directory once :policy allows it
+func runReportCommand(policy *Policy, directory string, application string, arguments ...string) (string, error) {
+	cmd, err := policy.Command(directory, application, arguments...)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.Output()
+	return string(output), err
+}
+
+// orNotAvailable returns :value or n/a when it is empty
+func orNotAvailable(value string) string {
+	if len(value) == 0 {
+		return "n/a"
+	}
+	return value
+}
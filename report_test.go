@@ -0,0 +1,99 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportTestSuite struct {
+	suite.Suite
+	directory string
+	project   *ProjectDirectory
+}
+
+func TestReport(t *testing.T) {
+	suite.Run(t, new(ReportTestSuite))
+}
+
+func (s *ReportTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-report")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.project = InitProjectDirectory(&ProjectDirectoryConfig{Path: path.Join(directory, DefaultProjectDirectory)})
+	assert.Nil(s.T(), s.project.Init())
+}
+
+func (s *ReportTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *ReportTestSuite) TestGetProjectReport() {
+	t := s.T()
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "go.mod"), []byte("module example.com/app\n"), 0644))
+	assert.Nil(t, os.MkdirAll(path.Join(s.directory, "bin"), 0755))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "bin/app"), make([]byte, 2048), 0755))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, DefaultCoverProfile), []byte("mode: set\nexample.com/app/main.go:1.1,2.2 3 1\nexample.com/app/main.go:3.1,4.2 1 0\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "godev.yaml"), []byte("exec:\n  - go build\n  - bin/app\nprofiles:\n  debug: {}\n"), 0644))
+	assert.Nil(t, s.project.AppendHistory(&RunHistoryEntry{Pipeline: 1, Duration: "1s"}))
+	report := GetProjectReport(&ProjectReportConfig{
+		BuildOutput:   "bin/app",
+		ConfigFile:    path.Join(s.directory, "godev.yaml"),
+		Project:       s.project,
+		WorkDirectory: s.directory,
+	})
+	assert.Equal(t, "example.com/app", report.Module)
+	assert.Equal(t, &ProjectReportBinary{Path: "bin/app", Size: 2048}, report.Binary)
+	assert.Equal(t, 75.0, *report.Coverage)
+	assert.Equal(t, 1, report.Session.Runs)
+	assert.Equal(t, 2, report.ConfigFile.ExecGroups)
+	assert.Equal(t, []string{"debug"}, report.ConfigFile.Profiles)
+	markdown := report.Markdown()
+	assert.Contains(t, markdown, "| Module | example.com/app |\n")
+	assert.Contains(t, markdown, "| Coverage | 75.0% of statements |\n")
+	assert.Contains(t, markdown, "| Binary size | 2.0 KB (bin/app) |\n")
+	assert.Contains(t, markdown, "| Configuration | godev.yaml; 2 execution groups; profiles: debug |\n")
+}
+
+func (s *ReportTestSuite) TestMarkdown_notAvailable() {
+	markdown := (&ProjectReport{}).Markdown()
+	assert.Contains(s.T(), markdown, "| Test pass rate | n/a |\n")
+	assert.Contains(s.T(), markdown, "| Configuration | defaults (no configuration file) |\n")
+}
+
+func (s *ReportTestSuite) Test_getSessionHistory() {
+	t := s.T()
+	history := []RunHistoryEntry{{Pipeline: 1}, {Pipeline: 2}, {Pipeline: 1}, {Pipeline: 2}, {Pipeline: 3}}
+	assert.Equal(t, history[2:], getSessionHistory(history))
+	assert.Equal(t, history[4:], getSessionHistory(history[4:]), "expected a history without the start of the session to be the session")
+	assert.Empty(t, getSessionHistory(nil))
+}
+
+func (s *ReportTestSuite) Test_getSessionRuns() {
+	t := s.T()
+	recorder := InitRunRecorder()
+	recorder.Stdout.Write([]byte("--- FAIL: TestSum (0.00s)\nFAIL\texample.com/app\t0.01s\nok  \texample.com/app/api\t0.02s\n?   \texample.com/app/cmd\t[no test files]\n"))
+	assert.Nil(t, s.project.SaveRunOutput("20190304-151620-2", recorder))
+	runs := getSessionRuns(s.project, []RunHistoryEntry{
+		{ID: "20190304-151617-1", Pipeline: 1, Duration: "1s"},
+		{ID: "20190304-151620-2", Pipeline: 2, Duration: "2s", Failed: true, Recorded: true},
+	})
+	assert.Equal(t, &ProjectReportRuns{
+		Runs:            2,
+		Passed:          1,
+		LastDuration:    "2s",
+		AverageDuration: "1.5s",
+		PackagesPassed:  1,
+		PackagesTested:  2,
+	}, runs)
+	assert.Nil(t, getSessionRuns(s.project, nil))
+}
+
+func (s *ReportTestSuite) Test_parseModuleGraph() {
+	graph := parseModuleGraph("example.com/app go@1.21\nexample.com/app github.com/a/b@v1.0.0\nexample.com/app github.com/c/d@v1.0.0\ngithub.com/a/b@v1.0.0 github.com/c/d@v0.9.0\ngithub.com/a/b@v1.0.0 toolchain@go1.21.0\n")
+	assert.Equal(s.T(), &ProjectReportGraph{Modules: 2, Requirements: 3}, graph)
+}
@@ -0,0 +1,148 @@
+package godev
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// RunState is the state which godev keeps while it runs: the number of
+// pipelines and execution groups started, the run tags, verbosity and
+// additional outputs of the logs, the status line, the findings of lint
+// commands and the levels of the log lines of the commands. Every GoDev
+// has its own so that a program can embed several of them
+type RunState struct {
+	RunTags      *RunTagger
+	LintFindings *LintFindings
+	LogCounts    *LogLevelCounter
+	// StatusLine is what the output of the loggers and commands goes
+	// through, it is only drawn with --status-line
+	StatusLine *StatusLine
+	// pipeline is the number of the current pipeline, counting from 1
+	pipeline      int
+	pipelineMutex sync.Mutex
+	// executionGroups is the number of execution group runs started, it
+	// tells them apart in the verbose logs and is only changed atomically
+	// since the execution groups of a graph run concurrently
+	executionGroups int64
+	// verboseLogs is 1 when every logger of the RunState logs at the
+	// debug level or below instead of its own level
+	verboseLogs int32
+	// groupedOutputMutex stops the grouped output of commands which exit
+	// at the same time from interleaving
+	groupedOutputMutex sync.Mutex
+	// loggerOutputs are written to by every logger of the RunState in
+	// addition to their own outputs, they are added with --log-output
+	loggerOutputs      []LoggerSink
+	loggerOutputsMutex sync.RWMutex
+}
+
+// DefaultRunState is the state of the components which were not given
+// one, such as those of the commands other than the default one
+var DefaultRunState = InitRunState()
+
+// InitRunState returns a state in which no pipeline has run yet
+func InitRunState() *RunState {
+	return &RunState{
+		RunTags:      &RunTagger{},
+		LintFindings: &LintFindings{},
+		LogCounts:    &LogLevelCounter{counts: map[LogLevel]int{}},
+		StatusLine:   InitStatusLine(),
+	}
+}
+
+// get returns the state, DefaultRunState when it is nil
+func (state *RunState) get() *RunState {
+	if state == nil {
+		return DefaultRunState
+	}
+	return state
+}
+
+// StartPipeline counts a new pipeline, starts tagging the log lines with
+// it and returns its number
+func (state *RunState) StartPipeline() int {
+	state = state.get()
+	state.pipelineMutex.Lock()
+	state.pipeline++
+	pipeline := state.pipeline
+	state.pipelineMutex.Unlock()
+	state.RunTags.Start(pipeline)
+	return pipeline
+}
+
+// GetPipeline returns the number of the current pipeline, 0 before the
+// first one
+func (state *RunState) GetPipeline() int {
+	state = state.get()
+	state.pipelineMutex.Lock()
+	defer state.pipelineMutex.Unlock()
+	return state.pipeline
+}
+
+// SetPipeline sets the number of the current pipeline to :pipeline, the
+// next one started is counted from it
+func (state *RunState) SetPipeline(pipeline int) {
+	state = state.get()
+	state.pipelineMutex.Lock()
+	defer state.pipelineMutex.Unlock()
+	state.pipeline = pipeline
+}
+
+// countExecutionGroup counts an execution group run and returns its
+// number
+func (state *RunState) countExecutionGroup() int64 {
+	return atomic.AddInt64(&state.get().executionGroups, 1)
+}
+
+// ToggleVerboseLogs switches every logger of the RunState between its
+// own level and the debug level, it returns whether the loggers are
+// verbose now
+func (state *RunState) ToggleVerboseLogs() bool {
+	state = state.get()
+	for {
+		current := atomic.LoadInt32(&state.verboseLogs)
+		if atomic.CompareAndSwapInt32(&state.verboseLogs, current, 1-current) {
+			return current == 0
+		}
+	}
+}
+
+// isVerbose checks whether the loggers of the RunState log at the debug
+// level or below
+func (state *RunState) isVerbose() bool {
+	return atomic.LoadInt32(&state.get().verboseLogs) == 1
+}
+
+// AddLoggerOutputs makes every logger of the RunState write to :sinks as
+// well
+func (state *RunState) AddLoggerOutputs(sinks ...LoggerSink) {
+	state = state.get()
+	state.loggerOutputsMutex.Lock()
+	defer state.loggerOutputsMutex.Unlock()
+	state.loggerOutputs = append(state.loggerOutputs, sinks...)
+}
+
+// CloseLoggerOutputs stops the loggers of the RunState from writing to
+// the sinks added with AddLoggerOutputs and closes the ones which can be
+// closed
+func (state *RunState) CloseLoggerOutputs() {
+	state = state.get()
+	state.loggerOutputsMutex.Lock()
+	sinks := state.loggerOutputs
+	state.loggerOutputs = nil
+	state.loggerOutputsMutex.Unlock()
+	for _, sink := range sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// getLoggerOutputs returns the sinks added with AddLoggerOutputs
+func (state *RunState) getLoggerOutputs() []LoggerSink {
+	state = state.get()
+	state.loggerOutputsMutex.RLock()
+	defer state.loggerOutputsMutex.RUnlock()
+	return state.loggerOutputs
+}
@@ -0,0 +1,62 @@
+package godev
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RunStateTestSuite struct {
+	suite.Suite
+}
+
+func TestRunState(t *testing.T) {
+	suite.Run(t, new(RunStateTestSuite))
+}
+
+func (s *RunStateTestSuite) TestStartPipeline() {
+	t := s.T()
+	state := InitRunState()
+	state.RunTags.Enable()
+	assert.Equal(t, 0, state.GetPipeline())
+	assert.Equal(t, 1, state.StartPipeline())
+	assert.Equal(t, 2, state.StartPipeline())
+	assert.Equal(t, 2, state.GetPipeline())
+	assert.True(t, strings.HasPrefix(state.RunTags.Get(), "run=2+"))
+	state.SetPipeline(7)
+	assert.Equal(t, 8, state.StartPipeline())
+	assert.Equal(t, 0, InitRunState().GetPipeline(), "expected every RunState to count its own pipelines")
+}
+
+func (s *RunStateTestSuite) Test_countExecutionGroup() {
+	t := s.T()
+	state := InitRunState()
+	assert.Equal(t, int64(1), state.countExecutionGroup())
+	assert.Equal(t, int64(2), state.countExecutionGroup())
+	assert.Equal(t, int64(1), InitRunState().countExecutionGroup(), "expected every RunState to count its own execution groups")
+	assert.False(t, state.StatusLine == InitRunState().StatusLine, "expected every RunState to have its own status line")
+}
+
+func (s *RunStateTestSuite) Test_get() {
+	t := s.T()
+	var state *RunState
+	assert.Equal(t, DefaultRunState, state.get())
+	other := InitRunState()
+	assert.Equal(t, other, other.get())
+}
+
+func (s *RunStateTestSuite) TestCloseLoggerOutputs() {
+	t := s.T()
+	state := InitRunState()
+	file, err := ioutil.TempFile(t.TempDir(), "godev.log")
+	assert.Nil(t, err)
+	state.AddLoggerOutputs(&WriterSink{Writer: file, Closer: file})
+	assert.Len(t, state.getLoggerOutputs(), 1)
+	assert.Empty(t, DefaultRunState.getLoggerOutputs())
+	state.CloseLoggerOutputs()
+	assert.Empty(t, state.getLoggerOutputs())
+	assert.NotNil(t, file.Close(), "expected the file of the sink to be closed")
+}
@@ -1,26 +1,112 @@
-package main
+package godev
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // RunnerConfig configures the Runner
 type RunnerConfig struct {
-	Pipeline []*ExecutionGroup
-	LogLevel LogLevel
+	Pipeline       []*ExecutionGroup
+	LogLevel       LogLevel
+	MaxWarnings    int
+	MaxProcs       int
+	WatchDirectory string
+	// IsolateRuns gives every pipeline run its own temporary directory
+	// which is removed when the run succeeds
+	IsolateRuns bool
+	// AbortOnFailure skips the execution groups which have not started
+	// once one fails, as with --once where the pipeline has failed anyway
+	AbortOnFailure bool
+	// PreHook is called with the changed files before a new pipeline
+	// terminates the running one
+	PreHook func(changedFiles []string)
+	// Events receives EventBuildStarted, EventBuildCompleted and
+	// EventBuildFinished for each pipeline and is passed on to the
+	// execution groups
+	Events *EventBus
+	// Context stops the pipelines and their commands when it is done, it
+	// defaults to context.Background()
+	Context context.Context
+	// RunState counts the pipelines and holds their lint findings, it is
+	// passed on to the execution groups and defaults to DefaultRunState
+	RunState *RunState
 }
 
-// RunnerTriggerCount keeps track of the number of piplines run
-var RunnerTriggerCount = 0
+// RunnerStopInterval is how often a pipeline which is being stopped is
+// checked for execution groups it started in the meantime
+const RunnerStopInterval = 100 * time.Millisecond
 
 // Runner is the main component responsible for running the execution pipeline
 type Runner struct {
-	config    *RunnerConfig
-	logger    *Logger
-	waitGroup sync.WaitGroup
-	started   bool
-	stopped   bool
+	config         *RunnerConfig
+	logger         *Logger
+	waitGroup      sync.WaitGroup
+	disabledGroups map[int]bool
+	groupsMutex    sync.Mutex
+	// lastPipeline, lastStartedAt, lastDuration and lastFailed describe
+	// the last pipeline run and are guarded by lastRunMutex
+	lastPipeline  int
+	lastStartedAt time.Time
+	lastDuration  time.Duration
+	lastFailed    bool
+	lastRunMutex  sync.Mutex
+	exitCode      int
+	exitCodeMutex sync.Mutex
+	// pipelineMutex is held while a pipeline is started, stopped or
+	// cancelled so that concurrent triggers never run two pipelines at
+	// the same time, done is closed when the current pipeline finishes
+	pipelineMutex sync.Mutex
+	done          chan struct{}
+	// procs holds a value for each running command with --max-procs and
+	// is shared by the execution groups, it is nil without a limit
+	procs chan struct{}
+	// cancel cancels the context of the current pipeline so that its
+	// remaining execution groups are not run, the running ones are
+	// terminated separately so that they get the right signal
+	cancel      context.CancelFunc
+	cancelMutex sync.Mutex
+	// trailingRuns are the runs of execution groups which were skipped
+	// while cooling down keyed by their 0-based index, each is run once
+	// the cooldown of its group ends
+	trailingRuns  map[int]*trailingRun
+	trailingMutex sync.Mutex
+}
+
+// trailingRun holds the files which changed while an execution group was
+// cooling down, all files count as changed when a pipeline without
+// changes was skipped
+type trailingRun struct {
+	queuedAt     time.Time
+	changedFiles []string
+	everything   bool
+}
+
+// add adds the :changedFiles of a skipped pipeline to the trailing run
+func (run *trailingRun) add(changedFiles []string) {
+	if changedFiles == nil {
+		run.everything = true
+	}
+	for _, changedFile := range changedFiles {
+		if !sliceContainsString(run.changedFiles, changedFile) {
+			run.changedFiles = append(run.changedFiles, changedFile)
+		}
+	}
+}
+
+// getChangedFiles returns the files the trailing run is for, nil when
+// it is for everything
+func (run *trailingRun) getChangedFiles() []string {
+	if run.everything {
+		return nil
+	}
+	return run.changedFiles
 }
 
 // InitRunner initialises a runner
@@ -28,45 +114,701 @@ func InitRunner(config *RunnerConfig) *Runner {
 	runner := &Runner{
 		config: config,
 		logger: InitLogger(&LoggerConfig{
-			Name:   "runner",
-			Format: "production",
-			Level:  config.LogLevel},
-		),
-		started: false,
-		stopped: false,
+			Name:     "runner",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: config.RunState,
+		}),
+		disabledGroups: map[int]bool{},
+	}
+	if config.MaxProcs > 0 {
+		runner.procs = make(chan struct{}, config.MaxProcs)
 	}
 	return runner
 }
 
-func (runner *Runner) startPipeline() {
-	RunnerTriggerCount++
-	defer runner.logger.Tracef("completed pipeline %v", RunnerTriggerCount)
-	runner.logger.Tracef("starting pipeline %v", RunnerTriggerCount)
+func (runner *Runner) startPipeline(changedFiles []string) {
+	state := runner.GetRunState()
+	pipeline := state.StartPipeline()
+	defer runner.logger.Tracef("completed pipeline %v", pipeline)
+	runner.logger.Tracef("starting pipeline %v", pipeline)
+	startedAt := time.Now()
+	runner.lastRunMutex.Lock()
+	runner.lastPipeline = pipeline
+	runner.lastStartedAt = startedAt
+	runner.lastDuration = 0
+	runner.lastRunMutex.Unlock()
+	runner.exitCodeMutex.Lock()
+	runner.exitCode = 0
+	runner.exitCodeMutex.Unlock()
+	state.LintFindings.Reset()
+	for _, executionGroup := range runner.config.Pipeline {
+		executionGroup.setTerminating(false)
+	}
+	ctx, cancel := context.WithCancel(runner.getContext())
+	defer cancel()
+	runner.cancelMutex.Lock()
+	runner.cancel = cancel
+	runner.cancelMutex.Unlock()
+	runDirectory := runner.createRunDirectory()
+	runner.config.Events.Publish(&Event{
+		Name:         EventBuildStarted,
+		Time:         startedAt,
+		Pipeline:     pipeline,
+		ChangedFiles: changedFiles,
+	})
+	buildCompleted := false
+	var buildCompletedOnce sync.Once
+	completeBuild := func(failed bool) {
+		buildCompletedOnce.Do(func() {
+			buildCompleted = true
+			runner.config.Events.Publish(&Event{
+				Name:         EventBuildCompleted,
+				Pipeline:     pipeline,
+				ChangedFiles: changedFiles,
+				Duration:     time.Since(startedAt),
+				Failed:       failed,
+			})
+		})
+	}
+	var failed bool
+	if isDependencyGraph(runner.config.Pipeline) {
+		failed = runner.runGraph(ctx, changedFiles, completeBuild)
+	} else {
+		failed = runner.runSequence(ctx, changedFiles, completeBuild)
+	}
+	if failed && !runner.isTerminated() && ctx.Err() == nil {
+		runner.setExitCode(1)
+	}
+	if !buildCompleted && (runner.isTerminated() || ctx.Err() != nil) {
+		failed = true
+	}
+	completeBuild(failed)
+	runner.removeRunDirectory(runDirectory)
+	duration := time.Since(startedAt)
+	runner.lastRunMutex.Lock()
+	runner.lastDuration = duration
+	runner.lastFailed = failed
+	runner.lastRunMutex.Unlock()
+	if state.LintFindings.Count() > 0 {
+		runner.logger.Warnf("pipeline %v: %s", pipeline, state.LintFindings.Badge())
+	}
+	if summary := state.LogCounts.String(); len(summary) > 0 {
+		runner.logger.Infof("child logs this session: %s", summary)
+	}
+	runner.config.Events.Publish(&Event{
+		Name:         EventBuildFinished,
+		Pipeline:     pipeline,
+		ChangedFiles: changedFiles,
+		Duration:     duration,
+		Failed:       failed,
+	})
+}
+
+// RunnerLastRun describes the last pipeline run
+type RunnerLastRun struct {
+	// Pipeline is the number of the pipeline, counting from 1
+	Pipeline  int
+	StartedAt time.Time
+	// Duration is zero while the pipeline is running
+	Duration time.Duration
+	Failed   bool
+}
+
+// GetLastRun returns a snapshot of the last pipeline run, its StartedAt
+// is the zero time when no pipeline has run yet
+func (runner *Runner) GetLastRun() RunnerLastRun {
+	runner.lastRunMutex.Lock()
+	defer runner.lastRunMutex.Unlock()
+	return RunnerLastRun{
+		Pipeline:  runner.lastPipeline,
+		StartedAt: runner.lastStartedAt,
+		Duration:  runner.lastDuration,
+		Failed:    runner.lastFailed,
+	}
+}
+
+// getContext returns the context which the pipelines are run in
+func (runner *Runner) getContext() context.Context {
+	if runner.config.Context == nil {
+		return context.Background()
+	}
+	return runner.config.Context
+}
+
+// cancelPipeline cancels the context of the current pipeline
+func (runner *Runner) cancelPipeline() {
+	runner.cancelMutex.Lock()
+	defer runner.cancelMutex.Unlock()
+	if runner.cancel != nil {
+		runner.cancel()
+		runner.cancel = nil
+	}
+}
+
+// createRunDirectory creates the temporary directory of the pipeline run
+// with --isolate-runs and passes it on to the execution groups, it
+// returns an empty path when runs are not isolated
+func (runner *Runner) createRunDirectory() string {
+	if !runner.config.IsolateRuns {
+		return ""
+	}
+	runDirectory, err := ioutil.TempDir("", fmt.Sprintf("godev-run-%v-", runner.GetRunState().GetPipeline()))
+	if err != nil {
+		runner.logger.Warnf("pipeline %v runs without its own temporary directory: %s", runner.GetRunState().GetPipeline(), err)
+		return ""
+	}
+	runner.logger.Debugf("pipeline %v runs in '%s'", runner.GetRunState().GetPipeline(), runDirectory)
+	for _, executionGroup := range runner.config.Pipeline {
+		executionGroup.SetRunDirectory(runDirectory)
+	}
+	return runDirectory
+}
+
+// removeRunDirectory removes the temporary :runDirectory of the pipeline
+// run unless a command failed in it, in which case it is kept so that
+// its contents can be inspected
+func (runner *Runner) removeRunDirectory(runDirectory string) {
+	if len(runDirectory) == 0 {
+		return
+	}
+	for _, executionGroup := range runner.config.Pipeline {
+		executionGroup.SetRunDirectory("")
+	}
+	if runner.GetExitCode() != 0 {
+		runner.logger.Warnf("pipeline %v failed - its temporary directory is kept at '%s'", runner.GetRunState().GetPipeline(), runDirectory)
+		return
+	}
+	if err := os.RemoveAll(runDirectory); err != nil {
+		runner.logger.Warnf("unable to remove the temporary directory of pipeline %v: %s", runner.GetRunState().GetPipeline(), err)
+	}
+}
+
+// isTerminated checks whether any execution group of the pipeline was
+// terminated, in which case it failed because a new pipeline was started
+// or godev is stopping
+func (runner *Runner) isTerminated() bool {
+	for _, executionGroup := range runner.config.Pipeline {
+		if executionGroup.isTerminating() {
+			return true
+		}
+	}
+	return false
+}
+
+// runSequence runs the execution groups one after another and returns
+// whether any of them failed, the remaining groups are skipped when a
+// group with success criteria fails, the lint findings exceed the
+// maximum or :ctx is done - :completeBuild is called with whether the
+// pipeline failed so far before the supervised execution group runs
+func (runner *Runner) runSequence(ctx context.Context, changedFiles []string, completeBuild func(failed bool)) bool {
+	failed := false
+	for index, executionGroup := range runner.config.Pipeline {
+		if ctx.Err() != nil {
+			runner.logger.Infof("pipeline %v was cancelled - skipping remaining execution groups", runner.GetRunState().GetPipeline())
+			break
+		}
+		if executionGroup.supervised {
+			completeBuild(failed)
+		}
+		groupFailed, abort := runner.evaluateGroup(index, executionGroup, runner.runGroup(index, executionGroup, changedFiles))
+		failed = failed || groupFailed
+		if abort {
+			break
+		}
+	}
+	return failed
+}
+
+// runGraph runs each execution group as soon as the groups it depends on
+// have finished so that independent branches run concurrently, groups
+// whose dependencies failed are skipped and count as failed, as are those
+// which have not started when :ctx is done or evaluateGroup aborted the
+// pipeline - it returns whether any of them failed. :completeBuild is
+// called with whether any group failed so far before the supervised
+// execution group runs
+func (runner *Runner) runGraph(ctx context.Context, changedFiles []string, completeBuild func(failed bool)) bool {
 	executionGroupCount := len(runner.config.Pipeline)
-	runner.started = true
+	finished := make([]chan bool, executionGroupCount)
+	for index := range finished {
+		finished[index] = make(chan bool)
+	}
+	failedGroups := make([]bool, executionGroupCount)
+	aborted := false
+	var mutex sync.Mutex
+	var waitGroup sync.WaitGroup
 	for index, executionGroup := range runner.config.Pipeline {
-		executionGroup.logger = InitLogger(&LoggerConfig{
-			Name:   "run",
-			Format: "production",
-			Level:  runner.config.LogLevel,
-			AdditionalFields: &map[string]interface{}{
-				"submodule": fmt.Sprintf("%v/%v/%v]", RunnerTriggerCount, index+1, executionGroupCount),
-			},
-		})
-		executionGroup.Run()
+		waitGroup.Add(1)
+		go func(index int, executionGroup *ExecutionGroup) {
+			defer waitGroup.Done()
+			defer close(finished[index])
+			var failedDependencies []string
+			for _, dependency := range executionGroup.dependencies {
+				<-finished[dependency]
+				mutex.Lock()
+				if failedGroups[dependency] {
+					failedDependencies = append(failedDependencies, runner.config.Pipeline[dependency].GetLabel(dependency))
+				}
+				mutex.Unlock()
+			}
+			mutex.Lock()
+			isAborted := aborted
+			mutex.Unlock()
+			if len(failedDependencies) > 0 || isAborted || ctx.Err() != nil {
+				if ctx.Err() != nil {
+					runner.logger.Infof("execution group %v/%v is skipped because the pipeline was cancelled", index+1, executionGroupCount)
+				} else if isAborted {
+					runner.logger.Warnf("execution group %v/%v is skipped because the pipeline was aborted", index+1, executionGroupCount)
+				} else {
+					runner.logger.Warnf("execution group %v/%v is skipped because %s failed", index+1, executionGroupCount, strings.Join(failedDependencies, ", "))
+				}
+				mutex.Lock()
+				failedGroups[index] = true
+				mutex.Unlock()
+				return
+			}
+			if executionGroup.supervised {
+				mutex.Lock()
+				failed := false
+				for _, groupFailed := range failedGroups {
+					failed = failed || groupFailed
+				}
+				mutex.Unlock()
+				completeBuild(failed)
+			}
+			groupFailed := runner.runGroup(index, executionGroup, changedFiles)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if aborted {
+				failedGroups[index] = groupFailed || runner.hasExceededMaxWarnings()
+				return
+			}
+			failedGroups[index], aborted = runner.evaluateGroup(index, executionGroup, groupFailed)
+		}(index, executionGroup)
+	}
+	waitGroup.Wait()
+	for _, groupFailed := range failedGroups {
+		if groupFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateGroup decides whether the execution group at the 0-based
+// :index failed after running with :groupFailed and whether the execution
+// groups which have not started are skipped because of it - a group
+// fails when a command failed or the lint findings exceed the maximum,
+// and the pipeline is aborted when that maximum is exceeded, a group
+// with success criteria fails or any group fails with AbortOnFailure.
+// runSequence and runGraph both go through
+// it so that they decide the same way
+func (runner *Runner) evaluateGroup(index int, executionGroup *ExecutionGroup, groupFailed bool) (bool, bool) {
+	executionGroupCount := len(runner.config.Pipeline)
+	if groupFailed && executionGroup.HasSuccessCriteria() {
+		runner.logger.Errorf(
+			"pipeline %v failed: execution group %v/%v did not meet its success criteria - skipping execution groups which have not started",
+			runner.GetRunState().GetPipeline(),
+			index+1,
+			executionGroupCount,
+		)
+		return true, true
+	}
+	if runner.hasExceededMaxWarnings() {
+		runner.logger.Errorf(
+			"pipeline %v failed: %s exceeds the maximum of %v - skipping execution groups which have not started",
+			runner.GetRunState().GetPipeline(),
+			runner.GetRunState().LintFindings.Badge(),
+			runner.config.MaxWarnings,
+		)
+		return true, true
+	}
+	if groupFailed && runner.config.AbortOnFailure {
+		runner.logger.Errorf(
+			"pipeline %v failed: execution group %v/%v failed - skipping execution groups which have not started",
+			runner.GetRunState().GetPipeline(),
+			index+1,
+			executionGroupCount,
+		)
+		return true, true
+	}
+	return groupFailed, false
+}
+
+// runGroup runs the execution group at the 0-based :index unless it is
+// disabled, cooling down, has no matching changes or is skipped by its
+// script, and returns whether any of its commands failed - its commands
+// are stopped when the context of the runner is done
+func (runner *Runner) runGroup(index int, executionGroup *ExecutionGroup, changedFiles []string) bool {
+	executionGroupCount := len(runner.config.Pipeline)
+	executionGroup.events = runner.config.Events
+	executionGroup.procs = runner.procs
+	executionGroup.runState = runner.config.RunState
+	executionGroup.position = fmt.Sprintf("%v/%v", index+1, executionGroupCount)
+	executionGroup.logger = InitLogger(&LoggerConfig{
+		Name:   "run",
+		Format: "production",
+		Level:  runner.config.LogLevel,
+		AdditionalFields: &map[string]interface{}{
+			"submodule": fmt.Sprintf("%v/%v/%v]", runner.GetRunState().GetPipeline(), index+1, executionGroupCount),
+		},
+		RunState: runner.config.RunState,
+	})
+	if !runner.IsGroupEnabled(index + 1) {
+		runner.logger.Infof("execution group %v/%v is disabled - skipping", index+1, executionGroupCount)
+		return false
+	}
+	if cooldown := executionGroup.GetCooldown(); cooldown > 0 {
+		if executionGroup.MatchesChanges(changedFiles, runner.config.WatchDirectory) {
+			runner.queueTrailingRun(index, executionGroup, changedFiles, cooldown)
+			runner.logger.Infof("execution group %v/%v is cooling down for another %v - running it when it ends", index+1, executionGroupCount, cooldown.Round(time.Second))
+		} else {
+			runner.logger.Infof("execution group %v/%v is cooling down for another %v - skipping", index+1, executionGroupCount, cooldown.Round(time.Second))
+		}
+		return false
+	}
+	if !executionGroup.MatchesChanges(changedFiles, runner.config.WatchDirectory) {
+		runner.logger.Infof("execution group %v/%v has no changes matching %v - skipping", index+1, executionGroupCount, executionGroup.GetChangePatterns())
+		return false
+	}
+	if skipped, err := executionGroup.IsSkippedByScript(changedFiles, runner.config.WatchDirectory, runner.GetRunState().GetPipeline()); err != nil {
+		runner.logger.Warnf("execution group %v/%v could not evaluate its skip script - running it: %s", index+1, executionGroupCount, err)
+	} else if skipped {
+		runner.logger.Infof("execution group %v/%v is skipped by its script %s", index+1, executionGroupCount, executionGroup.skipScript)
+		return false
+	}
+	executionGroup.SetChangedFiles(changedFiles, runner.config.WatchDirectory)
+	executionGroup.Run(runner.getContext())
+	lastErrors := executionGroup.GetLastErrors()
+	if len(lastErrors) > 0 {
+		runner.logger.Warnf(
+			"execution group %v/%v failed: %v of %v command(s) failed - %s",
+			index+1,
+			executionGroupCount,
+			len(lastErrors),
+			len(executionGroup.commands),
+			strings.Join(lastErrors, "; "),
+		)
+		if !executionGroup.isTerminating() && runner.getContext().Err() == nil {
+			runner.setExitCode(executionGroup.GetExitCode())
+		}
+	}
+	return len(lastErrors) > 0
+}
+
+// queueTrailingRun queues a run of the execution group at the 0-based
+// :index for when its :cooldown ends with the :changedFiles it skipped,
+// the changes of later skipped pipelines are added to the queued run
+func (runner *Runner) queueTrailingRun(index int, executionGroup *ExecutionGroup, changedFiles []string, cooldown time.Duration) {
+	runner.trailingMutex.Lock()
+	defer runner.trailingMutex.Unlock()
+	if runner.trailingRuns == nil {
+		runner.trailingRuns = map[int]*trailingRun{}
+	}
+	if pending, ok := runner.trailingRuns[index]; ok {
+		pending.add(changedFiles)
+		return
 	}
-	runner.stopped = true
+	pending := &trailingRun{queuedAt: time.Now()}
+	pending.add(changedFiles)
+	runner.trailingRuns[index] = pending
+	time.AfterFunc(cooldown, func() { runner.runTrailingRun(index, executionGroup) })
 }
 
-// Trigger triggers the pipeline
+// runTrailingRun runs the trailing run queued for the execution group at
+// the 0-based :index unless the group has run since it was queued or the
+// context of the runner is done
+func (runner *Runner) runTrailingRun(index int, executionGroup *ExecutionGroup) {
+	runner.trailingMutex.Lock()
+	pending := runner.trailingRuns[index]
+	delete(runner.trailingRuns, index)
+	runner.trailingMutex.Unlock()
+	if pending == nil || runner.getContext().Err() != nil || executionGroup.GetLastRun().After(pending.queuedAt) {
+		return
+	}
+	runner.logger.Infof("execution group %v/%v has cooled down - running it for the changes it skipped", index+1, len(runner.config.Pipeline))
+	runner.runGroup(index, executionGroup, pending.getChangedFiles())
+}
+
+// setExitCode sets the exit code of the pipeline to :exitCode unless a
+// command failed before with its own exit code
+func (runner *Runner) setExitCode(exitCode int) {
+	runner.exitCodeMutex.Lock()
+	defer runner.exitCodeMutex.Unlock()
+	if runner.exitCode == 0 {
+		runner.exitCode = exitCode
+	}
+}
+
+// GetExitCode returns the exit code of the first command which failed in
+// the last pipeline, 1 if it failed without a command failing (eg. by
+// exceeding --max-warnings) and 0 if it succeeded or was terminated
+func (runner *Runner) GetExitCode() int {
+	runner.exitCodeMutex.Lock()
+	defer runner.exitCodeMutex.Unlock()
+	return runner.exitCode
+}
+
+// GetRunState returns the state which the pipelines of the runner are
+// counted in, DefaultRunState when it was not given one
+func (runner *Runner) GetRunState() *RunState {
+	return runner.config.RunState.get()
+}
+
+// hasExceededMaxWarnings checks if vet/lint findings exceed the configured
+// maximum, a negative maximum disables the check
+func (runner *Runner) hasExceededMaxWarnings() bool {
+	return runner.config.MaxWarnings >= 0 && runner.GetRunState().LintFindings.Count() > runner.config.MaxWarnings
+}
+
+// IsGroupEnabled checks whether the execution group at the 1-based
+// :index will be run in the pipeline
+func (runner *Runner) IsGroupEnabled(index int) bool {
+	runner.groupsMutex.Lock()
+	defer runner.groupsMutex.Unlock()
+	return !runner.disabledGroups[index]
+}
+
+// GetDisabledGroups returns the sorted 1-based indices of the disabled
+// execution groups
+func (runner *Runner) GetDisabledGroups() []int {
+	runner.groupsMutex.Lock()
+	defer runner.groupsMutex.Unlock()
+	disabledGroups := []int{}
+	for index, disabled := range runner.disabledGroups {
+		if disabled {
+			disabledGroups = append(disabledGroups, index)
+		}
+	}
+	sort.Ints(disabledGroups)
+	return disabledGroups
+}
+
+// GetMatchingGroups returns the 1-based indices of the execution groups
+// whose file patterns or routes match the :changedFiles
+func (runner *Runner) GetMatchingGroups(changedFiles []string) []int {
+	matchingGroups := []int{}
+	for index, executionGroup := range runner.config.Pipeline {
+		if executionGroup.MatchesChanges(changedFiles, runner.config.WatchDirectory) {
+			matchingGroups = append(matchingGroups, index+1)
+		}
+	}
+	return matchingGroups
+}
+
+// IsRunning checks if any execution group in the pipeline is running
+func (runner *Runner) IsRunning() bool {
+	for _, executionGroup := range runner.config.Pipeline {
+		if executionGroup.IsRunning() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGroupEnabled enables or disables the execution group at the
+// 1-based :index for subsequent pipeline runs
+func (runner *Runner) SetGroupEnabled(index int, enabled bool) error {
+	if index < 1 || index > len(runner.config.Pipeline) {
+		return fmt.Errorf("execution group %v does not exist (there are %v execution groups)", index, len(runner.config.Pipeline))
+	}
+	runner.groupsMutex.Lock()
+	defer runner.groupsMutex.Unlock()
+	runner.disabledGroups[index] = !enabled
+	return nil
+}
+
+// SelectGroups disables the execution groups which :skipSelectors select
+// and, if there are :onlySelectors, those which they do not select - the
+// selectors are names or 1-based indices
+func (runner *Runner) SelectGroups(onlySelectors, skipSelectors []string) error {
+	onlyGroups, err := findExecutionGroups(runner.config.Pipeline, onlySelectors)
+	if err != nil {
+		return err
+	}
+	skipGroups, err := findExecutionGroups(runner.config.Pipeline, skipSelectors)
+	if err != nil {
+		return err
+	}
+	for index := 1; index <= len(runner.config.Pipeline); index++ {
+		skipped := len(onlyGroups) > 0 && !sliceContainsInt(onlyGroups, index)
+		if skipped || sliceContainsInt(skipGroups, index) {
+			runner.SetGroupEnabled(index, false)
+		}
+	}
+	return nil
+}
+
+// IsReady checks whether the last execution group is running and all
+// of its commands with a readiness pattern have matched it
+func (runner *Runner) IsReady() bool {
+	if len(runner.config.Pipeline) == 0 {
+		return false
+	}
+	lastGroup := runner.config.Pipeline[len(runner.config.Pipeline)-1]
+	return lastGroup.IsRunning() && lastGroup.IsReady()
+}
+
+// Trigger triggers the pipeline with all execution groups
 func (runner *Runner) Trigger() {
-	runner.started = false
-	runner.stopped = false
-	runner.terminateIfRunning()
-	go runner.startPipeline()
+	runner.TriggerWithChanges(nil)
+}
+
+// TriggerWithChanges triggers the pipeline, skipping execution groups
+// with file patterns that none of the :changedFiles match - the running
+// pipeline is stopped before the new one starts
+func (runner *Runner) TriggerWithChanges(changedFiles []string) {
+	runner.pipelineMutex.Lock()
+	defer runner.pipelineMutex.Unlock()
+	runner.runPreHook(changedFiles)
+	runner.stopPipeline(nil)
+	done := make(chan struct{})
+	runner.done = done
+	go func() {
+		defer close(done)
+		runner.startPipeline(changedFiles)
+	}()
+}
+
+// RunOnce runs the pipeline with all execution groups, waits for it to
+// finish and returns its exit code
+func (runner *Runner) RunOnce() int {
+	runner.pipelineMutex.Lock()
+	runner.runPreHook(nil)
+	runner.stopPipeline(nil)
+	done := make(chan struct{})
+	runner.done = done
+	runner.pipelineMutex.Unlock()
+	defer close(done)
+	runner.startPipeline(nil)
+	return runner.GetExitCode()
+}
+
+// waitUntilFinished waits for the pipeline which was started last to
+// finish
+func (runner *Runner) waitUntilFinished() {
+	runner.pipelineMutex.Lock()
+	done := runner.done
+	runner.pipelineMutex.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// runPreHook calls the pre-hook, if there is one, with :changedFiles
+func (runner *Runner) runPreHook(changedFiles []string) {
+	if runner.config.PreHook != nil {
+		runner.config.PreHook(changedFiles)
+	}
+}
+
+// TriggerGroup runs only the execution group which :selector selects by
+// its name or 1-based index, restarting it if it is running
+func (runner *Runner) TriggerGroup(selector string) error {
+	indices, err := findExecutionGroups(runner.config.Pipeline, []string{selector})
+	if err != nil {
+		return err
+	}
+	index := indices[0] - 1
+	executionGroup := runner.config.Pipeline[index]
+	if executionGroup.IsRunning() {
+		executionGroup.Terminate()
+		if !executionGroup.WaitUntilStopped() {
+			runner.logger.Warnf("execution group %v/%v is still running after being terminated", index+1, len(runner.config.Pipeline))
+		}
+	}
+	go runner.runGroup(index, executionGroup, nil)
+	return nil
+}
+
+// ForwardSignal sends :signal to the running commands of the application,
+// or to all running commands when there is no application as in test
+// mode, and returns how many of them it was sent to
+func (runner *Runner) ForwardSignal(signal os.Signal) int {
+	hasApplication := false
+	for _, executionGroup := range runner.config.Pipeline {
+		hasApplication = hasApplication || executionGroup.supervised
+	}
+	forwarded := 0
+	for _, executionGroup := range runner.config.Pipeline {
+		if hasApplication && !executionGroup.supervised {
+			continue
+		}
+		for _, command := range executionGroup.commands {
+			if !command.IsRunning() {
+				continue
+			}
+			if err := command.SendSignal(signal); err != nil {
+				runner.logger.Warnf("unable to forward %v to command[%s]: %s", signal, command.GetID(), err)
+			} else {
+				forwarded++
+			}
+		}
+	}
+	return forwarded
 }
 
 func (runner *Runner) terminateIfRunning() {
+	runner.terminateWithSignal(nil)
+}
+
+// Cancel stops the running pipeline without starting a new one and
+// returns whether a pipeline was running
+func (runner *Runner) Cancel() bool {
+	runner.pipelineMutex.Lock()
+	defer runner.pipelineMutex.Unlock()
+	if !runner.isPipelineRunning() {
+		return false
+	}
+	runner.stopPipeline(nil)
+	return true
+}
+
+// terminateWithSignal stops the running pipeline by sending :signal to
+// its commands, or their stop signal when it is nil, and waits for it
+// to finish
+func (runner *Runner) terminateWithSignal(signal os.Signal) {
+	runner.pipelineMutex.Lock()
+	defer runner.pipelineMutex.Unlock()
+	runner.stopPipeline(signal)
+}
+
+// isPipelineRunning checks whether a pipeline has not finished yet or an
+// execution group is running on its own, pipelineMutex has to be held
+func (runner *Runner) isPipelineRunning() bool {
+	if runner.done != nil {
+		select {
+		case <-runner.done:
+		default:
+			return true
+		}
+	}
+	return runner.IsRunning()
+}
+
+// stopPipeline terminates the running execution groups with :signal and
+// waits for the current pipeline to finish, terminating the groups it
+// starts in the meantime - pipelineMutex has to be held
+func (runner *Runner) stopPipeline(signal os.Signal) {
+	for {
+		runner.terminateGroups(signal)
+		if runner.done == nil {
+			return
+		}
+		select {
+		case <-runner.done:
+			return
+		case <-time.After(RunnerStopInterval):
+		}
+	}
+}
+
+// terminateGroups terminates the running execution groups by sending
+// :signal to their commands, or their stop signal when it is nil, and
+// waits for them to stop - the pipeline is cancelled first so that its
+// remaining execution groups are not run
+func (runner *Runner) terminateGroups(signal os.Signal) {
+	runner.cancelPipeline()
 	defer func() {
 		if r := recover(); r != nil {
 			runner.logger.Warn(r)
@@ -74,9 +816,12 @@ func (runner *Runner) terminateIfRunning() {
 	}()
 	for index, executionGroup := range runner.config.Pipeline {
 		if executionGroup.IsRunning() {
-			runner.logger.Infof("terminating pipeline %v...", RunnerTriggerCount)
-			executionGroup.Terminate()
-			runner.logger.Infof("terminated pipeline %v", RunnerTriggerCount)
+			runner.logger.Infof("terminating pipeline %v...", runner.GetRunState().GetPipeline())
+			executionGroup.TerminateWithSignal(signal)
+			if !executionGroup.WaitUntilStopped() {
+				runner.logger.Warnf("execution group %v/%v is still running after being terminated", index+1, len(runner.config.Pipeline))
+			}
+			runner.logger.Infof("terminated pipeline %v", runner.GetRunState().GetPipeline())
 		} else {
 			runner.logger.Tracef("execution group %v/%v is not running", index, len(runner.config.Pipeline))
 		}
@@ -1,10 +1,17 @@
-package main
+package godev
 
 import (
-	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -13,7 +20,7 @@ import (
 type RunnerTestSuite struct {
 	suite.Suite
 	runner               *Runner
-	logs                 bytes.Buffer
+	logs                 syncBuffer
 	executionGroupLogger *Logger
 }
 
@@ -49,9 +56,406 @@ func (s *RunnerTestSuite) SetupTest() {
 }
 
 func (s *RunnerTestSuite) Test_startPipeline() {
-	s.runner.startPipeline()
+	s.runner.startPipeline(nil)
 	assert.Contains(s.T(), s.logs.String(), "starting pipeline")
 	assert.Contains(s.T(), s.logs.String(), "completed pipeline")
+	lastRun := s.runner.GetLastRun()
+	assert.False(s.T(), lastRun.StartedAt.IsZero())
+	assert.True(s.T(), lastRun.Duration > 0)
+	assert.False(s.T(), lastRun.Failed)
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_publishesEvents() {
+	t := s.T()
+	var events []*Event
+	s.runner.config.Events = InitEventBus(&EventBusConfig{})
+	s.runner.config.Events.Subscribe(EventAll, func(event *Event) { events = append(events, event) })
+	s.runner.startPipeline([]string{"/main.go"})
+	assert.Len(t, events, 3)
+	assert.Equal(t, EventBuildStarted, events[0].Name)
+	assert.Equal(t, []string{"/main.go"}, events[0].ChangedFiles)
+	assert.Equal(t, EventBuildCompleted, events[1].Name, "expected the build to complete with the pipeline without a supervised execution group")
+	assert.False(t, events[1].Failed)
+	assert.Equal(t, EventBuildFinished, events[2].Name)
+	assert.Equal(t, s.runner.GetRunState().GetPipeline(), events[2].Pipeline)
+	assert.Equal(t, s.runner.lastDuration, events[2].Duration)
+	assert.False(t, events[2].Failed)
+}
+
+func (s *RunnerTestSuite) TestTriggerWithChanges_completesBuildsBeforeTheApplication() {
+	t := s.T()
+	for _, graph := range []bool{false, true} {
+		events := make(chan *Event, 16)
+		s.runner.config.Events = InitEventBus(&EventBusConfig{})
+		s.runner.config.Events.Subscribe(EventAll, func(event *Event) { events <- event })
+		s.runner.config.Pipeline[1] = &ExecutionGroup{
+			commands:   []*Command{mockCommand("sleep", []string{"10"}, &s.logs)},
+			supervised: true,
+		}
+		if graph {
+			s.runner.config.Pipeline[0].dependencies = []int{}
+			s.runner.config.Pipeline[1].dependencies = []int{0}
+		}
+		s.runner.Trigger()
+		assert.Equal(t, EventBuildStarted, (<-events).Name, "graph: %v", graph)
+		completed := <-events
+		assert.Equal(t, EventBuildCompleted, completed.Name, "graph: %v - expected the build to complete while the application runs", graph)
+		assert.False(t, completed.Failed, "graph: %v", graph)
+		for !s.runner.config.Pipeline[1].IsRunning() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		s.runner.Trigger()
+		finished := <-events
+		assert.Equal(t, EventBuildFinished, finished.Name, "graph: %v", graph)
+		assert.False(t, finished.Failed, "graph: %v - expected stopping the application for a new pipeline not to fail the pipeline", graph)
+		assert.Equal(t, 0, s.runner.GetExitCode(), "graph: %v", graph)
+		s.runner.Cancel()
+		s.runner.config.Pipeline[0].dependencies = nil
+		s.runner.config.Pipeline[1].dependencies = nil
+	}
+	assert.NotContains(t, s.logs.String(), "execution group 2/2 failed")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_failsBuildsWhichAreStopped() {
+	t := s.T()
+	var events []*Event
+	s.runner.config.Events = InitEventBus(&EventBusConfig{})
+	s.runner.config.Events.Subscribe(EventAll, func(event *Event) { events = append(events, event) })
+	s.runner.config.Pipeline[0].commands[1] = mockCommand("sleep", []string{"10"}, &s.logs)
+	go func() {
+		for !s.runner.config.Pipeline[0].IsRunning() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		s.runner.terminateGroups(nil)
+	}()
+	s.runner.startPipeline(nil)
+	assert.Len(t, events, 3)
+	assert.Equal(t, EventBuildCompleted, events[1].Name)
+	assert.True(t, events[1].Failed, "expected a build which was stopped not to succeed")
+	assert.True(t, events[2].Failed)
+	assert.Equal(t, 0, s.runner.GetExitCode(), "expected a build which was stopped not to have an exit code")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_stopsWhenSuccessCriteriaAreNotMet() {
+	t := s.T()
+	s.runner.config.Pipeline[0].commands[0].config.SuccessCodes = []int{1}
+	s.runner.startPipeline(nil)
+	assert.True(t, s.runner.lastFailed)
+	assert.Contains(t, s.logs.String(), "execution group 1/2 failed: 1 of 2 command(s) failed")
+	assert.Contains(t, s.logs.String(), "execution group 1/2 did not meet its success criteria - skipping execution groups which have not started")
+	assert.True(t, s.runner.config.Pipeline[1].lastRun.IsZero(), "expected the second execution group to be skipped")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_decidesFailuresTheSameForSequencesAndGraphs() {
+	t := s.T()
+	for _, graph := range []bool{false, true} {
+		s.logs.Reset()
+		pipeline := []*ExecutionGroup{
+			&ExecutionGroup{commands: []*Command{mockCommand("sh", []string{"-c", "exit 0"}, &s.logs)}},
+			&ExecutionGroup{commands: []*Command{mockCommand("sh", []string{"-c", "sleep 0.3"}, &s.logs)}},
+			&ExecutionGroup{commands: []*Command{mockCommand("echo", []string{"runner 3"}, &s.logs)}},
+		}
+		pipeline[0].commands[0].config.SuccessCodes = []int{1}
+		if graph {
+			pipeline[0].dependencies = []int{}
+			pipeline[1].dependencies = []int{}
+			pipeline[2].dependencies = []int{1}
+		}
+		s.runner.config.Pipeline = pipeline
+		s.runner.startPipeline(nil)
+		assert.True(t, s.runner.GetLastRun().Failed, "graph: %v", graph)
+		assert.Contains(t, s.logs.String(), "execution group 1/3 did not meet its success criteria - skipping execution groups which have not started", "graph: %v", graph)
+		assert.True(t, pipeline[2].lastRun.IsZero(), "graph: %v - expected the execution groups which had not started to be skipped", graph)
+		pipeline[0].commands[0].config.SuccessCodes = nil
+		pipeline[0].commands[0] = mockCommand("sh", []string{"-c", "exit 1"}, &s.logs)
+		s.runner.startPipeline(nil)
+		assert.True(t, s.runner.GetLastRun().Failed, "graph: %v", graph)
+		assert.False(t, pipeline[2].lastRun.IsZero(), "graph: %v - expected a failure without success criteria to not abort the pipeline", graph)
+	}
+}
+
+func (s *RunnerTestSuite) TestTriggerWithChanges_runsPreHook() {
+	t := s.T()
+	hooked := make(chan []string, 1)
+	s.runner.config.PreHook = func(changedFiles []string) {
+		hooked <- changedFiles
+	}
+	s.runner.TriggerWithChanges([]string{"main.go"})
+	assert.Equal(t, []string{"main.go"}, <-hooked)
+	s.runner.waitUntilFinished()
+	assert.Equal(t, 0, s.runner.RunOnce())
+	assert.Nil(t, <-hooked, "expected the pre-hook to run before pipelines which are run once")
+}
+
+func (s *RunnerTestSuite) TestTriggerWithChanges_doesNotOverlapPipelines() {
+	t := s.T()
+	var running, overlapped int32
+	s.runner.config.Events = InitEventBus(&EventBusConfig{})
+	s.runner.config.Events.Subscribe(EventBuildStarted, func(*Event) {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+	})
+	s.runner.config.Events.Subscribe(EventBuildFinished, func(*Event) { atomic.AddInt32(&running, -1) })
+	s.runner.config.Pipeline[0].commands[1] = mockCommand("sleep", []string{"0.05"}, &s.logs)
+	var waitGroup sync.WaitGroup
+	for trigger := 0; trigger < 8; trigger++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			s.runner.Trigger()
+		}()
+	}
+	waitGroup.Wait()
+	s.runner.waitUntilFinished()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&overlapped), "expected concurrent triggers to not run pipelines at the same time")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&running))
+	assert.False(t, s.runner.Cancel(), "expected nothing to be cancelled after the last pipeline finished")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_abortsOnFailure() {
+	t := s.T()
+	for _, graph := range []bool{false, true} {
+		s.logs.Reset()
+		pipeline := []*ExecutionGroup{
+			&ExecutionGroup{commands: []*Command{mockCommand("echo", []string{"runner 1"}, &s.logs)}},
+			&ExecutionGroup{commands: []*Command{mockCommand("sh", []string{"-c", "exit 3"}, &s.logs)}},
+			&ExecutionGroup{commands: []*Command{mockCommand("echo", []string{"runner 3"}, &s.logs)}},
+		}
+		if graph {
+			pipeline[0].dependencies = []int{}
+			pipeline[1].dependencies = []int{0}
+			pipeline[2].dependencies = []int{0}
+		}
+		s.runner.config.Pipeline = pipeline
+		s.runner.config.AbortOnFailure = true
+		assert.Equal(t, 3, s.runner.RunOnce(), "graph: %v", graph)
+		assert.Contains(t, s.logs.String(), "execution group 2/3 failed - skipping execution groups which have not started", "graph: %v", graph)
+		if !graph {
+			assert.True(t, pipeline[2].lastRun.IsZero(), "expected the execution groups after the failed one to be skipped")
+		}
+	}
+}
+
+func (s *RunnerTestSuite) TestRunOnce() {
+	t := s.T()
+	defer s.logs.Reset()
+	assert.Equal(t, 0, s.runner.RunOnce())
+	assert.True(t, s.runner.GetLastRun().Duration > 0, "expected the pipeline to have finished")
+	s.runner.config.Pipeline[0].commands[1] = mockCommand("sh", []string{"-c", "sleep 0.1; exit 4"}, &s.logs)
+	s.runner.config.Pipeline[1].commands[0] = mockCommand("sh", []string{"-c", "exit 3"}, &s.logs)
+	assert.Equal(t, 4, s.runner.RunOnce(), "expected the exit code of the first command which failed")
+	s.runner.config.Pipeline[0].commands[1] = mockCommand("echo", []string{"runner 1.1"}, &s.logs)
+	s.runner.config.Pipeline[1].commands[0] = mockCommand("sh", []string{"-c", "kill -9 $$"}, &s.logs)
+	assert.Equal(t, 1, s.runner.RunOnce(), "expected commands failing without an exit code to exit with 1")
+	s.runner.setExitCode(2)
+	assert.Equal(t, 1, s.runner.GetExitCode(), "expected the first exit code to be kept")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_isolatesRuns() {
+	t := s.T()
+	defer s.logs.Reset()
+	s.runner.config.IsolateRuns = true
+	s.runner.config.Pipeline[1].commands[0] = mockCommand("sh", []string{"-c", "touch $GOTMPDIR/build && echo run dir: $GODEV_RUN_DIR"}, &s.logs)
+	s.runner.startPipeline(nil)
+	runDirectory := regexp.MustCompile(`pipeline \d+ runs in '([^']+)'`).FindStringSubmatch(s.logs.String())
+	assert.Len(t, runDirectory, 2)
+	assert.Contains(t, path.Base(runDirectory[1]), "godev-run-")
+	assert.False(t, s.runner.lastFailed, "expected the run directory to be passed on as GOTMPDIR")
+	assert.False(t, fileExists(runDirectory[1]), "expected the run directory to be removed when the run succeeds")
+	assert.Equal(t, "", s.runner.config.Pipeline[1].commands[0].runDirectory)
+
+	s.logs.Reset()
+	s.runner.config.Pipeline[1].commands[0] = mockCommand("sh", []string{"-c", "touch $GODEV_RUN_DIR/build && exit 1"}, &s.logs)
+	s.runner.startPipeline(nil)
+	runDirectory = regexp.MustCompile(`its temporary directory is kept at '([^']+)'`).FindStringSubmatch(s.logs.String())
+	assert.Len(t, runDirectory, 2)
+	defer os.RemoveAll(runDirectory[1])
+	assert.True(t, fileExists(path.Join(runDirectory[1], "build")), "expected the run directory to be kept when the run fails")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_runsGraph() {
+	t := s.T()
+	logger := s.runner.config.Pipeline[0].logger
+	lint := &ExecutionGroup{
+		commands: []*Command{mockCommand("echo", []string{"runner 3"}, &s.logs)},
+		logger:   logger,
+	}
+	s.runner.config.Pipeline = append(s.runner.config.Pipeline, lint)
+	s.runner.config.Pipeline[0].name = "build"
+	s.runner.config.Pipeline[1].name = "test"
+	lint.name = "lint"
+	assert.Nil(t, assignDependencies(s.runner.config.Pipeline, map[string][]string{"lint": []string{}}))
+	s.runner.config.Pipeline[0].commands[0] = mockCommand("sh", []string{"-c", "exit 1"}, &s.logs)
+	s.runner.startPipeline(nil)
+	assert.True(t, s.runner.lastFailed)
+	assert.Contains(t, s.logs.String(), "execution group 2/3 is skipped because 'build' failed")
+	assert.True(t, s.runner.config.Pipeline[1].lastRun.IsZero(), "expected the dependent execution group to be skipped")
+	assert.False(t, lint.lastRun.IsZero(), "expected the independent execution group to run")
+}
+
+func (s *RunnerTestSuite) TestSelectGroups() {
+	t := s.T()
+	s.runner.config.Pipeline[1].name = "test"
+	assert.Nil(t, s.runner.SelectGroups(nil, []string{"test"}))
+	assert.Equal(t, []int{2}, s.runner.GetDisabledGroups())
+	s.runner.SetGroupEnabled(2, true)
+	assert.Nil(t, s.runner.SelectGroups([]string{"test"}, nil))
+	assert.Equal(t, []int{1}, s.runner.GetDisabledGroups())
+	s.runner.SetGroupEnabled(1, true)
+	assert.Nil(t, s.runner.SelectGroups([]string{"1", "test"}, []string{"2"}))
+	assert.Equal(t, []int{2}, s.runner.GetDisabledGroups(), "expected skipped execution groups to be skipped even when selected")
+	assert.NotNil(t, s.runner.SelectGroups([]string{"vendor"}, nil), "expected unknown names to be rejected")
+	assert.NotNil(t, s.runner.SelectGroups(nil, []string{"3"}), "expected unknown indices to be rejected")
+}
+
+func (s *RunnerTestSuite) TestSetGroupEnabled() {
+	t := s.T()
+	assert.True(t, s.runner.IsGroupEnabled(1))
+	assert.Nil(t, s.runner.SetGroupEnabled(1, false))
+	assert.False(t, s.runner.IsGroupEnabled(1))
+	assert.True(t, s.runner.IsGroupEnabled(2))
+	assert.Nil(t, s.runner.SetGroupEnabled(1, true))
+	assert.True(t, s.runner.IsGroupEnabled(1))
+	assert.NotNil(t, s.runner.SetGroupEnabled(0, false))
+	assert.NotNil(t, s.runner.SetGroupEnabled(3, false))
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_skipsDisabledGroups() {
+	s.runner.SetGroupEnabled(2, false)
+	s.runner.startPipeline(nil)
+	assert.Contains(s.T(), s.logs.String(), "execution group 2/2 is disabled - skipping")
+}
+
+func (s *RunnerTestSuite) TestTriggerGroup() {
+	t := s.T()
+	assert.NotNil(t, s.runner.TriggerGroup("3"))
+	assert.NotNil(t, s.runner.TriggerGroup("test"))
+	s.runner.SetGroupEnabled(2, false)
+	assert.Nil(t, s.runner.TriggerGroup("2"))
+	for waited := 0; waited < 100 && !strings.Contains(s.logs.String(), "execution group 2/2 is disabled"); waited++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Contains(t, s.logs.String(), "execution group 2/2 is disabled - skipping")
+	assert.NotContains(t, s.logs.String(), "execution group 1/2", "expected only the triggered execution group to run")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_skipsCoolingDownGroups() {
+	s.runner.config.Pipeline[1].minInterval = time.Hour
+	s.runner.config.Pipeline[1].lastRun = time.Now()
+	s.runner.startPipeline(nil)
+	assert.Contains(s.T(), s.logs.String(), "execution group 2/2 is cooling down")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_queuesTrailingRunOfCoolingDownGroups() {
+	t := s.T()
+	s.runner.config.WatchDirectory = "/project"
+	s.runner.config.Pipeline[1].minInterval = time.Second
+	s.runner.config.Pipeline[1].lastRun = time.Now()
+	s.runner.startPipeline([]string{"/project/a.go"})
+	s.runner.startPipeline([]string{"/project/b.go", "/project/a.go"})
+	assert.Contains(t, s.logs.String(), "execution group 2/2 is cooling down for another 1s - running it when it ends")
+	s.runner.trailingMutex.Lock()
+	assert.Equal(t, []string{"/project/a.go", "/project/b.go"}, s.runner.trailingRuns[1].getChangedFiles())
+	s.runner.trailingMutex.Unlock()
+	for waited := 0; waited < 300 && !strings.Contains(s.logs.String(), "has cooled down"); waited++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Contains(t, s.logs.String(), "execution group 2/2 has cooled down - running it for the changes it skipped")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_skipsGroupsWithoutMatchingChanges() {
+	s.runner.config.WatchDirectory = "/project"
+	s.runner.config.Pipeline[1].onlyOn = []string{"*.proto"}
+	s.runner.startPipeline([]string{"/project/main.go"})
+	assert.Contains(s.T(), s.logs.String(), "execution group 2/2 has no changes matching [*.proto] - skipping")
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_passesChangedFiles() {
+	t := s.T()
+	s.runner.startPipeline([]string{"/project/main.go"})
+	for _, executionGroup := range s.runner.config.Pipeline {
+		for _, command := range executionGroup.commands {
+			assert.Equal(t, []string{"/project/main.go"}, command.changedFiles)
+		}
+	}
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_skipsGroupsByScript() {
+	t := s.T()
+	s.runner.config.WatchDirectory = "/project"
+	s.runner.config.Pipeline[0].skipScript, _ = CompileScript(`all(files, "docs/**")`)
+	s.runner.startPipeline([]string{"/project/docs/index.md"})
+	assert.Contains(t, s.logs.String(), `execution group 1/2 is skipped by its script all(files, "docs/**")`)
+	assert.Nil(t, s.runner.config.Pipeline[0].commands[0].changedFiles)
+}
+
+func (s *RunnerTestSuite) TestGetMatchingGroups() {
+	t := s.T()
+	s.runner.config.WatchDirectory = "/project"
+	s.runner.config.Pipeline[0].routes = []string{"web/**"}
+	s.runner.config.Pipeline[1].routes = []string{"**/*.go"}
+	assert.Equal(t, []int{1, 2}, s.runner.GetMatchingGroups(nil))
+	assert.Equal(t, []int{1}, s.runner.GetMatchingGroups([]string{"/project/web/app.ts"}))
+	assert.Equal(t, []int{2}, s.runner.GetMatchingGroups([]string{"/project/cmd/api/main.go"}))
+	assert.Equal(t, []int{}, s.runner.GetMatchingGroups([]string{"/project/README.md"}))
+}
+
+func (s *RunnerTestSuite) Test_hasExceededMaxWarnings() {
+	t := s.T()
+	s.runner.config.RunState = InitRunState()
+	s.runner.config.MaxWarnings = 0
+	assert.False(t, s.runner.hasExceededMaxWarnings())
+	s.runner.config.RunState.LintFindings.Add(&LintFinding{File: "main.go", Line: 1})
+	assert.True(t, s.runner.hasExceededMaxWarnings())
+	s.runner.config.MaxWarnings = 1
+	assert.False(t, s.runner.hasExceededMaxWarnings())
+	s.runner.config.MaxWarnings = -1
+	assert.False(t, s.runner.hasExceededMaxWarnings())
+}
+
+func (s *RunnerTestSuite) Test_startPipeline_stopsWhenTheContextIsDone() {
+	t := s.T()
+	defer s.logs.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	s.runner.config.Context = ctx
+	s.runner.config.Pipeline[0].commands = []*Command{mockCommand("sleep", []string{"10"}, &s.logs)}
+	startedAt := time.Now()
+	s.runner.startPipeline(nil)
+	assert.True(t, time.Since(startedAt) < DefaultKillTimeout, "expected the running command to be stopped")
+	assert.Contains(t, s.logs.String(), "was cancelled - skipping remaining execution groups")
+	assert.True(t, s.runner.config.Pipeline[1].lastRun.IsZero(), "expected the remaining execution groups not to run")
+	assert.Equal(t, 0, s.runner.GetExitCode(), "expected cancelled pipelines not to fail")
+}
+
+func (s *RunnerTestSuite) TestInitRunner_limitsProcesses() {
+	t := s.T()
+	assert.Nil(t, s.runner.procs, "expected no limit by default")
+	runner := InitRunner(&RunnerConfig{Pipeline: s.runner.config.Pipeline, MaxProcs: 2})
+	assert.Equal(t, 2, cap(runner.procs))
+	runner.logger.SetOutput(&s.logs)
+	runner.startPipeline(nil)
+	for _, executionGroup := range runner.config.Pipeline {
+		assert.Equal(t, runner.procs, executionGroup.procs, "expected the execution groups to share the limit")
+	}
+}
+
+func (s *RunnerTestSuite) TestCancel() {
+	t := s.T()
+	assert.False(t, s.runner.Cancel(), "expected nothing to be cancelled when the pipeline is idle")
+	s.runner.config.Pipeline[0].commands = []*Command{mockCommand("sleep", []string{"10"}, &s.logs)}
+	done := make(chan struct{})
+	go func() {
+		s.runner.startPipeline(nil)
+		close(done)
+	}()
+	for deadline := time.Now().Add(time.Second); !s.runner.IsRunning() && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, s.runner.Cancel())
+	<-done
+	assert.True(t, s.runner.config.Pipeline[1].lastRun.IsZero(), "expected the remaining execution groups not to run")
 }
 
 func (s *RunnerTestSuite) Test_terminateIfRunning_withoutRunningCommand() {
@@ -59,6 +463,23 @@ func (s *RunnerTestSuite) Test_terminateIfRunning_withoutRunningCommand() {
 	assert.Contains(s.T(), s.logs.String(), "is not running")
 }
 
+func (s *RunnerTestSuite) TestForwardSignal() {
+	t := s.T()
+	for _, executionGroup := range s.runner.config.Pipeline {
+		for _, command := range executionGroup.commands {
+			command.cmd = exec.Command("sleep", "5")
+			assert.Nil(t, command.cmd.Start())
+			defer command.cmd.Process.Kill()
+			command.started = true
+		}
+	}
+	assert.Equal(t, 3, s.runner.ForwardSignal(syscall.Signal(0)), "expected signals to reach all commands without an application")
+	s.runner.config.Pipeline[1].supervised = true
+	assert.Equal(t, 1, s.runner.ForwardSignal(syscall.Signal(0)), "expected signals to only reach the application")
+	s.runner.config.Pipeline[1].commands[0].stopped = true
+	assert.Equal(t, 0, s.runner.ForwardSignal(syscall.Signal(0)))
+}
+
 func (s *RunnerTestSuite) Test_terminateIfRunning_withRunningCommand() {
 	commandOfInterest := s.runner.config.Pipeline[0].commands[0]
 	commandOfInterest.started = true
@@ -70,6 +491,7 @@ func (s *RunnerTestSuite) Test_terminateIfRunning_withRunningCommand() {
 	commandOfInterest.started = false
 	commandOfInterest.stopped = false
 	commandOfInterest = s.runner.config.Pipeline[0].commands[0]
+	commandOfInterest.config.KillTimeout = 10 * time.Millisecond
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -0,0 +1,198 @@
+package godev
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// ScriptMaxSteps is the number of steps after which a script is stopped
+// so that a script cannot keep the pipeline from running
+const ScriptMaxSteps = 100000
+
+// ScriptTimeout is how long a script can run before it is stopped
+const ScriptTimeout = time.Second
+
+// Script is a Starlark expression which godev evaluates to shape the
+// pipeline, for example to skip the tests when only documentation
+// changed:
+//
+//	all(files, "docs/**/*.md")
+//
+// Scripts run without access to the file system or the network, they
+// can use the Starlark built-ins and the functions of getScriptFunctions
+type Script struct {
+	source     string
+	expression syntax.Expr
+}
+
+// CompileScript parses :source into a Script
+func CompileScript(source string) (*Script, error) {
+	expression, err := syntax.ParseExpr("script", source, 0)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid script: %s", source, err)
+	}
+	return &Script{source: source, expression: expression}, nil
+}
+
+// String returns the source of the script
+func (script *Script) String() string {
+	return script.source
+}
+
+// EvalBool evaluates the script with :variables and checks that it
+// results in a bool, the script is stopped after ScriptMaxSteps or
+// ScriptTimeout
+func (script *Script) EvalBool(variables map[string]interface{}) (bool, error) {
+	environment := getScriptFunctions()
+	for name, variable := range variables {
+		value, err := getScriptValue(variable)
+		if err != nil {
+			return false, fmt.Errorf("'%s' could not be given %s: %s", script.source, name, err)
+		}
+		environment[name] = value
+	}
+	thread := &starlark.Thread{Name: "script"}
+	thread.SetMaxExecutionSteps(ScriptMaxSteps)
+	timer := time.AfterFunc(ScriptTimeout, func() {
+		thread.Cancel(fmt.Sprintf("the script did not finish within %v", ScriptTimeout))
+	})
+	defer timer.Stop()
+	value, err := starlark.EvalExpr(thread, script.expression, environment)
+	if err != nil {
+		return false, fmt.Errorf("'%s' failed: %s", script.source, err)
+	}
+	result, ok := value.(starlark.Bool)
+	if !ok {
+		return false, fmt.Errorf("'%s' resulted in %v instead of True or False", script.source, value)
+	}
+	return bool(result), nil
+}
+
+// getScriptVariables returns the variables available to scripts for a
+// pipeline triggered by the :changedFiles, whose paths are made relative
+// to :baseDirectory
+func getScriptVariables(changedFiles []string, baseDirectory string, pipeline int) map[string]interface{} {
+	files := []string{}
+	for _, changedFile := range changedFiles {
+		if relativePath, err := filepath.Rel(baseDirectory, changedFile); err == nil {
+			files = append(files, filepath.ToSlash(relativePath))
+		} else {
+			files = append(files, filepath.ToSlash(changedFile))
+		}
+	}
+	return map[string]interface{}{
+		"files":    files,
+		"pipeline": pipeline,
+	}
+}
+
+// getScriptValue converts :value of the variables of a script into its
+// Starlark value, lists become tuples so that scripts cannot change them
+func getScriptValue(value interface{}) (starlark.Value, error) {
+	switch value := value.(type) {
+	case bool:
+		return starlark.Bool(value), nil
+	case int:
+		return starlark.MakeInt(value), nil
+	case string:
+		return starlark.String(value), nil
+	case []string:
+		values := starlark.Tuple{}
+		for _, item := range value {
+			values = append(values, starlark.String(item))
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("%T values are not supported", value)
+}
+
+// getScriptFunctions returns the functions godev adds to the Starlark
+// built-ins of scripts, patterns are matched like --include patterns -
+// true and false are kept for the scripts written before Starlark
+func getScriptFunctions() starlark.StringDict {
+	return starlark.StringDict{
+		"all":       starlark.NewBuiltin("all", countScriptMatches(func(matches, total int) starlark.Value { return starlark.Bool(matches == total) })),
+		"any":       starlark.NewBuiltin("any", countScriptMatches(func(matches, total int) starlark.Value { return starlark.Bool(matches > 0) })),
+		"count":     starlark.NewBuiltin("count", countScriptMatches(func(matches, total int) starlark.Value { return starlark.MakeInt(matches) })),
+		"none":      starlark.NewBuiltin("none", countScriptMatches(func(matches, total int) starlark.Value { return starlark.Bool(matches == 0) })),
+		"match":     starlark.NewBuiltin("match", matchScriptPattern),
+		"contains":  starlark.NewBuiltin("contains", containsScriptValue),
+		"hasPrefix": starlark.NewBuiltin("hasPrefix", compareScriptAffix(strings.HasPrefix)),
+		"hasSuffix": starlark.NewBuiltin("hasSuffix", compareScriptAffix(strings.HasSuffix)),
+		"true":      starlark.True,
+		"false":     starlark.False,
+	}
+}
+
+// scriptBuiltin is the signature of the functions of getScriptFunctions
+type scriptBuiltin func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error)
+
+// countScriptMatches returns a function taking a list and a pattern
+// which results in what :result makes of the number of values of the
+// list matching the pattern
+func countScriptMatches(result func(matches, total int) starlark.Value) scriptBuiltin {
+	return func(thread *starlark.Thread, builtin *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var list starlark.Indexable
+		var pattern string
+		if err := starlark.UnpackPositionalArgs(builtin.Name(), args, kwargs, 2, &list, &pattern); err != nil {
+			return nil, err
+		}
+		matches := 0
+		for index := 0; index < list.Len(); index++ {
+			value, ok := starlark.AsString(list.Index(index))
+			if !ok {
+				return nil, fmt.Errorf("%s: %v is not a string", builtin.Name(), list.Index(index))
+			} else if matchPattern(pattern, value) {
+				matches++
+			}
+		}
+		return result(matches, list.Len()), nil
+	}
+}
+
+// matchScriptPattern results in whether a path matches a pattern
+func matchScriptPattern(thread *starlark.Thread, builtin *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, value string
+	if err := starlark.UnpackPositionalArgs(builtin.Name(), args, kwargs, 2, &pattern, &value); err != nil {
+		return nil, err
+	}
+	return starlark.Bool(matchPattern(pattern, value)), nil
+}
+
+// containsScriptValue results in whether a list has a value or a string
+// has a substring
+func containsScriptValue(thread *starlark.Thread, builtin *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var container starlark.Value
+	var search string
+	if err := starlark.UnpackPositionalArgs(builtin.Name(), args, kwargs, 2, &container, &search); err != nil {
+		return nil, err
+	}
+	if value, ok := starlark.AsString(container); ok {
+		return starlark.Bool(strings.Contains(value, search)), nil
+	} else if list, ok := container.(starlark.Indexable); ok {
+		for index := 0; index < list.Len(); index++ {
+			if value, ok := starlark.AsString(list.Index(index)); ok && value == search {
+				return starlark.True, nil
+			}
+		}
+		return starlark.False, nil
+	}
+	return nil, fmt.Errorf("%s: %v is neither a list nor a string", builtin.Name(), container)
+}
+
+// compareScriptAffix returns a function taking a string and an affix
+// which results in what :compare makes of them
+func compareScriptAffix(compare func(string, string) bool) scriptBuiltin {
+	return func(thread *starlark.Thread, builtin *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var value, affix string
+		if err := starlark.UnpackPositionalArgs(builtin.Name(), args, kwargs, 2, &value, &affix); err != nil {
+			return nil, err
+		}
+		return starlark.Bool(compare(value, affix)), nil
+	}
+}
@@ -0,0 +1,105 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScriptTestSuite struct {
+	suite.Suite
+}
+
+func TestScript(t *testing.T) {
+	suite.Run(t, new(ScriptTestSuite))
+}
+
+func (s *ScriptTestSuite) TestCompileScript() {
+	t := s.T()
+	script, err := CompileScript(`all(files, "docs/**/*.md")`)
+	assert.Nil(t, err)
+	assert.Equal(t, `all(files, "docs/**/*.md")`, script.String())
+	_, err = CompileScript(`all(files,`)
+	assert.NotNil(t, err, "expected syntax errors to be rejected")
+}
+
+func (s *ScriptTestSuite) TestEvalBool() {
+	t := s.T()
+	variables := map[string]interface{}{
+		"files":    []string{"docs/index.md", "docs/guides/setup.md"},
+		"pipeline": 3,
+		"group":    "test",
+	}
+	for source, expected := range map[string]bool{
+		`all(files, "docs/**/*.md")`:                                   true,
+		`any(files, "**/*.go")`:                                        false,
+		`none(files, "**/*.go")`:                                       true,
+		`count(files, "docs/*.md") == 1`:                               true,
+		`len(files) > 1 and pipeline >= 3`:                             true,
+		`not (len(files) == 0 or group != "test")`:                     true,
+		`match("docs/*", files[0])`:                                    true,
+		`hasPrefix(files[1], "docs/") and hasSuffix(files[1], ".md")`:  true,
+		`contains(files, "docs/index.md")`:                             true,
+		`contains(group, "es") and group + "s" == "tests"`:             true,
+		`"docs/index.md" in files and files[0].endswith(".md")`:        true,
+		`pipeline - 4 < -0`:                                            true,
+		`False or (true and false)`:                                    false,
+		`len([f for f in files if f.startswith("docs/guides/")]) == 1`: true,
+	} {
+		result, err := evalScriptSource(source, variables)
+		assert.Nil(t, err, source)
+		assert.Equal(t, expected, result, source)
+	}
+}
+
+func (s *ScriptTestSuite) TestEvalBool_errors() {
+	t := s.T()
+	variables := map[string]interface{}{"files": []string{"main.go"}}
+	for _, source := range []string{
+		`len(files)`,
+		`unknown == 1`,
+		`exec("rm")`,
+		`files[1] == "main.go"`,
+		`len(files) < "1"`,
+		`len(files) > 0 && true`,
+		`files.Len()`,
+		`any(files)`,
+		`any(files, 1)`,
+	} {
+		_, err := evalScriptSource(source, variables)
+		assert.NotNil(t, err, source)
+	}
+}
+
+func (s *ScriptTestSuite) TestEvalBool_stopsAfterScriptMaxSteps() {
+	t := s.T()
+	_, err := evalScriptSource(`len([n for n in range(1000000)]) > 0`, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "too many steps")
+}
+
+func (s *ScriptTestSuite) Test_getScriptValue() {
+	t := s.T()
+	value, err := getScriptValue([]string{"main.go"})
+	assert.Nil(t, err)
+	assert.Equal(t, `("main.go",)`, value.String())
+	_, err = getScriptValue(1.5)
+	assert.NotNil(t, err, "expected unsupported variables to be rejected")
+}
+
+func (s *ScriptTestSuite) Test_getScriptVariables() {
+	t := s.T()
+	variables := getScriptVariables([]string{"/project/docs/index.md", "/project/main.go"}, "/project", 2)
+	assert.Equal(t, []string{"docs/index.md", "main.go"}, variables["files"])
+	assert.Equal(t, 2, variables["pipeline"])
+	assert.Equal(t, []string{}, getScriptVariables(nil, "/project", 1)["files"])
+}
+
+func evalScriptSource(source string, variables map[string]interface{}) (bool, error) {
+	compiled, err := CompileScript(source)
+	if err != nil {
+		return false, err
+	}
+	return compiled.EvalBool(variables)
+}
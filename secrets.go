@@ -0,0 +1,117 @@
+package godev
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SecretsService is the service which the secrets of godev are stored
+// under in the keychain of the operating system
+const SecretsService = "godev"
+
+// SecretReferencePrefix marks the values of --env and the env of the
+// configuration file which are the names of secrets in the keychain
+// (eg. REGISTRY_TOKEN=secret:registry-token) so that they do not have to
+// be written into the configuration in plaintext
+const SecretReferencePrefix = "secret:"
+
+// ErrSecretNotFound is returned when a secret is not in the store
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore stores the credentials which features of godev need, such
+// as webhook secrets and registry tokens, by their names
+type SecretStore interface {
+	Get(name string) (string, error)
+	Set(name string, value string) error
+	Delete(name string) error
+}
+
+// InitKeychainSecretStore creates a SecretStore backed by the keychain
+// of the operating system - the Keychain on macOS, the Secret Service
+// (through secret-tool) on Linux and the Credential Manager on Windows
+func InitKeychainSecretStore() *KeychainSecretStore {
+	return &KeychainSecretStore{service: SecretsService}
+}
+
+// KeychainSecretStore is the SecretStore of the keychain of the
+// operating system
+type KeychainSecretStore struct {
+	service string
+}
+
+// Get returns the secret named :name or ErrSecretNotFound
+func (store *KeychainSecretStore) Get(name string) (string, error) {
+	if err := validateSecretName(name); err != nil {
+		return "", err
+	}
+	return getKeychainSecret(store.service, name)
+}
+
+// Set stores :value as the secret named :name, replacing any secret
+// with the same name
+func (store *KeychainSecretStore) Set(name string, value string) error {
+	if err := validateSecretName(name); err != nil {
+		return err
+	}
+	return setKeychainSecret(store.service, name, value)
+}
+
+// Delete removes the secret named :name or returns ErrSecretNotFound
+func (store *KeychainSecretStore) Delete(name string) error {
+	if err := validateSecretName(name); err != nil {
+		return err
+	}
+	return deleteKeychainSecret(store.service, name)
+}
+
+// validateSecretName checks that :name can be used as the account of a
+// secret in every keychain
+func validateSecretName(name string) error {
+	if len(name) == 0 {
+		return errors.New("secrets must be named")
+	} else if strings.ContainsAny(name, " \t\r\n=") {
+		return fmt.Errorf("'%s' is not a valid secret name as it has whitespace or '='", name)
+	}
+	return nil
+}
+
+// ResolveSecretReferences returns :environment (KEY=value pairs) with
+// the values which reference secrets replaced by the secrets in :store,
+// along with the keys whose values were secrets so that they can be
+// left out of the logs
+func ResolveSecretReferences(environment []string, store SecretStore) ([]string, []string, error) {
+	var resolved []string
+	var secretKeys []string
+	for _, keyValue := range environment {
+		sections := strings.SplitN(keyValue, "=", 2)
+		if len(sections) != 2 || !strings.HasPrefix(sections[1], SecretReferencePrefix) {
+			resolved = append(resolved, keyValue)
+			continue
+		}
+		name := strings.TrimPrefix(sections[1], SecretReferencePrefix)
+		value, err := store.Get(name)
+		if err == ErrSecretNotFound {
+			return nil, nil, fmt.Errorf("the secret '%s' of %s is not in the keychain (store it with 'godev secret set %s')", name, sections[0], name)
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("unable to get the secret '%s' of %s: %s", name, sections[0], err)
+		}
+		resolved = append(resolved, sections[0]+"="+value)
+		secretKeys = append(secretKeys, sections[0])
+	}
+	return resolved, secretKeys, nil
+}
+
+// redactEnvironment returns :environment with the values of
+// :secretKeys replaced so that it can be logged
+func redactEnvironment(environment []string, secretKeys []string) []string {
+	var redacted []string
+	for _, keyValue := range environment {
+		key := strings.SplitN(keyValue, "=", 2)[0]
+		if sliceContainsString(secretKeys, key) {
+			keyValue = key + "=[secret]"
+		}
+		redacted = append(redacted, keyValue)
+	}
+	return redacted
+}
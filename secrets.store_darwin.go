@@ -0,0 +1,66 @@
+//go:build darwin
+// +build darwin
+
+package godev
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	shellquote "github.com/kballard/go-shellquote"
+)
+
+// darwinItemNotFound is the exit code of security when there is no
+// item which matches
+const darwinItemNotFound = 44
+
+// getKeychainSecret reads the generic password of :service and :name
+// from the login keychain
+func getKeychainSecret(service, name string) (string, error) {
+	output, err := exec.Command("security", "find-generic-password", "-s", service, "-a", name, "-w").Output()
+	if err != nil {
+		return "", getDarwinKeychainError(err, "read")
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
+
+// setKeychainSecret adds or updates the generic password of :service and
+// :name in the login keychain, the command is passed to the interactive
+// mode of security through stdin with the value hex encoded so that the
+// value does not show up in the arguments of the process
+func setKeychainSecret(service, name, value string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(shellquote.Join(
+		"add-generic-password", "-U", "-s", service, "-a", name, "-l", service+": "+name, "-X", hex.EncodeToString([]byte(value)),
+	) + "\n")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to store the secret in the keychain: %s", strings.TrimSpace(string(output)))
+	}
+	// the interactive mode does not always exit with the status of the
+	// commands it ran, so the secret is read back to confirm it
+	if stored, err := getKeychainSecret(service, name); err != nil || stored != value {
+		return errors.New("unable to store the secret in the keychain")
+	}
+	return nil
+}
+
+// deleteKeychainSecret removes the generic password of :service and
+// :name from the login keychain
+func deleteKeychainSecret(service, name string) error {
+	if err := exec.Command("security", "delete-generic-password", "-s", service, "-a", name).Run(); err != nil {
+		return getDarwinKeychainError(err, "delete")
+	}
+	return nil
+}
+
+// getDarwinKeychainError converts the :err of running security to
+// ErrSecretNotFound when there was no matching item
+func getDarwinKeychainError(err error, operation string) error {
+	if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == darwinItemNotFound {
+		return ErrSecretNotFound
+	}
+	return fmt.Errorf("unable to %s the secret in the keychain: %s", operation, err)
+}
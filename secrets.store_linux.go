@@ -0,0 +1,61 @@
+//go:build linux
+// +build linux
+
+package godev
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errNoSecretTool is returned when secret-tool, which talks to the
+// Secret Service (GNOME Keyring, KWallet), is not installed
+var errNoSecretTool = errors.New("secret-tool is needed to use the keychain (install libsecret-tools or libsecret)")
+
+// getKeychainSecret looks up the secret with the attributes service
+// :service and account :name
+func getKeychainSecret(service, name string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", errNoSecretTool
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", service, "account", name)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil && len(strings.TrimSpace(stderr.String())) == 0 {
+		return "", ErrSecretNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("unable to read the secret from the keychain: %s", strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// setKeychainSecret stores :value with the attributes service :service
+// and account :name, the value is passed through stdin so that it does
+// not show up in the arguments of the process
+func setKeychainSecret(service, name, value string) error {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return errNoSecretTool
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", service+": "+name, "service", service, "account", name)
+	cmd.Stdin = strings.NewReader(value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to store the secret in the keychain: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// deleteKeychainSecret removes the secret with the attributes service
+// :service and account :name
+func deleteKeychainSecret(service, name string) error {
+	if _, err := getKeychainSecret(service, name); err != nil {
+		return err
+	}
+	if output, err := exec.Command("secret-tool", "clear", "service", service, "account", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to delete the secret from the keychain: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
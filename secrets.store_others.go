@@ -0,0 +1,27 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package godev
+
+import (
+	"errors"
+)
+
+// errNoKeychain is returned on the platforms whose keychains are not
+// supported yet
+var errNoKeychain = errors.New("storing secrets is not supported on this platform")
+
+// getKeychainSecret is not implemented for this platform yet
+func getKeychainSecret(service, name string) (string, error) {
+	return "", errNoKeychain
+}
+
+// setKeychainSecret is not implemented for this platform yet
+func setKeychainSecret(service, name, value string) error {
+	return errNoKeychain
+}
+
+// deleteKeychainSecret is not implemented for this platform yet
+func deleteKeychainSecret(service, name string) error {
+	return errNoKeychain
+}
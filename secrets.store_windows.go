@@ -0,0 +1,110 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// procedures of the Credential Manager
+var (
+	advapi32        = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+// constants of the Credential Manager
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// windowsCredential is the CREDENTIALW structure
+type windowsCredential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// getKeychainSecret reads the generic credential :service::name
+func getKeychainSecret(service, name string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(service + ":" + name)
+	if err != nil {
+		return "", err
+	}
+	var credential *windowsCredential
+	result, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&credential)))
+	if result == 0 {
+		return "", getWindowsCredentialError(err, "read")
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(credential)))
+	if credential.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	blob := (*[1 << 20]byte)(unsafe.Pointer(credential.CredentialBlob))[:credential.CredentialBlobSize:credential.CredentialBlobSize]
+	return string(blob), nil
+}
+
+// setKeychainSecret writes :value as the generic credential
+// :service::name for the current user
+func setKeychainSecret(service, name, value string) error {
+	target, err := syscall.UTF16PtrFromString(service + ":" + name)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	blob := []byte(value)
+	credential := windowsCredential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           userName,
+	}
+	if len(blob) > 0 {
+		credential.CredentialBlob = &blob[0]
+	}
+	if result, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&credential)), 0); result == 0 {
+		return getWindowsCredentialError(err, "store")
+	}
+	return nil
+}
+
+// deleteKeychainSecret removes the generic credential :service::name
+func deleteKeychainSecret(service, name string) error {
+	target, err := syscall.UTF16PtrFromString(service + ":" + name)
+	if err != nil {
+		return err
+	}
+	if result, _, err := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); result == 0 {
+		return getWindowsCredentialError(err, "delete")
+	}
+	return nil
+}
+
+// getWindowsCredentialError converts the :err of calling the Credential
+// Manager to ErrSecretNotFound when there was no matching credential
+func getWindowsCredentialError(err error, operation string) error {
+	if err == errorNotFound {
+		return ErrSecretNotFound
+	}
+	return fmt.Errorf("unable to %s the secret in the credential manager: %s", operation, err)
+}
@@ -0,0 +1,65 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// memorySecretStore is a SecretStore for tests which keeps the secrets
+// in memory instead of the keychain
+type memorySecretStore map[string]string
+
+func (store memorySecretStore) Get(name string) (string, error) {
+	if value, ok := store[name]; ok {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+func (store memorySecretStore) Set(name string, value string) error {
+	store[name] = value
+	return nil
+}
+
+func (store memorySecretStore) Delete(name string) error {
+	if _, ok := store[name]; !ok {
+		return ErrSecretNotFound
+	}
+	delete(store, name)
+	return nil
+}
+
+type SecretsTestSuite struct {
+	suite.Suite
+}
+
+func TestSecrets(t *testing.T) {
+	suite.Run(t, new(SecretsTestSuite))
+}
+
+func (s *SecretsTestSuite) TestKeychainSecretStore_validatesNames() {
+	t := s.T()
+	store := InitKeychainSecretStore()
+	_, err := store.Get("")
+	assert.EqualError(t, err, "secrets must be named")
+	assert.EqualError(t, store.Set("registry token", "value"), "'registry token' is not a valid secret name as it has whitespace or '='")
+	assert.NotNil(t, store.Delete("a=b"))
+}
+
+func (s *SecretsTestSuite) TestResolveSecretReferences() {
+	t := s.T()
+	store := memorySecretStore{"registry-token": "t0k3n"}
+	environment, secretKeys, err := ResolveSecretReferences([]string{"A=1", "REGISTRY_TOKEN=secret:registry-token", "B=secret"}, store)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"A=1", "REGISTRY_TOKEN=t0k3n", "B=secret"}, environment)
+	assert.Equal(t, []string{"REGISTRY_TOKEN"}, secretKeys)
+	_, _, err = ResolveSecretReferences([]string{"WEBHOOK_SECRET=secret:webhook"}, store)
+	assert.EqualError(t, err, "the secret 'webhook' of WEBHOOK_SECRET is not in the keychain (store it with 'godev secret set webhook')")
+}
+
+func (s *SecretsTestSuite) Test_redactEnvironment() {
+	redacted := redactEnvironment([]string{"A=1", "REGISTRY_TOKEN=t0k3n"}, []string{"REGISTRY_TOKEN"})
+	assert.Equal(s.T(), []string{"A=1", "REGISTRY_TOKEN=[secret]"}, redacted)
+}
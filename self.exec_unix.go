@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package godev
+
+import (
+	"syscall"
+)
+
+// reexecute replaces the current process with :executable
+func reexecute(executable string, arguments []string, environment []string) error {
+	return syscall.Exec(executable, arguments, environment)
+}
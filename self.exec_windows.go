@@ -0,0 +1,24 @@
+//go:build windows
+// +build windows
+
+package godev
+
+import (
+	"os"
+	"os/exec"
+)
+
+// reexecute starts :executable as a new process attached to the current
+// console and exits since windows cannot replace the current process
+func reexecute(executable string, arguments []string, environment []string) error {
+	cmd := exec.Command(executable, arguments[1:]...)
+	cmd.Env = environment
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
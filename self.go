@@ -0,0 +1,128 @@
+package godev
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+)
+
+// SessionStateEnvironmentKey is the environment variable used to pass the
+// session state to a re-executed godev
+const SessionStateEnvironmentKey = "GODEV_SESSION_STATE"
+
+// SelfWatcherConfig configures SelfWatcher
+type SelfWatcherConfig struct {
+	Executable string
+	Interval   time.Duration
+	LogLevel   LogLevel
+	RunState   *RunState
+}
+
+// InitSelfWatcher creates a SelfWatcher which detects when the godev
+// executable is replaced by a self-update or reinstall
+func InitSelfWatcher(config *SelfWatcherConfig) *SelfWatcher {
+	selfWatcher := &SelfWatcher{
+		config: config,
+		logger: InitLogger(&LoggerConfig{
+			Name:     "self",
+			Format:   "production",
+			Level:    config.LogLevel,
+			RunState: config.RunState,
+		}),
+		stop: make(chan bool, 1),
+	}
+	selfWatcher.original, _ = os.Stat(config.Executable)
+	return selfWatcher
+}
+
+// SelfWatcher polls the godev executable for changes
+type SelfWatcher struct {
+	config   *SelfWatcherConfig
+	logger   *Logger
+	original os.FileInfo
+	stop     chan bool
+}
+
+// HasChanged checks if the executable is no longer the one which was
+// there when the SelfWatcher was initialised
+func (selfWatcher *SelfWatcher) HasChanged() bool {
+	current, err := os.Stat(selfWatcher.config.Executable)
+	if err != nil || selfWatcher.original == nil {
+		// the executable is being replaced or was never found
+		return false
+	}
+	return !os.SameFile(selfWatcher.original, current) ||
+		!current.ModTime().Equal(selfWatcher.original.ModTime()) ||
+		current.Size() != selfWatcher.original.Size()
+}
+
+// Start begins polling in the background, calling :onChange once when
+// the executable has changed
+func (selfWatcher *SelfWatcher) Start(onChange func()) {
+	go func() {
+		ticker := time.NewTicker(selfWatcher.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-selfWatcher.stop:
+				return
+			case <-ticker.C:
+				if selfWatcher.HasChanged() {
+					selfWatcher.logger.Debugf("'%s' has changed", selfWatcher.config.Executable)
+					onChange()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops polling
+func (selfWatcher *SelfWatcher) Stop() {
+	selfWatcher.stop <- true
+}
+
+// SessionState is the state of a godev session which survives a re-exec
+type SessionState struct {
+	DisabledGroups []int `json:"disabledGroups"`
+	TriggerCount   int   `json:"triggerCount"`
+}
+
+// GetSessionState captures the current session state of :runner
+func GetSessionState(runner *Runner) *SessionState {
+	return &SessionState{
+		DisabledGroups: runner.GetDisabledGroups(),
+		TriggerCount:   runner.GetRunState().GetPipeline(),
+	}
+}
+
+// Environment returns the session state as an environment variable
+func (sessionState *SessionState) Environment() string {
+	encoded, _ := json.Marshal(sessionState)
+	return SessionStateEnvironmentKey + "=" + string(encoded)
+}
+
+// Restore applies the session state to :runner
+func (sessionState *SessionState) Restore(runner *Runner) {
+	sort.Ints(sessionState.DisabledGroups)
+	for _, index := range sessionState.DisabledGroups {
+		runner.SetGroupEnabled(index, false)
+	}
+	runner.GetRunState().SetPipeline(sessionState.TriggerCount)
+}
+
+// popSessionState reads and removes the session state passed in by a
+// previous godev process so that commands do not inherit it
+func popSessionState() (*SessionState, error) {
+	encoded, ok := os.LookupEnv(SessionStateEnvironmentKey)
+	if !ok {
+		return nil, nil
+	}
+	os.Unsetenv(SessionStateEnvironmentKey)
+	sessionState := &SessionState{}
+	if err := json.Unmarshal([]byte(encoded), sessionState); err != nil {
+		return nil, err
+	}
+	return sessionState, nil
+}
@@ -0,0 +1,99 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SelfWatcherTestSuite struct {
+	suite.Suite
+	directory  string
+	executable string
+}
+
+func TestSelfWatcher(t *testing.T) {
+	suite.Run(t, new(SelfWatcherTestSuite))
+}
+
+func (s *SelfWatcherTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-self")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+	s.executable = path.Join(directory, "godev")
+	assert.Nil(s.T(), ioutil.WriteFile(s.executable, []byte("v1"), 0755))
+}
+
+func (s *SelfWatcherTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *SelfWatcherTestSuite) replaceExecutable(contents string) {
+	upgrade := path.Join(s.directory, "godev.new")
+	assert.Nil(s.T(), ioutil.WriteFile(upgrade, []byte(contents), 0755))
+	assert.Nil(s.T(), os.Rename(upgrade, s.executable))
+}
+
+func (s *SelfWatcherTestSuite) TestHasChanged() {
+	t := s.T()
+	selfWatcher := InitSelfWatcher(&SelfWatcherConfig{Executable: s.executable})
+	assert.False(t, selfWatcher.HasChanged())
+	assert.Nil(t, os.Remove(s.executable))
+	assert.False(t, selfWatcher.HasChanged(), "expected a missing executable to not count as a change")
+	s.replaceExecutable("v2")
+	assert.True(t, selfWatcher.HasChanged())
+}
+
+func (s *SelfWatcherTestSuite) TestStart() {
+	t := s.T()
+	selfWatcher := InitSelfWatcher(&SelfWatcherConfig{
+		Executable: s.executable,
+		Interval:   10 * time.Millisecond,
+	})
+	changed := make(chan bool, 1)
+	selfWatcher.Start(func() { changed <- true })
+	s.replaceExecutable("v2")
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected the upgrade to be detected")
+	}
+}
+
+func (s *SelfWatcherTestSuite) TestSessionState() {
+	t := s.T()
+	var logs bytes.Buffer
+	runner := InitRunner(&RunnerConfig{
+		Pipeline: []*ExecutionGroup{
+			&ExecutionGroup{commands: []*Command{mockCommand("true", []string{}, &logs)}},
+			&ExecutionGroup{commands: []*Command{mockCommand("true", []string{}, &logs)}},
+		},
+	})
+	runner.SetGroupEnabled(2, false)
+	runner.config.RunState = InitRunState()
+	runner.config.RunState.SetPipeline(7)
+	environment := GetSessionState(runner).Environment()
+	assert.Equal(t, `GODEV_SESSION_STATE={"disabledGroups":[2],"triggerCount":7}`, environment)
+
+	os.Setenv(SessionStateEnvironmentKey, environment[len(SessionStateEnvironmentKey)+1:])
+	sessionState, err := popSessionState()
+	assert.Nil(t, err)
+	_, stillSet := os.LookupEnv(SessionStateEnvironmentKey)
+	assert.False(t, stillSet)
+	restoredRunner := InitRunner(&RunnerConfig{Pipeline: runner.config.Pipeline, RunState: InitRunState()})
+	sessionState.Restore(restoredRunner)
+	assert.Equal(t, []int{2}, restoredRunner.GetDisabledGroups())
+	assert.Equal(t, 7, restoredRunner.GetRunState().GetPipeline())
+
+	sessionState, err = popSessionState()
+	assert.Nil(t, err)
+	assert.Nil(t, sessionState)
+}
@@ -0,0 +1,226 @@
+package godev
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	shellquote "github.com/kballard/go-shellquote"
+)
+
+// DefaultServiceHealthTimeout is how long a service has to become
+// healthy when its health-timeout is not specified
+const DefaultServiceHealthTimeout = 30 * time.Second
+
+// ServiceHealthInterval is the time between health checks of a service
+const ServiceHealthInterval = 500 * time.Millisecond
+
+// ServiceHost is the host that the ports of services are published on
+const ServiceHost = "127.0.0.1"
+
+// ServiceConfig is a container declared under the services key of the
+// configuration file which runs alongside the pipeline
+type ServiceConfig struct {
+	Image string `yaml:"image" toml:"image"`
+	// Ports are published on ServiceHost as "PORT" for the same port on
+	// both sides or as "HOST_PORT:CONTAINER_PORT"
+	Ports []string `yaml:"ports" toml:"ports"`
+	// Env is the environment of the container
+	Env map[string]string `yaml:"env" toml:"env"`
+	// Export is added to the environment of the commands
+	Export map[string]string `yaml:"export" toml:"export"`
+	// Health is run in the container until it succeeds, the published
+	// ports accepting connections is the health check without it
+	Health        string `yaml:"health" toml:"health"`
+	HealthTimeout string `yaml:"health-timeout" toml:"health-timeout"`
+}
+
+// Validate checks that the service can be started
+func (config *ServiceConfig) Validate() error {
+	if len(config.Image) == 0 {
+		return fmt.Errorf("has no image")
+	} else if err := validateContainerImage(config.Image); err != nil {
+		return err
+	}
+	for _, port := range config.Ports {
+		if _, _, err := parseServicePort(port); err != nil {
+			return err
+		}
+	}
+	if len(config.Health) > 0 {
+		if _, err := shellquote.Split(config.Health); err != nil {
+			return fmt.Errorf("has an invalid health check: %s", err)
+		}
+	}
+	if len(config.HealthTimeout) > 0 {
+		if _, err := time.ParseDuration(config.HealthTimeout); err != nil {
+			return fmt.Errorf("has an invalid health-timeout: %s", err)
+		}
+	}
+	return nil
+}
+
+// parseServicePort returns the host and container ports of :port
+func parseServicePort(port string) (int, int, error) {
+	sides := strings.SplitN(port, ":", 2)
+	var ports []int
+	for _, side := range sides {
+		number, err := strconv.Atoi(strings.TrimSpace(side))
+		if err != nil || number < 1 || number > 65535 {
+			return 0, 0, fmt.Errorf("'%s' is not a valid port (expected PORT or HOST_PORT:CONTAINER_PORT)", port)
+		}
+		ports = append(ports, number)
+	}
+	if len(ports) == 1 {
+		return ports[0], ports[0], nil
+	}
+	return ports[0], ports[1], nil
+}
+
+// InitService creates a service named :name which is run by
+// :containerRuntime once :policy allows it
+func InitService(name string, config ServiceConfig, containerRuntime string, policy *Policy, logger *Logger) *Service {
+	return &Service{
+		name:             name,
+		config:           config,
+		containerRuntime: containerRuntime,
+		containerName:    fmt.Sprintf("godev-%s-%v", name, os.Getpid()),
+		logger:           logger,
+		execute: func(application string, arguments ...string) ([]byte, error) {
+			cmd, err := policy.Command("", application, arguments...)
+			if err != nil {
+				return nil, err
+			}
+			return cmd.CombinedOutput()
+		},
+	}
+}
+
+// Service is a container which is started before the first pipeline
+// and removed when godev exits
+type Service struct {
+	name             string
+	config           ServiceConfig
+	containerRuntime string
+	containerName    string
+	logger           *Logger
+	started          bool
+	mutex            sync.Mutex
+	// execute runs the container runtime, replaced in tests
+	execute func(application string, arguments ...string) ([]byte, error)
+}
+
+// GetName returns the name of the service in the configuration file
+func (service *Service) GetName() string {
+	return service.name
+}
+
+// GetEnvironment returns NAME_HOST and NAME_PORT for the first published
+// port followed by the exported variables of the service
+func (service *Service) GetEnvironment() []string {
+	prefix := strings.ToUpper(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, service.name))
+	environment := []string{prefix + "_HOST=" + ServiceHost}
+	if len(service.config.Ports) > 0 {
+		hostPort, _, _ := parseServicePort(service.config.Ports[0])
+		environment = append(environment, fmt.Sprintf("%s_PORT=%v", prefix, hostPort))
+	}
+	var keys []string
+	for key := range service.config.Export {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		environment = append(environment, key+"="+service.config.Export[key])
+	}
+	return environment
+}
+
+// getRunArguments returns the arguments of the container runtime which
+// start the service in the background
+func (service *Service) getRunArguments() []string {
+	arguments := []string{"run", "-d", "--rm", "--name", service.containerName}
+	for _, port := range service.config.Ports {
+		hostPort, containerPort, _ := parseServicePort(port)
+		arguments = append(arguments, "-p", fmt.Sprintf("%s:%v:%v", ServiceHost, hostPort, containerPort))
+	}
+	var keys []string
+	for key := range service.config.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		arguments = append(arguments, "-e", key+"="+service.config.Env[key])
+	}
+	return append(arguments, service.config.Image)
+}
+
+// Start starts the container and waits until it is healthy
+func (service *Service) Start() error {
+	service.logger.Infof("starting service '%s' (%s)...", service.name, service.config.Image)
+	if output, err := service.execute(service.containerRuntime, service.getRunArguments()...); err != nil {
+		return fmt.Errorf("service '%s' could not be started: %s: %s", service.name, err, strings.TrimSpace(string(output)))
+	}
+	service.mutex.Lock()
+	service.started = true
+	service.mutex.Unlock()
+	timeout := DefaultServiceHealthTimeout
+	if len(service.config.HealthTimeout) > 0 {
+		timeout, _ = time.ParseDuration(service.config.HealthTimeout)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := service.checkHealth()
+		if err == nil {
+			service.logger.Infof("service '%s' is healthy", service.name)
+			return nil
+		} else if time.Now().After(deadline) {
+			return fmt.Errorf("service '%s' did not become healthy within %v: %s", service.name, timeout, err)
+		}
+		time.Sleep(ServiceHealthInterval)
+	}
+}
+
+// checkHealth runs the health check of the service once
+func (service *Service) checkHealth() error {
+	if len(service.config.Health) > 0 {
+		command, _ := shellquote.Split(service.config.Health)
+		arguments := append([]string{"exec", service.containerName}, command...)
+		if output, err := service.execute(service.containerRuntime, arguments...); err != nil {
+			return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	for _, port := range service.config.Ports {
+		hostPort, _, _ := parseServicePort(port)
+		connection, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%v", ServiceHost, hostPort), ServiceHealthInterval)
+		if err != nil {
+			return err
+		}
+		connection.Close()
+	}
+	return nil
+}
+
+// Stop removes the container if it was started
+func (service *Service) Stop() {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	if !service.started {
+		return
+	}
+	service.logger.Infof("stopping service '%s'...", service.name)
+	if output, err := service.execute(service.containerRuntime, "rm", "-f", service.containerName); err != nil {
+		service.logger.Warnf("service '%s' could not be removed: %s: %s", service.name, err, strings.TrimSpace(string(output)))
+	}
+	service.started = false
+}
@@ -0,0 +1,142 @@
+package godev
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServiceTestSuite struct {
+	suite.Suite
+	logs     bytes.Buffer
+	logger   *Logger
+	commands []string
+}
+
+func TestService(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
+
+func (s *ServiceTestSuite) SetupTest() {
+	s.logs.Reset()
+	s.commands = nil
+	s.logger = InitLogger(&LoggerConfig{Name: "test"})
+	s.logger.SetOutput(&s.logs)
+}
+
+// initService returns a service whose container runtime commands are
+// recorded in s.commands, :fail decides whether a command fails
+func (s *ServiceTestSuite) initService(config ServiceConfig, fail func(command string) bool) *Service {
+	service := InitService("postgres", config, "podman", nil, s.logger)
+	service.containerName = "godev-postgres"
+	service.execute = func(application string, arguments ...string) ([]byte, error) {
+		command := strings.Join(append([]string{application}, arguments...), " ")
+		s.commands = append(s.commands, command)
+		if fail != nil && fail(command) {
+			return []byte("not ready"), errors.New("exit status 1")
+		}
+		return nil, nil
+	}
+	return service
+}
+
+func (s *ServiceTestSuite) TestStartAndStop() {
+	t := s.T()
+	healthChecks := 0
+	service := s.initService(ServiceConfig{
+		Image:  "postgres:13",
+		Ports:  []string{"15432:5432"},
+		Env:    map[string]string{"POSTGRES_PASSWORD": "dev", "POSTGRES_DB": "app"},
+		Health: "pg_isready -U postgres",
+	}, func(command string) bool {
+		if strings.Contains(command, " exec ") {
+			healthChecks++
+			return healthChecks < 2
+		}
+		return false
+	})
+	service.Stop()
+	assert.Empty(t, s.commands, "expected services which were not started not to be removed")
+	assert.Nil(t, service.Start())
+	assert.Equal(t, []string{
+		"podman run -d --rm --name godev-postgres -p 127.0.0.1:15432:5432 -e POSTGRES_DB=app -e POSTGRES_PASSWORD=dev postgres:13",
+		"podman exec godev-postgres pg_isready -U postgres",
+		"podman exec godev-postgres pg_isready -U postgres",
+	}, s.commands)
+	assert.Contains(t, s.logs.String(), "service 'postgres' is healthy")
+	service.Stop()
+	service.Stop()
+	assert.Equal(t, "podman rm -f godev-postgres", s.commands[len(s.commands)-1])
+	assert.Len(t, s.commands, 4, "expected services to be removed once")
+}
+
+func (s *ServiceTestSuite) TestStart_withUnhealthyService() {
+	t := s.T()
+	service := s.initService(ServiceConfig{
+		Image:         "redis:6",
+		Health:        "redis-cli ping",
+		HealthTimeout: "1ms",
+	}, func(command string) bool { return strings.Contains(command, " exec ") })
+	err := service.Start()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "did not become healthy within 1ms")
+	service.Stop()
+	assert.Equal(t, "podman rm -f godev-postgres", s.commands[len(s.commands)-1], "expected unhealthy services to be removable")
+}
+
+func (s *ServiceTestSuite) TestStart_withRuntimeError() {
+	t := s.T()
+	service := s.initService(ServiceConfig{Image: "redis:6"}, func(string) bool { return true })
+	err := service.Start()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not ready")
+	service.Stop()
+	assert.Len(t, s.commands, 1, "expected services which could not be started not to be removed")
+}
+
+func (s *ServiceTestSuite) Test_checkHealth_withPorts() {
+	t := s.T()
+	listener, err := net.Listen("tcp", ServiceHost+":0")
+	assert.Nil(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	service := s.initService(ServiceConfig{Image: "redis:6", Ports: []string{fmt.Sprintf("%v:6379", port)}}, nil)
+	assert.Nil(t, service.checkHealth())
+	listener.Close()
+	assert.NotNil(t, service.checkHealth(), "expected closed ports to be unhealthy")
+}
+
+func (s *ServiceTestSuite) TestGetEnvironment() {
+	t := s.T()
+	service := InitService("local-stack", ServiceConfig{
+		Image:  "localstack/localstack",
+		Ports:  []string{"4566", "4571"},
+		Export: map[string]string{"AWS_REGION": "us-east-1", "AWS_ENDPOINT": "http://127.0.0.1:4566"},
+	}, "docker", nil, s.logger)
+	assert.Equal(t, []string{
+		"LOCAL_STACK_HOST=127.0.0.1",
+		"LOCAL_STACK_PORT=4566",
+		"AWS_ENDPOINT=http://127.0.0.1:4566",
+		"AWS_REGION=us-east-1",
+	}, service.GetEnvironment())
+}
+
+func (s *ServiceTestSuite) TestServiceConfig_Validate() {
+	t := s.T()
+	assert.Nil(t, (&ServiceConfig{Image: "postgres:13", Ports: []string{"5432", "15432:5432"}, HealthTimeout: "1m"}).Validate())
+	for _, invalid := range []ServiceConfig{
+		{},
+		{Image: "-it"},
+		{Image: "postgres", Ports: []string{"postgres"}},
+		{Image: "postgres", Ports: []string{"70000"}},
+		{Image: "postgres", Health: "pg_isready 'unclosed"},
+		{Image: "postgres", HealthTimeout: "soon"},
+	} {
+		assert.NotNilf(t, invalid.Validate(), "expected %v to be invalid", invalid)
+	}
+}
@@ -0,0 +1,210 @@
+package godev
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// StatusLineFrames are the frames of the spinner of the status line
+var StatusLineFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// StatusLineDelay is how long a step has to run without any output
+// before the status line is shown
+const StatusLineDelay = time.Second
+
+// StatusLineRefreshRate is how often the status line is redrawn
+const StatusLineRefreshRate = 100 * time.Millisecond
+
+// StatusLineMaxWidth is the number of characters the status line is
+// truncated to so that it does not wrap and cannot be cleared
+const StatusLineMaxWidth = 72
+
+// StatusLineClear moves the cursor to the start of the line and
+// erases it
+const StatusLineClear = "\r" + ColorStub + "K"
+
+// statusLineTrailingColors matches the colour codes at the end of some
+// output, such as those which the loggers write after a line
+var statusLineTrailingColors = regexp.MustCompile("(\033\\[[0-9;]*m)+$")
+
+// StatusLine is a single line at the bottom of the terminal showing a
+// spinner, the elapsed time and the step which is running, it is
+// cleared before any other output so that it never mixes with it
+type StatusLine struct {
+	writer io.Writer
+	steps  map[interface{}]*statusLineStep
+	mutex  sync.Mutex
+	stop   chan struct{}
+	frame  int
+	shown  bool
+	// lineEnded is whether the last output ended its line, the status
+	// line would otherwise overwrite a prompt or a progress bar
+	lineEnded  bool
+	lastOutput time.Time
+	// enabled is the number of Enable calls which Disable has not been
+	// called for yet
+	enabled int
+}
+
+// InitStatusLine creates a StatusLine which is not drawn until Enable is
+// called
+func InitStatusLine() *StatusLine {
+	return &StatusLine{steps: map[interface{}]*statusLineStep{}}
+}
+
+type statusLineStep struct {
+	label     string
+	startedAt time.Time
+}
+
+// isTerminal checks if :file is a terminal rather than a file, a pipe
+// or the null device which is also a character device
+func isTerminal(file *os.File) bool {
+	fileInfo, err := file.Stat()
+	if err != nil || fileInfo.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	nullInfo, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(fileInfo, nullInfo)
+}
+
+// Enable starts drawing the status line to :writer until Disable is
+// called as many times as Enable was, the writer of the first call is
+// kept
+func (status *StatusLine) Enable(writer io.Writer) {
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+	status.enabled++
+	if status.stop != nil {
+		return
+	}
+	status.writer = writer
+	status.stop = make(chan struct{})
+	status.lineEnded = true
+	go status.refresh(status.stop)
+}
+
+// Disable stops drawing the status line and clears it once it was
+// called for every call of Enable
+func (status *StatusLine) Disable() {
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+	if status.enabled > 0 {
+		status.enabled--
+	}
+	if status.stop == nil || status.enabled > 0 {
+		return
+	}
+	close(status.stop)
+	status.stop = nil
+	status.clear()
+}
+
+// IsEnabled checks whether the status line is being drawn
+func (status *StatusLine) IsEnabled() bool {
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+	return status.stop != nil
+}
+
+// Begin shows :label in the status line until End is called with the
+// same :key
+func (status *StatusLine) Begin(key interface{}, label string) {
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+	status.steps[key] = &statusLineStep{label: label, startedAt: time.Now()}
+}
+
+// End removes the step started with :key from the status line
+func (status *StatusLine) End(key interface{}) {
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+	delete(status.steps, key)
+}
+
+// Wrap returns a writer which clears the status line before writing to
+// :writer and delays it from being drawn again
+func (status *StatusLine) Wrap(writer io.Writer) io.Writer {
+	return &statusLineWriter{status: status, writer: writer}
+}
+
+func (status *StatusLine) refresh(stop chan struct{}) {
+	ticker := time.NewTicker(StatusLineRefreshRate)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			status.mutex.Lock()
+			if line := status.render(now); len(line) > 0 {
+				fmt.Fprint(status.writer, StatusLineClear+line)
+				status.shown = true
+				status.frame++
+			} else {
+				status.clear()
+			}
+			status.mutex.Unlock()
+		}
+	}
+}
+
+// render returns the status line at :now for the step which has been
+// running the longest, or an empty string when it should not be shown
+func (status *StatusLine) render(now time.Time) string {
+	if !status.lineEnded || now.Sub(status.lastOutput) < StatusLineDelay {
+		return ""
+	}
+	var longest *statusLineStep
+	for _, step := range status.steps {
+		if longest == nil || step.startedAt.Before(longest.startedAt) {
+			longest = step
+		}
+	}
+	if longest == nil || now.Sub(longest.startedAt) < StatusLineDelay {
+		return ""
+	}
+	line := fmt.Sprintf(
+		"%s %v %s",
+		StatusLineFrames[status.frame%len(StatusLineFrames)],
+		now.Sub(longest.startedAt).Truncate(time.Second),
+		longest.label,
+	)
+	if len(status.steps) > 1 {
+		line += fmt.Sprintf(" (+%v more)", len(status.steps)-1)
+	}
+	if characters := []rune(line); len(characters) > StatusLineMaxWidth {
+		line = string(characters[:StatusLineMaxWidth-1]) + "…"
+	}
+	return Color("gray", line)
+}
+
+// clear erases the status line when it is shown, the mutex has to be
+// held by the caller
+func (status *StatusLine) clear() {
+	if status.shown {
+		fmt.Fprint(status.writer, StatusLineClear)
+		status.shown = false
+	}
+}
+
+type statusLineWriter struct {
+	status *StatusLine
+	writer io.Writer
+}
+
+func (writer *statusLineWriter) Write(output []byte) (int, error) {
+	status := writer.status
+	status.mutex.Lock()
+	defer status.mutex.Unlock()
+	status.clear()
+	status.lastOutput = time.Now()
+	if content := statusLineTrailingColors.ReplaceAll(output, nil); len(content) > 0 {
+		status.lineEnded = content[len(content)-1] == '\n'
+	}
+	return writer.writer.Write(output)
+}
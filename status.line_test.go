@@ -0,0 +1,127 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusLineTestSuite struct {
+	suite.Suite
+	status *StatusLine
+	output bytes.Buffer
+}
+
+func TestStatusLine(t *testing.T) {
+	suite.Run(t, new(StatusLineTestSuite))
+}
+
+func (s *StatusLineTestSuite) SetupTest() {
+	s.output.Reset()
+	s.status = &StatusLine{steps: map[interface{}]*statusLineStep{}, writer: &s.output, lineEnded: true}
+}
+
+func (s *StatusLineTestSuite) TestRender() {
+	t := s.T()
+	now := time.Now()
+	assert.Equal(t, "", s.status.render(now), "expected nothing to be shown without running steps")
+	s.status.steps["vendor"] = &statusLineStep{label: "group 1/3: go mod vendor", startedAt: now.Add(-500 * time.Millisecond)}
+	assert.Equal(t, "", s.status.render(now), "expected short steps not to be shown")
+	s.status.steps["vendor"].startedAt = now.Add(-12*time.Second - 300*time.Millisecond)
+	assert.Contains(t, s.status.render(now), StatusLineFrames[0]+" 12s group 1/3: go mod vendor")
+	s.status.frame = 1
+	s.status.steps["vet"] = &statusLineStep{label: "group 1/3: go vet ./...", startedAt: now.Add(-2 * time.Second)}
+	assert.Contains(t, s.status.render(now), StatusLineFrames[1]+" 12s group 1/3: go mod vendor (+1 more)", "expected the longest running step to be shown")
+	s.status.lastOutput = now.Add(-100 * time.Millisecond)
+	assert.Equal(t, "", s.status.render(now), "expected the status line to wait for the output to go quiet")
+	s.status.lastOutput = time.Time{}
+	s.status.lineEnded = false
+	assert.Equal(t, "", s.status.render(now), "expected the status line not to overwrite unfinished lines")
+	s.status.lineEnded = true
+	s.status.steps["vendor"].label = strings.Repeat("x", 100)
+	assert.Contains(t, s.status.render(now), "x…")
+	assert.NotContains(t, s.status.render(now), strings.Repeat("x", StatusLineMaxWidth))
+}
+
+func (s *StatusLineTestSuite) TestWrap() {
+	t := s.T()
+	var written bytes.Buffer
+	writer := s.status.Wrap(&written)
+	s.status.shown = true
+	count, err := writer.Write([]byte("downloading..."))
+	assert.Nil(t, err)
+	assert.Equal(t, 14, count)
+	assert.Equal(t, StatusLineClear, s.output.String(), "expected the status line to be cleared before any output")
+	assert.Equal(t, "downloading...", written.String())
+	assert.False(t, s.status.shown)
+	assert.False(t, s.status.lineEnded)
+	assert.WithinDuration(t, time.Now(), s.status.lastOutput, time.Second)
+	writer.Write([]byte(" done\n"))
+	assert.True(t, s.status.lineEnded)
+	writer.Write([]byte(Color("green", "downloading")))
+	assert.False(t, s.status.lineEnded)
+	writer.Write([]byte("\n" + ColorStub + "0m"))
+	assert.True(t, s.status.lineEnded, "expected colour codes after a line not to count as output")
+	assert.Equal(t, StatusLineClear, s.output.String(), "expected the status line to only be cleared when it is shown")
+}
+
+func (s *StatusLineTestSuite) TestEnable() {
+	t := s.T()
+	var output bytes.Buffer
+	status := &StatusLine{steps: map[interface{}]*statusLineStep{}}
+	assert.False(t, status.IsEnabled())
+	status.Begin("build", "group 2/3: go build")
+	status.mutex.Lock()
+	status.steps["build"].startedAt = time.Now().Add(-time.Minute)
+	status.mutex.Unlock()
+	status.Enable(&output)
+	assert.True(t, status.IsEnabled())
+	time.Sleep(3 * StatusLineRefreshRate)
+	status.Disable()
+	assert.False(t, status.IsEnabled())
+	assert.Contains(t, output.String(), "1m0s group 2/3: go build")
+	assert.True(t, strings.HasSuffix(output.String(), StatusLineClear), "expected the status line to be cleared when it is disabled")
+	status.End("build")
+	assert.Empty(t, status.steps)
+}
+
+func (s *StatusLineTestSuite) TestEnable_nested() {
+	t := s.T()
+	var output bytes.Buffer
+	status := &StatusLine{steps: map[interface{}]*statusLineStep{}}
+	status.Enable(&output)
+	status.Enable(&output)
+	status.Disable()
+	assert.True(t, status.IsEnabled(), "expected the status line to be drawn until every Enable was disabled")
+	status.Disable()
+	assert.False(t, status.IsEnabled())
+	status.Disable()
+	status.Enable(&output)
+	assert.True(t, status.IsEnabled(), "expected extra calls of Disable not to be counted")
+	status.Disable()
+	assert.False(t, status.IsEnabled())
+}
+
+func (s *StatusLineTestSuite) Test_isTerminal() {
+	t := s.T()
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Skipf("cannot open %s: %s", os.DevNull, err)
+	}
+	defer null.Close()
+	assert.False(t, isTerminal(null), "expected the null device not to be a terminal")
+	file, err := ioutil.TempFile("", "godev-status-line")
+	if err != nil {
+		t.Errorf("error while creating a temporary file: %s", err)
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	assert.False(t, isTerminal(file))
+}
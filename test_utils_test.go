@@ -1,14 +1,16 @@
-package main
+package godev
 
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,7 +68,7 @@ func ensureCLIStartSetsRunFlag(t *testing.T, withArgs []string, runFlagName stri
 	cli := initCLI()
 	var logs bytes.Buffer
 	cli.rawLogger.SetOutput(&logs)
-	cli.Start(withArgs, func(config *Config) {
+	err := cli.Start(withArgs, func(config *Config) error {
 		assert.NotNil(t, config)
 		configInstance := reflect.ValueOf(config)
 		flagValue := reflect.Indirect(configInstance).FieldByName(runFlagName)
@@ -74,10 +76,37 @@ func ensureCLIStartSetsRunFlag(t *testing.T, withArgs []string, runFlagName stri
 		if len(and) > 0 {
 			and[0](logs)
 		}
+		return nil
 	})
+	assert.Nil(t, err)
+}
+
+// syncBuffer is a bytes.Buffer which the goroutines under test can
+// write to while the test reads it
+type syncBuffer struct {
+	buffer bytes.Buffer
+	mutex  sync.Mutex
+}
+
+func (buffer *syncBuffer) Write(data []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.Write(data)
+}
+
+func (buffer *syncBuffer) String() string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.String()
 }
 
-func mockCommand(application string, arguments []string, logOutput *bytes.Buffer) *Command {
+func (buffer *syncBuffer) Reset() {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	buffer.buffer.Reset()
+}
+
+func mockCommand(application string, arguments []string, logOutput io.Writer) *Command {
 	command := &Command{
 		id: fmt.Sprintf("%s%v", application, arguments),
 		config: &CommandConfig{
@@ -90,24 +119,12 @@ func mockCommand(application string, arguments []string, logOutput *bytes.Buffer
 			Level:  "trace",
 		}),
 		signal: make(chan os.Signal),
+		status: make(chan error),
 	}
 	command.logger.SetOutput(logOutput)
 	return command
 }
 
-func expectError(t *testing.T) func() {
-	return func() {
-		assert.NotNil(t, recover(), "expected an error but none was panicked")
-	}
-}
-
-func expectNoError(t *testing.T) func() {
-	return func() {
-		err := recover()
-		assert.Nilf(t, err, "expected no errors but '%s' was panicked", err)
-	}
-}
-
 func removeFile(t *testing.T, pathToFile string) {
 	err := os.Remove(pathToFile)
 	assert.Nilf(
@@ -1,10 +1,12 @@
-package main
+package godev
 
 import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ConfigCommaDelimitedString holds an array of strings to enable
@@ -37,33 +39,117 @@ func (cmfs *ConfigMultiflagString) String() string {
 	return strings.Join(*cmfs, ",")
 }
 
-func getCurrentWorkingDirectory() string {
-	cwd, err := os.Getwd()
+// byteSizeUnits maps the supported units of parseByteSize to their multipliers
+var byteSizeUnits = map[string]int64{
+	"":   1,
+	"B":  1,
+	"KB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+}
+
+// parseByteSize parses :value like "512", "64KB" or "1MB" into a number of bytes
+func parseByteSize(value string) (int64, error) {
+	normalised := strings.ToUpper(strings.TrimSpace(value))
+	numberEnd := strings.IndexFunc(normalised, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if numberEnd < 0 {
+		numberEnd = len(normalised)
+	}
+	multiplier, ok := byteSizeUnits[strings.TrimSpace(normalised[numberEnd:])]
+	if !ok {
+		return 0, fmt.Errorf("'%s' has an unknown unit (use one of B, KB, MB, GB)", value)
+	}
+	number, err := strconv.ParseFloat(normalised[:numberEnd], 64)
 	if err != nil {
-		panic(err)
+		return 0, fmt.Errorf("'%s' is not a valid size", value)
 	}
-	return cwd
+	return int64(number * float64(multiplier)), nil
 }
 
-const confirmationTrueCanonical = "y"
+// formatByteSize formats :size like "512 B", "64.0 KB" or "1.5 MB"
+func formatByteSize(size int64) string {
+	for _, unit := range []string{"GB", "MB", "KB"} {
+		if size >= byteSizeUnits[unit] || -size >= byteSizeUnits[unit] {
+			return fmt.Sprintf("%.1f %s", float64(size)/float64(byteSizeUnits[unit]), unit)
+		}
+	}
+	return fmt.Sprintf("%v B", size)
+}
 
-var confirmationTrue = []string{confirmationTrueCanonical, "yes", "yupp", "yeah", "yea", "ok", "okay"}
+// formatByteSizeDelta formats :delta like formatByteSize with its sign
+func formatByteSizeDelta(delta int64) string {
+	if delta == 0 {
+		return "unchanged"
+	} else if delta > 0 {
+		return "+" + formatByteSize(delta)
+	}
+	return formatByteSize(delta)
+}
 
-const confirmationFalseCanonical = "n"
+// parseGroupDurations parses :values in the form "<group index>=<duration>"
+// into a map of 1-based execution group indices to durations
+func parseGroupDurations(values []string) (map[int]time.Duration, error) {
+	durations := map[int]time.Duration{}
+	for _, value := range values {
+		sections := strings.SplitN(value, "=", 2)
+		if len(sections) != 2 {
+			return nil, fmt.Errorf("'%s' should be in the form <group index>=<duration>", value)
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(sections[0]))
+		if err != nil || index < 1 {
+			return nil, fmt.Errorf("'%s' is not a valid execution group index", sections[0])
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(sections[1]))
+		if err != nil {
+			return nil, err
+		}
+		durations[index] = duration
+	}
+	return durations, nil
+}
+
+// parseGroupRoutes parses :values in the form "<pattern>=<group name>"
+// into a map of file patterns to the names of the execution groups they
+// route changes to
+func parseGroupRoutes(values []string) (map[string][]string, error) {
+	routes := map[string][]string{}
+	for _, value := range values {
+		sections := strings.SplitN(value, "=", 2)
+		if len(sections) != 2 || len(strings.TrimSpace(sections[1])) == 0 {
+			return nil, fmt.Errorf("'%s' should be in the form <pattern>=<group name>", value)
+		}
+		pattern := strings.TrimSpace(sections[0])
+		if err := validatePatterns([]string{pattern}); err != nil {
+			return nil, err
+		}
+		routes[pattern] = append(routes[pattern], strings.TrimSpace(sections[1]))
+	}
+	return routes, nil
+}
 
-var confirmationFalse = []string{confirmationFalseCanonical, "no", "nope", "nah", "neh", "stop", "dont"}
+func getCurrentWorkingDirectory() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return cwd
+}
 
-func confirm(reader *bufio.Reader, question string, byDefault bool, retryText ...string) bool {
+func confirm(messages *Messages, reader *bufio.Reader, question string, byDefault bool, retryText ...string) bool {
+	confirmationTrue := strings.Split(messages.Get(MessageConfirmYes), ",")
+	confirmationFalse := strings.Split(messages.Get(MessageConfirmNo), ",")
 	var options string
 	if byDefault {
-		options = fmt.Sprintf("%s/%s", strings.ToUpper(confirmationTrueCanonical), confirmationFalseCanonical)
+		options = fmt.Sprintf("%s/%s", strings.ToUpper(confirmationTrue[0]), confirmationFalse[0])
 	} else {
-		options = fmt.Sprintf("%s/%s", confirmationTrueCanonical, strings.ToUpper(confirmationFalseCanonical))
+		options = fmt.Sprintf("%s/%s", confirmationTrue[0], strings.ToUpper(confirmationFalse[0]))
 	}
 	fmt.Printf("%s [%s]: ", question, options)
 	userInput, err := reader.ReadString('\n')
 	if err != nil {
-		panic(err)
+		return byDefault
 	}
 	if len(userInput) < 2 {
 		return byDefault
@@ -79,7 +165,7 @@ func confirm(reader *bufio.Reader, question string, byDefault bool, retryText ..
 		confirmation = false
 	} else if len(retryText) > 0 {
 		fmt.Println(retryText[0])
-		confirmation = confirm(reader, question, byDefault, retryText...)
+		confirmation = confirm(messages, reader, question, byDefault, retryText...)
 	}
 	return confirmation
 }
@@ -87,10 +173,7 @@ func confirm(reader *bufio.Reader, question string, byDefault bool, retryText ..
 func directoryExists(pathToDirectory string) bool {
 	fileInfo, err := os.Lstat(pathToDirectory)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return false
-		}
-		panic(err)
+		return false
 	}
 	if fileInfo.IsDir() {
 		return true
@@ -101,10 +184,7 @@ func directoryExists(pathToDirectory string) bool {
 func fileExists(pathToFile string) bool {
 	fileInfo, err := os.Lstat(pathToFile)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return false
-		}
-		panic(err)
+		return false
 	}
 	if fileInfo.IsDir() {
 		return false
@@ -120,3 +200,12 @@ func sliceContainsString(slice []string, search string) bool {
 	}
 	return false
 }
+
+func sliceContainsInt(slice []int, search int) bool {
+	for _, sliceItem := range slice {
+		if search == sliceItem {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,11 @@
-package main
+package godev
 
 import (
 	"bufio"
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -44,29 +45,81 @@ func (s *UtilsTestSuite) TestConfigMultiflagStringString() {
 	assert.Equal(s.T(), 3, strings.Count(ccds.String(), ","))
 }
 
+func (s *UtilsTestSuite) Test_parseByteSize() {
+	t := s.T()
+	for value, expected := range map[string]int64{"512": 512, "512B": 512, "64kb": 65536, "1MB": 1048576, "1.5 KB": 1536, "2GB": 2147483648} {
+		size, err := parseByteSize(value)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, size, value)
+	}
+	_, err := parseByteSize("1TB")
+	assert.NotNil(t, err)
+	_, err = parseByteSize("MB")
+	assert.NotNil(t, err)
+}
+
+func (s *UtilsTestSuite) Test_formatByteSize() {
+	t := s.T()
+	for size, expected := range map[int64]string{512: "512 B", 1536: "1.5 KB", 1048576: "1.0 MB", 2147483648: "2.0 GB", -2048: "-2.0 KB"} {
+		assert.Equal(t, expected, formatByteSize(size))
+	}
+	assert.Equal(t, "+1.0 KB", formatByteSizeDelta(1024))
+	assert.Equal(t, "-1.0 KB", formatByteSizeDelta(-1024))
+	assert.Equal(t, "unchanged", formatByteSizeDelta(0))
+}
+
+func (s *UtilsTestSuite) Test_parseGroupDurations() {
+	t := s.T()
+	durations, err := parseGroupDurations([]string{"3=5m", "1 = 30s"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]time.Duration{1: 30 * time.Second, 3: 5 * time.Minute}, durations)
+	_, err = parseGroupDurations([]string{"5m"})
+	assert.NotNil(t, err)
+	_, err = parseGroupDurations([]string{"0=5m"})
+	assert.NotNil(t, err)
+	_, err = parseGroupDurations([]string{"1=soon"})
+	assert.NotNil(t, err)
+}
+
+func (s *UtilsTestSuite) Test_parseGroupRoutes() {
+	t := s.T()
+	routes, err := parseGroupRoutes([]string{"web/**=assets", "**/*.go = build", "**/*.go=app"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]string{"web/**": []string{"assets"}, "**/*.go": []string{"build", "app"}}, routes)
+	for _, invalid := range []string{"web/**", "web/**=", "[=assets"} {
+		_, err = parseGroupRoutes([]string{invalid})
+		assert.NotNilf(t, err, "expected '%s' to be invalid", invalid)
+	}
+}
+
 func (s *UtilsTestSuite) Test_confirm_withReply() {
-	assert.True(s.T(), confirm(bufio.NewReader(strings.NewReader("y\n")), "hi", true))
-	assert.False(s.T(), confirm(bufio.NewReader(strings.NewReader("n\n")), "hi", true))
+	assert.True(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("y\n")), "hi", true))
+	assert.False(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("n\n")), "hi", true))
 }
 
 func (s *UtilsTestSuite) Test_confirm_withWindowsReply() {
-	assert.True(s.T(), confirm(bufio.NewReader(strings.NewReader("y\r\n")), "hi", true))
-	assert.False(s.T(), confirm(bufio.NewReader(strings.NewReader("n\r\n")), "hi", true))
+	assert.True(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("y\r\n")), "hi", true))
+	assert.False(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("n\r\n")), "hi", true))
 }
 
 func (s *UtilsTestSuite) Test_confirm_withWeirdReplyNoRetry() {
-	assert.False(s.T(), confirm(bufio.NewReader(strings.NewReader("something\n")), "hi", true))
-	assert.False(s.T(), confirm(bufio.NewReader(strings.NewReader("something\n")), "hi", true))
+	assert.False(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("something\n")), "hi", true))
+	assert.False(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("something\n")), "hi", true))
 }
 
 func (s *UtilsTestSuite) Test_confirm_withWeirdReplyAndRetry() {
-	assert.True(s.T(), confirm(bufio.NewReader(strings.NewReader("something\ny\n")), "hi", true, "retry please"))
-	assert.False(s.T(), confirm(bufio.NewReader(strings.NewReader("something\nn\n")), "hi", true, "retry please"))
+	assert.True(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("something\ny\n")), "hi", true, "retry please"))
+	assert.False(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("something\nn\n")), "hi", true, "retry please"))
 }
 
 func (s *UtilsTestSuite) Test_confirm_withoutReply() {
-	assert.True(s.T(), confirm(bufio.NewReader(strings.NewReader("\n")), "hi", true))
-	assert.False(s.T(), confirm(bufio.NewReader(strings.NewReader("\n")), "hi", false))
+	assert.True(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("\n")), "hi", true))
+	assert.False(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("\n")), "hi", false))
+}
+
+func (s *UtilsTestSuite) Test_confirm_withClosedInput() {
+	assert.True(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("")), "hi", true))
+	assert.False(s.T(), confirm(nil, bufio.NewReader(strings.NewReader("something")), "hi", false, "retry please"))
 }
 
 func (s *UtilsTestSuite) Test_directoryExists() {
@@ -94,3 +147,9 @@ func (s *UtilsTestSuite) Test_sliceContainsString() {
 	// test if duplicates might affect searching
 	assert.False(s.T(), sliceContainsString(testSlice, "aa"))
 }
+
+func (s *UtilsTestSuite) Test_sliceContainsInt() {
+	assert.True(s.T(), sliceContainsInt([]int{1, 3}, 3))
+	assert.False(s.T(), sliceContainsInt([]int{1, 3}, 2))
+	assert.False(s.T(), sliceContainsInt(nil, 1))
+}
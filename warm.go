@@ -0,0 +1,140 @@
+package godev
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+// WarmCacheDirectory is the directory in the work directory which holds
+// the caches warmed in a container, its pkg directory is mounted as the
+// WarmContainerPkgDirectory like it is for the development container
+const WarmCacheDirectory = ".cache"
+
+// WarmContainerPkgDirectory is the pkg directory of GOPATH in the
+// golang images, it holds the module cache and the build cache of the
+// containers that caches are warmed in
+const WarmContainerPkgDirectory = "/go/pkg"
+
+// WarmConfig configures WarmProject
+type WarmConfig struct {
+	ContainerRuntime string
+	// Image is the image to warm the caches in, the caches of the host
+	// are warmed when it is empty
+	Image  string
+	Logger *Logger
+	Output io.Writer
+	// Policy restricts the executables which are run to warm the caches
+	Policy        *Policy
+	WorkDirectory string
+}
+
+// WarmStep is a go command which fills a cache of the project
+type WarmStep struct {
+	Description string
+	Arguments   []string
+	// Optional steps only warn when they fail since the caches are still
+	// partly warmed, eg. when a package does not compile
+	Optional bool
+}
+
+// getWarmSteps returns the steps which warm the caches of a project,
+// the module graph is only downloaded for projects with a go.mod
+func getWarmSteps(hasModule bool) []WarmStep {
+	steps := []WarmStep{}
+	if hasModule {
+		steps = append(steps, WarmStep{Description: "downloading the module graph", Arguments: []string{"mod", "download", "all"}})
+	}
+	return append(steps,
+		WarmStep{Description: "compiling packages", Arguments: []string{"build", "./..."}, Optional: true},
+		WarmStep{Description: "compiling tests", Arguments: []string{"test", "-run", "^$", "./..."}, Optional: true},
+	)
+}
+
+// WarmProject pre-downloads the module graph of the project in the
+// configured work directory and fills the build cache with its packages
+// and tests so that the first build of a new clone does not wait on the
+// network, the configured image is used to run go when it is set
+func WarmProject(config *WarmConfig) error {
+	steps := getWarmSteps(fileExists(path.Join(config.WorkDirectory, "go.mod")))
+	for _, step := range steps {
+		config.Logger.Infof("%s (go %s)...", step.Description, strings.Join(step.Arguments, " "))
+		cmd, err := config.getStepCommand(step)
+		if err != nil {
+			return err
+		}
+		startedAt := time.Now()
+		if err := cmd.Run(); err != nil {
+			if !step.Optional {
+				return fmt.Errorf("%s failed: %s", step.Description, err)
+			}
+			config.Logger.Warnf("%s failed, the caches are only partly warmed: %s", step.Description, err)
+			continue
+		}
+		config.Logger.Infof("%s took %s", step.Description, time.Since(startedAt).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// getStepCommand returns the command which runs :step on the host or in
+// a container of the configured image
+func (config *WarmConfig) getStepCommand(step WarmStep) (*exec.Cmd, error) {
+	if len(config.Image) == 0 {
+		cmd, err := config.Policy.Command(config.WorkDirectory, "go", step.Arguments...)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Stdout = config.Output
+		cmd.Stderr = config.Output
+		return cmd, nil
+	}
+	if err := validateContainerImage(config.Image); err != nil {
+		return nil, err
+	}
+	containerRuntime, err := findContainerRuntime(config.ContainerRuntime)
+	if err != nil {
+		return nil, err
+	}
+	pkgDirectory := path.Join(config.WorkDirectory, WarmCacheDirectory, "pkg")
+	if err := os.MkdirAll(pkgDirectory, 0755); err != nil {
+		return nil, err
+	}
+	environment := config.getContainerEnvironment()
+	container := &ContainerCommand{
+		Image:              config.Image,
+		Application:        "go",
+		Arguments:          step.Arguments,
+		Directory:          config.WorkDirectory,
+		MountDirectory:     config.WorkDirectory,
+		EnvironmentKeys:    getEnvironmentKeys(environment),
+		OverrideEntrypoint: true,
+		Volumes:            []string{pkgDirectory + ":" + WarmContainerPkgDirectory},
+	}
+	cmd, err := config.Policy.Command(config.WorkDirectory, containerRuntime, container.GetArguments()...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = append(os.Environ(), environment...)
+	cmd.Stdout = config.Output
+	cmd.Stderr = config.Output
+	return cmd, nil
+}
+
+// getContainerEnvironment returns the variables which put the build
+// cache of the container next to its module cache in the mounted
+// WarmContainerPkgDirectory, and which pass on the go environment of the
+// host so that the same module proxy is used
+func (config *WarmConfig) getContainerEnvironment() []string {
+	environment := []string{"GOCACHE=" + path.Join(WarmContainerPkgDirectory, "go-build")}
+	goEnvironment := GetGoEnvironment(config.WorkDirectory, nil)
+	for _, key := range GoEnvironmentKeys {
+		if len(goEnvironment[key]) > 0 {
+			environment = append(environment, key+"="+goEnvironment[key])
+		}
+	}
+	return environment
+}
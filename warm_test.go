@@ -0,0 +1,102 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WarmTestSuite struct {
+	suite.Suite
+	directory string
+	logs      bytes.Buffer
+	logger    *Logger
+}
+
+func TestWarm(t *testing.T) {
+	suite.Run(t, new(WarmTestSuite))
+}
+
+func (s *WarmTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-warm")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.logs.Reset()
+	s.logger = InitLogger(&LoggerConfig{Name: "warm", Format: "raw", Level: "trace"})
+	s.logger.SetOutput(&s.logs)
+}
+
+func (s *WarmTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *WarmTestSuite) TestWarmProject() {
+	t := s.T()
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "go.mod"), []byte("module example.com/warm\n\ngo 1.11\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	var output bytes.Buffer
+	assert.Nil(t, WarmProject(&WarmConfig{Logger: s.logger, Output: &output, WorkDirectory: s.directory}))
+	assert.Contains(t, s.logs.String(), "downloading the module graph (go mod download all)...")
+	assert.Contains(t, s.logs.String(), "downloading the module graph took")
+	assert.Contains(t, s.logs.String(), "compiling packages took")
+	assert.Contains(t, s.logs.String(), "compiling tests took")
+}
+
+func (s *WarmTestSuite) TestWarmProject_failedSteps() {
+	t := s.T()
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "main.go"), []byte("package main\n\nfunc main() {\n"), 0644))
+	var output bytes.Buffer
+	assert.Nil(t, WarmProject(&WarmConfig{Logger: s.logger, Output: &output, WorkDirectory: s.directory}), "expected compile errors to only be warned about")
+	assert.NotContains(t, s.logs.String(), "downloading the module graph", "expected modules not to be downloaded without a go.mod")
+	assert.Contains(t, s.logs.String(), "compiling packages failed, the caches are only partly warmed")
+
+	err := WarmProject(&WarmConfig{ContainerRuntime: "false", Image: "golang:1.21", Logger: s.logger, Output: &output, WorkDirectory: s.directory})
+	assert.Nil(t, err, "expected the optional steps in the container to only be warned about")
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "go.mod"), []byte("module example.com/warm\n"), 0644))
+	err = WarmProject(&WarmConfig{ContainerRuntime: "false", Image: "golang:1.21", Logger: s.logger, Output: &output, WorkDirectory: s.directory})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "downloading the module graph failed")
+}
+
+func (s *WarmTestSuite) Test_getStepCommand() {
+	t := s.T()
+	step := getWarmSteps(true)[0]
+	config := &WarmConfig{WorkDirectory: s.directory}
+	cmd, err := config.getStepCommand(step)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go", "mod", "download", "all"}, cmd.Args)
+	assert.Equal(t, s.directory, cmd.Dir)
+
+	config.ContainerRuntime = "podman"
+	config.Image = "app:development"
+	cmd, err = config.getStepCommand(step)
+	assert.Nil(t, err)
+	assert.Equal(t, "podman", cmd.Args[0])
+	assert.Equal(t, []string{"--entrypoint", "go", "app:development", "mod", "download", "all"}, cmd.Args[len(cmd.Args)-6:])
+	assert.Contains(t, cmd.Args, path.Join(s.directory, WarmCacheDirectory, "pkg")+":/go/pkg")
+	assert.Contains(t, cmd.Args, "GOCACHE")
+	assert.Contains(t, cmd.Env, "GOCACHE=/go/pkg/go-build")
+	assert.True(t, directoryExists(path.Join(s.directory, WarmCacheDirectory, "pkg")), "expected the cache directory to be created before the container")
+
+	config.Image = "--privileged"
+	_, err = config.getStepCommand(step)
+	assert.NotNil(t, err)
+
+	config.Image = ""
+	config.Policy = &Policy{Allow: []string{"make"}, Path: "policy.yaml"}
+	_, err = config.getStepCommand(step)
+	assert.NotNil(t, err, "expected the policy to be checked before go is run")
+	assert.Contains(t, err.Error(), "'go' is not allowed by the policy at 'policy.yaml'")
+}
+
+func (s *WarmTestSuite) Test_getWarmSteps() {
+	t := s.T()
+	assert.Len(t, getWarmSteps(true), 3)
+	assert.False(t, getWarmSteps(true)[0].Optional, "expected failed downloads to fail the warm")
+	assert.Len(t, getWarmSteps(false), 2)
+}
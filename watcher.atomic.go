@@ -0,0 +1,70 @@
+package godev
+
+import (
+	"path"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatcherAtomicSaveWindow is how long after a file is renamed or removed
+// the creation of a file in the same directory is taken as the end of an
+// atomic save, editors rename their temporary files within milliseconds
+const WatcherAtomicSaveWindow = 500 * time.Millisecond
+
+// movedFile is a file which was recently renamed or removed
+type movedFile struct {
+	at time.Time
+	// temporary is set for renamed files whose changes are not handled,
+	// such as the temporary files that editors write before renaming
+	// them over the saved file
+	temporary bool
+}
+
+// trackMovedFile remembers that :filePath was renamed or removed so that
+// the creation of the file it was saved over can be recognised
+func (fw *Watcher) trackMovedFile(filePath string, temporary bool) {
+	if fw.movedFiles == nil {
+		fw.movedFiles = map[string]movedFile{}
+	}
+	now := time.Now()
+	for movedPath, moved := range fw.movedFiles {
+		if now.Sub(moved.at) > WatcherAtomicSaveWindow {
+			delete(fw.movedFiles, movedPath)
+		}
+	}
+	fw.movedFiles[filePath] = movedFile{at: now, temporary: temporary}
+}
+
+// isAtomicSave checks whether :event creates a file which replaced
+// itself or a temporary file in the same directory that was just renamed,
+// as editors do when they write a temporary file and rename it over the
+// saved one (eg. vim, VS Code and JetBrains IDEs)
+func (fw *Watcher) isAtomicSave(event *WatcherEvent) bool {
+	if event.Op&fsnotify.Create == 0 || len(fw.movedFiles) == 0 || !fw.pathExists(event.FilePath()) || fw.pathIsDirectory(event.FilePath()) {
+		return false
+	}
+	now := time.Now()
+	for movedPath, moved := range fw.movedFiles {
+		if now.Sub(moved.at) > WatcherAtomicSaveWindow {
+			continue
+		}
+		if movedPath == event.FilePath() || (moved.temporary && path.Dir(movedPath) == path.Dir(event.FilePath()) && !fw.pathExists(movedPath)) {
+			delete(fw.movedFiles, movedPath)
+			return true
+		}
+	}
+	return false
+}
+
+// dropQueuedMoves removes the queued events which renamed or removed
+// :filePath since they were part of its atomic save
+func (fw *Watcher) dropQueuedMoves(filePath string) {
+	events := fw.events[:0]
+	for _, event := range fw.events {
+		if event.FilePath() != filePath || event.Op&(fsnotify.Remove|fsnotify.Rename) == 0 {
+			events = append(events, event)
+		}
+	}
+	fw.events = events
+}
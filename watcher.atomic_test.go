@@ -0,0 +1,113 @@
+package godev
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatcherAtomicTestSuite struct {
+	suite.Suite
+	directory string
+	filePath  string
+}
+
+func TestWatcherAtomic(t *testing.T) {
+	suite.Run(t, new(WatcherAtomicTestSuite))
+}
+
+func (s *WatcherAtomicTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-watcher-atomic")
+	assert.Nil(s.T(), err)
+	s.directory = directory
+	s.filePath = path.Join(directory, "main.go")
+	assert.Nil(s.T(), ioutil.WriteFile(s.filePath, []byte("package main\n"), 0644))
+}
+
+func (s *WatcherAtomicTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *WatcherAtomicTestSuite) initWatcher(watchEvents ...string) *Watcher {
+	w := initTestWatcher(s.T(), &WatcherConfig{FileExtensions: []string{"go"}, WatchEvents: watchEvents, WatchDirectory: s.directory})
+	w.logger.SetOutput(&bytes.Buffer{})
+	return w
+}
+
+func (s *WatcherAtomicTestSuite) Test_handleEvent_withRenamedBackup() {
+	t := s.T()
+	for _, watchEvents := range [][]string{nil, {"write"}} {
+		w := s.initWatcher(watchEvents...)
+		backupPath := s.filePath + "~"
+		w.handleEvent(WatcherEvent{Name: s.filePath, Op: fsnotify.Rename})
+		w.handleEvent(WatcherEvent{Name: backupPath, Op: fsnotify.Create})
+		assert.True(t, w.handleEvent(WatcherEvent{Name: s.filePath, Op: fsnotify.Create}), "expected the recreated file to be handled with %v", watchEvents)
+		w.handleEvent(WatcherEvent{Name: s.filePath, Op: fsnotify.Write})
+		w.handleEvent(WatcherEvent{Name: backupPath, Op: fsnotify.Remove})
+		assert.Equal(t, []WatcherEvent{{Name: s.filePath, Op: fsnotify.Write}}, w.getDedupedEvents(), "expected a single write with %v", watchEvents)
+		w.Close()
+	}
+}
+
+func (s *WatcherAtomicTestSuite) Test_handleEvent_withRenamedTemporaryFile() {
+	t := s.T()
+	w := s.initWatcher("write")
+	defer w.Close()
+	temporaryPath := path.Join(s.directory, ".main.go.tmp.1234")
+	w.handleEvent(WatcherEvent{Name: temporaryPath, Op: fsnotify.Create})
+	w.handleEvent(WatcherEvent{Name: temporaryPath, Op: fsnotify.Write})
+	w.handleEvent(WatcherEvent{Name: temporaryPath, Op: fsnotify.Rename})
+	assert.True(t, w.handleEvent(WatcherEvent{Name: s.filePath, Op: fsnotify.Create}), "expected the file renamed over to be handled as written")
+	assert.Equal(t, []WatcherEvent{{Name: s.filePath, Op: fsnotify.Write}}, w.events)
+}
+
+func (s *WatcherAtomicTestSuite) Test_handleEvent_withoutAtomicSaves() {
+	t := s.T()
+	w := s.initWatcher()
+	defer w.Close()
+	renamedPath := path.Join(s.directory, "old.go")
+	w.handleEvent(WatcherEvent{Name: renamedPath, Op: fsnotify.Rename})
+	w.handleEvent(WatcherEvent{Name: s.filePath, Op: fsnotify.Create})
+	assert.Equal(t, []WatcherEvent{
+		{Name: renamedPath, Op: fsnotify.Rename},
+		{Name: s.filePath, Op: fsnotify.Create},
+	}, w.events, "expected renames of handled files to stay renames")
+
+	w.events = nil
+	w.movedFiles[s.filePath] = movedFile{at: time.Now().Add(-2 * WatcherAtomicSaveWindow)}
+	w.handleEvent(WatcherEvent{Name: s.filePath, Op: fsnotify.Create})
+	assert.Equal(t, []WatcherEvent{{Name: s.filePath, Op: fsnotify.Create}}, w.events, "expected files created after the window to stay created")
+}
+
+func (s *WatcherAtomicTestSuite) TestBeginWatch_withAtomicSave() {
+	t := s.T()
+	w := s.initWatcher()
+	defer w.Close()
+	assert.Nil(s.T(), w.RecursivelyWatch(s.directory))
+	var handled []WatcherEvent
+	var handledMutex sync.Mutex
+	var waitGroup sync.WaitGroup
+	w.BeginWatch(context.Background(), &waitGroup, func(events *[]WatcherEvent) bool {
+		handledMutex.Lock()
+		defer handledMutex.Unlock()
+		handled = append(handled, *events...)
+		return true
+	})
+	temporaryPath := path.Join(s.directory, ".main.go.swp")
+	assert.Nil(t, ioutil.WriteFile(temporaryPath, []byte("package main\n\nfunc main() {}\n"), 0644))
+	assert.Nil(t, os.Rename(temporaryPath, s.filePath))
+	time.Sleep(2500 * time.Millisecond)
+	w.EndWatch()
+	handledMutex.Lock()
+	defer handledMutex.Unlock()
+	assert.Equal(t, []WatcherEvent{{Name: s.filePath, Op: fsnotify.Write}}, handled)
+}
@@ -1,9 +1,12 @@
-package main
+package godev
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/fsnotify/fsnotify"
@@ -42,6 +45,36 @@ var watcherEventType = []string{
 	WatcherEventPermission,
 }
 
+// WatcherOperationNames are the names of the fsnotify operations in the
+// order of their bits, as accepted by --watch-events
+var WatcherOperationNames = []string{"create", "write", "remove", "rename", "chmod"}
+
+// ParseWatcherOperations returns the fsnotify operations named by
+// :names, all operations are selected when :names is empty
+func ParseWatcherOperations(names []string) (fsnotify.Op, error) {
+	var operations fsnotify.Op
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		}
+		found := false
+		for index, operationName := range WatcherOperationNames {
+			if name == operationName {
+				operations |= fsnotify.Op(1 << uint(index))
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("'%s' is not a file system event (expected one of %v)", name, WatcherOperationNames)
+		}
+	}
+	if operations == 0 {
+		return fsnotify.Create | fsnotify.Write | fsnotify.Remove | fsnotify.Rename | fsnotify.Chmod, nil
+	}
+	return operations, nil
+}
+
 // WatcherEvent provides some function candy for working with
 // fsnotify more easily
 type WatcherEvent fsnotify.Event
@@ -58,6 +91,23 @@ func (e *WatcherEvent) EventType() string {
 	return eventType
 }
 
+// Operations returns the names of the operations recorded in the event
+// (eg. [write chmod]) in the order of WatcherOperationNames
+func (e *WatcherEvent) Operations() []string {
+	var operations []string
+	for index, name := range WatcherOperationNames {
+		if e.Op&fsnotify.Op(1<<uint(index)) != 0 {
+			operations = append(operations, name)
+		}
+	}
+	return operations
+}
+
+// HasOperation checks whether the event records any of :operations
+func (e *WatcherEvent) HasOperation(operations fsnotify.Op) bool {
+	return e.Op&operations != 0
+}
+
 // FilePath returns the absolute path of the file/dir
 func (e *WatcherEvent) FilePath() string {
 	return e.Name
@@ -90,6 +140,33 @@ func (e *WatcherEvent) FileType() string {
 	return fileType
 }
 
+// watcherBinarySniffLength is the number of bytes read from the start
+// of a file to determine if it is a binary file
+const watcherBinarySniffLength = 8000
+
+// FileSize returns the size of the file in bytes or -1 if it could
+// not be determined (eg. the file was deleted)
+func (e *WatcherEvent) FileSize() int64 {
+	fileInfo, err := os.Lstat(e.Name)
+	if err != nil || fileInfo.IsDir() {
+		return -1
+	}
+	return fileInfo.Size()
+}
+
+// IsBinary checks if the file looks like a binary file by searching
+// for NUL bytes at the start of the file like git does
+func (e *WatcherEvent) IsBinary() bool {
+	file, err := os.Open(e.Name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	sniffed := make([]byte, watcherBinarySniffLength)
+	bytesRead, _ := file.Read(sniffed)
+	return bytes.IndexByte(sniffed[:bytesRead], 0) >= 0
+}
+
 // IsAnyOf verifies that the file extension matches :theseTypes
 func (e *WatcherEvent) IsAnyOf(theseTypes []string) bool {
 	for _, fileExtension := range theseTypes {
@@ -108,3 +185,44 @@ func (e *WatcherEvent) String() string {
 		e.FilePath(),
 	)
 }
+
+// SummariseWatcherEvents returns a concise description of the files changed
+// in :events grouped by file type and directory relative to :baseDirectory,
+// eg. "4 files changed: 3 .go in pkg/api, 1 .proto in api"
+func SummariseWatcherEvents(events []WatcherEvent, baseDirectory string) string {
+	changedFiles := map[string]bool{}
+	groupCounts := map[string]int{}
+	for _, event := range events {
+		if changedFiles[event.FilePath()] {
+			continue
+		}
+		changedFiles[event.FilePath()] = true
+		fileType := path.Ext(event.FilePath())
+		if len(fileType) == 0 {
+			fileType = event.FileName()
+		}
+		directory := path.Dir(event.FilePath())
+		if relativeDirectory, err := filepath.Rel(baseDirectory, directory); err == nil {
+			directory = filepath.ToSlash(relativeDirectory)
+		}
+		groupCounts[fmt.Sprintf("%s in %s", fileType, directory)]++
+	}
+	var groups []string
+	for group := range groupCounts {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groupCounts[groups[i]] != groupCounts[groups[j]] {
+			return groupCounts[groups[i]] > groupCounts[groups[j]]
+		}
+		return groups[i] < groups[j]
+	})
+	for index, group := range groups {
+		groups[index] = fmt.Sprintf("%v %s", groupCounts[group], group)
+	}
+	noun := "files"
+	if len(changedFiles) == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("%v %s changed: %s", len(changedFiles), noun, strings.Join(groups, ", "))
+}
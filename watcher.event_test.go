@@ -1,4 +1,4 @@
-package main
+package godev
 
 import (
 	"testing"
@@ -61,6 +61,27 @@ func (s *WatcherEventTestSuite) TestEventType_Chmod() {
 	assert.Equal(s.T(), "%", e.EventType())
 }
 
+func (s *WatcherEventTestSuite) TestOperations() {
+	t := s.T()
+	e := WatcherEvent(fsnotify.Event{Op: fsnotify.Write | fsnotify.Chmod})
+	assert.Equal(t, []string{"write", "chmod"}, e.Operations())
+	assert.True(t, e.HasOperation(fsnotify.Chmod))
+	assert.True(t, e.HasOperation(fsnotify.Create|fsnotify.Write))
+	assert.False(t, e.HasOperation(fsnotify.Remove|fsnotify.Rename))
+}
+
+func (s *WatcherEventTestSuite) TestParseWatcherOperations() {
+	t := s.T()
+	operations, err := ParseWatcherOperations([]string{"create", " Write "})
+	assert.Nil(t, err)
+	assert.Equal(t, fsnotify.Create|fsnotify.Write, operations)
+	operations, err = ParseWatcherOperations([]string{""})
+	assert.Nil(t, err)
+	assert.Equal(t, fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename|fsnotify.Chmod, operations, "expected all operations when none are specified")
+	_, err = ParseWatcherOperations([]string{"write", "modify"})
+	assert.NotNil(t, err)
+}
+
 func (s *WatcherEventTestSuite) TestFilePath() {
 	e := WatcherEvent(fsnotify.Event{
 		Op:   fsnotify.Chmod,
@@ -93,3 +114,25 @@ func (s *WatcherEventTestSuite) TestFileType() {
 	})
 	assert.Equal(s.T(), s.fileExtension, e.FileType())
 }
+
+func (s *WatcherEventTestSuite) TestSummariseWatcherEvents() {
+	t := s.T()
+	events := []WatcherEvent{
+		WatcherEvent{Op: fsnotify.Write, Name: "/project/pkg/api/a.go"},
+		WatcherEvent{Op: fsnotify.Write, Name: "/project/pkg/api/b.go"},
+		WatcherEvent{Op: fsnotify.Chmod, Name: "/project/pkg/api/b.go"},
+		WatcherEvent{Op: fsnotify.Remove, Name: "/project/pkg/api/c.go"},
+		WatcherEvent{Op: fsnotify.Create, Name: "/project/api/service.proto"},
+		WatcherEvent{Op: fsnotify.Write, Name: "/project/Makefile"},
+	}
+	assert.Equal(
+		t,
+		"5 files changed: 3 .go in pkg/api, 1 .proto in api, 1 Makefile in .",
+		SummariseWatcherEvents(events, "/project"),
+	)
+	assert.Equal(
+		t,
+		"1 file changed: 1 .go in pkg/api",
+		SummariseWatcherEvents(events[:1], "/project"),
+	)
+}
@@ -1,11 +1,14 @@
-package main
+package godev
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	_ "log"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,54 +17,220 @@ import (
 
 // WatcherConfig is for configuring Watcher
 type WatcherConfig struct {
-	FileExtensions []string
-	IgnoredNames   []string
-	RefreshRate    time.Duration
-	LogLevel       LogLevel
+	FileExtensions    []string
+	IgnoredNames      []string
+	IgnoreBinaryFiles bool
+	MaxFileSize       int64
+	RefreshRate       time.Duration
+	LogLevel          LogLevel
+	// TriggerFiles are absolute paths of files whose changes are
+	// handled regardless of their extension
+	TriggerFiles []string
+	// IncludePatterns are patterns of paths relative to WatchDirectory
+	// whose changes are handled regardless of their extension
+	IncludePatterns []string
+	// ExcludePatterns are patterns of paths relative to WatchDirectory
+	// whose changes are never handled, matching directories are not watched
+	ExcludePatterns []string
+	// UseGitignore skips paths ignored by the .gitignore files in
+	// WatchDirectory as if they matched ExcludePatterns
+	UseGitignore bool
+	// FollowSymlinks watches symlinked directories as if they were
+	// directories, each real directory is only watched once
+	FollowSymlinks bool
+	// PollInterval switches to listing the watched directories at this
+	// interval instead of relying on the operating system when non-zero
+	PollInterval time.Duration
+	// PollFallback polls the directories which cannot be watched because
+	// the limit of inotify watches is reached at this interval when
+	// non-zero, they are left unwatched otherwise
+	PollFallback time.Duration
+	// RecordFile is where the events received from the file system are
+	// written as WatcherRecords when it is set
+	RecordFile string
+	// ReplayFile replaces the events of the file system with those
+	// recorded in it when it is set
+	ReplayFile string
+	// WatchEvents are the names of the operations (see
+	// WatcherOperationNames) whose events are handled, all when empty
+	WatchEvents    []string
+	WatchDirectory string
+	// Events receives EventWatcherPaused and EventWatcherResumed
+	Events *EventBus
+	// RunState tags the log lines of the watcher with the pipeline
+	RunState *RunState
 }
 
-// InitWatcher returns a workable Watcher instance
-func InitWatcher(config *WatcherConfig) *Watcher {
-	watcher, err := fsnotify.NewWatcher()
+// WatcherTouchQueueSize is the number of paths passed to Touch which can
+// wait for the watch routine
+const WatcherTouchQueueSize = 64
+
+// InitWatcher returns a workable Watcher instance or an error if the
+// events of :config cannot be watched, recorded or replayed
+func InitWatcher(config *WatcherConfig) (*Watcher, error) {
+	logger := InitLogger(&LoggerConfig{Name: "watcher", Format: "production", Level: config.LogLevel, RunState: config.RunState})
+	operations, err := ParseWatcherOperations(config.WatchEvents)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	var backend watcherBackend
+	if len(config.ReplayFile) > 0 {
+		replay, err := initReplayBackend(config.ReplayFile, config.WatchDirectory, logger)
+		if err != nil {
+			return nil, err
+		}
+		backend = replay
+	} else if config.PollInterval > 0 {
+		backend = initPollingBackend(config.PollInterval)
+	} else {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return nil, fmt.Errorf("unable to watch the file system: %s", err)
+		}
+		backend = &fsnotifyBackend{watcher: watcher}
 	}
 	fw := &Watcher{
-		config:  config,
-		logger:  InitLogger(&LoggerConfig{Name: "watcher", Format: "production", Level: config.LogLevel}),
-		watcher: watcher,
+		config:       config,
+		logger:       logger,
+		operations:   operations,
+		watcher:      backend,
+		watchedPaths: map[string]bool{},
+		realPaths:    map[string]string{},
+		polledPaths:  map[string]bool{},
+		touched:      make(chan fsnotify.Event, WatcherTouchQueueSize),
+	}
+	if len(config.RecordFile) > 0 {
+		if fw.recorder, err = initWatcherRecorder(config.RecordFile, config.WatchDirectory); err != nil {
+			backend.Close()
+			return nil, err
+		}
+		fw.logger.Infof("recording file system events in '%s'", config.RecordFile)
+	}
+	if len(config.ReplayFile) > 0 {
+		fw.logger.Debugf("replaying the file system events recorded in '%s'", config.ReplayFile)
+	} else if config.PollInterval > 0 {
+		fw.logger.Debugf("polling for changes every %v", config.PollInterval)
+	} else if config.PollFallback > 0 {
+		fw.fallback = initPollingBackend(config.PollFallback)
+	}
+	if config.UseGitignore {
+		fw.gitignoreRules = loadGitignoreRules(config.WatchDirectory)
+		fw.logger.Debugf("loaded %v rule(s) from .gitignore files", len(fw.gitignoreRules))
+	}
+	if len(config.WatchDirectory) > 0 {
+		fw.loadGodevignore()
 	}
-	return fw
+	return fw, nil
 }
 
 // Watcher is a component for handling file system changes
 type Watcher struct {
 	config         *WatcherConfig
 	logger         *Logger
-	watcher        *fsnotify.Watcher
+	watcher        watcherBackend
 	events         []WatcherEvent
 	watchMutex     chan bool
 	intervalTicker <-chan time.Time
+	watchedPaths   map[string]bool
+	pathsMutex     sync.Mutex
+	// realPaths are the resolved paths of the watched directories when
+	// following symlinks and the paths that they are watched through
+	realPaths      map[string]string
+	operations     fsnotify.Op
+	gitignoreRules ignoreRules
+	// godevignoreRules are loaded from the GodevignoreFileName file in
+	// the watch directory and are replaced whenever it changes
+	godevignoreRules ignoreRules
+	ignoreMutex      sync.RWMutex
+	paused           bool
+	pausedMutex      sync.Mutex
+	// fallback polls the directories which could not be watched once
+	// the watch limit was reached when PollFallback is set
+	fallback          *pollingBackend
+	polledPaths       map[string]bool
+	watchLimitReached bool
+	recorder          *watcherRecorder
+	// touched receives the synthetic events of Touch
+	touched chan fsnotify.Event
+	// movedFiles are the recently renamed and removed files which an
+	// atomic save could have replaced
+	movedFiles map[string]movedFile
 }
 
-// Close closes the watcher, use for graceful shutdowns
+// GetWatchedPathCount returns the number of directories being watched
+func (fw *Watcher) GetWatchedPathCount() int {
+	fw.pathsMutex.Lock()
+	defer fw.pathsMutex.Unlock()
+	return len(fw.watchedPaths)
+}
+
+// Close closes the watcher, use for graceful shutdowns - it does nothing
+// when the watcher was not initialised
 func (fw *Watcher) Close() {
 	if fw.watcher == nil {
-		panic("watcher was not initialised")
+		return
 	}
 	fw.watcher.Close()
+	if fw.fallback != nil {
+		fw.fallback.Close()
+	}
+	if fw.recorder != nil {
+		fw.recorder.Close()
+	}
+}
+
+// GetPolledPathCount returns the number of directories being polled
+// because the watch limit was reached
+func (fw *Watcher) GetPolledPathCount() int {
+	fw.pathsMutex.Lock()
+	defer fw.pathsMutex.Unlock()
+	return len(fw.polledPaths)
+}
+
+// IsPaused checks whether changes are being dropped instead of being
+// passed to the handler
+func (fw *Watcher) IsPaused() bool {
+	fw.pausedMutex.Lock()
+	defer fw.pausedMutex.Unlock()
+	return fw.paused
+}
+
+// Pause drops changes until Resume is called, new directories are still
+// watched so that nothing is missed after - :reason is published with
+// EventWatcherPaused
+func (fw *Watcher) Pause(reason string) {
+	fw.setPaused(true, reason, EventWatcherPaused)
+}
+
+// Resume passes changes to the handler again after Pause
+func (fw *Watcher) Resume(reason string) {
+	fw.setPaused(false, reason, EventWatcherResumed)
+}
+
+func (fw *Watcher) setPaused(paused bool, reason, eventName string) {
+	fw.pausedMutex.Lock()
+	changed := fw.paused != paused
+	fw.paused = paused
+	fw.pausedMutex.Unlock()
+	if !changed {
+		return
+	}
+	fw.logger.Infof("%s (%s)", strings.Replace(eventName, "-", " ", 1), reason)
+	fw.config.Events.Publish(&Event{Name: eventName, Reason: reason})
 }
 
 // WatcherEventHandler defines the callback for BeginWatch() to use
 type WatcherEventHandler func(*[]WatcherEvent) bool
 
-// BeginWatch starts the file system watching in blocking mode
-func (fw *Watcher) BeginWatch(waitGroup *sync.WaitGroup, handler WatcherEventHandler) {
+// BeginWatch starts the file system watching in blocking mode until
+// EndWatch is called or :ctx is done
+func (fw *Watcher) BeginWatch(ctx context.Context, waitGroup *sync.WaitGroup, handler WatcherEventHandler) {
 	fw.logger.Trace("initialising file system watch")
 	fw.watchMutex = make(chan bool)
 	fw.intervalTicker = time.After(fw.config.RefreshRate)
 	waitGroup.Add(1)
 	go fw.watchRoutine(
+		ctx,
 		fw.intervalTicker,
 		fw.watchMutex,
 		handler,
@@ -75,7 +244,7 @@ func (fw *Watcher) EndWatch() {
 	fw.watchMutex <- true
 }
 
-func (fw *Watcher) watchRoutine(tick <-chan time.Time, stop chan bool, handler WatcherEventHandler, onDone func()) {
+func (fw *Watcher) watchRoutine(ctx context.Context, tick <-chan time.Time, stop chan bool, handler WatcherEventHandler, onDone func()) {
 	for {
 		select {
 		case <-tick:
@@ -86,50 +255,311 @@ func (fw *Watcher) watchRoutine(tick <-chan time.Time, stop chan bool, handler W
 				fw.logger.Tracef("processed %v event(s)", len(dedupedEvents))
 				fw.events = make([]WatcherEvent, 0)
 			}
-		case event := <-fw.watcher.Events:
-			eventToAdd := WatcherEvent(event)
-			if eventToAdd.IsAnyOf(fw.config.FileExtensions) {
-				fw.events = append(fw.events, eventToAdd)
+		case event := <-fw.watcher.Events():
+			fw.recordEvent(event)
+			if fw.handleEvent(WatcherEvent(event)) {
+				tick = time.After(2 * time.Second)
+			}
+		case event := <-fw.touched:
+			if fw.handleEvent(WatcherEvent(event)) {
+				tick = time.After(2 * time.Second)
+			}
+		case event := <-fw.getFallbackEvents():
+			fw.recordEvent(event)
+			if fw.handleEvent(WatcherEvent(event)) {
 				tick = time.After(2 * time.Second)
-			} else if eventToAdd.FileType() == WatcherFileTypeDir {
-				fw.Watch(eventToAdd.FilePath())
 			}
 		case shouldWeStop := <-stop:
 			fw.logger.Tracef("received signal to terminate watch routine: %v", shouldWeStop)
 			fw.watchMutex = make(chan bool)
 			onDone()
 			if shouldWeStop {
-				break
+				return
 			}
+		case <-ctx.Done():
+			fw.logger.Tracef("terminating watch routine: %s", ctx.Err())
+			onDone()
+			return
 		default:
 		}
 	}
 }
 
-// RecursivelyWatch is so we can watch all sub directories of a directory
-func (fw *Watcher) RecursivelyWatch(directoryPath string) {
-	fw.assertDirectoryIntegrity(directoryPath)
-	allSubDirectories := fw.recursivelyGetDirectories(directoryPath)
+// Touch handles a write event for each of :filePaths as if they had
+// changed without modifying them, so they go through the same filters
+// as real changes
+func (fw *Watcher) Touch(filePaths []string) error {
+	if len(filePaths) > cap(fw.touched)-len(fw.touched) {
+		return fmt.Errorf("%v path(s) cannot be touched while %v other(s) are waiting", len(filePaths), len(fw.touched))
+	}
+	for _, filePath := range filePaths {
+		fw.logger.Debugf("touched '%s'", filePath)
+		fw.touched <- fsnotify.Event{Name: filePath, Op: fsnotify.Write}
+	}
+	return nil
+}
+
+// recordEvent writes :event for --record before it is filtered
+func (fw *Watcher) recordEvent(event fsnotify.Event) {
+	if fw.recorder == nil {
+		return
+	}
+	if err := fw.recorder.Record(event); err != nil {
+		fw.logger.Warnf("unable to record the event for '%s': %s", event.Name, err)
+	}
+}
+
+// handleEvent queues :event for the handler if it is for a watched file
+// and one of the selected operations and returns whether it was queued,
+// new directories are watched instead
+func (fw *Watcher) handleEvent(event WatcherEvent) bool {
+	handled := event.IsAnyOf(fw.config.FileExtensions) || fw.isTriggerFile(&event) || fw.isIncludedPath(event.FilePath())
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		if fw.isWatched(event.FilePath()) {
+			fw.unwatchDirectory(event.FilePath())
+		} else {
+			fw.trackMovedFile(event.FilePath(), event.Op&fsnotify.Rename != 0 && !handled)
+		}
+	}
+	if fw.isAtomicSave(&event) {
+		fw.logger.Tracef("handling the creation of '%s' as a write (atomic save)", event.FilePath())
+		event.Op = event.Op&^fsnotify.Create | fsnotify.Write
+		fw.dropQueuedMoves(event.FilePath())
+	}
+	if fw.isGodevignore(event.FilePath()) {
+		fw.reloadGodevignore()
+	} else if handled && !fw.isIgnoredFile(&event) {
+		if fw.IsPaused() {
+			fw.logger.Tracef("skipped event for '%s' while paused", event.FilePath())
+			return false
+		} else if !event.HasOperation(fw.operations) {
+			fw.logger.Tracef("skipped %v event for '%s'", event.Operations(), event.FilePath())
+			return false
+		}
+		fw.events = append(fw.events, event)
+		return true
+	} else if event.FileType() == WatcherFileTypeDir && !fw.isExcludedPath(event.FilePath()) {
+		return fw.watchNewDirectory(event.FilePath())
+	} else if fw.isSymlinkedDirectory(event.FilePath()) && !fw.isExcludedPath(event.FilePath()) {
+		fw.watchSymlinkedDirectory(event.FilePath())
+	}
+	return false
+}
+
+// watchNewDirectory watches :directoryPath created while watching and
+// the directories within it - the files already in them are handled as
+// created because their events were emitted before they were watched
+func (fw *Watcher) watchNewDirectory(directoryPath string) (queued bool) {
+	if fw.isWatched(directoryPath) || !fw.pathIsDirectory(directoryPath) {
+		return false
+	}
+	subDirectories, err := fw.recursivelyGetDirectories(directoryPath)
+	if err != nil {
+		fw.logger.Debugf("stopped watching '%s' as it changed while being watched: %s", directoryPath, err)
+		return false
+	}
+	directories := append([]string{directoryPath}, subDirectories...)
+	for _, directory := range directories {
+		if fw.isWatched(directory) {
+			continue
+		}
+		fw.Watch(directory)
+		listings, err := ioutil.ReadDir(directory)
+		if err != nil {
+			continue
+		}
+		for _, listing := range listings {
+			if listing.IsDir() {
+				continue
+			}
+			created := WatcherEvent{Name: path.Join(directory, listing.Name()), Op: fsnotify.Create}
+			if fw.handleEvent(created) {
+				queued = true
+			}
+		}
+	}
+	if len(directories) > 1 {
+		fw.logger.Debugf("watching the %v new directories in '%s'", len(directories), directoryPath)
+	}
+	return queued
+}
+
+// unwatchDirectory stops watching :directoryPath and the directories
+// within it after it was removed or renamed
+func (fw *Watcher) unwatchDirectory(directoryPath string) {
+	fw.pathsMutex.Lock()
+	var unwatched []string
+	for watchedPath := range fw.watchedPaths {
+		if watchedPath == directoryPath || strings.HasPrefix(watchedPath, directoryPath+"/") {
+			fw.watcher.Remove(watchedPath)
+			if fw.polledPaths[watchedPath] {
+				fw.fallback.Remove(watchedPath)
+				delete(fw.polledPaths, watchedPath)
+			}
+			delete(fw.watchedPaths, watchedPath)
+			unwatched = append(unwatched, watchedPath)
+		}
+	}
+	for realPath, watchedPath := range fw.realPaths {
+		if watchedPath == directoryPath || strings.HasPrefix(watchedPath, directoryPath+"/") {
+			delete(fw.realPaths, realPath)
+		}
+	}
+	fw.pathsMutex.Unlock()
+	for _, watchedPath := range unwatched {
+		fw.logger.Tracef("unregistered '%s'", watchedPath)
+	}
+}
+
+// RecursivelyWatch is so we can watch all sub directories of a directory,
+// it returns an error if :directoryPath is not a directory
+func (fw *Watcher) RecursivelyWatch(directoryPath string) error {
+	allSubDirectories, err := fw.recursivelyGetDirectories(directoryPath)
+	if err != nil {
+		return err
+	}
 	fw.Watch(directoryPath)
 	for _, directory := range allSubDirectories {
 		fw.Watch(directory)
 	}
+	for _, triggerFile := range fw.config.TriggerFiles {
+		if directory := path.Dir(triggerFile); !fw.isWatched(directory) && fw.pathExists(directory) {
+			fw.Watch(directory)
+		}
+	}
+	fw.logger.Debugf("watching %v director(ies), polling %v", fw.GetWatchedPathCount()-fw.GetPolledPathCount(), fw.GetPolledPathCount())
+	return nil
+}
+
+// isWatched checks whether :directoryPath is already being watched
+func (fw *Watcher) isWatched(directoryPath string) bool {
+	fw.pathsMutex.Lock()
+	defer fw.pathsMutex.Unlock()
+	return fw.watchedPaths[directoryPath]
 }
 
 // Watch is here for watching a single directory
 func (fw *Watcher) Watch(directoryPath string) {
-	fw.assertDirectoryIntegrity(directoryPath)
-	fw.watcher.Add(directoryPath)
+	if err := fw.checkDirectory(directoryPath); err != nil {
+		fw.logger.Warnf("unable to watch '%s': %s", directoryPath, err)
+		return
+	}
+	polled := false
+	if err := fw.watcher.Add(directoryPath); err != nil {
+		if !isWatchLimitError(err) {
+			fw.logger.Warnf("unable to watch '%s': %s", directoryPath, err)
+			return
+		}
+		fw.handleWatchLimit(directoryPath)
+		if fw.fallback == nil {
+			return
+		} else if err := fw.fallback.Add(directoryPath); err != nil {
+			fw.logger.Warnf("unable to poll '%s': %s", directoryPath, err)
+			return
+		}
+		polled = true
+	}
+	fw.pathsMutex.Lock()
+	if polled {
+		fw.polledPaths[directoryPath] = true
+	}
+	fw.watchedPaths[directoryPath] = true
+	if fw.followsSymlinks() {
+		if realPath, err := filepath.EvalSymlinks(directoryPath); err == nil {
+			fw.realPaths[realPath] = directoryPath
+		}
+	}
+	fw.pathsMutex.Unlock()
 	fw.logger.Tracef("registered '%s'", directoryPath)
 }
 
-// assertDirectoryIntegrity panicks if the :directoryPath does not exist/is not a directory
-func (fw *Watcher) assertDirectoryIntegrity(directoryPath string) {
+// handleWatchLimit explains how to raise the limit of inotify watches
+// the first time that :directoryPath and others cannot be watched
+// because of it
+func (fw *Watcher) handleWatchLimit(directoryPath string) {
+	fw.pathsMutex.Lock()
+	alreadyReached := fw.watchLimitReached
+	fw.watchLimitReached = true
+	watchCount := len(fw.watchedPaths) - len(fw.polledPaths)
+	fw.pathsMutex.Unlock()
+	if alreadyReached {
+		fw.logger.Tracef("unable to watch '%s': the watch limit was reached", directoryPath)
+		return
+	}
+	limit := getWatchLimit()
+	reachedLimit := "the limit of inotify watches"
+	if limit > 0 {
+		reachedLimit = fmt.Sprintf("the limit of %v inotify watches", limit)
+	}
+	suggestedLimit := DefaultSuggestedWatchLimit
+	if limit*2 > suggestedLimit {
+		suggestedLimit = limit * 2
+	}
+	fallback := "changes in the directories which are not watched are missed, use --poll-fallback to poll them"
+	if fw.fallback != nil {
+		fallback = fmt.Sprintf("the directories which are not watched are polled every %v", fw.config.PollFallback)
+	}
+	fw.logger.Errorf(
+		"%s was reached after watching %v director(ies) - %s. Raise it with 'sudo sysctl fs.inotify.max_user_watches=%v' and add 'fs.inotify.max_user_watches=%v' to /etc/sysctl.conf to keep it after restarts",
+		reachedLimit,
+		watchCount,
+		fallback,
+		suggestedLimit,
+		suggestedLimit,
+	)
+}
+
+// getFallbackEvents returns the events of the fallback polling, a nil
+// channel which never receives without it
+func (fw *Watcher) getFallbackEvents() <-chan fsnotify.Event {
+	if fw.fallback == nil {
+		return nil
+	}
+	return fw.fallback.Events()
+}
+
+// watchSymlinkedDirectory watches a symlinked directory created while
+// watching and its sub-directories unless its target is already watched
+func (fw *Watcher) watchSymlinkedDirectory(symlinkPath string) {
+	realPath, err := filepath.EvalSymlinks(symlinkPath)
+	if err != nil {
+		return
+	}
+	if fw.isWatchedRealPath(realPath) {
+		fw.logger.Tracef("skipped '%s' (already watched as '%s')", symlinkPath, realPath)
+		return
+	}
+	directories, err := fw.recursivelyGetDirectories(symlinkPath)
+	if err != nil {
+		fw.logger.Debugf("stopped watching '%s' as it changed while being watched: %s", symlinkPath, err)
+		return
+	}
+	fw.Watch(symlinkPath)
+	for _, directory := range directories {
+		if realPath, err := filepath.EvalSymlinks(directory); err == nil && !fw.isWatchedRealPath(realPath) {
+			fw.Watch(directory)
+		}
+	}
+}
+
+// isWatchedRealPath checks whether the directory at the resolved
+// :realPath is already being watched through any path
+func (fw *Watcher) isWatchedRealPath(realPath string) bool {
+	fw.pathsMutex.Lock()
+	defer fw.pathsMutex.Unlock()
+	_, watched := fw.realPaths[realPath]
+	return watched
+}
+
+// checkDirectory returns an error if :directoryPath does not exist or is
+// not a directory
+func (fw *Watcher) checkDirectory(directoryPath string) error {
 	if !fw.pathExists(directoryPath) {
-		panic(fmt.Sprintf("provided path '%s' does not exist", directoryPath))
+		return fmt.Errorf("provided path '%s' does not exist", directoryPath)
 	} else if !fw.pathIsDirectory(directoryPath) {
-		panic(fmt.Sprintf("provided path '%s' is not a directory", directoryPath))
+		return fmt.Errorf("provided path '%s' is not a directory", directoryPath)
 	}
+	return nil
 }
 
 // getDedupedEvents processes the events so that we don't respond to duplicate items
@@ -151,6 +581,130 @@ func (fw *Watcher) getDedupedEvents() []WatcherEvent {
 	return eventsToProcess
 }
 
+// isIgnoredFile checks whether the file changed in :event is excluded,
+// is too large or is a binary file when those are configured to be ignored
+func (fw *Watcher) isIgnoredFile(event *WatcherEvent) bool {
+	if fw.isExcludedPath(event.FilePath()) {
+		fw.logger.Tracef("ignored '%s' (matches an excluded or gitignored pattern)", event.FilePath())
+		return true
+	}
+	if fw.config.MaxFileSize > 0 {
+		if size := event.FileSize(); size > fw.config.MaxFileSize {
+			fw.logger.Tracef("ignored '%s' (%v bytes exceeds %v bytes)", event.FilePath(), size, fw.config.MaxFileSize)
+			return true
+		}
+	}
+	if fw.config.IgnoreBinaryFiles && event.IsBinary() {
+		fw.logger.Tracef("ignored '%s' (binary file)", event.FilePath())
+		return true
+	}
+	return false
+}
+
+// getRelativePath returns :absolutePath relative to the watch directory
+func (fw *Watcher) getRelativePath(absolutePath string) string {
+	if relativePath, err := filepath.Rel(fw.config.WatchDirectory, absolutePath); err == nil && len(fw.config.WatchDirectory) > 0 {
+		return relativePath
+	}
+	return absolutePath
+}
+
+// isExcludedPath checks whether :absolutePath matches one of the
+// ExcludePatterns or is ignored by git when UseGitignore is set
+func (fw *Watcher) isExcludedPath(absolutePath string) bool {
+	if fw.config == nil {
+		return false
+	}
+	relativePath := fw.getRelativePath(absolutePath)
+	if matchAnyPattern(fw.config.ExcludePatterns, relativePath) {
+		return true
+	}
+	fw.ignoreMutex.RLock()
+	defer fw.ignoreMutex.RUnlock()
+	if (len(fw.gitignoreRules) == 0 && len(fw.godevignoreRules) == 0) || strings.HasPrefix(relativePath, "..") {
+		return false
+	}
+	fileInfo, err := os.Lstat(absolutePath)
+	isDirectory := err == nil && fileInfo.IsDir()
+	return fw.gitignoreRules.IsIgnored(relativePath, isDirectory) || fw.godevignoreRules.IsIgnored(relativePath, isDirectory)
+}
+
+// isGodevignore checks whether :absolutePath is the .godevignore file
+// of the watch directory
+func (fw *Watcher) isGodevignore(absolutePath string) bool {
+	return len(fw.config.WatchDirectory) > 0 && path.Clean(absolutePath) == path.Join(fw.config.WatchDirectory, GodevignoreFileName)
+}
+
+// loadGodevignore replaces the rules from the .godevignore file in the
+// watch directory, a missing file has no rules
+func (fw *Watcher) loadGodevignore() {
+	filePath := path.Join(fw.config.WatchDirectory, GodevignoreFileName)
+	rules, err := loadIgnoreFile(filePath, "")
+	if err != nil && !os.IsNotExist(err) {
+		fw.logger.Warnf("unable to load '%s': %s", filePath, err)
+	}
+	fw.ignoreMutex.Lock()
+	fw.godevignoreRules = rules
+	fw.ignoreMutex.Unlock()
+	if len(rules) > 0 {
+		fw.logger.Debugf("loaded %v rule(s) from '%s'", len(rules), filePath)
+	}
+}
+
+// reloadGodevignore reloads the .godevignore file after it changed,
+// watching directories that are no longer ignored and unwatching those
+// that now are
+func (fw *Watcher) reloadGodevignore() {
+	fw.loadGodevignore()
+	fw.logger.Infof("reloaded '%s'", GodevignoreFileName)
+	fw.pathsMutex.Lock()
+	var unwatched []string
+	for directoryPath := range fw.watchedPaths {
+		if directoryPath != fw.config.WatchDirectory && fw.isExcludedPath(directoryPath) {
+			fw.watcher.Remove(directoryPath)
+			if fw.polledPaths[directoryPath] {
+				fw.fallback.Remove(directoryPath)
+				delete(fw.polledPaths, directoryPath)
+			}
+			delete(fw.watchedPaths, directoryPath)
+			unwatched = append(unwatched, directoryPath)
+		}
+	}
+	fw.pathsMutex.Unlock()
+	for _, directoryPath := range unwatched {
+		fw.logger.Tracef("unregistered '%s'", directoryPath)
+	}
+	if !fw.pathExists(fw.config.WatchDirectory) {
+		return
+	}
+	directories, err := fw.recursivelyGetDirectories(fw.config.WatchDirectory)
+	if err != nil {
+		fw.logger.Warnf("unable to watch new directories: %s", err)
+		return
+	}
+	for _, directoryPath := range directories {
+		if !fw.isWatched(directoryPath) {
+			fw.Watch(directoryPath)
+		}
+	}
+}
+
+// isIncludedPath checks whether :absolutePath matches one of the
+// IncludePatterns
+func (fw *Watcher) isIncludedPath(absolutePath string) bool {
+	return fw.config != nil && matchAnyPattern(fw.config.IncludePatterns, fw.getRelativePath(absolutePath))
+}
+
+// isTriggerFile checks whether the event is for one of the TriggerFiles
+func (fw *Watcher) isTriggerFile(event *WatcherEvent) bool {
+	for _, triggerFile := range fw.config.TriggerFiles {
+		if path.Clean(event.FilePath()) == path.Clean(triggerFile) {
+			return true
+		}
+	}
+	return false
+}
+
 // isIgnoredName checks whether the name was faulty
 func (fw *Watcher) isIgnoredName(name string) bool {
 	ignore := false
@@ -173,29 +727,80 @@ func (fw *Watcher) pathExists(absolutePath string) bool {
 	return true
 }
 
-// pathIsDirectory is for argument verification
+// pathIsDirectory is for argument verification, symlinks to directories
+// count as directories when following symlinks and paths which cannot
+// be read are not directories
 func (fw *Watcher) pathIsDirectory(absolutePath string) bool {
-	if fileInfo, err := os.Lstat(absolutePath); err != nil {
-		panic(err)
-	} else {
-		return fileInfo.IsDir()
+	stat := os.Lstat
+	if fw.followsSymlinks() {
+		stat = os.Stat
 	}
+	fileInfo, err := stat(absolutePath)
+	return err == nil && fileInfo.IsDir()
 }
 
 // recursivelyGetDirectories is here to retrieve a list of all sub-directories from :directoryPath
-func (fw *Watcher) recursivelyGetDirectories(directoryPath string) []string {
-	fw.assertDirectoryIntegrity(directoryPath)
+func (fw *Watcher) recursivelyGetDirectories(directoryPath string) ([]string, error) {
+	if err := fw.checkDirectory(directoryPath); err != nil {
+		return nil, err
+	}
+	visited := map[string]bool{}
+	if fw.followsSymlinks() {
+		if realPath, err := filepath.EvalSymlinks(directoryPath); err == nil {
+			visited[realPath] = true
+		}
+	}
+	return fw.getSubDirectories(directoryPath, visited)
+}
+
+// getSubDirectories lists the sub-directories of :directoryPath
+// recursively, when following symlinks directories whose real paths
+// were :visited already are skipped so that cycles end - sub-directories
+// which cannot be listed are skipped with a warning
+func (fw *Watcher) getSubDirectories(directoryPath string, visited map[string]bool) ([]string, error) {
 	directoryListing, err := ioutil.ReadDir(directoryPath)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("unable to list '%s': %s", directoryPath, err)
 	}
 	var listings []string
 	for _, listing := range directoryListing {
 		listingFullPath := path.Join(directoryPath, listing.Name())
-		if !fw.isIgnoredName(listing.Name()) && listing.IsDir() {
-			listings = append(listings, listingFullPath)
-			listings = append(listings, fw.recursivelyGetDirectories(listingFullPath)...)
+		if fw.isIgnoredName(listing.Name()) || !(listing.IsDir() || fw.isSymlinkedDirectory(listingFullPath)) || fw.isExcludedPath(listingFullPath) {
+			continue
+		}
+		if fw.followsSymlinks() {
+			realPath, err := filepath.EvalSymlinks(listingFullPath)
+			if err != nil || visited[realPath] {
+				fw.logger.Tracef("skipped '%s' (already watched as '%s')", listingFullPath, realPath)
+				continue
+			}
+			visited[realPath] = true
+		}
+		listings = append(listings, listingFullPath)
+		subDirectories, err := fw.getSubDirectories(listingFullPath, visited)
+		if err != nil {
+			fw.logger.Warnf("%s", err)
+			continue
 		}
+		listings = append(listings, subDirectories...)
+	}
+	return listings, nil
+}
+
+// followsSymlinks checks whether symlinked directories should be watched
+func (fw *Watcher) followsSymlinks() bool {
+	return fw.config != nil && fw.config.FollowSymlinks
+}
+
+// isSymlinkedDirectory checks whether :absolutePath is a symlink to a
+// directory that should be followed
+func (fw *Watcher) isSymlinkedDirectory(absolutePath string) bool {
+	if !fw.followsSymlinks() {
+		return false
+	}
+	if fileInfo, err := os.Lstat(absolutePath); err != nil || fileInfo.Mode()&os.ModeSymlink == 0 {
+		return false
 	}
-	return listings
+	fileInfo, err := os.Stat(absolutePath)
+	return err == nil && fileInfo.IsDir()
 }
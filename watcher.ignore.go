@@ -0,0 +1,153 @@
+package godev
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitignoreFileName is the name of the files that --use-gitignore loads
+const GitignoreFileName = ".gitignore"
+
+// GodevignoreFileName is the name of the file in the watch directory
+// with gitignore-style rules for paths that godev should not watch
+const GodevignoreFileName = ".godevignore"
+
+// ignoreRule is a single pattern from a gitignore-style file
+type ignoreRule struct {
+	// base is the slash-separated directory of the file the rule was
+	// loaded from relative to the watch directory
+	base          string
+	pattern       string
+	anchored      bool
+	directoryOnly bool
+	negate        bool
+}
+
+// match checks whether the slash-separated :relativePath matches the rule
+func (rule ignoreRule) match(relativePath string, isDirectory bool) bool {
+	if rule.directoryOnly && !isDirectory {
+		return false
+	}
+	if len(rule.base) > 0 {
+		if !strings.HasPrefix(relativePath, rule.base+"/") {
+			return false
+		}
+		relativePath = strings.TrimPrefix(relativePath, rule.base+"/")
+	}
+	if rule.anchored {
+		return matchPatternSegments(strings.Split(rule.pattern, "/"), strings.Split(relativePath, "/"))
+	}
+	matched, _ := path.Match(rule.pattern, path.Base(relativePath))
+	return matched
+}
+
+// ignoreRules are gitignore-style rules in the order they were loaded,
+// later rules take precedence over earlier ones
+type ignoreRules []ignoreRule
+
+// IsIgnored checks whether :relativePath (relative to the watch
+// directory) is ignored - like git, paths within an ignored directory
+// are ignored even if a later rule negates them
+func (rules ignoreRules) IsIgnored(relativePath string, isDirectory bool) bool {
+	relativePath = strings.Trim(filepath.ToSlash(relativePath), "/")
+	if len(rules) == 0 || len(relativePath) == 0 || relativePath == "." {
+		return false
+	}
+	segments := strings.Split(relativePath, "/")
+	for index := range segments {
+		isLast := index == len(segments)-1
+		if rules.evaluate(strings.Join(segments[:index+1], "/"), !isLast || isDirectory) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate returns the result of the last rule matching :relativePath
+func (rules ignoreRules) evaluate(relativePath string, isDirectory bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.match(relativePath, isDirectory) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnoreRules parses the :contents of a gitignore-style file found
+// in the :base directory, malformed patterns are skipped
+func parseIgnoreRules(contents, base string) ignoreRules {
+	rules := ignoreRules{}
+	base = strings.Trim(path.Clean(filepath.ToSlash(base)), "/")
+	if base == "." {
+		base = ""
+	}
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, "\\ ") {
+			line = strings.TrimRight(line, " \t")
+		}
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.directoryOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		rule.anchored = strings.Contains(line, "/")
+		rule.pattern = strings.TrimLeft(line, "/")
+		if len(rule.pattern) == 0 || validatePatterns([]string{rule.pattern}) != nil {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// loadIgnoreFile parses the gitignore-style file at :filePath whose
+// rules apply to the :base directory
+func loadIgnoreFile(filePath, base string) (ignoreRules, error) {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseIgnoreRules(string(contents), base), nil
+}
+
+// loadGitignoreRules loads .git/info/exclude and the .gitignore files of
+// :watchDirectory and its sub-directories, directories which are ignored
+// are not searched for further .gitignore files
+func loadGitignoreRules(watchDirectory string) ignoreRules {
+	rules := parseIgnoreRules(".git/", "")
+	if excludeRules, err := loadIgnoreFile(path.Join(watchDirectory, ".git", "info", "exclude"), ""); err == nil {
+		rules = append(rules, excludeRules...)
+	}
+	return loadNestedGitignoreRules(watchDirectory, "", rules)
+}
+
+func loadNestedGitignoreRules(watchDirectory, relativeDirectory string, rules ignoreRules) ignoreRules {
+	directory := path.Join(watchDirectory, relativeDirectory)
+	if fileRules, err := loadIgnoreFile(path.Join(directory, GitignoreFileName), relativeDirectory); err == nil {
+		rules = append(rules, fileRules...)
+	}
+	listings, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return rules
+	}
+	for _, listing := range listings {
+		relativePath := path.Join(relativeDirectory, listing.Name())
+		if listing.IsDir() && !rules.IsIgnored(relativePath, true) {
+			rules = loadNestedGitignoreRules(watchDirectory, relativePath, rules)
+		}
+	}
+	return rules
+}
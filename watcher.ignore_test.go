@@ -0,0 +1,79 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatcherIgnoreTestSuite struct {
+	suite.Suite
+}
+
+func TestWatcherIgnore(t *testing.T) {
+	suite.Run(t, new(WatcherIgnoreTestSuite))
+}
+
+func (s *WatcherIgnoreTestSuite) Test_parseIgnoreRules() {
+	t := s.T()
+	rules := parseIgnoreRules("# comment\n\n*.log  \r\n!keep.log\n/bin\nbuild/\ndocs/*.md\n\\#notes\n[\n", "")
+	assert.Equal(t, ignoreRules{
+		ignoreRule{pattern: "*.log"},
+		ignoreRule{pattern: "keep.log", negate: true},
+		ignoreRule{pattern: "bin", anchored: true},
+		ignoreRule{pattern: "build", directoryOnly: true},
+		ignoreRule{pattern: "docs/*.md", anchored: true},
+		ignoreRule{pattern: "#notes"},
+	}, rules)
+	assert.Equal(t, "web/app", parseIgnoreRules("*.js", "./web/app/")[0].base)
+}
+
+func (s *WatcherIgnoreTestSuite) Test_IsIgnored() {
+	t := s.T()
+	rules := parseIgnoreRules("*.log\n!keep.log\n/bin\nbuild/\ndocs/**/*.md\nnode_modules\n", "")
+	rules = append(rules, parseIgnoreRules("*.tmp\n/dist\n!debug.log\n", "web")...)
+	assert.True(t, rules.IsIgnored("server.log", false))
+	assert.True(t, rules.IsIgnored("logs/server.log", false), "expected patterns without a slash to match at any depth")
+	assert.False(t, rules.IsIgnored("logs/keep.log", false), "expected later negations to take precedence")
+	assert.True(t, rules.IsIgnored("bin", true))
+	assert.True(t, rules.IsIgnored("bin/app", false), "expected paths within ignored directories to be ignored")
+	assert.False(t, rules.IsIgnored("cmd/bin/main.go", false), "expected a leading slash to anchor the pattern")
+	assert.True(t, rules.IsIgnored("cmd/build", true))
+	assert.False(t, rules.IsIgnored("cmd/build", false), "expected trailing slashes to only match directories")
+	assert.True(t, rules.IsIgnored("docs/api/v1/index.md", false))
+	assert.True(t, rules.IsIgnored("web/node_modules/react/index.js", false))
+	assert.True(t, rules.IsIgnored("web/cache.tmp", false))
+	assert.False(t, rules.IsIgnored("cache.tmp", false), "expected nested rules to only apply below their directory")
+	assert.True(t, rules.IsIgnored("web/dist", true))
+	assert.False(t, rules.IsIgnored("web/src/dist", true))
+	assert.False(t, rules.IsIgnored("web/debug.log", false), "expected nested rules to take precedence")
+	assert.False(t, rules.IsIgnored("main.go", false))
+	assert.False(t, rules.IsIgnored(".", true))
+}
+
+func (s *WatcherIgnoreTestSuite) Test_loadGitignoreRules() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-gitignore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	for _, subDirectory := range []string{".git/info", "web/dist", "coverage"} {
+		assert.Nil(t, os.MkdirAll(path.Join(directory, subDirectory), os.ModePerm))
+	}
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, ".gitignore"), []byte("coverage/\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, ".git/info/exclude"), []byte("*.swp\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "web/.gitignore"), []byte("/dist\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, "coverage/.gitignore"), []byte("!*\n"), 0644))
+	rules := loadGitignoreRules(directory)
+	assert.True(t, rules.IsIgnored(".git/HEAD", false))
+	assert.True(t, rules.IsIgnored("main.go.swp", false))
+	assert.True(t, rules.IsIgnored("coverage/c.out", false))
+	assert.True(t, rules.IsIgnored("web/dist/app.js", false))
+	assert.False(t, rules.IsIgnored("web/index.js", false))
+	for _, rule := range rules {
+		assert.NotEqual(t, "coverage", rule.base, "expected ignored directories not to be searched for .gitignore files")
+	}
+}
@@ -0,0 +1,36 @@
+package godev
+
+import (
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DefaultSuggestedWatchLimit is the smallest limit of inotify watches
+// suggested when the current one is reached
+const DefaultSuggestedWatchLimit = 524288
+
+// WatchLimitPath is where Linux exposes the limit of inotify watches
+const WatchLimitPath = "/proc/sys/fs/inotify/max_user_watches"
+
+// isWatchLimitError checks whether :err was returned because the limit
+// of inotify watches is reached, which Linux reports as ENOSPC
+func isWatchLimitError(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
+
+// getWatchLimit returns the limit of inotify watches or 0 when it is
+// not known
+func getWatchLimit() int {
+	contents, err := ioutil.ReadFile(WatchLimitPath)
+	if err != nil {
+		return 0
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return 0
+	}
+	return limit
+}
@@ -0,0 +1,108 @@
+package godev
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatcherLimitTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestWatcherLimit(t *testing.T) {
+	suite.Run(t, new(WatcherLimitTestSuite))
+}
+
+func (s *WatcherLimitTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-watcher-limit")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+	for _, subDirectory := range []string{"a", "b", "c"} {
+		assert.Nil(s.T(), os.Mkdir(path.Join(directory, subDirectory), os.ModePerm))
+	}
+}
+
+func (s *WatcherLimitTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+// limitedBackend fails like inotify once :limit directories are watched
+type limitedBackend struct {
+	limit   int
+	watched []string
+	events  chan fsnotify.Event
+}
+
+func (backend *limitedBackend) Add(directoryPath string) error {
+	if len(backend.watched) >= backend.limit {
+		return syscall.ENOSPC
+	}
+	backend.watched = append(backend.watched, directoryPath)
+	return nil
+}
+
+func (backend *limitedBackend) Remove(directoryPath string) error { return nil }
+
+func (backend *limitedBackend) Close() error { return nil }
+
+func (backend *limitedBackend) Events() <-chan fsnotify.Event { return backend.events }
+
+func (s *WatcherLimitTestSuite) TestRecursivelyWatch_reachesTheLimit() {
+	t := s.T()
+	var logs bytes.Buffer
+	w := initTestWatcher(s.T(), &WatcherConfig{LogLevel: "trace"})
+	defer w.Close()
+	w.watcher = &limitedBackend{limit: 2}
+	w.logger.SetOutput(&logs)
+	assert.Nil(s.T(), w.RecursivelyWatch(s.directory))
+	assert.Equal(t, 2, w.GetWatchedPathCount(), "expected only the directories within the limit to be watched")
+	assert.Equal(t, 0, w.GetPolledPathCount())
+	assert.Equal(t, 1, bytes.Count(logs.Bytes(), []byte("was reached after watching 2 director(ies)")), "expected the limit to be explained once")
+	assert.Contains(t, logs.String(), "sudo sysctl fs.inotify.max_user_watches=")
+	assert.Contains(t, logs.String(), "use --poll-fallback to poll them")
+}
+
+func (s *WatcherLimitTestSuite) TestRecursivelyWatch_pollsTheRest() {
+	t := s.T()
+	var logs bytes.Buffer
+	w := initTestWatcher(s.T(), &WatcherConfig{
+		FileExtensions: []string{"go"},
+		PollFallback:   10 * time.Millisecond,
+		WatchDirectory: s.directory,
+	})
+	defer w.Close()
+	w.watcher = &limitedBackend{limit: 2}
+	w.logger.SetOutput(&logs)
+	assert.Nil(s.T(), w.RecursivelyWatch(s.directory))
+	assert.Equal(t, 4, w.GetWatchedPathCount())
+	assert.Equal(t, 2, w.GetPolledPathCount())
+	assert.Contains(t, logs.String(), fmt.Sprintf("the directories which are not watched are polled every %v", 10*time.Millisecond))
+	filePath := path.Join(s.directory, "c", "main.go")
+	assert.Nil(t, ioutil.WriteFile(filePath, []byte("package main"), 0644))
+	select {
+	case event := <-w.getFallbackEvents():
+		assert.Equal(t, filePath, event.Name)
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected changes in polled directories to be reported")
+	}
+}
+
+func (s *WatcherLimitTestSuite) Test_isWatchLimitError() {
+	t := s.T()
+	assert.True(t, isWatchLimitError(syscall.ENOSPC))
+	assert.True(t, isWatchLimitError(fmt.Errorf("adding a watch: %w", syscall.ENOSPC)))
+	assert.False(t, isWatchLimitError(syscall.ENOENT))
+}
@@ -0,0 +1,74 @@
+package godev
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// matchPattern checks whether the slash-separated :relativePath matches
+// :pattern - patterns containing a slash are matched against the whole
+// path where "**" matches any number of directories, other patterns are
+// matched against the file name
+func matchPattern(pattern, relativePath string) bool {
+	relativePath = strings.Trim(filepath.ToSlash(relativePath), "/")
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(relativePath))
+		return matched
+	}
+	return matchPatternSegments(
+		strings.Split(strings.Trim(pattern, "/"), "/"),
+		strings.Split(relativePath, "/"),
+	)
+}
+
+// matchPatternSegments matches the :patterns against the :segments of a
+// path one directory at a time
+func matchPatternSegments(patterns, segments []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for skipped := 0; skipped <= len(segments); skipped++ {
+				if matchPatternSegments(patterns[1:], segments[skipped:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(patterns[0], segments[0]); !matched {
+			return false
+		}
+		patterns = patterns[1:]
+		segments = segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// matchAnyPattern checks whether :relativePath matches any of :patterns
+func matchAnyPattern(patterns []string, relativePath string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, relativePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePatterns returns an error for the first of :patterns which
+// is malformed
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if len(strings.Trim(pattern, "/")) == 0 {
+			return fmt.Errorf("'%s' is not a valid pattern", pattern)
+		}
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("'%s' is not a valid pattern: %s", pattern, err)
+			}
+		}
+	}
+	return nil
+}
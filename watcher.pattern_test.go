@@ -0,0 +1,46 @@
+package godev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatcherPatternTestSuite struct {
+	suite.Suite
+}
+
+func TestWatcherPattern(t *testing.T) {
+	suite.Run(t, new(WatcherPatternTestSuite))
+}
+
+func (s *WatcherPatternTestSuite) Test_matchPattern() {
+	t := s.T()
+	assert.True(t, matchPattern("*_gen.go", "api_gen.go"))
+	assert.True(t, matchPattern("*_gen.go", "internal/api/api_gen.go"), "expected patterns without a slash to match file names")
+	assert.False(t, matchPattern("*_gen.go", "internal/api/api.go"))
+	assert.True(t, matchPattern("configs/*.yaml", "configs/app.yaml"))
+	assert.False(t, matchPattern("configs/*.yaml", "configs/dev/app.yaml"))
+	assert.False(t, matchPattern("configs/*.yaml", "deploy/configs/app.yaml"))
+	assert.True(t, matchPattern("configs/**/*.yaml", "configs/app.yaml"))
+	assert.True(t, matchPattern("configs/**/*.yaml", "configs/dev/eu/app.yaml"))
+	assert.True(t, matchPattern("**/testdata/**", "testdata"))
+	assert.True(t, matchPattern("**/testdata/**", "pkg/parser/testdata"))
+	assert.True(t, matchPattern("**/testdata/**", "pkg/parser/testdata/golden/a.go"))
+	assert.False(t, matchPattern("**/testdata/**", "pkg/testdatabase/a.go"))
+	assert.True(t, matchPattern("/docs/*.md", "docs/index.md"))
+}
+
+func (s *WatcherPatternTestSuite) Test_matchAnyPattern() {
+	t := s.T()
+	assert.False(t, matchAnyPattern(nil, "main.go"))
+	assert.True(t, matchAnyPattern([]string{"*.yaml", "*.go"}, "cmd/main.go"))
+}
+
+func (s *WatcherPatternTestSuite) Test_validatePatterns() {
+	t := s.T()
+	assert.Nil(t, validatePatterns([]string{"**/testdata/**", "*_gen.go", "configs/*.yaml"}))
+	assert.NotNil(t, validatePatterns([]string{"configs/[*.yaml"}))
+	assert.NotNil(t, validatePatterns([]string{"/"}))
+}
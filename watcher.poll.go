@@ -0,0 +1,170 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watcherBackend is the source of file system events for Watcher
+type watcherBackend interface {
+	Add(directoryPath string) error
+	Remove(directoryPath string) error
+	Close() error
+	Events() <-chan fsnotify.Event
+}
+
+// fsnotifyBackend receives events from the operating system
+type fsnotifyBackend struct {
+	watcher *fsnotify.Watcher
+}
+
+func (backend *fsnotifyBackend) Add(directoryPath string) error {
+	return backend.watcher.Add(directoryPath)
+}
+
+func (backend *fsnotifyBackend) Remove(directoryPath string) error {
+	return backend.watcher.Remove(directoryPath)
+}
+
+func (backend *fsnotifyBackend) Close() error {
+	return backend.watcher.Close()
+}
+
+func (backend *fsnotifyBackend) Events() <-chan fsnotify.Event {
+	return backend.watcher.Events
+}
+
+// pollingEntry is what the pollingBackend remembers of a file to tell
+// whether it changed
+type pollingEntry struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// initPollingBackend creates a backend which lists the watched
+// directories every :interval, for file systems where the operating
+// system does not report changes such as NFS and some docker mounts
+func initPollingBackend(interval time.Duration) *pollingBackend {
+	backend := &pollingBackend{
+		interval:    interval,
+		events:      make(chan fsnotify.Event),
+		directories: map[string]map[string]pollingEntry{},
+		stop:        make(chan bool),
+	}
+	go backend.pollRoutine()
+	return backend
+}
+
+// pollingBackend emits the same events as fsnotify by comparing the
+// entries of the watched directories between polls
+type pollingBackend struct {
+	interval    time.Duration
+	events      chan fsnotify.Event
+	directories map[string]map[string]pollingEntry
+	mutex       sync.Mutex
+	stop        chan bool
+	stopOnce    sync.Once
+}
+
+func (backend *pollingBackend) Add(directoryPath string) error {
+	entries, err := listPollingEntries(directoryPath)
+	if err != nil {
+		return err
+	}
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	backend.directories[directoryPath] = entries
+	return nil
+}
+
+func (backend *pollingBackend) Remove(directoryPath string) error {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	delete(backend.directories, directoryPath)
+	return nil
+}
+
+func (backend *pollingBackend) Close() error {
+	backend.stopOnce.Do(func() { close(backend.stop) })
+	return nil
+}
+
+func (backend *pollingBackend) Events() <-chan fsnotify.Event {
+	return backend.events
+}
+
+func (backend *pollingBackend) pollRoutine() {
+	ticker := time.NewTicker(backend.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-backend.stop:
+			return
+		case <-ticker.C:
+			for _, event := range backend.poll() {
+				select {
+				case backend.events <- event:
+				case <-backend.stop:
+					return
+				}
+			}
+		}
+	}
+}
+
+// poll lists the watched directories and returns the events for the
+// differences since the last poll, directories which no longer exist
+// are reported as removed and stop being watched
+func (backend *pollingBackend) poll() []fsnotify.Event {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	var events []fsnotify.Event
+	for directoryPath, previousEntries := range backend.directories {
+		entries, err := listPollingEntries(directoryPath)
+		if err != nil {
+			delete(backend.directories, directoryPath)
+			events = append(events, fsnotify.Event{Name: directoryPath, Op: fsnotify.Remove})
+			continue
+		}
+		for name, entry := range entries {
+			filePath := path.Join(directoryPath, name)
+			previousEntry, existed := previousEntries[name]
+			if !existed {
+				events = append(events, fsnotify.Event{Name: filePath, Op: fsnotify.Create})
+			} else if !entry.modTime.Equal(previousEntry.modTime) || entry.size != previousEntry.size {
+				events = append(events, fsnotify.Event{Name: filePath, Op: fsnotify.Write})
+			} else if entry.mode != previousEntry.mode {
+				events = append(events, fsnotify.Event{Name: filePath, Op: fsnotify.Chmod})
+			}
+		}
+		for name := range previousEntries {
+			if _, exists := entries[name]; !exists {
+				events = append(events, fsnotify.Event{Name: path.Join(directoryPath, name), Op: fsnotify.Remove})
+			}
+		}
+		backend.directories[directoryPath] = entries
+	}
+	return events
+}
+
+func listPollingEntries(directoryPath string) (map[string]pollingEntry, error) {
+	listings, err := ioutil.ReadDir(directoryPath)
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]pollingEntry{}
+	for _, listing := range listings {
+		entries[listing.Name()] = pollingEntry{
+			modTime: listing.ModTime(),
+			size:    listing.Size(),
+			mode:    listing.Mode(),
+		}
+	}
+	return entries, nil
+}
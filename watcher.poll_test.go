@@ -0,0 +1,94 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatcherPollTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestWatcherPoll(t *testing.T) {
+	suite.Run(t, new(WatcherPollTestSuite))
+}
+
+func (s *WatcherPollTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-poll")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+}
+
+func (s *WatcherPollTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *WatcherPollTestSuite) Test_poll() {
+	t := s.T()
+	backend := &pollingBackend{directories: map[string]map[string]pollingEntry{}}
+	filePath := path.Join(s.directory, "main.go")
+	assert.Nil(t, backend.Add(s.directory))
+	assert.NotNil(t, backend.Add(path.Join(s.directory, "missing")))
+	assert.Empty(t, backend.poll())
+
+	assert.Nil(t, ioutil.WriteFile(filePath, []byte("package main"), 0644))
+	assert.Equal(t, []fsnotify.Event{{Name: filePath, Op: fsnotify.Create}}, backend.poll())
+	assert.Nil(t, ioutil.WriteFile(filePath, []byte("package main\n"), 0644))
+	assert.Equal(t, []fsnotify.Event{{Name: filePath, Op: fsnotify.Write}}, backend.poll())
+	if runtime.GOOS != "windows" {
+		assert.Nil(t, os.Chmod(filePath, 0600))
+		assert.Equal(t, []fsnotify.Event{{Name: filePath, Op: fsnotify.Chmod}}, backend.poll())
+	}
+	assert.Nil(t, os.Remove(filePath))
+	assert.Equal(t, []fsnotify.Event{{Name: filePath, Op: fsnotify.Remove}}, backend.poll())
+
+	assert.Nil(t, os.Remove(s.directory))
+	assert.Equal(t, []fsnotify.Event{{Name: s.directory, Op: fsnotify.Remove}}, backend.poll())
+	assert.Empty(t, backend.directories, "expected removed directories to stop being polled")
+}
+
+func (s *WatcherPollTestSuite) Test_Remove() {
+	t := s.T()
+	backend := &pollingBackend{directories: map[string]map[string]pollingEntry{}}
+	assert.Nil(t, backend.Add(s.directory))
+	assert.Nil(t, backend.Remove(s.directory))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "main.go"), []byte("package main"), 0644))
+	assert.Empty(t, backend.poll())
+}
+
+func (s *WatcherPollTestSuite) Test_Events() {
+	t := s.T()
+	backend := initPollingBackend(10 * time.Millisecond)
+	defer backend.Close()
+	assert.Nil(t, backend.Add(s.directory))
+	assert.Nil(t, ioutil.WriteFile(path.Join(s.directory, "main.go"), []byte("package main"), 0644))
+	select {
+	case event := <-backend.Events():
+		assert.Equal(t, path.Join(s.directory, "main.go"), event.Name)
+		assert.Equal(t, fsnotify.Create, event.Op)
+	case <-time.After(2 * time.Second):
+		t.Error("expected an event for the created file")
+	}
+	assert.Nil(t, backend.Close(), "expected closing twice to be safe")
+}
+
+func (s *WatcherPollTestSuite) Test_InitWatcher_withPollInterval() {
+	t := s.T()
+	w := initTestWatcher(s.T(), &WatcherConfig{PollInterval: time.Second})
+	defer w.Close()
+	assert.IsType(t, &pollingBackend{}, w.watcher)
+	w = initTestWatcher(s.T(), &WatcherConfig{})
+	defer w.Close()
+	assert.IsType(t, &fsnotifyBackend{}, w.watcher)
+}
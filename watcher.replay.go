@@ -0,0 +1,173 @@
+package godev
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatcherRecord is a line of the file written by --record and read by
+// --replay for each event received from the file system
+type WatcherRecord struct {
+	// Delay is the time since the previous event or since the watch
+	// started for the first one, in nanoseconds when encoded
+	Delay time.Duration `json:"delay"`
+	// Path is relative to the watch directory so that a recording can be
+	// replayed in another checkout of the project
+	Path       string   `json:"path"`
+	Operations []string `json:"ops"`
+}
+
+// initWatcherRecorder creates the file at :filePath to record the events
+// of a watch of :watchDirectory in
+func initWatcherRecorder(filePath, watchDirectory string) (*watcherRecorder, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &watcherRecorder{
+		file:           file,
+		encoder:        json.NewEncoder(file),
+		watchDirectory: watchDirectory,
+		lastRecordedAt: time.Now(),
+	}, nil
+}
+
+// watcherRecorder writes the events received by the Watcher as
+// WatcherRecords before they are filtered
+type watcherRecorder struct {
+	file           *os.File
+	encoder        *json.Encoder
+	watchDirectory string
+	lastRecordedAt time.Time
+	mutex          sync.Mutex
+}
+
+// Record writes :event with the time since the last one
+func (recorder *watcherRecorder) Record(event fsnotify.Event) error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	now := time.Now()
+	relativePath := event.Name
+	if relative, err := filepath.Rel(recorder.watchDirectory, event.Name); err == nil {
+		relativePath = filepath.ToSlash(relative)
+	}
+	watcherEvent := WatcherEvent(event)
+	record := WatcherRecord{
+		Delay:      now.Sub(recorder.lastRecordedAt),
+		Path:       relativePath,
+		Operations: watcherEvent.Operations(),
+	}
+	recorder.lastRecordedAt = now
+	return recorder.encoder.Encode(record)
+}
+
+// Close closes the file being recorded in
+func (recorder *watcherRecorder) Close() error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return recorder.file.Close()
+}
+
+// loadWatcherRecords reads the WatcherRecords in the file at :filePath
+func loadWatcherRecords(filePath string) ([]WatcherRecord, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var records []WatcherRecord
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var record WatcherRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %v of '%s' is not a recorded event: %s", line, filePath, err)
+		} else if len(record.Path) == 0 || len(record.Operations) == 0 {
+			return nil, fmt.Errorf("line %v of '%s' has no path or no ops", line, filePath)
+		} else if _, err := ParseWatcherOperations(record.Operations); err != nil {
+			return nil, fmt.Errorf("line %v of '%s' has invalid ops: %s", line, filePath, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// initReplayBackend creates a backend which emits the events recorded in
+// the file at :filePath with their recorded delays instead of those of
+// the file system, relative paths are resolved from :watchDirectory
+func initReplayBackend(filePath, watchDirectory string, logger *Logger) (*replayBackend, error) {
+	records, err := loadWatcherRecords(filePath)
+	if err != nil {
+		return nil, err
+	}
+	backend := &replayBackend{
+		records:        records,
+		watchDirectory: watchDirectory,
+		logger:         logger,
+		events:         make(chan fsnotify.Event),
+		stop:           make(chan bool),
+	}
+	go backend.replayRoutine()
+	return backend, nil
+}
+
+// replayBackend emits recorded events, the directories that the Watcher
+// adds are not watched
+type replayBackend struct {
+	records        []WatcherRecord
+	watchDirectory string
+	logger         *Logger
+	events         chan fsnotify.Event
+	stop           chan bool
+	stopOnce       sync.Once
+}
+
+func (backend *replayBackend) Add(directoryPath string) error {
+	return nil
+}
+
+func (backend *replayBackend) Remove(directoryPath string) error {
+	return nil
+}
+
+func (backend *replayBackend) Close() error {
+	backend.stopOnce.Do(func() { close(backend.stop) })
+	return nil
+}
+
+func (backend *replayBackend) Events() <-chan fsnotify.Event {
+	return backend.events
+}
+
+func (backend *replayBackend) replayRoutine() {
+	backend.logger.Infof("replaying %v recorded event(s)", len(backend.records))
+	for _, record := range backend.records {
+		select {
+		case <-time.After(record.Delay):
+		case <-backend.stop:
+			return
+		}
+		operations, _ := ParseWatcherOperations(record.Operations)
+		eventPath := filepath.FromSlash(record.Path)
+		if !filepath.IsAbs(eventPath) {
+			eventPath = path.Join(backend.watchDirectory, record.Path)
+		}
+		select {
+		case backend.events <- fsnotify.Event{Name: eventPath, Op: operations}:
+		case <-backend.stop:
+			return
+		}
+	}
+	backend.logger.Infof("replayed all %v recorded event(s)", len(backend.records))
+}
@@ -0,0 +1,117 @@
+package godev
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatcherReplayTestSuite struct {
+	suite.Suite
+	directory string
+	logger    *Logger
+}
+
+func TestWatcherReplay(t *testing.T) {
+	suite.Run(t, new(WatcherReplayTestSuite))
+}
+
+func (s *WatcherReplayTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-watcher-replay")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = directory
+	s.logger = InitLogger(&LoggerConfig{Name: "test"})
+	s.logger.SetOutput(&bytes.Buffer{})
+}
+
+func (s *WatcherReplayTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *WatcherReplayTestSuite) TestRecordAndReplay() {
+	t := s.T()
+	recordPath := path.Join(s.directory, "events.jsonl")
+	recorder, err := initWatcherRecorder(recordPath, "/project")
+	assert.Nil(t, err)
+	assert.Nil(t, recorder.Record(fsnotify.Event{Name: "/project/main.go", Op: fsnotify.Create | fsnotify.Write}))
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, recorder.Record(fsnotify.Event{Name: "/project/pkg/util.go", Op: fsnotify.Remove}))
+	assert.Nil(t, recorder.Close())
+	records, err := loadWatcherRecords(recordPath)
+	assert.Nil(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "main.go", records[0].Path)
+	assert.Equal(t, []string{"create", "write"}, records[0].Operations)
+	assert.Equal(t, "pkg/util.go", records[1].Path)
+	assert.True(t, records[1].Delay >= 10*time.Millisecond, "expected the time between events to be recorded")
+
+	backend, err := initReplayBackend(recordPath, "/checkout", s.logger)
+	assert.Nil(t, err)
+	defer backend.Close()
+	var replayed []fsnotify.Event
+	for len(replayed) < 2 {
+		select {
+		case event := <-backend.Events():
+			replayed = append(replayed, event)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "expected the recorded events to be replayed")
+		}
+	}
+	assert.Equal(t, []fsnotify.Event{
+		{Name: "/checkout/main.go", Op: fsnotify.Create | fsnotify.Write},
+		{Name: "/checkout/pkg/util.go", Op: fsnotify.Remove},
+	}, replayed)
+}
+
+func (s *WatcherReplayTestSuite) Test_loadWatcherRecords_invalid() {
+	t := s.T()
+	for _, contents := range []string{
+		"not json\n",
+		`{"delay":0,"path":"","ops":["write"]}` + "\n",
+		`{"delay":0,"path":"main.go","ops":["touch"]}` + "\n",
+	} {
+		filePath := path.Join(s.directory, "events.jsonl")
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(contents), 0644))
+		_, err := loadWatcherRecords(filePath)
+		assert.NotNil(t, err, contents)
+	}
+	_, err := loadWatcherRecords(path.Join(s.directory, "missing.jsonl"))
+	assert.NotNil(t, err)
+}
+
+func (s *WatcherReplayTestSuite) TestWatcher_recordsAndReplays() {
+	t := s.T()
+	recordPath := path.Join(s.directory, "events.jsonl")
+	assert.Nil(t, ioutil.WriteFile(recordPath, []byte(
+		`{"delay":0,"path":"main.go","ops":["write"]}`+"\n"+
+			`{"delay":0,"path":"README.md","ops":["write"]}`+"\n",
+	), 0644))
+	rerecordPath := path.Join(s.directory, "rerecorded.jsonl")
+	w := initTestWatcher(s.T(), &WatcherConfig{
+		FileExtensions: []string{"go"},
+		RecordFile:     rerecordPath,
+		ReplayFile:     recordPath,
+		WatchDirectory: s.directory,
+	})
+	w.logger.SetOutput(&bytes.Buffer{})
+	assert.IsType(t, &replayBackend{}, w.watcher)
+	for count := 0; count < 2; count++ {
+		event := <-w.watcher.Events()
+		w.recordEvent(event)
+		w.handleEvent(WatcherEvent(event))
+	}
+	w.Close()
+	assert.Equal(t, []WatcherEvent{{Name: path.Join(s.directory, "main.go"), Op: fsnotify.Write}}, w.events, "expected replayed events to be filtered like real ones")
+	records, err := loadWatcherRecords(rerecordPath)
+	assert.Nil(t, err)
+	assert.Len(t, records, 2, "expected events to be recorded before they are filtered")
+}
@@ -1,10 +1,13 @@
-package main
+package godev
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -31,6 +34,16 @@ func (s *WatcherTestSuite) SetupTest() {
 	s.currentDirectory = cwd
 }
 
+// initTestWatcher returns a Watcher for :config and fails the test if it
+// cannot be initialised
+func initTestWatcher(t *testing.T, config *WatcherConfig) *Watcher {
+	w, err := InitWatcher(config)
+	if err != nil {
+		t.Fatalf("unable to initialise the watcher: %s", err)
+	}
+	return w
+}
+
 func (s *WatcherTestSuite) TestEndWatch() {
 	var logBuffer bytes.Buffer
 	mockLog := InitLogger(&LoggerConfig{
@@ -57,19 +70,24 @@ func (s *WatcherTestSuite) TestEndWatch() {
 	wg.Wait()
 }
 
+func (s *WatcherTestSuite) TestClose_withoutInitialisation() {
+	assert.NotPanics(s.T(), func() { (&Watcher{}).Close() })
+}
+
 func (s *WatcherTestSuite) TestRecursivelyWatch() {
 	var logBuffer bytes.Buffer
 	mockLog := InitLogger(&LoggerConfig{
 		Name: "test",
 	})
 	mockLog.SetOutput(&logBuffer)
-	w := InitWatcher(&WatcherConfig{})
+	w := initTestWatcher(s.T(), &WatcherConfig{})
 	w.logger = mockLog
 	cwd := s.currentDirectory
 	testDirectoryPath := path.Join(cwd, "/data/test-recursive")
-	w.RecursivelyWatch(testDirectoryPath)
+	assert.Nil(s.T(), w.RecursivelyWatch(testDirectoryPath))
 	defer w.Close()
-	allSubDirectories := w.recursivelyGetDirectories(testDirectoryPath)
+	allSubDirectories, err := w.recursivelyGetDirectories(testDirectoryPath)
+	assert.Nil(s.T(), err)
 	logs := string(logBuffer.Bytes())
 	for _, subDirectory := range allSubDirectories {
 		assert.Containsf(
@@ -89,12 +107,13 @@ func (s *WatcherTestSuite) TestWatch() {
 		Name: "test",
 	})
 	mockLog.SetOutput(&logBuffer)
-	w := InitWatcher(&WatcherConfig{})
+	w := initTestWatcher(s.T(), &WatcherConfig{})
 	w.logger = mockLog
 	cwd := s.currentDirectory
 	testDirectoryPath := path.Join(cwd, "/data/test-watch")
 	w.Watch(testDirectoryPath)
 	defer w.Close()
+	assert.Equal(t, 1, w.GetWatchedPathCount())
 	testFilePath := path.Join(testDirectoryPath, "Watcher.TestWatch")
 	createFile(t, testFilePath)
 	removeFile(t, testFilePath)
@@ -106,18 +125,17 @@ func (s *WatcherTestSuite) TestWatch() {
 	)
 }
 
-func (s *WatcherTestSuite) Test_assertDirectoryIntegrityPass() {
-	defer expectError(s.T())()
-	w := &Watcher{}
-	cwd := s.currentDirectory
-	w.assertDirectoryIntegrity(path.Join(cwd, "/non/existent"))
-}
-
-func (s *WatcherTestSuite) Test_assertDirectoryIntegrityFail() {
-	defer expectNoError(s.T())()
+func (s *WatcherTestSuite) Test_checkDirectory() {
+	t := s.T()
 	w := &Watcher{}
 	cwd := s.currentDirectory
-	w.assertDirectoryIntegrity(path.Join(cwd))
+	assert.Nil(t, w.checkDirectory(cwd))
+	err := w.checkDirectory(path.Join(cwd, "/non/existent"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "/non/existent' does not exist")
+	err = w.checkDirectory(path.Join(cwd, "/main.go"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "/main.go' is not a directory")
 }
 
 func (s *WatcherTestSuite) Test_getDedupedEvents() {
@@ -138,6 +156,289 @@ func (s *WatcherTestSuite) Test_getDedupedEvents() {
 	assert.Len(s.T(), w.getDedupedEvents(), 2)
 }
 
+func (s *WatcherTestSuite) Test_isIgnoredFile() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-watcher")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	textFile := path.Join(directory, "text.go")
+	binaryFile := path.Join(directory, "binary.go")
+	assert.Nil(t, ioutil.WriteFile(textFile, []byte("package main\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(binaryFile, []byte{0x7f, 'E', 'L', 'F', 0x00, 0x01}, 0644))
+	w := initTestWatcher(s.T(), &WatcherConfig{})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	textEvent := &WatcherEvent{Op: fsnotify.Write, Name: textFile}
+	binaryEvent := &WatcherEvent{Op: fsnotify.Write, Name: binaryFile}
+	deletedEvent := &WatcherEvent{Op: fsnotify.Remove, Name: path.Join(directory, "deleted.go")}
+	assert.False(t, w.isIgnoredFile(textEvent))
+	assert.False(t, w.isIgnoredFile(binaryEvent))
+	w.config.IgnoreBinaryFiles = true
+	assert.False(t, w.isIgnoredFile(textEvent))
+	assert.True(t, w.isIgnoredFile(binaryEvent))
+	assert.False(t, w.isIgnoredFile(deletedEvent))
+	w.config.MaxFileSize = 5
+	assert.True(t, w.isIgnoredFile(textEvent))
+	assert.False(t, w.isIgnoredFile(deletedEvent))
+}
+
+func (s *WatcherTestSuite) Test_isTriggerFile() {
+	t := s.T()
+	w := initTestWatcher(s.T(), &WatcherConfig{TriggerFiles: []string{"/project/.env"}})
+	defer w.Close()
+	assert.True(t, w.isTriggerFile(&WatcherEvent{Op: fsnotify.Write, Name: "/project/.env"}))
+	assert.False(t, w.isTriggerFile(&WatcherEvent{Op: fsnotify.Write, Name: "/project/sub/.env"}))
+}
+
+func (s *WatcherTestSuite) Test_isIncludedPathAndIsExcludedPath() {
+	t := s.T()
+	w := initTestWatcher(s.T(), &WatcherConfig{
+		ExcludePatterns: []string{"**/testdata/**", "*_gen.go"},
+		IncludePatterns: []string{"configs/*.yaml"},
+		WatchDirectory:  "/project",
+	})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	assert.True(t, w.isIncludedPath("/project/configs/app.yaml"))
+	assert.False(t, w.isIncludedPath("/project/app.yaml"))
+	assert.True(t, w.isExcludedPath("/project/pkg/testdata"))
+	assert.True(t, w.isExcludedPath("/project/api_gen.go"))
+	assert.False(t, w.isExcludedPath("/project/main.go"))
+	assert.True(t, w.isIgnoredFile(&WatcherEvent{Op: fsnotify.Write, Name: "/project/pkg/api_gen.go"}))
+}
+
+func (s *WatcherTestSuite) Test_isExcludedPath_withUseGitignore() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-watcher")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, os.MkdirAll(path.Join(directory, "node_modules/react"), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, ".gitignore"), []byte("node_modules\n*.out\n"), 0644))
+	w := initTestWatcher(s.T(), &WatcherConfig{UseGitignore: true, WatchDirectory: directory})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	assert.True(t, w.isExcludedPath(path.Join(directory, "node_modules")))
+	assert.True(t, w.isExcludedPath(path.Join(directory, "c.out")))
+	assert.False(t, w.isExcludedPath(path.Join(directory, "main.go")))
+	assert.False(t, w.isExcludedPath("/elsewhere/c.out"))
+	directories, err := w.recursivelyGetDirectories(directory)
+	assert.Nil(t, err)
+	assert.Empty(t, directories)
+}
+
+func (s *WatcherTestSuite) Test_reloadGodevignore() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-watcher")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	assert.Nil(t, os.MkdirAll(path.Join(directory, "tmp/cache"), os.ModePerm))
+	assert.Nil(t, os.MkdirAll(path.Join(directory, "src"), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, GodevignoreFileName), []byte("tmp/\n*.bak\n"), 0644))
+	w := initTestWatcher(s.T(), &WatcherConfig{WatchDirectory: directory})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	assert.True(t, w.isGodevignore(path.Join(directory, GodevignoreFileName)))
+	assert.False(t, w.isGodevignore(path.Join(directory, "src", GodevignoreFileName)))
+	assert.True(t, w.isExcludedPath(path.Join(directory, "main.go.bak")))
+	assert.Nil(s.T(), w.RecursivelyWatch(directory))
+	assert.True(t, w.isWatched(path.Join(directory, "src")))
+	assert.False(t, w.isWatched(path.Join(directory, "tmp")))
+
+	assert.Nil(t, ioutil.WriteFile(path.Join(directory, GodevignoreFileName), []byte("src\n"), 0644))
+	w.reloadGodevignore()
+	assert.False(t, w.isExcludedPath(path.Join(directory, "main.go.bak")))
+	assert.False(t, w.isWatched(path.Join(directory, "src")), "expected newly ignored directories to be unwatched")
+	assert.True(t, w.isWatched(path.Join(directory, "tmp")), "expected directories which are no longer ignored to be watched")
+	assert.True(t, w.isWatched(path.Join(directory, "tmp/cache")))
+	assert.True(t, w.isWatched(directory))
+
+	assert.Nil(t, os.Remove(path.Join(directory, GodevignoreFileName)))
+	w.reloadGodevignore()
+	assert.True(t, w.isWatched(path.Join(directory, "src")))
+}
+
+func (s *WatcherTestSuite) Test_recursivelyGetDirectories_withFollowSymlinks() {
+	t := s.T()
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require elevated privileges on windows")
+	}
+	directory, err := ioutil.TempDir("", "godev-watcher")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	service := path.Join(directory, "service")
+	shared := path.Join(directory, "shared")
+	assert.Nil(t, os.MkdirAll(path.Join(service, "cmd"), os.ModePerm))
+	assert.Nil(t, os.MkdirAll(path.Join(shared, "lib"), os.ModePerm))
+	assert.Nil(t, os.Symlink(shared, path.Join(service, "shared")))
+	assert.Nil(t, os.Symlink(service, path.Join(shared, "lib", "service")), "expected a cycle back to the service")
+	assert.Nil(t, os.Symlink(path.Join(service, "cmd"), path.Join(service, "cmd-link")))
+
+	w := initTestWatcher(s.T(), &WatcherConfig{WatchDirectory: service})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	directories, err := w.recursivelyGetDirectories(service)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{path.Join(service, "cmd")}, directories)
+
+	w = initTestWatcher(s.T(), &WatcherConfig{FollowSymlinks: true, WatchDirectory: service})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	directories, err = w.recursivelyGetDirectories(service)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		path.Join(service, "cmd"),
+		path.Join(service, "shared"),
+		path.Join(service, "shared", "lib"),
+	}, directories, "expected symlinked directories to be followed once")
+	assert.Nil(s.T(), w.RecursivelyWatch(service))
+	assert.True(t, w.isWatched(path.Join(service, "shared", "lib")))
+	assert.False(t, w.isWatched(path.Join(service, "cmd-link")))
+}
+
+func (s *WatcherTestSuite) Test_watchSymlinkedDirectory() {
+	t := s.T()
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require elevated privileges on windows")
+	}
+	directory, err := ioutil.TempDir("", "godev-watcher")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	service := path.Join(directory, "service")
+	shared := path.Join(directory, "shared")
+	assert.Nil(t, os.MkdirAll(service, os.ModePerm))
+	assert.Nil(t, os.MkdirAll(path.Join(shared, "lib"), os.ModePerm))
+	w := initTestWatcher(s.T(), &WatcherConfig{FollowSymlinks: true, WatchDirectory: service})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	assert.Nil(s.T(), w.RecursivelyWatch(service))
+	assert.Nil(t, os.Symlink(shared, path.Join(service, "shared")))
+	assert.True(t, w.isSymlinkedDirectory(path.Join(service, "shared")))
+	w.watchSymlinkedDirectory(path.Join(service, "shared"))
+	assert.True(t, w.isWatched(path.Join(service, "shared")))
+	assert.True(t, w.isWatched(path.Join(service, "shared", "lib")))
+	assert.Nil(t, os.Symlink(shared, path.Join(service, "again")))
+	w.watchSymlinkedDirectory(path.Join(service, "again"))
+	assert.False(t, w.isWatched(path.Join(service, "again")), "expected already watched targets to be skipped")
+}
+
+func (s *WatcherTestSuite) Test_handleEvent_withNewAndRemovedDirectories() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-watcher")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	w := initTestWatcher(s.T(), &WatcherConfig{FileExtensions: []string{"go"}, WatchDirectory: directory})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	assert.Nil(s.T(), w.RecursivelyWatch(directory))
+	pkg := path.Join(directory, "pkg")
+	assert.Nil(t, os.MkdirAll(path.Join(pkg, "api", "v1"), os.ModePerm))
+	createFile(t, path.Join(pkg, "api", "v1", "handler.go"))
+	assert.True(t, w.handleEvent(WatcherEvent{Name: pkg, Op: fsnotify.Create}), "expected files created with the directory to be handled")
+	assert.True(t, w.isWatched(pkg))
+	assert.True(t, w.isWatched(path.Join(pkg, "api")))
+	assert.True(t, w.isWatched(path.Join(pkg, "api", "v1")))
+	assert.Equal(t, []WatcherEvent{{Name: path.Join(pkg, "api", "v1", "handler.go"), Op: fsnotify.Create}}, w.events)
+	assert.Nil(t, os.RemoveAll(pkg))
+	w.handleEvent(WatcherEvent{Name: pkg, Op: fsnotify.Remove})
+	assert.False(t, w.isWatched(pkg))
+	assert.False(t, w.isWatched(path.Join(pkg, "api", "v1")), "expected the directories within removed ones to be unwatched")
+	assert.True(t, w.isWatched(directory))
+	assert.Equal(t, 1, w.GetWatchedPathCount())
+}
+
+func (s *WatcherTestSuite) Test_handleEvent_withWatchEvents() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-watcher")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	filePath := path.Join(directory, "main.go")
+	assert.Nil(t, ioutil.WriteFile(filePath, []byte("package main\n"), 0644))
+	w := initTestWatcher(s.T(), &WatcherConfig{
+		FileExtensions: []string{"go"},
+		WatchEvents:    []string{"create", "write"},
+		WatchDirectory: directory,
+	})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	assert.False(t, w.handleEvent(WatcherEvent{Name: filePath, Op: fsnotify.Chmod}), "expected chmod events to be skipped")
+	assert.True(t, w.handleEvent(WatcherEvent{Name: filePath, Op: fsnotify.Write}))
+	assert.True(t, w.handleEvent(WatcherEvent{Name: filePath, Op: fsnotify.Write | fsnotify.Chmod}))
+	assert.Len(t, w.events, 2)
+	assert.Nil(t, os.Mkdir(path.Join(directory, "pkg"), os.ModePerm))
+	assert.False(t, w.handleEvent(WatcherEvent{Name: path.Join(directory, "pkg"), Op: fsnotify.Create}))
+	assert.True(t, w.isWatched(path.Join(directory, "pkg")), "expected new directories to be watched")
+}
+
+func (s *WatcherTestSuite) TestPauseAndResume() {
+	t := s.T()
+	directory, err := ioutil.TempDir("", "godev-watcher")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+	filePath := path.Join(directory, "main.go")
+	var events []*Event
+	bus := InitEventBus(&EventBusConfig{})
+	bus.Subscribe(EventAll, func(event *Event) { events = append(events, event) })
+	w := initTestWatcher(s.T(), &WatcherConfig{
+		FileExtensions: []string{"go"},
+		WatchDirectory: directory,
+		Events:         bus,
+	})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	w.Pause("testing")
+	w.Pause("testing again")
+	assert.True(t, w.IsPaused())
+	assert.False(t, w.handleEvent(WatcherEvent{Name: filePath, Op: fsnotify.Write}), "expected changes to be dropped while paused")
+	assert.Nil(t, os.Mkdir(path.Join(directory, "pkg"), os.ModePerm))
+	w.handleEvent(WatcherEvent{Name: path.Join(directory, "pkg"), Op: fsnotify.Create})
+	assert.True(t, w.isWatched(path.Join(directory, "pkg")), "expected new directories to be watched while paused")
+	w.Resume("done")
+	assert.False(t, w.IsPaused())
+	assert.True(t, w.handleEvent(WatcherEvent{Name: filePath, Op: fsnotify.Write}))
+	assert.Len(t, events, 2)
+	assert.Equal(t, EventWatcherPaused, events[0].Name)
+	assert.Equal(t, "testing", events[0].Reason)
+	assert.Equal(t, EventWatcherResumed, events[1].Name)
+}
+
+func (s *WatcherTestSuite) TestBeginWatch_stopsWhenTheContextIsDone() {
+	w := initTestWatcher(s.T(), &WatcherConfig{FileExtensions: []string{"go"}})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	ctx, cancel := context.WithCancel(context.Background())
+	var waitGroup sync.WaitGroup
+	w.BeginWatch(ctx, &waitGroup, func(events *[]WatcherEvent) bool { return true })
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		waitGroup.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		s.T().Error("expected the watch routine to stop when its context is done")
+	}
+}
+
+func (s *WatcherTestSuite) TestTouch() {
+	t := s.T()
+	w := initTestWatcher(s.T(), &WatcherConfig{FileExtensions: []string{"go"}})
+	defer w.Close()
+	w.logger.SetOutput(&bytes.Buffer{})
+	var handled []WatcherEvent
+	var waitGroup sync.WaitGroup
+	w.BeginWatch(context.Background(), &waitGroup, func(events *[]WatcherEvent) bool {
+		handled = append(handled, *events...)
+		return true
+	})
+	assert.Nil(t, w.Touch([]string{"/project/main.go", "/project/README.md"}))
+	time.Sleep(2500 * time.Millisecond)
+	w.EndWatch()
+	assert.Equal(t, []WatcherEvent{{Name: "/project/main.go", Op: fsnotify.Write}}, handled, "expected touched paths to be filtered like changes")
+	assert.NotNil(t, w.Touch(make([]string, WatcherTouchQueueSize+1)), "expected touches beyond the queue to be rejected")
+}
+
 func (s *WatcherTestSuite) Test_isIgnoredName() {
 	ignoredName := "ignored"
 	watchedNames := []string{
@@ -181,14 +482,29 @@ func (s *WatcherTestSuite) Test_pathIsDirectory() {
 
 	filePath := path.Join(s.currentDirectory, "/main.go")
 	assert.Falsef(s.T(), w.pathIsDirectory(filePath), "expected '%s' to not be a directory but it was", filePath)
+	assert.False(s.T(), w.pathIsDirectory(path.Join(cwd, "/non/existent")))
 }
 
 func (s *WatcherTestSuite) Test_recursivelyGetDirectories() {
 	w := &Watcher{}
 	cwd := s.currentDirectory
 	expectedDirectories := []string{"1", "2", "2-1", "2-2", "2-2-1", "3"}
-	directories := w.recursivelyGetDirectories(path.Join(cwd, "/data/test-recursive"))
+	directories, err := w.recursivelyGetDirectories(path.Join(cwd, "/data/test-recursive"))
+	assert.Nil(s.T(), err)
 	for index, directory := range directories {
 		assert.Equalf(s.T(), path.Base(directory), expectedDirectories[index], "expected '%s' to be '%s", path.Base(directory), expectedDirectories[index])
 	}
 }
+
+func (s *WatcherTestSuite) Test_recursivelyGetDirectories_withExcludePatterns() {
+	t := s.T()
+	directory := path.Join(s.currentDirectory, "/data/test-recursive")
+	w := &Watcher{config: &WatcherConfig{ExcludePatterns: []string{"2/**"}, WatchDirectory: directory}}
+	directories, err := w.recursivelyGetDirectories(directory)
+	assert.Nil(t, err)
+	var names []string
+	for _, subDirectory := range directories {
+		names = append(names, path.Base(subDirectory))
+	}
+	assert.Equal(t, []string{"1", "3"}, names)
+}
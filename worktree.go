@@ -0,0 +1,67 @@
+package godev
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitWorktree is a linked git worktree, a second checkout of a
+// repository which shares its objects with the main worktree
+type GitWorktree struct {
+	// Path is the top-level directory of the linked worktree
+	Path string
+	// MainPath is the top-level directory of the main worktree
+	MainPath string
+}
+
+// DetectGitWorktree returns the linked worktree which :directory is in
+// or nil when it is in a main worktree, a bare repository or no
+// repository at all
+func DetectGitWorktree(directory string) *GitWorktree {
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return nil
+	}
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel", "--git-dir", "--git-common-dir")
+	cmd.Dir = directory
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 3 {
+		return nil
+	}
+	for index, line := range lines {
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(directory, line)
+		}
+		lines[index] = resolvePath(line)
+	}
+	toplevel, gitDirectory, commonDirectory := lines[0], lines[1], lines[2]
+	if gitDirectory == commonDirectory || filepath.Base(commonDirectory) != ".git" {
+		return nil
+	}
+	return &GitWorktree{Path: toplevel, MainPath: filepath.Dir(commonDirectory)}
+}
+
+// GetMainPath returns the path in the main worktree which corresponds to
+// :filePath in the linked worktree or an empty string if :filePath is not
+// in the linked worktree
+func (worktree *GitWorktree) GetMainPath(filePath string) string {
+	relativePath, err := filepath.Rel(worktree.Path, resolvePath(filePath))
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.Join(worktree.MainPath, relativePath)
+}
+
+// resolvePath returns :filePath with symbolic links resolved as git
+// reports its directories, or :filePath when it cannot be resolved
+func resolvePath(filePath string) string {
+	if resolved, err := filepath.EvalSymlinks(filePath); err == nil {
+		return resolved
+	}
+	return filepath.Clean(filePath)
+}
@@ -0,0 +1,75 @@
+package godev
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WorktreeTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestWorktree(t *testing.T) {
+	suite.Run(t, new(WorktreeTestSuite))
+}
+
+func (s *WorktreeTestSuite) SetupTest() {
+	directory, err := ioutil.TempDir("", "godev-worktree")
+	if err != nil {
+		s.T().Errorf("error while creating a temporary directory: %s", err)
+	}
+	s.directory = resolvePath(directory)
+}
+
+func (s *WorktreeTestSuite) TearDownTest() {
+	os.RemoveAll(s.directory)
+}
+
+func (s *WorktreeTestSuite) git(directory string, arguments ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=godev", "-c", "user.email=godev@localhost"}, arguments...)...)
+	cmd.Dir = directory
+	if output, err := cmd.CombinedOutput(); err != nil {
+		s.T().Errorf("git %v failed: %s\n%s", arguments, err, output)
+	}
+}
+
+func (s *WorktreeTestSuite) TestDetectGitWorktree() {
+	t := s.T()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	mainPath := filepath.Join(s.directory, "main")
+	assert.Nil(t, os.MkdirAll(filepath.Join(mainPath, "cmd"), 0755))
+	assert.Nil(t, DetectGitWorktree(mainPath), "expected no worktree outside of git repositories")
+	s.git(mainPath, "init")
+	s.git(mainPath, "commit", "--allow-empty", "-m", "initial")
+	assert.Nil(t, DetectGitWorktree(mainPath), "expected the main worktree not to be a linked worktree")
+	assert.Nil(t, DetectGitWorktree(filepath.Join(mainPath, "cmd")))
+	linkedPath := filepath.Join(s.directory, "review")
+	s.git(mainPath, "worktree", "add", "-b", "review", linkedPath)
+	worktree := DetectGitWorktree(linkedPath)
+	if assert.NotNil(t, worktree) {
+		assert.Equal(t, linkedPath, worktree.Path)
+		assert.Equal(t, mainPath, worktree.MainPath)
+	}
+	assert.Nil(t, os.MkdirAll(filepath.Join(linkedPath, "cmd", "api"), 0755))
+	worktree = DetectGitWorktree(filepath.Join(linkedPath, "cmd", "api"))
+	if assert.NotNil(t, worktree, "expected worktrees to be detected from their subdirectories") {
+		assert.Equal(t, linkedPath, worktree.Path)
+	}
+}
+
+func (s *WorktreeTestSuite) TestGetMainPath() {
+	t := s.T()
+	worktree := &GitWorktree{Path: filepath.Join(s.directory, "review"), MainPath: filepath.Join(s.directory, "main")}
+	assert.Equal(t, filepath.Join(s.directory, "main", ".godev", "certs"), worktree.GetMainPath(filepath.Join(s.directory, "review", ".godev", "certs")))
+	assert.Equal(t, "", worktree.GetMainPath(filepath.Join(s.directory, "other", ".godev")))
+	assert.Equal(t, "", worktree.GetMainPath(filepath.Join(s.directory, "reviewed")))
+}